@@ -0,0 +1,25 @@
+package client_test
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/buck06191/todo-app/pkg/server"
+)
+
+// TestOpenAPIUpToDate checks openapi.json, which the client is generated
+// from, is the document the server describes itself with.
+func TestOpenAPIUpToDate(t *testing.T) {
+	want, err := server.OpenAPI()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile("openapi.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, append(want, '\n')) {
+		t.Error("openapi.json is out of date, run go generate ./client")
+	}
+}
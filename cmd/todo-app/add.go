@@ -0,0 +1,324 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/buck06191/todo-app/pkg/todo"
+	"golang.org/x/term"
+)
+
+// addCommand implements `todo-app add <task> [-due date]`,
+// `todo-app add -json '<json>'`, `todo-app add -stdin`, `todo-app add -i`
+// and `todo-app add -editor`.
+func addCommand() (*flag.FlagSet, func(args []string) error) {
+	fs := newFlagSet("add", "<task> [flags]")
+	due := fs.String("due", "", "When the item is due: YYYY-MM-DD, YYYY-MM-DDTHH:MM or e.g. \"tomorrow\", \"next friday 9am\", \"in 3 days\".")
+	priority := fs.String("priority", "", "How important the item is: low, medium or high (or P1–P3).")
+	var tags stringList
+	fs.Var(&tags, "tag", "Tag the item. Can be given more than once, or put #tag in the task instead.")
+	project := fs.String("project", "", "Project the item belongs to, or put +project in the task instead.")
+	var contexts stringList
+	fs.Var(&contexts, "context", "Context the item can be done in, e.g. home. Can be given more than once, or put @context in the task instead.")
+	repeat := fs.String("repeat", "", "Repeat the item when it is done: daily, weekly, every 2 weeks, every monday, every last friday, every business day, or an RRULE such as FREQ=MONTHLY;BYDAY=-1FR.")
+	remind := fs.String("remind", "", "Remind of the item this long before it is due instead of at the daemon's times, separated by commas, e.g. 1d,2h. Also given as alarms by -format ics exports.")
+	estimate := fs.String("estimate", "", "How long the item is expected to take, e.g. 2h, 45m or 1d, for todo-app workload.")
+	parent := fs.Int("parent", 0, "Make the item a subtask of the item with this ID.")
+	noDupes := fs.Bool("no-dupes", false, "Fail rather than add an item much like one on the list that isn't done, instead of asking at a terminal or only warning elsewhere. With -stdin such lines are left out.")
+	templateName := fs.String("template", "", "Fill in the fields not given from the template saved with this name by todo-app template save, adding its subtasks under the item.")
+	asJSON := fs.Bool("json", false, "Read the item as JSON: '{\"todo\": task to do, \"due\": date due (YYYY-MM-DD or RFC 3339)}'")
+	stdin := fs.Bool("stdin", false, "Read items from standard input, one JSON object as -json takes per line, adding the good ones and reporting the rest by line number.")
+	interactive := fs.Bool("i", false, "Ask for the task, due date, priority and tags one at a time, asking again for any that can't be read. Press enter to leave one out, or to keep the task or flag given for it.")
+	editor := fs.Bool("editor", false, "Write the item in $EDITOR, as a file with its fields at the top and its notes below them. The task and flags given fill them in to start with.")
+	return fs, func(args []string) error {
+		positional := parseInterspersed(fs, args)
+
+		if *interactive && (*stdin || *asJSON || *editor) {
+			return errors.New("-i can't be used with -stdin, -json or -editor")
+		}
+		if *editor && (*stdin || *asJSON) {
+			return errors.New("-editor can't be used with -stdin or -json")
+		}
+
+		if *stdin {
+			if len(positional) > 0 || *asJSON || *due != "" || *priority != "" || len(tags) > 0 || *project != "" || len(contexts) > 0 || *repeat != "" || *remind != "" || *estimate != "" || *parent != 0 || *templateName != "" {
+				return errors.New("-stdin takes no task and no other flags")
+			}
+			return addStdin(os.Stdin, *noDupes)
+		}
+
+		if len(positional) == 0 && !*interactive && !*editor {
+			fs.Usage()
+			return errors.New("add needs something to do")
+		}
+
+		var item todo.ParsedTodoItem
+		var err error
+		fields := todo.TodoItem{
+			Todo:     strings.Join(positional, " "),
+			Due:      *due,
+			Priority: *priority,
+			Tags:     tags,
+			Project:  *project,
+			Contexts: contexts,
+			Repeat:   *repeat,
+			Remind:   *remind,
+			Estimate: *estimate,
+			Parent:   *parent,
+		}
+		var template todo.Template
+		if *templateName != "" {
+			if template, err = loadTemplate(*templateName); err != nil {
+				return err
+			}
+			fields = template.Apply(fields)
+		}
+		switch {
+		case *interactive:
+			if fields, err = askItem(bufio.NewReader(os.Stdin), os.Stdout, fields); err != nil {
+				return err
+			}
+			item, err = todo.ParseItem(fields)
+		case *editor:
+			if fields, err = editItemFile(fields, "todo-new-*.md"); err != nil {
+				return err
+			}
+			if strings.TrimSpace(fields.Todo) == "" {
+				return errors.New("nothing added, as the task was left empty")
+			}
+			item, err = todo.ParseItem(fields)
+		case *asJSON:
+			if len(positional) != 1 || *due != "" || *priority != "" || len(tags) > 0 || *project != "" || len(contexts) > 0 || *repeat != "" || *remind != "" || *estimate != "" || *parent != 0 || *templateName != "" {
+				return errors.New("-json takes a single JSON item and no other flags")
+			}
+			item, err = todo.ParseInput(&positional[0])
+		default:
+			item, err = todo.ParseItem(fields)
+		}
+		if err != nil {
+			return err
+		}
+
+		store, err := openStore()
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+
+		saved, err := store.List()
+		if err != nil {
+			return err
+		}
+		if item.Parent != 0 {
+			if err := todo.CheckParent(saved, 0, item.Parent); err != nil {
+				return err
+			}
+		}
+		if add, err := checkDuplicate(saved, item, *noDupes); !add || err != nil {
+			return err
+		}
+
+		item, err = store.Add(item)
+		if err != nil {
+			return err
+		}
+		if len(template.Subtasks) == 0 {
+			return PrettyPrintItem(item)
+		}
+
+		added := []todo.ParsedTodoItem{item}
+		for _, task := range template.Subtasks {
+			sub, err := todo.ParseItem(todo.TodoItem{Todo: task, Parent: item.ID})
+			if err != nil {
+				return err
+			}
+			if sub, err = store.Add(sub); err != nil {
+				return err
+			}
+			added = append(added, sub)
+		}
+		fmt.Println("Added:")
+		return listPrinter{w: os.Stdout, now: time.Now()}.print(added)
+	}
+}
+
+// checkDuplicate reports whether item should be added when it may be a
+// repeat of one of the items in saved that aren't done. With noDupes it is
+// an error; otherwise it is asked about at a terminal, taking no for an
+// answer, and only warned of elsewhere so that scripts carry on.
+func checkDuplicate(saved []todo.ParsedTodoItem, item todo.ParsedTodoItem, noDupes bool) (bool, error) {
+	dupes := todo.Duplicates(saved, item.Todo)
+	if len(dupes) == 0 {
+		return true, nil
+	}
+	like := dupes[0]
+	switch {
+	case noDupes:
+		return false, fmt.Errorf("not added, as it is much like %d %s, which isn't done yet", like.ID, like.Todo)
+	case !term.IsTerminal(int(os.Stdin.Fd())):
+		fmt.Fprintf(os.Stderr, "todo-app: adding it anyway, but it is much like %d %s, which isn't done yet\n", like.ID, like.Todo)
+		return true, nil
+	}
+	fmt.Fprintf(os.Stderr, "This is much like %d %s, which isn't done yet. Add it anyway? [y/N] ", like.ID, like.Todo)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	if a := strings.ToLower(strings.TrimSpace(answer)); a != "y" && a != "yes" {
+		fmt.Println("Nothing added.")
+		return false, nil
+	}
+	return true, nil
+}
+
+// loadTemplate returns the template saved on the list as name.
+func loadTemplate(name string) (todo.Template, error) {
+	store, err := openStore()
+	if err != nil {
+		return todo.Template{}, err
+	}
+	defer store.Close()
+	return todo.SavedTemplate(store, name)
+}
+
+// askItem asks on out for the task, due date, priority and tags of an item,
+// reading the answers from in, and returns item with them filled in. What
+// item already has is shown as the answer given by pressing enter. Answers
+// that can't be read are explained and asked for again, and the due date
+// is shown as it was understood.
+func askItem(in *bufio.Reader, out io.Writer, item todo.TodoItem) (todo.TodoItem, error) {
+	ask := func(question, def string) (string, error) {
+		answer, err := askLine(in, out, question, def)
+		if err != nil {
+			return "", errors.New("nothing added")
+		}
+		return answer, nil
+	}
+
+	var err error
+	for {
+		if item.Todo, err = ask("Task", item.Todo); err != nil {
+			return item, err
+		}
+		if item.Todo != "" {
+			break
+		}
+		fmt.Fprintln(out, "  The task can't be empty.")
+	}
+	for {
+		if item.Due, err = ask("Due, e.g. tomorrow, next friday 9am or 2025-03-01", item.Due); err != nil {
+			return item, err
+		}
+		due, parseErr := todo.ParseDueDate(item.Due)
+		if parseErr == nil {
+			if !due.IsZero() {
+				fmt.Fprintf(out, "  Due %s %s\n", due.In(todo.Location).Format("Mon"), formatDue(todo.ParsedTodoItem{Due: due}))
+			}
+			break
+		}
+		fmt.Fprintf(out, "  %v\n", parseErr)
+		item.Due = ""
+	}
+	for {
+		if item.Priority, err = ask("Priority: low, medium or high", item.Priority); err != nil {
+			return item, err
+		}
+		_, parseErr := todo.ParsePriority(item.Priority)
+		if parseErr == nil {
+			break
+		}
+		fmt.Fprintf(out, "  %v\n", parseErr)
+		item.Priority = ""
+	}
+	answer, err := ask("Tags, separated by spaces", strings.Join(item.Tags, " "))
+	if err != nil {
+		return item, err
+	}
+	item.Tags = strings.FieldsFunc(answer, func(r rune) bool { return r == ' ' || r == ',' })
+	return item, nil
+}
+
+// askLine asks question on out and returns the answer read from in, or def
+// if there isn't one. It returns io.EOF once in has nothing more to read.
+func askLine(in *bufio.Reader, out io.Writer, question, def string) (string, error) {
+	if def != "" {
+		question += " [" + def + "]"
+	}
+	fmt.Fprintf(out, "%s: ", question)
+	line, err := in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		fmt.Fprintln(out)
+		return "", io.EOF
+	}
+	if answer := strings.TrimSpace(line); answer != "" {
+		return answer, nil
+	}
+	return def, nil
+}
+
+// maxLine is the longest line `todo-app add -stdin` reads.
+const maxLine = 1 << 20
+
+// addStdin adds the items in r, one JSON object per line, skipping blank
+// lines. A line that isn't a good item is reported with its number and
+// left out, and the rest are still added. Items much like ones not done
+// yet, including those added earlier from r, are warned of, or with
+// noDupes left out.
+func addStdin(r io.Reader, noDupes bool) error {
+	store, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	saved, err := store.List()
+	if err != nil {
+		return err
+	}
+
+	var added, failed, dupes int
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxLine)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		item, err := todo.ParseInput(&line)
+		if err == nil {
+			err = todo.CheckParent(saved, 0, item.Parent)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "line %d: %v\n", n, err)
+			failed++
+			continue
+		}
+		if found := todo.Duplicates(saved, item.Todo); len(found) > 0 {
+			if noDupes {
+				fmt.Fprintf(os.Stderr, "line %d: left out, as it is much like %d %s\n", n, found[0].ID, found[0].Todo)
+				dupes++
+				continue
+			}
+			fmt.Fprintf(os.Stderr, "line %d: much like %d %s, which isn't done yet\n", n, found[0].ID, found[0].Todo)
+		}
+		if item, err = store.Add(item); err != nil {
+			return err
+		}
+		saved = append(saved, item)
+		added++
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if dupes > 0 {
+		fmt.Printf("Added %d items, leaving out %d much like ones on the list\n", added, dupes)
+	} else {
+		fmt.Printf("Added %d items\n", added)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d lines couldn't be added", failed)
+	}
+	return nil
+}
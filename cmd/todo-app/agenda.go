@@ -0,0 +1,97 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/buck06191/todo-app/pkg/calendar"
+	"github.com/buck06191/todo-app/pkg/todo"
+)
+
+// agendaCommand implements `todo-app agenda`, a calendar of what is due this
+// week or month.
+func agendaCommand() (*flag.FlagSet, func(args []string) error) {
+	fs := newFlagSet("agenda", "[-week | -month] [flags]")
+	week := fs.Bool("week", false, "Show the week, Monday to Sunday. This is the default.")
+	month := fs.Bool("month", false, "Show the whole month instead of the week.")
+	fromFlag := fs.String("from", "", "Show the week or month containing this date instead of the current one, e.g. 2024-06-01 or \"next monday\".")
+	whereExpr := fs.String("where", "", "Only show items matching a filter expression, as for list.")
+	return fs, func(args []string) error {
+		fs.Parse(args)
+
+		if fs.NArg() > 0 {
+			fs.Usage()
+			return fmt.Errorf("unexpected argument %q", fs.Arg(0))
+		}
+		if *week && *month {
+			return fmt.Errorf("-week and -month can't be used together")
+		}
+
+		now := time.Now()
+		from := now
+		if *fromFlag != "" {
+			var err error
+			if from, err = todo.ParseDueDate(*fromFlag); err != nil {
+				return err
+			}
+		}
+		where, err := parseWhere(*whereExpr)
+		if err != nil {
+			return err
+		}
+
+		store, err := openStore()
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+
+		saved, err := store.List()
+		if err != nil {
+			return err
+		}
+		var items []todo.ParsedTodoItem
+		for _, item := range saved {
+			if where == nil || where.Match(item, now) {
+				items = append(items, item)
+			}
+		}
+
+		opts := calendar.Options{Width: terminalWidth(os.Stdout), Today: now}
+		if opts.Width == 0 {
+			opts.Width = 120
+		}
+		start, days := todo.WeekStart(from), 7
+		title := "Week of " + start.Format("Mon 2 January 2006")
+		if *month {
+			start, days = todo.MonthGrid(from)
+			opts.Month = todo.MonthStart(from).Month()
+			opts.MaxItems = 3
+			title = todo.MonthStart(from).Format("January 2006")
+		}
+		overdue, agenda := todo.Agenda(items, start, days, now)
+
+		if useColor(os.Stdout) {
+			opts.Style = func(item todo.ParsedTodoItem, text string) string {
+				if c := (listPrinter{now: now}).color(item); c != colorNone {
+					return c + text + colorReset
+				}
+				return text
+			}
+		}
+
+		if len(overdue) > 0 {
+			fmt.Println("Overdue:")
+			if err := PrintList(os.Stdout, overdue, now); err != nil {
+				return err
+			}
+			fmt.Println()
+		}
+		fmt.Println(title)
+		fmt.Println()
+		fmt.Print(calendar.Grid(agenda, opts))
+		return nil
+	}
+}
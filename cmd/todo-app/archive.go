@@ -0,0 +1,303 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/buck06191/todo-app/pkg/todo"
+)
+
+// archiveCommand implements `todo-app archive [-older-than 30d]`, moving
+// done items out of the list into a file for the month they were done in,
+// and `todo-app archive list` and `archive search` for looking them up.
+func archiveCommand() (*flag.FlagSet, func(args []string) error) {
+	fs := newFlagSet("archive", "[-older-than 30d] [-dry-run] | list ... | search ...")
+	var olderThan age
+	fs.Var(&olderThan, "older-than", "Only archive items done at least this long ago, e.g. 30d, 2w or 12h. (default every done item)")
+	dir := archiveDirFlag(fs)
+	dryRun := fs.Bool("dry-run", false, "Show the items that would be archived without moving them.")
+	return fs, func(args []string) error {
+		if len(args) > 0 {
+			switch args[0] {
+			case "list":
+				return runCommand(archiveListCommand, args[1:])
+			case "search":
+				return runCommand(archiveSearchCommand, args[1:])
+			}
+		}
+
+		fs.Parse(args)
+		if fs.NArg() > 0 {
+			fs.Usage()
+			return fmt.Errorf("unknown archive command %q", fs.Arg(0))
+		}
+
+		store, err := openStore()
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+		saved, err := store.List()
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		cutoff := now.Add(-time.Duration(olderThan))
+		archive := map[int]bool{}
+		for _, item := range saved {
+			if item.Completed && !doneAt(item, now).After(cutoff) {
+				archive[item.ID] = true
+			}
+		}
+		// Items with subtasks staying on the list are kept too, and so in turn
+		// are their parents.
+		for changed := true; changed; {
+			changed = false
+			for _, item := range saved {
+				if item.Parent != 0 && !archive[item.ID] && archive[item.Parent] {
+					delete(archive, item.Parent)
+					changed = true
+				}
+			}
+		}
+		var items []todo.ParsedTodoItem
+		byMonth := map[string][]todo.ParsedTodoItem{}
+		var ids []int
+		for _, item := range saved {
+			if archive[item.ID] {
+				items = append(items, item)
+				month := doneAt(item, now).In(todo.Location).Format("2006-01")
+				byMonth[month] = append(byMonth[month], item)
+				ids = append(ids, item.ID)
+			}
+		}
+		if *dryRun || len(items) == 0 {
+			return printDryRun("archive", items)
+		}
+
+		path, err := archiveDir(*dir)
+		if err != nil {
+			return err
+		}
+		// The archive is written first, so that nothing is lost if removing
+		// the items then fails. Archiving them again replaces them in it.
+		for month, items := range byMonth {
+			file, err := openArchive(filepath.Join(path, month+".json"))
+			if err != nil {
+				return err
+			}
+			err = file.Import(items, nil)
+			if cerr := file.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				return err
+			}
+		}
+		if err := store.(todo.Remover).Remove(ids...); err != nil {
+			return err
+		}
+		fmt.Printf("Archived %d items to %s\n", len(items), path)
+		return nil
+	}
+}
+
+// archiveListCommand implements `todo-app archive list [-month YYYY-MM]`.
+func archiveListCommand() (*flag.FlagSet, func(args []string) error) {
+	fs := newFlagSet("archive list", "[flags]")
+	month := fs.String("month", "", "Only show the items done in this month, as YYYY-MM.")
+	dir := archiveDirFlag(fs)
+	output := outputFlag(fs)
+	absolute := absoluteFlag(fs)
+	return fs, func(args []string) error {
+		fs.Parse(args)
+		if fs.NArg() > 0 {
+			fs.Usage()
+			return errors.New("archive list takes no arguments")
+		}
+		if *month != "" {
+			if _, err := time.Parse("2006-01", *month); err != nil {
+				return fmt.Errorf("bad -month %q, expected YYYY-MM", *month)
+			}
+		}
+		render, err := lookupOutput(*output)
+		if err != nil {
+			return err
+		}
+
+		items, err := loadArchive(*dir, *month)
+		if err != nil {
+			return err
+		}
+		if len(items) == 0 && render == nil {
+			fmt.Println("Nothing has been archived.")
+			return nil
+		}
+		return listPrinter{w: os.Stdout, now: time.Now(), render: render, absolute: *absolute}.print(items)
+	}
+}
+
+// archiveSearchCommand implements `todo-app archive search <query>`, finding
+// archived items as search finds those on the list.
+func archiveSearchCommand() (*flag.FlagSet, func(args []string) error) {
+	fs := newFlagSet("archive search", "<query> [flags]")
+	useRegexp := fs.Bool("regex", false, "Treat the query as a regular expression, matched case-insensitively.")
+	dir := archiveDirFlag(fs)
+	output := outputFlag(fs)
+	absolute := absoluteFlag(fs)
+	return fs, func(args []string) error {
+		positional := parseInterspersed(fs, args)
+		if len(positional) == 0 {
+			fs.Usage()
+			return errors.New("nothing to search for")
+		}
+		query := strings.Join(positional, " ")
+		render, err := lookupOutput(*output)
+		if err != nil {
+			return err
+		}
+
+		items, err := loadArchive(*dir, "")
+		if err != nil {
+			return err
+		}
+		mem := todo.NewMemoryStore()
+		if err := mem.Import(items, nil); err != nil {
+			return err
+		}
+		var found []todo.ParsedTodoItem
+		if *useRegexp {
+			re, err := regexp.Compile("(?im)" + query)
+			if err != nil {
+				return fmt.Errorf("bad -regex query: %w", err)
+			}
+			found, err = todo.SearchRegexp(mem, re)
+			if err != nil {
+				return err
+			}
+		} else if found, err = todo.Search(mem, query); err != nil {
+			return err
+		}
+		if len(found) == 0 && render == nil {
+			fmt.Println("No matches.")
+			return nil
+		}
+		return listPrinter{w: os.Stdout, now: time.Now(), render: render, absolute: *absolute}.print(found)
+	}
+}
+
+// archiveDirFlag adds the -dir flag giving where the archive is kept.
+func archiveDirFlag(fs *flag.FlagSet) *string {
+	return fs.String("dir", "", "Directory the archive is kept in. (default archive beside the JSON file, or ~/.todo/archive for other stores, with a directory for each list and shared list)")
+}
+
+// archiveDir returns the directory the list's archive is kept in: dir if
+// it is given, and otherwise the archive directory beside the store's file.
+func archiveDir(dir string) (string, error) {
+	if dir == "" {
+		parent, err := storeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(parent, "archive")
+	}
+	switch {
+	case *listName != "" && *listName != mainList:
+		dir = filepath.Join(dir, "lists", *listName)
+	case *sharedName != "":
+		dir = filepath.Join(dir, filepath.FromSlash(*sharedName))
+	}
+	return dir, nil
+}
+
+// openArchive opens one month's archive file, encrypted with the store's
+// key if the store is.
+func openArchive(path string) (*todo.JSONStore, error) {
+	file := todo.NewJSONStore(path)
+	if storeKey != nil {
+		if err := file.UseKey(storeKey); err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+	return file, nil
+}
+
+// archiveMonth matches the names of the archive's files.
+var archiveMonth = regexp.MustCompile(`^\d{4}-\d{2}\.json$`)
+
+// loadArchive returns the archived items done in month, or every one if
+// month is "", oldest month first.
+func loadArchive(dir, month string) ([]todo.ParsedTodoItem, error) {
+	path, err := archiveDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	// The list is opened for its key, which an encrypted archive needs.
+	store, err := openList()
+	if err != nil {
+		return nil, err
+	}
+	store.Close()
+	entries, err := os.ReadDir(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var items []todo.ParsedTodoItem
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !archiveMonth.MatchString(name) || month != "" && name != month+".json" {
+			continue
+		}
+		file, err := openArchive(filepath.Join(path, name))
+		if err != nil {
+			return nil, err
+		}
+		saved, err := file.List()
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		items = append(items, saved...)
+	}
+	return items, nil
+}
+
+// doneAt returns when item was done for archiving it, which is when it was
+// added for items done before that was kept, or now for neither.
+func doneAt(item todo.ParsedTodoItem, now time.Time) time.Time {
+	switch {
+	case !item.CompletedAt.IsZero():
+		return item.CompletedAt
+	case !item.CreatedAt.IsZero():
+		return item.CreatedAt
+	}
+	return now
+}
+
+// age is a flag.Value for how long ago something was, given in days or
+// weeks, e.g. 30d or 2w, or as time.ParseDuration takes it.
+type age time.Duration
+
+func (a *age) String() string {
+	return time.Duration(*a).String()
+}
+
+func (a *age) Set(s string) error {
+	d, err := todo.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("expected e.g. 30d, 2w or 12h")
+	}
+	*a = age(d)
+	return nil
+}
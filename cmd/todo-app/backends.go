@@ -0,0 +1,8 @@
+package main
+
+// Storage backends that can be picked with -store.
+import (
+	_ "github.com/buck06191/todo-app/pkg/todo/bolt"
+	_ "github.com/buck06191/todo-app/pkg/todo/postgres"
+	_ "github.com/buck06191/todo-app/pkg/todo/sqlite"
+)
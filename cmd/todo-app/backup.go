@@ -0,0 +1,255 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/buck06191/todo-app/pkg/seal"
+	"github.com/buck06191/todo-app/pkg/todo"
+)
+
+// backupKey is the meta key of the store's backupSettings.
+const backupKey = "backup"
+
+// backupSettings say whether backups are made automatically before the
+// store is changed.
+type backupSettings struct {
+	// Keep is how many automatic backups to keep, or 0 for none.
+	Keep int    `json:"keep"`
+	Dir  string `json:"dir,omitempty"`
+}
+
+// backupTime is the layout of the times in backup file names, which sort
+// in the order the backups were made.
+const backupTime = "20060102-150405.000"
+
+// sealedBackup starts a backup of an encrypted store, followed by the
+// sealed snapshot.
+const sealedBackup = "todo-app:sealed-backup\n"
+
+// backupCommand implements `todo-app backup [path]`, writing a snapshot of
+// the whole store, and `todo-app backup -auto N` to make one before every
+// change.
+func backupCommand() (*flag.FlagSet, func(args []string) error) {
+	fs := newFlagSet("backup", "[path] | -auto <n> [-dir <dir>]")
+	auto := fs.Int("auto", -1, "Back the store up automatically before every command that changes it, keeping this many of those backups, or 0 to stop.")
+	dir := fs.String("dir", "", "With -auto, the directory to keep the backups in. (default backups beside the JSON file, or ~/.todo/backups for other stores)")
+	return fs, func(args []string) error {
+		fs.Parse(args)
+		if fs.NArg() > 1 || *auto >= 0 && fs.NArg() > 0 {
+			fs.Usage()
+			return errors.New("backup takes at most one path, and none with -auto")
+		}
+
+		store, err := openRootStore()
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+
+		if *auto >= 0 {
+			if *dir != "" {
+				if *dir, err = filepath.Abs(*dir); err != nil {
+					return err
+				}
+			}
+			if err := todo.SaveMeta(store, backupKey, backupSettings{Keep: *auto, Dir: *dir}); err != nil {
+				return err
+			}
+			if *auto == 0 {
+				fmt.Println("Automatic backups are off.")
+				return nil
+			}
+			path, err := backupDir(*dir)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("The store will be backed up to %s before every change, keeping the last %d backups.\n", path, *auto)
+			return nil
+		}
+
+		path := fs.Arg(0)
+		if info, err := os.Stat(path); path == "" || err == nil && info.IsDir() {
+			if path == "" {
+				if path, err = backupDir(""); err != nil {
+					return err
+				}
+			}
+			path = filepath.Join(path, "backup-"+time.Now().Format(backupTime)+".json.gz")
+		}
+		snap, err := writeBackup(store, path)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Backed up %d items to %s\n", snap.Items(), path)
+		return nil
+	}
+}
+
+// restoreBackup implements `todo-app restore <backup>`, replacing the
+// whole store with a backup once the user says so.
+func restoreBackup(path string, yes bool) error {
+	store, err := openRootStore()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	snap, err := readBackup(path)
+	if err != nil {
+		return err
+	}
+	if !yes {
+		fmt.Printf("Replace everything in the store with the backup made %s, of %d items? [y/N] ", snap.CreatedAt.In(todo.Location).Format("2006-01-02 15:04"), snap.Items())
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if a := strings.ToLower(strings.TrimSpace(answer)); a != "y" && a != "yes" {
+			fmt.Println("Nothing restored.")
+			return nil
+		}
+	}
+
+	// The store is backed up as it was first, so restoring can be undone
+	// by restoring that.
+	dir, err := backupDir("")
+	if err != nil {
+		return err
+	}
+	before := filepath.Join(dir, "before-restore-"+time.Now().Format(backupTime)+".json.gz")
+	if _, err := writeBackup(store, before); err != nil {
+		return fmt.Errorf("backing up the store before restoring: %w", err)
+	}
+	if err := snap.RestoreTo(store); err != nil {
+		return err
+	}
+	fmt.Printf("Restored %d items from %s. The store as it was is in %s\n", snap.Items(), path, before)
+	return nil
+}
+
+// backupDir returns the directory backups are kept in: dir if it is given,
+// and otherwise the backups directory beside the store's file.
+func backupDir(dir string) (string, error) {
+	if dir != "" {
+		return dir, nil
+	}
+	parent, err := storeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(parent, "backups"), nil
+}
+
+// autoBackup makes j back root up before its first change if automatic
+// backups are on.
+func autoBackup(j *todo.Journal, root todo.Store) error {
+	var settings backupSettings
+	err := todo.LoadMeta(root, backupKey, &settings)
+	if err != nil && !errors.Is(err, todo.ErrNoMeta) {
+		return err
+	}
+	if settings.Keep <= 0 {
+		return nil
+	}
+	j.BeforeChange(func() error {
+		dir, err := backupDir(settings.Dir)
+		if err != nil {
+			return err
+		}
+		path := filepath.Join(dir, "auto-"+time.Now().Format(backupTime)+".json.gz")
+		if _, err := writeBackup(root, path); err != nil {
+			return fmt.Errorf("backing up the store: %w", err)
+		}
+		return rotateBackups(dir, settings.Keep)
+	})
+	return nil
+}
+
+// rotateBackups deletes all but the last keep automatic backups in dir.
+func rotateBackups(dir string, keep int) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	var names []string
+	for _, e := range entries {
+		if name := e.Name(); strings.HasPrefix(name, "auto-") && strings.HasSuffix(name, ".json.gz") {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	for len(names) > keep {
+		if err := os.Remove(filepath.Join(dir, names[0])); err != nil {
+			return err
+		}
+		names = names[1:]
+	}
+	return nil
+}
+
+// writeBackup writes a snapshot of store to path, sealed with the store's
+// key if it is encrypted.
+func writeBackup(store todo.Store, path string) (*todo.Snapshot, error) {
+	var buf bytes.Buffer
+	snap, err := todo.WriteSnapshot(&buf, store)
+	if err != nil {
+		return nil, err
+	}
+	data := buf.Bytes()
+	if storeKey != nil {
+		box, err := storeKey.Seal(data)
+		if err != nil {
+			return nil, err
+		}
+		data = append([]byte(sealedBackup), box...)
+	}
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	tmp, err := os.CreateTemp(dir, ".backup-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return nil, err
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, err
+	}
+	return snap, os.Rename(tmp.Name(), path)
+}
+
+// readBackup reads a backup written by writeBackup, asking for its
+// passphrase if it is sealed and the store isn't encrypted with it.
+func readBackup(path string) (*todo.Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if box, ok := bytes.CutPrefix(data, []byte(sealedBackup)); ok {
+		key := storeKey
+		if key == nil {
+			secret, err := passphrase(false)
+			if err != nil {
+				return nil, err
+			}
+			if key, err = seal.NewKey(secret, nil); err != nil {
+				return nil, err
+			}
+		}
+		if data, err = key.Open(box); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return todo.ReadSnapshot(bytes.NewReader(data))
+}
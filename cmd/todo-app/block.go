@@ -0,0 +1,74 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/buck06191/todo-app/pkg/todo"
+)
+
+// blockCommand implements `todo-app block <id> -on <id>`, recording that the
+// first item can't be started until the second is done.
+func blockCommand() (*flag.FlagSet, func(args []string) error) {
+	return blockerCommand("block", func(store todo.Store, item todo.ParsedTodoItem, on int) error {
+		saved, err := store.List()
+		if err != nil {
+			return err
+		}
+		if err := todo.CheckBlocker(saved, item.ID, on); err != nil {
+			return err
+		}
+
+		item.BlockedBy = todo.AddBlocker(item.BlockedBy, on)
+		if err := store.Update(item); err != nil {
+			return err
+		}
+		fmt.Printf("%d now waits on %d\n", item.ID, on)
+		return nil
+	})
+}
+
+// unblockCommand implements `todo-app unblock <id> -on <id>`.
+func unblockCommand() (*flag.FlagSet, func(args []string) error) {
+	return blockerCommand("unblock", func(store todo.Store, item todo.ParsedTodoItem, on int) error {
+		item.BlockedBy = todo.RemoveBlocker(item.BlockedBy, on)
+		if err := store.Update(item); err != nil {
+			return err
+		}
+		fmt.Printf("%d no longer waits on %d\n", item.ID, on)
+		return nil
+	})
+}
+
+// blockerCommand parses the arguments shared by block and unblock and
+// calls change with the item being changed.
+func blockerCommand(name string, change func(store todo.Store, item todo.ParsedTodoItem, on int) error) (*flag.FlagSet, func(args []string) error) {
+	fs := newFlagSet(name, "<id> -on <id>")
+	on := fs.Int("on", 0, "ID of the item that has to be done first.")
+	return fs, func(args []string) error {
+		positional := parseInterspersed(fs, args)
+
+		if len(positional) != 1 || *on == 0 {
+			fs.Usage()
+			return errors.New(name + " takes one item ID and -on")
+		}
+
+		id, err := parseID(positional[0])
+		if err != nil {
+			return err
+		}
+
+		store, err := openStore()
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+
+		item, err := store.Get(id)
+		if err != nil {
+			return err
+		}
+		return change(store, item, *on)
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/buck06191/todo-app/pkg/caldav"
+	"github.com/buck06191/todo-app/pkg/todo"
+)
+
+// caldavCommand implements `todo-app caldav`, keeping the list in step with
+// a task list on a CalDAV server such as Nextcloud or Fastmail.
+func caldavCommand() (*flag.FlagSet, func(args []string) error) {
+	fs := newFlagSet("caldav", "[status | forget] [-url <collection>] [-user <name>] [-password <password>]")
+	collection := fs.String("url", "", "URL of the task list on the server, e.g. https://cloud.example.com/remote.php/dav/calendars/me/tasks/ on Nextcloud. Remembered for next time.")
+	user := fs.String("user", "", "User name to log in with, remembered along with -url.")
+	password := fs.String("password", "", "Password to log in with, remembered along with -url. Use an app password where the server has them. $TODO_CALDAV_PASSWORD is used instead if set, and isn't remembered.")
+	return fs, func(args []string) error {
+		positional := parseInterspersed(fs, args)
+
+		sub := "sync"
+		if len(positional) > 0 {
+			sub, positional = positional[0], positional[1:]
+		}
+		if len(positional) > 0 {
+			fs.Usage()
+			return fmt.Errorf("unexpected argument %q", positional[0])
+		}
+
+		store, err := openList()
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+		state, err := caldav.LoadState(store)
+		if err != nil {
+			return err
+		}
+
+		switch sub {
+		case "sync":
+			return caldavSync(store, &state, *collection, *user, *password)
+		case "status":
+			return caldavStatus(state)
+		case "forget":
+			if err := caldav.SaveState(store, caldav.State{}); err != nil {
+				return err
+			}
+			fmt.Println("Forgot the CalDAV state. The next sync will keep the items on both sides.")
+			return nil
+		}
+		fs.Usage()
+		return fmt.Errorf("unknown caldav command %q", sub)
+	}
+}
+
+func caldavSync(store todo.Store, state *caldav.State, collection, user, password string) error {
+	if collection != "" && state.URL != "" && collection != state.URL {
+		return fmt.Errorf("this list syncs with %s, run `todo-app caldav forget` first to sync it with %s instead", state.URL, collection)
+	}
+	if collection != "" {
+		state.URL = collection
+	}
+	if state.URL == "" {
+		return errors.New("caldav needs -url the first time, the URL of the task list on the server")
+	}
+	if user != "" {
+		state.User = user
+	}
+	if password != "" {
+		state.Password = password
+	}
+	if p := os.Getenv("TODO_CALDAV_PASSWORD"); p != "" {
+		password = p
+	} else {
+		password = state.Password
+	}
+
+	c, err := caldav.NewClient(state.URL, state.User, password)
+	if err != nil {
+		return err
+	}
+	res, err := caldav.Sync(store, c, state)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Synced with %s: pulled %d and pushed %d tasks, deleted %d\n", state.URL, res.Pulled, res.Pushed, res.Deleted)
+	if res.Conflicts > 0 {
+		fmt.Printf("Kept the values here for %d conflicting changes made on both sides\n", res.Conflicts)
+	}
+	return nil
+}
+
+func caldavStatus(state caldav.State) error {
+	if state.URL == "" {
+		fmt.Println("This list isn't synced with CalDAV. Start with: todo-app caldav -url <collection> -user <name> -password <password>")
+		return nil
+	}
+	fmt.Printf("Syncing with %s", state.URL)
+	if state.User != "" {
+		fmt.Printf(" as %s", state.User)
+	}
+	fmt.Println()
+	if state.Synced.IsZero() {
+		fmt.Println("Never synced")
+	} else {
+		fmt.Printf("Last synced %s\n", state.Synced.In(todo.Location).Format("2006-01-02 15:04"))
+	}
+	fmt.Printf("%d tasks synced\n", len(state.Tasks))
+	return nil
+}
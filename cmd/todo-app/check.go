@@ -0,0 +1,97 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/buck06191/todo-app/pkg/todo"
+)
+
+// The exit statuses of check when it finds something, after the 1 for
+// errors and 2 for bad usage that every command has.
+const (
+	checkOverdue exitCode = 3
+	checkDueSoon exitCode = 4
+)
+
+// checkCommand implements `todo-app check [-due-within 24h]`, for scripts
+// and shell prompts: it lists the open items that are overdue or due within
+// -due-within, and exits with checkOverdue if any are overdue, checkDueSoon
+// if the others are only due soon, and 0 if there are none.
+func checkCommand() (*flag.FlagSet, func(args []string) error) {
+	fs := newFlagSet("check", "[-due-within 24h] [-where <expr>] [-quiet]")
+	within := age(0)
+	fs.Var(&within, "due-within", "Also count items due within this long, e.g. 30m, 24h or 2d. (default only overdue ones)")
+	whereExpr := fs.String("where", "", "Only count items matching a filter expression, as for list.")
+	quiet := fs.Bool("quiet", false, "Don't list the items, only exit with the status.")
+	absolute := absoluteFlag(fs)
+	return fs, func(args []string) error {
+		fs.Parse(args)
+		if fs.NArg() > 0 {
+			fs.Usage()
+			return errors.New("check takes no arguments")
+		}
+		where, err := parseWhere(*whereExpr)
+		if err != nil {
+			return err
+		}
+
+		store, err := openList()
+		if err != nil {
+			return err
+		}
+		items, err := store.List()
+		store.Close()
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		found, overdue := checkItems(items, now, time.Duration(within), where)
+		if len(found) == 0 {
+			return nil
+		}
+		if !*quiet {
+			if err := (listPrinter{w: os.Stdout, now: now, absolute: *absolute}).print(found); err != nil {
+				return err
+			}
+		}
+		if overdue {
+			return checkOverdue
+		}
+		return checkDueSoon
+	}
+}
+
+// checkItems returns the open items that are overdue at now or due within
+// the time given after it, and whether any are overdue. Items due all day
+// today aren't overdue until the day is over, so with within 0 they are
+// left out.
+func checkItems(items []todo.ParsedTodoItem, now time.Time, within time.Duration, where *todo.Filter) (found []todo.ParsedTodoItem, overdue bool) {
+	for _, item := range items {
+		if item.Completed || item.Due.IsZero() {
+			continue
+		}
+		late := item.IsOverdue(now)
+		if !late && (within <= 0 || item.Due.After(now.Add(within))) {
+			continue
+		}
+		if where != nil && !where.Match(item, now) {
+			continue
+		}
+		found = append(found, item)
+		overdue = overdue || late
+	}
+	return found, overdue
+}
+
+// exitCode is returned by commands to exit with a status other than 0
+// without there being an error to report.
+type exitCode int
+
+func (c exitCode) Error() string {
+	return fmt.Sprintf("exit status %d", int(c))
+}
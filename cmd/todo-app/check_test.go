@@ -0,0 +1,41 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/buck06191/todo-app/pkg/todo"
+)
+
+func TestCheckItems(t *testing.T) {
+	now := time.Date(2025, 1, 10, 15, 0, 0, 0, time.UTC)
+	items := []todo.ParsedTodoItem{
+		{ID: 1, Todo: "Pay rent", Due: time.Date(2025, 1, 9, 0, 0, 0, 0, time.UTC)},
+		{ID: 2, Todo: "Buy milk", Due: time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)},
+		{ID: 3, Todo: "Call the bank", Due: time.Date(2025, 1, 10, 14, 0, 0, 0, time.UTC)},
+		{ID: 4, Todo: "Walk the dog", Due: time.Date(2025, 1, 10, 18, 0, 0, 0, time.UTC)},
+		{ID: 5, Todo: "Water the plants", Due: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), Completed: true},
+		{ID: 6, Todo: "Read a book"},
+	}
+	tests := []struct {
+		within  time.Duration
+		want    []int
+		overdue bool
+	}{
+		// Item 2 is due all day today, so isn't overdue yet.
+		{0, []int{1, 3}, true},
+		{time.Hour, []int{1, 2, 3}, true},
+		{4 * time.Hour, []int{1, 2, 3, 4}, true},
+	}
+	for _, tt := range tests {
+		found, overdue := checkItems(items, now, tt.within, nil)
+		var ids []int
+		for _, item := range found {
+			ids = append(ids, item.ID)
+		}
+		if !slices.Equal(ids, tt.want) || overdue != tt.overdue {
+			t.Errorf("checkItems within %s = %v, %v, want %v, %v", tt.within, ids, overdue, tt.want, tt.overdue)
+		}
+	}
+}
@@ -0,0 +1,455 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
+
+	"github.com/buck06191/todo-app/pkg/format"
+	"github.com/buck06191/todo-app/pkg/todo"
+)
+
+// command is a single `todo-app <name>` subcommand. Each command parses its
+// own arguments with its own flag set.
+type command struct {
+	name    string
+	summary string
+	setup   setupFunc
+}
+
+// setupFunc builds the flag set of a command, returning it along with the
+// function that parses the command's arguments with it and runs it.
+// Completion calls it for the flags alone.
+type setupFunc func() (*flag.FlagSet, func(args []string) error)
+
+// run runs the command with args.
+func (cmd *command) run(args []string) error {
+	return runCommand(cmd.setup, args)
+}
+
+// runCommand sets up a command, or a subcommand with flags of its own, and
+// runs it with args.
+func runCommand(setup setupFunc, args []string) error {
+	_, run := setup()
+	return run(args)
+}
+
+// commands lists every subcommand in the order they are shown in the usage
+// message.
+var commands = []command{
+	{name: "add", summary: "Add an item to the todo list", setup: addCommand},
+	{name: "list", summary: "Show the items on the todo list", setup: listCommand},
+	{name: "agenda", summary: "Show what is due this week or month as a calendar", setup: agendaCommand},
+	{name: "workload", summary: "Add up the estimates of what is due each day", setup: workloadCommand},
+	{name: "daemon", summary: "Stay up to remind of items before they are due", setup: daemonCommand},
+	{name: "check", summary: "Exit with a status saying whether anything is overdue or due soon", setup: checkCommand},
+	{name: "prompt", summary: "Sum up what is due in a few characters for a shell prompt", setup: promptCommand},
+	{name: "notify", summary: "Send desktop notifications for items coming due", setup: notifyCommand},
+	{name: "tui", summary: "Work through the list in a full screen interface", setup: tuiCommand},
+	{name: "in", summary: "Put a thought in the inbox, to be triaged later", setup: inCommand},
+	{name: "triage", summary: "Go through the inbox giving items due dates, projects and priorities", setup: triageCommand},
+	{name: "show", summary: "Show everything about one item", setup: showCommand},
+	{name: "history", summary: "Show every change made to an item", setup: historyCommand},
+	{name: "search", summary: "Find items by their text, notes or tags", setup: searchCommand},
+	{name: "done", summary: "Mark an item as done", setup: doneCommand},
+	{name: "start", summary: "Start the clock on an item, stopping it on any other", setup: startCommand},
+	{name: "stop", summary: "Stop the clock on the item being worked on", setup: stopCommand},
+	{name: "log", summary: "Record time spent on an item without the clock", setup: logCommand},
+	{name: "pomo", summary: "Work on an item for a pomodoro, with a countdown", setup: pomoCommand},
+	{name: "edit", summary: "Change the text or due date of an item", setup: editCommand},
+	{name: "snooze", summary: "Put an item off by moving its due date later", setup: snoozeCommand},
+	{name: "note", summary: "Write notes for an item in $EDITOR", setup: noteCommand},
+	{name: "rm", summary: "Move an item to the trash", setup: rmCommand},
+	{name: "restore", summary: "Restore an item from the trash", setup: restoreCommand},
+	{name: "undo", summary: "Reverse the last change made to the list", setup: undoCommand},
+	{name: "redo", summary: "Make the last undone change again", setup: redoCommand},
+	{name: "block", summary: "Mark an item as waiting on another one", setup: blockCommand},
+	{name: "unblock", summary: "Stop an item waiting on another one", setup: unblockCommand},
+	{name: "filter", summary: "Save, show or delete named filters for list", setup: filterCommand},
+	{name: "template", summary: "Save, show or delete templates to add items with", setup: templateCommand},
+	{name: "stats", summary: "Sum up what was added and done, and what is overdue", setup: statsCommand},
+	{name: "timesheet", summary: "Show the time spent on each project each day", setup: timesheetCommand},
+	{name: "tags", summary: "Show every tag with how many items have it", setup: tagsCommand},
+	{name: "archive", summary: "Move done items out of the list into an archive", setup: archiveCommand},
+	{name: "trash", summary: "Show or empty the trash", setup: trashCommand},
+	{name: "serve", summary: "Serve the todo list over HTTP as a JSON API", setup: serveCommand},
+	{name: "token", summary: "Create, show or revoke API tokens for serve", setup: tokenCommand},
+	{name: "user", summary: "Add, show or remove users with their own list on serve", setup: userCommand},
+	{name: "lists", summary: "Show, create, rename or delete the lists in the store", setup: listsCommand},
+	{name: "use", summary: "Switch every command from then on to another list", setup: useCommand},
+	{name: "share", summary: "Create shared lists and set who can use them", setup: shareCommand},
+	{name: "sync", summary: "Sync the list with a server to use it on several devices", setup: syncCommand},
+	{name: "digest", summary: "Email a summary of what is overdue and coming up", setup: digestCommand},
+	{name: "telegram", summary: "Run a Telegram bot for adding to and working through lists", setup: telegramCommand},
+	{name: "slack", summary: "Post what is due today to a Slack channel", setup: slackCommand},
+	{name: "caldav", summary: "Sync the list with a task list on a CalDAV server", setup: caldavCommand},
+	{name: "import", summary: "Add the items of a todo.txt or CSV file, or another todo app, to the list", setup: importCommand},
+	{name: "export", summary: "Write the list out as JSON, CSV, todo.txt or an iCalendar file", setup: exportCommand},
+	{name: "backup", summary: "Back the whole store up to a compressed file", setup: backupCommand},
+	{name: "config", summary: "Show or change the settings in the config file", setup: configCommand},
+	{name: "migrate", summary: "Copy the todo list into another store", setup: migrateCommand},
+}
+
+// findCommand returns the command called name, or nil if there isn't one.
+func findCommand(name string) *command {
+	for i := range commands {
+		if commands[i].name == name {
+			return &commands[i]
+		}
+	}
+	return nil
+}
+
+// usage prints the top level help message listing the global flags and the
+// available subcommands.
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "Usage: %s [flags] <command> [arguments]\n\nCommands:\n", filepath.Base(os.Args[0]))
+	width := 0
+	for _, cmd := range commands {
+		width = max(width, len(cmd.name))
+	}
+	for _, cmd := range commands {
+		fmt.Fprintf(out, "  %-*s %s\n", width, cmd.name, cmd.summary)
+	}
+	if names := plugins(); len(names) > 0 {
+		fmt.Fprintf(out, "\nPlugins, run as %s<command>:\n", pluginPrefix)
+		for _, name := range names {
+			fmt.Fprintf(out, "  %s\n", name)
+		}
+	}
+	fmt.Fprintf(out, "\nFlags:\n")
+	flag.PrintDefaults()
+}
+
+// newFlagSet returns a flag set for the named subcommand with a usage
+// message in the same shape as the top level one.
+func newFlagSet(name, args string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s %s %s\n", filepath.Base(os.Args[0]), name, args)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseInterspersed parses args with fs, allowing flags to come after
+// positional arguments as in `todo-app edit 3 -task "..."`. The positional
+// arguments are returned in order. Everything after a "--" is treated as
+// positional.
+func parseInterspersed(fs *flag.FlagSet, args []string) []string {
+	var positional []string
+	for {
+		fs.Parse(args)
+		rest := fs.Args()
+		if len(rest) == 0 {
+			return positional
+		}
+		if len(args) > len(rest) && args[len(args)-len(rest)-1] == "--" {
+			return append(positional, rest...)
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+}
+
+// stringList is a flag that can be given more than once, collecting every
+// value.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// target is a list to work on, as given by -list, -shared and -as.
+type target struct {
+	list, shared, as string
+}
+
+// flagTarget is the list given by the global flags.
+func flagTarget() target {
+	return target{list: *listName, shared: *sharedName, as: *asUser}
+}
+
+// openStore opens the list commands work on: the list given with -list or
+// the shared list given with -shared if there is one, and otherwise the
+// store itself. Its changes are journaled for undo and kept in the items'
+// histories as made by the command being run, and once it is closed items
+// added and done are sent to the webhooks and the post- hooks are run for
+// each change. The caller must close it.
+func openStore() (todo.Store, error) {
+	return openTarget(flagTarget(), operation)
+}
+
+// openTarget opens the list t as openStore does, journaling its changes
+// as made by op.
+func openTarget(t target, op string) (todo.Store, error) {
+	hooks, err := webhooks()
+	if err != nil {
+		return nil, err
+	}
+	j, err := openJournal(t, todo.NewJournal, op)
+	if err != nil {
+		return nil, err
+	}
+	if len(hooks) > 0 || hasPostHooks() {
+		j.AfterCommit(func(changes []todo.Change) {
+			sendChanges(hooks, changes)
+			runPostHooks(changes)
+		})
+	}
+	return j, nil
+}
+
+// openList opens the list openStore does without journaling the changes
+// made to it for undo, for undo and redo themselves, and for syncs, whose
+// changes were made elsewhere and are undone there. They are still kept in
+// the items' histories.
+func openList() (todo.Store, error) {
+	j, err := openJournal(flagTarget(), todo.NewRecorder, operation)
+	if err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+func openJournal(t target, journal func(todo.Store, string) *todo.Journal, op string) (*todo.Journal, error) {
+	var store, root todo.Store
+	var err error
+	switch {
+	case t.list != "" && t.list != mainList && t.shared != "":
+		return nil, errListAndShared
+	case t.list != "" && t.list != mainList:
+		var named *namedList
+		if named, err = openNamed(t.list); err == nil {
+			store, root = named, named.root
+		}
+	case t.shared != "":
+		store, err = openShared(t.shared, t.as)
+		if err == nil {
+			root = store.(*sharedList).root
+		}
+	default:
+		store, err = openRootStore()
+		root = store
+	}
+	if err != nil {
+		return nil, err
+	}
+	j := journal(store, op)
+	if err := autoBackup(j, root); err != nil {
+		store.Close()
+		return nil, err
+	}
+	return j, nil
+}
+
+// storeDir returns the directory the store's JSON file is in, where files
+// kept beside it such as the archive and backups go, or the one the default
+// store is in for stores that aren't files.
+func storeDir() (string, error) {
+	path := *storePath
+	if path == "" || strings.Contains(path, "://") {
+		var err error
+		if path, err = todo.DefaultPath(); err != nil {
+			return "", err
+		}
+	}
+	return filepath.Dir(path), nil
+}
+
+// openRootStore opens the store given with -store, falling back to the
+// JSON file in the default location, and unlocks it if it is encrypted.
+// Commands managing everything in the store use it rather than openStore.
+// The caller must close it.
+func openRootStore() (todo.Store, error) {
+	url := *storePath
+	if url == "" {
+		var err error
+		url, err = todo.DefaultPath()
+		if err != nil {
+			return nil, err
+		}
+	}
+	store, err := todo.Open(url)
+	if err != nil {
+		return nil, err
+	}
+	if err := unlock(store); err != nil {
+		store.Close()
+		return nil, err
+	}
+	return store, nil
+}
+
+// parseID parses an item ID given on the command line.
+func parseID(arg string) (int, error) {
+	id, err := strconv.Atoi(arg)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("%q is not a valid item ID", arg)
+	}
+	return id, nil
+}
+
+// parseIDs parses every argument as an item ID.
+func parseIDs(args []string) ([]int, error) {
+	var ids []int
+	for _, arg := range args {
+		id, err := parseID(arg)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// parseWhere parses the filter expression given with -where, returning nil
+// if there isn't one.
+func parseWhere(expr string) (*todo.Filter, error) {
+	if expr == "" {
+		return nil, nil
+	}
+	return todo.ParseFilter(expr)
+}
+
+// bulkFlags adds the -where and -dry-run flags of the commands that can
+// change many items at once, verb saying what they do to them.
+func bulkFlags(fs *flag.FlagSet, verb string) (where *string, dryRun *bool) {
+	where = fs.String("where", "", "Instead of item IDs, "+verb+" every item matching a filter expression such as 'tag:sprint-42'.")
+	dryRun = fs.Bool("dry-run", false, "Show the items that would be changed without changing them.")
+	return where, dryRun
+}
+
+// findItem returns the item arg names: the one with that ID if arg is a
+// number, and otherwise the one whose task best matches arg as todo.Fuzzy
+// matches it, looking at the items still to do before the done ones. When
+// several match as well as each other the user is asked which is meant.
+// Arguments starting with "-" are refused rather than matched, as they are
+// flags given in the wrong place.
+func findItem(store todo.Store, arg string) (todo.ParsedTodoItem, error) {
+	if _, err := strconv.Atoi(arg); err == nil {
+		id, err := parseID(arg)
+		if err != nil {
+			return todo.ParsedTodoItem{}, err
+		}
+		return store.Get(id)
+	}
+	if strings.HasPrefix(arg, "-") {
+		return todo.ParsedTodoItem{}, fmt.Errorf("%q isn't an item ID or text, it looks like a flag", arg)
+	}
+	saved, err := store.List()
+	if err != nil {
+		return todo.ParsedTodoItem{}, err
+	}
+	open := slices.DeleteFunc(slices.Clone(saved), func(item todo.ParsedTodoItem) bool { return item.Completed })
+	found := todo.Fuzzy(open, arg)
+	if len(found) == 0 {
+		found = todo.Fuzzy(saved, arg)
+	}
+	switch len(found) {
+	case 0:
+		return todo.ParsedTodoItem{}, fmt.Errorf("no item matches %q", arg)
+	case 1:
+		return found[0], nil
+	}
+	return chooseItem(arg, found)
+}
+
+// chooseItem asks at the terminal which of items, all matching query, is
+// meant. The question goes to standard error, so as not to end up in
+// output being piped somewhere.
+func chooseItem(query string, items []todo.ParsedTodoItem) (todo.ParsedTodoItem, error) {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		ids := make([]string, len(items))
+		for i, item := range items {
+			ids[i] = strconv.Itoa(item.ID)
+		}
+		return todo.ParsedTodoItem{}, fmt.Errorf("%d items match %q, give the ID of one of them: %s", len(items), query, strings.Join(ids, ", "))
+	}
+	fmt.Fprintf(os.Stderr, "%d items match %q:\n", len(items), query)
+	for i, item := range items {
+		fmt.Fprintf(os.Stderr, "  %d) %d %s\n", i+1, item.ID, item.Todo)
+	}
+	fmt.Fprintf(os.Stderr, "Which one? [1-%d] ", len(items))
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	n, err := strconv.Atoi(strings.TrimSpace(answer))
+	if err != nil || n < 1 || n > len(items) {
+		return todo.ParsedTodoItem{}, errors.New("no item chosen")
+	}
+	return items[n-1], nil
+}
+
+// selectItems returns the items a command that changes items acts on: the
+// ones args name, by ID or by their text as findItem finds them, or the
+// ones on the list matching where if it is given.
+func selectItems(store todo.Store, args []string, where string) ([]todo.ParsedTodoItem, error) {
+	if where == "" {
+		items := make([]todo.ParsedTodoItem, len(args))
+		for i, arg := range args {
+			var err error
+			if items[i], err = findItem(store, arg); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	}
+	if len(args) > 0 {
+		return nil, errors.New("give either item IDs or -where, not both")
+	}
+	filter, err := todo.ParseFilter(where)
+	if err != nil {
+		return nil, err
+	}
+	saved, err := store.List()
+	if err != nil {
+		return nil, err
+	}
+	var items []todo.ParsedTodoItem
+	now := time.Now()
+	for _, item := range saved {
+		if filter.Match(item, now) {
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+// printDryRun prints the items a command given -dry-run would change, and
+// how.
+func printDryRun(verb string, items []todo.ParsedTodoItem) error {
+	if len(items) == 0 {
+		fmt.Println("No items match")
+		return nil
+	}
+	fmt.Printf("Would %s %d items:\n", verb, len(items))
+	return PrintList(os.Stdout, items, time.Now())
+}
+
+// outputFlag adds the -output flag used by the commands that print items.
+func outputFlag(fs *flag.FlagSet) *string {
+	return fs.String("output", "", "Print the items as "+strings.Join(format.Names(), ", ")+" instead of in the usual format.")
+}
+
+// lookupOutput returns the renderer for the format given with -output, or
+// nil for the usual human readable output.
+func lookupOutput(name string) (format.Renderer, error) {
+	if name == "" {
+		return nil, nil
+	}
+	return format.Lookup(name)
+}
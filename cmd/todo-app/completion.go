@@ -0,0 +1,474 @@
+package main
+
+import (
+	"cmp"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/buck06191/todo-app/pkg/format"
+	"github.com/buck06191/todo-app/pkg/todo"
+)
+
+// completion is added to the commands here rather than in the table, which
+// it couldn't be in as it completes them.
+func init() {
+	commands = append(commands, command{name: "completion", summary: "Print a script completing commands, IDs, tags and lists in bash, zsh or fish", setup: completionCommand})
+}
+
+// completionCommand implements `todo-app completion bash|zsh|fish`,
+// printing a script that completes the commands and flags in that shell,
+// along with the item IDs, tags, contexts, projects and list names in the
+// store. The script runs `todo-app completion -complete` with the words
+// typed so far to find them, so they are always those of the store as it
+// is.
+func completionCommand() (*flag.FlagSet, func(args []string) error) {
+	fs := newFlagSet("completion", "bash | zsh | fish")
+	complete := fs.Bool("complete", false, "Print the ways the last of the arguments, the words after todo-app with the one being typed last, could be completed, one a line with a tab before any description. The scripts do this.")
+	return fs, func(args []string) error {
+		fs.Parse(args)
+
+		if *complete {
+			return completeWords(os.Stdout, fs.Args())
+		}
+		if fs.NArg() != 1 {
+			fs.Usage()
+			return errors.New("completion needs the shell to print the script for: bash, zsh or fish")
+		}
+		script, ok := completionScripts[fs.Arg(0)]
+		if !ok {
+			return fmt.Errorf("no completion for %q, only bash, zsh and fish", fs.Arg(0))
+		}
+		fmt.Print(script)
+		return nil
+	}
+}
+
+// completionScripts are the scripts completion prints for each shell. They
+// fall back to completing file names when -complete has nothing to offer,
+// and keep standard input away from the terminal so that an encrypted store
+// isn't unlocked by asking for its passphrase.
+var completionScripts = map[string]string{
+	"bash": `# bash completion for todo-app. Add to ~/.bashrc:
+#   source <(todo-app completion bash)
+
+_todo_app() {
+	# The line is split again on spaces, as bash splits words at @ and :
+	# too, and the start of the word bash has in mind is taken back off.
+	local line=${COMP_LINE:0:COMP_POINT} words
+	read -ra words <<<"$line"
+	[[ $line == *[[:space:]] ]] && words+=("")
+	local word=${words[${#words[@]}-1]}
+	local prefix=${word%"${COMP_WORDS[COMP_CWORD]}"}
+	mapfile -t COMPREPLY < <(todo-app completion -complete -- "${words[@]:1}" </dev/null 2>/dev/null | cut -f1)
+	COMPREPLY=("${COMPREPLY[@]#"$prefix"}")
+}
+
+complete -o default -F _todo_app todo-app
+`,
+	"zsh": `# zsh completion for todo-app. Add to ~/.zshrc, after compinit:
+#   source <(todo-app completion zsh)
+
+_todo_app() {
+	local -a completions
+	local line value
+	for line in "${(@f)$(todo-app completion -complete -- "${(@)words[2,CURRENT]}" </dev/null 2>/dev/null)}"; do
+		[[ -n $line ]] || continue
+		value=${${line%%$'\t'*}//:/\\:}
+		if [[ $line == *$'\t'* ]]; then
+			completions+=("$value:${line#*$'\t'}")
+		else
+			completions+=("$value")
+		fi
+	done
+	if (( ${#completions} )); then
+		_describe todo-app completions
+	else
+		_files
+	fi
+}
+
+compdef _todo_app todo-app
+`,
+	"fish": `# fish completion for todo-app. Add to ~/.config/fish/config.fish:
+#   todo-app completion fish | source
+
+function __todo_app_complete
+	set -l words (commandline -opc)
+	set -l current (commandline -ct)
+	set -l completions (todo-app completion -complete -- $words[2..-1] "$current" </dev/null 2>/dev/null)
+	if test (count $completions) -eq 0
+		__fish_complete_path "$current"
+	else
+		printf '%s\n' $completions
+	end
+end
+
+complete -c todo-app -f -a '(__todo_app_complete)'
+`,
+}
+
+// subcommands are the words that can follow each command, and each of
+// those that has words of its own after it.
+var subcommands = map[string][]string{
+	"archive":      {"list", "search"},
+	"caldav":       {"status", "forget"},
+	"completion":   {"bash", "zsh", "fish"},
+	"config":       {"get", "set", "unset", "list", "path"},
+	"digest":       {"email"},
+	"filter":       {"list", "save", "rm"},
+	"import":       {"trello", "mstodo"},
+	"lists":        {"list", "create", "rename", "delete"},
+	"share":        {"list", "create", "add", "rm", "delete"},
+	"slack":        {"digest"},
+	"sync":         {"status", "conflicts", "forget", "keygen", "todoist", "google"},
+	"sync google":  {"login", "status", "forget"},
+	"sync todoist": {"status", "forget"},
+	"template":     {"list", "show", "save", "rm"},
+	"token":        {"list", "create", "revoke"},
+	"user":         {"list", "add", "rm"},
+}
+
+// ownFlags are the subcommands parsing their flags with a flag set of
+// their own rather than the command's, with how to set them up.
+var ownFlags = map[string]setupFunc{
+	"archive list":   archiveListCommand,
+	"archive search": archiveSearchCommand,
+	"import mstodo":  importMSTodoCommand,
+	"import trello":  importTrelloCommand,
+	"sync google":    syncGoogleCommand,
+	"sync todoist":   syncTodoistCommand,
+}
+
+// idCommands are the commands taking item IDs, with how many, 0 meaning
+// any number.
+var idCommands = map[string]int{
+	"block": 1, "done": 0, "edit": 1, "history": 1, "log": 1, "note": 1, "pomo": 1,
+	"restore": 0, "rm": 0, "show": 1, "snooze": 1, "start": 1, "stop": 1, "unblock": 1,
+}
+
+// completeWords prints to w the ways the last of words, the words after
+// todo-app on the command line, could be completed. The global flags among
+// them are set first, so that the store and list they name are the ones
+// the IDs, tags and lists come from.
+func completeWords(w io.Writer, words []string) error {
+	if len(words) == 0 {
+		words = []string{""}
+	}
+	words, cur := words[:len(words)-1], words[len(words)-1]
+	c := completer{w: w, cur: cur}
+
+	for len(words) > 0 && strings.HasPrefix(words[0], "-") && words[0] != "-" {
+		name, value, hasValue := flagWord(words[0])
+		f := flag.CommandLine.Lookup(name)
+		if f == nil {
+			return nil
+		}
+		switch {
+		case hasValue:
+			words = words[1:]
+		case isBoolFlag(f):
+			value, words = "true", words[1:]
+		case len(words) == 1:
+			return c.flagValues(name)
+		default:
+			value, words = words[1], words[2:]
+		}
+		if err := flag.Set(name, value); err != nil {
+			return nil
+		}
+	}
+	if len(words) == 0 {
+		if strings.HasPrefix(cur, "-") {
+			return c.flags(flag.CommandLine)
+		}
+		for _, cmd := range commands {
+			c.offer(cmd.name, cmd.summary)
+		}
+		for _, name := range plugins() {
+			c.offer(name, "Plugin "+pluginPrefix+name)
+		}
+		return nil
+	}
+
+	cmd := findCommand(words[0])
+	if cmd == nil {
+		return nil
+	}
+	args := words[1:]
+	setup := cmd.setup
+	if len(args) > 0 && ownFlags[cmd.name+" "+args[0]] != nil {
+		setup = ownFlags[cmd.name+" "+args[0]]
+	}
+	fs, _ := setup()
+
+	var positional []string
+	for i := 0; i < len(args); i++ {
+		if args[i] == "--" {
+			positional = append(positional, args[i+1:]...)
+			break
+		}
+		name, _, hasValue := flagWord(args[i])
+		if name == "" {
+			positional = append(positional, args[i])
+			continue
+		}
+		if f := fs.Lookup(name); f != nil && !hasValue && !isBoolFlag(f) {
+			if i == len(args)-1 {
+				return c.flagValues(name)
+			}
+			i++
+		}
+	}
+	if strings.HasPrefix(cur, "-") {
+		return c.flags(fs)
+	}
+
+	path := cmd.name
+	for len(positional) > 0 && slices.Contains(subcommands[path], positional[0]) {
+		path += " " + positional[0]
+		positional = positional[1:]
+	}
+	switch {
+	case cmd.name == "add" || cmd.name == "list" || cmd.name == "search":
+		switch {
+		case strings.HasPrefix(cur, "#"):
+			return c.tags("#")
+		case strings.HasPrefix(cur, "@"):
+			return c.items(func(item todo.ParsedTodoItem) []string { return item.Contexts }, "@")
+		case strings.HasPrefix(cur, "+"):
+			return c.items(func(item todo.ParsedTodoItem) []string { return []string{item.Project} }, "+")
+		}
+	case len(positional) > 0:
+		if n, ok := idCommands[path]; ok && n == 0 {
+			return c.ids(path)
+		}
+	case subcommands[path] != nil:
+		for _, word := range subcommands[path] {
+			c.offer(word, "")
+		}
+	case path == "config get" || path == "config set" || path == "config unset":
+		for _, s := range settings {
+			c.offer(s.key, firstSentence(s.usage))
+		}
+	case path == "use":
+		return c.lists(true)
+	case path == "lists rename" || path == "lists delete":
+		return c.lists(false)
+	case path == "template show" || path == "template save" || path == "template rm":
+		return c.templates()
+	default:
+		if _, ok := idCommands[path]; ok {
+			return c.ids(path)
+		}
+	}
+	return nil
+}
+
+// flagWord splits a word on the command line into the name of the flag it
+// is and any value given with =, returning "" for words that aren't flags.
+func flagWord(word string) (name, value string, hasValue bool) {
+	if !strings.HasPrefix(word, "-") || word == "-" || word == "--" {
+		return "", "", false
+	}
+	name = strings.TrimPrefix(strings.TrimPrefix(word, "-"), "-")
+	name, value, hasValue = strings.Cut(name, "=")
+	return name, value, hasValue
+}
+
+// isBoolFlag reports whether f is given without a value, as -dry-run is.
+func isBoolFlag(f *flag.Flag) bool {
+	b, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
+
+// completer prints the completions of the word cur.
+type completer struct {
+	w   io.Writer
+	cur string
+}
+
+// offer prints value if it completes the word, with a description if there
+// is one.
+func (c completer) offer(value, description string) {
+	if !strings.HasPrefix(value, c.cur) {
+		return
+	}
+	if description == "" {
+		fmt.Fprintln(c.w, value)
+		return
+	}
+	fmt.Fprintf(c.w, "%s\t%s\n", value, description)
+}
+
+// flags offers the flags of fs, described by their usage.
+func (c completer) flags(fs *flag.FlagSet) error {
+	fs.VisitAll(func(f *flag.Flag) {
+		c.offer("-"+f.Name, firstSentence(f.Usage))
+	})
+	return nil
+}
+
+// firstSentence returns the first sentence of a usage message, without its
+// full stop, to describe a completion in a few words.
+func firstSentence(usage string) string {
+	sentence, _, _ := strings.Cut(usage, ". ")
+	return strings.TrimSuffix(sentence, ".")
+}
+
+// flagValues offers the values of the flag called name, for those whose
+// values can be known.
+func (c completer) flagValues(name string) error {
+	switch name {
+	case "list":
+		return c.lists(true)
+	case "tag":
+		return c.tags("")
+	case "context":
+		return c.items(func(item todo.ParsedTodoItem) []string { return item.Contexts }, "")
+	case "project":
+		return c.items(func(item todo.ParsedTodoItem) []string { return []string{item.Project} }, "")
+	case "on":
+		return c.ids("block")
+	case "priority":
+		for _, p := range []string{"low", "medium", "high"} {
+			c.offer(p, "")
+		}
+	case "output":
+		for _, name := range format.Names() {
+			c.offer(name, "")
+		}
+	case "sort":
+		for _, key := range todo.SortKeys() {
+			c.offer(key, "")
+		}
+	case "template":
+		return c.templates()
+	}
+	return nil
+}
+
+// openItems returns the items on the list that aren't done yet.
+func openItems() ([]todo.ParsedTodoItem, error) {
+	store, err := openList()
+	if err != nil {
+		return nil, err
+	}
+	defer store.Close()
+	items, err := store.List()
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(items, func(item todo.ParsedTodoItem) bool { return item.Completed }), nil
+}
+
+// ids offers the IDs of the items the command called name works on, each
+// described by its text: those in the trash for restore, every item for
+// show and history, and otherwise the items still to do.
+func (c completer) ids(name string) error {
+	store, err := openList()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	var items []todo.ParsedTodoItem
+	if name == "restore" {
+		items, err = store.Trash()
+	} else {
+		items, err = store.List()
+	}
+	if err != nil {
+		return err
+	}
+	slices.SortFunc(items, func(a, b todo.ParsedTodoItem) int { return cmp.Compare(a.ID, b.ID) })
+	for _, item := range items {
+		if !item.Completed || name == "show" || name == "history" || name == "restore" {
+			c.offer(strconv.Itoa(item.ID), item.Todo)
+		}
+	}
+	return nil
+}
+
+// tags offers the tags of the items still to do, the most used first, each
+// after prefix.
+func (c completer) tags(prefix string) error {
+	items, err := openItems()
+	if err != nil {
+		return err
+	}
+	for _, count := range todo.CountTags(items) {
+		items := "1 item"
+		if count.Count != 1 {
+			items = fmt.Sprintf("%d items", count.Count)
+		}
+		c.offer(prefix+count.Tag, items)
+	}
+	return nil
+}
+
+// items offers the values words finds in the items still to do, such as
+// their contexts or projects, sorted and each after prefix.
+func (c completer) items(words func(todo.ParsedTodoItem) []string, prefix string) error {
+	items, err := openItems()
+	if err != nil {
+		return err
+	}
+	var values []string
+	for _, item := range items {
+		for _, word := range words(item) {
+			if word != "" {
+				values = append(values, word)
+			}
+		}
+	}
+	slices.Sort(values)
+	for _, value := range slices.Compact(values) {
+		c.offer(prefix+value, "")
+	}
+	return nil
+}
+
+// lists offers the names of the lists in the store, and that of the main
+// list too if withMain is set.
+func (c completer) lists(withMain bool) error {
+	root, err := openRootStore()
+	if err != nil {
+		return err
+	}
+	defer root.Close()
+	names, err := listNames(root)
+	if err != nil {
+		return err
+	}
+	if withMain {
+		c.offer(mainList, "the store's own list")
+	}
+	for _, name := range names {
+		c.offer(name, "")
+	}
+	return nil
+}
+
+// templates offers the names of the templates saved on the list, each
+// described by what it fills in.
+func (c completer) templates() error {
+	store, err := openList()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	templates, err := todo.Templates(store)
+	if err != nil {
+		return err
+	}
+	for _, name := range slices.Sorted(maps.Keys(templates)) {
+		c.offer(name, describeTemplate(templates[name]))
+	}
+	return nil
+}
@@ -0,0 +1,424 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/buck06191/todo-app/pkg/config"
+	"github.com/buck06191/todo-app/pkg/todo"
+	"github.com/buck06191/todo-app/pkg/webhook"
+)
+
+// userConfig is the config file, read before the command is run.
+var userConfig *config.File
+
+// alwaysColor is set by setting color to always, to color the output even
+// when it isn't a terminal.
+var alwaysColor bool
+
+// setting is one of the keys the config file can set, all of them to
+// strings.
+type setting struct {
+	key   string
+	usage string
+	// check, if set, checks a value before config set saves it and when
+	// the file is read.
+	check func(value string) error
+}
+
+// settings are the keys the config file can set, each of which can also
+// be set with the environment variable envName gives it. Keys that aren't
+// known are left alone, so that older todo-apps can share the file.
+var settings = []setting{
+	{key: "store", usage: "Where the todo list is saved, as with -store."},
+	{key: "timezone", usage: "Time zone to read and show due dates in, as with -tz.", check: func(value string) error {
+		_, err := time.LoadLocation(value)
+		return err
+	}},
+	{key: "date_format", usage: "How dates are shown, as a Go time layout such as 02/01/2006 or Jan 2 2006.", check: func(value string) error {
+		if strings.TrimSpace(value) == "" {
+			return errors.New("the date format can't be empty")
+		}
+		return nil
+	}},
+	{key: "list", usage: "The list to work on unless -list is given, see the lists command.", check: func(value string) error {
+		if value == mainList {
+			return nil
+		}
+		return validListName(value)
+	}},
+	{key: "shared", usage: "The shared list to work on unless -shared is given."},
+	{key: "color", usage: "Whether to color the output: auto, always or never.", check: func(value string) error {
+		switch value {
+		case "auto", "always", "never":
+			return nil
+		}
+		return fmt.Errorf("expected auto, always or never, not %q", value)
+	}},
+	{key: "priority.escalate", usage: "How soon before items are due to raise their priority for sorting and showing, e.g. medium=3d,high=1d, with 0 for once they are overdue.", check: func(value string) error {
+		_, err := todo.ParseEscalation(value)
+		return err
+	}},
+	{key: "holidays", usage: "A file of holidays for repeat rules skipping them to move off, with a date such as 2025-12-25, or 12-25 for every year, on each line.", check: func(value string) error {
+		_, err := readHolidays(value)
+		return err
+	}},
+	{key: "remotes.<name>", usage: "URL of a server to sync with as todo-app sync -remote <name>."},
+	{key: "webhooks.<name>.url", usage: "URL to POST items to as they are added, done or come to be overdue, see the README."},
+	{key: "webhooks.<name>.secret", usage: "Secret the webhook's payloads are signed with, in the X-Todo-Signature header as sha256=<HMAC-SHA256 of the body in hex>."},
+	{key: "webhooks.<name>.events", usage: "The events the webhook is sent, separated by commas: add, done and overdue. (default all of them)", check: func(value string) error {
+		_, err := webhook.ParseEvents(value)
+		return err
+	}},
+	{key: "notify.within", usage: "How long before items are due notify tells of them, as with its -within.", check: func(value string) error {
+		var a age
+		return a.Set(value)
+	}},
+	{key: "remind", usage: "How long before items are due the daemon reminds of them, as with its -remind.", check: func(value string) error {
+		_, err := todo.ParseOffsets(value)
+		return err
+	}},
+	{key: "pomo.length", usage: "How long pomo's countdown runs for, as with its -length.", check: func(value string) error {
+		var a age
+		return a.Set(value)
+	}},
+	{key: "workload.capacity", usage: "How much estimated work fits in a day before workload warns, as with its -capacity.", check: func(value string) error {
+		var a age
+		return a.Set(value)
+	}},
+	{key: "serve.listen", usage: "Address serve listens on, as with its -listen."},
+	{key: "serve.grpc", usage: "Address serve serves the gRPC API on, as with its -grpc."},
+	{key: "serve.tokens", usage: "API tokens serve accepts as well as those made with the token command, separated by commas."},
+	{key: "smtp.server", usage: "Host and port of the SMTP server digest email sends through, e.g. smtp.example.com:587."},
+	{key: "smtp.username", usage: "User name to log in to the SMTP server with, if it needs one."},
+	{key: "smtp.password", usage: "Password to log in to the SMTP server with."},
+	{key: "digest.to", usage: "Addresses digest email sends to, separated by commas, as with its -to."},
+	{key: "digest.from", usage: "Address digest email sends from, as with its -from. (default smtp.username)"},
+	{key: "digest.days", usage: "How many days after today digest email tells of, as with its -days.", check: func(value string) error {
+		if n, err := strconv.Atoi(value); err != nil || n < 0 {
+			return fmt.Errorf("%q is not a number of days", value)
+		}
+		return nil
+	}},
+	{key: "digest.at", usage: "Time of day the daemon emails the digest at, as with its -digest.", check: func(value string) error {
+		_, _, err := parseDigestAt(value)
+		return err
+	}},
+	{key: "slack.webhook", usage: "URL of the Slack incoming webhook slack digest posts to, as with its -webhook."},
+	{key: "slack.signing_secret", usage: "Signing secret of the Slack app whose slash command serve answers at /slack/command."},
+	{key: "slack.list", usage: "Shared list the Slack slash command works on, as with serve's -slack-list. (default the store's own list)"},
+	{key: "telegram.token", usage: "Token of the bot telegram runs as, as with its -token."},
+	{key: "telegram.chats.<name>.list", usage: "The list the telegram bot works on for the chat with this ID, as with -list."},
+	{key: "telegram.chats.<name>.shared", usage: "The shared list the telegram bot works on for the chat with this ID, as with -shared."},
+	{key: "telegram.chats.<name>.user", usage: "User the telegram bot acts as on the chat's shared list, as with -as."},
+}
+
+// findSetting returns the setting for key, or an error naming them all if
+// there isn't one.
+func findSetting(key string) (*setting, error) {
+	for i, s := range settings {
+		if key == s.key {
+			return &settings[i], nil
+		}
+		// The name in a key such as webhooks.<name>.url can't have dots in
+		// it, while one at the end, as in remotes.<name>, can.
+		prefix, suffix, isTable := strings.Cut(s.key, "<name>")
+		if name, ok := strings.CutPrefix(key, prefix); isTable && ok {
+			if name, ok = strings.CutSuffix(name, suffix); ok && name != "" && (suffix == "" || !strings.Contains(name, ".")) {
+				return &settings[i], nil
+			}
+		}
+	}
+	var keys []string
+	for _, s := range settings {
+		keys = append(keys, s.key)
+	}
+	return nil, fmt.Errorf("unknown setting %q, expected one of %s", key, strings.Join(keys, ", "))
+}
+
+// configFile returns the path of the config file: -config or $TODO_CONFIG
+// if either is given, and otherwise config.toml in
+// $XDG_CONFIG_HOME/todo-app, or in ~/.config/todo-app without it.
+func configFile() (string, error) {
+	if *configPath != "" {
+		return *configPath, nil
+	}
+	if path := os.Getenv("TODO_CONFIG"); path != "" {
+		return path, nil
+	}
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "todo-app", "config.toml"), nil
+}
+
+// loadConfig reads the config file into userConfig and uses the settings
+// in it and the environment for the global flags that weren't given.
+func loadConfig() error {
+	path, err := configFile()
+	if err != nil {
+		return err
+	}
+	if userConfig, err = config.Load(path); err != nil {
+		return err
+	}
+
+	given := flagsGiven(flag.CommandLine)
+	err = flagDefaults(flag.CommandLine, map[string]string{"store": "store", "tz": "timezone", "list": "list", "shared": "shared"})
+	if err != nil {
+		return err
+	}
+	// Either of -list and -shared on the command line wins over a default
+	// for the other.
+	if given["shared"] && !given["list"] {
+		*listName = ""
+	}
+	if given["list"] && !given["shared"] {
+		*sharedName = ""
+	}
+	// A store written by hand may well be under ~, which the shell would
+	// otherwise have expanded.
+	if rest, ok := strings.CutPrefix(*storePath, "~/"); ok {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return err
+		}
+		*storePath = filepath.Join(home, rest)
+	}
+	if v, ok, err := settingValue("date_format"); err != nil {
+		return err
+	} else if ok {
+		dateFormat = v
+	}
+	if v, ok, err := settingValue("priority.escalate"); err != nil {
+		return err
+	} else if ok {
+		todo.Escalate, _ = todo.ParseEscalation(v)
+	}
+	if v, ok, err := settingValue("holidays"); err != nil {
+		return err
+	} else if ok {
+		if todo.Holidays, err = readHolidays(v); err != nil {
+			return err
+		}
+	}
+	v, _, err := settingValue("color")
+	switch {
+	case err != nil:
+		return err
+	case v == "never" && !given["no-color"]:
+		*noColor = true
+	case v == "always":
+		alwaysColor = true
+	}
+	return nil
+}
+
+// readHolidays reads the holiday calendar in the file at path, which can
+// start with ~/.
+func readHolidays(path string) (todo.HolidayCalendar, error) {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		path = filepath.Join(home, rest)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	calendar, err := todo.ReadHolidays(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return calendar, nil
+}
+
+// envName returns the environment variable that sets key, such as
+// TODO_DATE_FORMAT for date_format and TODO_REMOTES_HOME for remotes.home.
+func envName(key string) string {
+	return "TODO_" + strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(key))
+}
+
+// settingValue returns the value of the setting key, from its environment
+// variable if that is set and otherwise from the config file, and whether
+// it is set at all.
+func settingValue(key string) (string, bool, error) {
+	v, ok := os.LookupEnv(envName(key))
+	where := "$" + envName(key)
+	if !ok {
+		var err error
+		if v, ok, err = userConfig.String(key); err != nil || !ok {
+			return "", false, err
+		}
+		where, _ = configFile()
+	}
+	if s, _ := findSetting(key); s != nil && s.check != nil {
+		if err := s.check(v); err != nil {
+			return "", false, fmt.Errorf("%s: %s: %w", where, key, err)
+		}
+	}
+	return v, true, nil
+}
+
+// flagDefaults sets the flags of fs that weren't given on the command line
+// from the settings keys gives for them, by flag name, so that flags win
+// over the environment and it over the config file.
+func flagDefaults(fs *flag.FlagSet, keys map[string]string) error {
+	given := flagsGiven(fs)
+	for name, key := range keys {
+		v, ok, err := settingValue(key)
+		if err != nil {
+			return err
+		}
+		if ok && !given[name] {
+			if err := fs.Set(name, v); err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+		}
+	}
+	return nil
+}
+
+// flagsGiven returns the names of the flags of fs given on the command
+// line.
+func flagsGiven(fs *flag.FlagSet) map[string]bool {
+	given := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) {
+		given[f.Name] = true
+	})
+	return given
+}
+
+// configCommand implements `todo-app config get|set|unset|list|path`, for
+// changing the config file without editing it by hand. get shows the
+// setting's environment variable instead if it is set.
+func configCommand() (*flag.FlagSet, func(args []string) error) {
+	fs := newFlagSet("config", "get <key> | set <key> <value> | unset <key> | list | path")
+	usage := fs.Usage
+	fs.Usage = func() {
+		usage()
+		fmt.Fprintf(fs.Output(), "\nSettings:\n")
+		width := 0
+		for _, s := range settings {
+			width = max(width, len(s.key))
+		}
+		for _, s := range settings {
+			fmt.Fprintf(fs.Output(), "  %-*s %s\n", width, s.key, s.usage)
+		}
+	}
+	return fs, func(args []string) error {
+		positional := parseInterspersed(fs, args)
+		if len(positional) == 0 {
+			fs.Usage()
+			return errors.New("config needs a command")
+		}
+		path, err := configFile()
+		if err != nil {
+			return err
+		}
+
+		sub, positional := positional[0], positional[1:]
+		want := map[string]int{"get": 1, "set": 2, "unset": 1, "list": 0, "path": 0}
+		n, ok := want[sub]
+		if !ok {
+			fs.Usage()
+			return fmt.Errorf("unknown config command %q", sub)
+		}
+		if len(positional) != n {
+			fs.Usage()
+			return fmt.Errorf("config %s takes %d arguments", sub, n)
+		}
+
+		switch sub {
+		case "path":
+			fmt.Println(path)
+			return nil
+		case "list":
+			keys := userConfig.Keys()
+			if len(keys) == 0 {
+				fmt.Printf("Nothing is set in %s\n", path)
+			}
+			for _, key := range keys {
+				v, _ := userConfig.Get(key)
+				if _, ok := os.LookupEnv(envName(key)); ok {
+					fmt.Printf("%s = %s  # overridden by $%s\n", key, config.Format(v), envName(key))
+					continue
+				}
+				fmt.Printf("%s = %s\n", key, config.Format(v))
+			}
+			return nil
+		}
+
+		key := positional[0]
+		s, err := findSetting(key)
+		if err != nil {
+			return err
+		}
+		switch sub {
+		case "get":
+			v, ok, err := settingValue(key)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return fmt.Errorf("%s isn't set", key)
+			}
+			fmt.Println(v)
+			return nil
+		case "unset":
+			ok, err := userConfig.Unset(key)
+			if err != nil || !ok {
+				return err
+			}
+			return userConfig.Save(path)
+		}
+
+		v := positional[1]
+		if s.check != nil {
+			if err := s.check(v); err != nil {
+				return fmt.Errorf("bad %s: %w", key, err)
+			}
+		}
+		// A relative path is made absolute, since the config is used from
+		// any directory.
+		if key == "store" && !strings.Contains(v, "://") {
+			if v, err = filepath.Abs(v); err != nil {
+				return err
+			}
+		}
+		if err := userConfig.Set(key, v); err != nil {
+			return err
+		}
+		return userConfig.Save(path)
+	}
+}
+
+// resolveRemote returns the URL of the named remote in the config file, or
+// remote itself if it is a URL already.
+func resolveRemote(remote string) (string, error) {
+	if remote == "" || strings.Contains(remote, "://") {
+		return remote, nil
+	}
+	url, ok, err := settingValue("remotes." + remote)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", fmt.Errorf("no remote called %q, give a URL or add it with todo-app config set remotes.%s <url>", remote, remote)
+	}
+	return url, nil
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"reflect"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/buck06191/todo-app/pkg/notify"
+	"github.com/buck06191/todo-app/pkg/todo"
+	"github.com/buck06191/todo-app/pkg/webhook"
+)
+
+// defaultRemind is when the daemon reminds of items without the remind
+// setting.
+const defaultRemind = "10m"
+
+// offsets is a flag.Value for the reminder times -remind takes.
+type offsets []time.Duration
+
+func (o *offsets) String() string {
+	return todo.FormatOffsets(*o)
+}
+
+func (o *offsets) Set(s string) error {
+	parsed, err := todo.ParseOffsets(s)
+	if err != nil {
+		return err
+	}
+	*o = parsed
+	return nil
+}
+
+// remindFlag adds the -remind flag giving how long before items are due to
+// remind of them.
+func remindFlag(fs *flag.FlagSet) *offsets {
+	remind := &offsets{}
+	remind.Set(defaultRemind)
+	fs.Var(remind, "remind", "How long before items are due to remind of them, separated by commas, e.g. 1h,10m or 1d,0 for a day before and when due. Items due all day are due at 9:00 for this. Items added with their own -remind times use those instead.")
+	return remind
+}
+
+// daemonCommand implements `todo-app daemon`, staying up to send a desktop
+// notification at each of the -remind times before an item is due, or at
+// the item's own reminder times if it was given some, and to tell the
+// webhooks of items as they come to be overdue. With -digest, it also
+// emails the digest each day as `todo-app digest email` does. The list is
+// read again every -refresh, to pick up the changes made to it.
+func daemonCommand() (*flag.FlagSet, func(args []string) error) {
+	fs := newFlagSet("daemon", "[-remind 1h,10m] [-refresh 30s] [-digest 7:30]")
+	remind := remindFlag(fs)
+	refresh := fs.Duration("refresh", 30*time.Second, "How often to read the list again to pick up changes made to it.")
+	digestAt := fs.String("digest", "", "Also email the digest each day at this time, e.g. 7:30, to digest.to as digest email does.")
+	return fs, func(args []string) error {
+		fs.Parse(args)
+		if fs.NArg() > 0 {
+			fs.Usage()
+			return errors.New("daemon takes no arguments")
+		}
+		if err := flagDefaults(fs, map[string]string{"remind": "remind", "digest": "digest.at"}); err != nil {
+			return err
+		}
+		if *refresh <= 0 {
+			return errors.New("-refresh has to be more than 0")
+		}
+
+		hooks, err := webhooks()
+		if err != nil {
+			return err
+		}
+		var digestHour, digestMin, digestDays int
+		var digestTime time.Time
+		var to []string
+		if *digestAt != "" {
+			if digestHour, digestMin, err = parseDigestAt(*digestAt); err != nil {
+				return err
+			}
+			if to, err = digestTo(); err != nil {
+				return err
+			}
+			digestDays = defaultDigestDays
+			if v, ok, err := settingValue("digest.days"); err != nil {
+				return err
+			} else if ok {
+				if digestDays, err = strconv.Atoi(v); err != nil || digestDays < 0 {
+					return fmt.Errorf("digest.days: %q is not a number of days", v)
+				}
+			}
+			digestTime = nextDigest(digestHour, digestMin, time.Now())
+			log.Printf("Emailing the digest to %s each day at %s, first on %s", strings.Join(to, ", "), *digestAt, digestTime.Format("Mon 2 Jan"))
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		log.Printf("Reminding of items %s before they are due, unless they say otherwise", remind)
+		var items []todo.ParsedTodoItem
+		loaded := false
+		last := time.Now()
+		for {
+			// The store is only open while it is read, since the bolt store
+			// can't be opened by other commands while anything has it open.
+			latest, err := loadItems()
+			if err != nil {
+				log.Printf("Reading the list: %v", err)
+			} else {
+				if loaded && !reflect.DeepEqual(items, latest) {
+					log.Printf("The list has changed, rescheduling")
+				}
+				items, loaded = latest, true
+			}
+
+			now := time.Now()
+			for _, r := range todo.Reminders(items, *remind, last, now) {
+				n := itemNotification(r.Item, now)
+				if err := notify.Send(n); err != nil {
+					log.Printf("Reminding of %d: %v", r.Item.ID, err)
+					continue
+				}
+				log.Printf("Reminded of %d %s: %s", r.Item.ID, n.Title, n.Body)
+			}
+			if len(hooks) > 0 {
+				for _, item := range items {
+					if item.IsOverdue(now) && !item.IsOverdue(last) {
+						sendEvent(hooks, webhook.NewPayload(webhook.EventOverdue, item, now))
+						log.Printf("Sent the webhooks that %d %s is overdue", item.ID, item.Todo)
+					}
+				}
+			}
+			if !digestTime.IsZero() && !now.Before(digestTime) {
+				if err := sendDailyDigest(digestDays, to); err != nil {
+					log.Printf("Emailing the digest: %v", err)
+				}
+				digestTime = nextDigest(digestHour, digestMin, now)
+			}
+			last = now
+
+			wait := *refresh
+			if !digestTime.IsZero() && digestTime.Sub(now) < wait {
+				wait = digestTime.Sub(now)
+			}
+			if next, ok := todo.NextReminder(items, *remind, now); ok && next.Sub(now) < wait {
+				wait = next.Sub(now)
+			}
+			select {
+			case <-ctx.Done():
+				log.Printf("Stopped")
+				return nil
+			case <-time.After(wait):
+			}
+		}
+	}
+}
+
+// sendDailyDigest emails the digest looking days ahead to each of to,
+// unless there is nothing to tell of.
+func sendDailyDigest(days int, to []string) error {
+	d, err := loadDigest(days, nil)
+	if err != nil {
+		return err
+	}
+	if d.Empty() {
+		log.Printf("Nothing is overdue or coming up, so no digest was sent")
+		return nil
+	}
+	from, _, err := settingValue("digest.from")
+	if err != nil {
+		return err
+	}
+	if err := emailDigest(d, from, to); err != nil {
+		return err
+	}
+	log.Printf("Emailed the digest: %s", d.Subject())
+	return nil
+}
+
+// loadItems opens the list, reads its items and closes it again.
+func loadItems() ([]todo.ParsedTodoItem, error) {
+	store, err := openList()
+	if err != nil {
+		return nil, err
+	}
+	defer store.Close()
+	return store.List()
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/buck06191/todo-app/pkg/digest"
+	"github.com/buck06191/todo-app/pkg/todo"
+)
+
+// defaultDigestDays is how many days after today a digest looks ahead.
+const defaultDigestDays = 7
+
+// digestCommand implements `todo-app digest email`, emailing what is
+// overdue, due today and coming up through the SMTP server in the config
+// file, to be run each morning from cron or by the daemon with -digest.
+// Nothing is sent when there is nothing to tell of.
+func digestCommand() (*flag.FlagSet, func(args []string) error) {
+	fs := newFlagSet("digest", "email [-to <address>] [-days 7] [-where <filter>] [-print]")
+	var to stringList
+	fs.Var(&to, "to", "Address to send the digest to. Can be given more than once. (default digest.to from the config file)")
+	from := fs.String("from", "", "Address to send the digest from, or digest.from in the config file. (default smtp.username)")
+	days := fs.Int("days", defaultDigestDays, "How many days after today to tell of what is coming up on, or digest.days in the config file.")
+	whereExpr := fs.String("where", "", "Only include items matching a filter expression, as for list.")
+	printOnly := fs.Bool("print", false, "Write the email to standard output instead of sending it, e.g. for sendmail -t.")
+	return fs, func(args []string) error {
+		positional := parseInterspersed(fs, args)
+
+		if len(positional) == 0 || positional[0] != "email" {
+			fs.Usage()
+			if len(positional) == 0 {
+				return errors.New("digest needs a way to send it, such as email")
+			}
+			return fmt.Errorf("unknown digest command %q", positional[0])
+		}
+		if len(positional) > 1 {
+			fs.Usage()
+			return fmt.Errorf("unexpected argument %q", positional[1])
+		}
+		if err := flagDefaults(fs, map[string]string{"from": "digest.from", "days": "digest.days"}); err != nil {
+			return err
+		}
+		if *days < 0 {
+			return errors.New("-days can't be less than 0")
+		}
+		where, err := parseWhere(*whereExpr)
+		if err != nil {
+			return err
+		}
+
+		d, err := loadDigest(*days, where)
+		if err != nil {
+			return err
+		}
+		if d.Empty() {
+			fmt.Printf("Nothing is overdue or due in the next %d days, so there's nothing to send.\n", *days)
+			return nil
+		}
+		if len(to) == 0 {
+			if to, err = digestTo(); err != nil {
+				return err
+			}
+		}
+		if *printOnly {
+			if *from == "" {
+				*from = "todo-app"
+			}
+			_, err := os.Stdout.Write(d.Email(*from, to))
+			return err
+		}
+		if err := emailDigest(d, *from, to); err != nil {
+			return err
+		}
+		fmt.Printf("Sent the digest to %s: %s\n", strings.Join(to, ", "), d.Subject())
+		return nil
+	}
+}
+
+// loadDigest sums up the items of the list matching where, looking days
+// ahead.
+func loadDigest(days int, where *todo.Filter) (digest.Digest, error) {
+	saved, err := loadItems()
+	if err != nil {
+		return digest.Digest{}, err
+	}
+	now := time.Now()
+	var items []todo.ParsedTodoItem
+	for _, item := range saved {
+		if where == nil || where.Match(item, now) {
+			items = append(items, item)
+		}
+	}
+	return digest.New(items, now, days), nil
+}
+
+// digestTo returns the addresses in digest.to, separated by commas.
+func digestTo() ([]string, error) {
+	v, _, err := settingValue("digest.to")
+	if err != nil {
+		return nil, err
+	}
+	var to []string
+	for _, addr := range strings.Split(v, ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			to = append(to, addr)
+		}
+	}
+	if len(to) == 0 {
+		return nil, errors.New("the digest needs an address to go to, as -to or digest.to in the config file")
+	}
+	return to, nil
+}
+
+// emailDigest sends d from from, or smtp.username if from is empty, to each
+// of to through the SMTP server in the config file.
+func emailDigest(d digest.Digest, from string, to []string) error {
+	var s digest.SMTP
+	for key, value := range map[string]*string{"smtp.server": &s.Server, "smtp.username": &s.Username, "smtp.password": &s.Password} {
+		var err error
+		if *value, _, err = settingValue(key); err != nil {
+			return err
+		}
+	}
+	if s.Server == "" {
+		return errors.New("sending the digest needs smtp.server in the config file, e.g. smtp.example.com:587")
+	}
+	if from == "" {
+		from = s.Username
+	}
+	if err := s.Send(from, to, d.Email(from, to)); err != nil {
+		return fmt.Errorf("sending the digest: %w", err)
+	}
+	return nil
+}
+
+// parseDigestAt reads the time of day the daemon sends the digest at, such
+// as 7:30.
+func parseDigestAt(s string) (hour, min int, err error) {
+	h, m, ok := strings.Cut(s, ":")
+	if hour, err = strconv.Atoi(h); ok && err == nil {
+		min, err = strconv.Atoi(m)
+	}
+	if !ok || err != nil || hour < 0 || hour > 23 || min < 0 || min > 59 || len(m) != 2 {
+		return 0, 0, fmt.Errorf("%q is not a time of day such as 7:30", s)
+	}
+	return hour, min, nil
+}
+
+// nextDigest returns the first time after now that it is hour:min.
+func nextDigest(hour, min int, now time.Time) time.Time {
+	y, m, d := now.In(todo.Location).Date()
+	next := time.Date(y, m, d, hour, min, 0, 0, todo.Location)
+	if !next.After(now) {
+		next = time.Date(y, m, d+1, hour, min, 0, 0, todo.Location)
+	}
+	return next
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/buck06191/todo-app/pkg/todo"
+)
+
+// doneCommand implements `todo-app done <id>...` and `todo-app done -where
+// <filter>`. Completing an item that repeats adds its next occurrence.
+func doneCommand() (*flag.FlagSet, func(args []string) error) {
+	fs := newFlagSet("done", "<id or text>... | -where <filter> [flags]")
+	cascade := fs.Bool("cascade", false, "Also mark every subtask of the items as done.")
+	where, dryRun := bulkFlags(fs, "mark as done")
+	return fs, func(args []string) error {
+		positional := parseInterspersed(fs, args)
+
+		if len(positional) == 0 && *where == "" {
+			fs.Usage()
+			return errors.New("done needs at least one item ID or text")
+		}
+
+		store, err := openStore()
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+
+		selected, err := selectItems(store, positional, *where)
+		if err != nil {
+			return err
+		}
+		var ids []int
+		for _, item := range selected {
+			// Items a filter matches that are done already are left alone.
+			if *where == "" || !item.Completed {
+				ids = append(ids, item.ID)
+			}
+		}
+
+		if *cascade {
+			saved, err := store.List()
+			if err != nil {
+				return err
+			}
+			seen := map[int]bool{}
+			for _, id := range ids {
+				seen[id] = true
+			}
+			for _, id := range ids {
+				for _, sub := range todo.Descendants(saved, id) {
+					if !sub.Completed && !seen[sub.ID] {
+						seen[sub.ID] = true
+						ids = append(ids, sub.ID)
+					}
+				}
+			}
+		}
+
+		if len(ids) == 0 && *where != "" {
+			fmt.Println("No items match")
+			return nil
+		}
+		if *dryRun {
+			var items []todo.ParsedTodoItem
+			for _, id := range ids {
+				item, err := store.Get(id)
+				if err != nil {
+					return err
+				}
+				items = append(items, item)
+			}
+			return printDryRun("mark as done", items)
+		}
+
+		now := time.Now()
+		for _, id := range ids {
+			item, err := store.Get(id)
+			if err != nil {
+				return err
+			}
+			if item.Completed {
+				fmt.Printf("%d is already done\n", id)
+				continue
+			}
+
+			item.SetStatus(todo.StatusDone, now)
+			if err := store.Update(item); err != nil {
+				return err
+			}
+			fmt.Printf("Done: %d %s\n", id, item.Todo)
+
+			if next, ok := item.NextOccurrence(now); ok {
+				next, err = store.Add(next)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("Next: %d %s due %s\n", next.ID, next.Todo, formatDue(next))
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/buck06191/todo-app/pkg/todo"
+)
+
+// editCommand implements `todo-app edit <id> [flags]` and `todo-app edit
+// -where <filter> [flags]`. Only the fields given as flags, or with -set,
+// are changed.
+func editCommand() (*flag.FlagSet, func(args []string) error) {
+	fs := newFlagSet("edit", "<id or text> | -where <filter> [flags]")
+	task := fs.String("task", "", "New text for the item.")
+	due := fs.String("due", "", "New due date in any of the forms add takes, or \"\" to clear it.")
+	priority := fs.String("priority", "", "New priority: low, medium, high, or \"\" to clear it.")
+	var tags, untags stringList
+	fs.Var(&tags, "tag", "Add a tag. Can be given more than once.")
+	fs.Var(&untags, "untag", "Remove a tag. Can be given more than once.")
+	project := fs.String("project", "", "Move the item to this project, or \"\" to clear it.")
+	var contexts, uncontexts stringList
+	fs.Var(&contexts, "context", "Add a context. Can be given more than once.")
+	fs.Var(&uncontexts, "uncontext", "Remove a context. Can be given more than once.")
+	repeat := fs.String("repeat", "", "New repeat rule, or \"\" to stop the item repeating.")
+	remind := fs.String("remind", "", "New reminder times for the daemon and iCal export, e.g. 1d,2h, or \"\" to use the default ones.")
+	estimate := fs.String("estimate", "", "New estimate of how long the item will take, e.g. 2h, or \"\" to clear it.")
+	parent := fs.Int("parent", 0, "Make the item a subtask of the item with this ID, or 0 to make it a top level item.")
+	status := fs.String("status", "", "Move the item to backlog, doing or done.")
+	editor := fs.Bool("editor", false, "Change the item in $EDITOR, as a file with its fields at the top and its notes below them.")
+	where, dryRun := bulkFlags(fs, "edit")
+	var assignments stringList
+	fs.Var(&assignments, "set", "Change a field as its flag would, e.g. priority=low or tag=urgent. Can be given more than once.")
+	return fs, func(args []string) error {
+		positional := parseInterspersed(fs, args)
+
+		for _, a := range assignments {
+			name, value, ok := strings.Cut(a, "=")
+			if !ok || name == "set" || name == "where" || name == "dry-run" {
+				return fmt.Errorf("-set takes field=value, with the field one of edit's flags, not %q", a)
+			}
+			if err := fs.Set(name, value); err != nil {
+				return fmt.Errorf("-set %s: %w", a, err)
+			}
+		}
+
+		if len(positional) != 1 && *where == "" {
+			fs.Usage()
+			return errors.New("edit takes exactly one item ID or text, or -where")
+		}
+
+		set := map[string]bool{}
+		fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+		delete(set, "where")
+		delete(set, "dry-run")
+		delete(set, "set")
+		if len(set) == 0 {
+			fs.Usage()
+			return errors.New("nothing to change")
+		}
+		if *editor && (len(set) > 1 || *where != "") {
+			return errors.New("-editor takes one item and no other flags")
+		}
+		if set["task"] && *task == "" {
+			return errors.New("the task text can't be empty")
+		}
+
+		newDue, err := todo.ParseDueDate(*due)
+		if err != nil {
+			return err
+		}
+
+		newPriority, err := todo.ParsePriority(*priority)
+		if err != nil {
+			return err
+		}
+
+		newStatus, err := todo.ParseStatus(*status)
+		if err != nil {
+			return err
+		}
+
+		newEstimate, err := todo.ParseEstimate(*estimate)
+		if err != nil {
+			return err
+		}
+
+		var newRemind string
+		if *remind != "" {
+			offsets, err := todo.ParseOffsets(*remind)
+			if err != nil {
+				return err
+			}
+			newRemind = todo.FormatOffsets(offsets)
+		}
+
+		store, err := openStore()
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+
+		items, err := selectItems(store, positional, *where)
+		if err != nil {
+			return err
+		}
+		if *dryRun || len(items) == 0 {
+			return printDryRun("edit", items)
+		}
+		if *editor {
+			return editInEditor(store, items[0])
+		}
+
+		for _, item := range items {
+			if set["task"] {
+				item.Todo = *task
+			}
+			if set["due"] {
+				item.Due = newDue
+			}
+			if set["priority"] {
+				item.Priority = newPriority
+			}
+			if set["repeat"] {
+				item.Repeat = ""
+				if *repeat != "" {
+					r, err := todo.ParseRepeat(*repeat, item.Due)
+					if err != nil {
+						return err
+					}
+					item.Repeat = r.String()
+				}
+			}
+			if set["remind"] {
+				item.Remind = newRemind
+			}
+			if set["estimate"] {
+				item.Estimate = newEstimate
+			}
+			if set["parent"] {
+				saved, err := store.List()
+				if err != nil {
+					return err
+				}
+				if err := todo.CheckParent(saved, item.ID, *parent); err != nil {
+					return err
+				}
+				item.Parent = *parent
+			}
+			if set["project"] {
+				item.Project = strings.TrimSpace(*project)
+			}
+			item.Tags = todo.RemoveTags(todo.AddTags(item.Tags, tags...), untags...)
+			item.Contexts = todo.RemoveContexts(todo.AddContexts(item.Contexts, contexts...), uncontexts...)
+
+			// Moving a repeating item to done schedules the next one, as done
+			// does.
+			var next todo.ParsedTodoItem
+			var repeats bool
+			if set["status"] {
+				now := time.Now()
+				if item.SetStatus(newStatus, now) {
+					next, repeats = item.NextOccurrence(now)
+				}
+			}
+
+			if err := store.Update(item); err != nil {
+				return err
+			}
+			fmt.Printf("Updated: %d %s\n", item.ID, item.Todo)
+
+			if repeats {
+				if next, err = store.Add(next); err != nil {
+					return err
+				}
+				fmt.Printf("Next: %d %s due %s\n", next.ID, next.Todo, formatDue(next))
+			}
+		}
+		return nil
+	}
+}
+
+// editInEditor changes item to what it is changed to in the editor, for
+// edit -editor.
+func editInEditor(store todo.Store, item todo.ParsedTodoItem) error {
+	before := fieldsOf(item)
+	fields, err := editItemFile(before, fmt.Sprintf("todo-%d-*.md", item.ID))
+	if err != nil {
+		return err
+	}
+	if formatItemFile(fields) == formatItemFile(before) {
+		fmt.Println("Item unchanged.")
+		return nil
+	}
+	edited, err := todo.ParseItem(fields)
+	if err != nil {
+		return err
+	}
+	if edited.Parent != item.Parent {
+		saved, err := store.List()
+		if err != nil {
+			return err
+		}
+		if err := todo.CheckParent(saved, item.ID, edited.Parent); err != nil {
+			return err
+		}
+	}
+
+	item.Todo, item.Due, item.Priority = edited.Todo, edited.Due, edited.Priority
+	item.Tags, item.Project, item.Contexts = edited.Tags, edited.Project, edited.Contexts
+	item.Repeat, item.Remind, item.Estimate = edited.Repeat, edited.Remind, edited.Estimate
+	item.Parent, item.Notes = edited.Parent, edited.Notes
+	if err := store.Update(item); err != nil {
+		return err
+	}
+	fmt.Printf("Updated: %d %s\n", item.ID, item.Todo)
+	return nil
+}
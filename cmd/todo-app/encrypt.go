@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/buck06191/todo-app/pkg/seal"
+	"github.com/buck06191/todo-app/pkg/todo"
+	"golang.org/x/term"
+)
+
+// storeKey is the key the store was unlocked with, if it is encrypted,
+// for the files kept beside it such as the archive.
+var storeKey *seal.Key
+
+// unlock gives store its key if it is encrypted, or encrypts it if
+// -encrypt asks for that. A store opened again, as the daemon does, is
+// given the key it was unlocked with before rather than asking again.
+func unlock(store todo.Store) error {
+	previous := storeKey
+	storeKey = nil
+	enc, ok := store.(todo.Encrypter)
+	if !ok {
+		if *encrypt {
+			return errors.New("only JSON files and sqlite:// stores can be encrypted")
+		}
+		return nil
+	}
+	encrypted, err := enc.Encrypted()
+	if err != nil {
+		return err
+	}
+	if !encrypted && !*encrypt {
+		return nil
+	}
+	if encrypted && previous != nil && enc.UseKey(previous) == nil {
+		storeKey = previous
+		return nil
+	}
+
+	secret, err := passphrase(!encrypted)
+	if err != nil {
+		return err
+	}
+	key, err := seal.NewKey(secret, nil)
+	if err != nil {
+		return err
+	}
+	if err := enc.UseKey(key); err != nil {
+		return err
+	}
+	storeKey = key
+	return nil
+}
+
+// passphrase returns the store's passphrase: $TODO_PASSPHRASE, or what
+// $TODO_PASSPHRASE_COMMAND prints, or else one asked for at the terminal,
+// twice if it is a new one.
+func passphrase(confirm bool) ([]byte, error) {
+	if p := os.Getenv("TODO_PASSPHRASE"); p != "" {
+		return []byte(p), nil
+	}
+	// The command may include arguments, e.g. "pass show todo-app".
+	if command := strings.Fields(os.Getenv("TODO_PASSPHRASE_COMMAND")); len(command) > 0 {
+		cmd := exec.Command(command[0], command[1:]...)
+		cmd.Stdin = os.Stdin
+		cmd.Stderr = os.Stderr
+		out, err := cmd.Output()
+		if err != nil {
+			return nil, fmt.Errorf("getting the passphrase with $TODO_PASSPHRASE_COMMAND: %w", err)
+		}
+		// Only the first line, as pass prints other fields after it.
+		line, _, _ := bytes.Cut(out, []byte("\n"))
+		return bytes.TrimSuffix(line, []byte("\r")), nil
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return nil, fmt.Errorf("%w: set $TODO_PASSPHRASE or $TODO_PASSPHRASE_COMMAND when not at a terminal", todo.ErrEncrypted)
+	}
+
+	read := func(prompt string) ([]byte, error) {
+		fmt.Fprint(os.Stderr, prompt)
+		p, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Fprintln(os.Stderr)
+		return p, err
+	}
+	if !confirm {
+		return read("Passphrase: ")
+	}
+	p, err := read("New passphrase for the store: ")
+	if err != nil {
+		return nil, err
+	}
+	again, err := read("Passphrase again: ")
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(p, again) {
+		return nil, errors.New("the passphrases don't match")
+	}
+	return p, nil
+}
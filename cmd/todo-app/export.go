@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/buck06191/todo-app/pkg/format"
+	"github.com/buck06191/todo-app/pkg/ical"
+	"github.com/buck06191/todo-app/pkg/todo"
+)
+
+// exportCommand implements `todo-app export`, writing the list out in one of
+// the formats of -output, or as iCalendar to put the items that are due in
+// a calendar app.
+func exportCommand() (*flag.FlagSet, func(args []string) error) {
+	fs := newFlagSet("export", "[-format <format>] [-map <field>=<column>,...] [-o <file>] [-all] [-as todo|event] [-remind <duration>]")
+	name := fs.String("format", "json", "Format to write the items in: "+strings.Join(format.Names(), ", ")+". ics only includes the items that are due.")
+	out := fs.String("o", "-", "File to write to, or - for standard output. The file is replaced as a whole, so calendar apps subscribed to it never see half of it.")
+	mapping := fs.String("map", "", "With -format csv, the columns to write and the header of each, e.g. todo=Title,due=Deadline, rather than every field under its own name.")
+	all := fs.Bool("all", false, "Include items that have been done.")
+	as := fs.String("as", "event", "With -format ics, write each item as a calendar event on the day or at the time it is due (event), or as a task (todo) for apps that show them.")
+	remind := fs.Duration("remind", 15*time.Minute, "With -format ics, give each item an alarm this long before it is due, e.g. 15m or 24h, or none for 0.")
+	return fs, func(args []string) error {
+		positional := parseInterspersed(fs, args)
+		if len(positional) > 0 {
+			fs.Usage()
+			return fmt.Errorf("unexpected argument %q", positional[0])
+		}
+		if *remind < 0 {
+			return errors.New("-remind can't be negative")
+		}
+
+		ics := strings.EqualFold(*name, "ics")
+		render, err := format.Lookup(*name)
+		if err != nil {
+			return err
+		}
+		if ics {
+			var opts ical.Options
+			switch *as {
+			case "event":
+				opts.Events = true
+			case "todo":
+			default:
+				return fmt.Errorf("-as is event or todo, not %q", *as)
+			}
+			opts.Remind = *remind
+			render = format.ICS(opts)
+		}
+		if *mapping != "" {
+			if !strings.EqualFold(*name, "csv") {
+				return errors.New("-map only goes with -format csv")
+			}
+			columns, err := format.ParseMap(*mapping)
+			if err != nil {
+				return err
+			}
+			render = format.CSV(columns)
+		}
+
+		store, err := openStore()
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+		saved, err := store.List()
+		if err != nil {
+			return err
+		}
+		var items []todo.ParsedTodoItem
+		for _, item := range saved {
+			if item.Completed && !*all || ics && item.Due.IsZero() {
+				continue
+			}
+			items = append(items, item)
+		}
+		todo.SortByDue(items)
+
+		var b bytes.Buffer
+		if err := render.List(&b, items); err != nil {
+			return err
+		}
+		if *out == "-" {
+			_, err = os.Stdout.Write(b.Bytes())
+			return err
+		}
+		return replaceFile(*out, b.Bytes())
+	}
+}
+
+// replaceFile writes data to a temporary file next to path and renames it
+// over path, so readers see either the old file or the new one.
+func replaceFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
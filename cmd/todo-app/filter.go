@@ -0,0 +1,67 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/buck06191/todo-app/pkg/todo"
+)
+
+// filterCommand implements `todo-app filter`, managing the named filters
+// that can be given to list.
+func filterCommand() (*flag.FlagSet, func(args []string) error) {
+	fs := newFlagSet("filter", "[list | save <name> <expression> | rm <name>]")
+	return fs, func(args []string) error {
+		fs.Parse(args)
+		args = fs.Args()
+
+		sub := "list"
+		if len(args) > 0 {
+			sub, args = args[0], args[1:]
+		}
+		if (sub == "list" && len(args) != 0) || (sub == "save" && len(args) < 2) || (sub == "rm" && len(args) != 1) {
+			fs.Usage()
+			return fmt.Errorf("wrong number of arguments for filter %s", sub)
+		}
+
+		store, err := openStore()
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+
+		switch sub {
+		case "list":
+			filters, err := todo.SavedFilters(store)
+			if err != nil {
+				return err
+			}
+			if len(filters) == 0 {
+				fmt.Println("No saved filters yet.")
+				return nil
+			}
+			for _, name := range slices.Sorted(maps.Keys(filters)) {
+				fmt.Printf("%-16s %s\n", name, filters[name])
+			}
+			return nil
+		case "save":
+			name, expr := args[0], strings.Join(args[1:], " ")
+			if err := todo.SaveFilter(store, name, expr); err != nil {
+				return err
+			}
+			fmt.Printf("Saved filter %s: %s\n", name, expr)
+			return nil
+		case "rm":
+			if err := todo.DeleteFilter(store, args[0]); err != nil {
+				return err
+			}
+			fmt.Printf("Deleted filter %s\n", args[0])
+			return nil
+		}
+		fs.Usage()
+		return fmt.Errorf("unknown filter command %q", sub)
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/buck06191/todo-app/pkg/gtasks"
+	"github.com/buck06191/todo-app/pkg/todo"
+)
+
+// loginTimeout is how long `todo-app sync google login` waits for the
+// user to answer in their browser.
+const loginTimeout = 5 * time.Minute
+
+// syncGoogleCommand implements `todo-app sync google`, keeping the list in
+// step with Google Tasks.
+func syncGoogleCommand() (*flag.FlagSet, func(args []string) error) {
+	fs := newFlagSet("sync google", "[login | status | forget] [-client-id <id>] [-client-secret <secret>]")
+	clientID := fs.String("client-id", "", "Client ID of the OAuth client made for todo-app in the Google Cloud console, of the desktop app kind. Remembered for next time.")
+	clientSecret := fs.String("client-secret", "", "Client secret of the OAuth client, remembered along with -client-id.")
+	return fs, func(args []string) error {
+		positional := parseInterspersed(fs, args)
+
+		sub := "sync"
+		if len(positional) > 0 {
+			sub, positional = positional[0], positional[1:]
+		}
+		if len(positional) > 0 {
+			fs.Usage()
+			return fmt.Errorf("unexpected argument %q", positional[0])
+		}
+
+		store, err := openList()
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+		state, err := gtasks.LoadState(store)
+		if err != nil {
+			return err
+		}
+		if *clientID != "" {
+			state.ClientID = *clientID
+		}
+		if *clientSecret != "" {
+			state.ClientSecret = *clientSecret
+		}
+
+		switch sub {
+		case "sync":
+			if state.Token.Refresh == "" {
+				if err := googleLogin(store, &state); err != nil {
+					return err
+				}
+			}
+			return googleSync(store, &state)
+		case "login":
+			return googleLogin(store, &state)
+		case "status":
+			if state.Token.Refresh == "" {
+				fmt.Println("This list isn't synced with Google Tasks. Start with: todo-app sync google -client-id <id> -client-secret <secret>")
+				return nil
+			}
+			if state.Synced.IsZero() {
+				fmt.Println("Logged in to Google, never synced")
+			} else {
+				fmt.Printf("Last synced with Google Tasks %s\n", state.Synced.In(todo.Location).Format("2006-01-02 15:04"))
+			}
+			fmt.Printf("%d items synced\n", len(state.Tasks))
+			return nil
+		case "forget":
+			if err := gtasks.SaveState(store, gtasks.State{}); err != nil {
+				return err
+			}
+			fmt.Println("Forgot the Google login and sync state. The next sync will keep the items on both sides.")
+			return nil
+		}
+		fs.Usage()
+		return fmt.Errorf("unknown sync google command %q", sub)
+	}
+}
+
+// googleLogin logs in to Google in the browser and saves the token.
+func googleLogin(store todo.Store, state *gtasks.State) error {
+	if state.ClientID == "" || state.ClientSecret == "" {
+		return errors.New("logging in to Google needs -client-id and -client-secret, from an OAuth client of the desktop app kind made in the Google Cloud console with the Tasks API enabled")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), loginTimeout)
+	defer cancel()
+	token, err := gtasks.Login(ctx, state.Credentials, func(url string) {
+		fmt.Fprintf(os.Stderr, "Open this address in a browser to let todo-app use your tasks:\n\n  %s\n\n", url)
+	})
+	if errors.Is(err, context.DeadlineExceeded) {
+		return errors.New("gave up waiting for the login in the browser")
+	}
+	if err != nil {
+		return err
+	}
+	state.Token = token
+	if err := gtasks.SaveState(store, *state); err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stderr, "Logged in to Google")
+	return nil
+}
+
+func googleSync(store todo.Store, state *gtasks.State) error {
+	c := gtasks.NewClient(state.Credentials, state.Token, func(t gtasks.Token) error {
+		state.Token = t
+		return gtasks.SaveState(store, *state)
+	})
+	res, err := gtasks.Sync(store, c, state)
+	if errors.Is(err, gtasks.ErrLogin) {
+		return fmt.Errorf("%w, log in again with: todo-app sync google login", err)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Synced with Google Tasks: pulled %d and pushed %d tasks, deleted %d\n", res.Pulled, res.Pushed, res.Deleted)
+	if res.Conflicts > 0 {
+		fmt.Printf("Kept the values here for %d conflicting changes made on both sides\n", res.Conflicts)
+	}
+	return nil
+}
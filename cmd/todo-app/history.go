@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+
+	"github.com/buck06191/todo-app/pkg/todo"
+)
+
+// historyCommand implements `todo-app history <id>`, printing every change
+// made to an item, oldest first.
+func historyCommand() (*flag.FlagSet, func(args []string) error) {
+	fs := newFlagSet("history", "<id> [flags]")
+	asJSON := fs.Bool("json", false, "Print the events as JSON.")
+	return fs, func(args []string) error {
+		positional := parseInterspersed(fs, args)
+
+		if len(positional) != 1 {
+			fs.Usage()
+			return errors.New("history takes exactly one item ID")
+		}
+		id, err := parseID(positional[0])
+		if err != nil {
+			return err
+		}
+
+		store, err := openStore()
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+
+		events, err := todo.History(store, id)
+		if err != nil {
+			return err
+		}
+		if *asJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(events)
+		}
+		return printHistory(os.Stdout, events)
+	}
+}
+
+// printHistory prints each event with when it was, what kind it was and
+// what made it, then the fields it changed.
+func printHistory(w io.Writer, events []todo.Event) error {
+	for i, e := range events {
+		source := e.Source
+		if source == "" {
+			source = "(made outside todo-app)"
+		}
+		fmt.Fprintf(w, "%s  %-9s  %s\n", e.At.In(todo.Location).Format("2006-01-02 15:04"), e.Kind, source)
+
+		diff := todo.Diff(events, i)
+		var names []string
+		for name := range e.Fields {
+			if name != "id" {
+				names = append(names, name)
+			}
+		}
+		slices.Sort(names)
+		for _, name := range names {
+			now := compactJSON(e.Fields[name])
+			switch was := diff[name]; {
+			case e.Kind == todo.EventCreated:
+				fmt.Fprintf(w, "    %s: %s\n", name, now)
+			case was == nil:
+				fmt.Fprintf(w, "    %s: %s (was unset)\n", name, now)
+			case now == "null":
+				fmt.Fprintf(w, "    %s: unset (was %s)\n", name, compactJSON(was))
+			default:
+				fmt.Fprintf(w, "    %s: %s (was %s)\n", name, now, compactJSON(was))
+			}
+		}
+	}
+	return nil
+}
+
+// compactJSON returns raw on one line, as stores may have saved it
+// indented over several.
+func compactJSON(raw json.RawMessage) string {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, raw); err != nil {
+		return string(raw)
+	}
+	return buf.String()
+}
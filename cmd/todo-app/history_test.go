@@ -0,0 +1,43 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/buck06191/todo-app/pkg/todo"
+)
+
+func TestPrintHistoryCompactsFields(t *testing.T) {
+	saved := todo.Location
+	todo.Location = time.UTC
+	t.Cleanup(func() { todo.Location = saved })
+
+	at := time.Date(2025, 1, 10, 9, 0, 0, 0, time.UTC)
+	// As the JSON store saves them, indented.
+	events := []todo.Event{
+		{At: at, Kind: todo.EventCreated, Source: "add", Fields: map[string]json.RawMessage{
+			"id":   json.RawMessage(`1`),
+			"todo": json.RawMessage(`"Buy milk"`),
+			"tags": json.RawMessage("[\n      \"shopping\"\n    ]"),
+		}},
+		{At: at.Add(time.Hour), Kind: todo.EventEdited, Source: "tag 1 home", Fields: map[string]json.RawMessage{
+			"tags": json.RawMessage("[\n      \"shopping\",\n      \"home\"\n    ]"),
+		}},
+	}
+
+	var out bytes.Buffer
+	if err := printHistory(&out, events); err != nil {
+		t.Fatal(err)
+	}
+	want := `2025-01-10 09:00  created    add
+    tags: ["shopping"]
+    todo: "Buy milk"
+2025-01-10 10:00  edited     tag 1 home
+    tags: ["shopping","home"] (was ["shopping"])
+`
+	if got := out.String(); got != want {
+		t.Errorf("printHistory printed\n%s\nwant\n%s", got, want)
+	}
+}
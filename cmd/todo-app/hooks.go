@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+
+	"github.com/buck06191/todo-app/pkg/todo"
+)
+
+// The hooks run for the changes a command makes, once they are saved,
+// with each item changed as JSON on standard input.
+const (
+	hookAdd  = "post-add"
+	hookDone = "post-done"
+	hookEdit = "post-edit"
+	hookRm   = "post-rm"
+)
+
+// hooksDir returns the directory hook scripts are kept in: hooks beside
+// the config file.
+func hooksDir() (string, error) {
+	path, err := configFile()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(path), "hooks"), nil
+}
+
+// findHook returns the path of the hook called name, if there is one that
+// can be run.
+func findHook(name string) (string, bool) {
+	dir, err := hooksDir()
+	if err != nil {
+		return "", false
+	}
+	path := filepath.Join(dir, name)
+	info, err := os.Stat(path)
+	// Windows has no executable bit to check.
+	if err != nil || !info.Mode().IsRegular() || runtime.GOOS != "windows" && info.Mode().Perm()&0o111 == 0 {
+		return "", false
+	}
+	return path, true
+}
+
+// hasPostHooks reports whether there is any hook to run for changes.
+func hasPostHooks() bool {
+	for _, name := range []string{hookAdd, hookDone, hookEdit, hookRm} {
+		if _, ok := findHook(name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// runPreHook runs the hook pre-<command>, if there is one, with the
+// command's arguments as its own, before command is run. A hook failing
+// stops the command.
+func runPreHook(command string, args []string) error {
+	name := "pre-" + command
+	path, ok := findHook(name)
+	if !ok {
+		return nil
+	}
+	if err := runHook(path, name, args, nil); err != nil {
+		return fmt.Errorf("the %s hook stopped the command: %w", name, err)
+	}
+	return nil
+}
+
+// runPostHooks runs the hooks for each of changes, once they are saved:
+// post-add for the items added, post-done for those done, post-rm for
+// those moved to the trash and post-edit for any other change. Hooks that
+// fail are reported without failing the command, whose changes have been
+// made.
+func runPostHooks(changes []todo.Change) {
+	for _, c := range changes {
+		name := hookEdit
+		switch {
+		case c.Before == nil:
+			name = hookAdd
+		case c.Before.DeletedAt.IsZero() && !c.After.DeletedAt.IsZero():
+			name = hookRm
+		case !c.Before.Completed && c.After.Completed:
+			name = hookDone
+		}
+		path, ok := findHook(name)
+		if !ok {
+			continue
+		}
+		item, err := json.Marshal(c.After)
+		if err != nil {
+			continue
+		}
+		if err := runHook(path, name, nil, append(item, '\n')); err != nil {
+			fmt.Fprintf(os.Stderr, "todo-app: hook %s for %d: %v\n", name, c.After.ID, err)
+		}
+	}
+}
+
+// runHook runs the hook at path with args, and stdin on its standard
+// input. What it writes goes to standard error, so as not to mix with the
+// command's own output. TODO_HOOK names the hook and TODO_COMMAND the
+// command it is run for, as the journal records it.
+func runHook(path, name string, args []string, stdin []byte) error {
+	cmd := exec.Command(path, args...)
+	cmd.Stdin = bytes.NewReader(stdin)
+	cmd.Stdout, cmd.Stderr = os.Stderr, os.Stderr
+	cmd.Env = append(os.Environ(), "TODO_HOOK="+name, "TODO_COMMAND="+operation)
+	err := cmd.Run()
+	if errors.Is(err, fs.ErrPermission) {
+		return fmt.Errorf("%s can't be run", path)
+	}
+	return err
+}
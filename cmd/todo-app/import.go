@@ -0,0 +1,234 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/buck06191/todo-app/pkg/format"
+	"github.com/buck06191/todo-app/pkg/markdown"
+	"github.com/buck06191/todo-app/pkg/mstodo"
+	"github.com/buck06191/todo-app/pkg/todo"
+	"github.com/buck06191/todo-app/pkg/todotxt"
+	"github.com/buck06191/todo-app/pkg/trello"
+)
+
+// importFormats read the files `todo-app import` takes, by the name of
+// their format.
+var importFormats = map[string]func(io.Reader) ([]todo.ParsedTodoItem, error){
+	"csv":     func(r io.Reader) ([]todo.ParsedTodoItem, error) { return format.ReadCSV(r, nil) },
+	"md":      markdown.Read,
+	"todotxt": todotxt.Read,
+}
+
+// importCommand implements `todo-app import`, adding the items of a file, or
+// the lists of another todo app, to the list.
+func importCommand() (*flag.FlagSet, func(args []string) error) {
+	names := slices.Sorted(maps.Keys(importFormats))
+	fs := newFlagSet("import", "-format <format> [-map <field>=<column>,...] <file> | trello ... | mstodo ...")
+	name := fs.String("format", "", "Format of the file: "+strings.Join(names, ", ")+".")
+	mapping := fs.String("map", "", "With -format csv, the columns holding each field, e.g. todo=Title,due=Deadline, for files that don't name them as export does.")
+	return fs, func(args []string) error {
+		if len(args) > 0 {
+			switch args[0] {
+			case "mstodo":
+				return runCommand(importMSTodoCommand, args[1:])
+			case "trello":
+				return runCommand(importTrelloCommand, args[1:])
+			}
+		}
+
+		positional := parseInterspersed(fs, args)
+		if len(positional) != 1 {
+			fs.Usage()
+			return errors.New("import needs a file to read, or - for standard input, or trello or mstodo to import from those")
+		}
+		read, ok := importFormats[strings.ToLower(*name)]
+		if !ok {
+			fs.Usage()
+			return fmt.Errorf("unknown import format %q, expected one of %s", *name, strings.Join(names, ", "))
+		}
+		if *mapping != "" {
+			if !strings.EqualFold(*name, "csv") {
+				return errors.New("-map only goes with -format csv")
+			}
+			columns, err := format.ParseMap(*mapping)
+			if err != nil {
+				return err
+			}
+			read = func(r io.Reader) ([]todo.ParsedTodoItem, error) { return format.ReadCSV(r, columns) }
+		}
+
+		in := os.Stdin
+		if positional[0] != "-" {
+			f, err := os.Open(positional[0])
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			in = f
+		}
+		items, err := read(in)
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", positional[0], err)
+		}
+
+		store, err := openStore()
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+		if err := addImported(store, items); err != nil {
+			return err
+		}
+		fmt.Printf("Imported %d items\n", len(items))
+		return nil
+	}
+}
+
+// addImported adds items to store. Their IDs are the ones they had where
+// they came from, if any, and their parents and blockers are given by
+// those; they are numbered anew, with references to items that weren't
+// imported dropped.
+func addImported(store todo.Store, items []todo.ParsedTodoItem) error {
+	now := time.Now()
+	ids := map[int]int{}
+	var linked []todo.ParsedTodoItem
+	for _, item := range items {
+		if item.CreatedAt.IsZero() {
+			item.CreatedAt = now
+		}
+		if item.Completed && item.CompletedAt.IsZero() {
+			item.CompletedAt = item.CreatedAt
+		}
+		parent, blockedBy := item.Parent, item.BlockedBy
+		item.Parent, item.BlockedBy = 0, nil
+		saved, err := store.Add(item)
+		if err != nil {
+			return err
+		}
+		if item.ID != 0 {
+			ids[item.ID] = saved.ID
+		}
+		if parent != 0 || len(blockedBy) > 0 {
+			saved.Parent, saved.BlockedBy = parent, blockedBy
+			linked = append(linked, saved)
+		}
+	}
+	for _, item := range linked {
+		item.Parent = ids[item.Parent]
+		var blockedBy []int
+		for _, id := range item.BlockedBy {
+			if blocker, ok := ids[id]; ok {
+				blockedBy = append(blockedBy, blocker)
+			}
+		}
+		item.BlockedBy = blockedBy
+		if err := store.Update(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// importTrelloCommand implements `todo-app import trello`, adding the cards
+// of a board Trello has exported as JSON to the list.
+func importTrelloCommand() (*flag.FlagSet, func(args []string) error) {
+	fs := newFlagSet("import trello", "[-lists project|tag] <board.json>")
+	as := fs.String("lists", "project", "Make each list of the board the project of its cards (project), or make the board the project and each list a tag (tag), for lists such as To Do and Doing.")
+	return fs, func(args []string) error {
+		positional := parseInterspersed(fs, args)
+		if len(positional) != 1 {
+			fs.Usage()
+			return errors.New("import trello needs the JSON file Trello exported the board as, or - for standard input")
+		}
+		var lists trello.Lists
+		switch *as {
+		case "project":
+			lists = trello.ListsAsProjects
+		case "tag":
+			lists = trello.ListsAsTags
+		default:
+			return fmt.Errorf("-lists is project or tag, not %q", *as)
+		}
+
+		in := os.Stdin
+		if positional[0] != "-" {
+			f, err := os.Open(positional[0])
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			in = f
+		}
+		items, err := trello.Read(in, lists)
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", positional[0], err)
+		}
+
+		store, err := openStore()
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+		if err := addImported(store, items); err != nil {
+			return err
+		}
+		fmt.Printf("Imported %d items\n", len(items))
+		return nil
+	}
+}
+
+// importMSTodoCommand implements `todo-app import mstodo`, adding the lists
+// and tasks of Microsoft To Do to the list.
+func importMSTodoCommand() (*flag.FlagSet, func(args []string) error) {
+	fs := newFlagSet("import mstodo", "-client-id <id> [-tenant <tenant>]")
+	clientID := fs.String("client-id", "", "Application ID of the app registered for todo-app in the Azure portal, allowing public client flows and the Tasks.Read permission.")
+	tenant := fs.String("tenant", "common", "Tenant to log in to: common for any account, consumers for personal Microsoft accounts only, or the ID of an organisation.")
+	return fs, func(args []string) error {
+		positional := parseInterspersed(fs, args)
+		if len(positional) > 0 {
+			fs.Usage()
+			return fmt.Errorf("unexpected argument %q", positional[0])
+		}
+		if *clientID == "" {
+			fs.Usage()
+			return errors.New("import mstodo needs -client-id")
+		}
+
+		store, err := openStore()
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), loginTimeout)
+		defer cancel()
+		token, err := mstodo.Login(ctx, *clientID, *tenant, func(message string) {
+			fmt.Fprintf(os.Stderr, "%s\n\n", message)
+		})
+		if errors.Is(err, context.DeadlineExceeded) {
+			return errors.New("gave up waiting for the code to be entered")
+		}
+		if err != nil {
+			return err
+		}
+
+		res, err := mstodo.Import(store, mstodo.NewClient(token))
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Imported %d items from %d Microsoft To Do lists\n", res.Added, res.Lists)
+		if res.Skipped > 0 {
+			fmt.Printf("Skipped %d tasks imported before\n", res.Skipped)
+		}
+		return nil
+	}
+}
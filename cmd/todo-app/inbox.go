@@ -0,0 +1,237 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/buck06191/todo-app/pkg/todo"
+)
+
+// inCommand implements `todo-app in <thought>`, adding an item to the inbox
+// as it is written, to be given its due date, project and priority later by
+// triage.
+func inCommand() (*flag.FlagSet, func(args []string) error) {
+	fs := newFlagSet("in", "<thought>")
+	return fs, func(args []string) error {
+		fs.Parse(args)
+		if fs.NArg() == 0 {
+			fs.Usage()
+			return errors.New("in needs something to put in the inbox")
+		}
+
+		item, err := todo.ParseItem(todo.TodoItem{Todo: strings.Join(fs.Args(), " ")})
+		if err != nil {
+			return err
+		}
+		item.Inbox = true
+
+		store, err := openStore()
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+		if item, err = store.Add(item); err != nil {
+			return err
+		}
+		fmt.Printf("In the inbox: %d %s\n", item.ID, item.Todo)
+		return nil
+	}
+}
+
+// triageCommand implements `todo-app triage`, going through the items in the
+// inbox one at a time and asking for the due date, project, priority and
+// tags of each, or whether to mark it done, move it to the trash or leave it
+// in the inbox for now.
+func triageCommand() (*flag.FlagSet, func(args []string) error) {
+	fs := newFlagSet("triage", "[-where <filter>]")
+	whereExpr := fs.String("where", "", "Only triage the items in the inbox matching a filter expression, as for list.")
+	return fs, func(args []string) error {
+		fs.Parse(args)
+		if fs.NArg() > 0 {
+			fs.Usage()
+			return fmt.Errorf("unexpected argument %q", fs.Arg(0))
+		}
+		where, err := parseWhere(*whereExpr)
+		if err != nil {
+			return err
+		}
+
+		inbox, err := loadInbox(where)
+		if err != nil {
+			return err
+		}
+		if len(inbox) == 0 {
+			fmt.Println("The inbox is empty.")
+			return nil
+		}
+
+		in, out := bufio.NewReader(os.Stdin), os.Stdout
+		fmt.Fprintf(out, "%d items to triage. Press enter to keep a field as it is.\n", len(inbox))
+		var triaged, done, removed, skipped int
+	loop:
+		for i, item := range inbox {
+			fmt.Fprintf(out, "\n%d of %d: %d  %s\n", i+1, len(inbox), item.ID, item.Todo)
+			action, err := askLine(in, out, "Enter to triage it, or d for done, r to move it to the trash, s to skip, q to stop", "")
+			if err != nil {
+				skipped += len(inbox) - i
+				break
+			}
+			switch strings.ToLower(action) {
+			case "":
+				if item, err = askTriage(in, out, item); err != nil {
+					skipped += len(inbox) - i
+					break loop
+				}
+				err := updateInboxItem(item.ID, func(saved *todo.ParsedTodoItem) {
+					saved.Due, saved.Project, saved.Priority, saved.Tags = item.Due, item.Project, item.Priority, item.Tags
+					saved.Inbox = false
+				})
+				if err != nil {
+					return err
+				}
+				triaged++
+			case "d", "done":
+				err := updateInboxItem(item.ID, func(saved *todo.ParsedTodoItem) {
+					saved.SetStatus(todo.StatusDone, time.Now())
+					saved.Inbox = false
+				})
+				if err != nil {
+					return err
+				}
+				done++
+			case "r", "rm":
+				if err := removeInboxItem(item.ID); err != nil {
+					return err
+				}
+				removed++
+			case "s", "skip":
+				skipped++
+			case "q", "quit":
+				skipped += len(inbox) - i
+				break loop
+			default:
+				fmt.Fprintf(out, "  %q isn't one of d, r, s or q, so the item stays in the inbox.\n", action)
+				skipped++
+			}
+		}
+
+		fmt.Fprintf(out, "\nTriaged %d, %d done, %d moved to the trash and %d left in the inbox.\n", triaged, done, removed, skipped)
+		return nil
+	}
+}
+
+// loadInbox returns the items in the inbox that aren't done yet, and match
+// where if it isn't nil, in the order they were added.
+func loadInbox(where *todo.Filter) ([]todo.ParsedTodoItem, error) {
+	store, err := openStore()
+	if err != nil {
+		return nil, err
+	}
+	defer store.Close()
+	saved, err := store.List()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	var inbox []todo.ParsedTodoItem
+	for _, item := range saved {
+		if item.Inbox && !item.Completed && (where == nil || where.Match(item, now)) {
+			inbox = append(inbox, item)
+		}
+	}
+	return inbox, nil
+}
+
+// askTriage asks for the due date, project, priority and tags of item,
+// asking again for any that can't be read, and returns item with them
+// filled in. It returns io.EOF if in runs out first.
+func askTriage(in *bufio.Reader, out io.Writer, item todo.ParsedTodoItem) (todo.ParsedTodoItem, error) {
+	def := ""
+	if !item.Due.IsZero() {
+		def = item.Due.In(todo.Location).Format("2006-01-02T15:04")
+		if item.DueAllDay() {
+			def = item.Due.In(todo.Location).Format("2006-01-02")
+		}
+	}
+	for {
+		answer, err := askLine(in, out, "Due, e.g. tomorrow, next friday 9am or 2025-03-01", def)
+		if err != nil {
+			return item, err
+		}
+		due, err := todo.ParseDueDate(answer)
+		if err == nil {
+			if !due.IsZero() {
+				fmt.Fprintf(out, "  Due %s %s\n", due.In(todo.Location).Format("Mon"), formatDue(todo.ParsedTodoItem{Due: due}))
+			}
+			item.Due = due
+			break
+		}
+		fmt.Fprintf(out, "  %v\n", err)
+	}
+
+	project, err := askLine(in, out, "Project", item.Project)
+	if err != nil {
+		return item, err
+	}
+	item.Project = strings.TrimPrefix(project, "+")
+
+	def = ""
+	if item.Priority != todo.PriorityNone {
+		def = item.Priority.String()
+	}
+	for {
+		answer, err := askLine(in, out, "Priority: low, medium or high", def)
+		if err != nil {
+			return item, err
+		}
+		priority, err := todo.ParsePriority(answer)
+		if err == nil {
+			item.Priority = priority
+			break
+		}
+		fmt.Fprintf(out, "  %v\n", err)
+	}
+
+	answer, err := askLine(in, out, "Tags, separated by spaces", strings.Join(item.Tags, " "))
+	if err != nil {
+		return item, err
+	}
+	item.Tags = nil
+	for _, tag := range strings.FieldsFunc(answer, func(r rune) bool { return r == ' ' || r == ',' }) {
+		item.Tags = append(item.Tags, strings.TrimPrefix(tag, "#"))
+	}
+	return item, nil
+}
+
+// updateInboxItem changes the item with the given ID with change, opening
+// the store for each item so that each is a change of its own for undo and
+// other commands aren't kept waiting while there are questions to answer.
+func updateInboxItem(id int, change func(item *todo.ParsedTodoItem)) error {
+	store, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	item, err := store.Get(id)
+	if err != nil {
+		return err
+	}
+	change(&item)
+	return store.Update(item)
+}
+
+// removeInboxItem moves the item with the given ID to the trash.
+func removeInboxItem(id int) error {
+	store, err := openStore()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	return store.Delete(id)
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/term"
+
+	"github.com/buck06191/todo-app/pkg/todo"
+)
+
+// itemFields are the fields of an item file, in the order they are written.
+var itemFields = []string{"todo", "due", "priority", "tags", "project", "contexts", "repeat", "remind", "estimate", "parent"}
+
+// itemFileHelp starts the fields of an item file, saying how to fill it in.
+const itemFileHelp = `# Fill in the fields, leaving empty any that don't apply, and write the
+# notes below the second ---. Lines up here starting with # are left out.
+`
+
+// fieldsOf returns the fields of item as add takes them.
+func fieldsOf(item todo.ParsedTodoItem) todo.TodoItem {
+	fields := todo.TodoItem{
+		Todo:     item.Todo,
+		Tags:     item.Tags,
+		Project:  item.Project,
+		Contexts: item.Contexts,
+		Repeat:   item.Repeat,
+		Remind:   item.Remind,
+		Estimate: item.Estimate,
+		Parent:   item.Parent,
+		Notes:    item.Notes,
+	}
+	if item.Priority != todo.PriorityNone {
+		fields.Priority = item.Priority.String()
+	}
+	switch {
+	case item.Due.IsZero():
+	case item.DueAllDay():
+		fields.Due = item.Due.Format("2006-01-02")
+	default:
+		fields.Due = item.Due.In(todo.Location).Format("2006-01-02T15:04")
+	}
+	return fields
+}
+
+// formatItemFile writes fields as an item file: the fields between lines
+// of ---, one "name: value" a line, and the notes after them.
+func formatItemFile(fields todo.TodoItem) string {
+	var b strings.Builder
+	b.WriteString("---\n" + itemFileHelp)
+	parent := ""
+	if fields.Parent != 0 {
+		parent = strconv.Itoa(fields.Parent)
+	}
+	values := map[string]string{
+		"todo":     fields.Todo,
+		"due":      fields.Due,
+		"priority": fields.Priority,
+		"tags":     strings.Join(fields.Tags, " "),
+		"project":  fields.Project,
+		"contexts": strings.Join(fields.Contexts, " "),
+		"repeat":   fields.Repeat,
+		"remind":   fields.Remind,
+		"estimate": fields.Estimate,
+		"parent":   parent,
+	}
+	for _, name := range itemFields {
+		fmt.Fprintf(&b, "%s: %s\n", name, values[name])
+	}
+	b.WriteString("---\n")
+	if fields.Notes != "" {
+		b.WriteString(fields.Notes + "\n")
+	}
+	return b.String()
+}
+
+// parseItemFile reads an item file formatItemFile wrote and the user edited,
+// checking each field as add would. Every mistake is reported, each with
+// the number of the line it is on.
+func parseItemFile(text string) (todo.TodoItem, error) {
+	var fields todo.TodoItem
+	var problems []string
+	problem := func(n int, format string, args ...any) {
+		problems = append(problems, fmt.Sprintf("line %d: ", n)+fmt.Sprintf(format, args...))
+	}
+
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	n := 0
+	for n < len(lines) && strings.TrimSpace(lines[n]) == "" {
+		n++
+	}
+	if n == len(lines) || strings.TrimSpace(lines[n]) != "---" {
+		return fields, fmt.Errorf("line %d: the fields have to start with a line of ---", n+1)
+	}
+
+	seen := map[string]int{}
+	repeatLine := 0
+	closed := false
+	for n++; n < len(lines); n++ {
+		line := strings.TrimSpace(lines[n])
+		if line == "---" {
+			closed = true
+			n++
+			break
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, value, ok := strings.Cut(line, ":")
+		name, value = strings.ToLower(strings.TrimSpace(name)), strings.TrimSpace(value)
+		switch {
+		case !ok:
+			problem(n+1, "expected a field as name: value, not %q", line)
+			continue
+		case !slices.Contains(itemFields, name):
+			problem(n+1, "unknown field %q, expected one of %s", name, strings.Join(itemFields, ", "))
+			continue
+		case seen[name] != 0:
+			problem(n+1, "%s is already given on line %d", name, seen[name])
+			continue
+		}
+		seen[name] = n + 1
+
+		var err error
+		switch name {
+		case "todo":
+			fields.Todo = value
+		case "due":
+			fields.Due = value
+			_, err = todo.ParseDueDate(value)
+		case "priority":
+			fields.Priority = value
+			_, err = todo.ParsePriority(value)
+		case "tags":
+			fields.Tags = splitList(value)
+		case "project":
+			fields.Project = value
+		case "contexts":
+			fields.Contexts = splitList(value)
+		case "repeat":
+			fields.Repeat, repeatLine = value, n+1
+		case "remind":
+			fields.Remind = value
+			if value != "" {
+				_, err = todo.ParseOffsets(value)
+			}
+		case "estimate":
+			fields.Estimate = value
+			_, err = todo.ParseEstimate(value)
+		case "parent":
+			if value != "" {
+				if fields.Parent, err = parseID(value); err != nil {
+					err = fmt.Errorf("%q is not an item ID", value)
+				}
+			}
+		}
+		if err != nil {
+			problem(n+1, "%v", err)
+		}
+	}
+	if !closed {
+		problems = append(problems, fmt.Sprintf("line %d: the fields have to end with a line of ---", n))
+	}
+
+	// The repeat rule is checked once the due date it starts from is known,
+	// if that is right.
+	if due, err := todo.ParseDueDate(fields.Due); err == nil && fields.Repeat != "" {
+		if _, err := todo.ParseRepeat(fields.Repeat, due); err != nil {
+			problem(repeatLine, "%v", err)
+		}
+	}
+	if closed {
+		fields.Notes = strings.Trim(strings.Join(lines[n:], "\n"), "\n\t ")
+	}
+	if len(problems) > 0 {
+		return fields, errors.New(strings.Join(problems, "\n"))
+	}
+	return fields, nil
+}
+
+// splitList splits a field holding several tags or contexts, separated by
+// spaces or commas.
+func splitList(value string) []string {
+	return strings.FieldsFunc(value, func(r rune) bool { return r == ' ' || r == ',' })
+}
+
+// editItemFile opens fields as an item file in the editor and returns the
+// fields as saved. When they can't be read, the mistakes are shown and, at
+// a terminal, the user can go back to the file to put them right.
+func editItemFile(fields todo.TodoItem, pattern string) (todo.TodoItem, error) {
+	text := formatItemFile(fields)
+	for {
+		edited, err := editText(text, pattern)
+		if err != nil {
+			return fields, fmt.Errorf("editing the item: %w", err)
+		}
+		parsed, err := parseItemFile(edited)
+		if err == nil {
+			return parsed, nil
+		}
+		if !term.IsTerminal(int(os.Stdin.Fd())) {
+			return fields, err
+		}
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprint(os.Stderr, "Edit the item again? [Y/n] ")
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if a := strings.ToLower(strings.TrimSpace(answer)); a != "" && a != "y" && a != "yes" {
+			return fields, errors.New("nothing saved")
+		}
+		text = edited
+	}
+}
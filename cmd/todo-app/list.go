@@ -0,0 +1,416 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/buck06191/todo-app/pkg/format"
+	"github.com/buck06191/todo-app/pkg/todo"
+)
+
+// listCommand implements `todo-app list [@context...] [filter...]`, where
+// the filters are names saved with `todo-app filter save`. With -watch it
+// stays up, showing the list again whenever it changes.
+func listCommand() (*flag.FlagSet, func(args []string) error) {
+	fs := newFlagSet("list", "[@context...] [filter...] [flags]")
+	overdue := fs.Bool("overdue", false, "Only show items that are overdue.")
+	all := fs.Bool("all", false, "Include items that have been done.")
+	var tags stringList
+	fs.Var(&tags, "tag", "Only show items with this tag. Can be given more than once to require several tags.")
+	project := fs.String("project", "", "Only show items in this project.")
+	ready := fs.Bool("ready", false, "Only show items that aren't waiting on anything.")
+	groupBy := fs.String("group-by", "", "Show the items in sections by project, context or tag.")
+	sortBy := fs.String("sort", "", "Sort by these comma separated keys, each with a - in front to reverse it, e.g. due,-priority,created. The keys are "+strings.Join(todo.SortKeys(), ", ")+".")
+	whereExpr := fs.String("where", "", "Only show items matching a filter expression such as 'due<+2d and not done'. Done items are shown if it matches them, as with saved filters.")
+	output := outputFlag(fs)
+	absolute := absoluteFlag(fs)
+	watch := fs.Bool("watch", false, "Stay up, clearing the screen and showing the list again whenever it changes, e.g. in a tmux pane. Stop with an interrupt.")
+	return fs, func(args []string) error {
+		positional := parseInterspersed(fs, args)
+
+		var contexts, filterNames []string
+		for _, arg := range positional {
+			if strings.HasPrefix(arg, "@") {
+				contexts = append(contexts, arg)
+			} else {
+				filterNames = append(filterNames, arg)
+			}
+		}
+
+		group, err := groupFunc(*groupBy)
+		if err != nil {
+			return err
+		}
+		render, err := lookupOutput(*output)
+		if err != nil {
+			return err
+		}
+		if group != nil && render != nil {
+			return errors.New("-group-by can't be used with -output")
+		}
+		// -where is parsed again each time the list is shown as well, so
+		// that relative dates such as today in it move on while watching.
+		if _, err := parseWhere(*whereExpr); err != nil {
+			return err
+		}
+		var order todo.Comparator
+		if *sortBy != "" {
+			if order, err = todo.ParseSort(*sortBy); err != nil {
+				return err
+			}
+		}
+
+		// show prints the list to w, opening the store only while it does,
+		// since the bolt store can't be used by other commands while anything
+		// has it open.
+		show := func(w io.Writer) error {
+			store, err := openStore()
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			var filters []*todo.Filter
+			for _, name := range filterNames {
+				filter, err := todo.SavedFilter(store, name)
+				if err != nil {
+					return err
+				}
+				filters = append(filters, filter)
+			}
+			where, err := parseWhere(*whereExpr)
+			if err != nil {
+				return err
+			}
+			if where != nil {
+				filters = append(filters, where)
+			}
+
+			saved, err := store.List()
+			if err != nil {
+				return err
+			}
+
+			now := time.Now()
+			blocked := todo.OpenBlockers(saved)
+			var items []todo.ParsedTodoItem
+			for _, item := range saved {
+				if item.Completed && !*all && len(filters) == 0 {
+					continue
+				}
+				if !matchAll(filters, item, now) {
+					continue
+				}
+				if *ready && (item.Completed || len(blocked[item.ID]) > 0) {
+					continue
+				}
+				if *overdue && !item.IsOverdue(now) {
+					continue
+				}
+				if !hasAllTags(item, tags) || !hasAllContexts(item, contexts) {
+					continue
+				}
+				if *project != "" && !strings.EqualFold(item.Project, *project) {
+					continue
+				}
+				items = append(items, item)
+			}
+
+			printer := listPrinter{w: w, now: now, progress: todo.SubtaskProgress(saved), blocked: blocked, order: order, render: render, absolute: *absolute}
+			if group != nil {
+				return printer.printGroups(items, group)
+			}
+			return printer.print(items)
+		}
+		if *watch {
+			return watchList(show)
+		}
+		return show(os.Stdout)
+	}
+}
+
+// PrintList writes items to w, one per line, sorted by due date with overdue
+// items flagged. Subtasks are indented below their parent.
+func PrintList(w io.Writer, items []todo.ParsedTodoItem, now time.Time) error {
+	return listPrinter{w: w, now: now}.print(items)
+}
+
+// listPrinter writes items in the human readable list format.
+type listPrinter struct {
+	w   io.Writer
+	now time.Time
+	// progress is shown next to items with subtasks. It is worked out from
+	// the whole list so filtering doesn't change the counts.
+	progress map[int]todo.Progress
+	// blocked holds the open items each blocked item is waiting on.
+	blocked map[int][]int
+	// order is how the items are sorted, by due date if it is nil.
+	order todo.Comparator
+	// render writes the items in a machine readable format instead, if it
+	// is set. They are sorted but not arranged into a tree.
+	render format.Renderer
+	// absolute shows every due date as a date, rather than as "due
+	// tomorrow" and so on for the ones coming up soon.
+	absolute bool
+}
+
+func (p listPrinter) print(items []todo.ParsedTodoItem) error {
+	if len(items) == 0 && p.render == nil {
+		_, err := fmt.Fprintln(p.w, "Nothing to do!")
+		return err
+	}
+
+	sorted := make([]todo.ParsedTodoItem, len(items))
+	copy(sorted, items)
+	if p.order != nil {
+		todo.SortBy(sorted, p.order)
+	} else {
+		todo.SortByDue(sorted)
+	}
+	if p.render != nil {
+		return p.render.List(p.w, sorted)
+	}
+
+	shown := map[int]bool{}
+	for _, item := range sorted {
+		shown[item.ID] = true
+	}
+
+	// Items whose parent isn't being shown are printed at the top level.
+	t := &table{right: []bool{true}}
+	for _, item := range sorted {
+		if item.Parent == 0 || !shown[item.Parent] {
+			p.addTree(t, sorted, item, 0, map[int]bool{})
+		}
+	}
+	return t.write(p.w)
+}
+
+// addTree adds item to t followed by its subtasks, indented below it.
+func (p listPrinter) addTree(t *table, items []todo.ParsedTodoItem, item todo.ParsedTodoItem, depth int, seen map[int]bool) {
+	if seen[item.ID] {
+		return
+	}
+	seen[item.ID] = true
+
+	t.add(p.color(item), strconv.Itoa(item.ID), p.due(item), priorityMarker(item.EffectivePriority(p.now)), strings.Repeat("  ", depth)+p.text(item))
+	for _, child := range items {
+		if child.Parent == item.ID {
+			p.addTree(t, items, child, depth+1, seen)
+		}
+	}
+}
+
+// due returns the item's due date for the list: relative to now if it is
+// soon enough, unless -absolute was given.
+func (p listPrinter) due(item todo.ParsedTodoItem) string {
+	if !p.absolute {
+		if relative, ok := item.RelativeDue(p.now); ok {
+			return relative
+		}
+	}
+	return formatDue(item)
+}
+
+// color returns the color to show item in: red if it is overdue, yellow
+// if it is due today and magenta if its priority has been raised as it is
+// due soon.
+func (p listPrinter) color(item todo.ParsedTodoItem) string {
+	switch {
+	case item.Completed || item.Due.IsZero():
+		return colorNone
+	case item.IsOverdue(p.now):
+		return colorRed
+	}
+	y, m, d := item.Due.In(todo.Location).Date()
+	ny, nm, nd := p.now.In(todo.Location).Date()
+	if y == ny && m == nm && d == nd {
+		return colorYellow
+	}
+	if item.EffectivePriority(p.now) > item.Priority {
+		return colorMagenta
+	}
+	return colorNone
+}
+
+// text returns the item's text followed by markers for everything else
+// about it that is shown in the list.
+func (p listPrinter) text(item todo.ParsedTodoItem) string {
+	text := item.Todo
+	if item.Notes != "" {
+		text += " …"
+	}
+	if progress, ok := p.progress[item.ID]; ok {
+		text += fmt.Sprintf(" [%d/%d]", progress.Done, progress.Total)
+	}
+	if item.Project != "" {
+		text += " +" + item.Project
+	}
+	for _, context := range item.Contexts {
+		text += " @" + context
+	}
+	for _, tag := range item.Tags {
+		text += " #" + tag
+	}
+	if r, ok := item.Recurrence(); ok {
+		text += "  (" + r.Describe() + ")"
+	}
+	if on := p.blocked[item.ID]; len(on) > 0 && !item.Completed {
+		text += "  (waiting on " + joinIDs(on) + ")"
+	}
+	if item.Completed {
+		text += "  (done)"
+	} else if _, relative := item.RelativeDue(p.now); item.IsOverdue(p.now) && (p.absolute || !relative) {
+		// Relative due dates already say the item is overdue.
+		text += "  (overdue)"
+	}
+	if item.CurrentStatus() == todo.StatusDoing {
+		text += "  (doing)"
+	}
+	return text
+}
+
+// printGroups writes items in a section for each group returned by group.
+// Items in several groups, such as ones with two tags, are shown in each of
+// them, and items in none are shown at the end.
+func (p listPrinter) printGroups(items []todo.ParsedTodoItem, group func(todo.ParsedTodoItem) []string) error {
+	var names []string
+	groups := map[string][]todo.ParsedTodoItem{}
+	var rest []todo.ParsedTodoItem
+
+	for _, item := range items {
+		keys := group(item)
+		if len(keys) == 0 {
+			rest = append(rest, item)
+		}
+		for _, key := range keys {
+			if _, seen := groups[key]; !seen {
+				names = append(names, key)
+			}
+			groups[key] = append(groups[key], item)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if _, err := fmt.Fprintf(p.w, "== %s ==\n", name); err != nil {
+			return err
+		}
+		if err := p.print(groups[name]); err != nil {
+			return err
+		}
+		fmt.Fprintln(p.w)
+	}
+	if len(rest) > 0 {
+		if _, err := fmt.Fprintln(p.w, "== (none) =="); err != nil {
+			return err
+		}
+		return p.print(rest)
+	}
+	return nil
+}
+
+// groupFunc returns the grouping for `list -group-by name`, or nil if name
+// is empty.
+func groupFunc(name string) (func(todo.ParsedTodoItem) []string, error) {
+	switch name {
+	case "":
+		return nil, nil
+	case "project":
+		return func(item todo.ParsedTodoItem) []string {
+			if item.Project == "" {
+				return nil
+			}
+			return []string{"+" + item.Project}
+		}, nil
+	case "context":
+		return func(item todo.ParsedTodoItem) []string {
+			return prefixAll("@", item.Contexts)
+		}, nil
+	case "tag":
+		return func(item todo.ParsedTodoItem) []string {
+			return prefixAll("#", item.Tags)
+		}, nil
+	}
+	return nil, fmt.Errorf("can't group by %q, expected project, context or tag", name)
+}
+
+// dateFormat is the layout dates are shown with, set by the date_format
+// setting.
+var dateFormat = "2006-01-02"
+
+// formatDue formats the item's due date in the -tz time zone, leaving off
+// the time for items due all day.
+func formatDue(item todo.ParsedTodoItem) string {
+	switch {
+	case item.Due.IsZero():
+		return ""
+	case item.DueAllDay():
+		return item.Due.Format(dateFormat)
+	default:
+		return item.Due.In(todo.Location).Format(dateFormat + " 15:04")
+	}
+}
+
+// absoluteFlag adds the -absolute flag used by the commands that print the
+// list.
+func absoluteFlag(fs *flag.FlagSet) *bool {
+	return fs.Bool("absolute", false, fmt.Sprintf("Show due dates as dates, rather than as \"due in 3 days\" and so on for those within %d days.", todo.RelativeDueDays))
+}
+
+// priorityMarker returns one to three exclamation marks for prioritised
+// items.
+func priorityMarker(p todo.Priority) string {
+	return strings.Repeat("!", int(p))
+}
+
+// matchAll reports whether item matches every one of filters.
+func matchAll(filters []*todo.Filter, item todo.ParsedTodoItem, now time.Time) bool {
+	for _, filter := range filters {
+		if !filter.Match(item, now) {
+			return false
+		}
+	}
+	return true
+}
+
+func hasAllTags(item todo.ParsedTodoItem, tags []string) bool {
+	for _, tag := range tags {
+		if !item.HasTag(tag) {
+			return false
+		}
+	}
+	return true
+}
+
+func hasAllContexts(item todo.ParsedTodoItem, contexts []string) bool {
+	for _, context := range contexts {
+		if !item.HasContext(context) {
+			return false
+		}
+	}
+	return true
+}
+
+func prefixAll(prefix string, words []string) []string {
+	var prefixed []string
+	for _, word := range words {
+		prefixed = append(prefixed, prefix+word)
+	}
+	return prefixed
+}
+
+func joinIDs(ids []int) string {
+	var s []string
+	for _, id := range ids {
+		s = append(s, strconv.Itoa(id))
+	}
+	return strings.Join(s, ", ")
+}
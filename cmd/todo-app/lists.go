@@ -0,0 +1,341 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/buck06191/todo-app/pkg/todo"
+)
+
+// listPrefix starts the namespaces of the lists made with `todo-app lists
+// create`, keeping them apart from those of users and shared lists.
+const listPrefix = "lists/"
+
+// mainList is what -list calls the store's own list.
+const mainList = "main"
+
+// errListAndShared is returned when both -list and -shared are given.
+var errListAndShared = errors.New("give -list or -shared, not both")
+
+// createdKey is the meta key a new list is made with, so that it is there
+// before it has any items.
+const createdKey = "created"
+
+// namedList is a list made with `todo-app lists create`, opened with
+// -list. Closing it closes the store it is in.
+type namedList struct {
+	todo.Store
+	root todo.Store
+}
+
+// openNamed opens the list called name, which has to have been created.
+func openNamed(name string) (*namedList, error) {
+	root, err := openRootStore()
+	if err != nil {
+		return nil, err
+	}
+	if err := checkListExists(root, name); err != nil {
+		root.Close()
+		return nil, err
+	}
+	list, err := todo.Namespace(root, listPrefix+name)
+	if err != nil {
+		root.Close()
+		return nil, err
+	}
+	return &namedList{Store: list, root: root}, nil
+}
+
+func checkListExists(root todo.Store, name string) error {
+	names, err := listNames(root)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(names, name) {
+		return fmt.Errorf("no list called %q, make it with: todo-app lists create %s", name, name)
+	}
+	return nil
+}
+
+// Remove implements todo.Remover.
+func (l *namedList) Remove(ids ...int) error {
+	remover, ok := l.Store.(todo.Remover)
+	if !ok {
+		return todo.ErrCantRemove
+	}
+	return remover.Remove(ids...)
+}
+
+// SearchWords implements todo.Searcher so the list's index is still used.
+func (l *namedList) SearchWords(terms []string) ([]todo.ParsedTodoItem, error) {
+	if searcher, ok := l.Store.(todo.Searcher); ok {
+		return searcher.SearchWords(terms)
+	}
+	return l.Store.List()
+}
+
+// GetMeta implements todo.MetaStore, so saved filters work on named lists.
+func (l *namedList) GetMeta(key string) ([]byte, error) {
+	if meta, ok := l.Store.(todo.MetaStore); ok {
+		return meta.GetMeta(key)
+	}
+	return nil, nil
+}
+
+func (l *namedList) PutMeta(key string, value []byte) error {
+	if meta, ok := l.Store.(todo.MetaStore); ok {
+		return meta.PutMeta(key, value)
+	}
+	return todo.ErrNoMeta
+}
+
+func (l *namedList) MetaKeys() ([]string, error) {
+	if meta, ok := l.Store.(todo.MetaStore); ok {
+		return meta.MetaKeys()
+	}
+	return nil, nil
+}
+
+func (l *namedList) Close() error {
+	return l.root.Close()
+}
+
+// listNames returns the names of the lists made with `todo-app lists
+// create`, sorted.
+func listNames(root todo.Store) ([]string, error) {
+	ns, ok := root.(todo.Namespacer)
+	if !ok {
+		return nil, nil
+	}
+	all, err := ns.Namespaces()
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, name := range all {
+		if rest, ok := strings.CutPrefix(name, listPrefix); ok {
+			names = append(names, rest)
+		}
+	}
+	return names, nil
+}
+
+// validListName checks that name can be used for a list: letters, digits,
+// - and _, and not the name of the main list.
+func validListName(name string) error {
+	if !todo.ValidNamespace(name) || strings.Contains(name, "/") {
+		return fmt.Errorf("%w %q, use letters, digits, - and _", todo.ErrBadNamespace, name)
+	}
+	if name == mainList {
+		return fmt.Errorf("%w: %s is the store's own list", todo.ErrBadNamespace, mainList)
+	}
+	return nil
+}
+
+// listsCommand implements `todo-app lists`, showing the lists in the store
+// and creating, renaming and deleting them.
+func listsCommand() (*flag.FlagSet, func(args []string) error) {
+	fs := newFlagSet("lists", "[list | create <name> | rename <name> <new name> | delete <name> [-yes]]")
+	yes := fs.Bool("yes", false, "With delete, don't ask before deleting the list.")
+	return fs, func(args []string) error {
+		positional := parseInterspersed(fs, args)
+
+		sub := "list"
+		if len(positional) > 0 {
+			sub, positional = positional[0], positional[1:]
+		}
+		want := map[string]int{"list": 0, "create": 1, "rename": 2, "delete": 1}
+		n, ok := want[sub]
+		if !ok {
+			fs.Usage()
+			return fmt.Errorf("unknown lists command %q", sub)
+		}
+		if len(positional) != n {
+			fs.Usage()
+			return fmt.Errorf("wrong number of arguments for lists %s", sub)
+		}
+		for _, name := range positional {
+			if err := validListName(name); err != nil {
+				return err
+			}
+		}
+
+		root, err := openRootStore()
+		if err != nil {
+			return err
+		}
+		defer root.Close()
+		if _, ok := root.(todo.Namespacer); !ok {
+			return todo.ErrNoNamespaces
+		}
+		names, err := listNames(root)
+		if err != nil {
+			return err
+		}
+
+		switch sub {
+		case "list":
+			return printLists(root, names)
+		case "create":
+			name := positional[0]
+			if slices.Contains(names, name) {
+				return fmt.Errorf("there is already a list called %q", name)
+			}
+			list, err := todo.Namespace(root, listPrefix+name)
+			if err != nil {
+				return err
+			}
+			if err := todo.SaveMeta(list, createdKey, time.Now()); err != nil {
+				return err
+			}
+			fmt.Printf("Created list %s. Switch to it with: todo-app use %s\n", name, name)
+			return nil
+		case "rename":
+			old, name := positional[0], positional[1]
+			if err := checkListExists(root, old); err != nil {
+				return err
+			}
+			if err := todo.RenameNamespace(root, listPrefix+old, listPrefix+name); err != nil {
+				return err
+			}
+			// The config file keeps up, if it names the list.
+			if v, ok := userConfig.Get("list"); ok && v == old {
+				path, err := configFile()
+				if err != nil {
+					return err
+				}
+				if err := userConfig.Set("list", name); err != nil {
+					return err
+				}
+				if err := userConfig.Save(path); err != nil {
+					return err
+				}
+			}
+			fmt.Printf("Renamed list %s to %s\n", old, name)
+			return nil
+		case "delete":
+			name := positional[0]
+			if err := checkListExists(root, name); err != nil {
+				return err
+			}
+			if !*yes {
+				fmt.Printf("Delete the list %s with everything in it, including its trash? [y/N] ", name)
+				answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+				if a := strings.ToLower(strings.TrimSpace(answer)); a != "y" && a != "yes" {
+					fmt.Println("Nothing deleted.")
+					return nil
+				}
+			}
+			if err := root.(todo.Namespacer).DropNamespace(listPrefix + name); err != nil {
+				return err
+			}
+			fmt.Printf("Deleted list %s\n", name)
+			if current, _, _ := settingValue("list"); current == name {
+				fmt.Println("It was the default list; change that with: todo-app config set list <name>")
+			}
+			return nil
+		}
+		return nil
+	}
+}
+
+// printLists shows the main list and each named one with how many items
+// are open on it, marking the one commands use.
+func printLists(root todo.Store, names []string) error {
+	current := *listName
+	if current == "" {
+		current = mainList
+	}
+	for _, name := range append([]string{mainList}, names...) {
+		list := root
+		if name != mainList {
+			var err error
+			if list, err = todo.Namespace(root, listPrefix+name); err != nil {
+				return err
+			}
+		}
+		items, err := list.List()
+		if err != nil {
+			return fmt.Errorf("list %s: %w", name, err)
+		}
+		open := 0
+		for _, item := range items {
+			if !item.Completed {
+				open++
+			}
+		}
+		mark := " "
+		if name == current {
+			mark = "*"
+		}
+		fmt.Printf("%s %-16s %d open, %d done\n", mark, name, open, len(items)-open)
+	}
+	if len(names) == 0 {
+		fmt.Println("\nThere are no other lists. Make one with: todo-app lists create <name>")
+	}
+	return nil
+}
+
+// useCommand implements `todo-app use <list>`, making the list the one every
+// command works on from then on, by saving it as list in the config file.
+// Without a list it says which one is in use.
+func useCommand() (*flag.FlagSet, func(args []string) error) {
+	fs := newFlagSet("use", "[<list> | main]")
+	return fs, func(args []string) error {
+		positional := parseInterspersed(fs, args)
+		if len(positional) > 1 {
+			fs.Usage()
+			return errors.New("use takes one list")
+		}
+		if len(positional) == 0 {
+			current := *listName
+			if current == "" {
+				current = mainList
+			}
+			fmt.Printf("Using list %s\n", current)
+			return nil
+		}
+
+		name := positional[0]
+		if name != mainList {
+			if err := validListName(name); err != nil {
+				return err
+			}
+			root, err := openRootStore()
+			if err != nil {
+				return err
+			}
+			err = checkListExists(root, name)
+			root.Close()
+			if err != nil {
+				return err
+			}
+		}
+		path, err := configFile()
+		if err != nil {
+			return err
+		}
+		if name == mainList {
+			_, err = userConfig.Unset("list")
+		} else {
+			err = userConfig.Set("list", name)
+		}
+		if err != nil {
+			return err
+		}
+		if err := userConfig.Save(path); err != nil {
+			return err
+		}
+		fmt.Printf("Using list %s\n", name)
+		if env := envName("list"); os.Getenv(env) != "" {
+			fmt.Printf("$%s is set, though, and wins over it.\n", env)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,100 @@
+// Command todo-app is a small CLI todo list app built on top of the todo
+// package.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/buck06191/todo-app/pkg/todo"
+)
+
+var (
+	storePath  = flag.String("store", "", "Where the todo list is saved: the path of a JSON file or a store URL such as memory://. (default ~/.todo/todos.json)")
+	timeZone   = flag.String("tz", "", "Time zone to read and show due dates in, e.g. Europe/London. (default the local time zone)")
+	noColor    = flag.Bool("no-color", false, "Don't color the output. Setting NO_COLOR in the environment does the same.")
+	listName   = flag.String("list", "", "Work on the list of this name instead of the main one, see the lists command.")
+	sharedName = flag.String("shared", "", "Work on the shared list of this name instead of your own, see the share command.")
+	asUser     = flag.String("as", "", "User to act as on a shared list, whose role decides what is allowed. (default whoever manages the store)")
+	configPath = flag.String("config", "", "The config file giving the defaults of these flags and other settings, see the config command. $TODO_CONFIG does the same. (default ~/.config/todo-app/config.toml)")
+	encrypt    = flag.Bool("encrypt", false, "Encrypt the store with a passphrase, which is then needed every time it is opened: from $TODO_PASSPHRASE, the output of $TODO_PASSPHRASE_COMMAND, or asked for at the terminal. Works with JSON files and sqlite:// stores.")
+)
+
+// operation is what the journal records the command being run as, for
+// undo: its name and the arguments before any flags, which are left out so
+// passwords and tokens given as flags aren't saved.
+var operation string
+
+// PrettyPrintItem shows an item that has just been added, in the same form
+// as in the list.
+func PrettyPrintItem(item todo.ParsedTodoItem) error {
+	fmt.Println("Added:")
+	return listPrinter{w: os.Stdout, now: time.Now()}.print([]todo.ParsedTodoItem{item})
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+
+	if err := loadConfig(); err != nil {
+		fmt.Fprintf(os.Stderr, "todo-app: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *timeZone != "" {
+		loc, err := time.LoadLocation(*timeZone)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "todo-app: unknown time zone %q\n", *timeZone)
+			os.Exit(2)
+		}
+		todo.Location = loc
+	}
+
+	cmd := findCommand(flag.Arg(0))
+	if cmd == nil {
+		if path, ok := findPlugin(flag.Arg(0)); ok {
+			if err := runPlugin(path, flag.Args()[1:]); err != nil {
+				fmt.Fprintf(os.Stderr, "todo-app: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		fmt.Fprintf(os.Stderr, "Unknown command %q\n\n", flag.Arg(0))
+		usage()
+		os.Exit(2)
+	}
+
+	words := []string{cmd.name}
+	for _, arg := range flag.Args()[1:] {
+		if strings.HasPrefix(arg, "-") {
+			break
+		}
+		words = append(words, arg)
+	}
+	operation = strings.Join(words, " ")
+
+	// Completion runs at every press of tab, too often for hooks.
+	if cmd.name != "completion" {
+		if err := runPreHook(cmd.name, flag.Args()[1:]); err != nil {
+			fmt.Fprintf(os.Stderr, "todo-app: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if err := cmd.run(flag.Args()[1:]); err != nil {
+		var code exitCode
+		if errors.As(err, &code) {
+			os.Exit(int(code))
+		}
+		fmt.Fprintf(os.Stderr, "todo-app: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/buck06191/todo-app/pkg/todo"
+)
+
+// migrateCommand implements `todo-app migrate -to <url>`, copying
+// everything in the current store into another one, e.g. from the JSON file
+// to bolt.
+func migrateCommand() (*flag.FlagSet, func(args []string) error) {
+	fs := newFlagSet("migrate", "-to <store url>")
+	to := fs.String("to", "", "Store to copy the todo list into, e.g. bolt:///home/me/.todo/todos.db")
+	return fs, func(args []string) error {
+		fs.Parse(args)
+
+		if *to == "" {
+			fs.Usage()
+			return errors.New("migrate needs a store to copy to")
+		}
+
+		src, err := openRootStore()
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+
+		dst, err := todo.Open(*to)
+		if err != nil {
+			return err
+		}
+		defer dst.Close()
+
+		if err := todo.Copy(dst, src); err != nil {
+			return err
+		}
+
+		fmt.Printf("Copied the todo list to %s\n", *to)
+		return nil
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// noteCommand implements `todo-app note <id>`, opening the item's notes in
+// $EDITOR, or setting them directly with -m.
+func noteCommand() (*flag.FlagSet, func(args []string) error) {
+	fs := newFlagSet("note", "<id> [flags]")
+	message := fs.String("m", "", "Set the notes to this text instead of opening an editor. Use \"\" to clear them.")
+	return fs, func(args []string) error {
+		positional := parseInterspersed(fs, args)
+
+		if len(positional) != 1 {
+			fs.Usage()
+			return errors.New("note takes exactly one item ID")
+		}
+
+		id, err := parseID(positional[0])
+		if err != nil {
+			return err
+		}
+
+		store, err := openStore()
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+
+		item, err := store.Get(id)
+		if err != nil {
+			return err
+		}
+
+		setDirectly := false
+		fs.Visit(func(f *flag.Flag) { setDirectly = setDirectly || f.Name == "m" })
+
+		notes := *message
+		if !setDirectly {
+			notes, err = editText(item.Notes, fmt.Sprintf("todo-%d-*.md", item.ID))
+			if err != nil {
+				return fmt.Errorf("editing notes: %w", err)
+			}
+		}
+
+		notes = strings.TrimRight(notes, "\n\t ")
+		if notes == item.Notes {
+			fmt.Println("Notes unchanged.")
+			return nil
+		}
+
+		item.Notes = notes
+		if err := store.Update(item); err != nil {
+			return err
+		}
+		fmt.Printf("Updated the notes for %d %s\n", item.ID, item.Todo)
+		return nil
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/buck06191/todo-app/pkg/notify"
+	"github.com/buck06191/todo-app/pkg/todo"
+)
+
+// notifiedKey is the meta key of the notifications sent so far, so each
+// item is only notified about once when it comes due and once when it is
+// overdue.
+const notifiedKey = "notified"
+
+// notified is what an item was last notified about: its due date then, and
+// whether it was overdue.
+type notified struct {
+	Due     time.Time `json:"due"`
+	Overdue bool      `json:"overdue,omitempty"`
+}
+
+// maxNotifications is how many notifications notify sends at once; past
+// that, the rest are summed up in the last.
+const maxNotifications = 5
+
+// notifyCommand implements `todo-app notify`, sending a desktop notification
+// for each item coming due within -within or overdue that it hasn't sent
+// one for yet. It is meant to be run every few minutes, e.g. from cron.
+func notifyCommand() (*flag.FlagSet, func(args []string) error) {
+	fs := newFlagSet("notify", "[-within 1h] [-again] [-dry-run]")
+	within := age(time.Hour)
+	fs.Var(&within, "within", "Notify about items due within this long, e.g. 30m, 2h or 1d, as well as overdue ones.")
+	again := fs.Bool("again", false, "Notify about every item due, including those already notified about.")
+	dryRun := fs.Bool("dry-run", false, "Print the notifications instead of sending them.")
+	return fs, func(args []string) error {
+		fs.Parse(args)
+		if fs.NArg() > 0 {
+			fs.Usage()
+			return errors.New("notify takes no arguments")
+		}
+		if err := flagDefaults(fs, map[string]string{"within": "notify.within"}); err != nil {
+			return err
+		}
+
+		store, err := openList()
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+		items, err := store.List()
+		if err != nil {
+			return err
+		}
+		sent := map[int]notified{}
+		if err := todo.LoadMeta(store, notifiedKey, &sent); err != nil {
+			return fmt.Errorf("reading the notifications sent: %w", err)
+		}
+
+		now := time.Now()
+		var due []todo.ParsedTodoItem
+		keep := map[int]notified{}
+		for _, item := range items {
+			if item.Completed || item.Due.IsZero() || item.Due.After(now.Add(time.Duration(within))) {
+				continue
+			}
+			n := notified{Due: item.Due, Overdue: item.IsOverdue(now)}
+			if last, ok := sent[item.ID]; *again || !ok || !last.Due.Equal(n.Due) || n.Overdue && !last.Overdue {
+				due = append(due, item)
+			}
+			keep[item.ID] = n
+		}
+
+		for i, item := range due {
+			n := itemNotification(item, now)
+			if i == maxNotifications-1 && len(due) > maxNotifications {
+				n = notify.Notification{
+					Title: fmt.Sprintf("%d more items due", len(due)-i),
+					Body:  strings.Join(todos(due[i:]), ", "),
+				}
+			}
+			if *dryRun {
+				fmt.Printf("%s: %s\n", n.Title, n.Body)
+			} else if err := notify.Send(n); err != nil {
+				return err
+			}
+			if i == maxNotifications-1 {
+				break
+			}
+		}
+		if *dryRun {
+			return nil
+		}
+
+		// Only the items still due are remembered, so the list doesn't grow.
+		return todo.SaveMeta(store, notifiedKey, keep)
+	}
+}
+
+// itemNotification returns the notification for an item that is due, such
+// as "Pay rent" with "Due in 10 minutes".
+func itemNotification(item todo.ParsedTodoItem, now time.Time) notify.Notification {
+	when, ok := item.RelativeDue(now)
+	if !ok {
+		when = "due " + formatDue(item)
+		if item.IsOverdue(now) {
+			when = "overdue since " + formatDue(item)
+		}
+	}
+	return notify.Notification{
+		Title:  item.Todo,
+		Body:   strings.ToUpper(when[:1]) + when[1:],
+		Urgent: item.IsOverdue(now),
+	}
+}
+
+// todos returns the text of each of items.
+func todos(items []todo.ParsedTodoItem) []string {
+	texts := make([]string, len(items))
+	for i, item := range items {
+		texts[i] = item.Todo
+	}
+	return texts
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/buck06191/todo-app/pkg/todo"
+)
+
+// pluginPrefix starts the names of the executables run as commands that
+// todo-app doesn't have itself, as todo-app-foo is run for `todo-app foo`.
+const pluginPrefix = "todo-app-"
+
+// findPlugin returns the path of the executable on $PATH for the command
+// called name, if there is one.
+func findPlugin(name string) (string, bool) {
+	if name == "" || strings.ContainsAny(name, `/\`) || strings.HasPrefix(name, "-") {
+		return "", false
+	}
+	path, err := exec.LookPath(pluginPrefix + name)
+	return path, err == nil
+}
+
+// plugins returns the names of the commands there are executables on $PATH
+// for, sorted, leaving out any named the same as one of todo-app's own.
+func plugins() []string {
+	var names []string
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			name, ok := strings.CutPrefix(e.Name(), pluginPrefix)
+			if runtime.GOOS == "windows" {
+				name, _ = strings.CutSuffix(strings.ToLower(name), ".exe")
+			}
+			if !ok || name == "" || findCommand(name) != nil || slices.Contains(names, name) {
+				continue
+			}
+			if _, ok := findPlugin(name); ok {
+				names = append(names, name)
+			}
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// runPlugin runs the executable at path for a command todo-app doesn't
+// have, with args, and exits with its status. The global flags are passed
+// on in the environment, as the variables todo-app reads them from, so
+// that the todo-app in $TODO_APP works on the same list when the plugin
+// runs it: TODO_STORE, TODO_TIMEZONE, TODO_LIST, TODO_SHARED, TODO_CONFIG
+// and NO_COLOR, with TODO_AS for -as, which todo-app itself only takes as a
+// flag.
+func runPlugin(path string, args []string) error {
+	store := *storePath
+	if store == "" {
+		var err error
+		if store, err = todo.DefaultPath(); err != nil {
+			return err
+		}
+	}
+	config, err := configFile()
+	if err != nil {
+		return err
+	}
+	// Both lists are always passed, so that neither is taken from the
+	// config file beside the other.
+	list := *listName
+	if list == "" {
+		list = mainList
+	}
+	env := []string{
+		"TODO_STORE=" + store,
+		"TODO_LIST=" + list,
+		"TODO_SHARED=" + *sharedName,
+		"TODO_AS=" + *asUser,
+		"TODO_CONFIG=" + config,
+	}
+	if *timeZone != "" {
+		env = append(env, "TODO_TIMEZONE="+*timeZone)
+	}
+	if *noColor {
+		env = append(env, "NO_COLOR=1")
+	}
+	if self, err := os.Executable(); err == nil {
+		env = append(env, "TODO_APP="+self)
+	}
+
+	cmd := exec.Command(path, args...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	cmd.Env = append(os.Environ(), env...)
+	// An interrupt at the terminal reaches the plugin too, which decides
+	// what to do about it, so todo-app waits for it to finish.
+	signal.Ignore(os.Interrupt)
+	err = cmd.Run()
+	var exit *exec.ExitError
+	if errors.As(err, &exit) {
+		os.Exit(exit.ExitCode())
+	}
+	if err != nil {
+		return fmt.Errorf("running %s: %w", path, err)
+	}
+	os.Exit(0)
+	return nil
+}
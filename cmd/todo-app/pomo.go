@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"golang.org/x/term"
+
+	"github.com/buck06191/todo-app/pkg/notify"
+	"github.com/buck06191/todo-app/pkg/todo"
+)
+
+// pomoCommand implements `todo-app pomo <id>`, a pomodoro on an item: the
+// clock is started on it, a countdown runs for -length, and at the end the
+// clock is stopped and a desktop notification sent. Stopping the countdown
+// early with an interrupt logs the time worked so far.
+func pomoCommand() (*flag.FlagSet, func(args []string) error) {
+	fs := newFlagSet("pomo", "<id> [-length 25m]")
+	length := age(25 * time.Minute)
+	fs.Var(&length, "length", "How long to work for, e.g. 25m or 50m.")
+	quiet := fs.Bool("quiet", false, "Don't send a desktop notification at the end.")
+	return fs, func(args []string) error {
+		positional := parseInterspersed(fs, args)
+		if len(positional) != 1 {
+			fs.Usage()
+			return errors.New("pomo takes exactly one item ID")
+		}
+		if err := flagDefaults(fs, map[string]string{"length": "pomo.length"}); err != nil {
+			return err
+		}
+		if length <= 0 {
+			return errors.New("-length has to be more than 0")
+		}
+		id, err := parseID(positional[0])
+		if err != nil {
+			return err
+		}
+
+		item, err := startPomo(id)
+		if err != nil {
+			return err
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		end := time.Now().Add(time.Duration(length))
+		finished := countdown(ctx, item, end)
+
+		// The store is only opened again now, since the bolt store can't be used
+		// by other commands while anything has it open.
+		took, err := stopPomo(id)
+		if err != nil {
+			return err
+		}
+		if !finished {
+			fmt.Printf("Stopped early: %s on %d %s\n", formatLength(took), item.ID, item.Todo)
+			return nil
+		}
+		fmt.Printf("Done: %s on %d %s. Time for a break.\n", formatLength(took), item.ID, item.Todo)
+		if *quiet {
+			return nil
+		}
+		n := notify.Notification{
+			Title: "Pomodoro done",
+			Body:  fmt.Sprintf("%s on %s. Time for a break.", formatLength(took), item.Todo),
+		}
+		if err := notify.Send(n); err != nil && !errors.Is(err, notify.ErrUnsupported) {
+			return err
+		}
+		return nil
+	}
+}
+
+// startPomo starts the clock on the item, as start does.
+func startPomo(id int) (todo.ParsedTodoItem, error) {
+	store, err := openStore()
+	if err != nil {
+		return todo.ParsedTodoItem{}, err
+	}
+	defer store.Close()
+
+	item, err := store.Get(id)
+	if err != nil {
+		return item, err
+	}
+	if item.Completed {
+		return item, fmt.Errorf("%d is already done", item.ID)
+	}
+	now := time.Now()
+	if err := stopRunning(store, now); err != nil {
+		return item, err
+	}
+	// The item may have been one that was running.
+	if item, err = store.Get(id); err != nil {
+		return item, err
+	}
+	item.StartWork(now)
+	if item.CurrentStatus() == todo.StatusBacklog {
+		item.SetStatus(todo.StatusDoing, now)
+	}
+	return item, store.Update(item)
+}
+
+// stopPomo stops the clock on the item, returning how long it ran. It may
+// have been stopped already, by stop or done.
+func stopPomo(id int) (time.Duration, error) {
+	store, err := openStore()
+	if err != nil {
+		return 0, err
+	}
+	defer store.Close()
+
+	item, err := store.Get(id)
+	if err != nil {
+		return 0, err
+	}
+	took, err := item.StopWork(time.Now())
+	if errors.Is(err, todo.ErrNotStarted) && len(item.Worked) > 0 {
+		last := item.Worked[len(item.Worked)-1]
+		return last.End.Sub(last.Start), nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return took, store.Update(item)
+}
+
+// countdown shows the time left until end, on one line rewritten each
+// second on a terminal, until then or until ctx is done. It reports
+// whether it got to the end.
+func countdown(ctx context.Context, item todo.ParsedTodoItem, end time.Time) bool {
+	tty := term.IsTerminal(int(os.Stdout.Fd()))
+	if !tty {
+		fmt.Printf("Working on %d %s until %s\n", item.ID, item.Todo, end.In(todo.Location).Format("15:04"))
+	}
+	tick := time.NewTicker(time.Second)
+	defer tick.Stop()
+	for {
+		left := time.Until(end).Round(time.Second)
+		if tty {
+			fmt.Printf("\r\033[K%02d:%02d  %d %s", left/time.Minute, left%time.Minute/time.Second, item.ID, item.Todo)
+		}
+		if left <= 0 {
+			if tty {
+				fmt.Println()
+			}
+			return true
+		}
+		select {
+		case <-ctx.Done():
+			if tty {
+				fmt.Println()
+			}
+			return false
+		case <-tick.C:
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/buck06191/todo-app/pkg/server"
+	"github.com/buck06191/todo-app/pkg/todo"
+)
+
+// promptCache is what prompt saves between runs, so that most of them
+// don't open the store at all.
+type promptCache struct {
+	Text string `json:"text"`
+	// Modified is when the store's file was last changed when the text was
+	// worked out, and ValidUntil when the text goes out of date even if
+	// the store doesn't change, as items come due.
+	Modified   time.Time `json:"modified,omitzero"`
+	ValidUntil time.Time `json:"valid_until"`
+}
+
+// promptTTL is how long a cached prompt is used for stores that aren't a
+// file whose changes can be seen.
+const promptTTL = time.Minute
+
+// promptCommand implements `todo-app prompt`, a short summary for a shell
+// prompt such as "✓3 ⏰2 ❗1": the items done today, due today and
+// overdue, leaving out the ones there are none of. It is cached until the
+// store changes or the summary would, as it is run for every prompt.
+func promptCommand() (*flag.FlagSet, func(args []string) error) {
+	fs := newFlagSet("prompt", "[-plain] [-no-cache]")
+	plain := fs.Bool("plain", false, "Use words rather than symbols, e.g. \"3 done 2 due 1 overdue\".")
+	noCache := fs.Bool("no-cache", false, "Work the summary out from the store, without using or saving the cached one.")
+	return fs, func(args []string) error {
+		fs.Parse(args)
+		if fs.NArg() > 0 {
+			fs.Usage()
+			return errors.New("prompt takes no arguments")
+		}
+
+		now := time.Now()
+		cachePath, modified := promptCachePath(*plain), storeModified()
+		if !*noCache && cachePath != "" {
+			if cached, ok := readPromptCache(cachePath, modified, now); ok {
+				printPrompt(cached.Text)
+				return nil
+			}
+		}
+
+		store, err := openList()
+		if err != nil {
+			return err
+		}
+		items, err := store.List()
+		store.Close()
+		if err != nil {
+			return err
+		}
+
+		cache := summarizePrompt(items, now, *plain)
+		cache.Modified = modified
+		if modified.IsZero() {
+			cache.ValidUntil = now.Add(promptTTL)
+		}
+		printPrompt(cache.Text)
+		if *noCache || cachePath == "" {
+			return nil
+		}
+		// A cache that can't be written only makes the next prompt slower.
+		if raw, err := json.Marshal(cache); err == nil {
+			if os.MkdirAll(filepath.Dir(cachePath), 0o700) == nil {
+				os.WriteFile(cachePath, raw, 0o600)
+			}
+		}
+		return nil
+	}
+}
+
+func printPrompt(text string) {
+	if text != "" {
+		fmt.Println(text)
+	}
+}
+
+// summarizePrompt counts the items done today, due today and overdue at
+// now, and works out when the counts next change: at midnight, or when an
+// item comes due today or becomes overdue before then.
+func summarizePrompt(items []todo.ParsedTodoItem, now time.Time, plain bool) promptCache {
+	y, m, d := now.In(todo.Location).Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, todo.Location)
+	tomorrow := today.AddDate(0, 0, 1)
+	until := tomorrow
+
+	var done, due, overdue int
+	for _, item := range items {
+		switch {
+		case item.Completed:
+			if !item.CompletedAt.Before(today) && item.CompletedAt.Before(tomorrow) {
+				done++
+			}
+		case item.Due.IsZero():
+		case item.IsOverdue(now):
+			overdue++
+		case item.Due.Before(tomorrow):
+			due++
+			if by := item.DueBy(); by.Before(until) {
+				until = by
+			}
+		}
+	}
+
+	symbols := []string{"✓", "⏰", "❗"}
+	if plain {
+		symbols = []string{" done", " due", " overdue"}
+	}
+	var parts []string
+	for i, n := range []int{done, due, overdue} {
+		if n == 0 {
+			continue
+		}
+		if plain {
+			parts = append(parts, fmt.Sprintf("%d%s", n, symbols[i]))
+		} else {
+			parts = append(parts, fmt.Sprintf("%s%d", symbols[i], n))
+		}
+	}
+	return promptCache{Text: strings.Join(parts, " "), ValidUntil: until}
+}
+
+// promptCachePath returns the file the prompt for the list in use is
+// cached in, under the user's cache directory, or "" if there isn't one.
+func promptCachePath(plain bool) string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	key := strings.Join([]string{*storePath, *listName, *sharedName, *asUser, fmt.Sprint(plain)}, "\x00")
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(dir, "todo-app", "prompt-"+hex.EncodeToString(sum[:8])+".json")
+}
+
+// readPromptCache returns the cached prompt at path if it is still right:
+// the store hasn't changed since and it hasn't gone out of date.
+func readPromptCache(path string, modified, now time.Time) (promptCache, bool) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return promptCache{}, false
+	}
+	var cache promptCache
+	if err := json.Unmarshal(raw, &cache); err != nil {
+		return promptCache{}, false
+	}
+	return cache, cache.Modified.Equal(modified) && now.Before(cache.ValidUntil)
+}
+
+// storeModified returns when the file the list given by the global flags
+// is kept in, and the write-ahead log beside it for SQLite, was last
+// changed, or the zero time for stores that aren't a file.
+func storeModified() time.Time {
+	path := *storePath
+	if path == "" {
+		var err error
+		if path, err = todo.DefaultPath(); err != nil {
+			return time.Time{}
+		}
+	}
+	if _, rest, ok := strings.Cut(path, "://"); ok {
+		path, _, _ = strings.Cut(rest, "?")
+		if !filepath.IsAbs(path) {
+			return time.Time{}
+		}
+	} else {
+		// JSON stores keep each list in a file of its own.
+		var name string
+		switch {
+		case *listName != "" && *listName != mainList:
+			name = listPrefix + *listName
+		case *sharedName != "":
+			name = server.SharedNamespace(*sharedName)
+		}
+		list, err := todo.Namespace(todo.NewJSONStore(path), name)
+		if err != nil {
+			return time.Time{}
+		}
+		path = list.(*todo.JSONStore).Path()
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	modified := info.ModTime()
+	if wal, err := os.Stat(path + "-wal"); err == nil && wal.ModTime().After(modified) {
+		modified = wal.ModTime()
+	}
+	return modified
+}
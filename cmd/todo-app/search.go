@@ -0,0 +1,84 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/buck06191/todo-app/pkg/todo"
+)
+
+// searchCommand implements `todo-app search <query>`, finding items by their
+// text, notes and tags.
+func searchCommand() (*flag.FlagSet, func(args []string) error) {
+	fs := newFlagSet("search", "<query> [flags]")
+	useRegexp := fs.Bool("regex", false, "Treat the query as a regular expression, matched case-insensitively.")
+	all := fs.Bool("all", false, "Include items that have been done.")
+	whereExpr := fs.String("where", "", "Only show matches for a filter expression as well, as for list.")
+	output := outputFlag(fs)
+	absolute := absoluteFlag(fs)
+	return fs, func(args []string) error {
+		positional := parseInterspersed(fs, args)
+
+		if len(positional) == 0 {
+			fs.Usage()
+			return fmt.Errorf("nothing to search for")
+		}
+		query := strings.Join(positional, " ")
+
+		var re *regexp.Regexp
+		if *useRegexp {
+			var err error
+			if re, err = regexp.Compile("(?im)" + query); err != nil {
+				return fmt.Errorf("bad -regex query: %w", err)
+			}
+		}
+
+		where, err := parseWhere(*whereExpr)
+		if err != nil {
+			return err
+		}
+		render, err := lookupOutput(*output)
+		if err != nil {
+			return err
+		}
+
+		store, err := openStore()
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+
+		var found []todo.ParsedTodoItem
+		if re != nil {
+			found, err = todo.SearchRegexp(store, re)
+		} else {
+			found, err = todo.Search(store, query)
+		}
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		items := found[:0]
+		for _, item := range found {
+			if item.Completed && !*all && where == nil {
+				continue
+			}
+			if where == nil || where.Match(item, now) {
+				items = append(items, item)
+			}
+		}
+		if len(items) == 0 && render == nil {
+			fmt.Println("No matches.")
+			return nil
+		}
+
+		// Progress and blockers are left out rather than reading the whole
+		// list, which the index is there to avoid.
+		return listPrinter{w: os.Stdout, now: now, render: render, absolute: *absolute}.print(items)
+	}
+}
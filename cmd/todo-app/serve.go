@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/buck06191/todo-app/pkg/server"
+)
+
+// serveCommand implements `todo-app serve`, serving the list over HTTP until
+// interrupted.
+func serveCommand() (*flag.FlagSet, func(args []string) error) {
+	fs := newFlagSet("serve", "[flags]")
+	listen := fs.String("listen", "localhost:8080", "Address to listen on, or $TODO_SERVE_LISTEN. Use :8080 to accept connections from other machines.")
+	grpcListen := fs.String("grpc", "", "Address to serve the gRPC API on as well, e.g. localhost:9090.")
+	withGraphQL := fs.Bool("graphql", false, "Serve a GraphQL endpoint at /graphql too.")
+	ipRate := fs.Float64("ip-rate", 0, "Requests a second each address may make on average, or 0 for no limit.")
+	tokenRate := fs.Float64("token-rate", 0, "Requests a second that may be made with each API token, or from each address without one, or 0 for no limit.")
+	burst := fs.Int("burst", server.DefaultLimits.Burst, "Requests that may be made at once before -ip-rate and -token-rate apply.")
+	maxBody := fs.Int64("max-body", server.DefaultLimits.MaxBody, "Largest request body to read, in `bytes`.")
+	slackList := fs.String("slack-list", "", "Shared list the Slack slash command adds to and lists, rather than the store's own list. The command is answered at /slack/command once slack.signing_secret is set.")
+	openAPI := fs.String("openapi", "", "Write an OpenAPI 3 document describing the JSON API to this `file` and exit, or to standard output for -.")
+	return fs, func(args []string) error {
+		fs.Parse(args)
+		if err := flagDefaults(fs, map[string]string{"listen": "serve.listen", "grpc": "serve.grpc", "slack-list": "slack.list"}); err != nil {
+			return err
+		}
+		var given []string
+		if v, ok, err := settingValue("serve.tokens"); err != nil {
+			return err
+		} else if ok {
+			for _, token := range strings.Split(v, ",") {
+				if token = strings.TrimSpace(token); token != "" {
+					given = append(given, token)
+				}
+			}
+		}
+
+		if *openAPI != "" {
+			return writeOpenAPI(*openAPI)
+		}
+		if *maxBody <= 0 {
+			return errors.New("-max-body has to be more than 0")
+		}
+
+		store, err := openRootStore()
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+
+		tokens, err := server.Tokens(store)
+		if err != nil {
+			return err
+		}
+		for _, addr := range []string{*listen, *grpcListen} {
+			if len(tokens) == 0 && len(given) == 0 && addr != "" && !strings.HasPrefix(addr, "localhost:") && !strings.HasPrefix(addr, "127.0.0.1:") {
+				log.Printf("Warning: there are no API tokens, so anyone who can reach %s can change the list. Create one with `todo-app token create`.", addr)
+			}
+		}
+
+		api := server.New(store)
+		api.AllowTokens(given...)
+		api.SetLimits(server.Limits{IPRate: *ipRate, TokenRate: *tokenRate, Burst: *burst, MaxBody: *maxBody})
+		if *withGraphQL {
+			api.EnableGraphQL()
+		}
+		if secret, ok, err := settingValue("slack.signing_secret"); err != nil {
+			return err
+		} else if ok && secret != "" {
+			if *slackList != "" {
+				if _, err := server.FindSharedList(store, *slackList); err != nil {
+					return err
+				}
+			}
+			api.EnableSlack(secret, *slackList)
+			log.Printf("Answering the Slack slash command at /slack/command")
+		} else if *slackList != "" {
+			return errors.New("-slack-list needs the Slack app's signing secret in slack.signing_secret")
+		}
+		srv := &http.Server{
+			Addr:              *listen,
+			Handler:           api,
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+
+		grpcSrv := api.GRPC()
+		if *grpcListen != "" {
+			lis, err := net.Listen("tcp", *grpcListen)
+			if err != nil {
+				return err
+			}
+			log.Printf("Serving the gRPC API on %s", *grpcListen)
+			go func() {
+				if err := grpcSrv.Serve(lis); err != nil {
+					log.Printf("gRPC: %v", err)
+				}
+			}()
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		go func() {
+			<-ctx.Done()
+			shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			srv.Shutdown(shutdown)
+			// Watch streams never end by themselves, so don't wait for them.
+			grpcSrv.Stop()
+		}()
+
+		log.Printf("Serving the todo list on http://%s", *listen)
+		if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	}
+}
+
+// writeOpenAPI writes the OpenAPI document of the JSON API to path, or to
+// standard output if path is "-".
+func writeOpenAPI(path string) error {
+	doc, err := server.OpenAPI()
+	if err != nil {
+		return err
+	}
+	doc = append(doc, '\n')
+	if path == "-" {
+		_, err = os.Stdout.Write(doc)
+		return err
+	}
+	return os.WriteFile(path, doc, 0o644)
+}
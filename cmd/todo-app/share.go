@@ -0,0 +1,199 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/buck06191/todo-app/pkg/server"
+	"github.com/buck06191/todo-app/pkg/todo"
+)
+
+// sharedList is a shared list opened with -shared. Changes the role
+// doesn't allow are refused, and closing it closes the store it is in.
+type sharedList struct {
+	todo.Store
+	root todo.Store
+	name string
+	role server.Role
+}
+
+// openShared opens the shared list called name, acting as user.
+func openShared(name, user string) (todo.Store, error) {
+	root, err := openRootStore()
+	if err != nil {
+		return nil, err
+	}
+	list, role, err := server.SharedStore(root, name, user)
+	if err != nil {
+		root.Close()
+		return nil, err
+	}
+	return &sharedList{Store: list, root: root, name: name, role: role}, nil
+}
+
+func (l *sharedList) check() error {
+	if l.role.CanWrite() {
+		return nil
+	}
+	return fmt.Errorf("%w: you are a %s of %s and can't change it", server.ErrNotPermitted, l.role, l.name)
+}
+
+func (l *sharedList) Add(item todo.ParsedTodoItem) (todo.ParsedTodoItem, error) {
+	if err := l.check(); err != nil {
+		return item, err
+	}
+	return l.Store.Add(item)
+}
+
+func (l *sharedList) Update(item todo.ParsedTodoItem) error {
+	if err := l.check(); err != nil {
+		return err
+	}
+	return l.Store.Update(item)
+}
+
+func (l *sharedList) Delete(id int) error {
+	if err := l.check(); err != nil {
+		return err
+	}
+	return l.Store.Delete(id)
+}
+
+func (l *sharedList) Restore(id int) error {
+	if err := l.check(); err != nil {
+		return err
+	}
+	return l.Store.Restore(id)
+}
+
+func (l *sharedList) Purge() (int, error) {
+	if err := l.check(); err != nil {
+		return 0, err
+	}
+	return l.Store.Purge()
+}
+
+// Remove implements todo.Remover.
+func (l *sharedList) Remove(ids ...int) error {
+	if err := l.check(); err != nil {
+		return err
+	}
+	remover, ok := l.Store.(todo.Remover)
+	if !ok {
+		return todo.ErrCantRemove
+	}
+	return remover.Remove(ids...)
+}
+
+// SearchWords implements todo.Searcher so the list's index is still used.
+func (l *sharedList) SearchWords(terms []string) ([]todo.ParsedTodoItem, error) {
+	if searcher, ok := l.Store.(todo.Searcher); ok {
+		return searcher.SearchWords(terms)
+	}
+	return l.Store.List()
+}
+
+// GetMeta implements todo.MetaStore, so saved filters work on shared lists.
+func (l *sharedList) GetMeta(key string) ([]byte, error) {
+	if meta, ok := l.Store.(todo.MetaStore); ok {
+		return meta.GetMeta(key)
+	}
+	return nil, nil
+}
+
+func (l *sharedList) PutMeta(key string, value []byte) error {
+	if err := l.check(); err != nil {
+		return err
+	}
+	if meta, ok := l.Store.(todo.MetaStore); ok {
+		return meta.PutMeta(key, value)
+	}
+	return todo.ErrNoMeta
+}
+
+func (l *sharedList) MetaKeys() ([]string, error) {
+	if meta, ok := l.Store.(todo.MetaStore); ok {
+		return meta.MetaKeys()
+	}
+	return nil, nil
+}
+
+func (l *sharedList) Close() error {
+	return l.root.Close()
+}
+
+// shareCommand implements `todo-app share`, managing the shared lists
+// several users can work on. Changes are made as the user given with -as.
+func shareCommand() (*flag.FlagSet, func(args []string) error) {
+	fs := newFlagSet("share", "[list | create <list> | add <list> <user> [-role editor] | rm <list> <user> | delete <list>]")
+	role := fs.String("role", string(server.RoleEditor), "Role to give with add: owner, editor or viewer.")
+	return fs, func(args []string) error {
+		positional := parseInterspersed(fs, args)
+
+		sub := "list"
+		if len(positional) > 0 {
+			sub, positional = positional[0], positional[1:]
+		}
+		want := map[string]int{"list": 0, "create": 1, "add": 2, "rm": 2, "delete": 1}
+		if n, ok := want[sub]; ok && len(positional) != n {
+			fs.Usage()
+			return fmt.Errorf("wrong number of arguments for share %s", sub)
+		}
+
+		store, err := openRootStore()
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+
+		switch sub {
+		case "list":
+			lists, err := server.SharedLists(store)
+			if err != nil {
+				return err
+			}
+			lists = slices.DeleteFunc(lists, func(l server.SharedList) bool { return l.Role(*asUser) == "" })
+			if len(lists) == 0 {
+				fmt.Println("No shared lists yet.")
+				return nil
+			}
+			for _, l := range lists {
+				var members []string
+				for _, user := range slices.Sorted(maps.Keys(l.Members)) {
+					members = append(members, user+":"+string(l.Members[user]))
+				}
+				fmt.Printf("%-16s %s\n", l.Name, strings.Join(members, " "))
+			}
+			return nil
+		case "create":
+			if err := server.CreateSharedList(store, positional[0], *asUser); err != nil {
+				return err
+			}
+			fmt.Printf("Created shared list %s. Use it with: todo-app -shared %s -as <user> list\n", positional[0], positional[0])
+			return nil
+		case "add":
+			if err := server.Share(store, positional[0], *asUser, positional[1], server.Role(*role)); err != nil {
+				return err
+			}
+			fmt.Printf("Gave %s the %s role in %s\n", positional[1], *role, positional[0])
+			return nil
+		case "rm":
+			if err := server.Unshare(store, positional[0], *asUser, positional[1]); err != nil {
+				return err
+			}
+			fmt.Printf("Took %s out of %s\n", positional[1], positional[0])
+			return nil
+		case "delete":
+			if err := server.DeleteSharedList(store, positional[0], *asUser); err != nil {
+				return err
+			}
+			fmt.Printf("Deleted shared list %s\n", positional[0])
+			return nil
+		}
+		fs.Usage()
+		return fmt.Errorf("unknown share command %q", sub)
+	}
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"cmp"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/buck06191/todo-app/pkg/todo"
+)
+
+// showCommand implements `todo-app show <id>`, printing every field of one
+// item.
+func showCommand() (*flag.FlagSet, func(args []string) error) {
+	fs := newFlagSet("show", "<id or text> [flags]")
+	asJSON := fs.Bool("json", false, "Print the item as JSON, the same as -output json.")
+	output := outputFlag(fs)
+	return fs, func(args []string) error {
+		positional := parseInterspersed(fs, args)
+
+		if len(positional) != 1 {
+			fs.Usage()
+			return errors.New("show takes exactly one item ID or text")
+		}
+
+		if *asJSON {
+			*output = "json"
+		}
+		render, err := lookupOutput(*output)
+		if err != nil {
+			return err
+		}
+
+		store, err := openStore()
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+
+		// Items in the trash can be shown too, by ID.
+		item, err := findItem(store, positional[0])
+		if id, idErr := parseID(positional[0]); idErr == nil && errors.Is(err, todo.ErrNotFound) {
+			item, err = getAnywhere(store, id)
+		}
+		if err != nil {
+			return err
+		}
+
+		if render != nil {
+			return render.Item(os.Stdout, item)
+		}
+
+		saved, err := store.List()
+		if err != nil {
+			return err
+		}
+		return printDetails(os.Stdout, item, saved, time.Now())
+	}
+}
+
+// getAnywhere returns the item with the given ID whether it is on the list
+// or in the trash.
+func getAnywhere(store todo.Store, id int) (todo.ParsedTodoItem, error) {
+	item, err := store.Get(id)
+	if !errors.Is(err, todo.ErrNotFound) {
+		return item, err
+	}
+
+	trash, trashErr := store.Trash()
+	if trashErr != nil {
+		return item, trashErr
+	}
+	for _, trashed := range trash {
+		if trashed.ID == id {
+			return trashed, nil
+		}
+	}
+	return item, err
+}
+
+// printDetails writes a field per line for item. saved is the rest of the
+// list, used to describe the item's parent, subtasks and blockers.
+func printDetails(w io.Writer, item todo.ParsedTodoItem, saved []todo.ParsedTodoItem, now time.Time) error {
+	byID := map[int]todo.ParsedTodoItem{}
+	for _, other := range saved {
+		byID[other.ID] = other
+	}
+	describe := func(id int) string {
+		if other, ok := byID[id]; ok {
+			return fmt.Sprintf("%d %s", id, other.Todo)
+		}
+		return fmt.Sprintf("%d (deleted)", id)
+	}
+	stamp := func(t time.Time) string {
+		return t.In(todo.Location).Format(dateFormat + " 15:04")
+	}
+
+	var fields [][2]string
+	field := func(name, value string) {
+		if value != "" {
+			fields = append(fields, [2]string{name, value})
+		}
+	}
+
+	status := "open"
+	switch {
+	case !item.DeletedAt.IsZero():
+		status = "in the trash since " + stamp(item.DeletedAt)
+	case item.Completed:
+		status = "done " + stamp(item.CompletedAt)
+	case item.IsOverdue(now):
+		status = "overdue"
+	}
+	if item.CurrentStatus() == todo.StatusDoing && item.DeletedAt.IsZero() {
+		status += ", doing"
+	}
+	if item.Inbox && !item.Completed {
+		status += ", in the inbox"
+	}
+	field("Status", status)
+
+	if !item.Due.IsZero() {
+		due := formatDue(item)
+		if item.DueAllDay() {
+			due += " (all day)"
+		}
+		field("Due", due)
+	}
+	priority := item.Priority.String()
+	if raised := item.EffectivePriority(now); raised > item.Priority {
+		why := "due soon"
+		if item.IsOverdue(now) {
+			why = "overdue"
+		}
+		priority = fmt.Sprintf("%s (raised from %s, as it is %s)", raised, cmp.Or(priority, "none"), why)
+	}
+	field("Priority", priority)
+	if item.Project != "" {
+		field("Project", "+"+item.Project)
+	}
+	field("Contexts", strings.Join(prefixAll("@", item.Contexts), " "))
+	field("Tags", strings.Join(prefixAll("#", item.Tags), " "))
+	if r, ok := item.Recurrence(); ok {
+		field("Repeats", fmt.Sprintf("%s (%s)", r.Describe(), r))
+	}
+	switch {
+	case item.Snoozed == 1:
+		field("Snoozed", "once")
+	case item.Snoozed > 1:
+		field("Snoozed", fmt.Sprintf("%d times", item.Snoozed))
+	}
+	if item.Estimate != "" {
+		field("Estimate", item.Estimate)
+	}
+	if len(item.Worked) > 0 {
+		spent := formatLength(item.Spent(now))
+		if item.Running() {
+			spent += ", running since " + stamp(item.Worked[len(item.Worked)-1].Start)
+		}
+		field("Spent", spent)
+	}
+	if item.Remind != "" {
+		field("Remind", strings.ReplaceAll(item.Remind, ",", ", ")+" before")
+	}
+	if item.Parent != 0 {
+		field("Parent", describe(item.Parent))
+	}
+	if progress, ok := todo.SubtaskProgress(saved)[item.ID]; ok {
+		field("Subtasks", fmt.Sprintf("%d of %d done", progress.Done, progress.Total))
+	}
+	var waiting []string
+	for _, on := range item.BlockedBy {
+		waiting = append(waiting, describe(on))
+	}
+	field("Waiting on", strings.Join(waiting, ", "))
+	if !item.CreatedAt.IsZero() {
+		field("Created", stamp(item.CreatedAt))
+	}
+
+	if _, err := fmt.Fprintf(w, "%d  %s\n\n", item.ID, item.Todo); err != nil {
+		return err
+	}
+	for _, f := range fields {
+		if _, err := fmt.Fprintf(w, "  %-11s %s\n", f[0]+":", f[1]); err != nil {
+			return err
+		}
+	}
+	if item.Notes != "" {
+		fmt.Fprintf(w, "\n  Notes:\n")
+		for _, line := range strings.Split(item.Notes, "\n") {
+			if _, err := fmt.Fprintf(w, "    %s\n", line); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/buck06191/todo-app/pkg/slack"
+	"github.com/buck06191/todo-app/pkg/todo"
+)
+
+// slackCommand implements `todo-app slack digest`, posting what is overdue
+// and due today to a Slack channel through an incoming webhook, e.g. from
+// cron each morning. The slash command for adding to a list from Slack is
+// answered by serve.
+func slackCommand() (*flag.FlagSet, func(args []string) error) {
+	fs := newFlagSet("slack", "digest [-webhook <url>] [-where <filter>] [-print]")
+	webhook := fs.String("webhook", "", "URL of the Slack incoming webhook to post to, or slack.webhook in the config file.")
+	whereExpr := fs.String("where", "", "Only include items matching a filter expression, as for list.")
+	printOnly := fs.Bool("print", false, "Print the message instead of posting it.")
+	return fs, func(args []string) error {
+		positional := parseInterspersed(fs, args)
+
+		if len(positional) == 0 || positional[0] != "digest" {
+			fs.Usage()
+			if len(positional) == 0 {
+				return errors.New("slack needs a command, such as digest")
+			}
+			return fmt.Errorf("unknown slack command %q", positional[0])
+		}
+		if len(positional) > 1 {
+			fs.Usage()
+			return fmt.Errorf("unexpected argument %q", positional[1])
+		}
+		if err := flagDefaults(fs, map[string]string{"webhook": "slack.webhook"}); err != nil {
+			return err
+		}
+		if *webhook == "" && !*printOnly {
+			return errors.New("slack digest needs -webhook, or slack.webhook in the config file, to post to")
+		}
+		where, err := parseWhere(*whereExpr)
+		if err != nil {
+			return err
+		}
+
+		store, err := openList()
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+		saved, err := store.List()
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		var items []todo.ParsedTodoItem
+		for _, item := range saved {
+			if where == nil || where.Match(item, now) {
+				items = append(items, item)
+			}
+		}
+		overdue, agenda := todo.Agenda(items, now, 1, now)
+		msg := slack.Digest(overdue, agenda[0].Items, now)
+
+		if *printOnly {
+			fmt.Println(msg.Text)
+			return nil
+		}
+		if err := slack.Post(*webhook, msg); err != nil {
+			return fmt.Errorf("posting the digest: %w", err)
+		}
+		fmt.Printf("Posted the digest of %d overdue and %d due today to Slack.\n", len(overdue), len(agenda[0].Items))
+		return nil
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/buck06191/todo-app/pkg/todo"
+)
+
+// snoozeCommand implements `todo-app snooze <id> 2d` and `todo-app snooze
+// <id> -until friday`, putting an item off by moving its due date later.
+// Each time is counted on the item, and show and -where snoozed>=3 bring
+// out the ones that keep being put off.
+func snoozeCommand() (*flag.FlagSet, func(args []string) error) {
+	fs := newFlagSet("snooze", "<id> <how long> | <id> -until <date>")
+	until := fs.String("until", "", "Put the item off until this date instead, e.g. friday, \"next monday 9am\" or 2025-03-01.")
+	return fs, func(args []string) error {
+		positional := parseInterspersed(fs, args)
+
+		if len(positional) == 0 || (len(positional) == 2) == (*until != "") || len(positional) > 2 {
+			fs.Usage()
+			return errors.New("snooze takes an item ID and how long to put it off for, e.g. 2d, or -until")
+		}
+		id, err := parseID(positional[0])
+		if err != nil {
+			return err
+		}
+
+		store, err := openStore()
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+
+		item, err := store.Get(id)
+		if err != nil {
+			return err
+		}
+		if item.Completed {
+			return fmt.Errorf("%d is already done", item.ID)
+		}
+
+		now := time.Now()
+		var due time.Time
+		if *until != "" {
+			due, err = todo.ParseDueDate(*until)
+		} else {
+			due, err = item.SnoozeFor(positional[1], now)
+		}
+		if err != nil {
+			return err
+		}
+		if err := item.Snooze(due, now); err != nil {
+			return err
+		}
+		if err := store.Update(item); err != nil {
+			return err
+		}
+		times := "once"
+		if item.Snoozed > 1 {
+			times = fmt.Sprintf("%d times", item.Snoozed)
+		}
+		fmt.Printf("Snoozed: %d %s, now due %s (put off %s)\n", item.ID, item.Todo, formatDue(item), times)
+		return nil
+	}
+}
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/buck06191/todo-app/pkg/todo"
+)
+
+// maxBusiest is how many tags and projects stats prints; -output json has
+// them all.
+const maxBusiest = 5
+
+// maxBar is how wide the bar of the day or week with the most items done
+// is drawn.
+const maxBar = 30
+
+// sparks are the heights of a sparkline, lowest first.
+var sparks = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline draws counts as a line of bars of eight heights, the highest
+// for the biggest count, with nothing at all for none.
+func sparkline(counts []int) string {
+	most := 0
+	for _, n := range counts {
+		most = max(most, n)
+	}
+	var b strings.Builder
+	for _, n := range counts {
+		if n == 0 {
+			b.WriteRune(' ')
+			continue
+		}
+		b.WriteRune(sparks[(n*len(sparks)-1)/most])
+	}
+	return b.String()
+}
+
+// bar draws n as a bar width long for most, at least one wide for anything
+// but none.
+func bar(n, most, width int) string {
+	if n == 0 || most == 0 {
+		return ""
+	}
+	return strings.Repeat("█", max(1, n*width/most))
+}
+
+// statsCommand implements `todo-app stats [-since 30d]`, summing up the
+// items added and done each day or week, how long items take to get done,
+// what is overdue, the busiest tags and projects and the items snoozed
+// most. Archived items count as well, so archiving doesn't lose the
+// history.
+func statsCommand() (*flag.FlagSet, func(args []string) error) {
+	fs := newFlagSet("stats", "[-since 30d] [-week] [-output json]")
+	since := age(30 * 24 * time.Hour)
+	fs.Var(&since, "since", "How far back to go, e.g. 7d, 12w or 90d.")
+	week := fs.Bool("week", false, "Count by week, starting on Monday, instead of by day.")
+	output := fs.String("output", "", "Print the statistics as json instead, e.g. for a dashboard.")
+	return fs, func(args []string) error {
+		fs.Parse(args)
+		if fs.NArg() > 0 {
+			fs.Usage()
+			return errors.New("stats takes no arguments")
+		}
+		if *output != "" && *output != "json" {
+			return fmt.Errorf("-output only takes json, not %q", *output)
+		}
+
+		store, err := openList()
+		if err != nil {
+			return err
+		}
+		items, err := store.List()
+		store.Close()
+		if err != nil {
+			return err
+		}
+		archived, err := loadArchive("", "")
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		stats := todo.ComputeStats(append(items, archived...), now.Add(-time.Duration(since)), now, *week)
+		if *output == "json" {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(statsJSON(stats))
+		}
+		return printStats(os.Stdout, stats, *week)
+	}
+}
+
+// printStats writes stats for reading, with a line for each day or week.
+func printStats(w io.Writer, stats todo.Stats, week bool) error {
+	day := func(t time.Time) string { return t.In(todo.Location).Format(dateFormat) }
+	fmt.Fprintf(w, "Since %s\n\n", day(stats.Since))
+	fmt.Fprintf(w, "  Added:         %d\n", stats.Added)
+	done := fmt.Sprint(stats.Done)
+	if stats.DoneLate > 0 {
+		done += fmt.Sprintf(", %d of them late", stats.DoneLate)
+	}
+	fmt.Fprintf(w, "  Done:          %s\n", done)
+	if stats.MeanTimeToDone > 0 {
+		fmt.Fprintf(w, "  Time to done:  %s on average, %s median\n", formatLength(stats.MeanTimeToDone), formatLength(stats.MedianTimeToDone))
+	}
+	fmt.Fprintf(w, "  Open:          %d, %d overdue\n", stats.Open, stats.Overdue)
+	days := func(n int) string {
+		if n == 1 {
+			return "1 day"
+		}
+		return fmt.Sprintf("%d days", n)
+	}
+	fmt.Fprintf(w, "  Streak:        %s in a row with something done, the longest %s\n", days(stats.Streak), days(stats.LongestStreak))
+
+	heading := "Each day"
+	if week {
+		heading = "Each week, from Monday"
+	}
+	counts := make([]int, len(stats.Periods))
+	most := 0
+	for i, p := range stats.Periods {
+		counts[i] = p.Done
+		most = max(most, p.Done)
+	}
+	fmt.Fprintln(w, strings.TrimRight(fmt.Sprintf("\n%s:  %s", heading, sparkline(counts)), " "))
+	for _, p := range stats.Periods {
+		line := fmt.Sprintf("  %s  %4d added  %4d done  %s", day(p.Start), p.Added, p.Done, bar(p.Done, most, maxBar))
+		fmt.Fprintln(w, strings.TrimRight(line, " "))
+	}
+
+	busiest := func(heading, prefix string, counts []todo.StatsCount) {
+		if len(counts) == 0 {
+			return
+		}
+		fmt.Fprintf(w, "\n%s:\n", heading)
+		for _, c := range counts[:min(len(counts), maxBusiest)] {
+			fmt.Fprintf(w, "  %-20s %4d added  %4d done\n", prefix+c.Name, c.Added, c.Done)
+		}
+	}
+	busiest("Busiest tags", "#", stats.Tags)
+	busiest("Busiest projects", "+", stats.Projects)
+
+	if len(stats.Snoozed) > 0 {
+		fmt.Fprintf(w, "\nPut off most:\n")
+		for _, item := range stats.Snoozed[:min(len(stats.Snoozed), maxBusiest)] {
+			times := "once"
+			if item.Snoozed > 1 {
+				times = fmt.Sprintf("%d times", item.Snoozed)
+			}
+			fmt.Fprintf(w, "  %4d  %-40s snoozed %s\n", item.ID, item.Todo, times)
+		}
+	}
+	return nil
+}
+
+// formatLength formats a length of time to the nearest hour once it is
+// days long, or to the minute, such as "2d 4h", "3h 20m" or "3h".
+func formatLength(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour:
+		d = d.Round(time.Hour)
+		if h := d % (24 * time.Hour) / time.Hour; h > 0 {
+			return fmt.Sprintf("%dd %dh", d/(24*time.Hour), h)
+		}
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	case d >= time.Hour:
+		d = d.Round(time.Minute)
+		if m := d % time.Hour / time.Minute; m > 0 {
+			return fmt.Sprintf("%dh %dm", d/time.Hour, m)
+		}
+		return fmt.Sprintf("%dh", d/time.Hour)
+	}
+	return fmt.Sprintf("%dm", d.Round(time.Minute)/time.Minute)
+}
+
+// statsJSON is the stats as -output json prints them, with lengths of time
+// in seconds.
+func statsJSON(stats todo.Stats) any {
+	type period struct {
+		Start time.Time `json:"start"`
+		Added int       `json:"added"`
+		Done  int       `json:"done"`
+	}
+	type snoozed struct {
+		ID      int    `json:"id"`
+		Todo    string `json:"todo"`
+		Snoozed int    `json:"snoozed"`
+	}
+	type count struct {
+		Name  string `json:"name"`
+		Added int    `json:"added"`
+		Done  int    `json:"done"`
+	}
+	counts := func(in []todo.StatsCount) []count {
+		out := make([]count, len(in))
+		for i, c := range in {
+			out[i] = count(c)
+		}
+		return out
+	}
+	periods := make([]period, len(stats.Periods))
+	for i, p := range stats.Periods {
+		periods[i] = period(p)
+	}
+	put := make([]snoozed, len(stats.Snoozed))
+	for i, item := range stats.Snoozed {
+		put[i] = snoozed{ID: item.ID, Todo: item.Todo, Snoozed: item.Snoozed}
+	}
+	return struct {
+		Since                   time.Time `json:"since"`
+		Until                   time.Time `json:"until"`
+		Added                   int       `json:"added"`
+		Done                    int       `json:"done"`
+		DoneLate                int       `json:"done_late"`
+		MeanTimeToDoneSeconds   int64     `json:"mean_time_to_done_seconds"`
+		MedianTimeToDoneSeconds int64     `json:"median_time_to_done_seconds"`
+		Open                    int       `json:"open"`
+		Overdue                 int       `json:"overdue"`
+		Streak                  int       `json:"streak"`
+		LongestStreak           int       `json:"longest_streak"`
+		Periods                 []period  `json:"periods"`
+		Tags                    []count   `json:"tags"`
+		Projects                []count   `json:"projects"`
+		Snoozed                 []snoozed `json:"snoozed"`
+	}{
+		Since:                   stats.Since,
+		Until:                   stats.Until,
+		Added:                   stats.Added,
+		Done:                    stats.Done,
+		DoneLate:                stats.DoneLate,
+		MeanTimeToDoneSeconds:   int64(stats.MeanTimeToDone / time.Second),
+		MedianTimeToDoneSeconds: int64(stats.MedianTimeToDone / time.Second),
+		Open:                    stats.Open,
+		Overdue:                 stats.Overdue,
+		Streak:                  stats.Streak,
+		LongestStreak:           stats.LongestStreak,
+		Periods:                 periods,
+		Tags:                    counts(stats.Tags),
+		Projects:                counts(stats.Projects),
+		Snoozed:                 put,
+	}
+}
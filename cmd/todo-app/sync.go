@@ -0,0 +1,309 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/buck06191/todo-app/pkg/seal"
+	"github.com/buck06191/todo-app/pkg/todo"
+	"github.com/buck06191/todo-app/pkg/todosync"
+	"golang.org/x/term"
+)
+
+// syncCommand implements `todo-app sync`, keeping the list in step with the
+// same list on a `todo-app serve` server, so it can be used from several
+// devices.
+func syncCommand() (*flag.FlagSet, func(args []string) error) {
+	fs := newFlagSet("sync", "[status | conflicts | forget | keygen <file> | todoist | google] [-remote <url>] [-token <token>] [-device <name>] [-keyfile <file> | -passphrase] [-take here|theirs]")
+	remote := fs.String("remote", "", "URL of the server to sync with, or the name of one of the remotes in the config file, remembered for next time. Add /lists/<name> for a shared list on it.")
+	token := fs.String("token", "", "API token for the server, remembered along with -remote.")
+	device := fs.String("device", "", "Name of this device on the server. (default the host name)")
+	keyfile := fs.String("keyfile", "", "Encrypt the items with the key in this file before they are sent, so the server only keeps ciphertext. Make one with todo-app sync keygen <file> and copy it to each device. Remembered for next time.")
+	passphrase := fs.Bool("passphrase", false, "Encrypt the items with a passphrase instead, asked for at the terminal or taken from $TODO_SYNC_PASSPHRASE. Remembered for next time.")
+	take := fs.String("take", "", "With conflicts, resolve them all without asking by keeping the value on this device (here) or on the server (theirs).")
+	return fs, func(args []string) error {
+		// Todoist and Google Tasks are synced with flags of their own.
+		if len(args) > 0 && args[0] == "todoist" {
+			return runCommand(syncTodoistCommand, args[1:])
+		}
+		if len(args) > 0 && args[0] == "google" {
+			return runCommand(syncGoogleCommand, args[1:])
+		}
+
+		positional := parseInterspersed(fs, args)
+
+		sub := "sync"
+		if len(positional) > 0 {
+			sub, positional = positional[0], positional[1:]
+		}
+		if sub == "keygen" {
+			if len(positional) != 1 {
+				fs.Usage()
+				return errors.New("sync keygen needs the file to write the key to")
+			}
+			return syncKeygen(positional[0])
+		}
+		if len(positional) > 0 {
+			fs.Usage()
+			return fmt.Errorf("unexpected argument %q", positional[0])
+		}
+		if *keyfile != "" && *passphrase {
+			return errors.New("give -keyfile or -passphrase, not both")
+		}
+
+		store, err := openList()
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+		state, err := todosync.LoadState(store)
+		if err != nil {
+			return err
+		}
+
+		switch sub {
+		case "sync":
+			return syncNow(store, &state, *remote, *token, *device, *keyfile, *passphrase)
+		case "status":
+			return syncStatus(store, state)
+		case "conflicts":
+			return syncConflicts(store, &state, *take)
+		case "forget":
+			if err := todosync.SaveState(store, todosync.State{}); err != nil {
+				return err
+			}
+			fmt.Println("Forgot the sync state. The next sync will keep the items on both sides.")
+			return nil
+		}
+		fs.Usage()
+		return fmt.Errorf("unknown sync command %q", sub)
+	}
+}
+
+func syncNow(store todo.Store, state *todosync.State, remote, token, device, keyfile string, passphrase bool) error {
+	remote, err := resolveRemote(remote)
+	if err != nil {
+		return err
+	}
+	if remote != "" && state.Remote != "" && remote != state.Remote {
+		return fmt.Errorf("this list syncs with %s, run `todo-app sync forget` first to sync it with %s instead", state.Remote, remote)
+	}
+	if remote != "" {
+		state.Remote = remote
+	}
+	if state.Remote == "" {
+		return errors.New("sync needs -remote the first time, e.g. -remote https://todo.example.com")
+	}
+	if token != "" {
+		state.Token = token
+	}
+	if device != "" {
+		state.Device = device
+	}
+	if state.Device == "" {
+		state.Device, _ = os.Hostname()
+	}
+
+	// Changing the key pushes every item again, encrypted with the new one.
+	if keyfile != "" && keyfile != state.Keyfile || passphrase && !state.Passphrase {
+		state.Keyfile, state.Passphrase, state.Sealed = keyfile, passphrase, false
+	}
+
+	r, err := todosync.NewHTTPRemote(state.Remote, state.Token)
+	if err != nil {
+		return err
+	}
+	if state.Keyfile != "" || state.Passphrase {
+		key, err := syncKey(state)
+		if err != nil {
+			return err
+		}
+		r = todosync.Encrypt(r, key)
+	}
+	res, err := todosync.Sync(store, r, state)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Synced with %s: pulled %d and pushed %d changes\n", state.Remote, res.Pulled, res.Pushed)
+	if res.Conflicts > 0 {
+		fmt.Printf("Kept the values here for %d conflicting changes made on both sides. Resolve them with: todo-app sync conflicts\n", res.Conflicts)
+	}
+	return nil
+}
+
+func syncStatus(store todo.Store, state todosync.State) error {
+	if state.Remote == "" {
+		fmt.Println("This list isn't synced. Start with: todo-app sync -remote <url>")
+		return nil
+	}
+	pending, err := state.Pending(store)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Syncing with %s as %s\n", state.Remote, state.Device)
+	switch {
+	case state.Keyfile != "":
+		fmt.Printf("Items are encrypted with the key in %s\n", state.Keyfile)
+	case state.Passphrase:
+		fmt.Println("Items are encrypted with a passphrase")
+	}
+	if state.Synced.IsZero() {
+		fmt.Println("Never synced")
+	} else {
+		fmt.Printf("Last synced %s, at revision %d\n", state.Synced.In(todo.Location).Format("2006-01-02 15:04"), state.Rev)
+	}
+	fmt.Printf("%d items synced, %d changes to push\n", len(state.Items), len(pending))
+	if state.Queue != nil {
+		fmt.Printf("%d changes queued while the server couldn't be reached, to be sent again first\n", len(state.Queue.Push.Changes))
+	}
+	if len(state.Conflicts) > 0 {
+		fmt.Printf("%d conflicts to resolve with: todo-app sync conflicts\n", len(state.Conflicts))
+	}
+	return nil
+}
+
+// syncKey returns the key the list's items are encrypted with, from the key
+// file or passphrase state says, giving the device a salt if it has none.
+func syncKey(state *todosync.State) (*seal.Key, error) {
+	var secret []byte
+	switch {
+	case state.Keyfile != "":
+		data, err := os.ReadFile(state.Keyfile)
+		if err != nil {
+			return nil, err
+		}
+		secret = bytes.TrimSpace(data)
+	case os.Getenv("TODO_SYNC_PASSPHRASE") != "":
+		secret = []byte(os.Getenv("TODO_SYNC_PASSPHRASE"))
+	case term.IsTerminal(int(os.Stdin.Fd())):
+		fmt.Fprint(os.Stderr, "Passphrase: ")
+		line, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return nil, err
+		}
+		secret = line
+	default:
+		return nil, errors.New("this list is encrypted with a passphrase, set $TODO_SYNC_PASSPHRASE when not at a terminal")
+	}
+	if state.Salt == nil {
+		salt, err := seal.NewSalt()
+		if err != nil {
+			return nil, err
+		}
+		state.Salt = salt
+	}
+	return seal.NewKey(secret, state.Salt)
+}
+
+// syncKeygen writes a new random key to path, refusing to overwrite one.
+func syncKeygen(path string) error {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(f, base64.StdEncoding.EncodeToString(key)); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Printf("Wrote a new key to %s. Copy it to each device and sync with -keyfile %s\n", path, path)
+	return nil
+}
+
+// syncConflicts resolves the conflicts found by syncing, asking which side
+// to take for each one unless take says.
+func syncConflicts(store todo.Store, state *todosync.State, take string) error {
+	switch take {
+	case "", "here", "theirs":
+	default:
+		return fmt.Errorf("-take must be here or theirs, not %q", take)
+	}
+	if len(state.Conflicts) == 0 {
+		fmt.Println("No conflicts.")
+		return nil
+	}
+	if take == "" && !term.IsTerminal(int(os.Stdin.Fd())) {
+		return errors.New("resolving conflicts asks which side to take, give -take here or -take theirs when not at a terminal")
+	}
+	byID, err := itemsByID(store)
+	if err != nil {
+		return err
+	}
+
+	in := bufio.NewReader(os.Stdin)
+	for i := 0; i < len(state.Conflicts); {
+		c := state.Conflicts[i]
+		item := byID[c.ID]
+		from := "the server"
+		if c.Device != "" {
+			from = c.Device
+		}
+		fmt.Printf("Item %d %q: %s\n  here:   %s\n  %-7s %s\n",
+			c.ID, item.Todo, c.Field, todosync.Value(item, c.Field), from+":", todosync.Value(c.Theirs, c.Field))
+
+		answer := take
+		for answer == "" {
+			fmt.Print("Keep [h]ere, take [t]heirs or [s]kip? ")
+			line, err := in.ReadString('\n')
+			if err != nil {
+				fmt.Println()
+				return nil
+			}
+			switch strings.ToLower(strings.TrimSpace(line)) {
+			case "h", "here":
+				answer = "here"
+			case "t", "theirs":
+				answer = "theirs"
+			case "s", "skip":
+				answer = "skip"
+			}
+		}
+		if answer == "skip" {
+			i++
+			continue
+		}
+		if err := state.Resolve(store, i, answer == "theirs"); err != nil {
+			return err
+		}
+		if byID, err = itemsByID(store); err != nil {
+			return err
+		}
+	}
+	if n := len(state.Conflicts); n > 0 {
+		fmt.Printf("%d conflicts left\n", n)
+	} else {
+		fmt.Println("All conflicts resolved. Run todo-app sync to push the result.")
+	}
+	return nil
+}
+
+// itemsByID returns the items in store, including the trash, by ID.
+func itemsByID(store todo.Store) (map[int]todo.ParsedTodoItem, error) {
+	items, err := store.List()
+	if err != nil {
+		return nil, err
+	}
+	trash, err := store.Trash()
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int]todo.ParsedTodoItem, len(items)+len(trash))
+	for _, item := range append(items, trash...) {
+		byID[item.ID] = item
+	}
+	return byID, nil
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+// ANSI escape codes for the colors rows can be shown in.
+const (
+	colorNone    = ""
+	colorRed     = "\x1b[31m"
+	colorYellow  = "\x1b[33m"
+	colorMagenta = "\x1b[35m"
+	colorReset   = "\x1b[0m"
+)
+
+// table writes rows of cells in aligned columns. Columns with nothing in
+// any row are left out, and the last column is cut short if the rows would
+// be wider than the terminal.
+type table struct {
+	// right lists the columns that are aligned to the right.
+	right []bool
+	rows  []tableRow
+}
+
+type tableRow struct {
+	color string
+	cells []string
+}
+
+// add appends a row shown in color, which is one of the color constants.
+func (t *table) add(color string, cells ...string) {
+	t.rows = append(t.rows, tableRow{color: color, cells: cells})
+}
+
+// write writes the table to w. Colors are only used if w is a terminal and
+// they haven't been turned off.
+func (t *table) write(w io.Writer) error {
+	var widths []int
+	for _, row := range t.rows {
+		for i, cell := range row.cells {
+			if i >= len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[i] = max(widths[i], utf8.RuneCountInString(cell))
+		}
+	}
+
+	maxWidth := terminalWidth(w)
+	color := useColor(w)
+
+	for _, row := range t.rows {
+		var line strings.Builder
+		for i, cell := range row.cells {
+			if widths[i] == 0 {
+				continue
+			}
+			if line.Len() > 0 {
+				line.WriteString("  ")
+			}
+			if i == len(row.cells)-1 {
+				if maxWidth > 0 {
+					cell = truncate(cell, max(maxWidth-line.Len(), 10))
+				}
+				line.WriteString(cell)
+				break
+			}
+			pad := strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell))
+			if i < len(t.right) && t.right[i] {
+				line.WriteString(pad + cell)
+			} else {
+				line.WriteString(cell + pad)
+			}
+		}
+
+		text := strings.TrimRight(line.String(), " ")
+		if color && row.color != colorNone {
+			text = row.color + text + colorReset
+		}
+		if _, err := io.WriteString(w, text+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// truncate cuts s down to at most n runes, ending it with … if anything was
+// removed.
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:n-1]) + "…"
+}
+
+// fder is a writer with a file descriptor, as os.File and frame are.
+type fder interface {
+	Fd() uintptr
+}
+
+// terminalWidth returns the width of w if it is a terminal, or 0 if it
+// isn't or the width can't be found.
+func terminalWidth(w io.Writer) int {
+	f, ok := w.(fder)
+	if !ok || !term.IsTerminal(int(f.Fd())) {
+		return 0
+	}
+	width, _, err := term.GetSize(int(f.Fd()))
+	if err != nil {
+		return 0
+	}
+	return width
+}
+
+// useColor reports whether output to w should be colored: it has to be a
+// terminal, unless the config file says to always color it, and neither
+// -no-color nor NO_COLOR can have been set.
+func useColor(w io.Writer) bool {
+	if *noColor || os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	if alwaysColor {
+		return true
+	}
+	f, ok := w.(fder)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/buck06191/todo-app/pkg/todo"
+)
+
+// tagsCommand implements `todo-app tags`, listing every tag in use with the
+// number of items that have it.
+func tagsCommand() (*flag.FlagSet, func(args []string) error) {
+	fs := newFlagSet("tags", "[flags]")
+	all := fs.Bool("all", false, "Count items that have been done as well.")
+	return fs, func(args []string) error {
+		fs.Parse(args)
+
+		store, err := openStore()
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+
+		items, err := store.List()
+		if err != nil {
+			return err
+		}
+
+		open := items[:0]
+		for _, item := range items {
+			if !item.Completed || *all {
+				open = append(open, item)
+			}
+		}
+
+		counts := todo.CountTags(open)
+		if len(counts) == 0 {
+			fmt.Println("No tags yet.")
+			return nil
+		}
+		for _, c := range counts {
+			fmt.Printf("%4d  #%s\n", c.Count, c.Tag)
+		}
+		return nil
+	}
+}
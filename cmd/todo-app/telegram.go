@@ -0,0 +1,316 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/buck06191/todo-app/pkg/telegram"
+	"github.com/buck06191/todo-app/pkg/todo"
+)
+
+// telegramRetry is how long the bot waits to poll again after Telegram
+// can't be reached.
+const telegramRetry = 5 * time.Second
+
+// telegramHelp answers /help and /start in chats linked to a list.
+const telegramHelp = `Send me something to do and I'll add it, ending with "due <date>" for when it is due, e.g. Book the venue due friday 5pm.
+/today shows what is overdue and due today.
+/list shows everything still to do.
+/done <id or text> marks an item as done.`
+
+// telegramCommand implements `todo-app telegram`, a Telegram bot for adding
+// to and working through lists from chats. Each chat is linked to a list in
+// the config file, by telegram.chats.<chat id>.list or .shared, with .user
+// to act as on a shared list, so that nobody else can use the bot to get at
+// the store. Messages from other chats are answered with how to link them.
+func telegramCommand() (*flag.FlagSet, func(args []string) error) {
+	fs := newFlagSet("telegram", "[-token <token>] [-api <url>]")
+	token := fs.String("token", "", "The bot's token, from @BotFather. Better given as telegram.token in the config file or $TODO_TELEGRAM_TOKEN, kept out of the process list.")
+	api := fs.String("api", "", "URL of the Bot API server, for bots running their own. (default "+telegram.DefaultAPI+")")
+	return fs, func(args []string) error {
+		fs.Parse(args)
+		if fs.NArg() > 0 {
+			fs.Usage()
+			return errors.New("telegram takes no arguments")
+		}
+		if err := flagDefaults(fs, map[string]string{"token": "telegram.token"}); err != nil {
+			return err
+		}
+		if *token == "" {
+			return errors.New("telegram needs the bot's token, as -token or telegram.token in the config file")
+		}
+		chats, err := telegramChats()
+		if err != nil {
+			return err
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		bot := telegram.NewClient(*token, *api)
+		me, err := bot.Me(ctx)
+		if err != nil {
+			return err
+		}
+		log.Printf("Answering messages to @%s", me.Username)
+		if len(chats) == 0 {
+			log.Printf("No chats are linked to a list yet, so messages are only answered with how to link them")
+		}
+
+		var offset int64
+		for {
+			updates, err := bot.Updates(ctx, offset)
+			switch {
+			case ctx.Err() != nil:
+				log.Printf("Stopped")
+				return nil
+			case errors.Is(err, telegram.ErrUnauthorized):
+				return err
+			case err != nil:
+				log.Printf("Reading messages: %v", err)
+				select {
+				case <-ctx.Done():
+				case <-time.After(telegramRetry):
+				}
+				continue
+			}
+			for _, u := range updates {
+				offset = u.ID + 1
+				if u.Message == nil || strings.TrimSpace(u.Message.Text) == "" {
+					continue
+				}
+				answer := answerTelegram(chats, *u.Message)
+				if err := bot.Send(ctx, u.Message.Chat.ID, answer); err != nil {
+					log.Printf("Answering chat %d: %v", u.Message.Chat.ID, err)
+				}
+			}
+		}
+	}
+}
+
+// telegramChats returns the lists the chats given in the config file are
+// linked to, by chat ID.
+func telegramChats() (map[int64]target, error) {
+	chats := map[int64]target{}
+	for _, key := range userConfig.Keys() {
+		rest, ok := strings.CutPrefix(key, "telegram.chats.")
+		if !ok {
+			continue
+		}
+		name, _, _ := strings.Cut(rest, ".")
+		id, err := strconv.ParseInt(name, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %q is not a chat ID", key, name)
+		}
+		if _, ok := chats[id]; ok {
+			continue
+		}
+		var t target
+		for field, value := range map[string]*string{"list": &t.list, "shared": &t.shared, "user": &t.as} {
+			if *value, _, err = settingValue("telegram.chats." + name + "." + field); err != nil {
+				return nil, err
+			}
+		}
+		if t.list != "" && t.list != mainList && t.shared != "" {
+			return nil, fmt.Errorf("telegram.chats.%s: give list or shared, not both", name)
+		}
+		chats[id] = t
+	}
+	return chats, nil
+}
+
+// answerTelegram carries out what msg asks of the list its chat is linked
+// to, returning what to answer with.
+func answerTelegram(chats map[int64]target, msg telegram.Message) string {
+	command, text := telegram.Command(msg.Text)
+	t, ok := chats[msg.Chat.ID]
+	if !ok {
+		log.Printf("Message from chat %d, which isn't linked to a list", msg.Chat.ID)
+		return fmt.Sprintf("This chat isn't linked to a list yet. Link it to one with:\ntodo-app config set telegram.chats.%d.list main", msg.Chat.ID)
+	}
+
+	var answer string
+	var err error
+	switch command {
+	case "start", "help":
+		return telegramHelp
+	case "", "add":
+		answer, err = telegramAdd(t, text)
+	case "today":
+		answer, err = telegramToday(t)
+	case "list":
+		answer, err = telegramList(t)
+	case "done":
+		answer, err = telegramDone(t, text)
+	default:
+		return fmt.Sprintf("There's no /%s.\n%s", command, telegramHelp)
+	}
+	if err != nil {
+		return "Sorry, " + err.Error()
+	}
+	return answer
+}
+
+func telegramAdd(t target, text string) (string, error) {
+	if text == "" {
+		return "", errors.New("there's nothing to add, try /add Buy milk")
+	}
+	task, due := todo.SplitDue(text)
+	item, err := todo.ParseItem(todo.TodoItem{Todo: task, Due: due})
+	if err != nil {
+		return "", err
+	}
+	store, err := openTarget(t, "telegram add")
+	if err != nil {
+		return "", err
+	}
+	defer store.Close()
+	if item, err = store.Add(item); err != nil {
+		return "", err
+	}
+	if item.Due.IsZero() {
+		return "Added " + chatLine(item, ""), nil
+	}
+	return "Added " + chatLine(item, "due "+formatDue(item)), nil
+}
+
+func telegramToday(t target) (string, error) {
+	store, err := openTarget(t, "telegram today")
+	if err != nil {
+		return "", err
+	}
+	defer store.Close()
+	saved, err := store.List()
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	overdue, agenda := todo.Agenda(saved, now, 1, now)
+	if len(overdue) == 0 && len(agenda[0].Items) == 0 {
+		return "Nothing is due today.", nil
+	}
+	var b strings.Builder
+	if len(overdue) > 0 {
+		b.WriteString("Overdue:\n")
+		for _, item := range overdue {
+			b.WriteString(chatLine(item, "due "+formatDue(item)) + "\n")
+		}
+	}
+	if len(agenda[0].Items) > 0 {
+		b.WriteString("Today:\n")
+		for _, item := range agenda[0].Items {
+			when := ""
+			if !item.DueAllDay() {
+				when = item.Due.In(todo.Location).Format("15:04")
+			}
+			b.WriteString(chatLine(item, when) + "\n")
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+func telegramList(t target) (string, error) {
+	store, err := openTarget(t, "telegram list")
+	if err != nil {
+		return "", err
+	}
+	defer store.Close()
+	saved, err := store.List()
+	if err != nil {
+		return "", err
+	}
+	open := slices.DeleteFunc(saved, func(item todo.ParsedTodoItem) bool { return item.Completed })
+	if len(open) == 0 {
+		return "There's nothing to do.", nil
+	}
+	todo.SortByDue(open)
+	lines := make([]string, len(open))
+	for i, item := range open {
+		when := ""
+		if !item.Due.IsZero() {
+			when = "due " + formatDue(item)
+		}
+		lines[i] = chatLine(item, when)
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// telegramDone marks the item arg names as done: the one with that ID, or
+// the item still to do whose task best matches it. When several match,
+// they are listed for one to be picked by ID.
+func telegramDone(t target, arg string) (string, error) {
+	if arg == "" {
+		return "", errors.New("which item? Give its ID or some of its text, e.g. /done milk")
+	}
+	store, err := openTarget(t, "telegram done "+arg)
+	if err != nil {
+		return "", err
+	}
+	defer store.Close()
+
+	var item todo.ParsedTodoItem
+	if _, err := strconv.Atoi(arg); err == nil {
+		id, err := parseID(arg)
+		if err != nil {
+			return "", err
+		}
+		if item, err = store.Get(id); err != nil {
+			return "", err
+		}
+	} else {
+		saved, err := store.List()
+		if err != nil {
+			return "", err
+		}
+		open := slices.DeleteFunc(saved, func(item todo.ParsedTodoItem) bool { return item.Completed })
+		found := todo.Fuzzy(open, arg)
+		switch len(found) {
+		case 0:
+			return "", fmt.Errorf("nothing still to do matches %q", arg)
+		case 1:
+			item = found[0]
+		default:
+			lines := []string{fmt.Sprintf("%d items match %q, which one? Send /done with its ID.", len(found), arg)}
+			for _, item := range found {
+				lines = append(lines, chatLine(item, ""))
+			}
+			return strings.Join(lines, "\n"), nil
+		}
+	}
+	if item.Completed {
+		return fmt.Sprintf("%d is already done.", item.ID), nil
+	}
+
+	now := time.Now()
+	item.SetStatus(todo.StatusDone, now)
+	if err := store.Update(item); err != nil {
+		return "", err
+	}
+	answer := "Done: " + chatLine(item, "")
+	if next, ok := item.NextOccurrence(now); ok {
+		if next, err = store.Add(next); err != nil {
+			return "", err
+		}
+		answer += "\nNext: " + chatLine(next, "due "+formatDue(next))
+	}
+	return answer, nil
+}
+
+// chatLine is an item as a line of a chat message, with when it is due if
+// that isn't empty.
+func chatLine(item todo.ParsedTodoItem, when string) string {
+	line := fmt.Sprintf("%d %s", item.ID, item.Todo)
+	if when != "" {
+		line += " (" + when + ")"
+	}
+	return line
+}
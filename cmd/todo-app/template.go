@@ -0,0 +1,169 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/buck06191/todo-app/pkg/todo"
+)
+
+// templateCommand implements `todo-app template`, managing the templates
+// items can be added with by add -template.
+func templateCommand() (*flag.FlagSet, func(args []string) error) {
+	fs := newFlagSet("template", "[list | show <name> | save <name> [flags] | rm <name>]")
+	due := fs.String("due", "", "When items added with the template are due, read as they are added, e.g. \"tomorrow\" or \"friday 5pm\".")
+	priority := fs.String("priority", "", "How important items added with the template are: low, medium or high.")
+	var tags stringList
+	fs.Var(&tags, "tag", "Tag items added with the template. Can be given more than once.")
+	project := fs.String("project", "", "Project items added with the template belong to.")
+	var contexts stringList
+	fs.Var(&contexts, "context", "Context items added with the template can be done in. Can be given more than once.")
+	repeat := fs.String("repeat", "", "Repeat items added with the template when they are done, as add -repeat takes it.")
+	remind := fs.String("remind", "", "Remind of items added with the template this long before they are due, as add -remind takes it.")
+	estimate := fs.String("estimate", "", "How long items added with the template are expected to take, e.g. 2h.")
+	notes := fs.String("notes", "", "Notes for items added with the template.")
+	var subtasks stringList
+	fs.Var(&subtasks, "subtask", "Add a subtask with this text under each item added with the template. Can be given more than once.")
+	return fs, func(args []string) error {
+		positional := parseInterspersed(fs, args)
+
+		sub := "list"
+		if len(positional) > 0 {
+			sub, positional = positional[0], positional[1:]
+		}
+		if (sub == "list" && len(positional) != 0) || (sub != "list" && len(positional) != 1) {
+			fs.Usage()
+			return fmt.Errorf("wrong number of arguments for template %s", sub)
+		}
+		flagged := false
+		fs.Visit(func(*flag.Flag) { flagged = true })
+		if flagged && sub != "save" {
+			fs.Usage()
+			return fmt.Errorf("template %s takes no flags", sub)
+		}
+
+		store, err := openStore()
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+
+		switch sub {
+		case "list":
+			templates, err := todo.Templates(store)
+			if err != nil {
+				return err
+			}
+			if len(templates) == 0 {
+				fmt.Println("No templates yet.")
+				return nil
+			}
+			for _, name := range slices.Sorted(maps.Keys(templates)) {
+				fmt.Printf("%-16s %s\n", name, describeTemplate(templates[name]))
+			}
+			return nil
+		case "show":
+			t, err := todo.SavedTemplate(store, positional[0])
+			if err != nil {
+				return err
+			}
+			printTemplate(t)
+			return nil
+		case "save":
+			name := positional[0]
+			t := todo.Template{
+				Due:      *due,
+				Priority: *priority,
+				Tags:     tags,
+				Project:  *project,
+				Contexts: contexts,
+				Repeat:   *repeat,
+				Remind:   *remind,
+				Estimate: *estimate,
+				Notes:    *notes,
+				Subtasks: subtasks,
+			}
+			if err := todo.SaveTemplate(store, name, t); err != nil {
+				return err
+			}
+			fmt.Printf("Saved template %s: %s\n", name, describeTemplate(t))
+			return nil
+		case "rm":
+			if err := todo.DeleteTemplate(store, positional[0]); err != nil {
+				return err
+			}
+			fmt.Printf("Deleted template %s\n", positional[0])
+			return nil
+		}
+		fs.Usage()
+		return fmt.Errorf("unknown template command %q", sub)
+	}
+}
+
+// describeTemplate sums up the fields t fills in on one line, as the task
+// of an item is written with them, e.g. "+home #shopping (due: tomorrow,
+// priority: low, 2 subtasks)".
+func describeTemplate(t todo.Template) string {
+	var words []string
+	if t.Project != "" {
+		words = append(words, "+"+t.Project)
+	}
+	for _, c := range t.Contexts {
+		words = append(words, "@"+c)
+	}
+	for _, tag := range t.Tags {
+		words = append(words, "#"+tag)
+	}
+	var about []string
+	for _, field := range []struct{ name, value string }{
+		{"due", t.Due}, {"priority", t.Priority}, {"repeat", t.Repeat},
+		{"remind", t.Remind}, {"estimate", t.Estimate},
+	} {
+		if field.value != "" {
+			about = append(about, field.name+": "+field.value)
+		}
+	}
+	if t.Notes != "" {
+		about = append(about, "notes")
+	}
+	switch n := len(t.Subtasks); {
+	case n == 1:
+		about = append(about, "1 subtask")
+	case n > 1:
+		about = append(about, fmt.Sprintf("%d subtasks", n))
+	}
+	if len(about) > 0 {
+		words = append(words, "("+strings.Join(about, ", ")+")")
+	}
+	if len(words) == 0 {
+		return "(nothing filled in)"
+	}
+	return strings.Join(words, " ")
+}
+
+// printTemplate prints each of the fields t fills in, with its notes and
+// subtasks below them.
+func printTemplate(t todo.Template) {
+	for _, field := range []struct{ name, value string }{
+		{"Due", t.Due}, {"Priority", t.Priority},
+		{"Tags", strings.Join(t.Tags, " ")}, {"Project", t.Project},
+		{"Contexts", strings.Join(t.Contexts, " ")}, {"Repeat", t.Repeat},
+		{"Remind", t.Remind}, {"Estimate", t.Estimate},
+	} {
+		if field.value != "" {
+			fmt.Printf("%-9s %s\n", field.name+":", field.value)
+		}
+	}
+	if t.Notes != "" {
+		fmt.Printf("\n%s\n", strings.TrimRight(t.Notes, "\n"))
+	}
+	if len(t.Subtasks) > 0 {
+		fmt.Println("\nSubtasks:")
+		for _, task := range t.Subtasks {
+			fmt.Printf("  %s\n", task)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/buck06191/todo-app/pkg/todo"
+	"github.com/buck06191/todo-app/pkg/todoist"
+)
+
+// syncTodoistCommand implements `todo-app sync todoist`, keeping the list in
+// step with a Todoist account.
+func syncTodoistCommand() (*flag.FlagSet, func(args []string) error) {
+	fs := newFlagSet("sync todoist", "[status | forget] [-token <token>] [-dry-run]")
+	token := fs.String("token", "", "Todoist API token, from Settings > Integrations > Developer, remembered for next time. $TODOIST_TOKEN is used instead if set, and isn't remembered.")
+	dryRun := fs.Bool("dry-run", false, "Show the changes a sync would make on both sides without making them.")
+	return fs, func(args []string) error {
+		positional := parseInterspersed(fs, args)
+
+		sub := "sync"
+		if len(positional) > 0 {
+			sub, positional = positional[0], positional[1:]
+		}
+		if len(positional) > 0 {
+			fs.Usage()
+			return fmt.Errorf("unexpected argument %q", positional[0])
+		}
+
+		store, err := openList()
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+		state, err := todoist.LoadState(store)
+		if err != nil {
+			return err
+		}
+
+		switch sub {
+		case "sync":
+			return todoistSync(store, &state, *token, *dryRun)
+		case "status":
+			if state.Synced.IsZero() {
+				fmt.Println("This list hasn't been synced with Todoist. Start with: todo-app sync todoist -token <token>")
+				return nil
+			}
+			fmt.Printf("Last synced with Todoist %s\n", state.Synced.In(todo.Location).Format("2006-01-02 15:04"))
+			fmt.Printf("%d items synced\n", len(state.Tasks))
+			return nil
+		case "forget":
+			if err := todoist.SaveState(store, todoist.State{}); err != nil {
+				return err
+			}
+			fmt.Println("Forgot the Todoist state and token. The next sync will keep the items on both sides.")
+			return nil
+		}
+		fs.Usage()
+		return fmt.Errorf("unknown sync todoist command %q", sub)
+	}
+}
+
+func todoistSync(store todo.Store, state *todoist.State, token string, dryRun bool) error {
+	if token != "" && !dryRun {
+		state.Token = token
+	}
+	if env := os.Getenv("TODOIST_TOKEN"); env != "" {
+		token = env
+	} else if token == "" {
+		token = state.Token
+	}
+	if token == "" {
+		return errors.New("sync todoist needs -token the first time, or $TODOIST_TOKEN")
+	}
+
+	changes, err := todoist.Sync(store, todoist.NewClient(todoist.DefaultURL, token), state, dryRun)
+	// The changes made before an error are shown along with it.
+	for _, c := range changes {
+		fmt.Println(c)
+	}
+	switch {
+	case err != nil:
+		return err
+	case len(changes) == 0:
+		fmt.Println("Nothing to sync")
+	case dryRun:
+		fmt.Println("Run without -dry-run to make these changes")
+	default:
+		fmt.Println("Synced with Todoist")
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/buck06191/todo-app/pkg/server"
+)
+
+// tokenCommand implements `todo-app token`, managing the API tokens serve
+// checks requests against.
+func tokenCommand() (*flag.FlagSet, func(args []string) error) {
+	fs := newFlagSet("token", "[list | create -name <name> [-user <user>] [-scope read,write] | revoke <name>]")
+	name := fs.String("name", "", "Name for the new token, e.g. the device it is for.")
+	user := fs.String("user", "", "User the new token is for, leave out for a token reaching the store's own list.")
+	scope := fs.String("scope", strings.Join(server.Scopes, ","), "Comma separated scopes for the new token: read to look at the list, write to change it.")
+	return fs, func(args []string) error {
+		positional := parseInterspersed(fs, args)
+
+		sub := "list"
+		if len(positional) > 0 {
+			sub, positional = positional[0], positional[1:]
+		}
+		if (sub == "list" && len(positional) != 0) || (sub == "create" && len(positional) != 0) || (sub == "revoke" && len(positional) != 1) {
+			fs.Usage()
+			return fmt.Errorf("wrong number of arguments for token %s", sub)
+		}
+
+		store, err := openRootStore()
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+
+		switch sub {
+		case "list":
+			tokens, err := server.Tokens(store)
+			if err != nil {
+				return err
+			}
+			if len(tokens) == 0 {
+				fmt.Println("No tokens yet, so serve lets anyone in.")
+				return nil
+			}
+			for _, t := range tokens {
+				owner := t.User
+				if owner == "" {
+					owner = "-"
+				}
+				fmt.Printf("%-16s %-16s %-12s created %s\n", t.Name, owner, strings.Join(t.Scopes, ","), t.CreatedAt.Format("2006-01-02 15:04"))
+			}
+			return nil
+		case "create":
+			if *name == "" {
+				fs.Usage()
+				return fmt.Errorf("token create needs a -name")
+			}
+			var scopes []string
+			for _, s := range strings.Split(*scope, ",") {
+				if s = strings.TrimSpace(s); s != "" {
+					scopes = append(scopes, s)
+				}
+			}
+			secret, err := server.CreateToken(store, *name, *user, scopes)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Created token %s. It won't be shown again:\n\n%s\n", *name, secret)
+			return nil
+		case "revoke":
+			if err := server.RevokeToken(store, positional[0]); err != nil {
+				return err
+			}
+			fmt.Printf("Revoked token %s\n", positional[0])
+			return nil
+		}
+		fs.Usage()
+		return fmt.Errorf("unknown token command %q", sub)
+	}
+}
@@ -0,0 +1,290 @@
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/buck06191/todo-app/pkg/todo"
+)
+
+// startCommand implements `todo-app start <id>`, starting the clock on an
+// item and moving it to doing. Only one item is worked on at a time, so the
+// clock on any other is stopped first.
+func startCommand() (*flag.FlagSet, func(args []string) error) {
+	fs := newFlagSet("start", "<id>")
+	return fs, func(args []string) error {
+		positional := parseInterspersed(fs, args)
+		if len(positional) != 1 {
+			fs.Usage()
+			return errors.New("start takes exactly one item ID")
+		}
+		id, err := parseID(positional[0])
+		if err != nil {
+			return err
+		}
+
+		store, err := openStore()
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+
+		item, err := store.Get(id)
+		if err != nil {
+			return err
+		}
+		if item.Completed {
+			return fmt.Errorf("%d is already done", item.ID)
+		}
+		if item.Running() {
+			return fmt.Errorf("%d is %w", item.ID, todo.ErrStarted)
+		}
+
+		now := time.Now()
+		if err := stopRunning(store, now); err != nil {
+			return err
+		}
+		item.StartWork(now)
+		if item.CurrentStatus() == todo.StatusBacklog {
+			item.SetStatus(todo.StatusDoing, now)
+		}
+		if err := store.Update(item); err != nil {
+			return err
+		}
+		fmt.Printf("Started: %d %s\n", item.ID, item.Todo)
+		return nil
+	}
+}
+
+// stopCommand implements `todo-app stop [<id>]`, stopping the clock on the
+// item being worked on.
+func stopCommand() (*flag.FlagSet, func(args []string) error) {
+	fs := newFlagSet("stop", "[<id>]")
+	return fs, func(args []string) error {
+		positional := parseInterspersed(fs, args)
+		if len(positional) > 1 {
+			fs.Usage()
+			return errors.New("stop takes at most one item ID")
+		}
+
+		store, err := openStore()
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+
+		now := time.Now()
+		if len(positional) == 0 {
+			items, err := store.List()
+			if err != nil {
+				return err
+			}
+			for _, item := range items {
+				if item.Running() {
+					return stopItem(store, item, now)
+				}
+			}
+			return errors.New("nothing has been started")
+		}
+
+		id, err := parseID(positional[0])
+		if err != nil {
+			return err
+		}
+		item, err := store.Get(id)
+		if err != nil {
+			return err
+		}
+		if !item.Running() {
+			return fmt.Errorf("%d is %w", item.ID, todo.ErrNotStarted)
+		}
+		return stopItem(store, item, now)
+	}
+}
+
+// stopRunning stops the clock on every item in store it is running on.
+func stopRunning(store todo.Store, now time.Time) error {
+	items, err := store.List()
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		if item.Running() {
+			if err := stopItem(store, item, now); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func stopItem(store todo.Store, item todo.ParsedTodoItem, now time.Time) error {
+	took, err := item.StopWork(now)
+	if err != nil {
+		return err
+	}
+	if err := store.Update(item); err != nil {
+		return err
+	}
+	fmt.Printf("Stopped: %d %s after %s, %s in all\n", item.ID, item.Todo, formatLength(took), formatLength(item.Spent(now)))
+	return nil
+}
+
+// logCommand implements `todo-app log <id> <how long>`, recording time
+// worked on an item without the clock having been started, such as 1h30m.
+func logCommand() (*flag.FlagSet, func(args []string) error) {
+	fs := newFlagSet("log", "<id> <how long> [-at <time>]")
+	at := fs.String("at", "", "When the work finished, e.g. \"yesterday 5pm\" or 2025-02-03T17:00. (default now)")
+	return fs, func(args []string) error {
+		positional := parseInterspersed(fs, args)
+		if len(positional) != 2 {
+			fs.Usage()
+			return errors.New("log takes an item ID and how long was spent on it, e.g. 1h30m")
+		}
+		id, err := parseID(positional[0])
+		if err != nil {
+			return err
+		}
+		took, err := todo.ParseDuration(positional[1])
+		if err != nil {
+			return err
+		}
+		if took == 0 {
+			return errors.New("log needs more than no time at all")
+		}
+		now := time.Now()
+		end := now
+		if *at != "" {
+			if end, err = todo.ParseDueDate(*at); err != nil {
+				return err
+			}
+		}
+
+		store, err := openStore()
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+
+		item, err := store.Get(id)
+		if err != nil {
+			return err
+		}
+		item.LogWork(took, end)
+		if err := store.Update(item); err != nil {
+			return err
+		}
+		fmt.Printf("Logged %s on %d %s, %s in all\n", formatLength(took), item.ID, item.Todo, formatLength(item.Spent(now)))
+		return nil
+	}
+}
+
+// timesheetCommand implements `todo-app timesheet [-since 7d]`, the time
+// spent on each project each day. Archived items count as well.
+func timesheetCommand() (*flag.FlagSet, func(args []string) error) {
+	fs := newFlagSet("timesheet", "[-since 7d] [-output json]")
+	since := age(7 * 24 * time.Hour)
+	fs.Var(&since, "since", "How far back to go, e.g. 7d, 2w or 30d.")
+	output := fs.String("output", "", "Print the timesheet as json instead, with the time in seconds.")
+	return fs, func(args []string) error {
+		fs.Parse(args)
+		if fs.NArg() > 0 {
+			fs.Usage()
+			return errors.New("timesheet takes no arguments")
+		}
+		if *output != "" && *output != "json" {
+			return fmt.Errorf("-output only takes json, not %q", *output)
+		}
+
+		store, err := openList()
+		if err != nil {
+			return err
+		}
+		items, err := store.List()
+		store.Close()
+		if err != nil {
+			return err
+		}
+		archived, err := loadArchive("", "")
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		days := todo.Timesheet(append(items, archived...), now.Add(-time.Duration(since)), now, now)
+		if *output == "json" {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(timesheetJSON(days))
+		}
+		return printTimesheet(os.Stdout, days)
+	}
+}
+
+// printTimesheet writes the time spent on each project, under each day,
+// then the total for each project.
+func printTimesheet(w io.Writer, days []todo.TimesheetDay) error {
+	if len(days) == 0 {
+		fmt.Fprintln(w, "No time spent on anything yet. Start the clock with: todo-app start <id>")
+		return nil
+	}
+	name := func(project string) string {
+		if project == "" {
+			return "(no project)"
+		}
+		return "+" + project
+	}
+	totals := map[string]time.Duration{}
+	var order []string
+	var all time.Duration
+	for _, day := range days {
+		fmt.Fprintf(w, "%s\n", day.Date.Format(dateFormat+" Monday"))
+		for _, p := range day.Projects {
+			fmt.Fprintf(w, "  %-24s %10s\n", name(p.Project), formatLength(p.Spent))
+			if _, ok := totals[p.Project]; !ok {
+				order = append(order, p.Project)
+			}
+			totals[p.Project] += p.Spent
+		}
+		if len(day.Projects) > 1 {
+			fmt.Fprintf(w, "  %-24s %10s\n", "total", formatLength(day.Total))
+		}
+		all += day.Total
+	}
+
+	slices.SortStableFunc(order, func(a, b string) int { return cmp.Compare(totals[b], totals[a]) })
+	fmt.Fprintf(w, "\nIn all\n")
+	for _, project := range order {
+		fmt.Fprintf(w, "  %-24s %10s\n", name(project), formatLength(totals[project]))
+	}
+	fmt.Fprintf(w, "  %-24s %10s\n", "total", formatLength(all))
+	return nil
+}
+
+// timesheetJSON is the timesheet as -output json prints it.
+func timesheetJSON(days []todo.TimesheetDay) any {
+	type project struct {
+		Project      string `json:"project"`
+		SpentSeconds int64  `json:"spent_seconds"`
+	}
+	type day struct {
+		Date         string    `json:"date"`
+		Projects     []project `json:"projects"`
+		TotalSeconds int64     `json:"total_seconds"`
+	}
+	out := make([]day, len(days))
+	for i, d := range days {
+		out[i] = day{Date: d.Date.Format("2006-01-02"), TotalSeconds: int64(d.Total / time.Second)}
+		for _, p := range d.Projects {
+			out[i].Projects = append(out[i].Projects, project{Project: p.Project, SpentSeconds: int64(p.Spent / time.Second)})
+		}
+	}
+	return out
+}
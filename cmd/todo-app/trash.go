@@ -0,0 +1,118 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// rmCommand implements `todo-app rm <id>...` and `todo-app rm -where
+// <filter>`. Items are moved to the trash and can be brought back with
+// `restore`.
+func rmCommand() (*flag.FlagSet, func(args []string) error) {
+	fs := newFlagSet("rm", "<id or text>... | -where <filter> [-dry-run]")
+	where, dryRun := bulkFlags(fs, "move to the trash")
+	return fs, func(args []string) error {
+		positional := parseInterspersed(fs, args)
+
+		if len(positional) == 0 && *where == "" {
+			fs.Usage()
+			return errors.New("rm needs at least one item ID or text")
+		}
+
+		store, err := openStore()
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+
+		items, err := selectItems(store, positional, *where)
+		if err != nil {
+			return err
+		}
+		if *dryRun || len(items) == 0 {
+			return printDryRun("move to the trash", items)
+		}
+		for _, item := range items {
+			if err := store.Delete(item.ID); err != nil {
+				return err
+			}
+			fmt.Printf("Moved to trash: %d %s\n", item.ID, item.Todo)
+		}
+		return nil
+	}
+}
+
+// restoreCommand implements `todo-app restore <id>...`, and `todo-app
+// restore <backup>` for a file written by backup.
+func restoreCommand() (*flag.FlagSet, func(args []string) error) {
+	fs := newFlagSet("restore", "<id>... | <backup> [-yes]")
+	yes := fs.Bool("yes", false, "With a backup, replace the store with it without asking first.")
+	return fs, func(args []string) error {
+		fs.Parse(args)
+
+		if fs.NArg() == 0 {
+			fs.Usage()
+			return errors.New("restore needs at least one item ID")
+		}
+		if _, err := strconv.Atoi(fs.Arg(0)); err != nil && fs.NArg() == 1 {
+			return restoreBackup(fs.Arg(0), *yes)
+		}
+
+		ids, err := parseIDs(fs.Args())
+		if err != nil {
+			return err
+		}
+
+		store, err := openStore()
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+
+		for _, id := range ids {
+			if err := store.Restore(id); err != nil {
+				return err
+			}
+			fmt.Printf("Restored: %d\n", id)
+		}
+		return nil
+	}
+}
+
+// trashCommand implements `todo-app trash [--purge]`.
+func trashCommand() (*flag.FlagSet, func(args []string) error) {
+	fs := newFlagSet("trash", "[flags]")
+	purge := fs.Bool("purge", false, "Permanently delete everything in the trash.")
+	return fs, func(args []string) error {
+		fs.Parse(args)
+
+		store, err := openStore()
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+
+		if *purge {
+			n, err := store.Purge()
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Permanently deleted %d item(s).\n", n)
+			return nil
+		}
+
+		trash, err := store.Trash()
+		if err != nil {
+			return err
+		}
+		if len(trash) == 0 {
+			fmt.Println("The trash is empty.")
+			return nil
+		}
+		return PrintList(os.Stdout, trash, time.Now())
+	}
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"flag"
+	"github.com/buck06191/todo-app/pkg/tui"
+)
+
+// tuiCommand implements `todo-app tui`, the full screen interactive
+// interface.
+func tuiCommand() (*flag.FlagSet, func(args []string) error) {
+	fs := newFlagSet("tui", "[flags]")
+	where := fs.String("where", "", "Start with only the items matching a filter expression, as for list.")
+	refresh := fs.Duration("refresh", tui.DefaultRefresh, "How often to reload the list to pick up changes made elsewhere.")
+	return fs, func(args []string) error {
+		fs.Parse(args)
+
+		store, err := openStore()
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+
+		return tui.Run(store, tui.Options{NoColor: *noColor, Refresh: *refresh, Filter: *where})
+	}
+}
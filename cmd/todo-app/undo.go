@@ -0,0 +1,53 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/buck06191/todo-app/pkg/todo"
+)
+
+// undoCommand implements `todo-app undo`, reversing the last command that
+// changed the list.
+func undoCommand() (*flag.FlagSet, func(args []string) error) {
+	return replayCommand("undo", "Undid", todo.Undo)
+}
+
+// redoCommand implements `todo-app redo`, making the last undone command's
+// changes again.
+func redoCommand() (*flag.FlagSet, func(args []string) error) {
+	return replayCommand("redo", "Redid", todo.Redo)
+}
+
+func replayCommand(name, did string, apply func(todo.Store) (todo.Operation, error)) (*flag.FlagSet, func(args []string) error) {
+	fs := newFlagSet(name, "")
+	return fs, func(args []string) error {
+		fs.Parse(args)
+		if fs.NArg() > 0 {
+			fs.Usage()
+			return fmt.Errorf("%s takes no arguments", name)
+		}
+
+		// The list is opened without a journal, so undoing isn't itself
+		// journaled as a change to undo.
+		store, err := openList()
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+
+		op, err := apply(store)
+		switch {
+		case errors.Is(err, todo.ErrNothingToUndo), errors.Is(err, todo.ErrNothingToRedo):
+			fmt.Printf("Nothing to %s.\n", name)
+			return nil
+		case errors.Is(err, todo.ErrChangedSince):
+			return fmt.Errorf("can't %s %q: %w", name, op.Command, err)
+		case err != nil:
+			return err
+		}
+		fmt.Printf("%s: %s (%d item(s), %s)\n", did, op.Command, len(op.Changes), op.At.In(todo.Location).Format("2006-01-02 15:04"))
+		return nil
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/buck06191/todo-app/pkg/server"
+)
+
+// userCommand implements `todo-app user`, managing the users serve gives a
+// list of their own.
+func userCommand() (*flag.FlagSet, func(args []string) error) {
+	fs := newFlagSet("user", "[list | add <name> | rm <name>]")
+	return fs, func(args []string) error {
+		positional := parseInterspersed(fs, args)
+
+		sub := "list"
+		if len(positional) > 0 {
+			sub, positional = positional[0], positional[1:]
+		}
+		if (sub == "list" && len(positional) != 0) || ((sub == "add" || sub == "rm") && len(positional) != 1) {
+			fs.Usage()
+			return fmt.Errorf("wrong number of arguments for user %s", sub)
+		}
+
+		store, err := openRootStore()
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+
+		switch sub {
+		case "list":
+			users, err := server.Users(store)
+			if err != nil {
+				return err
+			}
+			if len(users) == 0 {
+				fmt.Println("No users yet.")
+				return nil
+			}
+			for _, u := range users {
+				fmt.Printf("%-16s created %s\n", u.Name, u.CreatedAt.Format("2006-01-02 15:04"))
+			}
+			return nil
+		case "add":
+			if err := server.AddUser(store, positional[0]); err != nil {
+				return err
+			}
+			fmt.Printf("Added user %s. Create a token for them with: todo-app token create -name <name> -user %s\n", positional[0], positional[0])
+			return nil
+		case "rm":
+			if err := server.DeleteUser(store, positional[0]); err != nil {
+				return err
+			}
+			fmt.Printf("Removed user %s along with their list and tokens\n", positional[0])
+			return nil
+		}
+		fs.Usage()
+		return fmt.Errorf("unknown user command %q", sub)
+	}
+}
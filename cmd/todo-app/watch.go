@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// watchPoll is how often list -watch looks for changes to the file the
+// list is kept in. It looks at when the file was changed, as the prompt
+// cache does, rather than asking to be told of changes: there is nothing
+// in the standard library for that, and JSON stores are saved by renaming
+// a new file over the old one, which is lost track of by watching the file
+// itself. Looking once a second is cheap enough. The list is read again
+// each watchRefresh as well, for stores that aren't a file and for due
+// dates shown as "due in 2 hours" to move on.
+const (
+	watchPoll    = time.Second
+	watchRefresh = 30 * time.Second
+)
+
+// frame collects what is shown on the terminal out in one go, so the screen
+// is only redrawn when it changes. It counts as the terminal for colors and
+// the width lines are cut to.
+type frame struct {
+	bytes.Buffer
+	out *os.File
+}
+
+func (f *frame) Fd() uintptr {
+	return f.out.Fd()
+}
+
+// watchList shows what show prints on a clear screen, printing it again
+// when the file the list given by the global flags is kept in changes,
+// every watchRefresh, and when the terminal is resized, until interrupted.
+// Errors reading the list are shown in its place, as the store may be in
+// the middle of being written.
+func watchList(show func(w io.Writer) error) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	tick := time.NewTicker(watchPoll)
+	defer tick.Stop()
+
+	var shown []byte
+	var modified, read time.Time
+	width := -1
+	for {
+		now := time.Now()
+		if m, w := storeModified(), terminalWidth(os.Stdout); !m.Equal(modified) || w != width || now.Sub(read) >= watchRefresh {
+			modified, width, read = m, w, now
+			f := &frame{out: os.Stdout}
+			if err := show(f); err != nil {
+				f.Reset()
+				fmt.Fprintf(f, "todo-app: %v\n", err)
+			}
+			if !bytes.Equal(f.Bytes(), shown) {
+				shown = f.Bytes()
+				fmt.Print("\033[H\033[2J")
+				os.Stdout.Write(shown)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-tick.C:
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/buck06191/todo-app/pkg/todo"
+	"github.com/buck06191/todo-app/pkg/webhook"
+)
+
+// webhooks returns the webhooks given in the config file, each as
+// webhooks.<name>.url with its secret and events beside it, sorted by
+// name. Their environment variables can change them, but not add more.
+func webhooks() ([]webhook.Hook, error) {
+	var hooks []webhook.Hook
+	for _, key := range slices.Sorted(slices.Values(userConfig.Keys())) {
+		name, ok := strings.CutPrefix(key, "webhooks.")
+		if name, ok = strings.CutSuffix(name, ".url"); !ok || name == "" || strings.Contains(name, ".") {
+			continue
+		}
+		hook := webhook.Hook{Name: name}
+		var err error
+		if hook.URL, _, err = settingValue(key); err != nil {
+			return nil, err
+		}
+		if hook.Secret, _, err = settingValue("webhooks." + name + ".secret"); err != nil {
+			return nil, err
+		}
+		events, _, err := settingValue("webhooks." + name + ".events")
+		if err != nil {
+			return nil, err
+		}
+		if hook.Events, err = webhook.ParseEvents(events); err != nil {
+			return nil, err
+		}
+		hooks = append(hooks, hook)
+	}
+	return hooks, nil
+}
+
+// sendChanges sends the hooks the add and done events of changes.
+func sendChanges(hooks []webhook.Hook, changes []todo.Change) {
+	now := time.Now()
+	for _, c := range changes {
+		switch {
+		case !c.After.DeletedAt.IsZero():
+		case c.Before == nil:
+			sendEvent(hooks, webhook.NewPayload(webhook.EventAdd, c.After, now))
+		case !c.Before.Completed && c.After.Completed:
+			sendEvent(hooks, webhook.NewPayload(webhook.EventDone, c.After, now))
+		}
+	}
+}
+
+// sendEvent sends p to the hooks that want it. Those that can't be sent to
+// are reported without failing the command, whose changes have been made.
+func sendEvent(hooks []webhook.Hook, p webhook.Payload) {
+	for _, hook := range hooks {
+		if !hook.Wants(p.Event) {
+			continue
+		}
+		if err := hook.Send(p); err != nil {
+			fmt.Fprintf(os.Stderr, "todo-app: webhook %s: %v\n", hook.Name, err)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/buck06191/todo-app/pkg/todo"
+)
+
+// workloadCommand implements `todo-app workload`, adding up the estimates of
+// the items due each day this week or month, less the time already spent
+// on them, and warning of the days that hold more than -capacity. Overdue
+// items count on today, as they still need doing.
+func workloadCommand() (*flag.FlagSet, func(args []string) error) {
+	fs := newFlagSet("workload", "[-week | -month] [flags]")
+	week := fs.Bool("week", false, "Show the week, Monday to Sunday. This is the default.")
+	month := fs.Bool("month", false, "Show the whole month instead of the week.")
+	fromFlag := fs.String("from", "", "Show the week or month containing this date instead of the current one, e.g. 2024-06-01 or \"next monday\".")
+	capacity := age(8 * time.Hour)
+	fs.Var(&capacity, "capacity", "How much estimated work fits in a day, e.g. 6h.")
+	whereExpr := fs.String("where", "", "Only count items matching a filter expression, as for list.")
+	return fs, func(args []string) error {
+		fs.Parse(args)
+
+		if fs.NArg() > 0 {
+			fs.Usage()
+			return fmt.Errorf("unexpected argument %q", fs.Arg(0))
+		}
+		if *week && *month {
+			return errors.New("-week and -month can't be used together")
+		}
+		if err := flagDefaults(fs, map[string]string{"capacity": "workload.capacity"}); err != nil {
+			return err
+		}
+		if capacity <= 0 {
+			return errors.New("-capacity has to be more than 0")
+		}
+
+		now := time.Now()
+		from := now
+		if *fromFlag != "" {
+			var err error
+			if from, err = todo.ParseDueDate(*fromFlag); err != nil {
+				return err
+			}
+		}
+		where, err := parseWhere(*whereExpr)
+		if err != nil {
+			return err
+		}
+
+		store, err := openList()
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+		saved, err := store.List()
+		if err != nil {
+			return err
+		}
+		var items []todo.ParsedTodoItem
+		for _, item := range saved {
+			if where == nil || where.Match(item, now) {
+				items = append(items, item)
+			}
+		}
+
+		start, days := todo.WeekStart(from), 7
+		if *month {
+			start = todo.MonthStart(from)
+			days = start.AddDate(0, 1, -1).Day()
+		}
+		overdue, agenda := todo.Agenda(items, start, days, now)
+
+		y, m, d := now.In(todo.Location).Date()
+		today := time.Date(y, m, d, 0, 0, 0, 0, todo.Location)
+		limit := time.Duration(capacity)
+		over, unestimated := 0, 0
+		for _, day := range agenda {
+			dayItems := day.Items
+			if day.Date.Equal(today) {
+				dayItems = append(append([]todo.ParsedTodoItem(nil), overdue...), dayItems...)
+			}
+			var total time.Duration
+			for _, item := range dayItems {
+				if item.Estimate == "" {
+					unestimated++
+				}
+				total += item.Remaining(now)
+			}
+
+			line := fmt.Sprintf("%s  %8s  %s", day.Date.Format("Mon "+dateFormat), formatLength(total), bar(int(min(total, 2*limit)/time.Minute), int(limit/time.Minute), maxBar))
+			if total == 0 {
+				line = fmt.Sprintf("%s  %8s", day.Date.Format("Mon "+dateFormat), "-")
+			}
+			if total > limit {
+				over++
+				line += fmt.Sprintf("  over by %s", formatLength(total-limit))
+				if useColor(os.Stdout) {
+					line = colorRed + line + colorReset
+				}
+			}
+			fmt.Println(line)
+		}
+
+		if over > 0 {
+			days := "1 day holds"
+			if over > 1 {
+				days = fmt.Sprintf("%d days hold", over)
+			}
+			fmt.Printf("\n%s more than the %s that fits in a day. Move some of it with: todo-app snooze <id> <how long>\n", days, formatLength(limit))
+		}
+		switch {
+		case unestimated == 1:
+			fmt.Println("\n1 item due hasn't been estimated. Estimate it with: todo-app edit <id> -estimate 2h")
+		case unestimated > 1:
+			fmt.Printf("\n%d items due haven't been estimated. Estimate them with: todo-app edit <id> -estimate 2h\n", unestimated)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,190 @@
+package caldav
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ErrChanged is returned when saving or deleting a resource that has been
+// changed on the server since its ETag was read.
+var ErrChanged = errors.New("changed on the server since it was last read")
+
+// timeout is how long a request to the server can take.
+const timeout = 30 * time.Second
+
+// Client talks to one calendar collection on a CalDAV server.
+type Client struct {
+	url            *url.URL
+	user, password string
+	http           *http.Client
+}
+
+// NewClient returns a Client for the calendar collection at collection,
+// such as https://cloud.example.com/remote.php/dav/calendars/me/tasks/ on
+// Nextcloud, logging in as user with password if user isn't empty.
+func NewClient(collection, user, password string) (*Client, error) {
+	u, err := url.Parse(collection)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("%s isn't an http or https URL", collection)
+	}
+	if !strings.HasSuffix(u.Path, "/") {
+		u.Path += "/"
+	}
+	return &Client{url: u, user: user, password: password, http: &http.Client{Timeout: timeout}}, nil
+}
+
+// Resource is a calendar object in the collection.
+type Resource struct {
+	// Href is the path of the resource on the server.
+	Href string
+	ETag string
+	Data []byte
+}
+
+const query = `<?xml version="1.0" encoding="utf-8"?>
+<c:calendar-query xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">
+  <d:prop><d:getetag/><c:calendar-data/></d:prop>
+  <c:filter><c:comp-filter name="VCALENDAR"><c:comp-filter name="VTODO"/></c:comp-filter></c:filter>
+</c:calendar-query>`
+
+type multistatus struct {
+	Responses []struct {
+		Href      string `xml:"DAV: href"`
+		Propstats []struct {
+			Status string `xml:"DAV: status"`
+			Prop   struct {
+				ETag string `xml:"DAV: getetag"`
+				Data string `xml:"urn:ietf:params:xml:ns:caldav calendar-data"`
+			} `xml:"DAV: prop"`
+		} `xml:"DAV: propstat"`
+	} `xml:"DAV: response"`
+}
+
+// List returns every resource in the collection holding a VTODO.
+func (c *Client) List() ([]Resource, error) {
+	resp, err := c.do("REPORT", c.url.Path, strings.NewReader(query), map[string]string{
+		"Depth":        "1",
+		"Content-Type": "application/xml; charset=utf-8",
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusMultiStatus {
+		return nil, c.failed(resp)
+	}
+
+	var ms multistatus
+	if err := xml.NewDecoder(resp.Body).Decode(&ms); err != nil {
+		return nil, fmt.Errorf("reading the tasks from %s: %w", c.url.Redacted(), err)
+	}
+	var resources []Resource
+	for _, r := range ms.Responses {
+		for _, ps := range r.Propstats {
+			if !strings.Contains(ps.Status, " 200 ") || ps.Prop.Data == "" {
+				continue
+			}
+			href, err := c.path(r.Href)
+			if err != nil {
+				return nil, err
+			}
+			resources = append(resources, Resource{Href: href, ETag: ps.Prop.ETag, Data: []byte(ps.Prop.Data)})
+		}
+	}
+	return resources, nil
+}
+
+// Put saves data at href, returning its new ETag, which is empty if the
+// server didn't say. etag is the ETag it was read with, or empty for a
+// resource that must not exist yet. A resource that has been changed or
+// made since fails with ErrChanged.
+func (c *Client) Put(href string, data []byte, etag string) (string, error) {
+	header := map[string]string{"Content-Type": "text/calendar; charset=utf-8"}
+	if etag != "" {
+		header["If-Match"] = etag
+	} else {
+		header["If-None-Match"] = "*"
+	}
+	resp, err := c.do(http.MethodPut, href, bytes.NewReader(data), header)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusNoContent:
+		return resp.Header.Get("ETag"), nil
+	case http.StatusPreconditionFailed:
+		return "", fmt.Errorf("%s: %w", href, ErrChanged)
+	}
+	return "", c.failed(resp)
+}
+
+// Delete deletes the resource at href if it still has etag. One that has
+// been changed since fails with ErrChanged, and one that has already gone
+// is no error.
+func (c *Client) Delete(href, etag string) error {
+	header := map[string]string{}
+	if etag != "" {
+		header["If-Match"] = etag
+	}
+	resp, err := c.do(http.MethodDelete, href, nil, header)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent, http.StatusNotFound:
+		return nil
+	case http.StatusPreconditionFailed:
+		return fmt.Errorf("%s: %w", href, ErrChanged)
+	}
+	return c.failed(resp)
+}
+
+// Href returns the path a new resource named name is saved at.
+func (c *Client) Href(name string) string {
+	return c.url.Path + name
+}
+
+func (c *Client) do(method, href string, body io.Reader, header map[string]string) (*http.Response, error) {
+	u := *c.url
+	u.Path, u.RawPath = href, ""
+	req, err := http.NewRequest(method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	if c.user != "" {
+		req.SetBasicAuth(c.user, c.password)
+	}
+	return c.http.Do(req)
+}
+
+// path returns the path of href, which servers may give as a full URL.
+func (c *Client) path(href string) (string, error) {
+	u, err := c.url.Parse(href)
+	if err != nil {
+		return "", err
+	}
+	return u.Path, nil
+}
+
+// failed returns the error for an unexpected response.
+func (c *Client) failed(resp *http.Response) error {
+	if resp.StatusCode == http.StatusUnauthorized {
+		return fmt.Errorf("%s: %s, check the user and password", c.url.Redacted(), resp.Status)
+	}
+	return fmt.Errorf("%s %s: %s", resp.Request.Method, resp.Request.URL.Redacted(), resp.Status)
+}
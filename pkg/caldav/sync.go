@@ -0,0 +1,313 @@
+// Package caldav keeps a todo list in step with a task list on a CalDAV
+// server, such as Nextcloud Tasks or Fastmail, as used by
+// `todo-app caldav`. Each item is saved on the server as a calendar object
+// holding one VTODO.
+//
+// The list remembers the ETag of each task along with how the item was
+// when they last synced, to tell which side has changed since. Changes are
+// saved with If-Match, so a task changed on the server in the meantime is
+// never overwritten without being merged first.
+package caldav
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/buck06191/todo-app/pkg/ical"
+	"github.com/buck06191/todo-app/pkg/todo"
+	"github.com/buck06191/todo-app/pkg/todosync"
+)
+
+// stateKey is the meta key the list keeps its State under.
+const stateKey = "caldav"
+
+// State is what a list remembers about syncing with a CalDAV server.
+type State struct {
+	// URL is the calendar collection, and User and Password what to log
+	// in to it with.
+	URL      string    `json:"url"`
+	User     string    `json:"user,omitempty"`
+	Password string    `json:"password,omitempty"`
+	Synced   time.Time `json:"synced,omitzero"`
+	Tasks    []Synced  `json:"tasks,omitempty"`
+}
+
+// Synced is an item saved as a task on the server.
+type Synced struct {
+	ID   int    `json:"id"`
+	UID  string `json:"uid"`
+	Href string `json:"href"`
+	// ETag is the task's ETag when it was last synced, or empty if it
+	// hasn't been saved on the server yet.
+	ETag string `json:"etag,omitempty"`
+	// Base is the item as it was when it was last synced.
+	Base todo.ParsedTodoItem `json:"base"`
+}
+
+// LoadState returns the CalDAV state of the list in store, which is empty
+// if it has never been synced.
+func LoadState(store todo.Store) (State, error) {
+	var state State
+	err := todo.LoadMeta(store, stateKey, &state)
+	return state, err
+}
+
+// SaveState saves the CalDAV state of the list in store.
+func SaveState(store todo.Store, state State) error {
+	return todo.SaveMeta(store, stateKey, state)
+}
+
+// Result says what a Sync did.
+type Result struct {
+	// Pulled is how many tasks were taken from the server, Pushed how many
+	// were saved on it and Deleted how many were deleted on one side
+	// because they had been on the other.
+	Pulled, Pushed, Deleted int
+	// Conflicts is how many fields were changed differently on both sides,
+	// keeping the value on the list.
+	Conflicts int
+}
+
+// Sync brings the list in store and the task list c is for into step,
+// saving state as it goes.
+//
+// A task changed on only one side since the last sync is taken from that
+// side. One changed on both is merged as todosync.Merge does, keeping the
+// list's value for fields changed on both. One deleted on one side is
+// moved to the trash, or deleted from the server, unless it has been
+// changed on the other, in which case the change is kept. The first sync
+// keeps the items of both.
+func Sync(store todo.Store, c *Client, state *State) (Result, error) {
+	var res Result
+	resources, err := c.List()
+	if err != nil {
+		return res, err
+	}
+	onServer := make(map[string]Resource, len(resources))
+	for _, r := range resources {
+		onServer[r.Href] = r
+	}
+	current, err := todo.AllItems(store)
+	if err != nil {
+		return res, err
+	}
+	synced := map[int]bool{}
+	hrefs := map[string]bool{}
+	for _, t := range state.Tasks {
+		synced[t.ID] = true
+		hrefs[t.Href] = true
+	}
+
+	// Tasks new to the list are added first, so that every item can then
+	// be saved with its references given as IDs on the list.
+	pulled := map[int]ical.Task{}
+	for _, r := range resources {
+		if hrefs[r.Href] {
+			continue
+		}
+		task, err := ical.Decode(r.Data)
+		if err != nil || task.UID == "" {
+			// Not a task that can be an item.
+			continue
+		}
+		id, err := add(store, task.Item)
+		if err != nil {
+			return res, err
+		}
+		state.Tasks = append(state.Tasks, Synced{ID: id, UID: task.UID, Href: r.Href, ETag: r.ETag})
+		pulled[id] = task
+		synced[id] = true
+	}
+	// Items new to the server get a UID to be saved under.
+	for _, id := range slices.Sorted(maps.Keys(current)) {
+		if synced[id] || !current[id].DeletedAt.IsZero() {
+			continue
+		}
+		uid, err := newUID()
+		if err != nil {
+			return res, err
+		}
+		state.Tasks = append(state.Tasks, Synced{ID: id, UID: uid, Href: c.Href(uid + ".ics")})
+	}
+	ids := map[string]int{}
+	for _, t := range state.Tasks {
+		ids[t.UID] = t.ID
+	}
+
+	push := map[int]bool{}
+	tasks := state.Tasks[:0]
+	for _, t := range state.Tasks {
+		if _, ok := pulled[t.ID]; ok {
+			tasks = append(tasks, t)
+			continue
+		}
+		r, there := onServer[t.Href]
+		local, here := current[t.ID]
+		alive := here && local.DeletedAt.IsZero()
+		changedHere := !alive || todosync.Hash(local) != todosync.Hash(t.Base)
+		changedThere := !there || r.ETag != t.ETag
+
+		switch {
+		case !there && !alive:
+			// Gone from both.
+			continue
+		case !changedHere && !changedThere:
+		case !there && changedHere:
+			// New here, or deleted on the server but changed here since,
+			// which is saved again.
+			t.ETag = ""
+			push[t.ID] = true
+		case !there:
+			if err := store.Delete(t.ID); err != nil {
+				return res, err
+			}
+			res.Deleted++
+			continue
+		case !alive && !changedThere:
+			err := c.Delete(t.Href, t.ETag)
+			if errors.Is(err, ErrChanged) {
+				// Its change is taken by the next sync.
+				break
+			}
+			if err != nil {
+				return res, err
+			}
+			res.Deleted++
+			continue
+		case !changedHere || !alive:
+			// Changed on the server, and perhaps deleted here, in which
+			// case the change brings it back.
+			task, err := ical.Decode(r.Data)
+			if err != nil {
+				break
+			}
+			if !here {
+				if t.ID, err = add(store, task.Item); err != nil {
+					return res, err
+				}
+				ids[t.UID] = t.ID
+			}
+			t.ETag = r.ETag
+			pulled[t.ID] = task
+		case !changedThere:
+			push[t.ID] = true
+		default:
+			// Changed on both sides: the merge is saved over the server's.
+			task, err := ical.Decode(r.Data)
+			if err != nil {
+				break
+			}
+			theirs := withRefs(task, ids)
+			theirs.ID = t.ID
+			merged, fields := todosync.Merge(t.Base, local, theirs)
+			res.Conflicts += len(fields)
+			if todosync.Hash(merged) != todosync.Hash(local) {
+				if err := todosync.Put(store, merged, false); err != nil {
+					return res, err
+				}
+			}
+			t.ETag = r.ETag
+			push[t.ID] = true
+		}
+		tasks = append(tasks, t)
+	}
+	state.Tasks = tasks
+
+	if current, err = todo.AllItems(store); err != nil {
+		return res, err
+	}
+	for id, task := range pulled {
+		want := withRefs(task, ids)
+		want.ID = id
+		have := current[id]
+		if want.CreatedAt.IsZero() {
+			want.CreatedAt = have.CreatedAt
+		}
+		if todosync.Hash(want) != todosync.Hash(have) {
+			if err := todosync.Put(store, want, !have.DeletedAt.IsZero()); err != nil {
+				return res, err
+			}
+		}
+		res.Pulled++
+	}
+
+	// The items as the store saved them are the base, since it may not
+	// keep times as precisely as they were read.
+	current, err = todo.AllItems(store)
+	if err != nil {
+		return res, err
+	}
+	uids := map[int]string{}
+	for _, t := range state.Tasks {
+		uids[t.ID] = t.UID
+	}
+	for i := range state.Tasks {
+		t := &state.Tasks[i]
+		if _, ok := pulled[t.ID]; ok {
+			t.Base = current[t.ID]
+		}
+		if !push[t.ID] {
+			continue
+		}
+		item := current[t.ID]
+		task := ical.Task{UID: t.UID, Item: item, Parent: uids[item.Parent]}
+		for _, id := range item.BlockedBy {
+			if uid := uids[id]; uid != "" {
+				task.BlockedBy = append(task.BlockedBy, uid)
+			}
+		}
+		etag, err := c.Put(t.Href, ical.Encode(task), t.ETag)
+		if errors.Is(err, ErrChanged) {
+			// Changed on the server since it was listed: merged by the
+			// next sync.
+			continue
+		}
+		if err != nil {
+			SaveState(store, *state)
+			return res, err
+		}
+		// A server that doesn't give the new ETag is taken to have
+		// changed the task, which the next sync then reads back.
+		t.ETag, t.Base = etag, item
+		res.Pushed++
+		if err := SaveState(store, *state); err != nil {
+			return res, err
+		}
+	}
+	state.Synced = time.Now()
+	return res, SaveState(store, *state)
+}
+
+// withRefs returns the item of task with its parent and blockers given by
+// their IDs in ids, dropping the ones that aren't in it.
+func withRefs(task ical.Task, ids map[string]int) todo.ParsedTodoItem {
+	item := task.Item
+	item.Parent = ids[task.Parent]
+	item.BlockedBy = nil
+	for _, uid := range task.BlockedBy {
+		if id := ids[uid]; id != 0 {
+			item.BlockedBy = append(item.BlockedBy, id)
+		}
+	}
+	return item
+}
+
+// newUID returns a UID for a new task.
+func newUID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// add adds item to store without its references, which can't be given yet,
+// returning its ID.
+func add(store todo.Store, item todo.ParsedTodoItem) (int, error) {
+	item.Parent, item.BlockedBy = 0, nil
+	return todosync.Add(store, item)
+}
@@ -0,0 +1,105 @@
+// Package calendar draws agendas from todo.Agenda as a text grid with a
+// column for each day of the week, as used by `todo-app agenda` and the
+// TUI.
+package calendar
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/buck06191/todo-app/pkg/todo"
+)
+
+// Options change how Grid draws a calendar.
+type Options struct {
+	// Width is the width of the whole grid. Each day gets a seventh of it.
+	Width int
+	// MaxItems is how many items are listed on each day before the rest
+	// are summarised as "+n more". Zero means no limit.
+	MaxItems int
+	// Month, if set, is the month being shown. Days from the weeks either
+	// side of it are marked as being outside it.
+	Month time.Month
+	// Today is marked in the grid, if it is shown.
+	Today time.Time
+	// Style, if set, is applied to each item's text after it has been
+	// laid out, e.g. to color it. It mustn't change its width.
+	Style func(item todo.ParsedTodoItem, text string) string
+}
+
+// Grid draws days as rows of seven, one row per week. days should start
+// on a Monday, as from todo.WeekStart or todo.MonthGrid.
+func Grid(days []todo.AgendaDay, opts Options) string {
+	cell := max((opts.Width-6)/7, 8)
+	y, m, d := opts.Today.In(todo.Location).Date()
+
+	var b strings.Builder
+	for week := 0; week < len(days); week += 7 {
+		row := days[week:min(week+7, len(days))]
+		if week > 0 {
+			b.WriteString(strings.Repeat("─", cell*7+6) + "\n")
+		}
+
+		var heads []string
+		lines := 0
+		for _, day := range row {
+			head := day.Date.Format("Mon 2")
+			if dy, dm, dd := day.Date.Date(); dy == y && dm == m && dd == d {
+				head += " today"
+			}
+			if opts.Month != 0 && day.Date.Month() != opts.Month {
+				head = "(" + head + ")"
+			}
+			heads = append(heads, pad(head, cell))
+
+			n := len(day.Items)
+			if opts.MaxItems > 0 && n > opts.MaxItems {
+				n = opts.MaxItems + 1
+			}
+			lines = max(lines, n)
+		}
+		b.WriteString(strings.TrimRight(strings.Join(heads, "│"), " ") + "\n")
+
+		for i := 0; i < lines; i++ {
+			var cells []string
+			for _, day := range row {
+				cells = append(cells, itemCell(day, i, cell, opts))
+			}
+			b.WriteString(strings.TrimRight(strings.Join(cells, "│"), " ") + "\n")
+		}
+	}
+	return b.String()
+}
+
+// itemCell returns line i of day's cell: an item, the "+n more" line, or
+// blank.
+func itemCell(day todo.AgendaDay, i, width int, opts Options) string {
+	if opts.MaxItems > 0 && i == opts.MaxItems && len(day.Items) > opts.MaxItems {
+		return pad(fmt.Sprintf("+%d more", len(day.Items)-opts.MaxItems), width)
+	}
+	if i >= len(day.Items) {
+		return pad("", width)
+	}
+
+	item := day.Items[i]
+	text := fmt.Sprintf("%d %s", item.ID, item.Todo)
+	if !item.DueAllDay() {
+		text = item.Due.In(todo.Location).Format("15:04") + " " + text
+	}
+	text = pad(text, width)
+	if opts.Style != nil {
+		text = opts.Style(item, text)
+	}
+	return text
+}
+
+// pad cuts or pads s to exactly width runes.
+func pad(s string, width int) string {
+	n := utf8.RuneCountInString(s)
+	if n > width {
+		return string([]rune(s)[:width-1]) + "…"
+	}
+	return s + strings.Repeat(" ", width-n)
+}
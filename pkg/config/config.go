@@ -0,0 +1,482 @@
+// Package config reads and writes todo-app's config.toml. It knows the
+// part of TOML a file of settings needs: tables, dotted keys, and string,
+// integer and boolean values.
+//
+// A File is changed in place, line by line, so the comments and layout of
+// a file written by hand are kept when a setting is changed with Set.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// File is a config file, as read by Load or Parse.
+type File struct {
+	lines   []string
+	entries []entry
+	// tables are the lines of the table headers, by table name.
+	tables map[string]int
+}
+
+type entry struct {
+	// key is the whole dotted key, including the table it is in, and
+	// table is that table.
+	key, table string
+	line       int
+	value      any
+}
+
+// Load reads the config file at path. A missing file is treated as an
+// empty one.
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return Parse(nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+	f, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%w", path, err)
+	}
+	return f, nil
+}
+
+// Parse reads a config file's contents. Errors start with the number of
+// the line they are on.
+func Parse(data []byte) (*File, error) {
+	f := &File{tables: map[string]int{}}
+	if len(data) > 0 {
+		f.lines = strings.Split(strings.TrimSuffix(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n"), "\n")
+	}
+	seen := map[string]bool{}
+	table := ""
+	for i, line := range f.lines {
+		rest := strings.TrimSpace(line)
+		if rest == "" || rest[0] == '#' {
+			continue
+		}
+		if rest[0] == '[' {
+			if strings.HasPrefix(rest, "[[") {
+				return nil, fmt.Errorf("%d: arrays of tables aren't supported", i+1)
+			}
+			parts, rest, err := parseKey(rest[1:])
+			if err == nil && !strings.HasPrefix(rest, "]") {
+				err = errors.New("expected ] after the table name")
+			}
+			if err == nil {
+				err = endOfLine(rest[1:])
+			}
+			if err != nil {
+				return nil, fmt.Errorf("%d: %w", i+1, err)
+			}
+			table = strings.Join(parts, ".")
+			if _, ok := f.tables[table]; ok {
+				return nil, fmt.Errorf("%d: table [%s] is defined twice", i+1, table)
+			}
+			f.tables[table] = i
+			continue
+		}
+
+		parts, rest, err := parseKey(rest)
+		if err == nil && !strings.HasPrefix(rest, "=") {
+			err = errors.New("expected = after the key")
+		}
+		var value any
+		if err == nil {
+			value, rest, err = parseValue(strings.TrimSpace(rest[1:]))
+		}
+		if err == nil {
+			err = endOfLine(rest)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%d: %w", i+1, err)
+		}
+		key := strings.Join(parts, ".")
+		if table != "" {
+			key = table + "." + key
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("%d: %s is set twice", i+1, key)
+		}
+		seen[key] = true
+		f.entries = append(f.entries, entry{key: key, table: table, line: i, value: value})
+	}
+	return f, nil
+}
+
+// Keys returns the keys set in the file, sorted.
+func (f *File) Keys() []string {
+	var keys []string
+	for _, e := range f.entries {
+		keys = append(keys, e.key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// Get returns the value of the dotted key, which is a string, an int64 or
+// a bool, and whether it is set.
+func (f *File) Get(key string) (any, bool) {
+	for _, e := range f.entries {
+		if e.key == key {
+			return e.value, true
+		}
+	}
+	return nil, false
+}
+
+// String returns the value of key if it is set to a string.
+func (f *File) String(key string) (string, bool, error) {
+	v, ok := f.Get(key)
+	if !ok {
+		return "", false, nil
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", false, fmt.Errorf("%s should be a string, not %s", key, Format(v))
+	}
+	return s, true, nil
+}
+
+// Set sets key to value, which must be a string, an int64 or a bool. A key
+// already set is changed where it is; a new one goes at the end of its
+// table, which is added to the end of the file if it isn't there yet.
+func (f *File) Set(key string, value any) error {
+	switch value.(type) {
+	case string, int64, bool:
+	default:
+		return fmt.Errorf("can't set %s to a %T", key, value)
+	}
+	parts, rest, err := parseKey(key)
+	if err == nil && rest != "" {
+		err = fmt.Errorf("unexpected %q", rest)
+	}
+	if err != nil {
+		return fmt.Errorf("bad key %q: %w", key, err)
+	}
+	key = strings.Join(parts, ".")
+
+	lines := slices.Clone(f.lines)
+	for _, e := range f.entries {
+		if e.key == key {
+			line := lines[e.line]
+			indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+			lines[e.line] = indent + formatKey(strings.Split(strings.TrimPrefix(key, e.table+"."), ".")) + " = " + Format(value)
+			return f.reparse(lines)
+		}
+	}
+
+	// The key goes in the deepest table it names that is already there,
+	// as a dotted key, or in a new table named after all but its last part.
+	table, local := "", parts
+	for i := len(parts) - 1; i > 0; i-- {
+		if _, ok := f.tables[strings.Join(parts[:i], ".")]; ok {
+			table, local = strings.Join(parts[:i], "."), parts[i:]
+			break
+		}
+	}
+	if table == "" && len(parts) > 1 && !f.rootHasDottedKeys() {
+		table, local = strings.Join(parts[:len(parts)-1], "."), parts[len(parts)-1:]
+	}
+	line := formatKey(local) + " = " + Format(value)
+
+	at, ok := f.endOfTable(table)
+	if !ok {
+		if len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) != "" {
+			lines = append(lines, "")
+		}
+		lines = append(lines, "["+table+"]", line)
+		return f.reparse(lines)
+	}
+	return f.reparse(slices.Insert(lines, at, line))
+}
+
+// Unset removes key from the file, reporting whether it was set.
+func (f *File) Unset(key string) (bool, error) {
+	for _, e := range f.entries {
+		if e.key == key {
+			return true, f.reparse(slices.Delete(slices.Clone(f.lines), e.line, e.line+1))
+		}
+	}
+	return false, nil
+}
+
+// Bytes returns the file's contents.
+func (f *File) Bytes() []byte {
+	if len(f.lines) == 0 {
+		return nil
+	}
+	return []byte(strings.Join(f.lines, "\n") + "\n")
+}
+
+// Save writes the file to path, making its directory if need be.
+func (f *File) Save(path string) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, ".config-*.toml")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(f.Bytes()); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Format returns value as it is written in TOML.
+func Format(value any) string {
+	switch v := value.(type) {
+	case string:
+		return quote(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	return fmt.Sprint(value)
+}
+
+func (f *File) reparse(lines []string) error {
+	parsed, err := Parse([]byte(strings.Join(lines, "\n")))
+	if err != nil {
+		return err
+	}
+	*f = *parsed
+	return nil
+}
+
+// endOfTable returns the line a new key of table goes on: after its last
+// key, or after its header if it has none. The first key of the root table
+// goes before the first table and any blank lines and comments above it,
+// or at the end of a file without tables.
+func (f *File) endOfTable(table string) (int, bool) {
+	at, ok := f.tables[table]
+	if table == "" {
+		at, ok = -1, true
+	}
+	if !ok {
+		return 0, false
+	}
+	at++
+	for _, e := range f.entries {
+		if e.table == table && e.line >= at {
+			at = e.line + 1
+		}
+	}
+	if table == "" && at == 0 {
+		if len(f.tables) == 0 {
+			return len(f.lines), true
+		}
+		first := len(f.lines)
+		for _, line := range f.tables {
+			first = min(first, line)
+		}
+		for at = first; at > 0; at-- {
+			if s := strings.TrimSpace(f.lines[at-1]); s != "" && s[0] != '#' {
+				break
+			}
+		}
+	}
+	return at, true
+}
+
+// rootHasDottedKeys reports whether the file sets dotted keys outside of
+// tables, as in remotes.work = "...", in which case new ones are too.
+func (f *File) rootHasDottedKeys() bool {
+	for _, e := range f.entries {
+		if e.table == "" && strings.Contains(e.key, ".") {
+			return true
+		}
+	}
+	return false
+}
+
+// parseKey parses a dotted key at the start of s, returning its parts and
+// what follows it with leading spaces trimmed.
+func parseKey(s string) ([]string, string, error) {
+	var parts []string
+	for {
+		s = strings.TrimLeft(s, " \t")
+		var part string
+		switch {
+		case strings.HasPrefix(s, `"`), strings.HasPrefix(s, "'"):
+			v, rest, err := parseString(s)
+			if err != nil {
+				return nil, "", err
+			}
+			part, s = v, rest
+		default:
+			n := 0
+			for n < len(s) && isBare(s[n]) {
+				n++
+			}
+			if n == 0 {
+				return nil, "", errors.New("expected a key")
+			}
+			part, s = s[:n], s[n:]
+		}
+		parts = append(parts, part)
+		s = strings.TrimLeft(s, " \t")
+		if !strings.HasPrefix(s, ".") {
+			return parts, s, nil
+		}
+		s = s[1:]
+	}
+}
+
+// parseValue parses the value at the start of s, returning what follows it.
+func parseValue(s string) (any, string, error) {
+	if strings.HasPrefix(s, `"`) || strings.HasPrefix(s, "'") {
+		return parseString(s)
+	}
+	n := strings.IndexAny(s, " \t#")
+	if n < 0 {
+		n = len(s)
+	}
+	word, rest := s[:n], s[n:]
+	switch word {
+	case "true":
+		return true, rest, nil
+	case "false":
+		return false, rest, nil
+	case "":
+		return nil, "", errors.New("expected a value")
+	}
+	if i, err := strconv.ParseInt(word, 0, 64); err == nil {
+		return i, rest, nil
+	}
+	return nil, "", fmt.Errorf("unsupported value %s: only strings, integers and booleans are", word)
+}
+
+// parseString parses a basic "..." or literal '...' string at the start of
+// s.
+func parseString(s string) (string, string, error) {
+	if strings.HasPrefix(s, `"""`) || strings.HasPrefix(s, "'''") {
+		return "", "", errors.New("multi-line strings aren't supported")
+	}
+	if s[0] == '\'' {
+		end := strings.IndexByte(s[1:], '\'')
+		if end < 0 {
+			return "", "", errors.New("unterminated string")
+		}
+		return s[1 : end+1], s[end+2:], nil
+	}
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"':
+			return b.String(), s[i+1:], nil
+		case '\\':
+			if i+1 == len(s) {
+				return "", "", errors.New("unterminated string")
+			}
+			i++
+			switch e := s[i]; e {
+			case '"', '\\':
+				b.WriteByte(e)
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'r':
+				b.WriteByte('\r')
+			case 'b':
+				b.WriteByte('\b')
+			case 'f':
+				b.WriteByte('\f')
+			case 'u', 'U':
+				size := 4
+				if e == 'U' {
+					size = 8
+				}
+				if i+size >= len(s) {
+					return "", "", errors.New("unterminated string")
+				}
+				r, err := strconv.ParseUint(s[i+1:i+1+size], 16, 32)
+				if err != nil || !utf8.ValidRune(rune(r)) {
+					return "", "", fmt.Errorf("bad escape \\%c%s", e, s[i+1:i+1+size])
+				}
+				b.WriteRune(rune(r))
+				i += size
+			default:
+				return "", "", fmt.Errorf("bad escape \\%c", e)
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", "", errors.New("unterminated string")
+}
+
+// endOfLine checks that nothing but a comment follows a key and value.
+func endOfLine(s string) error {
+	if s = strings.TrimSpace(s); s != "" && s[0] != '#' {
+		return fmt.Errorf("unexpected %q", s)
+	}
+	return nil
+}
+
+func isBare(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '_' || c == '-'
+}
+
+func formatKey(parts []string) string {
+	formatted := make([]string, len(parts))
+	for i, part := range parts {
+		formatted[i] = part
+		for j := 0; j < len(part); j++ {
+			if !isBare(part[j]) {
+				formatted[i] = quote(part)
+				break
+			}
+		}
+		if part == "" {
+			formatted[i] = `""`
+		}
+	}
+	return strings.Join(formatted, ".")
+}
+
+// quote writes s as a TOML basic string.
+func quote(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"', '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04X`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
@@ -0,0 +1,192 @@
+// Package digest sums up a list for a morning email: what is overdue, what
+// is due today and what is coming up over the next few days, written both
+// as plain text and as HTML, and sent over SMTP with Send.
+package digest
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"strings"
+	"time"
+
+	"github.com/buck06191/todo-app/pkg/todo"
+)
+
+// Digest is what a digest tells of, as of Now.
+type Digest struct {
+	Now     time.Time
+	Overdue []todo.ParsedTodoItem
+	// Days are today and each of the days after it, with the items due on
+	// them.
+	Days []todo.AgendaDay
+}
+
+// New sums up items as of now, with the days up to days after today.
+func New(items []todo.ParsedTodoItem, now time.Time, days int) Digest {
+	overdue, agenda := todo.Agenda(items, now, days+1, now)
+	return Digest{Now: now, Overdue: overdue, Days: agenda}
+}
+
+// Empty reports whether nothing is overdue or due on any of the days.
+func (d Digest) Empty() bool {
+	if len(d.Overdue) > 0 {
+		return false
+	}
+	for _, day := range d.Days {
+		if len(day.Items) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// count is the number of items due on the days.
+func (d Digest) count() int {
+	n := 0
+	for _, day := range d.Days {
+		n += len(day.Items)
+	}
+	return n
+}
+
+// Subject is the subject of the digest's email, such as "2 overdue, 3 due
+// today: Wednesday 14 October".
+func (d Digest) Subject() string {
+	var parts []string
+	if len(d.Overdue) > 0 {
+		parts = append(parts, fmt.Sprintf("%d overdue", len(d.Overdue)))
+	}
+	if len(d.Days) > 0 && len(d.Days[0].Items) > 0 {
+		parts = append(parts, fmt.Sprintf("%d due today", len(d.Days[0].Items)))
+	}
+	if len(parts) == 0 {
+		parts = append(parts, fmt.Sprintf("%d coming up", d.count()))
+	}
+	return strings.Join(parts, ", ") + ": " + d.Now.In(todo.Location).Format("Monday 2 January")
+}
+
+// section is a heading of the digest and the items under it, with when
+// each is due as it is shown.
+type section struct {
+	Title string
+	Items []line
+}
+
+type line struct {
+	ID       int
+	Todo     string
+	When     string
+	Priority string
+	Overdue  bool
+}
+
+// sections are the parts of the digest: overdue, today, then one for each
+// of the days after it with anything due.
+func (d Digest) sections() []section {
+	var sections []section
+	if len(d.Overdue) > 0 {
+		s := section{Title: "Overdue"}
+		for _, item := range d.Overdue {
+			s.Items = append(s.Items, lineOf(item, "due "+item.Due.In(todo.Location).Format("Mon 2 Jan"), true))
+		}
+		sections = append(sections, s)
+	}
+	for i, day := range d.Days {
+		title := day.Date.Format("Monday 2 January")
+		switch i {
+		case 0:
+			title = "Today"
+		case 1:
+			title = "Tomorrow"
+		}
+		if len(day.Items) == 0 {
+			continue
+		}
+		s := section{Title: title}
+		for _, item := range day.Items {
+			when := ""
+			if !item.DueAllDay() {
+				when = item.Due.In(todo.Location).Format("15:04")
+			}
+			s.Items = append(s.Items, lineOf(item, when, false))
+		}
+		sections = append(sections, s)
+	}
+	return sections
+}
+
+func lineOf(item todo.ParsedTodoItem, when string, overdue bool) line {
+	l := line{ID: item.ID, Todo: item.Todo, When: when, Overdue: overdue}
+	if item.Priority != todo.PriorityNone {
+		l.Priority = item.Priority.String()
+	}
+	return l
+}
+
+// Text is the digest as plain text.
+func (d Digest) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n", d.Now.In(todo.Location).Format("Monday 2 January 2006"))
+	if d.Empty() {
+		fmt.Fprintf(&b, "\nNothing is overdue or due in the next %d days.\n", len(d.Days)-1)
+		return b.String()
+	}
+	for _, s := range d.sections() {
+		fmt.Fprintf(&b, "\n%s\n", s.Title)
+		for _, l := range s.Items {
+			fmt.Fprintf(&b, "  %d  %s", l.ID, l.Todo)
+			var about []string
+			if l.When != "" {
+				about = append(about, l.When)
+			}
+			if l.Priority != "" {
+				about = append(about, l.Priority+" priority")
+			}
+			if len(about) > 0 {
+				fmt.Fprintf(&b, " (%s)", strings.Join(about, ", "))
+			}
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+var page = template.Must(template.New("digest").Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family: -apple-system, 'Segoe UI', Helvetica, Arial, sans-serif; color: #222; max-width: 40em">
+<h2 style="font-weight: normal">{{.Date}}</h2>
+{{- range .Sections}}
+<h3 style="margin-bottom: 0.3em{{if eq .Title "Overdue"}}; color: #c0392b{{end}}">{{.Title}}</h3>
+<table style="border-collapse: collapse">
+{{- range .Items}}
+<tr>
+<td style="padding: 2px 12px 2px 0; color: #888; text-align: right">{{.ID}}</td>
+<td style="padding: 2px 12px 2px 0">{{.Todo}}{{if .Priority}} <span style="color: #888">({{.Priority}} priority)</span>{{end}}</td>
+<td style="padding: 2px 0; color: {{if .Overdue}}#c0392b{{else}}#888{{end}}">{{.When}}</td>
+</tr>
+{{- end}}
+</table>
+{{- else}}
+<p>Nothing is overdue or due in the next {{.Days}} days.</p>
+{{- end}}
+</body>
+</html>
+`))
+
+// HTML is the digest as an HTML page, styled inline as email clients
+// need.
+func (d Digest) HTML() string {
+	var b bytes.Buffer
+	err := page.Execute(&b, struct {
+		Date     string
+		Sections []section
+		Days     int
+	}{d.Now.In(todo.Location).Format("Monday 2 January 2006"), d.sections(), len(d.Days) - 1})
+	if err != nil {
+		// The template and what is given to it are fixed, so this can't
+		// happen.
+		panic(err)
+	}
+	return b.String()
+}
@@ -0,0 +1,141 @@
+package digest
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// SMTP is the mail server digests are sent through.
+type SMTP struct {
+	// Server is the host and port, such as smtp.example.com:587. Port 465
+	// is spoken to over TLS from the start, and others are switched to TLS
+	// with STARTTLS when the server offers it.
+	Server   string
+	Username string
+	Password string
+}
+
+// Email is the digest as an email from from to each of to, with a plain
+// text part and an HTML one.
+func (d Digest) Email(from string, to []string) []byte {
+	boundary := randomBoundary()
+	var b bytes.Buffer
+	header := func(name, value string) { fmt.Fprintf(&b, "%s: %s\r\n", name, value) }
+	header("From", from)
+	header("To", strings.Join(to, ", "))
+	header("Subject", mime.QEncoding.Encode("utf-8", d.Subject()))
+	header("Date", d.Now.Format(time.RFC1123Z))
+	header("MIME-Version", "1.0")
+	header("Content-Type", `multipart/alternative; boundary="`+boundary+`"`)
+	b.WriteString("\r\n")
+
+	for _, part := range []struct{ kind, body string }{{"text/plain", d.Text()}, {"text/html", d.HTML()}} {
+		fmt.Fprintf(&b, "--%s\r\n", boundary)
+		header("Content-Type", part.kind+"; charset=utf-8")
+		header("Content-Transfer-Encoding", "quoted-printable")
+		b.WriteString("\r\n")
+		qp := quotedprintable.NewWriter(&b)
+		qp.Write([]byte(strings.ReplaceAll(part.body, "\n", "\r\n")))
+		qp.Close()
+		b.WriteString("\r\n")
+	}
+	fmt.Fprintf(&b, "--%s--\r\n", boundary)
+	return b.Bytes()
+}
+
+func randomBoundary() string {
+	var buf [16]byte
+	rand.Read(buf[:])
+	return "todo-app-" + hex.EncodeToString(buf[:])
+}
+
+// Send sends msg from from to each of to through the server. Only the
+// addresses in from and to are used, so they can be given with names, as
+// in "Todo <todo@example.com>".
+func (s SMTP) Send(from string, to []string, msg []byte) error {
+	sender, err := mail.ParseAddress(from)
+	if err != nil {
+		return fmt.Errorf("the address to send from, %q: %w", from, err)
+	}
+	if len(to) == 0 {
+		return errors.New("there's nobody to send to")
+	}
+	var rcpts []string
+	for _, addr := range to {
+		rcpt, err := mail.ParseAddress(addr)
+		if err != nil {
+			return fmt.Errorf("the address to send to, %q: %w", addr, err)
+		}
+		rcpts = append(rcpts, rcpt.Address)
+	}
+
+	host, port, err := net.SplitHostPort(s.Server)
+	if err != nil {
+		return fmt.Errorf("the SMTP server %q needs a port, such as %s:587", s.Server, s.Server)
+	}
+	var c *smtp.Client
+	if port == "465" {
+		conn, err := tls.DialWithDialer(&net.Dialer{Timeout: 30 * time.Second}, "tcp", s.Server, &tls.Config{ServerName: host})
+		if err != nil {
+			return err
+		}
+		if c, err = smtp.NewClient(conn, host); err != nil {
+			conn.Close()
+			return err
+		}
+	} else {
+		conn, err := net.DialTimeout("tcp", s.Server, 30*time.Second)
+		if err != nil {
+			return err
+		}
+		if c, err = smtp.NewClient(conn, host); err != nil {
+			conn.Close()
+			return err
+		}
+		if ok, _ := c.Extension("STARTTLS"); ok {
+			if err := c.StartTLS(&tls.Config{ServerName: host}); err != nil {
+				c.Close()
+				return err
+			}
+		}
+	}
+	defer c.Close()
+
+	if s.Username != "" {
+		// PlainAuth refuses to send the password without TLS, except to
+		// localhost.
+		if err := c.Auth(smtp.PlainAuth("", s.Username, s.Password, host)); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(sender.Address); err != nil {
+		return err
+	}
+	for _, rcpt := range rcpts {
+		if err := c.Rcpt(rcpt); err != nil {
+			return fmt.Errorf("%s: %w", rcpt, err)
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
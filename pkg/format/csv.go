@@ -0,0 +1,211 @@
+package format
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/buck06191/todo-app/pkg/todo"
+)
+
+// Column is a column of a csv file with the field it holds, for files
+// laid out by a spreadsheet rather than by todo-app.
+type Column struct {
+	Field  string
+	Header string
+}
+
+// ParseMap parses a column mapping such as "todo=Title,due=Deadline",
+// giving the header of the column for each field. "task" is taken as
+// todo.
+func ParseMap(spec string) ([]Column, error) {
+	var columns []Column
+	for _, pair := range strings.Split(spec, ",") {
+		name, header, ok := strings.Cut(pair, "=")
+		name, header = strings.ToLower(strings.TrimSpace(name)), strings.TrimSpace(header)
+		if !ok || name == "" || header == "" {
+			return nil, fmt.Errorf("bad column mapping %q, expected field=Column", pair)
+		}
+		if name == "task" {
+			name = "todo"
+		}
+		if _, ok := findField(name); !ok {
+			return nil, fmt.Errorf("unknown field %q in column mapping", name)
+		}
+		columns = append(columns, Column{Field: name, Header: header})
+	}
+	return columns, nil
+}
+
+// CSV returns a renderer writing csv with columns, in that order, rather
+// than every field under its own name.
+func CSV(columns []Column) Renderer {
+	return delimitedRenderer{comma: ',', columns: columns}
+}
+
+// allColumns are the columns csv is written with unless others are given.
+func allColumns() []Column {
+	columns := make([]Column, len(fields))
+	for i, f := range fields {
+		columns[i] = Column{Field: f.name, Header: f.name}
+	}
+	return columns
+}
+
+// ReadCSV reads items from csv with a header row. Columns are matched to
+// fields by columns if it isn't nil, and otherwise by being named after
+// them as in the csv format writes; other columns are left out. Lists are
+// split on spaces and commas, and booleans can also be yes, no or x. The
+// id, parent and blocked_by columns are kept as they are in the file, for
+// the caller to number the items and their references anew.
+func ReadCSV(r io.Reader, columns []Column) ([]todo.ParsedTodoItem, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if columns == nil {
+		columns = allColumns()
+	}
+	// index is the position of each field's column in the file.
+	index := map[string]int{}
+	for _, c := range columns {
+		for i, h := range header {
+			if strings.EqualFold(strings.TrimSpace(h), c.Header) {
+				index[c.Field] = i
+				break
+			}
+		}
+	}
+	if _, ok := index["todo"]; !ok {
+		return nil, errors.New("no column for the todo field, name it todo or map it with todo=Column")
+	}
+
+	var items []todo.ParsedTodoItem
+	for {
+		row, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return items, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := cr.FieldPos(0)
+		var item todo.ParsedTodoItem
+		for name, i := range index {
+			if i >= len(row) || strings.TrimSpace(row[i]) == "" {
+				continue
+			}
+			if err := set(&item, name, strings.TrimSpace(row[i])); err != nil {
+				return nil, fmt.Errorf("line %d: %s: %w", line, name, err)
+			}
+		}
+		if item.Todo == "" {
+			continue
+		}
+		items = append(items, item)
+	}
+}
+
+// set sets the field called name of item from its text in a csv file.
+// deleted_at isn't read, as items are imported into the list rather than
+// the trash.
+func set(item *todo.ParsedTodoItem, name, s string) error {
+	var err error
+	switch name {
+	case "id":
+		item.ID, err = strconv.Atoi(s)
+	case "todo":
+		item.Todo = s
+	case "due":
+		item.Due, err = todo.ParseDueDate(s)
+	case "priority":
+		item.Priority, err = todo.ParsePriority(s)
+	case "tags":
+		item.Tags = todo.AddTags(nil, list(s)...)
+	case "project":
+		item.Project = s
+	case "contexts":
+		item.Contexts = todo.AddContexts(nil, list(s)...)
+	case "repeat":
+		item.Repeat = s
+	case "remind":
+		item.Remind = s
+	case "estimate":
+		item.Estimate, err = todo.ParseEstimate(s)
+	case "parent":
+		item.Parent, err = strconv.Atoi(s)
+	case "blocked_by":
+		for _, word := range list(s) {
+			id, err := strconv.Atoi(word)
+			if err != nil {
+				return err
+			}
+			item.BlockedBy = append(item.BlockedBy, id)
+		}
+	case "notes":
+		item.Notes = s
+	case "status":
+		var status string
+		if status, err = todo.ParseStatus(s); status == todo.StatusDone {
+			item.Completed = true
+		} else if status == todo.StatusDoing {
+			item.Status = status
+		}
+	case "inbox":
+		item.Inbox, err = parseBool(s)
+	case "snoozed":
+		item.Snoozed, err = strconv.Atoi(s)
+	case "created_at":
+		item.CreatedAt, err = parseTime(s)
+	case "completed":
+		item.Completed, err = parseBool(s)
+	case "completed_at":
+		item.CompletedAt, err = parseTime(s)
+	}
+	return err
+}
+
+// list splits a list of tags, contexts or IDs.
+func list(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' })
+}
+
+// parseTime parses a time as RFC 3339, or as a date or date and time as
+// spreadsheets write them.
+func parseTime(s string) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, todo.Location); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("bad time %q, expected YYYY-MM-DD or RFC 3339", s)
+}
+
+func parseBool(s string) (bool, error) {
+	switch strings.ToLower(s) {
+	case "yes", "y", "x":
+		return true, nil
+	case "no", "n":
+		return false, nil
+	}
+	return strconv.ParseBool(s)
+}
+
+// findField returns the field called name.
+func findField(name string) (field, bool) {
+	for _, f := range fields {
+		if f.name == name {
+			return f, true
+		}
+	}
+	return field{}, false
+}
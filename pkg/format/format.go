@@ -0,0 +1,168 @@
+// Package format writes todo items in machine readable formats, for
+// feeding the output of todo-app into other tools:
+//
+//	json     an array of items, the same as the JSON store
+//	csv      a header row then a row per item
+//	tsv      the same with tabs, and tabs and newlines in values escaped
+//	yaml     a sequence of mappings
+//	ics      an iCalendar object with a VTODO for each item
+//	todotxt  a line per item in the todo.txt format
+//	org      an Emacs Org file with a headline per item
+//	md       Markdown checklists under a heading per project
+//
+// For csv, tsv and yaml the fields are named as in JSON. Lists such as
+// tags are joined with spaces in csv and tsv. Times are RFC 3339 in
+// todo.Location.
+//
+// Other packages can add formats of their own with Register, such as an
+// HTML page or a JSON shape another tool expects, and they are then offered
+// by Lookup and Names along with these.
+package format
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/buck06191/todo-app/pkg/ical"
+	"github.com/buck06191/todo-app/pkg/todo"
+)
+
+// ErrUnknownFormat is returned by Lookup for a format it doesn't know.
+var ErrUnknownFormat = errors.New("unknown output format")
+
+// Renderer writes items in one output format.
+type Renderer interface {
+	// List writes items, in the order given.
+	List(w io.Writer, items []todo.ParsedTodoItem) error
+	// Item writes a single item, e.g. as an object rather than an array of
+	// one in JSON.
+	Item(w io.Writer, item todo.ParsedTodoItem) error
+}
+
+var (
+	renderersMu sync.RWMutex
+	renderers   = map[string]Renderer{
+		"json":    jsonRenderer{},
+		"csv":     delimitedRenderer{comma: ','},
+		"tsv":     delimitedRenderer{comma: '\t'},
+		"yaml":    yamlRenderer{},
+		"ics":     ICS(ical.Options{}),
+		"todotxt": todotxtRenderer{},
+		"org":     orgRenderer{},
+		"md":      markdownRenderer{},
+	}
+)
+
+// Register makes r available to Lookup as the format called name, which is
+// matched without regard to case. It is meant to be called from the init
+// function of the package implementing the format, in the same way as
+// todo.Register for storage backends, and panics if name is taken, by one
+// of the built-in formats or another registered one.
+func Register(name string, r Renderer) {
+	renderersMu.Lock()
+	defer renderersMu.Unlock()
+
+	if r == nil {
+		panic("format: Register renderer is nil")
+	}
+	name = strings.ToLower(name)
+	if name == "" {
+		panic("format: Register called without a name")
+	}
+	if _, dup := renderers[name]; dup {
+		panic("format: Register called twice for format " + name)
+	}
+	renderers[name] = r
+}
+
+// Lookup returns the renderer for the format called name.
+func Lookup(name string) (Renderer, error) {
+	renderersMu.RLock()
+	r, ok := renderers[strings.ToLower(name)]
+	renderersMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%w %q, expected one of %s", ErrUnknownFormat, name, strings.Join(Names(), ", "))
+	}
+	return r, nil
+}
+
+// Names returns the names of the available formats, sorted.
+func Names() []string {
+	renderersMu.RLock()
+	defer renderersMu.RUnlock()
+
+	names := make([]string, 0, len(renderers))
+	for name := range renderers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// field is one of the columns of the csv, tsv and yaml formats. value
+// returns a string, int, bool, []string, []int or time.Time, with the zero
+// value meaning the field isn't set.
+type field struct {
+	name  string
+	value func(item todo.ParsedTodoItem) any
+}
+
+// fields follow the order and names of ParsedTodoItem's JSON.
+var fields = []field{
+	{"id", func(item todo.ParsedTodoItem) any { return item.ID }},
+	{"todo", func(item todo.ParsedTodoItem) any { return item.Todo }},
+	{"due", func(item todo.ParsedTodoItem) any { return item.Due }},
+	{"priority", func(item todo.ParsedTodoItem) any { return item.Priority.String() }},
+	{"tags", func(item todo.ParsedTodoItem) any { return item.Tags }},
+	{"project", func(item todo.ParsedTodoItem) any { return item.Project }},
+	{"contexts", func(item todo.ParsedTodoItem) any { return item.Contexts }},
+	{"repeat", func(item todo.ParsedTodoItem) any { return item.Repeat }},
+	{"remind", func(item todo.ParsedTodoItem) any { return item.Remind }},
+	{"estimate", func(item todo.ParsedTodoItem) any { return item.Estimate }},
+	{"parent", func(item todo.ParsedTodoItem) any { return item.Parent }},
+	{"blocked_by", func(item todo.ParsedTodoItem) any { return item.BlockedBy }},
+	{"notes", func(item todo.ParsedTodoItem) any { return item.Notes }},
+	{"status", func(item todo.ParsedTodoItem) any { return item.Status }},
+	{"inbox", func(item todo.ParsedTodoItem) any { return item.Inbox }},
+	{"snoozed", func(item todo.ParsedTodoItem) any { return item.Snoozed }},
+	{"created_at", func(item todo.ParsedTodoItem) any { return item.CreatedAt }},
+	{"completed", func(item todo.ParsedTodoItem) any { return item.Completed }},
+	{"completed_at", func(item todo.ParsedTodoItem) any { return item.CompletedAt }},
+	{"deleted_at", func(item todo.ParsedTodoItem) any { return item.DeletedAt }},
+}
+
+// text formats a field value for csv and tsv. Unset values are empty.
+func text(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case int:
+		if v == 0 {
+			return ""
+		}
+		return strconv.Itoa(v)
+	case bool:
+		return strconv.FormatBool(v)
+	case []string:
+		return strings.Join(v, " ")
+	case []int:
+		ids := make([]string, len(v))
+		for i, id := range v {
+			ids[i] = strconv.Itoa(id)
+		}
+		return strings.Join(ids, " ")
+	case time.Time:
+		if v.IsZero() {
+			return ""
+		}
+		return v.In(todo.Location).Format(time.RFC3339)
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,145 @@
+package format
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/buck06191/todo-app/pkg/todo"
+)
+
+// orgRenderer writes items as an Emacs Org file: a headline for each
+// item with its keyword, priority and tags, subtasks under their parents,
+// then its CLOSED and DEADLINE timestamps, its project as the CATEGORY
+// property and its notes.
+type orgRenderer struct{}
+
+func (orgRenderer) List(w io.Writer, items []todo.ParsedTodoItem) error {
+	var b strings.Builder
+	b.WriteString("#+TODO: TODO DOING | DONE\n")
+	listed := map[int]bool{}
+	for _, item := range items {
+		listed[item.ID] = true
+	}
+	children := map[int][]todo.ParsedTodoItem{}
+	var roots []todo.ParsedTodoItem
+	for _, item := range items {
+		if item.Parent != 0 && listed[item.Parent] {
+			children[item.Parent] = append(children[item.Parent], item)
+		} else {
+			roots = append(roots, item)
+		}
+	}
+	var write func(item todo.ParsedTodoItem, level int)
+	write = func(item todo.ParsedTodoItem, level int) {
+		writeOrg(&b, item, level)
+		for _, child := range children[item.ID] {
+			write(child, level+1)
+		}
+	}
+	for _, item := range roots {
+		write(item, 1)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func (r orgRenderer) Item(w io.Writer, item todo.ParsedTodoItem) error {
+	return r.List(w, []todo.ParsedTodoItem{item})
+}
+
+// orgPriorities are the Org priority cookies for each priority.
+var orgPriorities = map[todo.Priority]string{
+	todo.PriorityHigh:   "[#A] ",
+	todo.PriorityMedium: "[#B] ",
+	todo.PriorityLow:    "[#C] ",
+}
+
+func writeOrg(b *strings.Builder, item todo.ParsedTodoItem, level int) {
+	keyword := "TODO"
+	switch {
+	case item.Completed:
+		keyword = "DONE"
+	case item.Status == todo.StatusDoing:
+		keyword = "DOING"
+	}
+	fmt.Fprintf(b, "%s %s %s%s", strings.Repeat("*", level), keyword, orgPriorities[item.Priority], strings.Join(strings.Fields(item.Todo), " "))
+	var tags []string
+	for _, t := range item.Tags {
+		tags = append(tags, orgTag(t))
+	}
+	for _, c := range item.Contexts {
+		tags = append(tags, "@"+orgTag(c))
+	}
+	if len(tags) > 0 {
+		fmt.Fprintf(b, " :%s:", strings.Join(tags, ":"))
+	}
+	b.WriteByte('\n')
+
+	indent := strings.Repeat(" ", level+1)
+	var planning []string
+	if item.Completed && !item.CompletedAt.IsZero() {
+		planning = append(planning, "CLOSED: "+orgTime(item.CompletedAt, false, "[", "]"))
+	}
+	if !item.Due.IsZero() {
+		deadline := orgTime(item.Due, item.DueAllDay(), "<", "")
+		if repeat := orgRepeat(item); repeat != "" {
+			deadline += " " + repeat
+		}
+		planning = append(planning, "DEADLINE: "+deadline+">")
+	}
+	if len(planning) > 0 {
+		fmt.Fprintf(b, "%s%s\n", indent, strings.Join(planning, " "))
+	}
+	if item.Project != "" {
+		fmt.Fprintf(b, "%s:PROPERTIES:\n%s:CATEGORY: %s\n%s:END:\n", indent, indent, item.Project, indent)
+	}
+	if notes := strings.TrimSpace(item.Notes); notes != "" {
+		for _, line := range strings.Split(notes, "\n") {
+			line = strings.TrimRightFunc(line, unicode.IsSpace)
+			// A line starting with a star would be read as a headline.
+			if strings.HasPrefix(line, "*") {
+				line = "," + line
+			}
+			if line != "" {
+				line = indent + line
+			}
+			b.WriteString(line + "\n")
+		}
+	}
+}
+
+// orgTime formats t as an Org timestamp between open and close, without
+// the time of day if day is true.
+func orgTime(t time.Time, day bool, open, close string) string {
+	layout := "2006-01-02 Mon 15:04"
+	if day {
+		layout = "2006-01-02 Mon"
+	}
+	return open + t.In(todo.Location).Format(layout) + close
+}
+
+// orgRepeat returns the Org repeater for item's repeat rule, such as +1w,
+// or "" if it has none or Org can't give it, as for weekly rules on
+// several days.
+func orgRepeat(item todo.ParsedTodoItem) string {
+	r, ok := item.Recurrence()
+	if !ok || !r.Simple(item.Due) {
+		return ""
+	}
+	unit := map[todo.Frequency]string{todo.Daily: "d", todo.Weekly: "w", todo.Monthly: "m", todo.Yearly: "y"}[r.Freq]
+	return fmt.Sprintf("+%d%s", r.Interval, unit)
+}
+
+// orgTag returns tag with the characters Org doesn't allow in tags, such
+// as dashes, as underscores.
+func orgTag(tag string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("_@#%", r) {
+			return r
+		}
+		return '_'
+	}, tag)
+}
@@ -0,0 +1,215 @@
+package format
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/buck06191/todo-app/pkg/ical"
+	"github.com/buck06191/todo-app/pkg/markdown"
+	"github.com/buck06191/todo-app/pkg/todo"
+	"github.com/buck06191/todo-app/pkg/todotxt"
+
+	"gopkg.in/yaml.v3"
+)
+
+type jsonRenderer struct{}
+
+func (jsonRenderer) List(w io.Writer, items []todo.ParsedTodoItem) error {
+	if items == nil {
+		// An empty array rather than null.
+		items = []todo.ParsedTodoItem{}
+	}
+	return encodeJSON(w, items)
+}
+
+func (jsonRenderer) Item(w io.Writer, item todo.ParsedTodoItem) error {
+	return encodeJSON(w, item)
+}
+
+func encodeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// delimitedRenderer writes csv, or tsv when comma is a tab. csv values are
+// quoted as needed; tsv values can't be, so tabs, newlines and backslashes
+// in them are escaped instead. csv has columns if they are given, and
+// every field otherwise.
+type delimitedRenderer struct {
+	comma   rune
+	columns []Column
+}
+
+func (r delimitedRenderer) List(w io.Writer, items []todo.ParsedTodoItem) error {
+	if r.comma == '\t' {
+		return writeTSV(w, items)
+	}
+
+	columns := r.columns
+	if columns == nil {
+		columns = allColumns()
+	}
+	values := make([]func(todo.ParsedTodoItem) any, len(columns))
+	header := make([]string, len(columns))
+	for i, c := range columns {
+		f, _ := findField(c.Field)
+		values[i], header[i] = f.value, c.Header
+	}
+	cw := csv.NewWriter(w)
+	cw.Write(header)
+
+	for _, item := range items {
+		row := make([]string, len(columns))
+		for i, value := range values {
+			row[i] = text(value(item))
+		}
+		cw.Write(row)
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func (r delimitedRenderer) Item(w io.Writer, item todo.ParsedTodoItem) error {
+	return r.List(w, []todo.ParsedTodoItem{item})
+}
+
+var tsvEscaper = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
+
+func writeTSV(w io.Writer, items []todo.ParsedTodoItem) error {
+	var b strings.Builder
+	for i, f := range fields {
+		if i > 0 {
+			b.WriteByte('\t')
+		}
+		b.WriteString(f.name)
+	}
+	b.WriteByte('\n')
+
+	for _, item := range items {
+		for i, f := range fields {
+			if i > 0 {
+				b.WriteByte('\t')
+			}
+			b.WriteString(tsvEscaper.Replace(text(f.value(item))))
+		}
+		b.WriteByte('\n')
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+type yamlRenderer struct{}
+
+func (yamlRenderer) List(w io.Writer, items []todo.ParsedTodoItem) error {
+	seq := &yaml.Node{Kind: yaml.SequenceNode}
+	for _, item := range items {
+		node, err := yamlItem(item)
+		if err != nil {
+			return err
+		}
+		seq.Content = append(seq.Content, node)
+	}
+	if len(items) == 0 {
+		seq.Style = yaml.FlowStyle
+	}
+	return encodeYAML(w, seq)
+}
+
+func (yamlRenderer) Item(w io.Writer, item todo.ParsedTodoItem) error {
+	node, err := yamlItem(item)
+	if err != nil {
+		return err
+	}
+	return encodeYAML(w, node)
+}
+
+// yamlItem builds a mapping for item with the fields in order, leaving out
+// the ones that aren't set as JSON does.
+func yamlItem(item todo.ParsedTodoItem) (*yaml.Node, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for _, f := range fields {
+		value := f.value(item)
+		if text(value) == "" || value == false {
+			continue
+		}
+		if t, ok := value.(time.Time); ok {
+			value = t.In(todo.Location)
+		}
+
+		var v yaml.Node
+		if err := v.Encode(value); err != nil {
+			return nil, err
+		}
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: f.name}, &v)
+	}
+	return node, nil
+}
+
+func encodeYAML(w io.Writer, node *yaml.Node) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(node); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// ICS returns a renderer writing items as an iCalendar object, with opts
+// saying how. Each item's UID is made from its ID, so calendars
+// subscribed to the export recognise the items again when it is updated.
+func ICS(opts ical.Options) Renderer {
+	return icsRenderer{opts: opts}
+}
+
+type icsRenderer struct {
+	opts ical.Options
+}
+
+func (r icsRenderer) List(w io.Writer, items []todo.ParsedTodoItem) error {
+	tasks := make([]ical.Task, len(items))
+	for i, item := range items {
+		tasks[i] = ical.Task{UID: uid(item.ID), Item: item}
+		if item.Parent != 0 {
+			tasks[i].Parent = uid(item.Parent)
+		}
+		for _, id := range item.BlockedBy {
+			tasks[i].BlockedBy = append(tasks[i].BlockedBy, uid(id))
+		}
+	}
+	return ical.Write(w, tasks, r.opts)
+}
+
+func (r icsRenderer) Item(w io.Writer, item todo.ParsedTodoItem) error {
+	return r.List(w, []todo.ParsedTodoItem{item})
+}
+
+func uid(id int) string {
+	return fmt.Sprintf("item-%d@todo-app", id)
+}
+
+// todotxtRenderer writes items as the lines of a todo.txt file.
+type todotxtRenderer struct{}
+
+func (todotxtRenderer) List(w io.Writer, items []todo.ParsedTodoItem) error {
+	return todotxt.Write(w, items)
+}
+
+func (todotxtRenderer) Item(w io.Writer, item todo.ParsedTodoItem) error {
+	return todotxt.Write(w, []todo.ParsedTodoItem{item})
+}
+
+// markdownRenderer writes items as Markdown checklists.
+type markdownRenderer struct{}
+
+func (markdownRenderer) List(w io.Writer, items []todo.ParsedTodoItem) error {
+	return markdown.Write(w, items)
+}
+
+func (markdownRenderer) Item(w io.Writer, item todo.ParsedTodoItem) error {
+	return markdown.Write(w, []todo.ParsedTodoItem{item})
+}
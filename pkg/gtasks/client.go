@@ -0,0 +1,223 @@
+package gtasks
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// apiURL is the address of the Google Tasks API.
+const apiURL = "https://tasks.googleapis.com/tasks/v1"
+
+// timeout is how long a request to Google can take.
+const timeout = 30 * time.Second
+
+// errNotFound is returned for a task list or task Google doesn't have.
+var errNotFound = errors.New("not found on Google Tasks")
+
+// Client talks to the Google Tasks API as one user.
+type Client struct {
+	creds Credentials
+	token Token
+	// saveToken is called with the token whenever it is refreshed.
+	saveToken func(Token) error
+	http      *http.Client
+}
+
+// NewClient returns a Client using token, refreshing it with creds when it
+// expires and passing the new one to saveToken to be kept.
+func NewClient(creds Credentials, token Token, saveToken func(Token) error) *Client {
+	return &Client{creds: creds, token: token, saveToken: saveToken, http: &http.Client{Timeout: timeout}}
+}
+
+// List is a task list.
+type List struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+// Task is a task, with the fields that are kept in step with an item.
+type Task struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	Notes string `json:"notes"`
+	// Status is needsAction or completed.
+	Status string `json:"status"`
+	// Due is the date the task is due, as an RFC 3339 time at midnight
+	// UTC, since Google Tasks keeps no time of day.
+	Due     string `json:"due"`
+	Deleted bool   `json:"deleted"`
+}
+
+// Lists returns every task list.
+func (c *Client) Lists() ([]List, error) {
+	var lists []List
+	err := c.pages("/users/@me/lists", nil, func(raw json.RawMessage) error {
+		var page []List
+		err := json.Unmarshal(raw, &page)
+		lists = append(lists, page...)
+		return err
+	})
+	return lists, err
+}
+
+// DefaultList returns the user's default task list, "My Tasks" unless
+// they have renamed it.
+func (c *Client) DefaultList() (List, error) {
+	var l List
+	err := c.do(http.MethodGet, "/users/@me/lists/@default", nil, &l)
+	return l, err
+}
+
+// AddList makes a task list called title.
+func (c *Client) AddList(title string) (List, error) {
+	var l List
+	err := c.do(http.MethodPost, "/users/@me/lists", map[string]string{"title": title}, &l)
+	return l, err
+}
+
+// Tasks returns every task in the list with id, including the ones that
+// are done or have been deleted.
+func (c *Client) Tasks(list string) ([]Task, error) {
+	query := url.Values{
+		"showCompleted": {"true"},
+		"showHidden":    {"true"},
+		"showDeleted":   {"true"},
+		"maxResults":    {"100"},
+	}
+	var tasks []Task
+	err := c.pages("/lists/"+url.PathEscape(list)+"/tasks", query, func(raw json.RawMessage) error {
+		var page []Task
+		err := json.Unmarshal(raw, &page)
+		tasks = append(tasks, page...)
+		return err
+	})
+	return tasks, err
+}
+
+// AddTask adds a task with fields to list.
+func (c *Client) AddTask(list string, fields map[string]any) (Task, error) {
+	var t Task
+	err := c.do(http.MethodPost, "/lists/"+url.PathEscape(list)+"/tasks", fields, &t)
+	return t, err
+}
+
+// UpdateTask changes fields of the task with id in list.
+func (c *Client) UpdateTask(list, id string, fields map[string]any) (Task, error) {
+	var t Task
+	err := c.do(http.MethodPatch, "/lists/"+url.PathEscape(list)+"/tasks/"+url.PathEscape(id), fields, &t)
+	return t, err
+}
+
+// DeleteTask deletes the task with id from list. One that has already gone
+// is no error.
+func (c *Client) DeleteTask(list, id string) error {
+	err := c.do(http.MethodDelete, "/lists/"+url.PathEscape(list)+"/tasks/"+url.PathEscape(id), nil, nil)
+	if errors.Is(err, errNotFound) {
+		return nil
+	}
+	return err
+}
+
+// pages gets every page of the collection at path, passing the items of
+// each to add.
+func (c *Client) pages(path string, query url.Values, add func(json.RawMessage) error) error {
+	if query == nil {
+		query = url.Values{}
+	}
+	for {
+		var page struct {
+			Items         json.RawMessage `json:"items"`
+			NextPageToken string          `json:"nextPageToken"`
+		}
+		if err := c.do(http.MethodGet, path+"?"+query.Encode(), nil, &page); err != nil {
+			return err
+		}
+		if page.Items != nil {
+			if err := add(page.Items); err != nil {
+				return err
+			}
+		}
+		if page.NextPageToken == "" {
+			return nil
+		}
+		query.Set("pageToken", page.NextPageToken)
+	}
+}
+
+// do makes a request with body sent as JSON, if it isn't nil, and reads a
+// JSON answer into out, if it isn't nil. The token is refreshed first if
+// it has expired, and once more if Google doesn't take it.
+func (c *Client) do(method, path string, body, out any) error {
+	var data []byte
+	if body != nil {
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+	for attempt := 0; ; attempt++ {
+		if !c.token.valid() || attempt > 0 {
+			t, err := refresh(c.creds, c.token)
+			if err != nil {
+				return err
+			}
+			c.token = t
+			if err := c.saveToken(t); err != nil {
+				return err
+			}
+		}
+
+		req, err := http.NewRequest(method, apiURL+path, bytes.NewReader(data))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+c.token.Access)
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		resp, err := c.http.Do(req)
+		if err != nil {
+			return err
+		}
+		err = c.read(resp, method, path, out)
+		if resp.StatusCode == http.StatusUnauthorized && attempt == 0 {
+			continue
+		}
+		return err
+	}
+}
+
+// read reads the answer to a request into out, or returns the error it
+// holds.
+func (c *Client) read(resp *http.Response, method, path string, out any) error {
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return fmt.Errorf("%s %s: %w", method, path, errNotFound)
+	case resp.StatusCode == http.StatusUnauthorized:
+		return ErrLogin
+	case resp.StatusCode/100 != 2:
+		var e struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		if json.Unmarshal(msg, &e) == nil && e.Error.Message != "" {
+			msg = []byte(e.Error.Message)
+		}
+		return fmt.Errorf("Google Tasks: %s %s: %s %s", method, path, resp.Status, bytes.TrimSpace(msg))
+	case out == nil:
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("reading the answer to %s %s from Google Tasks: %w", method, path, err)
+	}
+	return nil
+}
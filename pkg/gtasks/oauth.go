@@ -0,0 +1,181 @@
+package gtasks
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// The endpoints of Google's OAuth 2.0 server, and the scope asked for.
+const (
+	authURL  = "https://accounts.google.com/o/oauth2/v2/auth"
+	tokenURL = "https://oauth2.googleapis.com/token"
+	scope    = "https://www.googleapis.com/auth/tasks"
+)
+
+// ErrLogin is returned when there is no token, or Google no longer takes
+// the one there is, and the user has to log in again.
+var ErrLogin = errors.New("not logged in to Google, or the login has expired")
+
+// Credentials identify the OAuth client the user made for todo-app in the
+// Google Cloud console, of the desktop app kind.
+type Credentials struct {
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+}
+
+// Token is an OAuth token. The access token is used until just before it
+// expires, and then refreshed with the refresh token.
+type Token struct {
+	Access  string    `json:"access"`
+	Refresh string    `json:"refresh"`
+	Expiry  time.Time `json:"expiry"`
+}
+
+// valid reports whether the access token can still be used for a while.
+func (t Token) valid() bool {
+	return t.Access != "" && time.Until(t.Expiry) > time.Minute
+}
+
+// Login asks the user to let todo-app use their tasks, given the
+// credentials of their OAuth client, returning the token Google gives.
+// show is called with the address to open in a browser, where Google asks
+// them; Google then sends the browser back to a server Login runs on the
+// loopback address until it has the answer, or ctx is done.
+func Login(ctx context.Context, creds Credentials, show func(url string)) (Token, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return Token{}, err
+	}
+	defer ln.Close()
+	redirect := "http://" + ln.Addr().String() + "/"
+
+	// The state ties the answer to this login, and the verifier makes sure
+	// only this process can exchange the code (PKCE).
+	state, verifier := random(), random()
+	sum := sha256.Sum256([]byte(verifier))
+	show(authURL + "?" + url.Values{
+		"client_id":             {creds.ClientID},
+		"redirect_uri":          {redirect},
+		"response_type":         {"code"},
+		"scope":                 {scope},
+		"access_type":           {"offline"},
+		"prompt":                {"consent"},
+		"state":                 {state},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(sum[:])},
+		"code_challenge_method": {"S256"},
+	}.Encode())
+
+	type answer struct {
+		code string
+		err  error
+	}
+	answers := make(chan answer, 1)
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		var a answer
+		switch {
+		case q.Get("state") != state:
+			http.Error(w, "This isn't the answer to the login todo-app is waiting for.", http.StatusBadRequest)
+			return
+		case q.Get("error") != "":
+			a.err = fmt.Errorf("Google refused access: %s", q.Get("error"))
+			fmt.Fprintln(w, "todo-app wasn't given access to your tasks. You can close this tab.")
+		default:
+			a.code = q.Get("code")
+			fmt.Fprintln(w, "todo-app can now sync your tasks. You can close this tab.")
+		}
+		select {
+		case answers <- a:
+		default:
+		}
+	})}
+	go srv.Serve(ln)
+	defer srv.Close()
+
+	var a answer
+	select {
+	case a = <-answers:
+	case <-ctx.Done():
+		return Token{}, ctx.Err()
+	}
+	if a.err != nil {
+		return Token{}, a.err
+	}
+	return fetchToken(creds, url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {a.code},
+		"redirect_uri":  {redirect},
+		"code_verifier": {verifier},
+	}, "")
+}
+
+// refresh returns a new access token for t.
+func refresh(creds Credentials, t Token) (Token, error) {
+	if t.Refresh == "" {
+		return Token{}, ErrLogin
+	}
+	return fetchToken(creds, url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {t.Refresh},
+	}, t.Refresh)
+}
+
+// fetchToken asks Google's token endpoint for a token. The refresh token
+// given is kept if the answer doesn't have a new one, as refreshes don't.
+func fetchToken(creds Credentials, form url.Values, refresh string) (Token, error) {
+	form.Set("client_id", creds.ClientID)
+	form.Set("client_secret", creds.ClientSecret)
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Post(tokenURL, "application/x-www-form-urlencoded", strings.NewReader(form.Encode()))
+	if err != nil {
+		return Token{}, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return Token{}, err
+	}
+
+	var answer struct {
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+		ExpiresIn    int    `json:"expires_in"`
+		Error        string `json:"error"`
+		Description  string `json:"error_description"`
+	}
+	if err := json.Unmarshal(body, &answer); err != nil {
+		return Token{}, fmt.Errorf("getting a token from Google: %s", resp.Status)
+	}
+	switch {
+	case answer.Error == "invalid_grant":
+		// The refresh token has been revoked or has expired.
+		return Token{}, ErrLogin
+	case answer.Error != "":
+		return Token{}, fmt.Errorf("getting a token from Google: %s %s", answer.Error, answer.Description)
+	case answer.AccessToken == "":
+		return Token{}, fmt.Errorf("getting a token from Google: %s", resp.Status)
+	}
+	t := Token{Access: answer.AccessToken, Refresh: answer.RefreshToken, Expiry: time.Now().Add(time.Duration(answer.ExpiresIn) * time.Second)}
+	if t.Refresh == "" {
+		t.Refresh = refresh
+	}
+	return t, nil
+}
+
+// random returns a random string for the OAuth state and PKCE verifier.
+func random() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,384 @@
+// Package gtasks keeps a todo list in step with Google Tasks, as used by
+// `todo-app sync google`, logging in with OAuth 2.0.
+//
+// Each task list is a project, and the default list, "My Tasks", holds
+// the items without one. An item and its task are kept in step in their
+// text, notes, due date and whether they are done; Google Tasks keeps no
+// time of day for due dates, so only the date is synced. The list
+// remembers those fields as they were when they last synced, to tell
+// which side has changed since, and merges changes made on both as
+// todosync.Merge does.
+package gtasks
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/buck06191/todo-app/pkg/todo"
+	"github.com/buck06191/todo-app/pkg/todosync"
+)
+
+// stateKey is the meta key the list keeps its State under.
+const stateKey = "gtasks"
+
+// State is what a list remembers about syncing with Google Tasks.
+type State struct {
+	Credentials
+	Token  Token     `json:"token"`
+	Synced time.Time `json:"synced,omitzero"`
+	Tasks  []Synced  `json:"tasks,omitempty"`
+}
+
+// Synced is an item kept in step with a task.
+type Synced struct {
+	ID   int    `json:"id"`
+	List string `json:"list"`
+	Task string `json:"task"`
+	// Base holds the fields kept in step as they were when they last
+	// synced.
+	Base todo.ParsedTodoItem `json:"base"`
+}
+
+// LoadState returns the Google Tasks state of the list in store, which is
+// empty if it has never been synced.
+func LoadState(store todo.Store) (State, error) {
+	var state State
+	err := todo.LoadMeta(store, stateKey, &state)
+	return state, err
+}
+
+// SaveState saves the Google Tasks state of the list in store.
+func SaveState(store todo.Store, state State) error {
+	return todo.SaveMeta(store, stateKey, state)
+}
+
+// Result says what a Sync did.
+type Result struct {
+	// Pulled is how many tasks were taken from Google, Pushed how many were
+	// saved there and Deleted how many were deleted on one side because
+	// they had been on the other.
+	Pulled, Pushed, Deleted int
+	// Conflicts is how many fields were changed differently on both sides,
+	// keeping the value on the list.
+	Conflicts int
+}
+
+// remote is a task with the list it is in.
+type remote struct {
+	list string
+	task Task
+}
+
+// Sync brings the list in store and the user's task lists into step,
+// saving state as it goes, including the token when c refreshes it.
+//
+// A field changed on only one side since the last sync is taken from that
+// side, and one changed on both keeps the list's value. One deleted on one
+// side is moved to the trash, or deleted from Google, unless it has been
+// changed on the other, in which case the change is kept. The first sync
+// keeps the items of both, leaving out the ones already done.
+func Sync(store todo.Store, c *Client, state *State) (Result, error) {
+	var res Result
+	s := syncer{store: store, c: c, lists: map[string]string{}, names: map[string]string{}}
+	def, err := c.DefaultList()
+	if err != nil {
+		return res, err
+	}
+	lists, err := c.Lists()
+	if err != nil {
+		return res, err
+	}
+	s.inbox = def.ID
+	remotes := map[string]remote{}
+	var order []string
+	for _, l := range lists {
+		if l.ID != def.ID {
+			s.lists[strings.ToLower(l.Title)] = l.ID
+			s.names[l.ID] = l.Title
+		}
+		tasks, err := c.Tasks(l.ID)
+		if err != nil {
+			return res, err
+		}
+		for _, t := range tasks {
+			remotes[t.ID] = remote{list: l.ID, task: t}
+			order = append(order, t.ID)
+		}
+	}
+	current, err := todo.AllItems(store)
+	if err != nil {
+		return res, err
+	}
+
+	synced := map[int]bool{}
+	known := map[string]bool{}
+	var keep []Synced
+	pending := slices.Clone(state.Tasks)
+	for i, t := range pending {
+		synced[t.ID] = true
+		known[t.Task] = true
+		local, here := current[t.ID]
+		alive := here && local.DeletedAt.IsZero()
+		r, there := remotes[t.Task]
+		there = there && !r.task.Deleted
+		theirs := s.fromTask(r)
+		changedHere := !alive || !same(local, t.Base)
+		changedThere := !there || !same(theirs, t.Base) || r.list != t.List
+
+		switch {
+		case !there && !alive:
+			// Gone from both.
+			continue
+		case !changedHere && !changedThere:
+			keep = append(keep, t)
+			continue
+		case !there:
+			if changedHere {
+				// Deleted on Google but changed here since, so saved again.
+				t.Task = ""
+				if err := s.push(&t, local); err != nil {
+					return res, err
+				}
+				res.Pushed++
+				break
+			}
+			if err := store.Delete(t.ID); err != nil {
+				return res, err
+			}
+			res.Deleted++
+			continue
+		case !alive && !changedThere:
+			if err := c.DeleteTask(t.List, t.Task); err != nil {
+				return res, err
+			}
+			res.Deleted++
+			continue
+		case !alive || !changedHere:
+			// Changed on Google, and perhaps deleted here, in which case
+			// the change brings it back.
+			if t.ID, err = s.pull(t.ID, theirs, here); err != nil {
+				return res, err
+			}
+			t.List, t.Base = r.list, theirs
+			res.Pulled++
+		case !changedThere:
+			if err := s.push(&t, local); err != nil {
+				return res, err
+			}
+			res.Pushed++
+		default:
+			merged, fields := todosync.Merge(t.Base, kept(local), theirs)
+			res.Conflicts += len(fields)
+			if !same(merged, local) {
+				if _, err := s.pull(t.ID, merged, true); err != nil {
+					return res, err
+				}
+				res.Pulled++
+			}
+			t.List = r.list
+			if err := s.push(&t, merged); err != nil {
+				return res, err
+			}
+			res.Pushed++
+		}
+		keep = append(keep, t)
+		state.Tasks = append(slices.Clone(keep), pending[i+1:]...)
+		if err := SaveState(store, *state); err != nil {
+			return res, err
+		}
+	}
+	state.Tasks = keep
+
+	for _, id := range order {
+		r := remotes[id]
+		if known[id] || r.task.Deleted || r.task.Status == "completed" {
+			continue
+		}
+		theirs := s.fromTask(r)
+		added, err := s.pull(0, theirs, false)
+		if err != nil {
+			return res, err
+		}
+		state.Tasks = append(state.Tasks, Synced{ID: added, List: r.list, Task: id, Base: theirs})
+		res.Pulled++
+		if err := SaveState(store, *state); err != nil {
+			return res, err
+		}
+	}
+	for _, id := range sortedIDs(current) {
+		item := current[id]
+		if synced[id] || !item.DeletedAt.IsZero() || item.Completed {
+			continue
+		}
+		t := Synced{ID: id}
+		if err := s.push(&t, item); err != nil {
+			return res, err
+		}
+		state.Tasks = append(state.Tasks, t)
+		res.Pushed++
+		if err := SaveState(store, *state); err != nil {
+			return res, err
+		}
+	}
+	state.Synced = time.Now()
+	return res, SaveState(store, *state)
+}
+
+// syncer makes the changes of a sync.
+type syncer struct {
+	store todo.Store
+	c     *Client
+	// lists are the IDs of the task lists other than the default one by
+	// lower case title, and names their titles by ID. inbox is the ID of
+	// the default list.
+	lists map[string]string
+	names map[string]string
+	inbox string
+}
+
+// pull makes the item with id, or a new one if there isn't one, have the
+// fields of want, taking it out of the trash if it is there, and returns
+// its ID. The time an item is due is kept if only the date is given.
+func (s *syncer) pull(id int, want todo.ParsedTodoItem, here bool) (int, error) {
+	if !here {
+		want.CreatedAt = time.Now()
+		if want.Completed {
+			want.CompletedAt = want.CreatedAt
+		}
+		saved, err := s.store.Add(want)
+		return saved.ID, err
+	}
+	current, err := todo.AllItems(s.store)
+	if err != nil {
+		return 0, err
+	}
+	item := current[id]
+	trashed := !item.DeletedAt.IsZero()
+	item.Todo, item.Notes, item.Project = want.Todo, want.Notes, want.Project
+	if !want.Due.Equal(day(item.Due)) {
+		item.Due = want.Due
+	}
+	if want.Completed != item.Completed {
+		status := todo.StatusBacklog
+		if want.Completed {
+			status = todo.StatusDone
+		}
+		item.SetStatus(status, time.Now())
+	}
+	if trashed {
+		if err := s.store.Restore(id); err != nil {
+			return 0, err
+		}
+	}
+	return id, s.store.Update(item)
+}
+
+// push saves item to the task t is for, or a new one if t has no task
+// yet, moving it to the list for its project if it is in another.
+func (s *syncer) push(t *Synced, item todo.ParsedTodoItem) error {
+	list, err := s.list(item.Project)
+	if err != nil {
+		return err
+	}
+	fields := map[string]any{
+		"title":     item.Todo,
+		"notes":     item.Notes,
+		"status":    "needsAction",
+		"due":       nil,
+		"completed": nil,
+	}
+	if item.Completed {
+		fields["status"] = "completed"
+		delete(fields, "completed")
+	}
+	if !item.Due.IsZero() {
+		y, m, d := item.Due.In(todo.Location).Date()
+		fields["due"] = time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Format(time.RFC3339)
+	}
+
+	var task Task
+	if t.Task != "" && t.List == list {
+		task, err = s.c.UpdateTask(list, t.Task, fields)
+	} else {
+		// Tasks are moved to another list by being made again in it.
+		if task, err = s.c.AddTask(list, fields); err == nil && t.Task != "" {
+			err = s.c.DeleteTask(t.List, t.Task)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	t.List, t.Task, t.Base = list, task.ID, kept(item)
+	return nil
+}
+
+// list returns the ID of the task list for project, making one if there
+// isn't one yet. Items without a project go in the default list.
+func (s *syncer) list(project string) (string, error) {
+	if project == "" {
+		return s.inbox, nil
+	}
+	if id, ok := s.lists[strings.ToLower(project)]; ok {
+		return id, nil
+	}
+	l, err := s.c.AddList(project)
+	if err != nil {
+		return "", err
+	}
+	s.lists[strings.ToLower(project)] = l.ID
+	s.names[l.ID] = l.Title
+	return l.ID, nil
+}
+
+// fromTask returns the fields of r kept in step.
+func (s *syncer) fromTask(r remote) todo.ParsedTodoItem {
+	item := todo.ParsedTodoItem{
+		Todo:      r.task.Title,
+		Notes:     r.task.Notes,
+		Project:   s.names[r.list],
+		Completed: r.task.Status == "completed",
+	}
+	if due, err := time.Parse(time.RFC3339, r.task.Due); err == nil {
+		// The date is given at midnight UTC.
+		y, m, d := due.UTC().Date()
+		item.Due = time.Date(y, m, d, 0, 0, 0, 0, todo.Location)
+	}
+	return item
+}
+
+// kept returns the fields of item that are kept in step with Google
+// Tasks, with the others left unset.
+func kept(item todo.ParsedTodoItem) todo.ParsedTodoItem {
+	return todo.ParsedTodoItem{
+		Todo:      item.Todo,
+		Notes:     item.Notes,
+		Project:   item.Project,
+		Due:       day(item.Due),
+		Completed: item.Completed,
+	}
+}
+
+// day returns the start of the day t is on in todo.Location.
+func day(t time.Time) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	y, m, d := t.In(todo.Location).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, todo.Location)
+}
+
+// same reports whether a and b have the same fields kept in step.
+func same(a, b todo.ParsedTodoItem) bool {
+	return todosync.Hash(kept(a)) == todosync.Hash(kept(b))
+}
+
+// sortedIDs returns the IDs of the items, in order.
+func sortedIDs(byID map[int]todo.ParsedTodoItem) []int {
+	ids := make([]int, 0, len(byID))
+	for id := range byID {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return ids
+}
@@ -0,0 +1,494 @@
+// Package ical reads and writes todo items as iCalendar (RFC 5545)
+// components: as VTODOs for `todo-app caldav` to sync, and as VTODOs or
+// VEVENTs for `todo-app export -format ics` to put them in calendar apps.
+package ical
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/buck06191/todo-app/pkg/todo"
+)
+
+// ErrNoTodo is returned by Decode for a calendar object without a VTODO.
+var ErrNoTodo = errors.New("no VTODO in the calendar object")
+
+// Task is an item as a VTODO component, with the item's references to
+// others given by their UIDs.
+type Task struct {
+	UID  string
+	Item todo.ParsedTodoItem
+	// Parent is the UID of the item's parent and BlockedBy the UIDs of the
+	// items it is waiting on.
+	Parent    string
+	BlockedBy []string
+}
+
+const (
+	dateFormat     = "20060102"
+	dateTimeFormat = "20060102T150405Z"
+)
+
+// Options change how Write writes tasks.
+type Options struct {
+	// Events writes each task as a VEVENT on the day or at the time it is
+	// due instead of as a VTODO, for calendar apps that don't show tasks.
+	// Tasks that aren't due are left out.
+	Events bool
+	// Remind, if it isn't zero, gives each task that is due an alarm this
+	// long before then. Tasks with their own reminder times get an alarm
+	// at each of those instead, whatever Remind is.
+	Remind time.Duration
+}
+
+// Encode returns task as an iCalendar object holding a single VTODO, as
+// saved on CalDAV servers.
+func Encode(task Task) []byte {
+	var b bytes.Buffer
+	calendar(&b, []Task{task}, Options{})
+	return b.Bytes()
+}
+
+// Write writes tasks to w as one iCalendar object, such as can be
+// imported into or subscribed to from calendar apps.
+func Write(w io.Writer, tasks []Task, opts Options) error {
+	var b bytes.Buffer
+	calendar(&b, tasks, opts)
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// calendar writes a VCALENDAR holding a component for each task.
+//
+// The item's text is the SUMMARY and its notes the DESCRIPTION. Tags,
+// contexts and the project are all CATEGORIES, the contexts starting with
+// @ and the project with +, as they are written when adding an item.
+// Priorities are 1 for high, 5 for medium and 9 for low, and the doing
+// status is IN-PROCESS.
+func calendar(b *bytes.Buffer, tasks []Task, opts Options) {
+	prop := func(name, value string) {
+		fold(b, name+":"+value)
+	}
+	stamp := time.Now().UTC().Format(dateTimeFormat)
+
+	prop("BEGIN", "VCALENDAR")
+	prop("VERSION", "2.0")
+	prop("PRODID", "-//todo-app//todo-app//EN")
+	for _, task := range tasks {
+		item := task.Item
+		component := "VTODO"
+		if opts.Events {
+			if item.Due.IsZero() {
+				continue
+			}
+			component = "VEVENT"
+		}
+		prop("BEGIN", component)
+		prop("UID", escape(task.UID))
+		prop("DTSTAMP", stamp)
+		if !item.CreatedAt.IsZero() {
+			prop("CREATED", item.CreatedAt.UTC().Format(dateTimeFormat))
+		}
+		prop("SUMMARY", escape(item.Todo))
+		if item.Notes != "" {
+			prop("DESCRIPTION", escape(item.Notes))
+		}
+		when := "DUE"
+		if opts.Events {
+			when = "DTSTART"
+		}
+		switch {
+		case item.Due.IsZero():
+		case item.DueAllDay():
+			prop(when+";VALUE=DATE", item.Due.In(todo.Location).Format(dateFormat))
+		default:
+			prop(when, item.Due.UTC().Format(dateTimeFormat))
+		}
+		if p := priorities[item.Priority]; p != 0 {
+			prop("PRIORITY", strconv.Itoa(p))
+		}
+		// Events have no status of their own for being done or started.
+		switch {
+		case opts.Events:
+		case item.Completed:
+			prop("STATUS", "COMPLETED")
+			if !item.CompletedAt.IsZero() {
+				prop("COMPLETED", item.CompletedAt.UTC().Format(dateTimeFormat))
+			}
+		case item.Status == todo.StatusDoing:
+			prop("STATUS", "IN-PROCESS")
+		default:
+			prop("STATUS", "NEEDS-ACTION")
+		}
+
+		var categories []string
+		for _, tag := range item.Tags {
+			categories = append(categories, escape(tag))
+		}
+		for _, context := range item.Contexts {
+			categories = append(categories, escape("@"+context))
+		}
+		if item.Project != "" {
+			categories = append(categories, escape("+"+item.Project))
+		}
+		if len(categories) > 0 {
+			prop("CATEGORIES", strings.Join(categories, ","))
+		}
+		if item.Repeat != "" {
+			if r, err := todo.ParseRepeat(item.Repeat, item.Due); err == nil {
+				prop("RRULE", r.RRule())
+			}
+		}
+		if task.Parent != "" {
+			prop("RELATED-TO;RELTYPE=PARENT", escape(task.Parent))
+		}
+		for _, uid := range task.BlockedBy {
+			prop("RELATED-TO;RELTYPE=DEPENDS-ON", escape(uid))
+		}
+		if !item.Due.IsZero() && !item.Completed {
+			// Items given their own reminder times get an alarm at each
+			// of them instead.
+			var remind []time.Duration
+			if opts.Remind != 0 {
+				remind = []time.Duration{opts.Remind}
+			}
+			// A VTODO's alarm is relative to its start unless it says
+			// otherwise, and it may have none.
+			trigger := "TRIGGER"
+			if !opts.Events {
+				trigger += ";RELATED=END"
+			}
+			for _, before := range item.RemindOffsets(remind) {
+				prop("BEGIN", "VALARM")
+				prop("ACTION", "DISPLAY")
+				prop("DESCRIPTION", escape(item.Todo))
+				prop(trigger, duration(-before))
+				prop("END", "VALARM")
+			}
+		}
+		prop("END", component)
+	}
+	prop("END", "VCALENDAR")
+}
+
+// duration formats d as an iCalendar DURATION, such as -PT15M.
+func duration(d time.Duration) string {
+	var b strings.Builder
+	if d < 0 {
+		b.WriteByte('-')
+		d = -d
+	}
+	b.WriteByte('P')
+	if days := d / (24 * time.Hour); days > 0 {
+		fmt.Fprintf(&b, "%dD", days)
+		d -= days * 24 * time.Hour
+	}
+	if d == 0 {
+		if b.Len() <= 2 {
+			// No time at all, which still needs a unit.
+			b.WriteString("T0S")
+		}
+		return b.String()
+	}
+	b.WriteByte('T')
+	if h := d / time.Hour; h > 0 {
+		fmt.Fprintf(&b, "%dH", h)
+		d -= h * time.Hour
+	}
+	if m := d / time.Minute; m > 0 {
+		fmt.Fprintf(&b, "%dM", m)
+		d -= m * time.Minute
+	}
+	if s := d / time.Second; s > 0 {
+		fmt.Fprintf(&b, "%dS", s)
+	}
+	return b.String()
+}
+
+// parseDuration parses an iCalendar DURATION, as duration writes it.
+func parseDuration(value string) (time.Duration, error) {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	sign := time.Duration(1)
+	switch {
+	case strings.HasPrefix(value, "-"):
+		sign, value = -1, value[1:]
+	case strings.HasPrefix(value, "+"):
+		value = value[1:]
+	}
+	rest, ok := strings.CutPrefix(value, "P")
+	if !ok || rest == "" {
+		return 0, fmt.Errorf("bad duration %q", value)
+	}
+	units := map[byte]time.Duration{'W': 7 * 24 * time.Hour, 'D': 24 * time.Hour}
+	var d time.Duration
+	for rest != "" {
+		if rest[0] == 'T' {
+			units = map[byte]time.Duration{'H': time.Hour, 'M': time.Minute, 'S': time.Second}
+			rest = rest[1:]
+			continue
+		}
+		i := strings.IndexFunc(rest, func(r rune) bool { return r < '0' || r > '9' })
+		if i <= 0 {
+			return 0, fmt.Errorf("bad duration %q", value)
+		}
+		n, _ := strconv.Atoi(rest[:i])
+		unit, ok := units[rest[i]]
+		if !ok {
+			return 0, fmt.Errorf("bad duration %q", value)
+		}
+		d += time.Duration(n) * unit
+		rest = rest[i+1:]
+	}
+	return sign * d, nil
+}
+
+// priorities are the iCalendar PRIORITY of each priority.
+var priorities = map[todo.Priority]int{
+	todo.PriorityHigh:   1,
+	todo.PriorityMedium: 5,
+	todo.PriorityLow:    9,
+}
+
+// priority returns the priority for an iCalendar PRIORITY, where 1 to 4
+// are high, 5 medium and 6 to 9 low.
+func priority(p int) todo.Priority {
+	switch {
+	case p <= 0:
+		return todo.PriorityNone
+	case p < 5:
+		return todo.PriorityHigh
+	case p == 5:
+		return todo.PriorityMedium
+	default:
+		return todo.PriorityLow
+	}
+}
+
+// Decode reads the first VTODO of an iCalendar object, as written by
+// Encode or by other CalDAV clients. Properties it has no use for are
+// ignored.
+func Decode(data []byte) (Task, error) {
+	var task Task
+	item := &task.Item
+	found, depth := false, 0
+	var remind []time.Duration
+	for _, line := range unfold(data) {
+		name, params, value := split(line)
+		switch {
+		case name == "BEGIN" && strings.EqualFold(value, "VTODO") && !found:
+			found, depth = true, 1
+			continue
+		case !found || depth == 0:
+			continue
+		case name == "BEGIN":
+			depth++
+			continue
+		case name == "END":
+			depth--
+			if depth == 0 {
+				if len(remind) > 0 {
+					item.Remind = todo.FormatOffsets(remind)
+				}
+				return task, nil
+			}
+			continue
+		case depth > 1:
+			// Alarms before the task is due are kept as its reminder
+			// times, and the other properties of a VALARM or the like
+			// ignored.
+			if name == "TRIGGER" && strings.EqualFold(params["RELATED"], "END") {
+				if d, err := parseDuration(value); err == nil && d <= 0 && !slices.Contains(remind, -d) {
+					remind = append(remind, -d)
+				}
+			}
+			continue
+		}
+
+		var err error
+		switch name {
+		case "UID":
+			task.UID = unescape(value)
+		case "SUMMARY":
+			item.Todo = unescape(value)
+		case "DESCRIPTION":
+			item.Notes = unescape(value)
+		case "CREATED":
+			item.CreatedAt, err = parseTime(value, params)
+		case "DUE":
+			item.Due, err = parseTime(value, params)
+		case "COMPLETED":
+			item.CompletedAt, err = parseTime(value, params)
+		case "PRIORITY":
+			var p int
+			p, err = strconv.Atoi(strings.TrimSpace(value))
+			item.Priority = priority(p)
+		case "STATUS":
+			switch strings.ToUpper(value) {
+			case "COMPLETED":
+				item.Completed = true
+			case "IN-PROCESS":
+				item.Status = todo.StatusDoing
+			}
+		case "CATEGORIES":
+			for _, c := range splitList(value) {
+				switch {
+				case strings.HasPrefix(c, "@") && len(c) > 1:
+					item.Contexts = append(item.Contexts, c[1:])
+				case strings.HasPrefix(c, "+") && len(c) > 1 && item.Project == "":
+					item.Project = c[1:]
+				case c != "":
+					item.Tags = append(item.Tags, c)
+				}
+			}
+		case "RRULE":
+			// Only rules that can be followed are kept.
+			if _, err := todo.ParseRepeat(value, item.Due); err == nil {
+				item.Repeat = value
+			}
+		case "RELATED-TO":
+			switch strings.ToUpper(params["RELTYPE"]) {
+			case "", "PARENT":
+				task.Parent = unescape(value)
+			case "DEPENDS-ON":
+				task.BlockedBy = append(task.BlockedBy, unescape(value))
+			}
+		}
+		if err != nil {
+			return task, fmt.Errorf("VTODO %s: %w", name, err)
+		}
+	}
+	if !found {
+		return task, ErrNoTodo
+	}
+	return task, errors.New("VTODO never ends")
+}
+
+// parseTime parses a DATE or DATE-TIME value. Times without a zone, and
+// dates, are in todo.Location.
+func parseTime(value string, params map[string]string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	loc := todo.Location
+	if tzid := params["TZID"]; tzid != "" {
+		if l, err := time.LoadLocation(strings.Trim(tzid, `"`)); err == nil {
+			loc = l
+		}
+	}
+	switch {
+	case len(value) == len(dateFormat):
+		return time.ParseInLocation(dateFormat, value, todo.Location)
+	case strings.HasSuffix(value, "Z"):
+		return time.Parse(dateTimeFormat, value)
+	default:
+		return time.ParseInLocation("20060102T150405", value, loc)
+	}
+}
+
+// fold writes line to b, folded into lines of at most 75 bytes as
+// iCalendar wants, without splitting UTF-8 sequences.
+func fold(b *bytes.Buffer, line string) {
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// The leading space counts towards the next line.
+		limit = 74
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
+
+// unfold returns the lines of data with folded lines joined back up.
+func unfold(data []byte) []string {
+	var lines []string
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(nil, len(data)+1)
+	for sc.Scan() {
+		line := strings.TrimSuffix(sc.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// split splits a content line into its upper case name, its parameters
+// and its value.
+func split(line string) (string, map[string]string, string) {
+	// The value starts at the first colon that isn't in a quoted
+	// parameter value.
+	quoted, colon := false, -1
+	for i, r := range line {
+		if r == '"' {
+			quoted = !quoted
+		}
+		if r == ':' && !quoted {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return strings.ToUpper(line), nil, ""
+	}
+	fields := strings.Split(line[:colon], ";")
+	params := map[string]string{}
+	for _, p := range fields[1:] {
+		k, v, _ := strings.Cut(p, "=")
+		params[strings.ToUpper(k)] = v
+	}
+	return strings.ToUpper(fields[0]), params, line[colon+1:]
+}
+
+var escaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`)
+
+func escape(text string) string {
+	return escaper.Replace(strings.ReplaceAll(text, "\r\n", "\n"))
+}
+
+func unescape(text string) string {
+	var b strings.Builder
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\\' && i+1 < len(text) {
+			i++
+			switch text[i] {
+			case 'n', 'N':
+				b.WriteByte('\n')
+			default:
+				b.WriteByte(text[i])
+			}
+			continue
+		}
+		b.WriteByte(text[i])
+	}
+	return b.String()
+}
+
+// splitList splits a list of text values on the commas that aren't
+// escaped, unescaping each.
+func splitList(value string) []string {
+	var values []string
+	start := 0
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			i++
+		case ',':
+			values = append(values, unescape(value[start:i]))
+			start = i + 1
+		}
+	}
+	return append(values, unescape(value[start:]))
+}
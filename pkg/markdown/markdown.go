@@ -0,0 +1,231 @@
+// Package markdown reads and writes todo items as Markdown checklists,
+// for `todo-app export -format md` and `todo-app import -format md`, to
+// take the list through notes apps and pull request descriptions:
+//
+//	## Errands
+//
+//	- [ ] Call the bank #money @phone (due: 2026-03-05 15:00, priority: high)
+//	  Ask about the loan too.
+//	  - [x] Find the account number
+//
+//	## Garden
+//
+//	- [ ] Water the plants (due: 2026-03-06, repeat: weekly)
+//
+// Items are grouped under a heading for their project, after the ones
+// without one. Subtasks are nested under their parents, and notes are
+// indented under their item. #tag and @context words are read as
+// `todo-app add` reads them.
+package markdown
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/buck06191/todo-app/pkg/todo"
+)
+
+// Write writes items as checklists grouped by project, in the order the
+// items are given.
+func Write(w io.Writer, items []todo.ParsedTodoItem) error {
+	listed := map[int]bool{}
+	for _, item := range items {
+		listed[item.ID] = true
+	}
+	children := map[int][]todo.ParsedTodoItem{}
+	var projects []string
+	byProject := map[string][]todo.ParsedTodoItem{}
+	for _, item := range items {
+		if item.Parent != 0 && listed[item.Parent] {
+			children[item.Parent] = append(children[item.Parent], item)
+			continue
+		}
+		if _, ok := byProject[item.Project]; !ok && item.Project != "" {
+			projects = append(projects, item.Project)
+		}
+		byProject[item.Project] = append(byProject[item.Project], item)
+	}
+
+	var b strings.Builder
+	var write func(item todo.ParsedTodoItem, depth int)
+	write = func(item todo.ParsedTodoItem, depth int) {
+		indent := strings.Repeat("  ", depth)
+		fmt.Fprintf(&b, "%s%s\n", indent, Format(item))
+		if notes := strings.TrimSpace(item.Notes); notes != "" {
+			for _, line := range strings.Split(notes, "\n") {
+				if line = strings.TrimRight(line, " \t\r"); line != "" {
+					line = indent + "  " + line
+				}
+				b.WriteString(line + "\n")
+			}
+		}
+		for _, child := range children[item.ID] {
+			write(child, depth+1)
+		}
+	}
+	for _, item := range byProject[""] {
+		write(item, 0)
+	}
+	for i, project := range projects {
+		if i > 0 || len(byProject[""]) > 0 {
+			b.WriteByte('\n')
+		}
+		fmt.Fprintf(&b, "## %s\n\n", project)
+		for _, item := range byProject[project] {
+			write(item, 0)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Format returns the checklist line for item, without its notes.
+func Format(item todo.ParsedTodoItem) string {
+	box := "[ ]"
+	if item.Completed {
+		box = "[x]"
+	}
+	words := append([]string{"-", box}, strings.Fields(item.Todo)...)
+	for _, t := range item.Tags {
+		words = append(words, "#"+t)
+	}
+	for _, c := range item.Contexts {
+		words = append(words, "@"+c)
+	}
+	var fields []string
+	if !item.Due.IsZero() {
+		layout := "2006-01-02 15:04"
+		if item.DueAllDay() {
+			layout = "2006-01-02"
+		}
+		fields = append(fields, "due: "+item.Due.In(todo.Location).Format(layout))
+	}
+	if item.Priority != todo.PriorityNone {
+		fields = append(fields, "priority: "+item.Priority.String())
+	}
+	if item.Repeat != "" {
+		fields = append(fields, "repeat: "+item.Repeat)
+	}
+	if len(fields) > 0 {
+		words = append(words, "("+strings.Join(fields, ", ")+")")
+	}
+	return strings.Join(words, " ")
+}
+
+var (
+	heading  = regexp.MustCompile(`^#{1,6}\s+(.*?)\s*#*$`)
+	checkbox = regexp.MustCompile(`^([ \t]*)[-*+]\s+\[([ xX])\]\s+(.*)$`)
+	trailer  = regexp.MustCompile(`\s*\(((?:due|priority|repeat):[^()]*)\)$`)
+)
+
+// Read reads the checklist items of a Markdown document, skipping
+// everything else. An item's project is that of the heading it is under,
+// and its subtasks are the items nested under it, which are given IDs for
+// Parent to refer to, for the caller to number anew as it adds them. Text
+// indented under an item is its notes.
+func Read(r io.Reader) ([]todo.ParsedTodoItem, error) {
+	var items []todo.ParsedTodoItem
+	var project string
+	// open are the indents and positions in items of the items the next
+	// one may be nested in, innermost last.
+	type openItem struct{ indent, index int }
+	var open []openItem
+	var notes []string
+	// last is the position in items of the item indented text is the notes
+	// of, or -1 if there isn't one.
+	last := -1
+	flush := func() {
+		if last >= 0 {
+			items[last].Notes = strings.TrimSpace(strings.Join(notes, "\n"))
+		}
+		notes = nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if m := checkbox.FindStringSubmatch(line); m != nil {
+			flush()
+			item, err := parseItem(m[3])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", n, err)
+			}
+			indent := width(m[1])
+			for len(open) > 0 && open[len(open)-1].indent >= indent {
+				open = open[:len(open)-1]
+			}
+			item.ID, item.Project, item.Completed = len(items)+1, project, m[2] != " "
+			if len(open) > 0 {
+				item.Parent = items[open[len(open)-1].index].ID
+			}
+			open = append(open, openItem{indent: indent, index: len(items)})
+			last = len(items)
+			items = append(items, item)
+			continue
+		}
+		if last >= 0 && (line == "" || width(leading(line)) > open[len(open)-1].indent) {
+			notes = append(notes, strings.TrimSpace(line))
+			continue
+		}
+		flush()
+		last, open = -1, nil
+		if m := heading.FindStringSubmatch(line); m != nil {
+			project = m[1]
+		}
+	}
+	flush()
+	return items, scanner.Err()
+}
+
+// parseItem parses the text of a checklist item after its box.
+func parseItem(text string) (todo.ParsedTodoItem, error) {
+	var item todo.ParsedTodoItem
+	if m := trailer.FindStringSubmatchIndex(text); m != nil {
+		for _, field := range strings.Split(text[m[2]:m[3]], ",") {
+			key, value, _ := strings.Cut(field, ":")
+			value = strings.TrimSpace(value)
+			var err error
+			switch strings.TrimSpace(key) {
+			case "due":
+				item.Due, err = parseDue(value)
+			case "priority":
+				item.Priority, err = todo.ParsePriority(value)
+			case "repeat":
+				item.Repeat = value
+			}
+			if err != nil {
+				return item, err
+			}
+		}
+		text = text[:m[0]]
+	}
+	text, item.Tags = todo.ExtractTags(text)
+	item.Todo, item.Contexts = todo.ExtractContexts(text)
+	if item.Todo == "" {
+		return item, todo.ErrEmptyTodo
+	}
+	return item, nil
+}
+
+// parseDue parses a due date as Write gives it, or as todo.ParseDueDate
+// takes it.
+func parseDue(s string) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02 15:04", s, todo.Location); err == nil {
+		return t, nil
+	}
+	return todo.ParseDueDate(s)
+}
+
+// leading returns the spaces and tabs line starts with.
+func leading(line string) string {
+	return line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+}
+
+// width returns how many columns indent takes, with tabs as four.
+func width(indent string) int {
+	return len(strings.ReplaceAll(indent, "\t", "    "))
+}
@@ -0,0 +1,106 @@
+package mstodo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// loginURL is the Microsoft identity platform, which tenant is added to.
+const loginURL = "https://login.microsoftonline.com/"
+
+// scope is what is asked for: reading the user's tasks.
+const scope = "Tasks.Read"
+
+// Login logs in to Microsoft with the device code flow, returning an
+// access token for Graph. clientID is the application made for todo-app in
+// the Azure portal, as a public client, and tenant is "common" for both
+// personal and work accounts, "consumers" for personal ones or the
+// organisation's ID. show is called with the instructions Microsoft gives
+// for where to enter the code; Login then waits for the user to have done
+// so, or for ctx to be done.
+func Login(ctx context.Context, clientID, tenant string, show func(message string)) (string, error) {
+	base := loginURL + url.PathEscape(tenant) + "/oauth2/v2.0/"
+	var code struct {
+		DeviceCode string `json:"device_code"`
+		Message    string `json:"message"`
+		Interval   int    `json:"interval"`
+	}
+	if err := post(ctx, base+"devicecode", url.Values{"client_id": {clientID}, "scope": {scope}}, &code); err != nil {
+		return "", err
+	}
+	show(code.Message)
+
+	interval := time.Duration(max(code.Interval, 1)) * time.Second
+	for {
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+		var token struct {
+			AccessToken string `json:"access_token"`
+		}
+		err := post(ctx, base+"token", url.Values{
+			"grant_type":  {"urn:ietf:params:oauth:grant-type:device_code"},
+			"client_id":   {clientID},
+			"device_code": {code.DeviceCode},
+		}, &token)
+		var oauthErr *oauthError
+		switch {
+		case errors.As(err, &oauthErr) && oauthErr.Code == "authorization_pending":
+			continue
+		case errors.As(err, &oauthErr) && oauthErr.Code == "slow_down":
+			interval += 5 * time.Second
+			continue
+		case err != nil:
+			return "", err
+		}
+		return token.AccessToken, nil
+	}
+}
+
+// oauthError is an error answered by the identity platform.
+type oauthError struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+func (e *oauthError) Error() string {
+	// The description starts with a code of its own and ends with trace
+	// IDs, over several lines.
+	desc, _, _ := strings.Cut(e.Description, "\r\n")
+	return fmt.Sprintf("logging in to Microsoft: %s: %s", e.Code, desc)
+}
+
+// post posts form to the identity platform, reading the answer into out.
+func post(ctx context.Context, endpoint string, form url.Values, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := (&http.Client{Timeout: timeout}).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		e := &oauthError{}
+		if json.Unmarshal(body, e) != nil || e.Code == "" {
+			return fmt.Errorf("logging in to Microsoft: %s", resp.Status)
+		}
+		return e
+	}
+	return json.Unmarshal(body, out)
+}
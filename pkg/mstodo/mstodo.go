@@ -0,0 +1,306 @@
+// Package mstodo imports lists and tasks from Microsoft To Do through
+// Microsoft Graph, for `todo-app import mstodo`, logging in with the OAuth
+// 2.0 device code flow.
+//
+// Each list becomes a project, apart from the default one, "Tasks", whose
+// tasks are added without one. Steps become subtasks of the item for their
+// task. The list remembers which tasks it has imported, so importing again
+// only adds the ones that are new since.
+package mstodo
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/buck06191/todo-app/pkg/todo"
+)
+
+// graphURL is the address of Microsoft Graph.
+const graphURL = "https://graph.microsoft.com/v1.0"
+
+// timeout is how long a request to Microsoft can take.
+const timeout = 30 * time.Second
+
+// stateKey is the meta key the list keeps the IDs of imported tasks under.
+const stateKey = "mstodo"
+
+// Client reads a user's lists and tasks from Microsoft Graph.
+type Client struct {
+	token string
+	http  *http.Client
+}
+
+// NewClient returns a Client using the access token Login returned.
+func NewClient(token string) *Client {
+	return &Client{token: token, http: &http.Client{Timeout: timeout}}
+}
+
+// List is a task list.
+type List struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"displayName"`
+	// WellknownListName is defaultList for the list tasks go in unless
+	// another is picked.
+	WellknownListName string `json:"wellknownListName"`
+}
+
+// Task is a task, with the fields that are imported.
+type Task struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	Body  struct {
+		Content     string `json:"content"`
+		ContentType string `json:"contentType"`
+	} `json:"body"`
+	// Importance is low, normal or high, and Status notStarted,
+	// inProgress, completed, waitingOnOthers or deferred.
+	Importance        string    `json:"importance"`
+	Status            string    `json:"status"`
+	Categories        []string  `json:"categories"`
+	CreatedDateTime   time.Time `json:"createdDateTime"`
+	DueDateTime       *dateTime `json:"dueDateTime"`
+	CompletedDateTime *dateTime `json:"completedDateTime"`
+	ChecklistItems    []struct {
+		DisplayName string `json:"displayName"`
+		IsChecked   bool   `json:"isChecked"`
+	} `json:"checklistItems"`
+}
+
+// dateTime is a time as Graph gives it: a time without an offset, in the
+// named time zone.
+type dateTime struct {
+	DateTime string `json:"dateTime"`
+	TimeZone string `json:"timeZone"`
+}
+
+// time returns d as a time, or the zero time if it can't be read. Zones
+// Go doesn't know, such as Windows ones, are taken as UTC, which Graph
+// uses unless asked otherwise.
+func (d *dateTime) time() time.Time {
+	if d == nil {
+		return time.Time{}
+	}
+	loc, err := time.LoadLocation(d.TimeZone)
+	if err != nil {
+		loc = time.UTC
+	}
+	t, err := time.ParseInLocation("2006-01-02T15:04:05.9999999", d.DateTime, loc)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// Lists returns every task list.
+func (c *Client) Lists() ([]List, error) {
+	var lists []List
+	err := c.pages("/me/todo/lists", func(raw json.RawMessage) error {
+		var page []List
+		err := json.Unmarshal(raw, &page)
+		lists = append(lists, page...)
+		return err
+	})
+	return lists, err
+}
+
+// Tasks returns every task in the list with id, with its steps.
+func (c *Client) Tasks(list string) ([]Task, error) {
+	var tasks []Task
+	path := "/me/todo/lists/" + url.PathEscape(list) + "/tasks?" + url.Values{"$expand": {"checklistItems"}}.Encode()
+	err := c.pages(path, func(raw json.RawMessage) error {
+		var page []Task
+		err := json.Unmarshal(raw, &page)
+		tasks = append(tasks, page...)
+		return err
+	})
+	return tasks, err
+}
+
+// pages gets every page of the collection at path, passing the items of
+// each to add.
+func (c *Client) pages(path string, add func(json.RawMessage) error) error {
+	next := graphURL + path
+	for next != "" {
+		var page struct {
+			Value    json.RawMessage `json:"value"`
+			NextLink string          `json:"@odata.nextLink"`
+		}
+		if err := c.get(next, &page); err != nil {
+			return err
+		}
+		if page.Value != nil {
+			if err := add(page.Value); err != nil {
+				return err
+			}
+		}
+		next = page.NextLink
+	}
+	return nil
+}
+
+// get reads the JSON answer to a GET of address into out.
+func (c *Client) get(address string, out any) error {
+	req, err := http.NewRequest(http.MethodGet, address, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		var e struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		if json.Unmarshal(msg, &e) == nil && e.Error.Message != "" {
+			msg = []byte(e.Error.Message)
+		}
+		return fmt.Errorf("Microsoft Graph: GET %s: %s %s", strings.TrimPrefix(address, graphURL), resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("reading the answer from Microsoft Graph: %w", err)
+	}
+	return nil
+}
+
+// Result says what an Import did.
+type Result struct {
+	// Lists is how many lists were read, Added how many items were added,
+	// steps included, and Skipped how many tasks had been imported before.
+	Lists, Added, Skipped int
+}
+
+// Import adds every task in the user's lists to store, but for the ones a
+// previous Import added.
+func Import(store todo.Store, c *Client) (Result, error) {
+	var res Result
+	imported, err := load(store)
+	if err != nil {
+		return res, err
+	}
+	lists, err := c.Lists()
+	if err != nil {
+		return res, err
+	}
+	for _, l := range lists {
+		res.Lists++
+		tasks, err := c.Tasks(l.ID)
+		if err != nil {
+			return res, err
+		}
+		project := l.DisplayName
+		if l.WellknownListName == "defaultList" {
+			project = ""
+		}
+		for _, t := range tasks {
+			if _, ok := imported[t.ID]; ok {
+				res.Skipped++
+				continue
+			}
+			item, err := store.Add(fromTask(t, project))
+			if err != nil {
+				return res, err
+			}
+			res.Added++
+			for _, step := range t.ChecklistItems {
+				sub := todo.ParsedTodoItem{
+					Todo:      step.DisplayName,
+					Project:   project,
+					Parent:    item.ID,
+					CreatedAt: item.CreatedAt,
+				}
+				if step.IsChecked {
+					sub.Completed, sub.CompletedAt = true, item.CreatedAt
+				}
+				if _, err := store.Add(sub); err != nil {
+					return res, err
+				}
+				res.Added++
+			}
+			// Saved after each task, so an import that fails part of the way
+			// through can be run again without adding tasks twice.
+			imported[t.ID] = item.ID
+			if err := save(store, imported); err != nil {
+				return res, err
+			}
+		}
+	}
+	return res, nil
+}
+
+// fromTask returns the item for t, in project.
+func fromTask(t Task, project string) todo.ParsedTodoItem {
+	item := todo.ParsedTodoItem{
+		Todo:      t.Title,
+		Notes:     notes(t.Body.Content, t.Body.ContentType),
+		Project:   project,
+		CreatedAt: t.CreatedDateTime,
+	}
+	if item.CreatedAt.IsZero() {
+		item.CreatedAt = time.Now()
+	}
+	switch t.Importance {
+	case "high":
+		item.Priority = todo.PriorityHigh
+	case "low":
+		item.Priority = todo.PriorityLow
+	}
+	for _, c := range t.Categories {
+		item.Tags = todo.AddTags(item.Tags, strings.ReplaceAll(c, " ", "-"))
+	}
+	if due := t.DueDateTime.time(); !due.IsZero() {
+		// To Do only keeps the day a task is due.
+		y, m, d := due.Date()
+		item.Due = time.Date(y, m, d, 0, 0, 0, 0, todo.Location)
+	}
+	switch t.Status {
+	case "completed":
+		item.Completed = true
+		if item.CompletedAt = t.CompletedDateTime.time(); item.CompletedAt.IsZero() {
+			item.CompletedAt = time.Now()
+		}
+	case "inProgress":
+		item.Status = todo.StatusDoing
+	}
+	return item
+}
+
+var (
+	htmlBreak = regexp.MustCompile(`(?i)<br\s*/?>|</(p|div|li|h[1-6])>`)
+	htmlTag   = regexp.MustCompile(`<[^>]*>`)
+	blank     = regexp.MustCompile(`\n{3,}`)
+)
+
+// notes returns the body of a task as plain text.
+func notes(content, contentType string) string {
+	if strings.EqualFold(contentType, "html") {
+		content = htmlBreak.ReplaceAllString(content, "\n")
+		content = html.UnescapeString(htmlTag.ReplaceAllString(content, ""))
+		content = blank.ReplaceAllString(strings.ReplaceAll(content, "\r", ""), "\n\n")
+	}
+	return strings.TrimSpace(content)
+}
+
+// load returns the IDs of the items imported tasks were added as, by the
+// ID of the task.
+func load(store todo.Store) (map[string]int, error) {
+	imported := map[string]int{}
+	return imported, todo.LoadMeta(store, stateKey, &imported)
+}
+
+// save saves the IDs load returns.
+func save(store todo.Store, imported map[string]int) error {
+	return todo.SaveMeta(store, stateKey, imported)
+}
@@ -0,0 +1,81 @@
+// Package notify shows desktop notifications with the tool each OS has for
+// it: notify-send on Linux and the BSDs, osascript on macOS, and a toast
+// from PowerShell on Windows.
+package notify
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// ErrUnsupported is returned by Send on systems it can't notify on.
+var ErrUnsupported = errors.New("desktop notifications aren't supported on " + runtime.GOOS)
+
+// Notification is a desktop notification.
+type Notification struct {
+	Title string
+	Body  string
+	// Urgent notifications stay up until dismissed where the system can do
+	// that.
+	Urgent bool
+}
+
+// Send shows n on the desktop.
+func Send(n Notification) error {
+	cmd, err := command(runtime.GOOS, n)
+	if err != nil {
+		return err
+	}
+	out, err := cmd.CombinedOutput()
+	if errors.Is(err, exec.ErrNotFound) {
+		return fmt.Errorf("%w: %s isn't installed", ErrUnsupported, cmd.Path)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w: %s", cmd.Path, err, bytes.TrimSpace(out))
+	}
+	return nil
+}
+
+func command(goos string, n Notification) (*exec.Cmd, error) {
+	switch goos {
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", appleScriptString(n.Body), appleScriptString(n.Title))
+		return exec.Command("osascript", "-e", script), nil
+	case "windows":
+		// The text is passed in the environment rather than in the script,
+		// so nothing in it needs quoting.
+		cmd := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", toastScript)
+		cmd.Env = append(os.Environ(), "TODO_NOTIFY_TITLE="+n.Title, "TODO_NOTIFY_BODY="+n.Body)
+		return cmd, nil
+	case "linux", "freebsd", "openbsd", "netbsd", "dragonfly":
+		urgency := "normal"
+		if n.Urgent {
+			urgency = "critical"
+		}
+		return exec.Command("notify-send", "--app-name=todo-app", "--urgency="+urgency, "--", n.Title, n.Body), nil
+	}
+	return nil, ErrUnsupported
+}
+
+// appleScriptString quotes s as an AppleScript string.
+func appleScriptString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// toastScript shows a toast with the title and body in the environment,
+// as from PowerShell itself, since showing one needs the ID of an app
+// that is installed.
+const toastScript = `
+[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] > $null
+$xml = [Windows.UI.Notifications.ToastNotificationManager]::GetTemplateContent([Windows.UI.Notifications.ToastTemplateType]::ToastText02)
+$text = $xml.GetElementsByTagName('text')
+$text.Item(0).AppendChild($xml.CreateTextNode($env:TODO_NOTIFY_TITLE)) > $null
+$text.Item(1).AppendChild($xml.CreateTextNode($env:TODO_NOTIFY_BODY)) > $null
+$toast = [Windows.UI.Notifications.ToastNotification]::new($xml)
+[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier('{1AC14E77-02E7-4E5D-B744-2EB1AE5198B7}\WindowsPowerShell\v1.0\powershell.exe').Show($toast)
+`
@@ -0,0 +1,125 @@
+// Package seal encrypts data with a key derived from a passphrase or the
+// contents of a key file, using scrypt to derive the key and NaCl secretbox
+// to encrypt. It is used to keep a store encrypted on disk and the items
+// synced through a server encrypted on it.
+//
+// Every sealed box starts with the salt its key was derived with, so a Key
+// can open boxes sealed on other machines, or by earlier runs, given the
+// same secret.
+package seal
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"sync"
+
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+)
+
+// ErrWrongKey is returned by Open for a box sealed with another secret, or
+// one that has been tampered with.
+var ErrWrongKey = errors.New("can't decrypt: wrong passphrase or key, or the data has been changed")
+
+// SaltSize is the size of a salt, as made by NewSalt.
+const SaltSize = 16
+
+const nonceSize = 24
+
+// Key seals and opens boxes with keys derived from a secret. It is safe to
+// use from several goroutines.
+type Key struct {
+	secret []byte
+
+	mu sync.Mutex
+	// salt is the salt boxes are sealed with, and keys the keys derived
+	// so far, by salt.
+	salt string
+	keys map[string]*[32]byte
+}
+
+// NewKey returns the Key for secret. Boxes are sealed with the key derived
+// with salt, or given nil, with the salt of the first box opened or else a
+// new one.
+func NewKey(secret, salt []byte) (*Key, error) {
+	if len(secret) == 0 {
+		return nil, errors.New("the passphrase or key can't be empty")
+	}
+	if salt != nil && len(salt) != SaltSize {
+		return nil, fmt.Errorf("the salt must be %d bytes, not %d", SaltSize, len(salt))
+	}
+	return &Key{secret: secret, salt: string(salt), keys: map[string]*[32]byte{}}, nil
+}
+
+// NewSalt returns a new random salt.
+func NewSalt() ([]byte, error) {
+	salt := make([]byte, SaltSize)
+	_, err := rand.Read(salt)
+	return salt, err
+}
+
+// derive returns the key for salt, which takes a moment the first time.
+// k.mu must be held.
+func (k *Key) derive(salt string) (*[32]byte, error) {
+	if key, ok := k.keys[salt]; ok {
+		return key, nil
+	}
+	raw, err := scrypt.Key(k.secret, []byte(salt), 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, err
+	}
+	key := new([32]byte)
+	copy(key[:], raw)
+	k.keys[salt] = key
+	return key, nil
+}
+
+// Seal returns data encrypted.
+func (k *Key) Seal(data []byte) ([]byte, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if k.salt == "" {
+		salt, err := NewSalt()
+		if err != nil {
+			return nil, err
+		}
+		k.salt = string(salt)
+	}
+	key, err := k.derive(k.salt)
+	if err != nil {
+		return nil, err
+	}
+	var nonce [nonceSize]byte
+	if _, err := rand.Read(nonce[:]); err != nil {
+		return nil, err
+	}
+	box := append([]byte(k.salt), nonce[:]...)
+	return secretbox.Seal(box, data, &nonce, key), nil
+}
+
+// Open returns the data sealed in box.
+func (k *Key) Open(box []byte) ([]byte, error) {
+	if len(box) < SaltSize+nonceSize+secretbox.Overhead {
+		return nil, ErrWrongKey
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	salt := string(box[:SaltSize])
+	key, err := k.derive(salt)
+	if err != nil {
+		return nil, err
+	}
+	var nonce [nonceSize]byte
+	copy(nonce[:], box[SaltSize:])
+	data, ok := secretbox.Open(nil, box[SaltSize+nonceSize:], &nonce, key)
+	if !ok {
+		return nil, ErrWrongKey
+	}
+	if k.salt == "" {
+		k.salt = salt
+	}
+	return data, nil
+}
@@ -0,0 +1,70 @@
+package server
+
+import (
+	"time"
+
+	"github.com/buck06191/todo-app/pkg/todo"
+)
+
+// eventType is the kind of change an event is for.
+type eventType int
+
+const (
+	eventAdded eventType = iota + 1
+	eventUpdated
+	eventDeleted
+	eventRestored
+	eventPurged
+)
+
+// event is a change made to a list through the server.
+type event struct {
+	typ  eventType
+	item todo.ParsedTodoItem
+	time time.Time
+}
+
+// watcherBuffer is how many events a watcher can fall behind by before it
+// is dropped.
+const watcherBuffer = 64
+
+// watcher is sent the changes made to one list.
+type watcher struct {
+	list   string
+	events chan event
+}
+
+// watch starts sending the changes made to l to the returned watcher. The
+// events channel is closed if the watcher falls too far behind. s.mu must
+// be held.
+func (s *Server) watch(l list) *watcher {
+	w := &watcher{list: l.name, events: make(chan event, watcherBuffer)}
+	s.watchers[w] = struct{}{}
+	return w
+}
+
+// unwatch stops sending changes to w.
+func (s *Server) unwatch(w *watcher) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.watchers[w]; ok {
+		delete(s.watchers, w)
+		close(w.events)
+	}
+}
+
+// publish sends a change to l to everyone watching it. s.mu must be held.
+func (s *Server) publish(l list, typ eventType, item todo.ParsedTodoItem) {
+	e := event{typ: typ, item: item, time: time.Now()}
+	for w := range s.watchers {
+		if w.list != l.name {
+			continue
+		}
+		select {
+		case w.events <- e:
+		default:
+			delete(s.watchers, w)
+			close(w.events)
+		}
+	}
+}
@@ -0,0 +1,453 @@
+package server
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/graph-gophers/graphql-go"
+
+	"github.com/buck06191/todo-app/pkg/todo"
+)
+
+//go:embed schema.graphql
+var graphqlSchema string
+
+// graphqlMaxDepth is how deeply queries can nest, so following parents and
+// subtasks back and forth can't make a request take forever.
+const graphqlMaxDepth = 8
+
+// EnableGraphQL adds a POST /graphql endpoint to s, taking queries against
+// the schema in schema.graphql. It uses the same tokens as the rest of the
+// API, but only needs the write scope for mutations. It must be called
+// before s starts serving.
+func (s *Server) EnableGraphQL() {
+	schema := graphql.MustParseSchema(graphqlSchema, &gqlRoot{s: s},
+		// Resolvers take turns with the store like requests do.
+		graphql.MaxParallelism(1),
+		graphql.MaxDepth(graphqlMaxDepth),
+	)
+
+	s.mux.HandleFunc("POST /graphql", s.respond(func(r *http.Request) (int, any, error) {
+		token, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		user, err := s.authenticate(token, false)
+		if err != nil {
+			return 0, nil, err
+		}
+		_, writeErr := s.authenticate(token, true)
+
+		var params struct {
+			Query         string          `json:"query"`
+			OperationName string          `json:"operationName"`
+			Variables     map[string]any  `json:"variables"`
+			Extensions    json.RawMessage `json:"extensions"`
+		}
+		if err := decode(r, &params); err != nil {
+			return 0, nil, err
+		}
+
+		ctx := context.WithValue(r.Context(), gqlKey{}, &gqlRequest{user: user, writeErr: writeErr})
+		return http.StatusOK, schema.Exec(ctx, params.Query, params.OperationName, params.Variables), nil
+	}))
+}
+
+// gqlKey is the context key of the gqlRequest a query is run for.
+type gqlKey struct{}
+
+// gqlRequest is who a GraphQL request is from.
+type gqlRequest struct {
+	user string
+	// writeErr is why the request's token can't be used for mutations, or
+	// nil if it can.
+	writeErr error
+}
+
+// gqlRoot resolves the Query and Mutation types.
+type gqlRoot struct {
+	s *Server
+}
+
+// open returns the list named by a list argument, as for Server.open.
+func (g *gqlRoot) open(ctx context.Context, shared *string, write bool) (list, error) {
+	req := ctx.Value(gqlKey{}).(*gqlRequest)
+	if write && req.writeErr != nil {
+		return list{}, req.writeErr
+	}
+	return g.s.open(req.user, deref(shared), write)
+}
+
+type gqlTodosArgs struct {
+	List   *string
+	All    *bool
+	Where  *string
+	Search *string
+	Sort   *string
+}
+
+func (a gqlTodosArgs) query() query {
+	return query{all: a.All != nil && *a.All, where: deref(a.Where), q: deref(a.Search), sort: deref(a.Sort)}
+}
+
+func (g *gqlRoot) Todos(ctx context.Context, args gqlTodosArgs) ([]*gqlTodo, error) {
+	l, err := g.open(ctx, args.List, false)
+	if err != nil {
+		return nil, err
+	}
+	items, err := g.s.find(l, args.query())
+	return gqlTodos(l, items), err
+}
+
+type gqlIDArgs struct {
+	ID   int32
+	List *string
+}
+
+func (g *gqlRoot) Todo(ctx context.Context, args gqlIDArgs) (*gqlTodo, error) {
+	l, err := g.open(ctx, args.List, false)
+	if err != nil {
+		return nil, err
+	}
+	item, err := l.store.Get(int(args.ID))
+	if err != nil {
+		return nil, err
+	}
+	return &gqlTodo{item: item, l: l}, nil
+}
+
+func (g *gqlRoot) Trash(ctx context.Context, args struct{ List *string }) ([]*gqlTodo, error) {
+	l, err := g.open(ctx, args.List, false)
+	if err != nil {
+		return nil, err
+	}
+	items, err := l.store.Trash()
+	return gqlTodos(l, items), err
+}
+
+type gqlGroupsArgs struct {
+	By     string
+	List   *string
+	All    *bool
+	Where  *string
+	Search *string
+	Sort   *string
+}
+
+func (g *gqlRoot) Groups(ctx context.Context, args gqlGroupsArgs) ([]*gqlGroup, error) {
+	todosArgs := gqlTodosArgs{List: args.List, All: args.All, Where: args.Where, Search: args.Search, Sort: args.Sort}
+	l, err := g.open(ctx, args.List, false)
+	if err != nil {
+		return nil, err
+	}
+	items, err := g.s.find(l, todosArgs.query())
+	if err != nil {
+		return nil, err
+	}
+
+	keys := func(item todo.ParsedTodoItem) []string {
+		switch args.By {
+		case "PROJECT":
+			if item.Project != "" {
+				return []string{item.Project}
+			}
+			return nil
+		case "CONTEXT":
+			return item.Contexts
+		}
+		return item.Tags
+	}
+	groups := map[string]*gqlGroup{}
+	for _, item := range items {
+		ks := keys(item)
+		if len(ks) == 0 {
+			ks = []string{""}
+		}
+		for _, k := range ks {
+			if groups[k] == nil {
+				groups[k] = &gqlGroup{key: k}
+			}
+			groups[k].todos = append(groups[k].todos, &gqlTodo{item: item, l: l})
+		}
+	}
+
+	out := []*gqlGroup{}
+	for _, k := range slices.Sorted(maps.Keys(groups)) {
+		if k != "" {
+			out = append(out, groups[k])
+		}
+	}
+	if rest, ok := groups[""]; ok {
+		out = append(out, rest)
+	}
+	return out, nil
+}
+
+func (g *gqlRoot) Lists(ctx context.Context) ([]*gqlSharedList, error) {
+	user := ctx.Value(gqlKey{}).(*gqlRequest).user
+	lists, err := SharedLists(g.s.store)
+	if err != nil {
+		return nil, err
+	}
+	out := []*gqlSharedList{}
+	for _, l := range lists {
+		if l.Role(user) != "" {
+			out = append(out, &gqlSharedList{list: l, user: user})
+		}
+	}
+	return out, nil
+}
+
+type gqlTodoInput struct {
+	Todo     string
+	Due      *string
+	Priority *string
+	Tags     *[]string
+	Project  *string
+	Contexts *[]string
+	Repeat   *string
+	Parent   *int32
+	Notes    *string
+}
+
+func (g *gqlRoot) AddTodo(ctx context.Context, args struct {
+	Input gqlTodoInput
+	List  *string
+}) (*gqlTodo, error) {
+	l, err := g.open(ctx, args.List, true)
+	if err != nil {
+		return nil, err
+	}
+	in := todo.TodoItem{
+		Todo:     args.Input.Todo,
+		Due:      deref(args.Input.Due),
+		Priority: deref(args.Input.Priority),
+		Project:  deref(args.Input.Project),
+		Repeat:   deref(args.Input.Repeat),
+		Notes:    deref(args.Input.Notes),
+	}
+	if args.Input.Tags != nil {
+		in.Tags = *args.Input.Tags
+	}
+	if args.Input.Contexts != nil {
+		in.Contexts = *args.Input.Contexts
+	}
+	if args.Input.Parent != nil {
+		in.Parent = int(*args.Input.Parent)
+	}
+	item, err := g.s.addItem(l, in)
+	if err != nil {
+		return nil, err
+	}
+	return &gqlTodo{item: item, l: l}, nil
+}
+
+type gqlPatchInput struct {
+	Todo      *string
+	Due       *string
+	Priority  *string
+	Tags      *[]string
+	Project   *string
+	Contexts  *[]string
+	Repeat    *string
+	Parent    *int32
+	Notes     *string
+	Status    *string
+	Completed *bool
+}
+
+func (g *gqlRoot) UpdateTodo(ctx context.Context, args struct {
+	ID    int32
+	Patch gqlPatchInput
+	List  *string
+}) (*gqlTodo, error) {
+	l, err := g.open(ctx, args.List, true)
+	if err != nil {
+		return nil, err
+	}
+	in := args.Patch
+	p := Patch{
+		Todo:      in.Todo,
+		Due:       in.Due,
+		Priority:  in.Priority,
+		Tags:      in.Tags,
+		Project:   in.Project,
+		Contexts:  in.Contexts,
+		Repeat:    in.Repeat,
+		Notes:     in.Notes,
+		Status:    in.Status,
+		Completed: in.Completed,
+	}
+	if in.Parent != nil {
+		parent := int(*in.Parent)
+		p.Parent = &parent
+	}
+	item, err := g.s.updateItem(l, int(args.ID), p)
+	if err != nil {
+		return nil, err
+	}
+	return &gqlTodo{item: item, l: l}, nil
+}
+
+func (g *gqlRoot) DeleteTodo(ctx context.Context, args gqlIDArgs) (bool, error) {
+	l, err := g.open(ctx, args.List, true)
+	if err != nil {
+		return false, err
+	}
+	if err := g.s.deleteItem(l, int(args.ID)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (g *gqlRoot) RestoreTodo(ctx context.Context, args gqlIDArgs) (*gqlTodo, error) {
+	l, err := g.open(ctx, args.List, true)
+	if err != nil {
+		return nil, err
+	}
+	item, err := g.s.restoreItem(l, int(args.ID))
+	if err != nil {
+		return nil, err
+	}
+	return &gqlTodo{item: item, l: l}, nil
+}
+
+func (g *gqlRoot) PurgeTrash(ctx context.Context, args struct{ List *string }) (int32, error) {
+	l, err := g.open(ctx, args.List, true)
+	if err != nil {
+		return 0, err
+	}
+	n, err := g.s.purgeTrash(l)
+	return int32(n), err
+}
+
+// gqlTodo resolves the Todo type for an item of l.
+type gqlTodo struct {
+	item todo.ParsedTodoItem
+	l    list
+}
+
+func gqlTodos(l list, items []todo.ParsedTodoItem) []*gqlTodo {
+	out := make([]*gqlTodo, len(items))
+	for i, item := range items {
+		out[i] = &gqlTodo{item: item, l: l}
+	}
+	return out
+}
+
+func (t *gqlTodo) ID() int32            { return int32(t.item.ID) }
+func (t *gqlTodo) Todo() string         { return t.item.Todo }
+func (t *gqlTodo) Due() *string         { return gqlTime(t.item.Due) }
+func (t *gqlTodo) DueAllDay() bool      { return t.item.DueAllDay() }
+func (t *gqlTodo) Overdue() bool        { return t.item.IsOverdue(time.Now()) }
+func (t *gqlTodo) Priority() *string    { return optional(t.item.Priority.String()) }
+func (t *gqlTodo) Tags() []string       { return nonNil(t.item.Tags) }
+func (t *gqlTodo) Project() *string     { return optional(t.item.Project) }
+func (t *gqlTodo) Contexts() []string   { return nonNil(t.item.Contexts) }
+func (t *gqlTodo) Repeat() *string      { return optional(t.item.Repeat) }
+func (t *gqlTodo) Notes() *string       { return optional(t.item.Notes) }
+func (t *gqlTodo) Status() string       { return t.item.CurrentStatus() }
+func (t *gqlTodo) CreatedAt() *string   { return gqlTime(t.item.CreatedAt) }
+func (t *gqlTodo) Completed() bool      { return t.item.Completed }
+func (t *gqlTodo) CompletedAt() *string { return gqlTime(t.item.CompletedAt) }
+
+func (t *gqlTodo) Parent() (*gqlTodo, error) {
+	if t.item.Parent == 0 {
+		return nil, nil
+	}
+	parent, err := t.l.store.Get(t.item.Parent)
+	if err != nil {
+		return nil, err
+	}
+	return &gqlTodo{item: parent, l: t.l}, nil
+}
+
+func (t *gqlTodo) Subtasks() ([]*gqlTodo, error) {
+	items, err := t.l.store.List()
+	if err != nil {
+		return nil, err
+	}
+	items = slices.DeleteFunc(items, func(item todo.ParsedTodoItem) bool { return item.Parent != t.item.ID })
+	return gqlTodos(t.l, items), nil
+}
+
+func (t *gqlTodo) BlockedBy() ([]*gqlTodo, error) {
+	out := []*gqlTodo{}
+	for _, id := range t.item.BlockedBy {
+		item, err := t.l.store.Get(id)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, &gqlTodo{item: item, l: t.l})
+	}
+	return out, nil
+}
+
+// gqlGroup resolves the Group type.
+type gqlGroup struct {
+	key   string
+	todos []*gqlTodo
+}
+
+func (g *gqlGroup) Key() string       { return g.key }
+func (g *gqlGroup) Count() int32      { return int32(len(g.todos)) }
+func (g *gqlGroup) Todos() []*gqlTodo { return g.todos }
+
+// gqlSharedList resolves the SharedList type for user.
+type gqlSharedList struct {
+	list SharedList
+	user string
+}
+
+func (l *gqlSharedList) Name() string { return l.list.Name }
+func (l *gqlSharedList) Role() string { return string(l.list.Role(l.user)) }
+
+func (l *gqlSharedList) Members() []*gqlMember {
+	out := []*gqlMember{}
+	for _, user := range slices.Sorted(maps.Keys(l.list.Members)) {
+		out = append(out, &gqlMember{user: user, role: l.list.Members[user]})
+	}
+	return out
+}
+
+// gqlMember resolves the Member type.
+type gqlMember struct {
+	user string
+	role Role
+}
+
+func (m *gqlMember) User() string { return m.user }
+func (m *gqlMember) Role() string { return string(m.role) }
+
+// gqlTime formats t in RFC 3339, or returns nil for the zero time.
+func gqlTime(t time.Time) *string {
+	if t.IsZero() {
+		return nil
+	}
+	return optional(t.Format(time.RFC3339))
+}
+
+// optional returns nil for an empty string, which GraphQL sends as null.
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
@@ -0,0 +1,295 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/buck06191/todo-app/pkg/server/todopb"
+	"github.com/buck06191/todo-app/pkg/todo"
+)
+
+// GRPC returns a gRPC server offering the TodoService of todopb/todo.proto.
+// It works on the same store as s and takes turns with its HTTP requests,
+// and changes made through either are streamed to WatchTodos calls.
+// Messages are limited to the MaxBody of s.
+func (s *Server) GRPC() *grpc.Server {
+	g := grpc.NewServer(grpc.MaxRecvMsgSize(int(s.limits.MaxBody)))
+	todopb.RegisterTodoServiceServer(g, &grpcService{s: s})
+	return g
+}
+
+// grpcService implements todopb.TodoServiceServer on top of a Server.
+type grpcService struct {
+	todopb.UnimplementedTodoServiceServer
+	s *Server
+}
+
+// call runs fn on the list a call is for, checking its token as for an
+// HTTP request.
+func (g *grpcService) call(ctx context.Context, shared string, write bool, fn func(l list) error) error {
+	g.s.mu.Lock()
+	defer g.s.mu.Unlock()
+
+	user, err := g.s.authenticate(bearer(ctx), write)
+	if err != nil {
+		return grpcError(err)
+	}
+	l, err := g.s.open(user, shared, write)
+	if err != nil {
+		return grpcError(err)
+	}
+	return grpcError(fn(l))
+}
+
+func (g *grpcService) ListTodos(ctx context.Context, req *todopb.ListTodosRequest) (*todopb.ListTodosResponse, error) {
+	var items []todo.ParsedTodoItem
+	err := g.call(ctx, req.List, false, func(l list) (err error) {
+		items, err = g.s.find(l, query{all: req.All, where: req.Where, q: req.Query, sort: req.Sort})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &todopb.ListTodosResponse{Todos: toProtos(items)}, nil
+}
+
+func (g *grpcService) GetTodo(ctx context.Context, req *todopb.GetTodoRequest) (*todopb.Todo, error) {
+	var item todo.ParsedTodoItem
+	err := g.call(ctx, req.List, false, func(l list) (err error) {
+		item, err = l.store.Get(int(req.Id))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toProto(item), nil
+}
+
+func (g *grpcService) AddTodo(ctx context.Context, req *todopb.AddTodoRequest) (*todopb.Todo, error) {
+	in := todo.TodoItem{
+		Todo:     req.Todo,
+		Due:      req.Due,
+		Priority: req.Priority,
+		Tags:     req.Tags,
+		Project:  req.Project,
+		Contexts: req.Contexts,
+		Repeat:   req.Repeat,
+		Parent:   int(req.Parent),
+		Notes:    req.Notes,
+		Remind:   req.Remind,
+		Estimate: req.Estimate,
+	}
+	var item todo.ParsedTodoItem
+	err := g.call(ctx, req.List, true, func(l list) (err error) {
+		item, err = g.s.addItem(l, in)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toProto(item), nil
+}
+
+func (g *grpcService) UpdateTodo(ctx context.Context, req *todopb.UpdateTodoRequest) (*todopb.Todo, error) {
+	p := Patch{
+		Todo:      req.Todo,
+		Due:       req.Due,
+		Priority:  req.Priority,
+		Project:   req.Project,
+		Repeat:    req.Repeat,
+		Remind:    req.Remind,
+		Estimate:  req.Estimate,
+		Notes:     req.Notes,
+		Status:    req.Status,
+		Completed: req.Completed,
+	}
+	if req.Tags != nil {
+		p.Tags = &req.Tags.Values
+	}
+	if req.Contexts != nil {
+		p.Contexts = &req.Contexts.Values
+	}
+	if req.Parent != nil {
+		parent := int(*req.Parent)
+		p.Parent = &parent
+	}
+
+	var item todo.ParsedTodoItem
+	err := g.call(ctx, req.List, true, func(l list) (err error) {
+		item, err = g.s.updateItem(l, int(req.Id), p)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toProto(item), nil
+}
+
+func (g *grpcService) DeleteTodo(ctx context.Context, req *todopb.DeleteTodoRequest) (*todopb.DeleteTodoResponse, error) {
+	err := g.call(ctx, req.List, true, func(l list) error {
+		return g.s.deleteItem(l, int(req.Id))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &todopb.DeleteTodoResponse{}, nil
+}
+
+func (g *grpcService) ListTrash(ctx context.Context, req *todopb.ListTrashRequest) (*todopb.ListTodosResponse, error) {
+	var items []todo.ParsedTodoItem
+	err := g.call(ctx, req.List, false, func(l list) (err error) {
+		items, err = l.store.Trash()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &todopb.ListTodosResponse{Todos: toProtos(items)}, nil
+}
+
+func (g *grpcService) RestoreTodo(ctx context.Context, req *todopb.RestoreTodoRequest) (*todopb.Todo, error) {
+	var item todo.ParsedTodoItem
+	err := g.call(ctx, req.List, true, func(l list) (err error) {
+		item, err = g.s.restoreItem(l, int(req.Id))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toProto(item), nil
+}
+
+func (g *grpcService) PurgeTrash(ctx context.Context, req *todopb.PurgeTrashRequest) (*todopb.PurgeTrashResponse, error) {
+	var n int
+	err := g.call(ctx, req.List, true, func(l list) (err error) {
+		n, err = g.s.purgeTrash(l)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &todopb.PurgeTrashResponse{Purged: int32(n)}, nil
+}
+
+func (g *grpcService) WatchTodos(req *todopb.WatchTodosRequest, stream grpc.ServerStreamingServer[todopb.TodoEvent]) error {
+	var w *watcher
+	err := g.call(stream.Context(), req.List, false, func(l list) error {
+		w = g.s.watch(l)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	defer g.s.unwatch(w)
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case e, ok := <-w.events:
+			if !ok {
+				return status.Error(codes.ResourceExhausted, "fell too far behind the changes to the list")
+			}
+			err := stream.Send(&todopb.TodoEvent{
+				Type: todopb.TodoEvent_Type(e.typ),
+				Todo: toProto(e.item),
+				Time: timestamppb.New(e.time),
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// bearer returns the token sent in a call's authorization metadata.
+func bearer(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, value := range md.Get("authorization") {
+		if token, ok := strings.CutPrefix(value, "Bearer "); ok {
+			return token
+		}
+	}
+	return ""
+}
+
+// grpcCodes maps the HTTP statuses errors are reported with to gRPC codes.
+var grpcCodes = map[int]codes.Code{
+	http.StatusBadRequest:   codes.InvalidArgument,
+	http.StatusUnauthorized: codes.Unauthenticated,
+	http.StatusForbidden:    codes.PermissionDenied,
+	http.StatusNotFound:     codes.NotFound,
+}
+
+// grpcError returns err as a gRPC status error, or nil if it is nil.
+func grpcError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	code, ok := grpcCodes[statusOf(err)]
+	if !ok {
+		code = codes.Internal
+	}
+	return status.Error(code, err.Error())
+}
+
+func toProtos(items []todo.ParsedTodoItem) []*todopb.Todo {
+	out := make([]*todopb.Todo, len(items))
+	for i, item := range items {
+		out[i] = toProto(item)
+	}
+	return out
+}
+
+func toProto(item todo.ParsedTodoItem) *todopb.Todo {
+	blockedBy := make([]int64, len(item.BlockedBy))
+	for i, id := range item.BlockedBy {
+		blockedBy[i] = int64(id)
+	}
+	var worked []*todopb.Interval
+	for _, w := range item.Worked {
+		worked = append(worked, &todopb.Interval{Start: timestamp(w.Start), End: timestamp(w.End)})
+	}
+	return &todopb.Todo{
+		Id:          int64(item.ID),
+		Todo:        item.Todo,
+		Due:         timestamp(item.Due),
+		Priority:    item.Priority.String(),
+		Tags:        item.Tags,
+		Project:     item.Project,
+		Contexts:    item.Contexts,
+		Repeat:      item.Repeat,
+		Parent:      int64(item.Parent),
+		BlockedBy:   blockedBy,
+		Notes:       item.Notes,
+		Status:      item.Status,
+		CreatedAt:   timestamp(item.CreatedAt),
+		Completed:   item.Completed,
+		CompletedAt: timestamp(item.CompletedAt),
+		DeletedAt:   timestamp(item.DeletedAt),
+		Remind:      item.Remind,
+		Snoozed:     int32(item.Snoozed),
+		Worked:      worked,
+		Estimate:    item.Estimate,
+		Inbox:       item.Inbox,
+	}
+}
+
+// timestamp converts t, leaving zero times unset.
+func timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
@@ -0,0 +1,86 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/buck06191/todo-app/pkg/todo"
+)
+
+// healthKey is the meta key written to check the store can be changed. It
+// is deleted again straight away.
+const healthKey = "health"
+
+// Health is the body of /healthz and /readyz: Status is "ok" if every one
+// of Checks passed and "failing" otherwise.
+type Health struct {
+	Status string                 `json:"status"`
+	Checks map[string]HealthCheck `json:"checks"`
+}
+
+// HealthCheck is the outcome of one check of the store.
+type HealthCheck struct {
+	Status string `json:"status"`
+	// Error says what went wrong with a failing check.
+	Error string `json:"error,omitempty"`
+	// Took is how long the check took, in milliseconds.
+	Took float64 `json:"took_ms"`
+}
+
+// checkHealth runs the given checks against the store. s.mu must be held.
+func (s *Server) checkHealth(checks map[string]func(store todo.Store) error) Health {
+	h := Health{Status: "ok", Checks: make(map[string]HealthCheck, len(checks))}
+	for name, check := range checks {
+		start := time.Now()
+		err := check(s.store)
+		c := HealthCheck{Status: "ok", Took: float64(time.Since(start).Microseconds()) / 1000}
+		if err != nil {
+			c.Status, c.Error = "failing", err.Error()
+			h.Status = "failing"
+		}
+		h.Checks[name] = c
+	}
+	return h
+}
+
+// readCheck checks that the items in store can be read.
+func readCheck(store todo.Store) error {
+	_, err := store.List()
+	return err
+}
+
+// writeCheck checks that store can be changed, by saving a meta key and
+// deleting it again.
+func writeCheck(store todo.Store) error {
+	meta, ok := store.(todo.MetaStore)
+	if !ok {
+		return todo.ErrNoMeta
+	}
+	value, err := json.Marshal(time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	if err := meta.PutMeta(healthKey, value); err != nil {
+		return err
+	}
+	return meta.PutMeta(healthKey, nil)
+}
+
+// healthz reports whether the store can be read, for liveness probes.
+func (s *Server) healthz(r *http.Request) (int, any, error) {
+	return healthStatus(s.checkHealth(map[string]func(todo.Store) error{"read": readCheck}))
+}
+
+// readyz reports whether the store can be read and changed, for readiness
+// probes.
+func (s *Server) readyz(r *http.Request) (int, any, error) {
+	return healthStatus(s.checkHealth(map[string]func(todo.Store) error{"read": readCheck, "write": writeCheck}))
+}
+
+func healthStatus(h Health) (int, any, error) {
+	if h.Status != "ok" {
+		return http.StatusServiceUnavailable, h, nil
+	}
+	return http.StatusOK, h, nil
+}
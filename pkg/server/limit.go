@@ -0,0 +1,154 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Limits keep a server other people can reach from being flooded with
+// requests, or used to fill up the store.
+type Limits struct {
+	// IPRate is how many requests a second each address may make on
+	// average, and TokenRate how many may be made with each API token
+	// from any address. Requests without a token the server knows count
+	// against TokenRate by address instead, so that making up tokens
+	// doesn't get around it. Zero turns a limit off.
+	IPRate, TokenRate float64
+	// Burst is how many requests may be made at once before the rates
+	// apply.
+	Burst int
+	// MaxBody is the largest request body that is read, in bytes.
+	MaxBody int64
+}
+
+// DefaultLimits are the limits of a new Server: requests aren't limited,
+// and bodies can be up to 1 MiB.
+var DefaultLimits = Limits{Burst: 20, MaxBody: 1 << 20}
+
+// SetLimits changes the limits of s. It has to be called before s starts
+// serving.
+func (s *Server) SetLimits(l Limits) {
+	s.limits = l
+	s.ipLimiter = newRateLimiter(l.IPRate, l.Burst)
+	s.tokenLimiter = newRateLimiter(l.TokenRate, l.Burst)
+}
+
+// unlimited are the paths that aren't rate limited, so that probes keep
+// working while a server is being flooded.
+var unlimited = map[string]bool{"/healthz": true, "/readyz": true}
+
+// limit checks r against the rate limits, returning a requestError with
+// status 429 and a Retry-After header set on w if it is over one.
+func (s *Server) limit(w http.ResponseWriter, r *http.Request) error {
+	if unlimited[r.URL.Path] {
+		return nil
+	}
+	now := time.Now()
+	addr, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		addr = r.RemoteAddr
+	}
+	wait := s.ipLimiter.wait(addr, now)
+	if wait == 0 && s.tokenLimiter != nil {
+		wait = s.tokenLimiter.wait(s.sender(r, addr), now)
+	}
+	if wait == 0 {
+		return nil
+	}
+
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	return &requestError{status: http.StatusTooManyRequests, err: fmt.Errorf("too many requests, try again in %v", wait.Round(time.Second))}
+}
+
+// sender returns the key the token limiter tells the sender of r apart
+// by: the hash of the token r was made with once it is known to be one,
+// and otherwise addr.
+func (s *Server) sender(r *http.Request, addr string) string {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return "addr " + addr
+	}
+	s.mu.Lock()
+	tokens, err := s.allTokens()
+	s.mu.Unlock()
+	if t, found := findToken(tokens, token); err == nil && found {
+		return "token " + t.Hash
+	}
+	return "addr " + addr
+}
+
+// sweepEvery is how often a rateLimiter forgets the senders it hasn't
+// heard from in a while.
+const sweepEvery = time.Minute
+
+// rateLimiter limits how often each sender of requests, told apart by a
+// key, can make them. A nil rateLimiter lets everything through.
+type rateLimiter struct {
+	rate  rate.Limit
+	burst int
+
+	mu      sync.Mutex
+	senders map[string]*sender
+	swept   time.Time
+}
+
+type sender struct {
+	limiter *rate.Limiter
+	last    time.Time
+}
+
+func newRateLimiter(perSecond float64, burst int) *rateLimiter {
+	if perSecond <= 0 {
+		return nil
+	}
+	return &rateLimiter{rate: rate.Limit(perSecond), burst: max(burst, 1), senders: make(map[string]*sender)}
+}
+
+// wait returns how long the sender with the given key has to wait before
+// its next request is allowed, or 0 if it is allowed now, in which case it
+// is counted against the limit.
+func (l *rateLimiter) wait(key string, now time.Time) time.Duration {
+	if l == nil {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.swept) > sweepEvery {
+		l.sweep(now)
+	}
+	s, ok := l.senders[key]
+	if !ok {
+		s = &sender{limiter: rate.NewLimiter(l.rate, l.burst)}
+		l.senders[key] = s
+	}
+	s.last = now
+
+	res := s.limiter.ReserveN(now, 1)
+	if delay := res.DelayFrom(now); delay > 0 {
+		res.CancelAt(now)
+		return delay
+	}
+	return 0
+}
+
+// sweep forgets the senders that have been quiet long enough for their
+// whole burst to be allowed again, since they are then no different from
+// new ones.
+func (l *rateLimiter) sweep(now time.Time) {
+	refill := time.Duration(float64(l.burst) / float64(l.rate) * float64(time.Second))
+	for key, s := range l.senders {
+		if now.Sub(s.last) > refill {
+			delete(l.senders, key)
+		}
+	}
+	l.swept = now
+}
@@ -0,0 +1,172 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// metrics are the Prometheus metrics of a Server. The counters only see
+// changes made through the server since it started, while the item counts
+// are read from the store on every scrape and so include changes made with
+// the CLI too.
+type metrics struct {
+	registry  *prometheus.Registry
+	added     *prometheus.CounterVec
+	completed *prometheus.CounterVec
+	deleted   *prometheus.CounterVec
+	duration  *prometheus.HistogramVec
+}
+
+func newMetrics(s *Server) *metrics {
+	m := &metrics{
+		registry: prometheus.NewRegistry(),
+		added: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "todo_items_added_total",
+			Help: "Items added through the server, including the next occurrences of repeating items.",
+		}, []string{"list"}),
+		completed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "todo_items_completed_total",
+			Help: "Items marked as done through the server.",
+		}, []string{"list"}),
+		deleted: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "todo_items_deleted_total",
+			Help: "Items moved to the trash through the server.",
+		}, []string{"list"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "todo_http_request_duration_seconds",
+			Help:    "How long HTTP requests took to answer, by route and status code.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"route", "code"}),
+	}
+	m.registry.MustRegister(
+		m.added, m.completed, m.deleted, m.duration,
+		itemCounts{s},
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return m
+}
+
+var (
+	openDesc    = prometheus.NewDesc("todo_items_open", "Items that aren't done yet.", []string{"list"}, nil)
+	overdueDesc = prometheus.NewDesc("todo_items_overdue", "Items that aren't done and are past their due date.", []string{"list"}, nil)
+)
+
+// itemCounts collects how many items are open and overdue on every list
+// in the store. Lists are labelled with the namespace they are kept in,
+// such as users/alice or shared/household, and the store's own list with
+// an empty name.
+type itemCounts struct {
+	s *Server
+}
+
+func (c itemCounts) Describe(ch chan<- *prometheus.Desc) {
+	ch <- openDesc
+	ch <- overdueDesc
+}
+
+func (c itemCounts) Collect(ch chan<- prometheus.Metric) {
+	c.s.mu.Lock()
+	defer c.s.mu.Unlock()
+
+	lists, err := c.s.lists()
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(openDesc, err)
+		return
+	}
+	now := time.Now()
+	for _, l := range lists {
+		items, err := l.store.List()
+		if err != nil {
+			ch <- prometheus.NewInvalidMetric(openDesc, err)
+			continue
+		}
+		var open, overdue int
+		for _, item := range items {
+			if item.Completed {
+				continue
+			}
+			open++
+			if item.IsOverdue(now) {
+				overdue++
+			}
+		}
+		ch <- prometheus.MustNewConstMetric(openDesc, prometheus.GaugeValue, float64(open), l.name)
+		ch <- prometheus.MustNewConstMetric(overdueDesc, prometheus.GaugeValue, float64(overdue), l.name)
+	}
+}
+
+// lists returns every list in the store: its own, each user's and each
+// shared one. s.mu must be held.
+func (s *Server) lists() ([]list, error) {
+	lists := []list{{store: s.store}}
+	users, err := Users(s.store)
+	if err != nil {
+		return nil, err
+	}
+	for _, u := range users {
+		store, err := UserStore(s.store, u.Name)
+		if err != nil {
+			return nil, err
+		}
+		lists = append(lists, list{store: store, name: userPrefix + u.Name})
+	}
+	shared, err := SharedLists(s.store)
+	if err != nil {
+		return nil, err
+	}
+	for _, sl := range shared {
+		store, _, err := SharedStore(s.store, sl.Name, "")
+		if err != nil {
+			return nil, err
+		}
+		lists = append(lists, list{store: store, name: sharePrefix + sl.Name})
+	}
+	return lists, nil
+}
+
+// serveMetrics serves the metrics in the Prometheus text format. Since
+// they cover every list, they need a read token without a user once
+// tokens have been created.
+func (s *Server) serveMetrics(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	user, err := s.authorize(r)
+	s.mu.Unlock()
+	if err == nil && user != "" {
+		err = &requestError{status: http.StatusForbidden, err: fmt.Errorf("%s's token can't see the metrics of every list", user)}
+	}
+	if err != nil {
+		reply(w, 0, nil, err)
+		return
+	}
+	promhttp.HandlerFor(s.metrics.registry, promhttp.HandlerOpts{ErrorHandling: promhttp.ContinueOnError}).ServeHTTP(w, r)
+}
+
+// statusRecorder remembers the status code a response was sent with.
+type statusRecorder struct {
+	http.ResponseWriter
+	code int
+}
+
+func (w *statusRecorder) WriteHeader(code int) {
+	w.code = code
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusRecorder) Unwrap() http.ResponseWriter { return w.ResponseWriter }
+
+// observe records how long the request r took, by the pattern of the route
+// it matched, such as "GET /todos/{id}".
+func (m *metrics) observe(r *http.Request, code int, took time.Duration) {
+	route := r.Pattern
+	if route == "" {
+		route = "unmatched"
+	}
+	m.duration.WithLabelValues(route, strconv.Itoa(code)).Observe(took.Seconds())
+}
@@ -0,0 +1,295 @@
+package server
+
+import (
+	"encoding"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/buck06191/todo-app/pkg/todo"
+	"github.com/buck06191/todo-app/pkg/todosync"
+)
+
+// route is an endpoint of the JSON API. The same table is used to serve
+// the routes and to describe them in the OpenAPI document, so the two
+// can't drift apart.
+type route struct {
+	method string
+	path   string
+	// id names the operation in the OpenAPI document, and so the method
+	// of generated clients.
+	id      string
+	summary string
+	serve   http.HandlerFunc
+	// query lists the query parameters the route takes.
+	query []param
+	// body is a value of the type the request body is read into, or nil.
+	body any
+	// status is what a successful request returns, with a value of the
+	// type of its body in result, or nil for none.
+	status int
+	result any
+	// fails is the status sent, also with a result, when the request
+	// worked but found something wrong, or 0.
+	fails int
+	// public routes don't need a token.
+	public bool
+}
+
+// param is a query parameter.
+type param struct {
+	name, typ, doc string
+}
+
+func (s *Server) routes() []route {
+	var item todo.ParsedTodoItem
+	var items []todo.ParsedTodoItem
+	var lists []SharedList
+
+	var routes []route
+	for _, prefix := range []string{"", "/lists/{list}"} {
+		suffix, where := "", "the list"
+		if prefix != "" {
+			suffix, where = "InList", "a shared list"
+		}
+		routes = append(routes,
+			route{method: "GET", path: prefix + "/todos", id: "listTodos" + suffix, summary: "Get the items on " + where, serve: s.handle(s.list),
+				query: []param{
+					{"all", "boolean", "Include done items."},
+					{"where", "string", "Only return items matching this filter expression, as taken by -where."},
+					{"q", "string", "Only return items with words starting with each of these."},
+					{"sort", "string", "Sort by these keys, as taken by -sort, rather than by due date."},
+				},
+				status: http.StatusOK, result: items},
+			route{method: "POST", path: prefix + "/todos", id: "addTodo" + suffix, summary: "Add an item to " + where, serve: s.handle(s.add),
+				body: todo.TodoItem{}, status: http.StatusCreated, result: item},
+			route{method: "GET", path: prefix + "/todos/{id}", id: "getTodo" + suffix, summary: "Get an item on " + where, serve: s.handle(s.get),
+				status: http.StatusOK, result: item},
+			route{method: "PATCH", path: prefix + "/todos/{id}", id: "updateTodo" + suffix, summary: "Change the fields given of an item on " + where, serve: s.handle(s.patch),
+				body: Patch{}, status: http.StatusOK, result: item},
+			route{method: "DELETE", path: prefix + "/todos/{id}", id: "deleteTodo" + suffix, summary: "Move an item on " + where + " to the trash", serve: s.handle(s.delete),
+				status: http.StatusNoContent},
+			route{method: "GET", path: prefix + "/trash", id: "listTrash" + suffix, summary: "Get the trash of " + where, serve: s.handle(s.trash),
+				status: http.StatusOK, result: items},
+			route{method: "POST", path: prefix + "/trash/{id}/restore", id: "restoreTodo" + suffix, summary: "Move an item back out of the trash of " + where, serve: s.handle(s.restore),
+				status: http.StatusOK, result: item},
+			route{method: "DELETE", path: prefix + "/trash", id: "purgeTrash" + suffix, summary: "Empty the trash of " + where + " for good", serve: s.handle(s.purge),
+				status: http.StatusOK, result: purgeResult{}},
+			route{method: "GET", path: prefix + "/sync", id: "pullSync" + suffix, summary: "Get the changes made to " + where + " since a revision, for syncing a device", serve: s.handle(s.pullSync),
+				query:  []param{{"since", "integer", "The revision the device last synced at, or 0 for everything."}},
+				status: http.StatusOK, result: todosync.Pull{}},
+			route{method: "POST", path: prefix + "/sync", id: "pushSync" + suffix, summary: "Make the changes a device has made to " + where + " since it last pulled", serve: s.handle(s.pushSync),
+				body: todosync.Push{}, status: http.StatusOK, result: todosync.Pushed{}},
+		)
+	}
+	return append(routes,
+		route{method: "GET", path: "/lists", id: "listSharedLists", summary: "Get the shared lists the token's user is in", serve: s.handleUser(s.sharedLists),
+			status: http.StatusOK, result: lists},
+		route{method: "POST", path: "/lists", id: "createSharedList", summary: "Create a shared list owned by the token's user", serve: s.handleUser(s.createList),
+			body: newSharedList{}, status: http.StatusCreated, result: SharedList{}},
+		route{method: "DELETE", path: "/lists/{list}", id: "deleteSharedList", summary: "Delete a shared list and everything in it", serve: s.handleUser(s.deleteList),
+			status: http.StatusNoContent},
+		route{method: "PUT", path: "/lists/{list}/members/{user}", id: "shareList", summary: "Give a user a role in a shared list", serve: s.handleUser(s.share),
+			body: memberRole{}, status: http.StatusOK, result: SharedList{}},
+		route{method: "DELETE", path: "/lists/{list}/members/{user}", id: "unshareList", summary: "Take a user out of a shared list", serve: s.handleUser(s.unshare),
+			status: http.StatusNoContent},
+		route{method: "GET", path: "/healthz", id: "healthz", summary: "Check the store can be read, for liveness probes", serve: s.respond(s.healthz),
+			status: http.StatusOK, result: Health{}, fails: http.StatusServiceUnavailable, public: true},
+		route{method: "GET", path: "/readyz", id: "readyz", summary: "Check the store can be read and changed, for readiness probes", serve: s.respond(s.readyz),
+			status: http.StatusOK, result: Health{}, fails: http.StatusServiceUnavailable, public: true},
+	)
+}
+
+// pathParam matches the parameters in a route's path.
+var pathParam = regexp.MustCompile(`\{(\w+)\}`)
+
+// OpenAPI returns an OpenAPI 3 document describing the JSON API, as JSON.
+func OpenAPI() ([]byte, error) {
+	gen := schemaGen{schemas: map[string]any{}}
+	errorResponse := map[string]any{
+		"description": "An error, with a status code to match.",
+		"content":     jsonContent(gen.schema(reflect.TypeOf(errorBody{}))),
+	}
+
+	paths := map[string]map[string]any{}
+	for _, rt := range new(Server).routes() {
+		var params []any
+		for _, name := range pathParam.FindAllStringSubmatch(rt.path, -1) {
+			typ := "string"
+			if name[1] == "id" {
+				typ = "integer"
+			}
+			params = append(params, map[string]any{
+				"name": name[1], "in": "path", "required": true,
+				"schema": map[string]any{"type": typ},
+			})
+		}
+		for _, q := range rt.query {
+			params = append(params, map[string]any{
+				"name": q.name, "in": "query", "description": q.doc,
+				"schema": map[string]any{"type": q.typ},
+			})
+		}
+
+		responses := map[string]any{"default": errorResponse}
+		success := map[string]any{"description": http.StatusText(rt.status)}
+		if rt.result != nil {
+			success["content"] = jsonContent(gen.schema(reflect.TypeOf(rt.result)))
+		}
+		responses[strconv.Itoa(rt.status)] = success
+		if rt.fails != 0 {
+			responses[strconv.Itoa(rt.fails)] = map[string]any{
+				"description": http.StatusText(rt.fails),
+				"content":     jsonContent(gen.schema(reflect.TypeOf(rt.result))),
+			}
+		}
+		op := map[string]any{
+			"operationId": rt.id,
+			"summary":     rt.summary,
+			"responses":   responses,
+		}
+		if rt.public {
+			op["security"] = []any{}
+		}
+		if params != nil {
+			op["parameters"] = params
+		}
+		if rt.body != nil {
+			op["requestBody"] = map[string]any{
+				"required": true,
+				"content":  jsonContent(gen.schema(reflect.TypeOf(rt.body))),
+			}
+		}
+
+		if paths[rt.path] == nil {
+			paths[rt.path] = map[string]any{}
+		}
+		paths[rt.path][strings.ToLower(rt.method)] = op
+	}
+
+	doc := map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":       "todo-app",
+			"description": "The JSON API served by `todo-app serve`.",
+			"version":     "1",
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": gen.schemas,
+			"securitySchemes": map[string]any{
+				"token": map[string]any{"type": "http", "scheme": "bearer"},
+			},
+		},
+		"security": []any{map[string]any{"token": []any{}}},
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+func jsonContent(schema map[string]any) map[string]any {
+	return map[string]any{"application/json": map[string]any{"schema": schema}}
+}
+
+// schemaNames are the names types are given in the document, where their
+// Go names would be unclear.
+var schemaNames = map[reflect.Type]string{
+	reflect.TypeOf(todo.ParsedTodoItem{}): "Todo",
+	reflect.TypeOf(todo.TodoItem{}):       "NewTodo",
+	reflect.TypeOf(errorBody{}):           "Error",
+	reflect.TypeOf(purgeResult{}):         "PurgeResult",
+	reflect.TypeOf(newSharedList{}):       "NewSharedList",
+	reflect.TypeOf(memberRole{}):          "MemberRole",
+}
+
+// schemaEnums are the values of string types with a fixed set of them.
+var schemaEnums = map[reflect.Type][]string{
+	reflect.TypeOf(todo.Priority(0)): {"low", "medium", "high"},
+	reflect.TypeOf(Role("")):         {string(RoleOwner), string(RoleEditor), string(RoleViewer)},
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// schemaGen works out JSON schemas for Go types, collecting the ones for
+// structs in schemas so they can be referred to by name.
+type schemaGen struct {
+	schemas map[string]any
+}
+
+func (g *schemaGen) schema(t reflect.Type) map[string]any {
+	if enum, ok := schemaEnums[t]; ok {
+		g.schemas[t.Name()] = map[string]any{"type": "string", "enum": enum}
+		return map[string]any{"$ref": "#/components/schemas/" + t.Name()}
+	}
+	if t == timeType {
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+	if t.Implements(textMarshalerType) {
+		return map[string]any{"type": "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		schema := g.schema(t.Elem())
+		if _, ok := schema["$ref"]; ok {
+			// Nothing can go alongside a $ref.
+			schema = map[string]any{"allOf": []any{schema}}
+		}
+		schema["nullable"] = true
+		return schema
+	case reflect.Slice:
+		return map[string]any{"type": "array", "items": g.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": g.schema(t.Elem())}
+	case reflect.Struct:
+		name, ok := schemaNames[t]
+		if !ok {
+			name = t.Name()
+		}
+		if _, seen := g.schemas[name]; !seen {
+			g.schemas[name] = nil
+			g.schemas[name] = g.object(t)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + name}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	}
+	return map[string]any{"type": "string"}
+}
+
+// object returns the schema of a struct from its JSON field tags. Fields
+// that can be left out are the ones with omitempty or omitzero, and
+// pointers.
+func (g *schemaGen) object(t reflect.Type) map[string]any {
+	props := map[string]any{}
+	var required []string
+	for i := range t.NumField() {
+		f := t.Field(i)
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if !f.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		props[name] = g.schema(f.Type)
+		if !strings.Contains(opts, "omit") && f.Type.Kind() != reflect.Pointer {
+			required = append(required, name)
+		}
+	}
+	schema := map[string]any{"type": "object", "properties": props}
+	if required != nil {
+		schema["required"] = required
+	}
+	return schema
+}
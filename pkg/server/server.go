@@ -0,0 +1,662 @@
+// Package server serves a todo.Store over HTTP as a JSON API, for scripts
+// and phone shortcuts to use, and over gRPC for other services; see
+// Server.GRPC and todopb/todo.proto. It is what `todo-app serve` runs.
+//
+// Items are sent and received as JSON. New items are posted in the same
+// form `todo-app add -json` reads, a todo.TodoItem, and everything else is
+// returned as todo.ParsedTodoItem:
+//
+//	GET    /todos               the list; ?all=1 includes done items,
+//	                            ?where= filters it, ?q= searches it and
+//	                            ?sort= orders it, as for the CLI
+//	POST   /todos               add an item
+//	GET    /todos/{id}          one item
+//	PATCH  /todos/{id}          change the fields given, see Patch
+//	DELETE /todos/{id}          move an item to the trash
+//	GET    /trash               the trash
+//	POST   /trash/{id}/restore  move an item back out of the trash
+//	DELETE /trash               empty the trash for good
+//
+// Errors are returned with a matching status code and a body of the form
+// {"error": "..."}.
+//
+// Once a Token has been created for the store, every API request needs one
+// with the right scope, sent as "Authorization: Bearer <token>". Several
+// people can share a server by adding a User for each of them: the API
+// works the same way, but requests made with a user's token only see and
+// change that user's own list.
+//
+// A SharedList is used by several users, each with a Role. Its items are
+// reached by putting /lists/{list} in front of the paths above, as in
+// GET /lists/household/todos, and viewers may only make GET requests.
+// Shared lists are managed with:
+//
+//	GET    /lists                       the shared lists the user is in
+//	POST   /lists                       create one, {"name": "household"},
+//	                                    with the user as its owner
+//	DELETE /lists/{list}                delete one and everything in it
+//	PUT    /lists/{list}/members/{user} give a user a role, {"role": "editor"}
+//	DELETE /lists/{list}/members/{user} take a user out
+//
+// Only owners can change the members, apart from users leaving a list.
+//
+// GET /sync?since={rev} and POST /sync, under either kind of path, are
+// for syncing devices with the list; see the todosync package.
+//
+// With EnableGraphQL, POST /graphql also takes GraphQL queries against the
+// schema in schema.graphql, for clients that want to pick the fields and
+// groupings they get back.
+//
+// With EnableSlack, POST /slack/command answers a Slack slash command, so
+// that a team can add items to a list and look at it from Slack.
+//
+// OpenAPI describes the JSON API as an OpenAPI 3 document, made from the
+// same table of routes the server is built from.
+//
+// GET /metrics serves Prometheus metrics: how many items are open and
+// overdue on each list, how many have been added, completed and deleted
+// through the server, and how long requests take.
+//
+// GET /healthz and GET /readyz check that the store can be read, and for
+// readiness also written to, answering with a Health and a 503 status if
+// it can't. They are the only routes that never need a token.
+//
+// SetLimits rate limits requests by address and by token, answering with
+// status 429 when they are over, and limits how big request bodies can be.
+//
+// Everything else is a small web page, served from the binary, for adding,
+// listing and completing items from a browser.
+package server
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/buck06191/todo-app/pkg/todo"
+	"github.com/buck06191/todo-app/pkg/todosync"
+)
+
+//go:embed web
+var web embed.FS
+
+// Server is an http.Handler serving the API for a store.
+type Server struct {
+	store todo.Store
+	mux   *http.ServeMux
+
+	// mu makes requests take turns with the store, since not every
+	// backend can be used from several goroutines at once. It also
+	// guards watchers.
+	mu       sync.Mutex
+	watchers map[*watcher]struct{}
+
+	metrics *metrics
+
+	limits       Limits
+	ipLimiter    *rateLimiter
+	tokenLimiter *rateLimiter
+
+	// tokens are those given to AllowTokens, which aren't in the store.
+	tokens []Token
+}
+
+// New returns a Server for store. Closing the store is left to the caller.
+func New(store todo.Store) *Server {
+	s := &Server{store: store, mux: http.NewServeMux(), watchers: make(map[*watcher]struct{})}
+	s.metrics = newMetrics(s)
+	s.SetLimits(DefaultLimits)
+	for _, rt := range s.routes() {
+		s.mux.HandleFunc(rt.method+" "+rt.path, rt.serve)
+	}
+	s.mux.HandleFunc("GET /metrics", s.serveMetrics)
+
+	files, err := fs.Sub(web, "web")
+	if err != nil {
+		panic(err)
+	}
+	s.mux.Handle("GET /", http.FileServerFS(files))
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	rec := &statusRecorder{ResponseWriter: w, code: http.StatusOK}
+	if err := s.limit(rec, r); err != nil {
+		reply(rec, 0, nil, err)
+	} else {
+		r.Body = http.MaxBytesReader(rec, r.Body, s.limits.MaxBody)
+		s.mux.ServeHTTP(rec, r)
+	}
+	s.metrics.observe(r, rec.code, time.Since(start))
+}
+
+// Patch is the body of a PATCH request. Only the fields that are present
+// are changed: null or missing leaves a field as it is, and an empty value
+// clears it. Dates, priorities, repeat rules, reminder times, estimates and
+// statuses are read in the same forms as on the command line. Setting
+// status or completed to done adds the next occurrence of a repeating item,
+// as `todo-app done` does.
+type Patch struct {
+	Todo      *string   `json:"todo"`
+	Due       *string   `json:"due"`
+	Priority  *string   `json:"priority"`
+	Tags      *[]string `json:"tags"`
+	Project   *string   `json:"project"`
+	Contexts  *[]string `json:"contexts"`
+	Repeat    *string   `json:"repeat"`
+	Remind    *string   `json:"remind"`
+	Estimate  *string   `json:"estimate"`
+	Parent    *int      `json:"parent"`
+	Notes     *string   `json:"notes"`
+	Status    *string   `json:"status"`
+	Completed *bool     `json:"completed"`
+}
+
+// requestError is an error caused by the request rather than the store,
+// reported with status.
+type requestError struct {
+	status int
+	err    error
+}
+
+func (e *requestError) Error() string { return e.err.Error() }
+func (e *requestError) Unwrap() error { return e.err }
+
+// errorBody is the body errors are sent back in.
+type errorBody struct {
+	Error string `json:"error"`
+}
+
+// purgeResult is the body sent back when the trash is emptied.
+type purgeResult struct {
+	Purged int `json:"purged"`
+}
+
+func badRequest(err error) error {
+	return &requestError{status: http.StatusBadRequest, err: err}
+}
+
+// handler handles one route on the list the request is for, returning the
+// status and value to send back as JSON, or an error. A nil value sends no
+// body.
+type handler func(l list, r *http.Request) (int, any, error)
+
+// userHandler handles a route about shared lists themselves rather than
+// their items, for the user the request is from.
+type userHandler func(user string, r *http.Request) (int, any, error)
+
+// list is the list a request is for.
+type list struct {
+	store todo.Store
+	// name is the namespace the list is kept in, used to tell the changes
+	// to each list apart.
+	name string
+}
+
+func (s *Server) handle(h handler) http.HandlerFunc {
+	return s.respond(func(r *http.Request) (int, any, error) {
+		user, err := s.authorize(r)
+		if err != nil {
+			return 0, nil, err
+		}
+		l, err := s.open(user, r.PathValue("list"), !readOnly(r))
+		if err != nil {
+			return 0, nil, err
+		}
+		return h(l, r)
+	})
+}
+
+func (s *Server) handleUser(h userHandler) http.HandlerFunc {
+	return s.respond(func(r *http.Request) (int, any, error) {
+		user, err := s.authorize(r)
+		if err != nil {
+			return 0, nil, err
+		}
+		return h(user, r)
+	})
+}
+
+// open returns the list a request made by user is for: the shared list
+// called shared if that isn't empty, and otherwise the user's own list, or
+// the store itself for whoever manages it. write is set for requests that
+// change the list.
+func (s *Server) open(user, shared string, write bool) (list, error) {
+	if shared == "" {
+		if user == "" {
+			return list{store: s.store}, nil
+		}
+		store, err := UserStore(s.store, user)
+		return list{store: store, name: userPrefix + user}, err
+	}
+
+	store, role, err := SharedStore(s.store, shared, user)
+	if err != nil {
+		return list{}, err
+	}
+	if write && !role.CanWrite() {
+		return list{}, fmt.Errorf("%w: %s is a %s of %s and can't change it", ErrNotPermitted, user, role, shared)
+	}
+	return list{store: store, name: sharePrefix + shared}, nil
+}
+
+// readOnly reports whether r only looks at things.
+func readOnly(r *http.Request) bool {
+	return r.Method == http.MethodGet || r.Method == http.MethodHead
+}
+
+// statusOf returns the HTTP status an error is reported with.
+func statusOf(err error) int {
+	var reqErr *requestError
+	switch {
+	case errors.As(err, &reqErr):
+		return reqErr.status
+	case errors.Is(err, todo.ErrNotFound), errors.Is(err, ErrNoShare), errors.Is(err, ErrNoUser):
+		return http.StatusNotFound
+	case errors.Is(err, ErrNotMember), errors.Is(err, ErrNotPermitted):
+		return http.StatusForbidden
+	case errors.Is(err, ErrBadShare), errors.Is(err, ErrBadUser), errors.Is(err, todosync.ErrBadChange):
+		return http.StatusBadRequest
+	case errors.Is(err, todosync.ErrStale):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+// respond runs h, keeping the store to it until it returns, and writes
+// back what it returns.
+func (s *Server) respond(h func(r *http.Request) (int, any, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		code, value, err := h(r)
+		s.mu.Unlock()
+		reply(w, code, value, err)
+	}
+}
+
+// reply writes back value as JSON with the status code, or err if it isn't
+// nil. A nil value sends no body.
+func reply(w http.ResponseWriter, code int, value any, err error) {
+	if err != nil {
+		code = statusOf(err)
+		value = errorBody{Error: err.Error()}
+		if code == http.StatusUnauthorized {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+		}
+	}
+
+	if value == nil {
+		w.WriteHeader(code)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(value)
+}
+
+// query picks the items to list.
+type query struct {
+	all   bool
+	where string
+	q     string
+	sort  string
+}
+
+// find returns the items of l matching q, sorted.
+func (s *Server) find(l list, q query) ([]todo.ParsedTodoItem, error) {
+	var where *todo.Filter
+	if q.where != "" {
+		var err error
+		if where, err = todo.ParseFilter(q.where); err != nil {
+			return nil, badRequest(err)
+		}
+	}
+	var order todo.Comparator
+	if q.sort != "" {
+		var err error
+		if order, err = todo.ParseSort(q.sort); err != nil {
+			return nil, badRequest(err)
+		}
+	}
+
+	var found []todo.ParsedTodoItem
+	var err error
+	if q.q != "" {
+		found, err = todo.Search(l.store, q.q)
+	} else {
+		found, err = l.store.List()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	items := []todo.ParsedTodoItem{}
+	for _, item := range found {
+		// A filter decides for itself whether done items match, as with
+		// -where.
+		if where != nil {
+			if !where.Match(item, now) {
+				continue
+			}
+		} else if item.Completed && !q.all {
+			continue
+		}
+		items = append(items, item)
+	}
+	if order != nil {
+		todo.SortBy(items, order)
+	} else {
+		todo.SortByDue(items)
+	}
+	return items, nil
+}
+
+// addItem adds a new item to l.
+func (s *Server) addItem(l list, in todo.TodoItem) (todo.ParsedTodoItem, error) {
+	item, err := todo.ParseItem(in)
+	if err != nil {
+		return item, badRequest(err)
+	}
+	if item.Parent != 0 {
+		saved, err := l.store.List()
+		if err != nil {
+			return item, err
+		}
+		if err := todo.CheckParent(saved, 0, item.Parent); err != nil {
+			return item, badRequest(err)
+		}
+	}
+
+	item, err = l.store.Add(item)
+	if err != nil {
+		return item, err
+	}
+	s.metrics.added.WithLabelValues(l.name).Inc()
+	s.publish(l, eventAdded, item)
+	return item, nil
+}
+
+// updateItem changes the item of l with the given ID as p asks.
+func (s *Server) updateItem(l list, id int, p Patch) (todo.ParsedTodoItem, error) {
+	item, err := l.store.Get(id)
+	if err != nil {
+		return item, err
+	}
+	done, err := apply(l.store, &item, p)
+	if err != nil {
+		return item, err
+	}
+	if err := l.store.Update(item); err != nil {
+		return item, err
+	}
+	s.publish(l, eventUpdated, item)
+
+	if done {
+		s.metrics.completed.WithLabelValues(l.name).Inc()
+		if next, ok := item.NextOccurrence(time.Now()); ok {
+			next, err := l.store.Add(next)
+			if err != nil {
+				return item, err
+			}
+			s.metrics.added.WithLabelValues(l.name).Inc()
+			s.publish(l, eventAdded, next)
+		}
+	}
+	return item, nil
+}
+
+// deleteItem moves the item of l with the given ID to the trash.
+func (s *Server) deleteItem(l list, id int) error {
+	item, err := l.store.Get(id)
+	if err != nil {
+		return err
+	}
+	if err := l.store.Delete(id); err != nil {
+		return err
+	}
+	item.DeletedAt = time.Now()
+	s.metrics.deleted.WithLabelValues(l.name).Inc()
+	s.publish(l, eventDeleted, item)
+	return nil
+}
+
+// restoreItem moves the item of l with the given ID out of the trash.
+func (s *Server) restoreItem(l list, id int) (todo.ParsedTodoItem, error) {
+	if err := l.store.Restore(id); err != nil {
+		return todo.ParsedTodoItem{}, err
+	}
+	item, err := l.store.Get(id)
+	if err != nil {
+		return item, err
+	}
+	s.publish(l, eventRestored, item)
+	return item, nil
+}
+
+// purgeTrash empties the trash of l, returning how many items were in it.
+func (s *Server) purgeTrash(l list) (int, error) {
+	trash, err := l.store.Trash()
+	if err != nil {
+		return 0, err
+	}
+	n, err := l.store.Purge()
+	if err != nil {
+		return 0, err
+	}
+	for _, item := range trash {
+		s.publish(l, eventPurged, item)
+	}
+	return n, nil
+}
+
+func (s *Server) list(l list, r *http.Request) (int, any, error) {
+	params := r.URL.Query()
+	all, _ := strconv.ParseBool(params.Get("all"))
+	items, err := s.find(l, query{all: all, where: params.Get("where"), q: params.Get("q"), sort: params.Get("sort")})
+	if err != nil {
+		return 0, nil, err
+	}
+	return http.StatusOK, items, nil
+}
+
+func (s *Server) add(l list, r *http.Request) (int, any, error) {
+	var in todo.TodoItem
+	if err := decode(r, &in); err != nil {
+		return 0, nil, err
+	}
+	item, err := s.addItem(l, in)
+	if err != nil {
+		return 0, nil, err
+	}
+	return http.StatusCreated, item, nil
+}
+
+func (s *Server) get(l list, r *http.Request) (int, any, error) {
+	id, err := pathID(r)
+	if err != nil {
+		return 0, nil, err
+	}
+	item, err := l.store.Get(id)
+	if err != nil {
+		return 0, nil, err
+	}
+	return http.StatusOK, item, nil
+}
+
+func (s *Server) patch(l list, r *http.Request) (int, any, error) {
+	id, err := pathID(r)
+	if err != nil {
+		return 0, nil, err
+	}
+	var p Patch
+	if err := decode(r, &p); err != nil {
+		return 0, nil, err
+	}
+	item, err := s.updateItem(l, id, p)
+	if err != nil {
+		return 0, nil, err
+	}
+	return http.StatusOK, item, nil
+}
+
+func (s *Server) delete(l list, r *http.Request) (int, any, error) {
+	id, err := pathID(r)
+	if err != nil {
+		return 0, nil, err
+	}
+	if err := s.deleteItem(l, id); err != nil {
+		return 0, nil, err
+	}
+	return http.StatusNoContent, nil, nil
+}
+
+func (s *Server) trash(l list, r *http.Request) (int, any, error) {
+	items, err := l.store.Trash()
+	if err != nil {
+		return 0, nil, err
+	}
+	if items == nil {
+		items = []todo.ParsedTodoItem{}
+	}
+	return http.StatusOK, items, nil
+}
+
+func (s *Server) restore(l list, r *http.Request) (int, any, error) {
+	id, err := pathID(r)
+	if err != nil {
+		return 0, nil, err
+	}
+	item, err := s.restoreItem(l, id)
+	if err != nil {
+		return 0, nil, err
+	}
+	return http.StatusOK, item, nil
+}
+
+func (s *Server) purge(l list, r *http.Request) (int, any, error) {
+	n, err := s.purgeTrash(l)
+	if err != nil {
+		return 0, nil, err
+	}
+	return http.StatusOK, purgeResult{Purged: n}, nil
+}
+
+// apply changes item as p asks, reporting whether it has just been done.
+// Problems with p are returned as bad requests.
+func apply(store todo.Store, item *todo.ParsedTodoItem, p Patch) (done bool, err error) {
+	if p.Todo != nil {
+		if strings.TrimSpace(*p.Todo) == "" {
+			return false, badRequest(todo.ErrEmptyTodo)
+		}
+		item.Todo = *p.Todo
+	}
+	if p.Due != nil {
+		if item.Due, err = todo.ParseDueDate(*p.Due); err != nil {
+			return false, badRequest(err)
+		}
+	}
+	if p.Priority != nil {
+		if item.Priority, err = todo.ParsePriority(*p.Priority); err != nil {
+			return false, badRequest(err)
+		}
+	}
+	if p.Tags != nil {
+		item.Tags = todo.AddTags(nil, *p.Tags...)
+	}
+	if p.Project != nil {
+		item.Project = strings.TrimSpace(*p.Project)
+	}
+	if p.Contexts != nil {
+		item.Contexts = todo.AddContexts(nil, *p.Contexts...)
+	}
+	if p.Repeat != nil {
+		item.Repeat = ""
+		if *p.Repeat != "" {
+			rule, err := todo.ParseRepeat(*p.Repeat, item.Due)
+			if err != nil {
+				return false, badRequest(err)
+			}
+			item.Repeat = rule.String()
+		}
+	}
+	if p.Remind != nil {
+		item.Remind = ""
+		if *p.Remind != "" {
+			offsets, err := todo.ParseOffsets(*p.Remind)
+			if err != nil {
+				return false, badRequest(err)
+			}
+			item.Remind = todo.FormatOffsets(offsets)
+		}
+	}
+	if p.Estimate != nil {
+		if item.Estimate, err = todo.ParseEstimate(*p.Estimate); err != nil {
+			return false, badRequest(err)
+		}
+	}
+	if p.Parent != nil {
+		saved, err := store.List()
+		if err != nil {
+			return false, err
+		}
+		if err := todo.CheckParent(saved, item.ID, *p.Parent); err != nil {
+			return false, badRequest(err)
+		}
+		item.Parent = *p.Parent
+	}
+	if p.Notes != nil {
+		item.Notes = strings.TrimRight(*p.Notes, "\n\t ")
+	}
+
+	status := ""
+	switch {
+	case p.Status != nil:
+		if status, err = todo.ParseStatus(*p.Status); err != nil {
+			return false, badRequest(err)
+		}
+	case p.Completed != nil && *p.Completed:
+		status = todo.StatusDone
+	case p.Completed != nil:
+		status = todo.StatusBacklog
+	default:
+		return false, nil
+	}
+	if status == item.CurrentStatus() {
+		return false, nil
+	}
+	return item.SetStatus(status, time.Now()), nil
+}
+
+// pathID returns the {id} in the request's path.
+func pathID(r *http.Request) (int, error) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || id <= 0 {
+		return 0, &requestError{status: http.StatusNotFound, err: fmt.Errorf("%w with ID %q", todo.ErrNotFound, r.PathValue("id"))}
+	}
+	return id, nil
+}
+
+// decode reads the request's JSON body into v.
+func decode(r *http.Request, v any) error {
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		var tooBig *http.MaxBytesError
+		if errors.As(err, &tooBig) {
+			return &requestError{status: http.StatusRequestEntityTooLarge, err: fmt.Errorf("the body is over the limit of %d bytes", tooBig.Limit)}
+		}
+		return badRequest(fmt.Errorf("%w: %v", todo.ErrInvalidJSON, err))
+	}
+	return nil
+}
@@ -0,0 +1,358 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/buck06191/todo-app/pkg/todo"
+)
+
+// Errors returned when working with shared lists.
+var (
+	ErrBadShare     = errors.New("bad shared list")
+	ErrNoShare      = errors.New("no such shared list")
+	ErrNotMember    = errors.New("not a member of the list")
+	ErrNotPermitted = errors.New("not allowed")
+)
+
+// Role is what a member of a shared list may do with it.
+type Role string
+
+// Roles a member of a shared list can have. Viewers can look at the list,
+// editors can also change it and owners can also change who it is shared
+// with.
+const (
+	RoleOwner  Role = "owner"
+	RoleEditor Role = "editor"
+	RoleViewer Role = "viewer"
+)
+
+// Roles lists every role, from the most to the least allowed.
+var Roles = []Role{RoleOwner, RoleEditor, RoleViewer}
+
+// CanWrite reports whether the role allows changing the list.
+func (r Role) CanWrite() bool {
+	return r == RoleOwner || r == RoleEditor
+}
+
+// CanManage reports whether the role allows changing the members.
+func (r Role) CanManage() bool {
+	return r == RoleOwner
+}
+
+// sharesKey is the todo.MetaStore key shared lists are saved under.
+const sharesKey = "shared"
+
+// sharePrefix starts the name of the namespace each shared list is kept in.
+const sharePrefix = "shared/"
+
+// SharedList is a list several users can use, each with a Role. Its items
+// are kept in a namespace of the store.
+type SharedList struct {
+	Name      string          `json:"name"`
+	Members   map[string]Role `json:"members"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// Role returns the role of user in the list, or "" if they aren't a
+// member. The empty user stands for whoever manages the store, such as a
+// token without a user, and is an owner of every list.
+func (l SharedList) Role(user string) Role {
+	if user == "" {
+		return RoleOwner
+	}
+	return l.Members[user]
+}
+
+// owners returns the number of owners of the list.
+func (l SharedList) owners() int {
+	n := 0
+	for _, role := range l.Members {
+		if role == RoleOwner {
+			n++
+		}
+	}
+	return n
+}
+
+// SharedLists returns the shared lists saved in store, sorted by name.
+// Stores that aren't MetaStores have none.
+func SharedLists(store todo.Store) ([]SharedList, error) {
+	var lists []SharedList
+	err := todo.LoadMeta(store, sharesKey, &lists)
+	if errors.Is(err, todo.ErrNoMeta) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading shared lists: %w", err)
+	}
+	return lists, nil
+}
+
+// FindSharedList returns the shared list called name.
+func FindSharedList(store todo.Store, name string) (SharedList, error) {
+	lists, err := SharedLists(store)
+	if err != nil {
+		return SharedList{}, err
+	}
+	for _, l := range lists {
+		if l.Name == name {
+			return l, nil
+		}
+	}
+	return SharedList{}, fmt.Errorf("%w called %q", ErrNoShare, name)
+}
+
+// CreateSharedList saves a new, empty shared list called name with owner
+// as its only member, or with no members if owner is empty. Names are made
+// of letters, digits, - and _, and have to be unique.
+func CreateSharedList(store todo.Store, name, owner string) error {
+	if !validName(name) {
+		return fmt.Errorf("%w: %q isn't a valid name, use letters, digits, - and _", ErrBadShare, name)
+	}
+	if _, ok := store.(todo.Namespacer); !ok {
+		return todo.ErrNoNamespaces
+	}
+	members := make(map[string]Role)
+	if owner != "" {
+		if err := checkUser(store, owner); err != nil {
+			return err
+		}
+		members[owner] = RoleOwner
+	}
+	return updateShares(store, func(lists []SharedList) ([]SharedList, error) {
+		for _, l := range lists {
+			if l.Name == name {
+				return nil, fmt.Errorf("%w: there is already a shared list called %q", ErrBadShare, name)
+			}
+		}
+		lists = append(lists, SharedList{
+			Name:      name,
+			Members:   members,
+			CreatedAt: time.Now(),
+		})
+		slices.SortFunc(lists, func(a, b SharedList) int { return strings.Compare(a.Name, b.Name) })
+		return lists, nil
+	})
+}
+
+// DeleteSharedList deletes the shared list called name and everything in
+// it. Only owners may, and by is the user asking, as for SharedList.Role.
+func DeleteSharedList(store todo.Store, name, by string) error {
+	err := updateShare(store, name, func(l *SharedList) error {
+		if !l.Role(by).CanManage() {
+			return fmt.Errorf("%w: only an owner can delete %s", ErrNotPermitted, name)
+		}
+		l.Name = ""
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if ns, ok := store.(todo.Namespacer); ok {
+		return ns.DropNamespace(sharePrefix + name)
+	}
+	return nil
+}
+
+// Share gives user the role in the shared list called name, adding them
+// to it if they aren't a member yet. Only owners may, and by is the user
+// asking. The last owner can't be made something else.
+func Share(store todo.Store, name, by, user string, role Role) error {
+	if !slices.Contains(Roles, role) {
+		return fmt.Errorf("%w: unknown role %q, expected one of owner, editor, viewer", ErrBadShare, role)
+	}
+	if err := checkUser(store, user); err != nil {
+		return err
+	}
+	return updateShare(store, name, func(l *SharedList) error {
+		if !l.Role(by).CanManage() {
+			return fmt.Errorf("%w: only an owner can share %s", ErrNotPermitted, name)
+		}
+		if l.Members[user] == RoleOwner && role != RoleOwner && l.owners() == 1 {
+			return fmt.Errorf("%w: %s is the last owner of %s", ErrNotPermitted, user, name)
+		}
+		l.Members[user] = role
+		return nil
+	})
+}
+
+// Unshare takes user out of the shared list called name. Owners may take
+// anyone out and everyone else only themselves; by is the user asking. The
+// last owner can't be taken out.
+func Unshare(store todo.Store, name, by, user string) error {
+	return updateShare(store, name, func(l *SharedList) error {
+		if by != user && !l.Role(by).CanManage() {
+			return fmt.Errorf("%w: only an owner can take others out of %s", ErrNotPermitted, name)
+		}
+		if _, ok := l.Members[user]; !ok {
+			return fmt.Errorf("%w: %s isn't in %s", ErrNotMember, user, name)
+		}
+		if l.Members[user] == RoleOwner && l.owners() == 1 {
+			return fmt.Errorf("%w: %s is the last owner of %s", ErrNotPermitted, user, name)
+		}
+		delete(l.Members, user)
+		return nil
+	})
+}
+
+// SharedNamespace returns the name of the namespace the shared list called
+// name keeps its items in.
+func SharedNamespace(name string) string {
+	return sharePrefix + name
+}
+
+// SharedStore returns the items of the shared list called name along with
+// the role user has in it. It fails with ErrNotMember if they have none.
+func SharedStore(store todo.Store, name, user string) (todo.Store, Role, error) {
+	l, err := FindSharedList(store, name)
+	if err != nil {
+		return nil, "", err
+	}
+	role := l.Role(user)
+	if role == "" {
+		return nil, "", fmt.Errorf("%w: %s isn't in %s", ErrNotMember, user, name)
+	}
+	list, err := todo.Namespace(store, SharedNamespace(name))
+	if err != nil {
+		return nil, "", err
+	}
+	return list, role, nil
+}
+
+// leaveShares takes user out of every shared list, for when they are
+// deleted. Lists left without an owner can still be managed by whoever
+// manages the store.
+func leaveShares(store todo.Store, user string) error {
+	return updateShares(store, func(lists []SharedList) ([]SharedList, error) {
+		for _, l := range lists {
+			delete(l.Members, user)
+		}
+		return lists, nil
+	})
+}
+
+// checkUser returns an error unless store has a user called name.
+func checkUser(store todo.Store, name string) error {
+	ok, err := hasUser(store, name)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%w called %q", ErrNoUser, name)
+	}
+	return nil
+}
+
+// updateShare applies fn to the shared list called name and saves the
+// result. fn can delete the list by clearing its name.
+func updateShare(store todo.Store, name string, fn func(l *SharedList) error) error {
+	return updateShares(store, func(lists []SharedList) ([]SharedList, error) {
+		i := slices.IndexFunc(lists, func(l SharedList) bool { return l.Name == name })
+		if i < 0 {
+			return nil, fmt.Errorf("%w called %q", ErrNoShare, name)
+		}
+		l := lists[i]
+		l.Members = maps.Clone(l.Members)
+		if l.Members == nil {
+			l.Members = make(map[string]Role)
+		}
+		if err := fn(&l); err != nil {
+			return nil, err
+		}
+		if l.Name == "" {
+			return slices.Delete(lists, i, i+1), nil
+		}
+		lists[i] = l
+		return lists, nil
+	})
+}
+
+func updateShares(store todo.Store, fn func(lists []SharedList) ([]SharedList, error)) error {
+	lists, err := SharedLists(store)
+	if err != nil {
+		return err
+	}
+	if lists, err = fn(lists); err != nil {
+		return err
+	}
+	return todo.SaveMeta(store, sharesKey, lists)
+}
+
+// newSharedList is the body of a request creating a shared list.
+type newSharedList struct {
+	Name string `json:"name"`
+}
+
+// memberRole is the body of a request giving a user a role.
+type memberRole struct {
+	Role Role `json:"role"`
+}
+
+// sharedLists lists the shared lists user is in, or every one for whoever
+// manages the store.
+func (s *Server) sharedLists(user string, r *http.Request) (int, any, error) {
+	lists, err := SharedLists(s.store)
+	if err != nil {
+		return 0, nil, err
+	}
+	found := []SharedList{}
+	for _, l := range lists {
+		if l.Role(user) != "" {
+			found = append(found, l)
+		}
+	}
+	return http.StatusOK, found, nil
+}
+
+// createList creates a shared list with user as its owner.
+func (s *Server) createList(user string, r *http.Request) (int, any, error) {
+	var body newSharedList
+	if err := decode(r, &body); err != nil {
+		return 0, nil, err
+	}
+	if err := CreateSharedList(s.store, body.Name, user); err != nil {
+		return 0, nil, err
+	}
+	l, err := FindSharedList(s.store, body.Name)
+	if err != nil {
+		return 0, nil, err
+	}
+	return http.StatusCreated, l, nil
+}
+
+func (s *Server) deleteList(user string, r *http.Request) (int, any, error) {
+	if err := DeleteSharedList(s.store, r.PathValue("list"), user); err != nil {
+		return 0, nil, err
+	}
+	return http.StatusNoContent, nil, nil
+}
+
+// share gives a user a role in a shared list.
+func (s *Server) share(user string, r *http.Request) (int, any, error) {
+	var body memberRole
+	if err := decode(r, &body); err != nil {
+		return 0, nil, err
+	}
+	name := r.PathValue("list")
+	if err := Share(s.store, name, user, r.PathValue("user"), body.Role); err != nil {
+		return 0, nil, err
+	}
+	l, err := FindSharedList(s.store, name)
+	if err != nil {
+		return 0, nil, err
+	}
+	return http.StatusOK, l, nil
+}
+
+func (s *Server) unshare(user string, r *http.Request) (int, any, error) {
+	if err := Unshare(s.store, r.PathValue("list"), user, r.PathValue("user")); err != nil {
+		return 0, nil, err
+	}
+	return http.StatusNoContent, nil, nil
+}
@@ -0,0 +1,123 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/buck06191/todo-app/pkg/slack"
+	"github.com/buck06191/todo-app/pkg/todo"
+)
+
+// slackListLimit is how many items /todo list shows in Slack.
+const slackListLimit = 20
+
+// slackHelp answers /todo help, and commands that aren't known. It is
+// already escaped for Slack.
+const slackHelp = "`%[1]s add &lt;task&gt;` adds an item, ending with `due &lt;date&gt;` for when it is due, e.g. `%[1]s add Book the venue #party due friday 5pm`.\n" +
+	"`%[1]s list` shows what is still to do."
+
+// EnableSlack serves POST /slack/command for a Slack slash command such as
+// /todo, so that people can add to and look at a list from Slack. Requests
+// have to be signed with the app's signing secret, in place of an API
+// token. The command works on the shared list called shared, or on the
+// store's own list if shared is empty.
+func (s *Server) EnableSlack(secret, shared string) {
+	s.mux.HandleFunc("POST /slack/command", func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			reply(w, 0, nil, badRequest(err))
+			return
+		}
+		if err := slack.Verify(secret, r.Header, body, time.Now()); err != nil {
+			reply(w, 0, nil, &requestError{status: http.StatusUnauthorized, err: err})
+			return
+		}
+		cmd, err := slack.ParseCommand(body)
+		if err != nil {
+			reply(w, 0, nil, badRequest(err))
+			return
+		}
+
+		// Slack only shows what is answered with 200, so mistakes are
+		// answered that way too, to whoever made them.
+		s.mu.Lock()
+		msg, err := s.slackCommand(cmd, shared)
+		s.mu.Unlock()
+		if err != nil {
+			msg = slack.Message{ResponseType: slack.Ephemeral, Text: "Sorry, " + slack.Escape(err.Error())}
+		}
+		reply(w, http.StatusOK, msg, nil)
+	})
+}
+
+// slackCommand runs a slash command on the shared list called shared, or
+// the store's own list. s.mu must be held.
+func (s *Server) slackCommand(cmd slack.Command, shared string) (slack.Message, error) {
+	name := cmd.Command
+	if name == "" {
+		name = "/todo"
+	}
+	verb, rest, _ := strings.Cut(cmd.Text, " ")
+	rest = strings.TrimSpace(rest)
+
+	switch strings.ToLower(verb) {
+	case "add":
+		l, err := s.open("", shared, true)
+		if err != nil {
+			return slack.Message{}, err
+		}
+		if rest == "" {
+			return slack.Message{}, fmt.Errorf("there's nothing to add, try `%s add Buy milk`", name)
+		}
+		task, due := todo.SplitDue(rest)
+		item, err := s.addItem(l, todo.TodoItem{Todo: task, Due: due})
+		if err != nil {
+			return slack.Message{}, err
+		}
+		when := ""
+		if !item.Due.IsZero() {
+			when = "due " + item.Due.In(todo.Location).Format("Mon 2 Jan")
+			if !item.DueAllDay() {
+				when += item.Due.In(todo.Location).Format(" 15:04")
+			}
+		}
+		who := "Added"
+		if cmd.UserID != "" {
+			who = fmt.Sprintf("<@%s> added", cmd.UserID)
+		}
+		return slack.Message{ResponseType: slack.InChannel, Text: who + ":\n" + slack.Line(item, when)}, nil
+
+	case "list":
+		l, err := s.open("", shared, false)
+		if err != nil {
+			return slack.Message{}, err
+		}
+		items, err := s.find(l, query{})
+		if err != nil {
+			return slack.Message{}, err
+		}
+		if len(items) == 0 {
+			return slack.Message{ResponseType: slack.Ephemeral, Text: "There's nothing to do."}, nil
+		}
+		var b strings.Builder
+		for i, item := range items {
+			if i == slackListLimit {
+				fmt.Fprintf(&b, "and %d more\n", len(items)-i)
+				break
+			}
+			when := ""
+			if !item.Due.IsZero() {
+				when = "due " + item.Due.In(todo.Location).Format("Mon 2 Jan")
+			}
+			b.WriteString(slack.Line(item, when) + "\n")
+		}
+		return slack.Message{ResponseType: slack.Ephemeral, Text: strings.TrimSuffix(b.String(), "\n")}, nil
+
+	case "", "help":
+		return slack.Message{ResponseType: slack.Ephemeral, Text: fmt.Sprintf(slackHelp, name)}, nil
+	}
+	return slack.Message{ResponseType: slack.Ephemeral, Text: fmt.Sprintf("There's no `%s %s`.\n", name, slack.Escape(verb)) + fmt.Sprintf(slackHelp, name)}, nil
+}
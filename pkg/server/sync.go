@@ -0,0 +1,50 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/buck06191/todo-app/pkg/todosync"
+)
+
+// pullSync returns the changes made to the list since the revision in the
+// since parameter, for a device to sync.
+func (s *Server) pullSync(l list, r *http.Request) (int, any, error) {
+	since := 0
+	if param := r.URL.Query().Get("since"); param != "" {
+		var err error
+		if since, err = strconv.Atoi(param); err != nil || since < 0 {
+			return 0, nil, badRequest(fmt.Errorf("since has to be a revision, not %q", param))
+		}
+	}
+	pull, err := todosync.Changes(l.store, since)
+	if err != nil {
+		return 0, nil, err
+	}
+	return http.StatusOK, pull, nil
+}
+
+// pushSync makes the changes a device has made to the list.
+func (s *Server) pushSync(l list, r *http.Request) (int, any, error) {
+	var push todosync.Push
+	if err := decode(r, &push); err != nil {
+		return 0, nil, err
+	}
+	pushed, applied, err := todosync.Apply(l.store, push)
+	if err != nil {
+		return 0, nil, err
+	}
+	for _, c := range applied {
+		switch {
+		case c.Ref != 0:
+			s.metrics.added.WithLabelValues(l.name).Inc()
+			s.publish(l, eventAdded, *c.Item)
+		case !c.Item.DeletedAt.IsZero():
+			s.publish(l, eventDeleted, *c.Item)
+		default:
+			s.publish(l, eventUpdated, *c.Item)
+		}
+	}
+	return http.StatusOK, pushed, nil
+}
@@ -0,0 +1,5 @@
+// Package todopb holds the messages and service of the gRPC API, generated
+// from todo.proto. Run go generate after changing it.
+package todopb
+
+//go:generate buf generate --template buf.gen.yaml
@@ -0,0 +1,1485 @@
+// The gRPC API of `todo-app serve`. It offers the same operations as the
+// JSON API, on the same store and with the same API tokens, sent as
+// "authorization: Bearer <token>" metadata.
+//
+// Errors use the standard gRPC codes: INVALID_ARGUMENT for bad requests,
+// UNAUTHENTICATED and PERMISSION_DENIED for token and role problems and
+// NOT_FOUND for unknown items and lists.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.12
+// 	protoc        (unknown)
+// source: todo.proto
+
+package todopb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type TodoEvent_Type int32
+
+const (
+	TodoEvent_TYPE_UNSPECIFIED TodoEvent_Type = 0
+	TodoEvent_TYPE_ADDED       TodoEvent_Type = 1
+	TodoEvent_TYPE_UPDATED     TodoEvent_Type = 2
+	TodoEvent_TYPE_DELETED     TodoEvent_Type = 3
+	TodoEvent_TYPE_RESTORED    TodoEvent_Type = 4
+	// The item was thrown away with the rest of the trash.
+	TodoEvent_TYPE_PURGED TodoEvent_Type = 5
+)
+
+// Enum value maps for TodoEvent_Type.
+var (
+	TodoEvent_Type_name = map[int32]string{
+		0: "TYPE_UNSPECIFIED",
+		1: "TYPE_ADDED",
+		2: "TYPE_UPDATED",
+		3: "TYPE_DELETED",
+		4: "TYPE_RESTORED",
+		5: "TYPE_PURGED",
+	}
+	TodoEvent_Type_value = map[string]int32{
+		"TYPE_UNSPECIFIED": 0,
+		"TYPE_ADDED":       1,
+		"TYPE_UPDATED":     2,
+		"TYPE_DELETED":     3,
+		"TYPE_RESTORED":    4,
+		"TYPE_PURGED":      5,
+	}
+)
+
+func (x TodoEvent_Type) Enum() *TodoEvent_Type {
+	p := new(TodoEvent_Type)
+	*p = x
+	return p
+}
+
+func (x TodoEvent_Type) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (TodoEvent_Type) Descriptor() protoreflect.EnumDescriptor {
+	return file_todo_proto_enumTypes[0].Descriptor()
+}
+
+func (TodoEvent_Type) Type() protoreflect.EnumType {
+	return &file_todo_proto_enumTypes[0]
+}
+
+func (x TodoEvent_Type) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use TodoEvent_Type.Descriptor instead.
+func (TodoEvent_Type) EnumDescriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{15, 0}
+}
+
+// Todo is an item on the list, as saved in the store.
+type Todo struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Todo  string                 `protobuf:"bytes,2,opt,name=todo,proto3" json:"todo,omitempty"`
+	Due   *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=due,proto3" json:"due,omitempty"`
+	// low, medium, high or empty.
+	Priority string   `protobuf:"bytes,4,opt,name=priority,proto3" json:"priority,omitempty"`
+	Tags     []string `protobuf:"bytes,5,rep,name=tags,proto3" json:"tags,omitempty"`
+	Project  string   `protobuf:"bytes,6,opt,name=project,proto3" json:"project,omitempty"`
+	Contexts []string `protobuf:"bytes,7,rep,name=contexts,proto3" json:"contexts,omitempty"`
+	// The recurrence rule of a repeating item, in RRULE syntax.
+	Repeat string `protobuf:"bytes,8,opt,name=repeat,proto3" json:"repeat,omitempty"`
+	// The ID of the item this is a subtask of, or 0.
+	Parent    int64   `protobuf:"varint,9,opt,name=parent,proto3" json:"parent,omitempty"`
+	BlockedBy []int64 `protobuf:"varint,10,rep,packed,name=blocked_by,json=blockedBy,proto3" json:"blocked_by,omitempty"`
+	Notes     string  `protobuf:"bytes,11,opt,name=notes,proto3" json:"notes,omitempty"`
+	// doing for started items, or empty.
+	Status      string                 `protobuf:"bytes,12,opt,name=status,proto3" json:"status,omitempty"`
+	CreatedAt   *timestamppb.Timestamp `protobuf:"bytes,13,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Completed   bool                   `protobuf:"varint,14,opt,name=completed,proto3" json:"completed,omitempty"`
+	CompletedAt *timestamppb.Timestamp `protobuf:"bytes,15,opt,name=completed_at,json=completedAt,proto3" json:"completed_at,omitempty"`
+	DeletedAt   *timestamppb.Timestamp `protobuf:"bytes,16,opt,name=deleted_at,json=deletedAt,proto3" json:"deleted_at,omitempty"`
+	// How long before it is due to be reminded of the item, such as "1d,2h".
+	Remind string `protobuf:"bytes,17,opt,name=remind,proto3" json:"remind,omitempty"`
+	// How many times the item has been put off with snooze.
+	Snoozed int32 `protobuf:"varint,18,opt,name=snoozed,proto3" json:"snoozed,omitempty"`
+	// The times the item was worked on.
+	Worked []*Interval `protobuf:"bytes,19,rep,name=worked,proto3" json:"worked,omitempty"`
+	// How long the item is expected to take, such as "2h" or "1d".
+	Estimate string `protobuf:"bytes,20,opt,name=estimate,proto3" json:"estimate,omitempty"`
+	// Whether the item is in the inbox, waiting to be triaged.
+	Inbox         bool `protobuf:"varint,21,opt,name=inbox,proto3" json:"inbox,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Todo) Reset() {
+	*x = Todo{}
+	mi := &file_todo_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Todo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Todo) ProtoMessage() {}
+
+func (x *Todo) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Todo.ProtoReflect.Descriptor instead.
+func (*Todo) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Todo) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Todo) GetTodo() string {
+	if x != nil {
+		return x.Todo
+	}
+	return ""
+}
+
+func (x *Todo) GetDue() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Due
+	}
+	return nil
+}
+
+func (x *Todo) GetPriority() string {
+	if x != nil {
+		return x.Priority
+	}
+	return ""
+}
+
+func (x *Todo) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *Todo) GetProject() string {
+	if x != nil {
+		return x.Project
+	}
+	return ""
+}
+
+func (x *Todo) GetContexts() []string {
+	if x != nil {
+		return x.Contexts
+	}
+	return nil
+}
+
+func (x *Todo) GetRepeat() string {
+	if x != nil {
+		return x.Repeat
+	}
+	return ""
+}
+
+func (x *Todo) GetParent() int64 {
+	if x != nil {
+		return x.Parent
+	}
+	return 0
+}
+
+func (x *Todo) GetBlockedBy() []int64 {
+	if x != nil {
+		return x.BlockedBy
+	}
+	return nil
+}
+
+func (x *Todo) GetNotes() string {
+	if x != nil {
+		return x.Notes
+	}
+	return ""
+}
+
+func (x *Todo) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Todo) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Todo) GetCompleted() bool {
+	if x != nil {
+		return x.Completed
+	}
+	return false
+}
+
+func (x *Todo) GetCompletedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CompletedAt
+	}
+	return nil
+}
+
+func (x *Todo) GetDeletedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DeletedAt
+	}
+	return nil
+}
+
+func (x *Todo) GetRemind() string {
+	if x != nil {
+		return x.Remind
+	}
+	return ""
+}
+
+func (x *Todo) GetSnoozed() int32 {
+	if x != nil {
+		return x.Snoozed
+	}
+	return 0
+}
+
+func (x *Todo) GetWorked() []*Interval {
+	if x != nil {
+		return x.Worked
+	}
+	return nil
+}
+
+func (x *Todo) GetEstimate() string {
+	if x != nil {
+		return x.Estimate
+	}
+	return ""
+}
+
+func (x *Todo) GetInbox() bool {
+	if x != nil {
+		return x.Inbox
+	}
+	return false
+}
+
+// Interval is a time an item was worked on. end is unset while it still
+// is.
+type Interval struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Start         *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=start,proto3" json:"start,omitempty"`
+	End           *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=end,proto3" json:"end,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Interval) Reset() {
+	*x = Interval{}
+	mi := &file_todo_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Interval) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Interval) ProtoMessage() {}
+
+func (x *Interval) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Interval.ProtoReflect.Descriptor instead.
+func (*Interval) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Interval) GetStart() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Start
+	}
+	return nil
+}
+
+func (x *Interval) GetEnd() *timestamppb.Timestamp {
+	if x != nil {
+		return x.End
+	}
+	return nil
+}
+
+// Strings is a list of strings that can be told apart from no list at all.
+type Strings struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Values        []string               `protobuf:"bytes,1,rep,name=values,proto3" json:"values,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Strings) Reset() {
+	*x = Strings{}
+	mi := &file_todo_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Strings) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Strings) ProtoMessage() {}
+
+func (x *Strings) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Strings.ProtoReflect.Descriptor instead.
+func (*Strings) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Strings) GetValues() []string {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+type ListTodosRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	List  string                 `protobuf:"bytes,1,opt,name=list,proto3" json:"list,omitempty"`
+	// Include done items.
+	All bool `protobuf:"varint,2,opt,name=all,proto3" json:"all,omitempty"`
+	// A filter expression, as taken by -where.
+	Where string `protobuf:"bytes,3,opt,name=where,proto3" json:"where,omitempty"`
+	// Words to search for.
+	Query string `protobuf:"bytes,4,opt,name=query,proto3" json:"query,omitempty"`
+	// A sort order, as taken by -sort.
+	Sort          string `protobuf:"bytes,5,opt,name=sort,proto3" json:"sort,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTodosRequest) Reset() {
+	*x = ListTodosRequest{}
+	mi := &file_todo_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTodosRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTodosRequest) ProtoMessage() {}
+
+func (x *ListTodosRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTodosRequest.ProtoReflect.Descriptor instead.
+func (*ListTodosRequest) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListTodosRequest) GetList() string {
+	if x != nil {
+		return x.List
+	}
+	return ""
+}
+
+func (x *ListTodosRequest) GetAll() bool {
+	if x != nil {
+		return x.All
+	}
+	return false
+}
+
+func (x *ListTodosRequest) GetWhere() string {
+	if x != nil {
+		return x.Where
+	}
+	return ""
+}
+
+func (x *ListTodosRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *ListTodosRequest) GetSort() string {
+	if x != nil {
+		return x.Sort
+	}
+	return ""
+}
+
+type ListTodosResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Todos         []*Todo                `protobuf:"bytes,1,rep,name=todos,proto3" json:"todos,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTodosResponse) Reset() {
+	*x = ListTodosResponse{}
+	mi := &file_todo_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTodosResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTodosResponse) ProtoMessage() {}
+
+func (x *ListTodosResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTodosResponse.ProtoReflect.Descriptor instead.
+func (*ListTodosResponse) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListTodosResponse) GetTodos() []*Todo {
+	if x != nil {
+		return x.Todos
+	}
+	return nil
+}
+
+type GetTodoRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	List          string                 `protobuf:"bytes,1,opt,name=list,proto3" json:"list,omitempty"`
+	Id            int64                  `protobuf:"varint,2,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTodoRequest) Reset() {
+	*x = GetTodoRequest{}
+	mi := &file_todo_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTodoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTodoRequest) ProtoMessage() {}
+
+func (x *GetTodoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTodoRequest.ProtoReflect.Descriptor instead.
+func (*GetTodoRequest) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetTodoRequest) GetList() string {
+	if x != nil {
+		return x.List
+	}
+	return ""
+}
+
+func (x *GetTodoRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+// AddTodoRequest takes dates, priorities and repeat rules in the same forms
+// as the command line.
+type AddTodoRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	List          string                 `protobuf:"bytes,1,opt,name=list,proto3" json:"list,omitempty"`
+	Todo          string                 `protobuf:"bytes,2,opt,name=todo,proto3" json:"todo,omitempty"`
+	Due           string                 `protobuf:"bytes,3,opt,name=due,proto3" json:"due,omitempty"`
+	Priority      string                 `protobuf:"bytes,4,opt,name=priority,proto3" json:"priority,omitempty"`
+	Tags          []string               `protobuf:"bytes,5,rep,name=tags,proto3" json:"tags,omitempty"`
+	Project       string                 `protobuf:"bytes,6,opt,name=project,proto3" json:"project,omitempty"`
+	Contexts      []string               `protobuf:"bytes,7,rep,name=contexts,proto3" json:"contexts,omitempty"`
+	Repeat        string                 `protobuf:"bytes,8,opt,name=repeat,proto3" json:"repeat,omitempty"`
+	Parent        int64                  `protobuf:"varint,9,opt,name=parent,proto3" json:"parent,omitempty"`
+	Notes         string                 `protobuf:"bytes,10,opt,name=notes,proto3" json:"notes,omitempty"`
+	Remind        string                 `protobuf:"bytes,11,opt,name=remind,proto3" json:"remind,omitempty"`
+	Estimate      string                 `protobuf:"bytes,12,opt,name=estimate,proto3" json:"estimate,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddTodoRequest) Reset() {
+	*x = AddTodoRequest{}
+	mi := &file_todo_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddTodoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddTodoRequest) ProtoMessage() {}
+
+func (x *AddTodoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddTodoRequest.ProtoReflect.Descriptor instead.
+func (*AddTodoRequest) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *AddTodoRequest) GetList() string {
+	if x != nil {
+		return x.List
+	}
+	return ""
+}
+
+func (x *AddTodoRequest) GetTodo() string {
+	if x != nil {
+		return x.Todo
+	}
+	return ""
+}
+
+func (x *AddTodoRequest) GetDue() string {
+	if x != nil {
+		return x.Due
+	}
+	return ""
+}
+
+func (x *AddTodoRequest) GetPriority() string {
+	if x != nil {
+		return x.Priority
+	}
+	return ""
+}
+
+func (x *AddTodoRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *AddTodoRequest) GetProject() string {
+	if x != nil {
+		return x.Project
+	}
+	return ""
+}
+
+func (x *AddTodoRequest) GetContexts() []string {
+	if x != nil {
+		return x.Contexts
+	}
+	return nil
+}
+
+func (x *AddTodoRequest) GetRepeat() string {
+	if x != nil {
+		return x.Repeat
+	}
+	return ""
+}
+
+func (x *AddTodoRequest) GetParent() int64 {
+	if x != nil {
+		return x.Parent
+	}
+	return 0
+}
+
+func (x *AddTodoRequest) GetNotes() string {
+	if x != nil {
+		return x.Notes
+	}
+	return ""
+}
+
+func (x *AddTodoRequest) GetRemind() string {
+	if x != nil {
+		return x.Remind
+	}
+	return ""
+}
+
+func (x *AddTodoRequest) GetEstimate() string {
+	if x != nil {
+		return x.Estimate
+	}
+	return ""
+}
+
+// UpdateTodoRequest changes only the fields that are set, and an empty
+// value clears a field. Setting status to done or completed to true adds
+// the next occurrence of a repeating item.
+type UpdateTodoRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	List          string                 `protobuf:"bytes,1,opt,name=list,proto3" json:"list,omitempty"`
+	Id            int64                  `protobuf:"varint,2,opt,name=id,proto3" json:"id,omitempty"`
+	Todo          *string                `protobuf:"bytes,3,opt,name=todo,proto3,oneof" json:"todo,omitempty"`
+	Due           *string                `protobuf:"bytes,4,opt,name=due,proto3,oneof" json:"due,omitempty"`
+	Priority      *string                `protobuf:"bytes,5,opt,name=priority,proto3,oneof" json:"priority,omitempty"`
+	Tags          *Strings               `protobuf:"bytes,6,opt,name=tags,proto3" json:"tags,omitempty"`
+	Project       *string                `protobuf:"bytes,7,opt,name=project,proto3,oneof" json:"project,omitempty"`
+	Contexts      *Strings               `protobuf:"bytes,8,opt,name=contexts,proto3" json:"contexts,omitempty"`
+	Repeat        *string                `protobuf:"bytes,9,opt,name=repeat,proto3,oneof" json:"repeat,omitempty"`
+	Parent        *int64                 `protobuf:"varint,10,opt,name=parent,proto3,oneof" json:"parent,omitempty"`
+	Notes         *string                `protobuf:"bytes,11,opt,name=notes,proto3,oneof" json:"notes,omitempty"`
+	Status        *string                `protobuf:"bytes,12,opt,name=status,proto3,oneof" json:"status,omitempty"`
+	Completed     *bool                  `protobuf:"varint,13,opt,name=completed,proto3,oneof" json:"completed,omitempty"`
+	Remind        *string                `protobuf:"bytes,14,opt,name=remind,proto3,oneof" json:"remind,omitempty"`
+	Estimate      *string                `protobuf:"bytes,15,opt,name=estimate,proto3,oneof" json:"estimate,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateTodoRequest) Reset() {
+	*x = UpdateTodoRequest{}
+	mi := &file_todo_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateTodoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateTodoRequest) ProtoMessage() {}
+
+func (x *UpdateTodoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateTodoRequest.ProtoReflect.Descriptor instead.
+func (*UpdateTodoRequest) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *UpdateTodoRequest) GetList() string {
+	if x != nil {
+		return x.List
+	}
+	return ""
+}
+
+func (x *UpdateTodoRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *UpdateTodoRequest) GetTodo() string {
+	if x != nil && x.Todo != nil {
+		return *x.Todo
+	}
+	return ""
+}
+
+func (x *UpdateTodoRequest) GetDue() string {
+	if x != nil && x.Due != nil {
+		return *x.Due
+	}
+	return ""
+}
+
+func (x *UpdateTodoRequest) GetPriority() string {
+	if x != nil && x.Priority != nil {
+		return *x.Priority
+	}
+	return ""
+}
+
+func (x *UpdateTodoRequest) GetTags() *Strings {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *UpdateTodoRequest) GetProject() string {
+	if x != nil && x.Project != nil {
+		return *x.Project
+	}
+	return ""
+}
+
+func (x *UpdateTodoRequest) GetContexts() *Strings {
+	if x != nil {
+		return x.Contexts
+	}
+	return nil
+}
+
+func (x *UpdateTodoRequest) GetRepeat() string {
+	if x != nil && x.Repeat != nil {
+		return *x.Repeat
+	}
+	return ""
+}
+
+func (x *UpdateTodoRequest) GetParent() int64 {
+	if x != nil && x.Parent != nil {
+		return *x.Parent
+	}
+	return 0
+}
+
+func (x *UpdateTodoRequest) GetNotes() string {
+	if x != nil && x.Notes != nil {
+		return *x.Notes
+	}
+	return ""
+}
+
+func (x *UpdateTodoRequest) GetStatus() string {
+	if x != nil && x.Status != nil {
+		return *x.Status
+	}
+	return ""
+}
+
+func (x *UpdateTodoRequest) GetCompleted() bool {
+	if x != nil && x.Completed != nil {
+		return *x.Completed
+	}
+	return false
+}
+
+func (x *UpdateTodoRequest) GetRemind() string {
+	if x != nil && x.Remind != nil {
+		return *x.Remind
+	}
+	return ""
+}
+
+func (x *UpdateTodoRequest) GetEstimate() string {
+	if x != nil && x.Estimate != nil {
+		return *x.Estimate
+	}
+	return ""
+}
+
+type DeleteTodoRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	List          string                 `protobuf:"bytes,1,opt,name=list,proto3" json:"list,omitempty"`
+	Id            int64                  `protobuf:"varint,2,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteTodoRequest) Reset() {
+	*x = DeleteTodoRequest{}
+	mi := &file_todo_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteTodoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteTodoRequest) ProtoMessage() {}
+
+func (x *DeleteTodoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteTodoRequest.ProtoReflect.Descriptor instead.
+func (*DeleteTodoRequest) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *DeleteTodoRequest) GetList() string {
+	if x != nil {
+		return x.List
+	}
+	return ""
+}
+
+func (x *DeleteTodoRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type DeleteTodoResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteTodoResponse) Reset() {
+	*x = DeleteTodoResponse{}
+	mi := &file_todo_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteTodoResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteTodoResponse) ProtoMessage() {}
+
+func (x *DeleteTodoResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteTodoResponse.ProtoReflect.Descriptor instead.
+func (*DeleteTodoResponse) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{9}
+}
+
+type ListTrashRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	List          string                 `protobuf:"bytes,1,opt,name=list,proto3" json:"list,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTrashRequest) Reset() {
+	*x = ListTrashRequest{}
+	mi := &file_todo_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTrashRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTrashRequest) ProtoMessage() {}
+
+func (x *ListTrashRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTrashRequest.ProtoReflect.Descriptor instead.
+func (*ListTrashRequest) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ListTrashRequest) GetList() string {
+	if x != nil {
+		return x.List
+	}
+	return ""
+}
+
+type RestoreTodoRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	List          string                 `protobuf:"bytes,1,opt,name=list,proto3" json:"list,omitempty"`
+	Id            int64                  `protobuf:"varint,2,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestoreTodoRequest) Reset() {
+	*x = RestoreTodoRequest{}
+	mi := &file_todo_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestoreTodoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreTodoRequest) ProtoMessage() {}
+
+func (x *RestoreTodoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestoreTodoRequest.ProtoReflect.Descriptor instead.
+func (*RestoreTodoRequest) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *RestoreTodoRequest) GetList() string {
+	if x != nil {
+		return x.List
+	}
+	return ""
+}
+
+func (x *RestoreTodoRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type PurgeTrashRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	List          string                 `protobuf:"bytes,1,opt,name=list,proto3" json:"list,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PurgeTrashRequest) Reset() {
+	*x = PurgeTrashRequest{}
+	mi := &file_todo_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PurgeTrashRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PurgeTrashRequest) ProtoMessage() {}
+
+func (x *PurgeTrashRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PurgeTrashRequest.ProtoReflect.Descriptor instead.
+func (*PurgeTrashRequest) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *PurgeTrashRequest) GetList() string {
+	if x != nil {
+		return x.List
+	}
+	return ""
+}
+
+type PurgeTrashResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// How many items were thrown away.
+	Purged        int32 `protobuf:"varint,1,opt,name=purged,proto3" json:"purged,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PurgeTrashResponse) Reset() {
+	*x = PurgeTrashResponse{}
+	mi := &file_todo_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PurgeTrashResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PurgeTrashResponse) ProtoMessage() {}
+
+func (x *PurgeTrashResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PurgeTrashResponse.ProtoReflect.Descriptor instead.
+func (*PurgeTrashResponse) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *PurgeTrashResponse) GetPurged() int32 {
+	if x != nil {
+		return x.Purged
+	}
+	return 0
+}
+
+type WatchTodosRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	List          string                 `protobuf:"bytes,1,opt,name=list,proto3" json:"list,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchTodosRequest) Reset() {
+	*x = WatchTodosRequest{}
+	mi := &file_todo_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchTodosRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchTodosRequest) ProtoMessage() {}
+
+func (x *WatchTodosRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchTodosRequest.ProtoReflect.Descriptor instead.
+func (*WatchTodosRequest) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *WatchTodosRequest) GetList() string {
+	if x != nil {
+		return x.List
+	}
+	return ""
+}
+
+// TodoEvent is a change to the list.
+type TodoEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Type  TodoEvent_Type         `protobuf:"varint,1,opt,name=type,proto3,enum=todo.v1.TodoEvent_Type" json:"type,omitempty"`
+	// The item as it is after the change.
+	Todo          *Todo                  `protobuf:"bytes,2,opt,name=todo,proto3" json:"todo,omitempty"`
+	Time          *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=time,proto3" json:"time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TodoEvent) Reset() {
+	*x = TodoEvent{}
+	mi := &file_todo_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TodoEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TodoEvent) ProtoMessage() {}
+
+func (x *TodoEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_todo_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TodoEvent.ProtoReflect.Descriptor instead.
+func (*TodoEvent) Descriptor() ([]byte, []int) {
+	return file_todo_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *TodoEvent) GetType() TodoEvent_Type {
+	if x != nil {
+		return x.Type
+	}
+	return TodoEvent_TYPE_UNSPECIFIED
+}
+
+func (x *TodoEvent) GetTodo() *Todo {
+	if x != nil {
+		return x.Todo
+	}
+	return nil
+}
+
+func (x *TodoEvent) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+var File_todo_proto protoreflect.FileDescriptor
+
+const file_todo_proto_rawDesc = "" +
+	"\n" +
+	"\n" +
+	"todo.proto\x12\atodo.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\x9d\x05\n" +
+	"\x04Todo\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
+	"\x04todo\x18\x02 \x01(\tR\x04todo\x12,\n" +
+	"\x03due\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x03due\x12\x1a\n" +
+	"\bpriority\x18\x04 \x01(\tR\bpriority\x12\x12\n" +
+	"\x04tags\x18\x05 \x03(\tR\x04tags\x12\x18\n" +
+	"\aproject\x18\x06 \x01(\tR\aproject\x12\x1a\n" +
+	"\bcontexts\x18\a \x03(\tR\bcontexts\x12\x16\n" +
+	"\x06repeat\x18\b \x01(\tR\x06repeat\x12\x16\n" +
+	"\x06parent\x18\t \x01(\x03R\x06parent\x12\x1d\n" +
+	"\n" +
+	"blocked_by\x18\n" +
+	" \x03(\x03R\tblockedBy\x12\x14\n" +
+	"\x05notes\x18\v \x01(\tR\x05notes\x12\x16\n" +
+	"\x06status\x18\f \x01(\tR\x06status\x129\n" +
+	"\n" +
+	"created_at\x18\r \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12\x1c\n" +
+	"\tcompleted\x18\x0e \x01(\bR\tcompleted\x12=\n" +
+	"\fcompleted_at\x18\x0f \x01(\v2\x1a.google.protobuf.TimestampR\vcompletedAt\x129\n" +
+	"\n" +
+	"deleted_at\x18\x10 \x01(\v2\x1a.google.protobuf.TimestampR\tdeletedAt\x12\x16\n" +
+	"\x06remind\x18\x11 \x01(\tR\x06remind\x12\x18\n" +
+	"\asnoozed\x18\x12 \x01(\x05R\asnoozed\x12)\n" +
+	"\x06worked\x18\x13 \x03(\v2\x11.todo.v1.IntervalR\x06worked\x12\x1a\n" +
+	"\bestimate\x18\x14 \x01(\tR\bestimate\x12\x14\n" +
+	"\x05inbox\x18\x15 \x01(\bR\x05inbox\"j\n" +
+	"\bInterval\x120\n" +
+	"\x05start\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x05start\x12,\n" +
+	"\x03end\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x03end\"!\n" +
+	"\aStrings\x12\x16\n" +
+	"\x06values\x18\x01 \x03(\tR\x06values\"x\n" +
+	"\x10ListTodosRequest\x12\x12\n" +
+	"\x04list\x18\x01 \x01(\tR\x04list\x12\x10\n" +
+	"\x03all\x18\x02 \x01(\bR\x03all\x12\x14\n" +
+	"\x05where\x18\x03 \x01(\tR\x05where\x12\x14\n" +
+	"\x05query\x18\x04 \x01(\tR\x05query\x12\x12\n" +
+	"\x04sort\x18\x05 \x01(\tR\x04sort\"8\n" +
+	"\x11ListTodosResponse\x12#\n" +
+	"\x05todos\x18\x01 \x03(\v2\r.todo.v1.TodoR\x05todos\"4\n" +
+	"\x0eGetTodoRequest\x12\x12\n" +
+	"\x04list\x18\x01 \x01(\tR\x04list\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\x03R\x02id\"\xaa\x02\n" +
+	"\x0eAddTodoRequest\x12\x12\n" +
+	"\x04list\x18\x01 \x01(\tR\x04list\x12\x12\n" +
+	"\x04todo\x18\x02 \x01(\tR\x04todo\x12\x10\n" +
+	"\x03due\x18\x03 \x01(\tR\x03due\x12\x1a\n" +
+	"\bpriority\x18\x04 \x01(\tR\bpriority\x12\x12\n" +
+	"\x04tags\x18\x05 \x03(\tR\x04tags\x12\x18\n" +
+	"\aproject\x18\x06 \x01(\tR\aproject\x12\x1a\n" +
+	"\bcontexts\x18\a \x03(\tR\bcontexts\x12\x16\n" +
+	"\x06repeat\x18\b \x01(\tR\x06repeat\x12\x16\n" +
+	"\x06parent\x18\t \x01(\x03R\x06parent\x12\x14\n" +
+	"\x05notes\x18\n" +
+	" \x01(\tR\x05notes\x12\x16\n" +
+	"\x06remind\x18\v \x01(\tR\x06remind\x12\x1a\n" +
+	"\bestimate\x18\f \x01(\tR\bestimate\"\xc9\x04\n" +
+	"\x11UpdateTodoRequest\x12\x12\n" +
+	"\x04list\x18\x01 \x01(\tR\x04list\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\x03R\x02id\x12\x17\n" +
+	"\x04todo\x18\x03 \x01(\tH\x00R\x04todo\x88\x01\x01\x12\x15\n" +
+	"\x03due\x18\x04 \x01(\tH\x01R\x03due\x88\x01\x01\x12\x1f\n" +
+	"\bpriority\x18\x05 \x01(\tH\x02R\bpriority\x88\x01\x01\x12$\n" +
+	"\x04tags\x18\x06 \x01(\v2\x10.todo.v1.StringsR\x04tags\x12\x1d\n" +
+	"\aproject\x18\a \x01(\tH\x03R\aproject\x88\x01\x01\x12,\n" +
+	"\bcontexts\x18\b \x01(\v2\x10.todo.v1.StringsR\bcontexts\x12\x1b\n" +
+	"\x06repeat\x18\t \x01(\tH\x04R\x06repeat\x88\x01\x01\x12\x1b\n" +
+	"\x06parent\x18\n" +
+	" \x01(\x03H\x05R\x06parent\x88\x01\x01\x12\x19\n" +
+	"\x05notes\x18\v \x01(\tH\x06R\x05notes\x88\x01\x01\x12\x1b\n" +
+	"\x06status\x18\f \x01(\tH\aR\x06status\x88\x01\x01\x12!\n" +
+	"\tcompleted\x18\r \x01(\bH\bR\tcompleted\x88\x01\x01\x12\x1b\n" +
+	"\x06remind\x18\x0e \x01(\tH\tR\x06remind\x88\x01\x01\x12\x1f\n" +
+	"\bestimate\x18\x0f \x01(\tH\n" +
+	"R\bestimate\x88\x01\x01B\a\n" +
+	"\x05_todoB\x06\n" +
+	"\x04_dueB\v\n" +
+	"\t_priorityB\n" +
+	"\n" +
+	"\b_projectB\t\n" +
+	"\a_repeatB\t\n" +
+	"\a_parentB\b\n" +
+	"\x06_notesB\t\n" +
+	"\a_statusB\f\n" +
+	"\n" +
+	"_completedB\t\n" +
+	"\a_remindB\v\n" +
+	"\t_estimate\"7\n" +
+	"\x11DeleteTodoRequest\x12\x12\n" +
+	"\x04list\x18\x01 \x01(\tR\x04list\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\x03R\x02id\"\x14\n" +
+	"\x12DeleteTodoResponse\"&\n" +
+	"\x10ListTrashRequest\x12\x12\n" +
+	"\x04list\x18\x01 \x01(\tR\x04list\"8\n" +
+	"\x12RestoreTodoRequest\x12\x12\n" +
+	"\x04list\x18\x01 \x01(\tR\x04list\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\x03R\x02id\"'\n" +
+	"\x11PurgeTrashRequest\x12\x12\n" +
+	"\x04list\x18\x01 \x01(\tR\x04list\",\n" +
+	"\x12PurgeTrashResponse\x12\x16\n" +
+	"\x06purged\x18\x01 \x01(\x05R\x06purged\"'\n" +
+	"\x11WatchTodosRequest\x12\x12\n" +
+	"\x04list\x18\x01 \x01(\tR\x04list\"\x81\x02\n" +
+	"\tTodoEvent\x12+\n" +
+	"\x04type\x18\x01 \x01(\x0e2\x17.todo.v1.TodoEvent.TypeR\x04type\x12!\n" +
+	"\x04todo\x18\x02 \x01(\v2\r.todo.v1.TodoR\x04todo\x12.\n" +
+	"\x04time\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\"t\n" +
+	"\x04Type\x12\x14\n" +
+	"\x10TYPE_UNSPECIFIED\x10\x00\x12\x0e\n" +
+	"\n" +
+	"TYPE_ADDED\x10\x01\x12\x10\n" +
+	"\fTYPE_UPDATED\x10\x02\x12\x10\n" +
+	"\fTYPE_DELETED\x10\x03\x12\x11\n" +
+	"\rTYPE_RESTORED\x10\x04\x12\x0f\n" +
+	"\vTYPE_PURGED\x10\x052\xbd\x04\n" +
+	"\vTodoService\x12B\n" +
+	"\tListTodos\x12\x19.todo.v1.ListTodosRequest\x1a\x1a.todo.v1.ListTodosResponse\x121\n" +
+	"\aGetTodo\x12\x17.todo.v1.GetTodoRequest\x1a\r.todo.v1.Todo\x121\n" +
+	"\aAddTodo\x12\x17.todo.v1.AddTodoRequest\x1a\r.todo.v1.Todo\x127\n" +
+	"\n" +
+	"UpdateTodo\x12\x1a.todo.v1.UpdateTodoRequest\x1a\r.todo.v1.Todo\x12E\n" +
+	"\n" +
+	"DeleteTodo\x12\x1a.todo.v1.DeleteTodoRequest\x1a\x1b.todo.v1.DeleteTodoResponse\x12B\n" +
+	"\tListTrash\x12\x19.todo.v1.ListTrashRequest\x1a\x1a.todo.v1.ListTodosResponse\x129\n" +
+	"\vRestoreTodo\x12\x1b.todo.v1.RestoreTodoRequest\x1a\r.todo.v1.Todo\x12E\n" +
+	"\n" +
+	"PurgeTrash\x12\x1a.todo.v1.PurgeTrashRequest\x1a\x1b.todo.v1.PurgeTrashResponse\x12>\n" +
+	"\n" +
+	"WatchTodos\x12\x1a.todo.v1.WatchTodosRequest\x1a\x12.todo.v1.TodoEvent0\x01B1Z/github.com/buck06191/todo-app/pkg/server/todopbb\x06proto3"
+
+var (
+	file_todo_proto_rawDescOnce sync.Once
+	file_todo_proto_rawDescData []byte
+)
+
+func file_todo_proto_rawDescGZIP() []byte {
+	file_todo_proto_rawDescOnce.Do(func() {
+		file_todo_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_todo_proto_rawDesc), len(file_todo_proto_rawDesc)))
+	})
+	return file_todo_proto_rawDescData
+}
+
+var file_todo_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_todo_proto_msgTypes = make([]protoimpl.MessageInfo, 16)
+var file_todo_proto_goTypes = []any{
+	(TodoEvent_Type)(0),           // 0: todo.v1.TodoEvent.Type
+	(*Todo)(nil),                  // 1: todo.v1.Todo
+	(*Interval)(nil),              // 2: todo.v1.Interval
+	(*Strings)(nil),               // 3: todo.v1.Strings
+	(*ListTodosRequest)(nil),      // 4: todo.v1.ListTodosRequest
+	(*ListTodosResponse)(nil),     // 5: todo.v1.ListTodosResponse
+	(*GetTodoRequest)(nil),        // 6: todo.v1.GetTodoRequest
+	(*AddTodoRequest)(nil),        // 7: todo.v1.AddTodoRequest
+	(*UpdateTodoRequest)(nil),     // 8: todo.v1.UpdateTodoRequest
+	(*DeleteTodoRequest)(nil),     // 9: todo.v1.DeleteTodoRequest
+	(*DeleteTodoResponse)(nil),    // 10: todo.v1.DeleteTodoResponse
+	(*ListTrashRequest)(nil),      // 11: todo.v1.ListTrashRequest
+	(*RestoreTodoRequest)(nil),    // 12: todo.v1.RestoreTodoRequest
+	(*PurgeTrashRequest)(nil),     // 13: todo.v1.PurgeTrashRequest
+	(*PurgeTrashResponse)(nil),    // 14: todo.v1.PurgeTrashResponse
+	(*WatchTodosRequest)(nil),     // 15: todo.v1.WatchTodosRequest
+	(*TodoEvent)(nil),             // 16: todo.v1.TodoEvent
+	(*timestamppb.Timestamp)(nil), // 17: google.protobuf.Timestamp
+}
+var file_todo_proto_depIdxs = []int32{
+	17, // 0: todo.v1.Todo.due:type_name -> google.protobuf.Timestamp
+	17, // 1: todo.v1.Todo.created_at:type_name -> google.protobuf.Timestamp
+	17, // 2: todo.v1.Todo.completed_at:type_name -> google.protobuf.Timestamp
+	17, // 3: todo.v1.Todo.deleted_at:type_name -> google.protobuf.Timestamp
+	2,  // 4: todo.v1.Todo.worked:type_name -> todo.v1.Interval
+	17, // 5: todo.v1.Interval.start:type_name -> google.protobuf.Timestamp
+	17, // 6: todo.v1.Interval.end:type_name -> google.protobuf.Timestamp
+	1,  // 7: todo.v1.ListTodosResponse.todos:type_name -> todo.v1.Todo
+	3,  // 8: todo.v1.UpdateTodoRequest.tags:type_name -> todo.v1.Strings
+	3,  // 9: todo.v1.UpdateTodoRequest.contexts:type_name -> todo.v1.Strings
+	0,  // 10: todo.v1.TodoEvent.type:type_name -> todo.v1.TodoEvent.Type
+	1,  // 11: todo.v1.TodoEvent.todo:type_name -> todo.v1.Todo
+	17, // 12: todo.v1.TodoEvent.time:type_name -> google.protobuf.Timestamp
+	4,  // 13: todo.v1.TodoService.ListTodos:input_type -> todo.v1.ListTodosRequest
+	6,  // 14: todo.v1.TodoService.GetTodo:input_type -> todo.v1.GetTodoRequest
+	7,  // 15: todo.v1.TodoService.AddTodo:input_type -> todo.v1.AddTodoRequest
+	8,  // 16: todo.v1.TodoService.UpdateTodo:input_type -> todo.v1.UpdateTodoRequest
+	9,  // 17: todo.v1.TodoService.DeleteTodo:input_type -> todo.v1.DeleteTodoRequest
+	11, // 18: todo.v1.TodoService.ListTrash:input_type -> todo.v1.ListTrashRequest
+	12, // 19: todo.v1.TodoService.RestoreTodo:input_type -> todo.v1.RestoreTodoRequest
+	13, // 20: todo.v1.TodoService.PurgeTrash:input_type -> todo.v1.PurgeTrashRequest
+	15, // 21: todo.v1.TodoService.WatchTodos:input_type -> todo.v1.WatchTodosRequest
+	5,  // 22: todo.v1.TodoService.ListTodos:output_type -> todo.v1.ListTodosResponse
+	1,  // 23: todo.v1.TodoService.GetTodo:output_type -> todo.v1.Todo
+	1,  // 24: todo.v1.TodoService.AddTodo:output_type -> todo.v1.Todo
+	1,  // 25: todo.v1.TodoService.UpdateTodo:output_type -> todo.v1.Todo
+	10, // 26: todo.v1.TodoService.DeleteTodo:output_type -> todo.v1.DeleteTodoResponse
+	5,  // 27: todo.v1.TodoService.ListTrash:output_type -> todo.v1.ListTodosResponse
+	1,  // 28: todo.v1.TodoService.RestoreTodo:output_type -> todo.v1.Todo
+	14, // 29: todo.v1.TodoService.PurgeTrash:output_type -> todo.v1.PurgeTrashResponse
+	16, // 30: todo.v1.TodoService.WatchTodos:output_type -> todo.v1.TodoEvent
+	22, // [22:31] is the sub-list for method output_type
+	13, // [13:22] is the sub-list for method input_type
+	13, // [13:13] is the sub-list for extension type_name
+	13, // [13:13] is the sub-list for extension extendee
+	0,  // [0:13] is the sub-list for field type_name
+}
+
+func init() { file_todo_proto_init() }
+func file_todo_proto_init() {
+	if File_todo_proto != nil {
+		return
+	}
+	file_todo_proto_msgTypes[7].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_todo_proto_rawDesc), len(file_todo_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   16,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_todo_proto_goTypes,
+		DependencyIndexes: file_todo_proto_depIdxs,
+		EnumInfos:         file_todo_proto_enumTypes,
+		MessageInfos:      file_todo_proto_msgTypes,
+	}.Build()
+	File_todo_proto = out.File
+	file_todo_proto_goTypes = nil
+	file_todo_proto_depIdxs = nil
+}
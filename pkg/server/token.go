@@ -0,0 +1,232 @@
+package server
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/buck06191/todo-app/pkg/todo"
+)
+
+// Errors returned when managing tokens.
+var (
+	ErrBadToken = errors.New("bad token")
+	ErrNoToken  = errors.New("no such token")
+)
+
+// Scopes a token can be given. ScopeRead allows GET requests and
+// ScopeWrite everything else.
+const (
+	ScopeRead  = "read"
+	ScopeWrite = "write"
+)
+
+// Scopes lists every scope, in the order they are shown.
+var Scopes = []string{ScopeRead, ScopeWrite}
+
+// tokensKey is the todo.MetaStore key tokens are saved under.
+const tokensKey = "tokens"
+
+// tokenPrefix starts every token, so they are easy to spot if one is
+// pasted somewhere it shouldn't be.
+const tokenPrefix = "todo_"
+
+// Token is an API token saved in a store. Only a hash of the secret is
+// kept; the secret itself is shown once, when the token is created. A token
+// created for a User only reaches that user's list, while one without gets
+// the store's own list.
+type Token struct {
+	Name      string    `json:"name"`
+	User      string    `json:"user,omitempty"`
+	Hash      string    `json:"hash"`
+	Scopes    []string  `json:"scopes"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Allows reports whether the token has scope.
+func (t Token) Allows(scope string) bool {
+	return slices.Contains(t.Scopes, scope)
+}
+
+// Tokens returns the tokens saved in store, sorted by name. Stores that
+// aren't MetaStores have none.
+func Tokens(store todo.Store) ([]Token, error) {
+	var tokens []Token
+	err := todo.LoadMeta(store, tokensKey, &tokens)
+	if errors.Is(err, todo.ErrNoMeta) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+// CreateToken saves a new token called name with the given scopes, or read
+// and write if there are none, and returns its secret. Names are made of
+// letters, digits, - and _, and have to be unique. If user isn't empty the
+// token is for the user of that name, who has to exist.
+func CreateToken(store todo.Store, name, user string, scopes []string) (string, error) {
+	if !validName(name) {
+		return "", fmt.Errorf("%w: %q isn't a valid name, use letters, digits, - and _", ErrBadToken, name)
+	}
+	if user != "" {
+		if err := checkUser(store, user); err != nil {
+			return "", err
+		}
+	}
+	if len(scopes) == 0 {
+		scopes = Scopes
+	}
+	for _, scope := range scopes {
+		if !slices.Contains(Scopes, scope) {
+			return "", fmt.Errorf("%w: unknown scope %q, expected one of %s", ErrBadToken, scope, strings.Join(Scopes, ", "))
+		}
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	token := tokenPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	err := updateTokens(store, func(tokens []Token) ([]Token, error) {
+		for _, t := range tokens {
+			if t.Name == name {
+				return nil, fmt.Errorf("%w: there is already a token called %q", ErrBadToken, name)
+			}
+		}
+		tokens = append(tokens, Token{
+			Name:      name,
+			User:      user,
+			Hash:      hashToken(token),
+			Scopes:    slices.Clone(scopes),
+			CreatedAt: time.Now(),
+		})
+		slices.SortFunc(tokens, func(a, b Token) int { return strings.Compare(a.Name, b.Name) })
+		return tokens, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// RevokeToken deletes the token called name, so it can't be used again.
+func RevokeToken(store todo.Store, name string) error {
+	return updateTokens(store, func(tokens []Token) ([]Token, error) {
+		i := slices.IndexFunc(tokens, func(t Token) bool { return t.Name == name })
+		if i < 0 {
+			return nil, fmt.Errorf("%w called %q", ErrNoToken, name)
+		}
+		return slices.Delete(tokens, i, i+1), nil
+	})
+}
+
+func updateTokens(store todo.Store, fn func(tokens []Token) ([]Token, error)) error {
+	tokens, err := Tokens(store)
+	if err != nil {
+		return err
+	}
+	if tokens, err = fn(tokens); err != nil {
+		return err
+	}
+	return todo.SaveMeta(store, tokensKey, tokens)
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// validName reports whether name can be used for a token or user.
+func validName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+// AllowTokens makes s accept each of secrets as a token with every scope
+// for the store's own list, as well as the tokens saved in the store, but
+// without saving them there, as for tokens given in the environment. It
+// has to be called before s starts serving.
+func (s *Server) AllowTokens(secrets ...string) {
+	for i, secret := range secrets {
+		s.tokens = append(s.tokens, Token{Name: fmt.Sprintf("given-%d", i+1), Hash: hashToken(secret), Scopes: Scopes})
+	}
+}
+
+// authorize checks the bearer token of an API request, as for
+// authenticate.
+func (s *Server) authorize(r *http.Request) (string, error) {
+	token, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return s.authenticate(token, !readOnly(r))
+}
+
+// authenticate checks the token a request was made with against the tokens
+// in the store and those given to AllowTokens, and returns the user it was
+// created for, which is empty for a token without one. write is set for
+// requests that change things. Until there is a token, every request is
+// allowed and made by nobody in particular.
+func (s *Server) authenticate(token string, write bool) (string, error) {
+	tokens, err := s.allTokens()
+	if err != nil || len(tokens) == 0 {
+		return "", err
+	}
+
+	unauthorized := &requestError{status: http.StatusUnauthorized}
+	if strings.TrimSpace(token) == "" {
+		unauthorized.err = errors.New("this needs an API token, sent as Authorization: Bearer <token>")
+		return "", unauthorized
+	}
+	t, ok := findToken(tokens, token)
+	if !ok {
+		unauthorized.err = errors.New("unknown API token")
+		return "", unauthorized
+	}
+	scope := ScopeRead
+	if write {
+		scope = ScopeWrite
+	}
+	if !t.Allows(scope) {
+		return "", &requestError{status: http.StatusForbidden, err: fmt.Errorf("token %q doesn't have the %s scope", t.Name, scope)}
+	}
+	return t.User, nil
+}
+
+// allTokens returns the tokens in the store and those given to
+// AllowTokens.
+func (s *Server) allTokens() ([]Token, error) {
+	tokens, err := Tokens(s.store)
+	return append(tokens, s.tokens...), err
+}
+
+// findToken returns the one of tokens that token is the secret of.
+func findToken(tokens []Token, token string) (Token, bool) {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return Token{}, false
+	}
+	hash := hashToken(token)
+	for _, t := range tokens {
+		if subtle.ConstantTimeCompare([]byte(hash), []byte(t.Hash)) == 1 {
+			return t, true
+		}
+	}
+	return Token{}, false
+}
@@ -0,0 +1,121 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/buck06191/todo-app/pkg/todo"
+)
+
+// Errors returned when managing users.
+var (
+	ErrBadUser = errors.New("bad user")
+	ErrNoUser  = errors.New("no such user")
+)
+
+// usersKey is the todo.MetaStore key users are saved under.
+const usersKey = "users"
+
+// userPrefix starts the name of the namespace each user's list is kept in.
+const userPrefix = "users/"
+
+// User is someone with a list of their own on the server. Their items are
+// kept in a namespace of the store, and the tokens created for them only
+// reach that list.
+type User struct {
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Users returns the users saved in store, sorted by name. Stores that
+// aren't MetaStores have none.
+func Users(store todo.Store) ([]User, error) {
+	var users []User
+	err := todo.LoadMeta(store, usersKey, &users)
+	if errors.Is(err, todo.ErrNoMeta) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading users: %w", err)
+	}
+	return users, nil
+}
+
+// AddUser saves a new user called name, with an empty list. Names are made
+// of letters, digits, - and _, and have to be unique. The store has to be
+// able to hold separate lists.
+func AddUser(store todo.Store, name string) error {
+	if !validName(name) {
+		return fmt.Errorf("%w: %q isn't a valid name, use letters, digits, - and _", ErrBadUser, name)
+	}
+	if _, ok := store.(todo.Namespacer); !ok {
+		return todo.ErrNoNamespaces
+	}
+	return updateUsers(store, func(users []User) ([]User, error) {
+		for _, u := range users {
+			if u.Name == name {
+				return nil, fmt.Errorf("%w: there is already a user called %q", ErrBadUser, name)
+			}
+		}
+		users = append(users, User{Name: name, CreatedAt: time.Now()})
+		slices.SortFunc(users, func(a, b User) int { return strings.Compare(a.Name, b.Name) })
+		return users, nil
+	})
+}
+
+// DeleteUser deletes the user called name along with their list and
+// tokens, and takes them out of every shared list.
+func DeleteUser(store todo.Store, name string) error {
+	err := updateUsers(store, func(users []User) ([]User, error) {
+		i := slices.IndexFunc(users, func(u User) bool { return u.Name == name })
+		if i < 0 {
+			return nil, fmt.Errorf("%w called %q", ErrNoUser, name)
+		}
+		return slices.Delete(users, i, i+1), nil
+	})
+	if err != nil {
+		return err
+	}
+
+	err = updateTokens(store, func(tokens []Token) ([]Token, error) {
+		return slices.DeleteFunc(tokens, func(t Token) bool { return t.User == name }), nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := leaveShares(store, name); err != nil {
+		return err
+	}
+	if ns, ok := store.(todo.Namespacer); ok {
+		return ns.DropNamespace(userPrefix + name)
+	}
+	return nil
+}
+
+// UserStore returns the list of the user called name in store.
+func UserStore(store todo.Store, name string) (todo.Store, error) {
+	return todo.Namespace(store, userPrefix+name)
+}
+
+// hasUser reports whether store has a user called name.
+func hasUser(store todo.Store, name string) (bool, error) {
+	users, err := Users(store)
+	if err != nil {
+		return false, err
+	}
+	return slices.ContainsFunc(users, func(u User) bool { return u.Name == name }), nil
+}
+
+func updateUsers(store todo.Store, fn func(users []User) ([]User, error)) error {
+	users, err := Users(store)
+	if err != nil {
+		return err
+	}
+	if users, err = fn(users); err != nil {
+		return err
+	}
+	return todo.SaveMeta(store, usersKey, users)
+}
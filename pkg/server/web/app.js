@@ -0,0 +1,144 @@
+// A small client for the JSON API in pkg/server. It reloads the list after
+// every change and every few seconds, so several people can share it.
+"use strict";
+
+const list = document.getElementById("items");
+const empty = document.getElementById("empty");
+const error = document.getElementById("error");
+const showDone = document.getElementById("show-done");
+let declined = false;
+
+async function api(method, path, body) {
+  const opts = { method, headers: {} };
+  if (body !== undefined) {
+    opts.headers["Content-Type"] = "application/json";
+    opts.body = JSON.stringify(body);
+  }
+  const token = localStorage.getItem("token");
+  if (token) {
+    opts.headers["Authorization"] = "Bearer " + token;
+  }
+  const resp = await fetch(path, opts);
+  if (resp.status === 401 && !declined) {
+    // Ask for a token, from `todo-app token create`, and try again. If
+    // that is cancelled, don't keep asking on every reload.
+    localStorage.removeItem("token");
+    const entered = prompt("API token:", "");
+    if (entered) {
+      localStorage.setItem("token", entered.trim());
+      return api(method, path, body);
+    }
+    declined = true;
+  }
+  if (resp.status === 204) {
+    return null;
+  }
+  const data = await resp.json();
+  if (!resp.ok) {
+    throw new Error(data.error || resp.statusText);
+  }
+  return data;
+}
+
+function showError(err) {
+  error.textContent = err ? err.message : "";
+  error.hidden = !err;
+}
+
+function formatDue(item) {
+  const due = new Date(item.due);
+  const allDay = item.due.includes("T00:00:00");
+  const opts = allDay
+    ? { weekday: "short", day: "numeric", month: "short" }
+    : { weekday: "short", day: "numeric", month: "short", hour: "2-digit", minute: "2-digit" };
+  return due.toLocaleString(undefined, opts);
+}
+
+function isOverdue(item) {
+  if (!item.due || item.completed) {
+    return false;
+  }
+  const due = new Date(item.due);
+  if (item.due.includes("T00:00:00")) {
+    due.setDate(due.getDate() + 1);
+  }
+  return due <= new Date();
+}
+
+function render(items) {
+  list.replaceChildren();
+  for (const item of items) {
+    const li = document.createElement("li");
+    li.classList.toggle("done", !!item.completed);
+    li.classList.toggle("overdue", isOverdue(item));
+
+    const check = document.createElement("input");
+    check.type = "checkbox";
+    check.checked = !!item.completed;
+    check.addEventListener("change", () =>
+      change(() => api("PATCH", `todos/${item.id}`, { completed: check.checked })));
+
+    const text = document.createElement("span");
+    text.className = "text";
+    text.textContent = item.todo;
+
+    const labels = [];
+    if (item.project) labels.push("+" + item.project);
+    for (const c of item.contexts || []) labels.push("@" + c);
+    for (const t of item.tags || []) labels.push("#" + t);
+    if (labels.length) {
+      const tags = document.createElement("span");
+      tags.className = "tags";
+      tags.textContent = " " + labels.join(" ");
+      text.append(tags);
+    }
+
+    const due = document.createElement("span");
+    due.className = "due";
+    due.textContent = item.due ? formatDue(item) : "";
+
+    const del = document.createElement("button");
+    del.className = "delete";
+    del.title = "Move to the trash";
+    del.textContent = "✕";
+    del.addEventListener("click", () => change(() => api("DELETE", `todos/${item.id}`)));
+
+    li.append(check, text, due, del);
+    list.append(li);
+  }
+  empty.hidden = items.length > 0;
+}
+
+async function load() {
+  try {
+    render(await api("GET", "todos" + (showDone.checked ? "?all=1" : "")));
+    showError(null);
+  } catch (err) {
+    showError(err);
+  }
+}
+
+async function change(fn) {
+  try {
+    await fn();
+    showError(null);
+  } catch (err) {
+    showError(err);
+  }
+  await load();
+}
+
+document.getElementById("add").addEventListener("submit", (event) => {
+  event.preventDefault();
+  const text = document.getElementById("text");
+  const due = document.getElementById("due");
+  change(async () => {
+    await api("POST", "todos", { todo: text.value, due: due.value });
+    text.value = "";
+    due.value = "";
+  });
+});
+
+showDone.addEventListener("change", load);
+setInterval(load, 5000);
+load();
@@ -0,0 +1,189 @@
+// Package slack posts to Slack and answers its slash commands: Digest sums
+// up what is due today as a message for an incoming webhook, and
+// ParseCommand and Verify read the requests Slack sends when someone uses a
+// slash command such as /todo, which `todo-app serve` answers.
+package slack
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/buck06191/todo-app/pkg/todo"
+)
+
+// Errors returned by Verify.
+var (
+	ErrUnsigned  = errors.New("the request isn't signed by Slack")
+	ErrBadSigned = errors.New("the request's Slack signature is wrong")
+	ErrStale     = errors.New("the request's Slack timestamp is too old")
+)
+
+// Headers Slack signs its requests with.
+const (
+	SignatureHeader = "X-Slack-Signature"
+	TimestampHeader = "X-Slack-Request-Timestamp"
+)
+
+// maxAge is how old a signed request can be before it is turned away, as
+// Slack recommends, so that one can't be replayed later.
+const maxAge = 5 * time.Minute
+
+// timeout is how long Slack has to answer a post.
+const timeout = 10 * time.Second
+
+// Where a slash command's answer is shown.
+const (
+	// InChannel answers are shown to everyone in the channel.
+	InChannel = "in_channel"
+	// Ephemeral answers are only shown to whoever used the command.
+	Ephemeral = "ephemeral"
+)
+
+// Message is a message posted to Slack, or the answer to a slash command.
+// Text is in Slack's mrkdwn.
+type Message struct {
+	ResponseType string `json:"response_type,omitempty"`
+	Text         string `json:"text"`
+}
+
+// Command is a slash command someone used, as Slack sends it.
+type Command struct {
+	// Command is the command itself, such as "/todo".
+	Command string
+	// Text is everything typed after it.
+	Text     string
+	UserID   string
+	UserName string
+	Channel  string
+}
+
+// ParseCommand reads the form Slack posts for a slash command.
+func ParseCommand(body []byte) (Command, error) {
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		return Command{}, fmt.Errorf("reading the slash command: %w", err)
+	}
+	return Command{
+		Command:  form.Get("command"),
+		Text:     strings.TrimSpace(form.Get("text")),
+		UserID:   form.Get("user_id"),
+		UserName: form.Get("user_name"),
+		Channel:  form.Get("channel_name"),
+	}, nil
+}
+
+// Sign returns the signature of body sent at timestamp with the app's
+// signing secret, as it goes in SignatureHeader.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "v0:%s:", timestamp)
+	mac.Write(body)
+	return "v0=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks that a request with header and body was signed with the
+// app's signing secret and was sent no longer ago than Slack allows.
+func Verify(secret string, header http.Header, body []byte, now time.Time) error {
+	timestamp, signature := header.Get(TimestampHeader), header.Get(SignatureHeader)
+	if timestamp == "" || signature == "" {
+		return ErrUnsigned
+	}
+	sent, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrBadSigned
+	}
+	if age := now.Sub(time.Unix(sent, 0)); age > maxAge || age < -maxAge {
+		return ErrStale
+	}
+	if !hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature)) {
+		return ErrBadSigned
+	}
+	return nil
+}
+
+// Post sends m to an incoming webhook, failing unless Slack answers with a
+// 2xx status.
+func Post(webhook string, m Message) error {
+	body, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	resp, err := (&http.Client{Timeout: timeout}).Post(webhook, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		if msg = bytes.TrimSpace(msg); len(msg) > 0 {
+			return fmt.Errorf("slack answered %s: %s", resp.Status, msg)
+		}
+		return fmt.Errorf("slack answered %s", resp.Status)
+	}
+	return nil
+}
+
+// Digest is the message summing up the day of now: the overdue items
+// given, then those due today, as todo.Agenda returns them.
+func Digest(overdue, today []todo.ParsedTodoItem, now time.Time) Message {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%s*\n", now.In(todo.Location).Format("Monday 2 January"))
+	if len(overdue) > 0 {
+		b.WriteString("*Overdue*\n")
+		y, m, d := now.In(todo.Location).Date()
+		for _, item := range overdue {
+			// Items due earlier today are told apart by the time alone.
+			layout := "Mon 2 Jan"
+			if dy, dm, dd := item.Due.In(todo.Location).Date(); dy == y && dm == m && dd == d {
+				layout = "15:04"
+			}
+			b.WriteString(Line(item, "due "+item.Due.In(todo.Location).Format(layout)) + "\n")
+		}
+	}
+	if len(today) == 0 {
+		b.WriteString("Nothing is due today.")
+		return Message{Text: b.String()}
+	}
+	b.WriteString("*Due today*\n")
+	for _, item := range today {
+		when := ""
+		if !item.DueAllDay() {
+			when = item.Due.In(todo.Location).Format("15:04")
+		}
+		b.WriteString(Line(item, when) + "\n")
+	}
+	return Message{Text: strings.TrimSuffix(b.String(), "\n")}
+}
+
+// Line is an item as a bullet of a message, with when it is due, if that
+// isn't empty, and its priority.
+func Line(item todo.ParsedTodoItem, when string) string {
+	line := fmt.Sprintf("• `#%d` %s", item.ID, Escape(item.Todo))
+	var about []string
+	if when != "" {
+		about = append(about, when)
+	}
+	if item.Priority != todo.PriorityNone {
+		about = append(about, item.Priority.String()+" priority")
+	}
+	if len(about) > 0 {
+		line += " _(" + strings.Join(about, ", ") + ")_"
+	}
+	return line
+}
+
+// Escape escapes the characters Slack reads as markup in s.
+func Escape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
@@ -0,0 +1,159 @@
+// Package telegram is a small client for the Telegram Bot API, enough for
+// `todo-app telegram` to read the messages sent to a bot by long polling
+// and answer them.
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultAPI is the Bot API server bots use unless they run their own.
+const DefaultAPI = "https://api.telegram.org"
+
+// PollTimeout is how long Updates waits for a message before returning
+// none.
+const PollTimeout = 50 * time.Second
+
+// ErrUnauthorized is returned when the bot's token is turned down.
+var ErrUnauthorized = errors.New("telegram doesn't accept the bot token")
+
+// Client talks to the Bot API as the bot with Token.
+type Client struct {
+	Token string
+	// API is the Bot API server, or DefaultAPI if it is empty.
+	API  string
+	http http.Client
+}
+
+// NewClient returns a client for the bot with token, on api, or on
+// DefaultAPI if api is empty.
+func NewClient(token, api string) *Client {
+	if api == "" {
+		api = DefaultAPI
+	}
+	return &Client{Token: token, API: strings.TrimSuffix(api, "/"), http: http.Client{Timeout: PollTimeout + 10*time.Second}}
+}
+
+// User is a Telegram user or bot.
+type User struct {
+	ID        int64  `json:"id"`
+	Username  string `json:"username"`
+	FirstName string `json:"first_name"`
+}
+
+// Chat is a conversation with the bot, private or in a group.
+type Chat struct {
+	ID    int64  `json:"id"`
+	Type  string `json:"type"`
+	Title string `json:"title"`
+}
+
+// Message is a message sent to the bot.
+type Message struct {
+	ID   int64  `json:"message_id"`
+	From *User  `json:"from"`
+	Chat Chat   `json:"chat"`
+	Text string `json:"text"`
+}
+
+// Update is something that happened to the bot. Only messages are asked
+// for.
+type Update struct {
+	ID      int64    `json:"update_id"`
+	Message *Message `json:"message"`
+}
+
+// response is the envelope every Bot API method answers with.
+type response struct {
+	OK          bool            `json:"ok"`
+	Result      json.RawMessage `json:"result"`
+	ErrorCode   int             `json:"error_code"`
+	Description string          `json:"description"`
+}
+
+// call runs method with params sent as JSON and reads its result into
+// result.
+func (c *Client) call(ctx context.Context, method string, params, result any) error {
+	body, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	u := c.API + "/bot" + url.PathEscape(c.Token) + "/" + method
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.http.Do(req)
+	if err != nil {
+		// The URL holds the token, so it is left out of the error.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("telegram %s: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	var r response
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return fmt.Errorf("telegram %s: %s", method, resp.Status)
+	}
+	if !r.OK {
+		if r.ErrorCode == http.StatusUnauthorized || r.ErrorCode == http.StatusNotFound {
+			return ErrUnauthorized
+		}
+		return fmt.Errorf("telegram %s: %s", method, r.Description)
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(r.Result, result)
+}
+
+// Me returns the bot itself, checking its token.
+func (c *Client) Me(ctx context.Context) (User, error) {
+	var me User
+	err := c.call(ctx, "getMe", struct{}{}, &me)
+	return me, err
+}
+
+// Updates waits up to PollTimeout for messages with an update ID of offset
+// or more, returning those there are. Passing one more than the last ID
+// seen tells Telegram those before it have been dealt with.
+func (c *Client) Updates(ctx context.Context, offset int64) ([]Update, error) {
+	var updates []Update
+	err := c.call(ctx, "getUpdates", map[string]any{
+		"offset":          offset,
+		"timeout":         int(PollTimeout / time.Second),
+		"allowed_updates": []string{"message"},
+	}, &updates)
+	return updates, err
+}
+
+// Send sends text to the chat with the given ID, as plain text.
+func (c *Client) Send(ctx context.Context, chat int64, text string) error {
+	return c.call(ctx, "sendMessage", map[string]any{"chat_id": chat, "text": text}, nil)
+}
+
+// Command splits a message such as "/add@todo_bot Buy milk" into the
+// command, "add", and the rest of the text. Messages that aren't commands
+// have an empty command.
+func Command(text string) (command, rest string) {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "/") {
+		return "", text
+	}
+	command, rest, _ = strings.Cut(text[1:], " ")
+	// In groups, commands are followed by the name of the bot they are for.
+	command, _, _ = strings.Cut(command, "@")
+	return strings.ToLower(command), strings.TrimSpace(rest)
+}
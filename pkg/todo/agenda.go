@@ -0,0 +1,79 @@
+package todo
+
+import "time"
+
+// AgendaDay is one day of an agenda and the items due on it, in the order
+// of SortByDue.
+type AgendaDay struct {
+	// Date is midnight at the start of the day, in Location.
+	Date  time.Time
+	Items []ParsedTodoItem
+}
+
+// Agenda puts the items that aren't done on the days they are due, for the
+// given number of days starting with the day from is in. Items that are
+// overdue at now are returned separately rather than on their day, so they
+// can be shown first, whether or not they fall within the agenda.
+func Agenda(items []ParsedTodoItem, from time.Time, days int, now time.Time) (overdue []ParsedTodoItem, agenda []AgendaDay) {
+	start := startOfDay(from)
+	agenda = make([]AgendaDay, days)
+	for i := range agenda {
+		agenda[i].Date = start.AddDate(0, 0, i)
+	}
+
+	sorted := make([]ParsedTodoItem, len(items))
+	copy(sorted, items)
+	SortByDue(sorted)
+
+	for _, item := range sorted {
+		if item.Completed || item.Due.IsZero() {
+			continue
+		}
+		if item.IsOverdue(now) {
+			overdue = append(overdue, item)
+			continue
+		}
+		day := startOfDay(item.Due)
+		// Count calendar days rather than dividing durations, which are
+		// off by an hour across daylight saving changes.
+		for i := range agenda {
+			if agenda[i].Date.Equal(day) {
+				agenda[i].Items = append(agenda[i].Items, item)
+				break
+			}
+		}
+	}
+	return overdue, agenda
+}
+
+// WeekStart returns midnight at the start of the Monday of the week t is
+// in, in Location.
+func WeekStart(t time.Time) time.Time {
+	day := startOfDay(t)
+	return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+}
+
+// MonthStart returns midnight at the start of the first day of the month t
+// is in, in Location.
+func MonthStart(t time.Time) time.Time {
+	y, m, _ := t.In(Location).Date()
+	return time.Date(y, m, 1, 0, 0, 0, 0, Location)
+}
+
+// MonthGrid returns the first day and number of days of a calendar for the
+// month t is in: whole weeks, Monday to Sunday, covering the month.
+func MonthGrid(t time.Time) (from time.Time, days int) {
+	first := MonthStart(t)
+	last := first.AddDate(0, 1, -1)
+	from = WeekStart(first)
+	to := WeekStart(last).AddDate(0, 0, 7)
+	for d := from; d.Before(to); d = d.AddDate(0, 0, 1) {
+		days++
+	}
+	return from, days
+}
+
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.In(Location).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, Location)
+}
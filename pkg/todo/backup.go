@@ -0,0 +1,226 @@
+package todo
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"time"
+)
+
+// snapshotVersion is the version of the format WriteSnapshot writes.
+const snapshotVersion = 1
+
+// Snapshot is everything in a store at one time, as WriteSnapshot writes
+// it: the items, trash and settings of each of its lists, by name, with ""
+// for the store's own.
+type Snapshot struct {
+	Version   int                     `json:"version"`
+	CreatedAt time.Time               `json:"created_at"`
+	Lists     map[string]snapshotList `json:"lists"`
+}
+
+type snapshotList struct {
+	Items []ParsedTodoItem `json:"items"`
+	Trash []ParsedTodoItem `json:"trash,omitempty"`
+	// Meta values are kept as bytes, which JSON gives as base64, since
+	// they aren't all JSON in every store.
+	Meta map[string][]byte `json:"meta,omitempty"`
+}
+
+// Items returns how many items there are in the snapshot, including those
+// in the trash, across every list.
+func (s *Snapshot) Items() int {
+	n := 0
+	for _, list := range s.Lists {
+		n += len(list.Items) + len(list.Trash)
+	}
+	return n
+}
+
+// WriteSnapshot writes everything in store, including its other lists if
+// it is a Namespacer, to w as gzipped JSON, returning the snapshot written.
+func WriteSnapshot(w io.Writer, store Store) (*Snapshot, error) {
+	s := &Snapshot{Version: snapshotVersion, CreatedAt: time.Now(), Lists: map[string]snapshotList{}}
+	names := []string{""}
+	if ns, ok := store.(Namespacer); ok {
+		others, err := ns.Namespaces()
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, others...)
+	}
+	for _, name := range names {
+		list, err := Namespace(store, name)
+		if err != nil {
+			return nil, err
+		}
+		saved, err := snapshotOf(list)
+		if err != nil {
+			return nil, fmt.Errorf("list %q: %w", name, err)
+		}
+		s.Lists[name] = saved
+	}
+
+	gz := gzip.NewWriter(w)
+	if err := json.NewEncoder(gz).Encode(s); err != nil {
+		return nil, err
+	}
+	return s, gz.Close()
+}
+
+func snapshotOf(list Store) (snapshotList, error) {
+	var s snapshotList
+	var err error
+	if s.Items, err = list.List(); err != nil {
+		return s, err
+	}
+	if s.Trash, err = list.Trash(); err != nil {
+		return s, err
+	}
+	meta, ok := list.(MetaStore)
+	if !ok {
+		return s, nil
+	}
+	keys, err := meta.MetaKeys()
+	if err != nil {
+		return s, err
+	}
+	s.Meta = map[string][]byte{}
+	for _, key := range keys {
+		if s.Meta[key], err = meta.GetMeta(key); err != nil {
+			return s, err
+		}
+	}
+	return s, nil
+}
+
+// ReadSnapshot reads a snapshot written by WriteSnapshot.
+func ReadSnapshot(r io.Reader) (*Snapshot, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("not a backup: %w", err)
+	}
+	var s Snapshot
+	if err := json.NewDecoder(gz).Decode(&s); err != nil {
+		return nil, fmt.Errorf("not a backup: %w", err)
+	}
+	if s.Version != snapshotVersion {
+		return nil, fmt.Errorf("backup is of version %d, which this todo-app can't read", s.Version)
+	}
+	return &s, nil
+}
+
+// RestoreTo replaces everything in store with what is in the snapshot:
+// each list is emptied and given the items, trash and settings it had,
+// keeping their IDs, and lists that weren't there are dropped. store must
+// be an Importer and a Remover, and a Namespacer if the snapshot has other
+// lists than its own. The snapshot is checked before anything is changed,
+// and each list's items are swapped in one write if the store is a
+// Replacer.
+func (s *Snapshot) RestoreTo(store Store) error {
+	ns, isNamespacer := store.(Namespacer)
+	for _, name := range slices.Sorted(maps.Keys(s.Lists)) {
+		if name != "" && !isNamespacer {
+			return fmt.Errorf("%w, so list %q can't be restored", ErrNoNamespaces, name)
+		}
+		if err := s.Lists[name].check(); err != nil {
+			return fmt.Errorf("list %q: %w", name, err)
+		}
+	}
+
+	if isNamespacer {
+		names, err := ns.Namespaces()
+		if err != nil {
+			return err
+		}
+		for _, name := range names {
+			if _, ok := s.Lists[name]; !ok {
+				if err := ns.DropNamespace(name); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(s.Lists)) {
+		list, err := Namespace(store, name)
+		if err != nil {
+			return err
+		}
+		if err := restoreList(list, s.Lists[name]); err != nil {
+			return fmt.Errorf("list %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// check reports whether the list can be restored: every item with an ID
+// of its own, none of them in both the list and the trash.
+func (s snapshotList) check() error {
+	seen := map[int]bool{}
+	for _, item := range append(slices.Clip(s.Items), s.Trash...) {
+		if item.ID <= 0 {
+			return fmt.Errorf("item %q has no ID", item.Todo)
+		}
+		if seen[item.ID] {
+			return fmt.Errorf("more than one item has ID %d", item.ID)
+		}
+		seen[item.ID] = true
+	}
+	return nil
+}
+
+func restoreList(list Store, s snapshotList) error {
+	var err error
+	if replacer, ok := list.(Replacer); ok {
+		err = replacer.Replace(s.Items, s.Trash)
+	} else {
+		err = removeAndImport(list, s)
+	}
+	if err != nil {
+		return err
+	}
+
+	meta, ok := list.(MetaStore)
+	if !ok {
+		return nil
+	}
+	keys, err := meta.MetaKeys()
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if _, ok := s.Meta[key]; !ok {
+			if err := meta.PutMeta(key, nil); err != nil {
+				return err
+			}
+		}
+	}
+	for key, value := range s.Meta {
+		if err := meta.PutMeta(key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeAndImport empties list and imports the snapshot's items into it,
+// for stores that can't replace them in one go.
+func removeAndImport(list Store, s snapshotList) error {
+	importer, canImport := list.(Importer)
+	remover, canRemove := list.(Remover)
+	if !canImport || !canRemove {
+		return errors.New("this store can't be restored from a backup")
+	}
+	all, err := AllItems(list)
+	if err != nil {
+		return err
+	}
+	if err := remover.Remove(slices.Collect(maps.Keys(all))...); err != nil {
+		return err
+	}
+	return importer.Import(s.Items, s.Trash)
+}
@@ -0,0 +1,46 @@
+package todo
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+)
+
+func TestSnapshotRestore(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		mustAdd(t, store, "Buy milk")
+		mustAdd(t, store, "Walk the dog")
+		store.Delete(2)
+		var buf bytes.Buffer
+		if _, err := WriteSnapshot(&buf, store); err != nil {
+			t.Fatal(err)
+		}
+		snap, err := ReadSnapshot(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		mustAdd(t, store, "Call the bank")
+		store.Restore(2)
+		if err := snap.RestoreTo(store); err != nil {
+			t.Fatal(err)
+		}
+		list, _ := store.List()
+		trash, _ := store.Trash()
+		if !slices.Equal(ids(list), []int{1}) || !slices.Equal(ids(trash), []int{2}) {
+			t.Errorf("after RestoreTo, List = %v and Trash = %v, want [1] and [2]", ids(list), ids(trash))
+		}
+
+		// A snapshot that can't be restored leaves the store as it was.
+		bad := &Snapshot{Lists: map[string]snapshotList{"": {
+			Items: []ParsedTodoItem{{ID: 5, Todo: "Pay rent"}},
+			Trash: []ParsedTodoItem{{ID: 5, Todo: "Pay the rent"}},
+		}}}
+		if err := bad.RestoreTo(store); err == nil {
+			t.Error("RestoreTo of a snapshot with an ID used twice succeeded")
+		}
+		if list, _ := store.List(); !slices.Equal(ids(list), []int{1}) {
+			t.Errorf("List after a failed RestoreTo = %v, want [1]", ids(list))
+		}
+	})
+}
@@ -0,0 +1,640 @@
+// Package bolt provides a todo.Store backed by a bbolt database, a pure Go
+// embedded key/value store, for when cgo isn't available for SQLite.
+// Importing it registers the `bolt://` store URL scheme:
+//
+//	import _ "github.com/buck06191/todo-app/pkg/todo/bolt"
+//
+//	store, err := todo.Open("bolt:///home/me/.todo/todos.db")
+//
+// The database has five buckets:
+//
+//	items  ID -> item JSON, for items on the list
+//	trash  ID -> item JSON, for items that have been deleted
+//	due    due date (RFC 3339, UTC) + ID -> nothing, an index over items
+//	words  word + 0x00 + ID -> nothing, a search index over items
+//	meta   key -> JSON, the values saved with PutMeta
+//
+// IDs are stored as 8 byte big-endian integers so the keys sort in the order
+// the items were added. The next ID comes from the items bucket's sequence.
+//
+// Other namespaces have the same five buckets inside a bucket named after
+// them in the lists bucket, so each has its own IDs.
+package bolt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/buck06191/todo-app/pkg/todo"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+func init() {
+	todo.Register("bolt", func(url string) (todo.Store, error) {
+		return Open(strings.TrimPrefix(url, "bolt://"))
+	})
+}
+
+var (
+	itemsBucket = []byte("items")
+	trashBucket = []byte("trash")
+	dueBucket   = []byte("due")
+	wordsBucket = []byte("words")
+	metaBucket  = []byte("meta")
+	listsBucket = []byte("lists")
+	// schemaBucket holds the version key, how many of migrations have
+	// been applied.
+	schemaBucket = []byte("schema")
+	versionKey   = []byte("version")
+)
+
+// migrations are run in order to bring a database up to date when it is
+// opened. Existing entries must never be changed; add a new one instead.
+var migrations = []func(tx *bolt.Tx) error{
+	// The words index, for databases made before there was one.
+	func(tx *bolt.Tx) error {
+		lists := []parent{tx}
+		err := tx.Bucket(listsBucket).ForEachBucket(func(name []byte) error {
+			lists = append(lists, tx.Bucket(listsBucket).Bucket(name))
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, p := range lists {
+			if p.Bucket(wordsBucket) == nil {
+				if err := indexWords(p); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	},
+}
+
+// dueKeyFormat is used for the keys of the due index. It has a fixed width
+// so the keys sort by time.
+const dueKeyFormat = "2006-01-02T15:04:05.000000000Z"
+
+// Store is a todo.Store saving to a bbolt database.
+type Store struct {
+	db *bolt.DB
+	// list is the name of the namespace, or nil for the default one.
+	list []byte
+	// view is set for stores returned by Namespace, which share db.
+	view bool
+}
+
+// parent holds the buckets of one list: the transaction itself for the
+// default list, or its bucket in lists for the others. *bolt.Tx and
+// *bolt.Bucket both have these methods.
+type parent interface {
+	Bucket(name []byte) *bolt.Bucket
+	CreateBucket(name []byte) (*bolt.Bucket, error)
+	CreateBucketIfNotExists(name []byte) (*bolt.Bucket, error)
+	DeleteBucket(name []byte) error
+}
+
+// Open opens the database at path, creating it and its buckets if needed.
+// Only one process can have the database open at a time; Open gives up if
+// it can't get the lock within a few seconds.
+func Open(path string) (*Store, error) {
+	if path == "" {
+		return nil, errors.New("bolt: no database path given")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("bolt: opening %s: %w", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists(listsBucket); err != nil {
+			return err
+		}
+		if err := migrate(tx); err != nil {
+			return err
+		}
+		return createList(tx)
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &Store{db: db}, nil
+}
+
+// Add implements todo.Store.
+func (s *Store) Add(item todo.ParsedTodoItem) (todo.ParsedTodoItem, error) {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		root := s.root(tx)
+		id, err := root.Bucket(itemsBucket).NextSequence()
+		if err != nil {
+			return err
+		}
+		item.ID = int(id)
+		return put(root, itemsBucket, item)
+	})
+	return item, err
+}
+
+// List implements todo.Store.
+func (s *Store) List() ([]todo.ParsedTodoItem, error) {
+	return s.all(itemsBucket)
+}
+
+// Get implements todo.Store.
+func (s *Store) Get(id int) (todo.ParsedTodoItem, error) {
+	var item todo.ParsedTodoItem
+	err := s.db.View(func(tx *bolt.Tx) error {
+		var err error
+		item, err = get(s.root(tx), itemsBucket, id)
+		return err
+	})
+	return item, err
+}
+
+// Update implements todo.Store.
+func (s *Store) Update(item todo.ParsedTodoItem) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		root := s.root(tx)
+		old, err := get(root, itemsBucket, item.ID)
+		if err != nil {
+			return err
+		}
+		if err := unindex(root, old); err != nil {
+			return err
+		}
+		return put(root, itemsBucket, item)
+	})
+}
+
+// Delete implements todo.Store.
+func (s *Store) Delete(id int) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		root := s.root(tx)
+		item, err := get(root, itemsBucket, id)
+		if err != nil {
+			return err
+		}
+		if err := remove(root, itemsBucket, item); err != nil {
+			return err
+		}
+		item.DeletedAt = time.Now()
+		return put(root, trashBucket, item)
+	})
+}
+
+// Trash implements todo.Store.
+func (s *Store) Trash() ([]todo.ParsedTodoItem, error) {
+	return s.all(trashBucket)
+}
+
+// Restore implements todo.Store.
+func (s *Store) Restore(id int) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		root := s.root(tx)
+		item, err := get(root, trashBucket, id)
+		if err != nil {
+			return err
+		}
+		if err := remove(root, trashBucket, item); err != nil {
+			return err
+		}
+		item.DeletedAt = time.Time{}
+		return put(root, itemsBucket, item)
+	})
+}
+
+// Purge implements todo.Store.
+func (s *Store) Purge() (int, error) {
+	var n int
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		root := s.root(tx)
+		n = root.Bucket(trashBucket).Stats().KeyN
+		if err := root.DeleteBucket(trashBucket); err != nil {
+			return err
+		}
+		_, err := root.CreateBucket(trashBucket)
+		return err
+	})
+	return n, err
+}
+
+// Remove implements todo.Remover.
+func (s *Store) Remove(ids ...int) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return removeIDs(s.root(tx), ids)
+	})
+}
+
+// Import implements todo.Importer. Items keep their IDs and the ID sequence
+// is moved past the largest one. Items replaced on the list are taken out
+// of the indexes first, as Update does.
+func (s *Store) Import(items, trash []todo.ParsedTodoItem) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return importItems(s.root(tx), items, trash)
+	})
+}
+
+// Replace implements todo.Replacer, removing and importing in one
+// transaction.
+func (s *Store) Replace(items, trash []todo.ParsedTodoItem) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		root := s.root(tx)
+		var ids []int
+		for _, bucket := range [][]byte{itemsBucket, trashBucket} {
+			err := root.Bucket(bucket).ForEach(func(k, _ []byte) error {
+				ids = append(ids, int(binary.BigEndian.Uint64(k)))
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+		if err := removeIDs(root, ids); err != nil {
+			return err
+		}
+		return importItems(root, items, trash)
+	})
+}
+
+// removeIDs removes the items with the given IDs from the list and the
+// trash, skipping IDs without an item.
+func removeIDs(root parent, ids []int) error {
+	for _, id := range ids {
+		for _, bucket := range [][]byte{itemsBucket, trashBucket} {
+			item, err := get(root, bucket, id)
+			if errors.Is(err, todo.ErrNotFound) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if err := remove(root, bucket, item); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// importItems saves items onto the list and trash into the trash as
+// Import does.
+func importItems(root parent, items, trash []todo.ParsedTodoItem) error {
+	seq := root.Bucket(itemsBucket).Sequence()
+	for _, item := range items {
+		old, err := get(root, itemsBucket, item.ID)
+		if err == nil {
+			err = unindex(root, old)
+		}
+		if err != nil && !errors.Is(err, todo.ErrNotFound) {
+			return err
+		}
+		if err := put(root, itemsBucket, item); err != nil {
+			return err
+		}
+		seq = max(seq, uint64(item.ID))
+	}
+	for _, item := range trash {
+		if err := put(root, trashBucket, item); err != nil {
+			return err
+		}
+		seq = max(seq, uint64(item.ID))
+	}
+	return root.Bucket(itemsBucket).SetSequence(seq)
+}
+
+// DueBetween returns the items on the list that are due at or after from and
+// before to, earliest first, using the due date index.
+func (s *Store) DueBetween(from, to time.Time) ([]todo.ParsedTodoItem, error) {
+	var items []todo.ParsedTodoItem
+	err := s.db.View(func(tx *bolt.Tx) error {
+		root := s.root(tx)
+		start := []byte(from.UTC().Format(dueKeyFormat))
+		end := []byte(to.UTC().Format(dueKeyFormat))
+
+		c := root.Bucket(dueBucket).Cursor()
+		for k, _ := c.Seek(start); k != nil && bytes.Compare(k, end) < 0; k, _ = c.Next() {
+			id := binary.BigEndian.Uint64(k[len(k)-8:])
+			item, err := get(root, itemsBucket, int(id))
+			if err != nil {
+				return err
+			}
+			items = append(items, item)
+		}
+		return nil
+	})
+	return items, err
+}
+
+// SearchWords implements todo.Searcher using the words index.
+func (s *Store) SearchWords(terms []string) ([]todo.ParsedTodoItem, error) {
+	var items []todo.ParsedTodoItem
+	err := s.db.View(func(tx *bolt.Tx) error {
+		root := s.root(tx)
+		var ids map[uint64]bool
+		for _, term := range terms {
+			matched := make(map[uint64]bool)
+			c := root.Bucket(wordsBucket).Cursor()
+			prefix := []byte(term)
+			for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+				id := binary.BigEndian.Uint64(k[len(k)-8:])
+				if ids == nil || ids[id] {
+					matched[id] = true
+				}
+			}
+			ids = matched
+		}
+
+		for _, id := range slices.Sorted(maps.Keys(ids)) {
+			item, err := get(root, itemsBucket, int(id))
+			if err != nil {
+				return err
+			}
+			items = append(items, item)
+		}
+		return nil
+	})
+	return items, err
+}
+
+// GetMeta implements todo.MetaStore.
+func (s *Store) GetMeta(key string) ([]byte, error) {
+	var value []byte
+	err := s.db.View(func(tx *bolt.Tx) error {
+		// Values are only valid during the transaction.
+		value = bytes.Clone(s.root(tx).Bucket(metaBucket).Get([]byte(key)))
+		return nil
+	})
+	return value, err
+}
+
+// PutMeta implements todo.MetaStore.
+func (s *Store) PutMeta(key string, value []byte) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		root := s.root(tx)
+		if value == nil {
+			return root.Bucket(metaBucket).Delete([]byte(key))
+		}
+		return root.Bucket(metaBucket).Put([]byte(key), value)
+	})
+}
+
+// MetaKeys implements todo.MetaStore.
+func (s *Store) MetaKeys() ([]string, error) {
+	var keys []string
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return s.root(tx).Bucket(metaBucket).ForEach(func(k, _ []byte) error {
+			keys = append(keys, string(k))
+			return nil
+		})
+	})
+	return keys, err
+}
+
+// Namespace implements todo.Namespacer.
+func (s *Store) Namespace(name string) (todo.Store, error) {
+	if name == "" {
+		return &Store{db: s.db, view: true}, nil
+	}
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		list, err := tx.Bucket(listsBucket).CreateBucketIfNotExists([]byte(name))
+		if err != nil {
+			return err
+		}
+		return createList(list)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Store{db: s.db, list: []byte(name), view: true}, nil
+}
+
+// Namespaces implements todo.Namespacer.
+func (s *Store) Namespaces() ([]string, error) {
+	var names []string
+	err := s.db.View(func(tx *bolt.Tx) error {
+		lists := tx.Bucket(listsBucket)
+		return lists.ForEachBucket(func(name []byte) error {
+			list := lists.Bucket(name)
+			for _, bucket := range [][]byte{itemsBucket, trashBucket, metaBucket} {
+				if k, _ := list.Bucket(bucket).Cursor().First(); k != nil {
+					names = append(names, string(name))
+					return nil
+				}
+			}
+			return nil
+		})
+	})
+	return names, err
+}
+
+// DropNamespace implements todo.Namespacer. The list is left empty rather
+// than removed, so stores already returned by Namespace for it still work.
+func (s *Store) DropNamespace(name string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		lists := tx.Bucket(listsBucket)
+		if lists.Bucket([]byte(name)) == nil {
+			return nil
+		}
+		if err := lists.DeleteBucket([]byte(name)); err != nil {
+			return err
+		}
+		list, err := lists.CreateBucket([]byte(name))
+		if err != nil {
+			return err
+		}
+		return createList(list)
+	})
+}
+
+// Close implements todo.Store. Closing a namespace does nothing.
+func (s *Store) Close() error {
+	if s.view {
+		return nil
+	}
+	return s.db.Close()
+}
+
+// root returns the parent of the store's buckets in tx.
+func (s *Store) root(tx *bolt.Tx) parent {
+	if s.list == nil {
+		return tx
+	}
+	return tx.Bucket(listsBucket).Bucket(s.list)
+}
+
+// createList creates the buckets of a list in p if they don't exist yet.
+func createList(p parent) error {
+	for _, name := range [][]byte{itemsBucket, trashBucket, dueBucket, wordsBucket, metaBucket} {
+		if _, err := p.CreateBucketIfNotExists(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrate runs the migrations the database hasn't had yet, the version
+// being 0 for databases from before it was kept. A new database is
+// created at the latest version.
+func migrate(tx *bolt.Tx) error {
+	schema := tx.Bucket(schemaBucket)
+	version := 0
+	switch {
+	case schema == nil && tx.Bucket(itemsBucket) == nil:
+		version = len(migrations)
+	case schema != nil:
+		if v := schema.Get(versionKey); len(v) == 8 {
+			version = int(binary.BigEndian.Uint64(v))
+		}
+	}
+	if version > len(migrations) {
+		return fmt.Errorf("bolt: %w: database is at version %d but this build only knows %d", todo.ErrNewerSchema, version, len(migrations))
+	}
+	if tx.Bucket(itemsBucket) != nil {
+		for _, m := range migrations[version:] {
+			if err := m(tx); err != nil {
+				return err
+			}
+		}
+	}
+	schema, err := tx.CreateBucketIfNotExists(schemaBucket)
+	if err != nil {
+		return err
+	}
+	return schema.Put(versionKey, itob(len(migrations)))
+}
+
+func (s *Store) all(bucket []byte) ([]todo.ParsedTodoItem, error) {
+	var items []todo.ParsedTodoItem
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return s.root(tx).Bucket(bucket).ForEach(func(_, v []byte) error {
+			var item todo.ParsedTodoItem
+			if err := json.Unmarshal(v, &item); err != nil {
+				return err
+			}
+			items = append(items, item)
+			return nil
+		})
+	})
+	return items, err
+}
+
+func get(p parent, bucket []byte, id int) (todo.ParsedTodoItem, error) {
+	var item todo.ParsedTodoItem
+
+	v := p.Bucket(bucket).Get(itob(id))
+	if v == nil {
+		where := ""
+		if bytes.Equal(bucket, trashBucket) {
+			where = " in the trash"
+		}
+		return item, fmt.Errorf("%w with ID %d%s", todo.ErrNotFound, id, where)
+	}
+
+	err := json.Unmarshal(v, &item)
+	return item, err
+}
+
+// put saves item in the bucket, adding it to the due and words indexes if
+// it is on the list.
+func put(p parent, bucket []byte, item todo.ParsedTodoItem) error {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+	if err := p.Bucket(bucket).Put(itob(item.ID), data); err != nil {
+		return err
+	}
+	if !bytes.Equal(bucket, itemsBucket) {
+		return nil
+	}
+	if !item.Due.IsZero() {
+		if err := p.Bucket(dueBucket).Put(dueKey(item), nil); err != nil {
+			return err
+		}
+	}
+	for _, key := range wordKeys(item) {
+		if err := p.Bucket(wordsBucket).Put(key, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// remove deletes item from the bucket and the indexes.
+func remove(p parent, bucket []byte, item todo.ParsedTodoItem) error {
+	if err := p.Bucket(bucket).Delete(itob(item.ID)); err != nil {
+		return err
+	}
+	return unindex(p, item)
+}
+
+// unindex deletes item's entries from the due and words indexes.
+func unindex(p parent, item todo.ParsedTodoItem) error {
+	if err := p.Bucket(dueBucket).Delete(dueKey(item)); err != nil {
+		return err
+	}
+	for _, key := range wordKeys(item) {
+		if err := p.Bucket(wordsBucket).Delete(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// indexWords creates the words bucket and fills it from the items already
+// on the list, for databases made before there was a search index.
+func indexWords(p parent) error {
+	words, err := p.CreateBucket(wordsBucket)
+	if err != nil {
+		return err
+	}
+	return p.Bucket(itemsBucket).ForEach(func(_, v []byte) error {
+		var item todo.ParsedTodoItem
+		if err := json.Unmarshal(v, &item); err != nil {
+			return err
+		}
+		for _, key := range wordKeys(item) {
+			if err := words.Put(key, nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// wordKeys returns the keys of item's entries in the words index.
+func wordKeys(item todo.ParsedTodoItem) [][]byte {
+	var keys [][]byte
+	for _, word := range todo.Tokenize(item.SearchText()) {
+		key := append([]byte(word), 0)
+		keys = append(keys, append(key, itob(item.ID)...))
+	}
+	return keys
+}
+
+func dueKey(item todo.ParsedTodoItem) []byte {
+	return append([]byte(item.Due.UTC().Format(dueKeyFormat)), itob(item.ID)...)
+}
+
+func itob(id int) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, uint64(id))
+	return b
+}
@@ -0,0 +1,71 @@
+package bolt
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/buck06191/todo-app/pkg/todo"
+)
+
+func TestImportReplacesIndexes(t *testing.T) {
+	store, err := Open(filepath.Join(t.TempDir(), "todos.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	monday := time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC)
+	friday := time.Date(2025, 3, 7, 9, 0, 0, 0, time.UTC)
+	item, err := store.Add(todo.ParsedTodoItem{Todo: "Buy milk", Due: monday})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = store.Import([]todo.ParsedTodoItem{{ID: item.ID, Todo: "Walk the dog", Due: friday}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if found, err := store.SearchWords([]string{"milk"}); err != nil || len(found) != 0 {
+		t.Errorf("SearchWords(milk) = %v, %v, want nothing", found, err)
+	}
+	if found, err := store.SearchWords([]string{"dog"}); err != nil || len(found) != 1 || found[0].Todo != "Walk the dog" {
+		t.Errorf("SearchWords(dog) = %v, %v, want the imported item", found, err)
+	}
+	if found, err := store.DueBetween(monday, monday.Add(time.Hour)); err != nil || len(found) != 0 {
+		t.Errorf("DueBetween on Monday = %v, %v, want nothing", found, err)
+	}
+	if found, err := store.DueBetween(friday, friday.Add(time.Hour)); err != nil || len(found) != 1 {
+		t.Errorf("DueBetween on Friday = %v, %v, want the imported item", found, err)
+	}
+}
+
+func TestReplace(t *testing.T) {
+	store, err := Open(filepath.Join(t.TempDir(), "todos.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	for _, task := range []string{"Buy milk", "Walk the dog"} {
+		if _, err := store.Add(todo.ParsedTodoItem{Todo: task}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	store.Delete(2)
+	if err := store.Replace([]todo.ParsedTodoItem{{ID: 7, Todo: "Call the bank"}}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	list, _ := store.List()
+	trash, _ := store.Trash()
+	if len(list) != 1 || list[0].ID != 7 || len(trash) != 0 {
+		t.Errorf("after Replace, List = %v and Trash = %v, want item 7 alone", list, trash)
+	}
+	if found, err := store.SearchWords([]string{"milk"}); err != nil || len(found) != 0 {
+		t.Errorf("SearchWords(milk) = %v, %v, want nothing", found, err)
+	}
+	if item, err := store.Add(todo.ParsedTodoItem{Todo: "Pay rent"}); err != nil || item.ID != 8 {
+		t.Errorf("Add after Replace = %d, %v, want ID 8", item.ID, err)
+	}
+}
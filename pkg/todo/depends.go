@@ -0,0 +1,88 @@
+package todo
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrDependencyCycle is returned when blocking an item would make it wait,
+// directly or through other items, on itself.
+var ErrDependencyCycle = errors.New("circular dependency")
+
+// CheckBlocker checks that the item with the given ID can be blocked on the
+// item with ID on: both must be in items and on mustn't already be waiting,
+// directly or indirectly, on id.
+func CheckBlocker(items []ParsedTodoItem, id, on int) error {
+	byID := map[int]ParsedTodoItem{}
+	for _, item := range items {
+		byID[item.ID] = item
+	}
+	for _, check := range []int{id, on} {
+		if _, ok := byID[check]; !ok {
+			return fmt.Errorf("%w with ID %d", ErrNotFound, check)
+		}
+	}
+	if id == on {
+		return fmt.Errorf("%w: %d can't wait on itself", ErrDependencyCycle, id)
+	}
+
+	// Walk everything on waits on looking for id.
+	seen := map[int]bool{}
+	stack := []int{on}
+	for len(stack) > 0 {
+		next := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if next == id {
+			return fmt.Errorf("%w: %d already waits on %d", ErrDependencyCycle, on, id)
+		}
+		if seen[next] {
+			continue
+		}
+		seen[next] = true
+		stack = append(stack, byID[next].BlockedBy...)
+	}
+	return nil
+}
+
+// OpenBlockers returns, for each item in items that is blocked, the IDs of
+// the items it is waiting on that aren't done yet. Blockers that have been
+// deleted don't count.
+func OpenBlockers(items []ParsedTodoItem) map[int][]int {
+	open := map[int]bool{}
+	for _, item := range items {
+		if !item.Completed {
+			open[item.ID] = true
+		}
+	}
+
+	blocked := map[int][]int{}
+	for _, item := range items {
+		for _, on := range item.BlockedBy {
+			if open[on] {
+				blocked[item.ID] = append(blocked[item.ID], on)
+			}
+		}
+	}
+	return blocked
+}
+
+// AddBlocker returns ids with on added unless it is already there.
+func AddBlocker(ids []int, on int) []int {
+	for _, id := range ids {
+		if id == on {
+			return ids
+		}
+	}
+	return append(ids, on)
+}
+
+// RemoveBlocker returns ids without on.
+func RemoveBlocker(ids []int, on int) []int {
+	var kept []int
+	for _, id := range ids {
+		if id != on {
+			kept = append(kept, id)
+		}
+	}
+	return kept
+}
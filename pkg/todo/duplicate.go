@@ -0,0 +1,109 @@
+package todo
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// DuplicateSimilarity is how similar, as Similarity measures it, the task of
+// a new item and that of one on the list have to be for Duplicates to take
+// it for a repeat.
+const DuplicateSimilarity = 0.8
+
+// Similarity returns how alike the tasks a and b are, from 0 for nothing
+// in common to 1 for the same. Case, punctuation and the spaces between
+// words are left out, so "Buy milk!" and "buy  milk" are the same, and what
+// is left is compared by the pairs of letters in it, so "Buy milk" and "Buy
+// some milk" are close to each other and the order of words matters little.
+// Words with digits in them have to match exactly, so tasks with different
+// numbers, such as "Pay invoice 1043" and "Pay invoice 1044", have nothing
+// in common, and words of one letter count as a whole.
+func Similarity(a, b string) float64 {
+	wordsA, wordsB := Tokenize(a), Tokenize(b)
+	if slices.Equal(wordsA, wordsB) {
+		return 1
+	}
+	if !slices.Equal(numbers(wordsA), numbers(wordsB)) {
+		return 0
+	}
+	pairsA, pairsB := letterPairs(wordsA), letterPairs(wordsB)
+	if len(pairsA) == 0 || len(pairsB) == 0 {
+		return 0
+	}
+	count := map[string]int{}
+	for _, p := range pairsA {
+		count[p]++
+	}
+	shared := 0
+	for _, p := range pairsB {
+		if count[p] > 0 {
+			count[p]--
+			shared++
+		}
+	}
+	return 2 * float64(shared) / float64(len(pairsA)+len(pairsB))
+}
+
+// numbers returns the words with digits in them, sorted.
+func numbers(words []string) []string {
+	var found []string
+	for _, word := range words {
+		if strings.ContainsFunc(word, unicode.IsDigit) {
+			found = append(found, word)
+		}
+	}
+	slices.Sort(found)
+	return found
+}
+
+// letterPairs returns each pair of letters next to each other within
+// words, and the whole of each word with a digit in it or of one letter,
+// which have none to compare or need to match exactly.
+func letterPairs(words []string) []string {
+	var pairs []string
+	for _, word := range words {
+		r := []rune(word)
+		if len(r) < 2 || strings.ContainsFunc(word, unicode.IsDigit) {
+			pairs = append(pairs, word)
+			continue
+		}
+		for i := 0; i+1 < len(r); i++ {
+			pairs = append(pairs, string(r[i:i+2]))
+		}
+	}
+	return pairs
+}
+
+// Duplicates returns the items that aren't done whose task is at least
+// DuplicateSimilarity like task, the most alike first, for telling of an
+// item being added again.
+func Duplicates(items []ParsedTodoItem, task string) []ParsedTodoItem {
+	type match struct {
+		item  ParsedTodoItem
+		score float64
+	}
+	var matches []match
+	for _, item := range items {
+		if item.Completed || !item.DeletedAt.IsZero() {
+			continue
+		}
+		if score := Similarity(item.Todo, task); score >= DuplicateSimilarity {
+			matches = append(matches, match{item, score})
+		}
+	}
+	slices.SortStableFunc(matches, func(a, b match) int {
+		switch {
+		case a.score > b.score:
+			return -1
+		case a.score < b.score:
+			return 1
+		}
+		return 0
+	})
+	found := make([]ParsedTodoItem, len(matches))
+	for i, m := range matches {
+		found[i] = m.item
+	}
+	return found
+}
@@ -0,0 +1,49 @@
+package todo
+
+import "testing"
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		dup  bool
+	}{
+		{"Buy milk", "Buy milk", true},
+		{"Buy milk!", "buy  milk", true},
+		{"Pay rent", "Pay the rent", true},
+		{"Buy milk", "Walk the dog", false},
+		{"Pay invoice 1044", "Pay invoice 1043", false},
+		{"Pay invoice 1044", "pay invoice #1044", true},
+		{"item 7b", "item 1", false},
+		{"Plan A", "Plan B", false},
+		{"Buy 2 apples", "Buy apples", false},
+		{"", "Buy milk", false},
+	}
+	for _, tt := range tests {
+		score := Similarity(tt.a, tt.b)
+		if dup := score >= DuplicateSimilarity; dup != tt.dup {
+			t.Errorf("Similarity(%q, %q) = %.2f, a duplicate: %v, want %v", tt.a, tt.b, score, dup, tt.dup)
+		}
+		if back := Similarity(tt.b, tt.a); back != score {
+			t.Errorf("Similarity(%q, %q) = %.2f, but %.2f the other way round", tt.a, tt.b, score, back)
+		}
+	}
+}
+
+func TestDuplicates(t *testing.T) {
+	items := []ParsedTodoItem{
+		{ID: 1, Todo: "Pay the rent"},
+		{ID: 2, Todo: "Pay rent", Completed: true},
+		{ID: 3, Todo: "pay rent!"},
+		{ID: 4, Todo: "Pay invoice 1043"},
+	}
+	var ids []int
+	for _, item := range Duplicates(items, "Pay rent") {
+		ids = append(ids, item.ID)
+	}
+	if len(ids) != 2 || ids[0] != 3 || ids[1] != 1 {
+		t.Errorf("Duplicates of %q = %v, want [3 1]", "Pay rent", ids)
+	}
+	if found := Duplicates(items, "Pay invoice 1044"); len(found) != 0 {
+		t.Errorf("Duplicates of %q = %v, want none", "Pay invoice 1044", found)
+	}
+}
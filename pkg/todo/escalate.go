@@ -0,0 +1,86 @@
+package todo
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Escalation raises the priority items are sorted and shown by as they
+// come due, so that items that matter but aren't urgent come up in time.
+// An item is raised to each level once it is due within the length given
+// for it, or for a length of 0, once it is overdue. Overdue items are
+// raised to every level given. The zero Escalation raises nothing.
+type Escalation map[Priority]time.Duration
+
+// Escalate is the policy EffectivePriority follows. The CLI sets it from
+// the priority.escalate setting.
+var Escalate Escalation
+
+// ParseEscalation parses an escalation policy such as "medium=3d,high=1d"
+// or "high=0": a priority for each of the levels to raise to with how
+// long before items are due, as ParseDuration takes it, to raise them. 0,
+// or overdue, raises them once they are overdue.
+func ParseEscalation(s string) (Escalation, error) {
+	e := Escalation{}
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, within, found := strings.Cut(part, "=")
+		if !found {
+			return nil, fmt.Errorf("%q should be a priority and how long before items are due, e.g. high=1d", part)
+		}
+		level, err := ParsePriority(name)
+		if err != nil {
+			return nil, err
+		}
+		if level == PriorityNone {
+			return nil, fmt.Errorf("%q: items can only be raised to low, medium or high", part)
+		}
+		if strings.TrimSpace(within) == "overdue" {
+			e[level] = 0
+			continue
+		}
+		if e[level], err = ParseDuration(strings.TrimSpace(within)); err != nil {
+			return nil, err
+		}
+	}
+	return e, nil
+}
+
+// String returns the policy as ParseEscalation takes it, lowest level
+// first.
+func (e Escalation) String() string {
+	var parts []string
+	for _, level := range []Priority{PriorityLow, PriorityMedium, PriorityHigh} {
+		if within, ok := e[level]; ok {
+			parts = append(parts, level.String()+"="+formatDuration(within))
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// Apply returns item's priority at now, raised to the highest level the
+// policy gives for how soon it is due. Done items and those without a due
+// date keep their own priority, as do those already at the level.
+func (e Escalation) Apply(item ParsedTodoItem, now time.Time) Priority {
+	p := item.Priority
+	if item.Completed || item.Due.IsZero() {
+		return p
+	}
+	overdue := item.IsOverdue(now)
+	for level, within := range e {
+		if level > p && (overdue || within > 0 && !item.Due.After(now.Add(within))) {
+			p = level
+		}
+	}
+	return p
+}
+
+// EffectivePriority returns the item's priority as Escalate raises it at
+// now, which is what items are sorted and shown by.
+func (item ParsedTodoItem) EffectivePriority(now time.Time) Priority {
+	return Escalate.Apply(item, now)
+}
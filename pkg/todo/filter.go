@@ -0,0 +1,567 @@
+package todo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var (
+	// ErrBadFilter is returned when a filter expression can't be parsed.
+	ErrBadFilter = errors.New("bad filter")
+	// ErrNoFilter is returned when there is no saved filter with a name.
+	ErrNoFilter = errors.New("no such filter")
+)
+
+// filtersKey is the MetaStore key saved filters are kept under, as a JSON
+// object of name to expression.
+const filtersKey = "filters"
+
+// Filter is a parsed filter expression such as
+//
+//	due<2025-02-01 and tag:work and not done
+//
+// Expressions are made of terms joined with and, or and not, with brackets
+// for grouping. Terms next to each other with nothing between them are
+// joined with and, and and binds tighter than or. The terms are:
+//
+//	tag:work, #work                items with the tag
+//	context:home, @home            items with the context
+//	project:site, +site            items in the project, project:none for none
+//	priority>=medium               compared with <, <=, >, >=, = or !=
+//	due<+2d, created>=2025-01-01   the same comparisons against a date
+//	completed<-30d                 when the item was done, 30 days ago
+//	due:none                       items without a due date
+//	id:3, parent:12                by ID, parent:none for top level items
+//	snoozed>=3                     put off with Snooze at least 3 times
+//	status:doing                   backlog, doing or done, see CurrentStatus
+//	text:inv, inv                  a word starting with inv, as for Search
+//	done, open, overdue, repeating, inbox
+//
+// Dates are anything ParseDueDate understands. A date without a time
+// compares by day, so due<=friday includes items due at 5pm on Friday. Values
+// containing spaces can be quoted: due<"next friday".
+type Filter struct {
+	source string
+	match  predicate
+}
+
+// predicate is a compiled filter expression or one of its parts.
+type predicate func(item ParsedTodoItem, now time.Time) bool
+
+// ParseFilter parses a filter expression. Relative dates in it are worked
+// out when it is parsed, not each time it is used.
+func ParseFilter(s string) (*Filter, error) {
+	tokens, err := lexFilter(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("%w: empty expression", ErrBadFilter)
+	}
+
+	p := &filterParser{tokens: tokens}
+	match, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok, ok := p.peek(); ok {
+		return nil, fmt.Errorf("%w: unexpected %q", ErrBadFilter, tok.text)
+	}
+	return &Filter{source: s, match: match}, nil
+}
+
+// Match reports whether item matches the filter. now is used for overdue.
+func (f *Filter) Match(item ParsedTodoItem, now time.Time) bool {
+	return f.match(item, now)
+}
+
+// String returns the expression the filter was parsed from.
+func (f *Filter) String() string {
+	return f.source
+}
+
+// filterToken is a word or bracket in a filter expression. Quoted words are
+// never treated as keywords or comparisons.
+type filterToken struct {
+	text   string
+	quoted bool
+}
+
+func lexFilter(s string) ([]filterToken, error) {
+	var tokens []filterToken
+	var word strings.Builder
+	inWord, quoted := false, false
+	var quote rune
+
+	flush := func() {
+		if inWord {
+			tokens = append(tokens, filterToken{text: word.String(), quoted: quoted})
+		}
+		word.Reset()
+		inWord, quoted = false, false
+	}
+
+	for _, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			quoted = quoted || !inWord
+			inWord = true
+		case r == '(' || r == ')':
+			flush()
+			tokens = append(tokens, filterToken{text: string(r)})
+		case r == ' ' || r == '\t' || r == '\n':
+			flush()
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("%w: unterminated quote", ErrBadFilter)
+	}
+	flush()
+	return tokens, nil
+}
+
+type filterParser struct {
+	tokens []filterToken
+	pos    int
+}
+
+func (p *filterParser) peek() (filterToken, bool) {
+	if p.pos >= len(p.tokens) {
+		return filterToken{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+// keyword reports whether the next token is the unquoted word kw, and skips
+// it if it is.
+func (p *filterParser) keyword(kw string) bool {
+	tok, ok := p.peek()
+	if ok && !tok.quoted && strings.EqualFold(tok.text, kw) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *filterParser) parseOr() (predicate, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("or") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(item ParsedTodoItem, now time.Time) bool {
+			return l(item, now) || right(item, now)
+		}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (predicate, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok, ok := p.peek()
+		if !ok || (!tok.quoted && (tok.text == ")" || strings.EqualFold(tok.text, "or"))) {
+			return left, nil
+		}
+		p.keyword("and")
+
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(item ParsedTodoItem, now time.Time) bool {
+			return l(item, now) && right(item, now)
+		}
+	}
+}
+
+func (p *filterParser) parseNot() (predicate, error) {
+	if p.keyword("not") {
+		inner, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return func(item ParsedTodoItem, now time.Time) bool {
+			return !inner(item, now)
+		}, nil
+	}
+
+	tok, ok := p.peek()
+	if !ok {
+		return nil, fmt.Errorf("%w: expression ends too soon", ErrBadFilter)
+	}
+	p.pos++
+
+	if !tok.quoted && tok.text == "(" {
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if tok, ok := p.peek(); !ok || tok.text != ")" {
+			return nil, fmt.Errorf("%w: missing )", ErrBadFilter)
+		}
+		p.pos++
+		return inner, nil
+	}
+	if !tok.quoted && (tok.text == ")" || isFilterKeyword(tok.text)) {
+		return nil, fmt.Errorf("%w: unexpected %q", ErrBadFilter, tok.text)
+	}
+	return parseTerm(tok)
+}
+
+func isFilterKeyword(word string) bool {
+	switch strings.ToLower(word) {
+	case "and", "or", "not":
+		return true
+	}
+	return false
+}
+
+// filterOps are the comparison operators, longest first so that <= isn't
+// read as <.
+var filterOps = []string{"<=", ">=", "!=", "<", ">", "=", ":"}
+
+// parseTerm compiles a single term of a filter expression.
+func parseTerm(tok filterToken) (predicate, error) {
+	if tok.quoted {
+		return textTerm(tok.text)
+	}
+
+	word := tok.text
+	switch {
+	case strings.HasPrefix(word, "#") && len(word) > 1:
+		return tagTerm("=", word[1:])
+	case strings.HasPrefix(word, "@") && len(word) > 1:
+		return contextTerm("=", word[1:])
+	case strings.HasPrefix(word, "+") && len(word) > 1:
+		return projectTerm("=", word[1:])
+	}
+
+	switch strings.ToLower(word) {
+	case "done":
+		return func(item ParsedTodoItem, _ time.Time) bool { return item.Completed }, nil
+	case "open":
+		return func(item ParsedTodoItem, _ time.Time) bool { return !item.Completed }, nil
+	case "overdue":
+		return func(item ParsedTodoItem, now time.Time) bool { return item.IsOverdue(now) }, nil
+	case "repeating":
+		return func(item ParsedTodoItem, _ time.Time) bool { return item.Repeat != "" }, nil
+	case "inbox":
+		return func(item ParsedTodoItem, _ time.Time) bool { return item.Inbox }, nil
+	}
+
+	i := strings.IndexAny(word, "<>=!:")
+	if i <= 0 {
+		return textTerm(word)
+	}
+	field, rest := strings.ToLower(word[:i]), word[i:]
+	var op string
+	for _, o := range filterOps {
+		if strings.HasPrefix(rest, o) {
+			op = o
+			break
+		}
+	}
+	if op == "" {
+		return nil, fmt.Errorf("%w: unknown comparison in %q", ErrBadFilter, word)
+	}
+	value := rest[len(op):]
+	if value == "" {
+		return nil, fmt.Errorf("%w: missing value in %q", ErrBadFilter, word)
+	}
+	if op == ":" {
+		op = "="
+	}
+
+	switch field {
+	case "tag", "tags":
+		return tagTerm(op, value)
+	case "context", "contexts":
+		return contextTerm(op, value)
+	case "project":
+		return projectTerm(op, value)
+	case "text":
+		if op != "=" {
+			return nil, fmt.Errorf("%w: text can only be matched with :", ErrBadFilter)
+		}
+		return textTerm(value)
+	case "priority":
+		return priorityTerm(op, value)
+	case "status":
+		return statusTerm(op, value)
+	case "due":
+		return timeTerm(op, value, func(item ParsedTodoItem) time.Time { return item.Due })
+	case "created":
+		return timeTerm(op, value, func(item ParsedTodoItem) time.Time { return item.CreatedAt })
+	case "completed":
+		return timeTerm(op, value, func(item ParsedTodoItem) time.Time { return item.CompletedAt })
+	case "id":
+		return intTerm(op, value, func(item ParsedTodoItem) int { return item.ID })
+	case "parent":
+		return intTerm(op, value, func(item ParsedTodoItem) int { return item.Parent })
+	case "snoozed":
+		return intTerm(op, value, func(item ParsedTodoItem) int { return item.Snoozed })
+	}
+	return nil, fmt.Errorf("%w: unknown field %q", ErrBadFilter, field)
+}
+
+// equalityOnly rejects the ordering comparisons for fields that only have
+// equality.
+func equalityOnly(field, op string) error {
+	if op != "=" && op != "!=" {
+		return fmt.Errorf("%w: %s can only be compared with : or !=", ErrBadFilter, field)
+	}
+	return nil
+}
+
+// negate inverts match when op is !=.
+func negate(op string, match predicate) predicate {
+	if op != "!=" {
+		return match
+	}
+	return func(item ParsedTodoItem, now time.Time) bool {
+		return !match(item, now)
+	}
+}
+
+func tagTerm(op, value string) (predicate, error) {
+	if err := equalityOnly("tag", op); err != nil {
+		return nil, err
+	}
+	return negate(op, func(item ParsedTodoItem, _ time.Time) bool {
+		return item.HasTag(value)
+	}), nil
+}
+
+func contextTerm(op, value string) (predicate, error) {
+	if err := equalityOnly("context", op); err != nil {
+		return nil, err
+	}
+	return negate(op, func(item ParsedTodoItem, _ time.Time) bool {
+		return item.HasContext(value)
+	}), nil
+}
+
+func projectTerm(op, value string) (predicate, error) {
+	if err := equalityOnly("project", op); err != nil {
+		return nil, err
+	}
+	if strings.EqualFold(value, "none") {
+		value = ""
+	}
+	return negate(op, func(item ParsedTodoItem, _ time.Time) bool {
+		return strings.EqualFold(item.Project, value)
+	}), nil
+}
+
+func textTerm(value string) (predicate, error) {
+	terms := Tokenize(value)
+	if len(terms) == 0 {
+		return nil, fmt.Errorf("%w: nothing to match in %q", ErrBadFilter, value)
+	}
+	return func(item ParsedTodoItem, _ time.Time) bool {
+		return item.MatchesWords(terms)
+	}, nil
+}
+
+func statusTerm(op, value string) (predicate, error) {
+	if err := equalityOnly("status", op); err != nil {
+		return nil, err
+	}
+	status, err := ParseStatus(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrBadFilter, err)
+	}
+	return negate(op, func(item ParsedTodoItem, _ time.Time) bool {
+		return item.CurrentStatus() == status
+	}), nil
+}
+
+func priorityTerm(op, value string) (predicate, error) {
+	want, err := ParsePriority(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrBadFilter, err)
+	}
+	return func(item ParsedTodoItem, _ time.Time) bool {
+		return compare(op, int(item.Priority), int(want))
+	}, nil
+}
+
+func intTerm(op, value string, field func(ParsedTodoItem) int) (predicate, error) {
+	var want int
+	if !strings.EqualFold(value, "none") {
+		var err error
+		if want, err = strconv.Atoi(value); err != nil {
+			return nil, fmt.Errorf("%w: %q isn't a number", ErrBadFilter, value)
+		}
+	}
+	return func(item ParsedTodoItem, _ time.Time) bool {
+		return compare(op, field(item), want)
+	}, nil
+}
+
+// timeTerm compares a date field against value. Items without the date
+// only match field:none, or != against a date.
+func timeTerm(op, value string, field func(ParsedTodoItem) time.Time) (predicate, error) {
+	if strings.EqualFold(value, "none") {
+		if err := equalityOnly("none", op); err != nil {
+			return nil, err
+		}
+		return negate(op, func(item ParsedTodoItem, _ time.Time) bool {
+			return field(item).IsZero()
+		}), nil
+	}
+
+	want, err := ParseDueDate(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrBadFilter, err)
+	}
+	byDay := (ParsedTodoItem{Due: want}).DueAllDay()
+
+	return negate(op, func(item ParsedTodoItem, _ time.Time) bool {
+		t := field(item)
+		if t.IsZero() {
+			return false
+		}
+		if byDay {
+			t = t.In(Location)
+			t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, Location)
+		}
+		if op == "!=" {
+			return t.Equal(want)
+		}
+		return compare(op, t.Compare(want), 0)
+	}), nil
+}
+
+// compare applies one of the comparison operators to a and b.
+func compare(op string, a, b int) bool {
+	switch op {
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	case "!=":
+		return a != b
+	}
+	return a == b
+}
+
+// SavedFilters returns the filters saved in store, by name. Stores that
+// aren't MetaStores have none.
+func SavedFilters(store Store) (map[string]string, error) {
+	filters := map[string]string{}
+	meta, ok := store.(MetaStore)
+	if !ok {
+		return filters, nil
+	}
+
+	raw, err := meta.GetMeta(filtersKey)
+	if err != nil || raw == nil {
+		return filters, err
+	}
+	if err := json.Unmarshal(raw, &filters); err != nil {
+		return nil, fmt.Errorf("reading saved filters: %w", err)
+	}
+	return filters, nil
+}
+
+// SavedFilter parses the filter saved in store as name.
+func SavedFilter(store Store, name string) (*Filter, error) {
+	filters, err := SavedFilters(store)
+	if err != nil {
+		return nil, err
+	}
+	expr, ok := filters[name]
+	if !ok {
+		return nil, fmt.Errorf("%w called %q", ErrNoFilter, name)
+	}
+	return ParseFilter(expr)
+}
+
+// SaveFilter saves expr in store as name, replacing any filter already
+// saved with that name. expr has to parse. Names are made of letters,
+// digits, - and _.
+func SaveFilter(store Store, name, expr string) error {
+	if !validFilterName(name) {
+		return fmt.Errorf("%w: %q isn't a valid name, use letters, digits, - and _", ErrBadFilter, name)
+	}
+	if _, err := ParseFilter(expr); err != nil {
+		return err
+	}
+	return updateFilters(store, func(filters map[string]string) error {
+		filters[name] = expr
+		return nil
+	})
+}
+
+// DeleteFilter removes the filter saved in store as name.
+func DeleteFilter(store Store, name string) error {
+	return updateFilters(store, func(filters map[string]string) error {
+		if _, ok := filters[name]; !ok {
+			return fmt.Errorf("%w called %q", ErrNoFilter, name)
+		}
+		delete(filters, name)
+		return nil
+	})
+}
+
+func updateFilters(store Store, fn func(filters map[string]string) error) error {
+	meta, ok := store.(MetaStore)
+	if !ok {
+		return ErrNoMeta
+	}
+	filters, err := SavedFilters(store)
+	if err != nil {
+		return err
+	}
+	if err := fn(filters); err != nil {
+		return err
+	}
+
+	raw, err := json.Marshal(filters)
+	if err != nil {
+		return err
+	}
+	return meta.PutMeta(filtersKey, raw)
+}
+
+func validFilterName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,133 @@
+package todo
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestFilter(t *testing.T) {
+	inLocation(t, "UTC")
+	// A Friday.
+	now := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	at(t, now)
+	day := func(d, hh int) time.Time { return time.Date(2025, 1, d, hh, 0, 0, 0, time.UTC) }
+	items := []ParsedTodoItem{
+		{ID: 1, Todo: "Buy milk", Tags: []string{"shopping"}, Contexts: []string{"town"}, Due: day(9, 0), Priority: PriorityHigh},
+		{ID: 2, Todo: "Write the invoice", Tags: []string{"work"}, Project: "site", Due: day(10, 17), Priority: PriorityMedium},
+		{ID: 3, Todo: "Call the bank", Tags: []string{"work"}, Due: day(14, 0), Completed: true, CompletedAt: day(8, 9)},
+		{ID: 4, Todo: "Water the plants", Contexts: []string{"home"}, Repeat: "FREQ=DAILY", Parent: 1, Priority: PriorityLow},
+		{ID: 5, Todo: "next friday party", Inbox: true, Snoozed: 3},
+	}
+	tests := []struct {
+		expr string
+		want []int
+	}{
+		{"tag:work", []int{2, 3}},
+		{"#work", []int{2, 3}},
+		{"tag!=work", []int{1, 4, 5}},
+		{"@home", []int{4}},
+		{"context:town", []int{1}},
+		{"+site", []int{2}},
+		{"project:none", []int{1, 3, 4, 5}},
+		{"priority>=medium", []int{1, 2}},
+		{"priority=low", []int{4}},
+		{"done", []int{3}},
+		{"open", []int{1, 2, 4, 5}},
+		{"overdue", []int{1}},
+		{"repeating", []int{4}},
+		{"inbox", []int{5}},
+		{"snoozed>=3", []int{5}},
+		{"id:2", []int{2}},
+		{"parent:1", []int{4}},
+		{"parent:none", []int{1, 2, 3, 5}},
+		{"due:none", []int{4, 5}},
+		{"due!=none", []int{1, 2, 3}},
+		{"inv", []int{2}},
+		{"text:bank", []int{3}},
+
+		// Dates without a time compare by day.
+		{"due<=2025-01-10", []int{1, 2}},
+		{"due=2025-01-10", []int{2}},
+		{"due!=2025-01-10", []int{1, 3, 4, 5}},
+		{"due<2025-01-10T12:00", []int{1}},
+		{"completed>=2025-01-08", []int{3}},
+
+		// Relative dates are from now.
+		{"due<today", []int{1}},
+		{"due<=tomorrow", []int{1, 2}},
+		{"due>+2d", []int{3}},
+
+		// Terms next to each other are joined with and, which binds tighter
+		// than or.
+		{"tag:work open", []int{2}},
+		{"tag:work and open", []int{2}},
+		{"#shopping or tag:work done", []int{1, 3}},
+		{"(#shopping or tag:work) and done", []int{3}},
+		{"#shopping or #work and not done", []int{1, 2}},
+
+		// Negation binds tightest of all.
+		{"not done", []int{1, 2, 4, 5}},
+		{"not not done", []int{3}},
+		{"not (done or inbox)", []int{1, 2, 4}},
+		{"not done tag:work", []int{2}},
+
+		// Quoted words are always text, and quotes let values have spaces.
+		{`"done"`, nil},
+		{`"the bank"`, []int{3}},
+		{`'next friday'`, []int{5}},
+		{`due<"next friday"`, []int{1, 2, 3}},
+	}
+	for _, tt := range tests {
+		f, err := ParseFilter(tt.expr)
+		if err != nil {
+			t.Errorf("ParseFilter(%q): %v", tt.expr, err)
+			continue
+		}
+		var got []int
+		for _, item := range items {
+			if f.Match(item, now) {
+				got = append(got, item.ID)
+			}
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%q matches %v, want %v", tt.expr, got, tt.want)
+		}
+		if f.String() != tt.expr {
+			t.Errorf("String() = %q, want %q", f.String(), tt.expr)
+		}
+	}
+}
+
+func TestParseFilterErrors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"   ",
+		"(tag:work",
+		"tag:work)",
+		"tag:work and",
+		"or done",
+		"done AND",
+		"not",
+		"()",
+		`"unterminated`,
+		"tag<work",
+		"project>site",
+		"text>inv",
+		"status<doing",
+		"status:sleeping",
+		"priority>=urgent",
+		"due<someday",
+		"due<none",
+		"id:three",
+		"colour:red",
+		"tag:",
+		"due=<today",
+		"!!!",
+	} {
+		if _, err := ParseFilter(expr); !errors.Is(err, ErrBadFilter) {
+			t.Errorf("ParseFilter(%q) error = %v, want ErrBadFilter", expr, err)
+		}
+	}
+}
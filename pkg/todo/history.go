@@ -0,0 +1,232 @@
+package todo
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"slices"
+	"time"
+)
+
+// historyKey is the meta key the history of every item is saved under, as
+// a JSON object of item ID to its events, oldest first.
+const historyKey = "history"
+
+// EventKind is what kind of change an Event made to an item.
+type EventKind string
+
+const (
+	EventCreated   EventKind = "created"
+	EventEdited    EventKind = "edited"
+	EventCompleted EventKind = "completed"
+	EventReopened  EventKind = "reopened"
+	EventPostponed EventKind = "postponed"
+	EventDeleted   EventKind = "deleted"
+	EventRestored  EventKind = "restored"
+	EventPurged    EventKind = "purged"
+	// EventArchived is an item being moved out of the store into an
+	// archive, after which it isn't there to change.
+	EventArchived EventKind = "archived"
+)
+
+// Event is one change made to an item. Fields are the fields of the item,
+// as they are named in its JSON, that the change set, with null for the
+// ones it cleared, so that replaying an item's events in turn gives it as
+// it is now.
+type Event struct {
+	At   time.Time `json:"at"`
+	Kind EventKind `json:"kind"`
+	// Source is the command that made the change, such as "done 5", or
+	// empty if it was made without going through a Journal, as the server
+	// does, and only noticed afterwards.
+	Source string                     `json:"source,omitempty"`
+	Fields map[string]json.RawMessage `json:"fields,omitempty"`
+}
+
+// History returns the events of the item with id, oldest first, including
+// one for any change made to it since that wasn't recorded.
+func History(store Store, id int) ([]Event, error) {
+	meta, ok := store.(MetaStore)
+	if !ok {
+		return nil, ErrNoMeta
+	}
+	h, err := loadHistory(meta)
+	if err != nil {
+		return nil, err
+	}
+	current := map[int]*ParsedTodoItem{}
+	item, _, err := locate(store, id)
+	switch {
+	case err == nil:
+		current[id] = &item
+	case errors.Is(err, ErrNotFound) && len(h[id]) > 0:
+		current[id] = nil
+	default:
+		return nil, err
+	}
+	if h.catchUp(current, time.Now()) {
+		if err := saveHistory(meta, h); err != nil {
+			return nil, err
+		}
+	}
+	return h[id], nil
+}
+
+// Replay returns the item events leave, reporting false if they leave none,
+// as when the item has been purged.
+func Replay(events []Event) (ParsedTodoItem, bool) {
+	var item ParsedTodoItem
+	fields, ok := replayFields(events)
+	if !ok {
+		return item, false
+	}
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return item, false
+	}
+	return item, json.Unmarshal(data, &item) == nil
+}
+
+func replayFields(events []Event) (map[string]json.RawMessage, bool) {
+	fields := map[string]json.RawMessage{}
+	exists := false
+	for _, e := range events {
+		if e.Kind == EventPurged || e.Kind == EventArchived {
+			fields, exists = map[string]json.RawMessage{}, false
+			continue
+		}
+		exists = true
+		for name, value := range e.Fields {
+			if string(value) == "null" {
+				delete(fields, name)
+			} else {
+				fields[name] = value
+			}
+		}
+	}
+	return fields, exists
+}
+
+// Diff returns the fields of the item events leave that the event at i
+// changed, each with the value it had before, or nil if it had none.
+func Diff(events []Event, i int) map[string]json.RawMessage {
+	before, _ := replayFields(events[:i])
+	diff := map[string]json.RawMessage{}
+	for name := range events[i].Fields {
+		diff[name] = before[name]
+	}
+	return diff
+}
+
+// itemHistory is what a list keeps under historyKey.
+type itemHistory map[int][]Event
+
+// record adds the event for an item changing from before to after, where
+// either is nil if the item didn't exist, if it changed at all.
+func (h itemHistory) record(id int, before, after *ParsedTodoItem, source string, at time.Time) {
+	fields := changedFields(before, after)
+	if after != nil && len(fields) == 0 {
+		return
+	}
+	h[id] = append(h[id], Event{At: at, Kind: eventKind(before, after), Source: source, Fields: fields})
+}
+
+// catchUp records a change with no source for every item in current whose
+// events don't leave it as it is there, where a nil item is one that
+// doesn't exist, reporting whether there were any.
+func (h itemHistory) catchUp(current map[int]*ParsedTodoItem, at time.Time) bool {
+	var ids []int
+	for id := range current {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	changed := false
+	for _, id := range ids {
+		var was *ParsedTodoItem
+		if item, ok := Replay(h[id]); ok {
+			was = &item
+		}
+		now, when := current[id], at
+		switch {
+		case now == nil && was == nil:
+			continue
+		case now != nil && was != nil && len(changedFields(was, now)) == 0:
+			continue
+		case was == nil && !now.CreatedAt.IsZero():
+			// Items from before history was kept are taken to have been
+			// created as they are.
+			when = now.CreatedAt
+		}
+		h.record(id, was, now, "", when)
+		changed = true
+	}
+	return changed
+}
+
+// eventKind returns what kind of change an item changing from before to
+// after is.
+func eventKind(before, after *ParsedTodoItem) EventKind {
+	switch {
+	case before == nil:
+		return EventCreated
+	case after == nil:
+		return EventPurged
+	case before.DeletedAt.IsZero() && !after.DeletedAt.IsZero():
+		return EventDeleted
+	case !before.DeletedAt.IsZero() && after.DeletedAt.IsZero():
+		return EventRestored
+	case !before.Completed && after.Completed:
+		return EventCompleted
+	case before.Completed && !after.Completed:
+		return EventReopened
+	case !before.Due.IsZero() && after.Due.After(before.Due):
+		return EventPostponed
+	}
+	return EventEdited
+}
+
+// changedFields returns the JSON fields of after that differ from those of
+// before, with null for those after doesn't have.
+func changedFields(before, after *ParsedTodoItem) map[string]json.RawMessage {
+	from, to := itemFields(before), itemFields(after)
+	changed := map[string]json.RawMessage{}
+	for name, value := range to {
+		if !bytes.Equal(from[name], value) {
+			changed[name] = value
+		}
+	}
+	for name := range from {
+		if _, ok := to[name]; !ok && after != nil {
+			changed[name] = json.RawMessage("null")
+		}
+	}
+	return changed
+}
+
+func itemFields(item *ParsedTodoItem) map[string]json.RawMessage {
+	fields := map[string]json.RawMessage{}
+	if item == nil {
+		return fields
+	}
+	if data, err := json.Marshal(item); err == nil {
+		json.Unmarshal(data, &fields)
+	}
+	return fields
+}
+
+func loadHistory(meta MetaStore) (itemHistory, error) {
+	h := itemHistory{}
+	raw, err := meta.GetMeta(historyKey)
+	if err != nil || raw == nil {
+		return h, err
+	}
+	return h, json.Unmarshal(raw, &h)
+}
+
+func saveHistory(meta MetaStore, h itemHistory) error {
+	raw, err := json.Marshal(h)
+	if err != nil {
+		return err
+	}
+	return meta.PutMeta(historyKey, raw)
+}
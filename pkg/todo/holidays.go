@@ -0,0 +1,48 @@
+package todo
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// HolidayCalendar is a set of days, such as public holidays, that repeat
+// rules skipping holidays move off. It is keyed by dates as 2006-01-02, or
+// as 01-02 for a holiday on the same date every year.
+type HolidayCalendar map[string]bool
+
+// Holidays are the days repeat rules skipping holidays don't fall on. The
+// CLI reads them from the file the holidays setting names.
+var Holidays HolidayCalendar
+
+// Has reports whether the day t is on, in its own time zone, is a holiday.
+func (c HolidayCalendar) Has(t time.Time) bool {
+	return c[t.Format("2006-01-02")] || c[t.Format("01-02")]
+}
+
+// ReadHolidays reads a holiday calendar with a date to a line, as
+// 2025-12-25, or as 12-25 for every year, and anything after the date on
+// the line, such as the holiday's name, ignored. Blank lines and lines
+// starting with # are skipped.
+func ReadHolidays(r io.Reader) (HolidayCalendar, error) {
+	c := HolidayCalendar{}
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		layout := "2006-01-02"
+		if len(fields[0]) == len("01-02") {
+			layout = "01-02"
+		}
+		date, err := time.Parse(layout, fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %q isn't a date such as 2025-12-25, or 12-25 for every year", line, fields[0])
+		}
+		c[date.Format(layout)] = true
+	}
+	return c, scanner.Err()
+}
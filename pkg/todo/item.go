@@ -0,0 +1,139 @@
+// Package todo contains the types, input parsing and storage used by the
+// todo-app CLI, so that they can be reused by other tools.
+package todo
+
+import (
+	"time"
+)
+
+// TodoItem is the internal type used to store the JSON data that is
+// deserialised by the app.
+type TodoItem struct {
+	Todo     string   `json:"todo"`
+	Due      string   `json:"due,omitempty"`
+	Priority string   `json:"priority,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	Project  string   `json:"project,omitempty"`
+	Contexts []string `json:"contexts,omitempty"`
+	Repeat   string   `json:"repeat,omitempty"`
+	Remind   string   `json:"remind,omitempty"`
+	Estimate string   `json:"estimate,omitempty"`
+	Parent   int      `json:"parent,omitempty"`
+	Notes    string   `json:"notes,omitempty"`
+}
+
+// ParsedTodoItem is the same as the TodoItem type, albeit with the `Due` field
+// parsed to due the `time.Time` struct. It is the type that is saved in a
+// Store. Repeat holds the recurrence rule, in RRULE syntax, of items that
+// repeat, Remind the reminder times, as FormatOffsets writes them, of items
+// reminded of at their own times, Estimate how long it is expected to
+// take, as ParseDuration takes it, Parent the ID of the item a subtask
+// belongs to and BlockedBy the IDs of items that have to be done before
+// this one can be started. Notes is free-form, possibly multi-line, text to
+// go with the one line Todo. Status is "doing" for items that have been
+// started and empty otherwise; see CurrentStatus. Inbox is set on items
+// captured in the inbox that haven't been triaged yet. Snoozed counts the
+// times the item has been put off with Snooze, and Worked holds the times it
+// was worked on; see Spent.
+type ParsedTodoItem struct {
+	ID          int        `json:"id"`
+	Todo        string     `json:"todo"`
+	Due         time.Time  `json:"due,omitzero"`
+	Priority    Priority   `json:"priority,omitzero"`
+	Tags        []string   `json:"tags,omitempty"`
+	Project     string     `json:"project,omitempty"`
+	Contexts    []string   `json:"contexts,omitempty"`
+	Repeat      string     `json:"repeat,omitempty"`
+	Remind      string     `json:"remind,omitempty"`
+	Estimate    string     `json:"estimate,omitempty"`
+	Parent      int        `json:"parent,omitempty"`
+	BlockedBy   []int      `json:"blocked_by,omitempty"`
+	Notes       string     `json:"notes,omitempty"`
+	Status      string     `json:"status,omitempty"`
+	Inbox       bool       `json:"inbox,omitempty"`
+	Snoozed     int        `json:"snoozed,omitempty"`
+	Worked      []Interval `json:"worked,omitempty"`
+	CreatedAt   time.Time  `json:"created_at,omitzero"`
+	Completed   bool       `json:"completed,omitempty"`
+	CompletedAt time.Time  `json:"completed_at,omitzero"`
+	DeletedAt   time.Time  `json:"deleted_at,omitzero"`
+}
+
+// DueAllDay reports whether the item is due on a day rather than at a
+// particular time. Dates given without a time are saved as midnight at the
+// start of the day, so a due time of exactly midnight counts as all day.
+func (item ParsedTodoItem) DueAllDay() bool {
+	if item.Due.IsZero() {
+		return false
+	}
+	h, m, s := item.Due.Clock()
+	return h == 0 && m == 0 && s == 0 && item.Due.Nanosecond() == 0
+}
+
+// DueBy returns the moment after which the item is overdue: its due time, or
+// the end of the day for items due all day.
+func (item ParsedTodoItem) DueBy() time.Time {
+	if item.DueAllDay() {
+		return item.Due.AddDate(0, 0, 1)
+	}
+	return item.Due
+}
+
+// IsOverdue reports whether the item's due date has passed at now. Items
+// due all day aren't overdue until the day is over, in the time zone the
+// date was given in. Items that have been done are never overdue.
+func (item ParsedTodoItem) IsOverdue(now time.Time) bool {
+	if item.Due.IsZero() || item.Completed {
+		return false
+	}
+	return !now.Before(item.DueBy())
+}
+
+// Recurrence returns the item's repeat rule, or false if it doesn't repeat.
+func (item ParsedTodoItem) Recurrence() (Recurrence, bool) {
+	if item.Repeat == "" {
+		return Recurrence{}, false
+	}
+	r, err := ParseRepeat(item.Repeat, item.Due)
+	return r, err == nil
+}
+
+// NextOccurrence returns a copy of a repeating item due on the next date
+// given by its rule, ready to be added to the store, or false if the item
+// doesn't repeat or its rule has run out. Items without a due date repeat
+// from now. A rule with a COUNT is saved with the count left.
+func (item ParsedTodoItem) NextOccurrence(now time.Time) (ParsedTodoItem, bool) {
+	r, ok := item.Recurrence()
+	if !ok {
+		return ParsedTodoItem{}, false
+	}
+
+	prev := item.Due
+	if prev.IsZero() {
+		y, m, d := now.In(Location).Date()
+		prev = time.Date(y, m, d, 0, 0, 0, 0, Location)
+	}
+
+	due, n := r.NextAfter(prev, now)
+	if due.IsZero() {
+		return ParsedTodoItem{}, false
+	}
+
+	next := item
+	next.ID = 0
+	next.Due = due
+	if r.Count > 0 {
+		r.Count -= n
+		next.Repeat = r.String()
+	}
+	next.CreatedAt = now
+	next.Completed = false
+	next.CompletedAt = time.Time{}
+	next.Status = ""
+	next.Snoozed = 0
+	next.Worked = nil
+	next.DeletedAt = time.Time{}
+	next.Tags = append([]string(nil), item.Tags...)
+	next.Contexts = append([]string(nil), item.Contexts...)
+	return next, true
+}
@@ -0,0 +1,474 @@
+package todo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// JournalSize is how many operations the journal keeps for Undo.
+const JournalSize = 50
+
+// journalKey is the meta key the journal is saved under.
+const journalKey = "journal"
+
+// ErrNothingToUndo is returned by Undo when the journal has no operations.
+var ErrNothingToUndo = errors.New("nothing to undo")
+
+// ErrNothingToRedo is returned by Redo when no operation has been undone
+// since the last one was made.
+var ErrNothingToRedo = errors.New("nothing to redo")
+
+// ErrChangedSince is returned by Undo and Redo when an item an operation
+// changed has been changed again since, so undoing it would lose that.
+var ErrChangedSince = errors.New("changed since")
+
+// Operation is one command's changes to the list, as the journal keeps
+// them.
+type Operation struct {
+	// Command is what was run, e.g. "done 5".
+	Command string    `json:"command"`
+	At      time.Time `json:"at"`
+	Changes []Change  `json:"changes"`
+}
+
+// Change is how an operation changed one item. Before is nil for an item
+// the operation added, and an item in the trash has DeletedAt set.
+type Change struct {
+	ID     int             `json:"id"`
+	Before *ParsedTodoItem `json:"before,omitempty"`
+	After  ParsedTodoItem  `json:"after"`
+}
+
+// journal is what a list keeps under journalKey: the operations that can
+// be undone, oldest first, and the ones that have been undone and can be
+// redone, the most recently undone last.
+type journal struct {
+	Done   []Operation `json:"done,omitempty"`
+	Undone []Operation `json:"undone,omitempty"`
+}
+
+// Journal is a Store recording the changes made through it, which it saves
+// as one Operation when it is closed, for Undo, and as Events in the
+// history of each item it changed. Stores that aren't MetaStores aren't
+// journaled.
+type Journal struct {
+	Store
+	command string
+	// undo is whether the changes are journaled for Undo as well as kept in
+	// the items' histories.
+	undo     bool
+	changes  []Change
+	purged   []ParsedTodoItem
+	archived []ParsedTodoItem
+	// before is called before the first change, see BeforeChange.
+	before func() error
+	// after is called with the changes once they are saved, see
+	// AfterCommit.
+	after func([]Change)
+}
+
+// NewJournal returns store journaling its changes as the operation
+// command.
+func NewJournal(store Store, command string) *Journal {
+	return &Journal{Store: store, command: command, undo: true}
+}
+
+// NewRecorder returns store recording its changes in the items' histories
+// as made by command, without journaling them for Undo.
+func NewRecorder(store Store, command string) *Journal {
+	return &Journal{Store: store, command: command}
+}
+
+// BeforeChange makes fn be called before the first change made through the
+// journal, such as to back the store up, which stops the change if fn
+// fails.
+func (j *Journal) BeforeChange(fn func() error) {
+	j.before = fn
+}
+
+// AfterCommit makes fn be called with the changes made through the journal
+// once Close has saved them, such as to tell other programs of them.
+func (j *Journal) AfterCommit(fn func(changes []Change)) {
+	j.after = fn
+}
+
+func (j *Journal) beforeChange() error {
+	if j.before == nil {
+		return nil
+	}
+	fn := j.before
+	j.before = nil
+	return fn()
+}
+
+// record notes that the item with id is now after, having been before.
+// Only the state before the first change to an item in an operation is
+// kept.
+func (j *Journal) record(id int, before *ParsedTodoItem, after ParsedTodoItem) {
+	for i := range j.changes {
+		if j.changes[i].ID == id {
+			j.changes[i].After = after
+			return
+		}
+	}
+	j.changes = append(j.changes, Change{ID: id, Before: before, After: after})
+}
+
+// Add implements Store.
+func (j *Journal) Add(item ParsedTodoItem) (ParsedTodoItem, error) {
+	if err := j.beforeChange(); err != nil {
+		return item, err
+	}
+	saved, err := j.Store.Add(item)
+	if err == nil {
+		j.record(saved.ID, nil, saved)
+	}
+	return saved, err
+}
+
+// Update implements Store.
+func (j *Journal) Update(item ParsedTodoItem) error {
+	before, err := j.Store.Get(item.ID)
+	if err != nil {
+		return err
+	}
+	if err := j.beforeChange(); err != nil {
+		return err
+	}
+	if err := j.Store.Update(item); err != nil {
+		return err
+	}
+	after, err := j.Store.Get(item.ID)
+	if err == nil {
+		j.record(item.ID, &before, after)
+	}
+	return err
+}
+
+// Delete implements Store.
+func (j *Journal) Delete(id int) error {
+	before, err := j.Store.Get(id)
+	if err != nil {
+		return err
+	}
+	if err := j.beforeChange(); err != nil {
+		return err
+	}
+	if err := j.Store.Delete(id); err != nil {
+		return err
+	}
+	after, _, err := locate(j.Store, id)
+	if err == nil {
+		j.record(id, &before, after)
+	}
+	return err
+}
+
+// Restore implements Store.
+func (j *Journal) Restore(id int) error {
+	before, _, err := locate(j.Store, id)
+	if err != nil {
+		return err
+	}
+	if err := j.beforeChange(); err != nil {
+		return err
+	}
+	if err := j.Store.Restore(id); err != nil {
+		return err
+	}
+	after, err := j.Store.Get(id)
+	if err == nil {
+		j.record(id, &before, after)
+	}
+	return err
+}
+
+// Purge implements Store.
+func (j *Journal) Purge() (int, error) {
+	trash, err := j.Store.Trash()
+	if err != nil {
+		return 0, err
+	}
+	if err := j.beforeChange(); err != nil {
+		return 0, err
+	}
+	n, err := j.Store.Purge()
+	if err == nil {
+		j.purged = append(j.purged, trash...)
+	}
+	return n, err
+}
+
+// Remove implements Remover, recording the items as archived, which is
+// what Remove is for.
+func (j *Journal) Remove(ids ...int) error {
+	remover, ok := j.Store.(Remover)
+	if !ok {
+		return ErrCantRemove
+	}
+	var archived []ParsedTodoItem
+	for _, id := range ids {
+		item, _, err := locate(j.Store, id)
+		switch {
+		case err == nil:
+			archived = append(archived, item)
+		case !errors.Is(err, ErrNotFound):
+			return err
+		}
+	}
+	if err := j.beforeChange(); err != nil {
+		return err
+	}
+	if err := remover.Remove(ids...); err != nil {
+		return err
+	}
+	j.archived = append(j.archived, archived...)
+	return nil
+}
+
+// SearchWords implements Searcher so the store's index is still used.
+func (j *Journal) SearchWords(terms []string) ([]ParsedTodoItem, error) {
+	if searcher, ok := j.Store.(Searcher); ok {
+		return searcher.SearchWords(terms)
+	}
+	return j.Store.List()
+}
+
+// GetMeta implements MetaStore.
+func (j *Journal) GetMeta(key string) ([]byte, error) {
+	if meta, ok := j.Store.(MetaStore); ok {
+		return meta.GetMeta(key)
+	}
+	return nil, nil
+}
+
+// PutMeta implements MetaStore.
+func (j *Journal) PutMeta(key string, value []byte) error {
+	if meta, ok := j.Store.(MetaStore); ok {
+		return meta.PutMeta(key, value)
+	}
+	return ErrNoMeta
+}
+
+// MetaKeys implements MetaStore.
+func (j *Journal) MetaKeys() ([]string, error) {
+	if meta, ok := j.Store.(MetaStore); ok {
+		return meta.MetaKeys()
+	}
+	return nil, nil
+}
+
+// Close saves the changes made as an operation, if there were any, and
+// closes the store. A new operation can't be redone past, so the undone
+// ones are forgotten.
+func (j *Journal) Close() error {
+	changes := j.changes
+	err := j.commit()
+	if cerr := j.Store.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil && j.after != nil && len(changes) > 0 {
+		j.after(changes)
+	}
+	return err
+}
+
+func (j *Journal) commit() error {
+	meta, ok := j.Store.(MetaStore)
+	if !ok || len(j.changes) == 0 && len(j.purged) == 0 && len(j.archived) == 0 {
+		return nil
+	}
+	now := time.Now()
+	changes, purged, archived := j.changes, j.purged, j.archived
+	j.changes, j.purged, j.archived = nil, nil, nil
+
+	// Changes made to the items before this operation without being
+	// recorded are caught up with first, so its events start from the
+	// items as it found them.
+	h, err := loadHistory(meta)
+	if err != nil {
+		return err
+	}
+	found := map[int]*ParsedTodoItem{}
+	for _, c := range changes {
+		found[c.ID] = c.Before
+	}
+	for i := range purged {
+		found[purged[i].ID] = &purged[i]
+	}
+	for i := range archived {
+		found[archived[i].ID] = &archived[i]
+	}
+	h.catchUp(found, now)
+	for _, c := range changes {
+		h.record(c.ID, c.Before, &c.After, j.command, now)
+	}
+	for i := range purged {
+		h.record(purged[i].ID, &purged[i], nil, j.command, now)
+	}
+	for _, item := range archived {
+		h[item.ID] = append(h[item.ID], Event{At: now, Kind: EventArchived, Source: j.command})
+	}
+	if err := saveHistory(meta, h); err != nil {
+		return err
+	}
+
+	if !j.undo || len(changes) == 0 {
+		return nil
+	}
+	jl, err := loadJournal(meta)
+	if err != nil {
+		return err
+	}
+	jl.Done = append(jl.Done, Operation{Command: j.command, At: now, Changes: changes})
+	if len(jl.Done) > JournalSize {
+		jl.Done = jl.Done[len(jl.Done)-JournalSize:]
+	}
+	jl.Undone = nil
+	return saveJournal(meta, jl)
+}
+
+// Undo reverses the most recent operation journaled on store, which may be
+// a Journal made with NewRecorder to keep the changes in the items'
+// histories, and returns it. Items it added are moved to the
+// trash. It fails with ErrChangedSince, changing nothing, if an item has
+// been changed since by something that isn't journaled, such as a sync.
+func Undo(store Store) (Operation, error) {
+	return replay(store, true)
+}
+
+// Redo makes the most recently undone operation again, and returns it.
+func Redo(store Store) (Operation, error) {
+	return replay(store, false)
+}
+
+func replay(store Store, undo bool) (Operation, error) {
+	meta, ok := store.(MetaStore)
+	if !ok {
+		return Operation{}, ErrNoMeta
+	}
+	jl, err := loadJournal(meta)
+	if err != nil {
+		return Operation{}, err
+	}
+	from, to := &jl.Done, &jl.Undone
+	if !undo {
+		from, to = to, from
+	}
+	if len(*from) == 0 {
+		if !undo {
+			return Operation{}, ErrNothingToRedo
+		}
+		return Operation{}, ErrNothingToUndo
+	}
+	op := (*from)[len(*from)-1]
+
+	// Every item is checked before anything is changed.
+	type step struct {
+		id      int
+		want    *ParsedTodoItem
+		trashed bool
+	}
+	var steps []step
+	for i := range op.Changes {
+		c := op.Changes[i]
+		if undo {
+			// Changes are undone last first.
+			c = op.Changes[len(op.Changes)-1-i]
+		}
+		have, want := &c.After, c.Before
+		if !undo {
+			have, want = c.Before, &c.After
+		}
+		current, trashed, err := locate(store, c.ID)
+		switch {
+		case errors.Is(err, ErrNotFound):
+			return op, fmt.Errorf("item %d has been purged or archived", c.ID)
+		case err != nil:
+			return op, err
+		case have != nil && !sameItem(*have, current):
+			return op, fmt.Errorf("item %d has been %w", c.ID, ErrChangedSince)
+		}
+		steps = append(steps, step{id: c.ID, want: want, trashed: trashed})
+	}
+
+	for _, s := range steps {
+		wantTrash := s.want == nil || !s.want.DeletedAt.IsZero()
+		switch {
+		case wantTrash && s.trashed:
+			// Items in the trash can't be changed, and are restored as they
+			// were when they go back on the list.
+		case wantTrash:
+			if s.want != nil {
+				item := *s.want
+				item.DeletedAt = time.Time{}
+				if err := store.Update(item); err != nil {
+					return op, err
+				}
+			}
+			if err := store.Delete(s.id); err != nil {
+				return op, err
+			}
+		default:
+			if s.trashed {
+				if err := store.Restore(s.id); err != nil {
+					return op, err
+				}
+			}
+			if err := store.Update(*s.want); err != nil {
+				return op, err
+			}
+		}
+	}
+
+	*from = (*from)[:len(*from)-1]
+	*to = append(*to, op)
+	return op, saveJournal(meta, jl)
+}
+
+// locate returns the item with id wherever it is, reporting whether it is
+// in the trash.
+func locate(store Store, id int) (ParsedTodoItem, bool, error) {
+	item, err := store.Get(id)
+	if err == nil || !errors.Is(err, ErrNotFound) {
+		return item, false, err
+	}
+	trash, err := store.Trash()
+	if err != nil {
+		return ParsedTodoItem{}, false, err
+	}
+	for _, item := range trash {
+		if item.ID == id {
+			return item, true, nil
+		}
+	}
+	return ParsedTodoItem{}, false, fmt.Errorf("%w with ID %d", ErrNotFound, id)
+}
+
+// sameItem reports whether a and b are the same but for when they were
+// moved to the trash.
+func sameItem(a, b ParsedTodoItem) bool {
+	a.DeletedAt, b.DeletedAt = time.Time{}, time.Time{}
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(ja) == string(jb)
+}
+
+func loadJournal(meta MetaStore) (journal, error) {
+	var jl journal
+	raw, err := meta.GetMeta(journalKey)
+	if err != nil || raw == nil {
+		return jl, err
+	}
+	return jl, json.Unmarshal(raw, &jl)
+}
+
+func saveJournal(meta MetaStore, jl journal) error {
+	raw, err := json.Marshal(jl)
+	if err != nil {
+		return err
+	}
+	return meta.PutMeta(journalKey, raw)
+}
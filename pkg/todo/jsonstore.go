@@ -0,0 +1,488 @@
+package todo
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/buck06191/todo-app/pkg/seal"
+)
+
+func init() {
+	Register("json", func(url string) (Store, error) {
+		return NewJSONStore(strings.TrimPrefix(url, "json://")), nil
+	})
+}
+
+// fileData is the on-disk layout of a JSONStore. Deleted items are moved to
+// Trash rather than being thrown away so they can be restored. Meta holds
+// the values saved with PutMeta. Version is how many of jsonMigrations
+// have been applied, 0 for files from before it was kept.
+type fileData struct {
+	Version int                        `json:"version"`
+	NextID  int                        `json:"next_id"`
+	Items   []ParsedTodoItem           `json:"items"`
+	Trash   []ParsedTodoItem           `json:"trash,omitempty"`
+	Meta    map[string]json.RawMessage `json:"meta,omitempty"`
+}
+
+// jsonMigrations bring the files of older versions up to date when they
+// are read, in order, and the file is saved at the latest version the
+// next time it is changed. Existing entries must never be changed; add a
+// new one instead.
+var jsonMigrations = []func(data *fileData){
+	// Items from before items had IDs are numbered.
+	func(data *fileData) {
+		data.assignIDs()
+		for i := range data.Items {
+			if data.Items[i].ID == 0 {
+				data.Items[i].ID = data.NextID
+				data.NextID++
+			}
+		}
+	},
+	// Tags and contexts from before they were normalized are, and lose
+	// any duplicates that makes.
+	func(data *fileData) {
+		for _, items := range [][]ParsedTodoItem{data.Items, data.Trash} {
+			for i := range items {
+				items[i].Tags = AddTags(nil, items[i].Tags...)
+				items[i].Contexts = AddContexts(nil, items[i].Contexts...)
+			}
+		}
+	},
+}
+
+// sealedHeader starts the file of an encrypted JSONStore, which goes on with
+// the sealed JSON in base64.
+const sealedHeader = "todo-app sealed\n"
+
+// JSONStore is a Store that keeps the whole list in a single JSON file. The
+// file is rewritten on every change. Once encrypted, with UseKey, the file
+// is sealed as a whole.
+//
+// Other namespaces are kept in files of their own in a directory next to
+// it, named after the file: the "work" list of todos.json is saved in
+// todos.lists/work.json.
+type JSONStore struct {
+	path string
+	// root is the path of the store the namespace belongs to, which is
+	// path for the store itself.
+	root string
+	// key is the key the file is encrypted with, if it is.
+	key *seal.Key
+}
+
+// NewJSONStore returns a JSONStore saving to the file at path. The file
+// doesn't need to exist yet.
+func NewJSONStore(path string) *JSONStore {
+	return &JSONStore{path: path, root: path}
+}
+
+// DefaultPath returns the location of the store file when no other path has
+// been given, i.e. `~/.todo/todos.json`.
+func DefaultPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".todo", "todos.json"), nil
+}
+
+// Path returns the file the store saves to.
+func (s *JSONStore) Path() string {
+	return s.path
+}
+
+// Add implements Store.
+func (s *JSONStore) Add(item ParsedTodoItem) (ParsedTodoItem, error) {
+	err := s.update(func(data *fileData) error {
+		item.ID = data.NextID
+		data.NextID++
+		data.Items = append(data.Items, item)
+		return nil
+	})
+	return item, err
+}
+
+// List implements Store.
+func (s *JSONStore) List() ([]ParsedTodoItem, error) {
+	data, err := s.load()
+	return data.Items, err
+}
+
+// Get implements Store.
+func (s *JSONStore) Get(id int) (ParsedTodoItem, error) {
+	data, err := s.load()
+	if err != nil {
+		return ParsedTodoItem{}, err
+	}
+
+	i, err := data.find(id)
+	if err != nil {
+		return ParsedTodoItem{}, err
+	}
+	return data.Items[i], nil
+}
+
+// Update implements Store.
+func (s *JSONStore) Update(item ParsedTodoItem) error {
+	return s.update(func(data *fileData) error {
+		i, err := data.find(item.ID)
+		if err != nil {
+			return err
+		}
+		data.Items[i] = item
+		return nil
+	})
+}
+
+// Delete implements Store.
+func (s *JSONStore) Delete(id int) error {
+	return s.update(func(data *fileData) error {
+		i, err := data.find(id)
+		if err != nil {
+			return err
+		}
+
+		item := data.Items[i]
+		item.DeletedAt = time.Now()
+		data.Items = append(data.Items[:i], data.Items[i+1:]...)
+		data.Trash = append(data.Trash, item)
+		return nil
+	})
+}
+
+// Trash implements Store.
+func (s *JSONStore) Trash() ([]ParsedTodoItem, error) {
+	data, err := s.load()
+	return data.Trash, err
+}
+
+// Restore implements Store.
+func (s *JSONStore) Restore(id int) error {
+	return s.update(func(data *fileData) error {
+		for i, item := range data.Trash {
+			if item.ID == id {
+				item.DeletedAt = time.Time{}
+				data.Trash = append(data.Trash[:i], data.Trash[i+1:]...)
+				data.Items = append(data.Items, item)
+				return nil
+			}
+		}
+		return fmt.Errorf("%w with ID %d in the trash", ErrNotFound, id)
+	})
+}
+
+// Purge implements Store.
+func (s *JSONStore) Purge() (int, error) {
+	var n int
+	err := s.update(func(data *fileData) error {
+		n = len(data.Trash)
+		data.Trash = nil
+		return nil
+	})
+	return n, err
+}
+
+// Remove implements Remover.
+func (s *JSONStore) Remove(ids ...int) error {
+	return s.update(func(data *fileData) error {
+		data.Items = withoutIDs(data.Items, ids)
+		data.Trash = withoutIDs(data.Trash, ids)
+		return nil
+	})
+}
+
+// Replace implements Replacer, writing the file once.
+func (s *JSONStore) Replace(items, trash []ParsedTodoItem) error {
+	return s.update(func(data *fileData) error {
+		data.Items = mergeByID(nil, items)
+		data.Trash = mergeByID(nil, trash)
+		data.assignIDs()
+		return nil
+	})
+}
+
+// Import implements Importer.
+func (s *JSONStore) Import(items, trash []ParsedTodoItem) error {
+	return s.update(func(data *fileData) error {
+		data.Items = mergeByID(data.Items, items)
+		data.Trash = mergeByID(data.Trash, trash)
+		data.assignIDs()
+		return nil
+	})
+}
+
+// GetMeta implements MetaStore.
+func (s *JSONStore) GetMeta(key string) ([]byte, error) {
+	data, err := s.load()
+	return data.Meta[key], err
+}
+
+// PutMeta implements MetaStore.
+func (s *JSONStore) PutMeta(key string, value []byte) error {
+	if value != nil && !json.Valid(value) {
+		return fmt.Errorf("%s: value isn't valid JSON", key)
+	}
+	return s.update(func(data *fileData) error {
+		if value == nil {
+			delete(data.Meta, key)
+			return nil
+		}
+		if data.Meta == nil {
+			data.Meta = make(map[string]json.RawMessage)
+		}
+		data.Meta[key] = value
+		return nil
+	})
+}
+
+// MetaKeys implements MetaStore.
+func (s *JSONStore) MetaKeys() ([]string, error) {
+	data, err := s.load()
+	return slices.Sorted(maps.Keys(data.Meta)), err
+}
+
+// Namespace implements Namespacer.
+func (s *JSONStore) Namespace(name string) (Store, error) {
+	if name == "" {
+		return &JSONStore{path: s.root, root: s.root, key: s.key}, nil
+	}
+	path := filepath.Join(s.listsDir(), filepath.FromSlash(name)+".json")
+	return &JSONStore{path: path, root: s.root, key: s.key}, nil
+}
+
+// Namespaces implements Namespacer.
+func (s *JSONStore) Namespaces() ([]string, error) {
+	dir := s.listsDir()
+	var names []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) && path == dir {
+			return filepath.SkipDir
+		}
+		// Skip the temporary files written by save.
+		if err != nil || d.IsDir() || filepath.Ext(path) != ".json" || strings.HasPrefix(d.Name(), ".") {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		names = append(names, filepath.ToSlash(strings.TrimSuffix(rel, ".json")))
+		return nil
+	})
+	slices.Sort(names)
+	return names, err
+}
+
+// DropNamespace implements Namespacer.
+func (s *JSONStore) DropNamespace(name string) error {
+	list, err := s.Namespace(name)
+	if err != nil {
+		return err
+	}
+	path := list.(*JSONStore).path
+	os.Remove(path + ".lock")
+	err = os.Remove(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// Encrypted implements Encrypter.
+func (s *JSONStore) Encrypted() (bool, error) {
+	raw, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	return bytes.HasPrefix(raw, []byte(sealedHeader)), err
+}
+
+// UseKey implements Encrypter, rewriting the files of the store and of
+// every other namespace in it that aren't encrypted yet.
+func (s *JSONStore) UseKey(key *seal.Key) error {
+	s.key = key
+	names, err := s.Namespaces()
+	if err != nil {
+		return err
+	}
+	for _, name := range append([]string{""}, names...) {
+		ns, err := s.Namespace(name)
+		if err != nil {
+			return err
+		}
+		list := ns.(*JSONStore)
+		if _, err := os.Stat(list.path); errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		// Loading checks the key against the files already encrypted.
+		data, err := list.load()
+		if err != nil {
+			return err
+		}
+		encrypted, err := list.Encrypted()
+		if err != nil {
+			return err
+		}
+		if encrypted {
+			continue
+		}
+		if err := list.save(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// listsDir is the directory the files of the other namespaces are kept in.
+func (s *JSONStore) listsDir() string {
+	return strings.TrimSuffix(s.root, filepath.Ext(s.root)) + ".lists"
+}
+
+// Close implements Store. The file isn't held open between calls so there
+// is nothing to do.
+func (s *JSONStore) Close() error {
+	return nil
+}
+
+// find returns the index of the item with the given ID.
+func (d *fileData) find(id int) (int, error) {
+	for i, item := range d.Items {
+		if item.ID == id {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("%w with ID %d", ErrNotFound, id)
+}
+
+// assignIDs moves NextID past the IDs of every item.
+func (d *fileData) assignIDs() {
+	for _, items := range [][]ParsedTodoItem{d.Items, d.Trash} {
+		for _, item := range items {
+			if item.ID >= d.NextID {
+				d.NextID = item.ID + 1
+			}
+		}
+	}
+	if d.NextID == 0 {
+		d.NextID = 1
+	}
+}
+
+// load reads the store file. A missing file is treated as an empty list so
+// that the first run doesn't need any setup.
+func (s *JSONStore) load() (fileData, error) {
+	var data fileData
+
+	raw, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		data.Version = len(jsonMigrations)
+		data.assignIDs()
+		return data, nil
+	}
+	if err != nil {
+		return data, err
+	}
+	if sealed, ok := bytes.CutPrefix(raw, []byte(sealedHeader)); ok {
+		if s.key == nil {
+			return data, fmt.Errorf("reading %s: %w", s.path, ErrEncrypted)
+		}
+		box, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(sealed)))
+		if err != nil {
+			return data, fmt.Errorf("reading %s: %w", s.path, err)
+		}
+		if raw, err = s.key.Open(box); err != nil {
+			return data, fmt.Errorf("reading %s: %w", s.path, err)
+		}
+	}
+
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return data, fmt.Errorf("reading %s: %w", s.path, err)
+	}
+	if data.Version > len(jsonMigrations) {
+		return data, fmt.Errorf("reading %s: %w: it is at version %d but this build only knows %d", s.path, ErrNewerSchema, data.Version, len(jsonMigrations))
+	}
+	for _, migrate := range jsonMigrations[data.Version:] {
+		migrate(&data)
+	}
+	data.Version = len(jsonMigrations)
+
+	data.assignIDs()
+	return data, nil
+}
+
+// save writes data to the store file. The data is written to a temporary
+// file in the same directory which is then renamed over the old file, so a
+// crash part way through never leaves a half-written store behind.
+func (s *JSONStore) save(data fileData) error {
+	dir := filepath.Dir(s.path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+
+	raw, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+	if s.key != nil {
+		box, err := s.key.Seal(raw)
+		if err != nil {
+			return err
+		}
+		raw = []byte(sealedHeader + base64.StdEncoding.EncodeToString(box) + "\n")
+	}
+
+	tmp, err := os.CreateTemp(dir, ".todos-*.json")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), s.path)
+}
+
+// update loads the store file, applies fn to it and saves the result.
+// Nothing is written if fn returns an error. The file is locked
+// throughout, so that other todo-apps changing it at the same time wait
+// their turn instead of losing the change.
+func (s *JSONStore) update(fn func(data *fileData) error) error {
+	release, err := lockFile(s.path)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	data, err := s.load()
+	if err != nil {
+		return err
+	}
+
+	if err := fn(&data); err != nil {
+		return err
+	}
+
+	return s.save(data)
+}
@@ -0,0 +1,50 @@
+package todo
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ErrLocked is returned when another todo-app kept the store's file locked
+// for longer than lockTimeout.
+var ErrLocked = errors.New("the store is locked by another todo-app")
+
+// lockTimeout is how long to wait for another todo-app to finish with the
+// store, as for the bolt and SQLite stores.
+const lockTimeout = 5 * time.Second
+
+// lockFile takes an advisory lock on path, by way of a path.lock file
+// beside it since the file itself is replaced on every save, retrying
+// until lockTimeout. The returned func releases the lock.
+func lockFile(path string) (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path+".lock", os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		locked, err := tryLock(f)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("locking %s: %w", path, err)
+		}
+		if locked {
+			break
+		}
+		if time.Now().After(deadline) {
+			f.Close()
+			return nil, fmt.Errorf("%w: %s", ErrLocked, path)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return func() {
+		unlock(f)
+		f.Close()
+	}, nil
+}
@@ -0,0 +1,274 @@
+package todo
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+func init() {
+	Register("memory", func(string) (Store, error) {
+		return NewMemoryStore(), nil
+	})
+}
+
+// MemoryStore is a Store that only keeps items in memory. It is mostly
+// useful in tests and for trying things out, as everything is lost when the
+// program exits.
+type MemoryStore struct {
+	mu     sync.Mutex
+	nextID int
+	items  []ParsedTodoItem
+	trash  []ParsedTodoItem
+	meta   map[string][]byte
+
+	// lists is shared by every namespace of the store.
+	lists *memoryLists
+}
+
+// memoryLists holds the namespaces of a MemoryStore.
+type memoryLists struct {
+	mu    sync.Mutex
+	root  *MemoryStore
+	named map[string]*MemoryStore
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	s := newMemoryList(&memoryLists{named: make(map[string]*MemoryStore)})
+	s.lists.root = s
+	return s
+}
+
+func newMemoryList(lists *memoryLists) *MemoryStore {
+	return &MemoryStore{nextID: 1, meta: make(map[string][]byte), lists: lists}
+}
+
+// Add implements Store.
+func (s *MemoryStore) Add(item ParsedTodoItem) (ParsedTodoItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item.ID = s.nextID
+	s.nextID++
+	s.items = append(s.items, item)
+	return item, nil
+}
+
+// List implements Store.
+func (s *MemoryStore) List() ([]ParsedTodoItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]ParsedTodoItem(nil), s.items...), nil
+}
+
+// Get implements Store.
+func (s *MemoryStore) Get(id int) (ParsedTodoItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, err := s.find(id)
+	if err != nil {
+		return ParsedTodoItem{}, err
+	}
+	return s.items[i], nil
+}
+
+// Update implements Store.
+func (s *MemoryStore) Update(item ParsedTodoItem) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, err := s.find(item.ID)
+	if err != nil {
+		return err
+	}
+	s.items[i] = item
+	return nil
+}
+
+// Delete implements Store.
+func (s *MemoryStore) Delete(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, err := s.find(id)
+	if err != nil {
+		return err
+	}
+
+	item := s.items[i]
+	item.DeletedAt = time.Now()
+	s.items = append(s.items[:i], s.items[i+1:]...)
+	s.trash = append(s.trash, item)
+	return nil
+}
+
+// Trash implements Store.
+func (s *MemoryStore) Trash() ([]ParsedTodoItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]ParsedTodoItem(nil), s.trash...), nil
+}
+
+// Restore implements Store.
+func (s *MemoryStore) Restore(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, item := range s.trash {
+		if item.ID == id {
+			item.DeletedAt = time.Time{}
+			s.trash = append(s.trash[:i], s.trash[i+1:]...)
+			s.items = append(s.items, item)
+			return nil
+		}
+	}
+	return fmt.Errorf("%w with ID %d in the trash", ErrNotFound, id)
+}
+
+// Purge implements Store.
+func (s *MemoryStore) Purge() (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := len(s.trash)
+	s.trash = nil
+	return n, nil
+}
+
+// Remove implements Remover.
+func (s *MemoryStore) Remove(ids ...int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.items = withoutIDs(s.items, ids)
+	s.trash = withoutIDs(s.trash, ids)
+	return nil
+}
+
+// Replace implements Replacer.
+func (s *MemoryStore) Replace(items, trash []ParsedTodoItem) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.items = mergeByID(nil, items)
+	s.trash = mergeByID(nil, trash)
+	for _, item := range append(items, trash...) {
+		if item.ID >= s.nextID {
+			s.nextID = item.ID + 1
+		}
+	}
+	return nil
+}
+
+// Import implements Importer.
+func (s *MemoryStore) Import(items, trash []ParsedTodoItem) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.items = mergeByID(s.items, items)
+	s.trash = mergeByID(s.trash, trash)
+	for _, item := range append(items, trash...) {
+		if item.ID >= s.nextID {
+			s.nextID = item.ID + 1
+		}
+	}
+	return nil
+}
+
+// GetMeta implements MetaStore.
+func (s *MemoryStore) GetMeta(key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.meta[key], nil
+}
+
+// PutMeta implements MetaStore.
+func (s *MemoryStore) PutMeta(key string, value []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if value == nil {
+		delete(s.meta, key)
+	} else {
+		s.meta[key] = slices.Clone(value)
+	}
+	return nil
+}
+
+// MetaKeys implements MetaStore.
+func (s *MemoryStore) MetaKeys() ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Sorted(maps.Keys(s.meta)), nil
+}
+
+// Namespace implements Namespacer.
+func (s *MemoryStore) Namespace(name string) (Store, error) {
+	s.lists.mu.Lock()
+	defer s.lists.mu.Unlock()
+
+	if name == "" {
+		return s.lists.root, nil
+	}
+	list, ok := s.lists.named[name]
+	if !ok {
+		list = newMemoryList(s.lists)
+		s.lists.named[name] = list
+	}
+	return list, nil
+}
+
+// Namespaces implements Namespacer.
+func (s *MemoryStore) Namespaces() ([]string, error) {
+	s.lists.mu.Lock()
+	defer s.lists.mu.Unlock()
+
+	var names []string
+	for name, list := range s.lists.named {
+		list.mu.Lock()
+		empty := len(list.items) == 0 && len(list.trash) == 0 && len(list.meta) == 0
+		list.mu.Unlock()
+		if !empty {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
+// DropNamespace implements Namespacer.
+func (s *MemoryStore) DropNamespace(name string) error {
+	s.lists.mu.Lock()
+	defer s.lists.mu.Unlock()
+
+	if list, ok := s.lists.named[name]; ok {
+		// Anything still holding the list sees it emptied.
+		list.mu.Lock()
+		list.items, list.trash, list.meta = nil, nil, make(map[string][]byte)
+		list.mu.Unlock()
+		delete(s.lists.named, name)
+	}
+	return nil
+}
+
+// Close implements Store. It does nothing.
+func (s *MemoryStore) Close() error {
+	return nil
+}
+
+func (s *MemoryStore) find(id int) (int, error) {
+	for i, item := range s.items {
+		if item.ID == id {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("%w with ID %d", ErrNotFound, id)
+}
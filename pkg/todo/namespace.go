@@ -0,0 +1,145 @@
+package todo
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Errors returned when working with namespaces.
+var (
+	ErrNoNamespaces  = errors.New("this store can't hold separate lists")
+	ErrBadNamespace  = errors.New("bad list name")
+	ErrNamespaceUsed = errors.New("item ID is used by another list")
+)
+
+// Namespacer is implemented by stores that can hold several separate lists
+// in the same place, such as one for each user of a server. Each list,
+// called a namespace, has its own items, trash and settings. The store
+// itself is the namespace called "".
+type Namespacer interface {
+	// Namespace returns the list called name. It shares the store's
+	// connection, so closing it does nothing; close the store instead.
+	Namespace(name string) (Store, error)
+	// Namespaces returns the names of the lists other than "" that have
+	// anything saved in them, sorted.
+	Namespaces() ([]string, error)
+	// DropNamespace deletes everything in the list called name, including
+	// its trash and settings.
+	DropNamespace(name string) error
+}
+
+// Namespace returns the list called name in store, which is store itself
+// for "". name must be valid as checked by ValidNamespace.
+func Namespace(store Store, name string) (Store, error) {
+	if name == "" {
+		return store, nil
+	}
+	if !ValidNamespace(name) {
+		return nil, fmt.Errorf("%w %q, use letters, digits, - and _, with / between parts", ErrBadNamespace, name)
+	}
+	ns, ok := store.(Namespacer)
+	if !ok {
+		return nil, ErrNoNamespaces
+	}
+	return ns.Namespace(name)
+}
+
+// ValidNamespace reports whether name can be used as a namespace: one or
+// more parts made of letters, digits, - and _, separated by slashes, as in
+// "work" or "users/alice".
+func ValidNamespace(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, part := range strings.Split(name, "/") {
+		if part == "" {
+			return false
+		}
+		for _, r := range part {
+			if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_' {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// copyNamespaces copies every namespace other than "" in src into dst, if
+// both can hold them.
+func copyNamespaces(dst, src Store) error {
+	from, ok := src.(Namespacer)
+	if !ok {
+		return nil
+	}
+	to, ok := dst.(Namespacer)
+	if !ok {
+		return nil
+	}
+
+	names, err := from.Namespaces()
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		s, err := from.Namespace(name)
+		if err != nil {
+			return err
+		}
+		d, err := to.Namespace(name)
+		if err != nil {
+			return err
+		}
+		if err := copyList(d, s); err != nil {
+			return fmt.Errorf("copying list %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// RenameNamespace moves everything in the list called old, including its
+// trash and settings, to the list called name, which has to be empty. As
+// some stores keep item IDs unique across lists, old is emptied before name
+// is filled, and filled again if that fails.
+func RenameNamespace(store Store, old, name string) error {
+	ns, ok := store.(Namespacer)
+	if !ok {
+		return ErrNoNamespaces
+	}
+	if old == "" || name == "" {
+		return fmt.Errorf("%w: the store's own list can't be renamed", ErrBadNamespace)
+	}
+	names, err := ns.Namespaces()
+	if err != nil {
+		return err
+	}
+	if slices.Contains(names, name) {
+		return fmt.Errorf("%w: there is already a list called %q", ErrBadNamespace, name)
+	}
+	from, err := Namespace(store, old)
+	if err != nil {
+		return err
+	}
+	to, err := Namespace(store, name)
+	if err != nil {
+		return err
+	}
+
+	saved := NewMemoryStore()
+	if err := copyList(saved, from); err != nil {
+		return err
+	}
+	if err := ns.DropNamespace(old); err != nil {
+		return err
+	}
+	if err := copyList(to, saved); err != nil {
+		ns.DropNamespace(name)
+		if rerr := copyList(from, saved); rerr != nil {
+			return fmt.Errorf("%w, and putting the items back in %s failed too: %v", err, old, rerr)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,237 @@
+package todo
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseNaturalDate reads a relative due date such as "tomorrow",
+// "next friday 9am", "in 3 days" or "+2w" against now. A day on its own
+// gives midnight at the start of that day, which is taken to mean the item
+// is due all day, the same as for a plain YYYY-MM-DD date. It reports false
+// if s isn't understood.
+//
+// The understood forms are:
+//
+//	today, tomorrow, yesterday
+//	monday ... sunday, mon ... sun  the first one after today
+//	next friday                     the same as friday
+//	this friday                     today if it is Friday, otherwise friday
+//	next week, next month, next year
+//	in 3 days, in a week, 2 months, 4 hours, 30 minutes
+//	+3d, +2w, +4h                   days, weeks, hours
+//	-30d, -1w                       days, weeks or hours ago
+//	2025-01-10
+//
+// Any of the day forms can be followed by a time of day, optionally after
+// "at": 9am, 9:30pm, 17:00, noon or midnight. A time on its own is today at
+// that time or, if it has already passed, tomorrow.
+func parseNaturalDate(s string, now time.Time) (time.Time, bool) {
+	words := strings.Fields(strings.ToLower(s))
+
+	hour, min, hasClock := -1, 0, false
+	if n := len(words); n >= 2 && (words[n-1] == "am" || words[n-1] == "pm") {
+		// "9 am" is the same as "9am".
+		words = append(words[:n-2], words[n-2]+words[n-1])
+	}
+	if n := len(words); n > 0 {
+		if h, m, ok := parseClock(words[n-1]); ok {
+			hour, min, hasClock = h, m, true
+			words = words[:n-1]
+			if n := len(words); n > 0 && words[n-1] == "at" {
+				words = words[:n-1]
+			}
+		}
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	atClock := func(day time.Time) time.Time {
+		if !hasClock {
+			return day
+		}
+		return time.Date(day.Year(), day.Month(), day.Day(), hour, min, 0, 0, day.Location())
+	}
+
+	if len(words) == 0 {
+		if !hasClock {
+			return time.Time{}, false
+		}
+		t := atClock(today)
+		if t.Before(now) {
+			t = atClock(today.AddDate(0, 0, 1))
+		}
+		return t, true
+	}
+
+	phrase := strings.Join(words, " ")
+	switch phrase {
+	case "today":
+		return atClock(today), true
+	case "tomorrow":
+		return atClock(today.AddDate(0, 0, 1)), true
+	case "yesterday":
+		return atClock(today.AddDate(0, 0, -1)), true
+	case "next week":
+		return atClock(today.AddDate(0, 0, 7)), true
+	case "next month":
+		return atClock(today.AddDate(0, 1, 0)), true
+	case "next year":
+		return atClock(today.AddDate(1, 0, 0)), true
+	}
+
+	if day, err := time.ParseInLocation("2006-01-02", phrase, now.Location()); err == nil {
+		return atClock(day), true
+	}
+
+	if len(words) <= 2 {
+		this := len(words) == 2 && words[0] == "this"
+		if len(words) == 1 || words[0] == "next" || this {
+			if weekday, ok := parseWeekday(words[len(words)-1]); ok {
+				days := (int(weekday) - int(today.Weekday()) + 7) % 7
+				if days == 0 && !this {
+					days = 7
+				}
+				return atClock(today.AddDate(0, 0, days)), true
+			}
+		}
+	}
+
+	if n, unit, ok := parseOffset(words); ok {
+		switch unit {
+		case "d":
+			return atClock(today.AddDate(0, 0, n)), true
+		case "w":
+			return atClock(today.AddDate(0, 0, 7*n)), true
+		case "mo":
+			return atClock(today.AddDate(0, n, 0)), true
+		case "y":
+			return atClock(today.AddDate(n, 0, 0)), true
+		case "h", "min":
+			if hasClock {
+				return time.Time{}, false
+			}
+			d := time.Duration(n) * time.Hour
+			if unit == "min" {
+				d = time.Duration(n) * time.Minute
+			}
+			return now.Add(d).Truncate(time.Minute), true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// parseClock reads a time of day such as 9am, 9:30pm, 17:00, noon or
+// midnight.
+func parseClock(word string) (hour, min int, ok bool) {
+	switch word {
+	case "noon":
+		return 12, 0, true
+	case "midnight":
+		return 0, 0, true
+	}
+
+	suffix := ""
+	if strings.HasSuffix(word, "am") || strings.HasSuffix(word, "pm") {
+		suffix = word[len(word)-2:]
+		word = word[:len(word)-2]
+	}
+
+	h, m, hasMin := strings.Cut(word, ":")
+	if !hasMin && suffix == "" {
+		// A bare number is more likely to be a count than a time.
+		return 0, 0, false
+	}
+
+	hour, err := strconv.Atoi(h)
+	if err != nil {
+		return 0, 0, false
+	}
+	if hasMin {
+		if len(m) != 2 {
+			return 0, 0, false
+		}
+		if min, err = strconv.Atoi(m); err != nil || min > 59 {
+			return 0, 0, false
+		}
+	}
+
+	switch suffix {
+	case "":
+		if hour > 23 {
+			return 0, 0, false
+		}
+	default:
+		if hour < 1 || hour > 12 {
+			return 0, 0, false
+		}
+		hour %= 12
+		if suffix == "pm" {
+			hour += 12
+		}
+	}
+	return hour, min, true
+}
+
+func parseWeekday(word string) (time.Weekday, bool) {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		name := strings.ToLower(d.String())
+		if word == name || word == name[:3] {
+			return d, true
+		}
+	}
+	return 0, false
+}
+
+// offsetUnits maps the unit words accepted in offsets like "in 3 days" to the
+// units used by parseOffset.
+var offsetUnits = map[string]string{
+	"day": "d", "days": "d", "d": "d",
+	"week": "w", "weeks": "w", "w": "w",
+	"month": "mo", "months": "mo",
+	"year": "y", "years": "y",
+	"hour": "h", "hours": "h", "h": "h",
+	"minute": "min", "minutes": "min", "min": "min", "mins": "min",
+}
+
+// parseOffset reads "in 3 days", "3 days", "in a week", "+3d" or "-3d",
+// returning the count, which is negative for times in the past, and unit.
+func parseOffset(words []string) (n int, unit string, ok bool) {
+	if len(words) == 1 && (strings.HasPrefix(words[0], "+") || strings.HasPrefix(words[0], "-")) {
+		word := words[0][1:]
+		i := strings.IndexFunc(word, func(r rune) bool { return r < '0' || r > '9' })
+		if i <= 0 {
+			return 0, "", false
+		}
+		switch word[i:] {
+		case "d", "w", "h":
+			n, err := strconv.Atoi(word[:i])
+			if words[0][0] == '-' {
+				n = -n
+			}
+			return n, word[i:], err == nil
+		}
+		return 0, "", false
+	}
+
+	if len(words) > 0 && words[0] == "in" {
+		words = words[1:]
+	}
+	if len(words) != 2 {
+		return 0, "", false
+	}
+
+	switch words[0] {
+	case "a", "an", "one":
+		n = 1
+	default:
+		var err error
+		if n, err = strconv.Atoi(words[0]); err != nil || n < 0 {
+			return 0, "", false
+		}
+	}
+
+	unit, ok = offsetUnits[words[1]]
+	return n, unit, ok
+}
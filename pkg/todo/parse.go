@@ -0,0 +1,162 @@
+package todo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Errors returned when parsing input. They are wrapped with more detail so
+// use errors.Is to check for them.
+var (
+	ErrInvalidJSON = errors.New("invalid JSON item")
+	ErrBadDueDate  = errors.New("badly formed due date")
+	ErrEmptyTodo   = errors.New("a todo item needs some text")
+)
+
+// Location is the time zone that due dates given without a UTC offset are
+// read in. It defaults to the local time zone.
+var Location = time.Local
+
+// dueDateFormats are the layouts accepted by ParseDueDate, tried in order.
+// All but the last are read in Location.
+var dueDateFormats = []string{
+	"2006-01-02",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04:05",
+	time.RFC3339,
+}
+
+// Now returns the current time. Relative due dates such as "tomorrow" are
+// resolved against it.
+var Now = time.Now
+
+// ParseDueDate parses a due date given as YYYY-MM-DD, optionally with a time
+// as in YYYY-MM-DDTHH:MM, or as a full RFC 3339 timestamp. A date on its own
+// is the start of that day and means the item is due at some point during
+// it. If none of those match, relative dates like "tomorrow" or
+// "next monday 9am" are tried. An empty string means there is no due date
+// and gives the zero time.
+func ParseDueDate(dueDate string) (time.Time, error) {
+	if dueDate == "" {
+		return time.Time{}, nil
+	}
+
+	for _, format := range dueDateFormats {
+		parsedDueDate, parseErr := time.ParseInLocation(format, dueDate, Location)
+		if parseErr == nil {
+			return parsedDueDate, nil
+		}
+	}
+
+	if parsedDueDate, ok := parseNaturalDate(dueDate, Now().In(Location)); ok {
+		return parsedDueDate, nil
+	}
+
+	return time.Time{}, fmt.Errorf("%w %q, expected YYYY-MM-DD, YYYY-MM-DDTHH:MM or something like \"tomorrow\"", ErrBadDueDate, dueDate)
+}
+
+// ParseInput parses JSON input into something more usable.
+// This includes checking for empty input and parsing the
+// `due` field.
+func ParseInput(input *string) (ParsedTodoItem, error) {
+	var todoItem TodoItem
+
+	err := json.Unmarshal([]byte(*input), &todoItem)
+	if err != nil {
+		return ParsedTodoItem{}, fmt.Errorf("%w: %v", ErrInvalidJSON, err)
+	}
+
+	return ParseItem(todoItem)
+}
+
+// ParseArgs builds an item from its task text and due date given as
+// separate command line arguments, e.g. `add "Buy milk" -due 2025-01-10`.
+// Several words are joined with spaces so the task doesn't need quoting.
+func ParseArgs(words []string, due string) (ParsedTodoItem, error) {
+	return ParseItem(TodoItem{Todo: strings.Join(words, " "), Due: due})
+}
+
+// SplitDue splits a task written as one line ending with "due <date>",
+// such as "Book the venue due friday 5pm", into the task and the date, if
+// the date can be read. Otherwise the whole of text is the task.
+func SplitDue(text string) (task, due string) {
+	i := strings.LastIndex(strings.ToLower(text), " due ")
+	if i < 0 {
+		return text, ""
+	}
+	when := strings.TrimSpace(text[i+len(" due "):])
+	if _, err := ParseDueDate(when); err != nil {
+		return text, ""
+	}
+	return strings.TrimSpace(text[:i]), when
+}
+
+// ParseItem checks todoItem has a task and parses its due date and
+// priority, returning a new item created now. Any `#tag`, `@context` or
+// `+project` words in the task are moved into the matching fields. It is
+// shared by all of the input parsers.
+func ParseItem(todoItem TodoItem) (ParsedTodoItem, error) {
+	todo, inlineTags := ExtractTags(todoItem.Todo)
+	todo, inlineContexts := ExtractContexts(todo)
+	todo, inlineProject := ExtractProject(todo)
+	if todo == "" {
+		return ParsedTodoItem{}, ErrEmptyTodo
+	}
+
+	parsedDueDate, err := ParseDueDate(todoItem.Due)
+	if err != nil {
+		return ParsedTodoItem{}, err
+	}
+
+	project := strings.TrimSpace(todoItem.Project)
+	if project == "" {
+		project = inlineProject
+	}
+
+	priority, err := ParsePriority(todoItem.Priority)
+	if err != nil {
+		return ParsedTodoItem{}, err
+	}
+
+	var repeat string
+	if todoItem.Repeat != "" {
+		r, err := ParseRepeat(todoItem.Repeat, parsedDueDate)
+		if err != nil {
+			return ParsedTodoItem{}, err
+		}
+		repeat = r.String()
+	}
+
+	var remind string
+	if todoItem.Remind != "" {
+		offsets, err := ParseOffsets(todoItem.Remind)
+		if err != nil {
+			return ParsedTodoItem{}, err
+		}
+		remind = FormatOffsets(offsets)
+	}
+
+	estimate, err := ParseEstimate(todoItem.Estimate)
+	if err != nil {
+		return ParsedTodoItem{}, err
+	}
+
+	return ParsedTodoItem{
+		Todo:      todo,
+		Due:       parsedDueDate,
+		Priority:  priority,
+		Tags:      AddTags(AddTags(nil, todoItem.Tags...), inlineTags...),
+		Project:   project,
+		Contexts:  AddContexts(AddContexts(nil, todoItem.Contexts...), inlineContexts...),
+		Repeat:    repeat,
+		Remind:    remind,
+		Estimate:  estimate,
+		Parent:    todoItem.Parent,
+		Notes:     strings.TrimRight(todoItem.Notes, "\n\t "),
+		CreatedAt: Now(),
+	}, nil
+}
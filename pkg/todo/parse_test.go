@@ -0,0 +1,91 @@
+package todo
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+// at makes Now return now for the rest of the test.
+func at(t *testing.T, now time.Time) {
+	t.Helper()
+	saved := Now
+	Now = func() time.Time { return now }
+	t.Cleanup(func() { Now = saved })
+}
+
+func TestParseDueDate(t *testing.T) {
+	inLocation(t, "Europe/London")
+	london := Location
+	// A Wednesday.
+	at(t, time.Date(2025, 1, 15, 10, 0, 0, 0, london))
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"", time.Time{}},
+		{"2025-01-10", time.Date(2025, 1, 10, 0, 0, 0, 0, london)},
+		{"2025-07-10T14:30", time.Date(2025, 7, 10, 14, 30, 0, 0, london)},
+		{"2025-07-10 14:30", time.Date(2025, 7, 10, 14, 30, 0, 0, london)},
+		{"2025-07-10T14:30:15", time.Date(2025, 7, 10, 14, 30, 15, 0, london)},
+		{"2025-07-10T14:30:00Z", time.Date(2025, 7, 10, 14, 30, 0, 0, time.UTC)},
+		{"2025-07-10T14:30:00-04:00", time.Date(2025, 7, 10, 18, 30, 0, 0, time.UTC)},
+		{"tomorrow", time.Date(2025, 1, 16, 0, 0, 0, 0, london)},
+	}
+	for _, tt := range tests {
+		got, err := ParseDueDate(tt.in)
+		if err != nil {
+			t.Errorf("ParseDueDate(%q): %v", tt.in, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("ParseDueDate(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+
+	for _, in := range []string{"2025-13-01", "2025-02-30", "10/01/2025", "someday", "2025-01-10T25:00"} {
+		if _, err := ParseDueDate(in); !errors.Is(err, ErrBadDueDate) {
+			t.Errorf("ParseDueDate(%q) error = %v, want ErrBadDueDate", in, err)
+		}
+	}
+}
+
+func TestParseInput(t *testing.T) {
+	inLocation(t, "UTC")
+	input := `{"todo": "Buy milk #shopping +home", "due": "2025-01-10", "priority": "high", "tags": ["errand"]}`
+	item, err := ParseInput(&input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.Todo != "Buy milk" {
+		t.Errorf("Todo = %q, want %q", item.Todo, "Buy milk")
+	}
+	if want := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC); !item.Due.Equal(want) {
+		t.Errorf("Due = %s, want %s", item.Due, want)
+	}
+	if item.Priority != PriorityHigh || item.Project != "home" {
+		t.Errorf("Priority, Project = %v, %q, want high, home", item.Priority, item.Project)
+	}
+	if !slices.Equal(item.Tags, []string{"errand", "shopping"}) {
+		t.Errorf("Tags = %v, want [errand shopping]", item.Tags)
+	}
+
+	tests := []struct {
+		input string
+		want  error
+	}{
+		{`{"todo": "Buy milk"`, ErrInvalidJSON},
+		{`not json`, ErrInvalidJSON},
+		{`{"todo": 3}`, ErrInvalidJSON},
+		{`{"todo": "Buy milk", "due": "next blue moon"}`, ErrBadDueDate},
+		{`{"todo": "Buy milk", "due": "2025-02-30"}`, ErrBadDueDate},
+		{`{"todo": "   "}`, ErrEmptyTodo},
+		{`{"due": "2025-01-10"}`, ErrEmptyTodo},
+	}
+	for _, tt := range tests {
+		if _, err := ParseInput(&tt.input); !errors.Is(err, tt.want) {
+			t.Errorf("ParseInput(%s) error = %v, want %v", tt.input, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package todo
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrBadPriority is returned when a priority can't be parsed.
+var ErrBadPriority = errors.New("unknown priority")
+
+// Priority is how important an item is. The zero value means no priority
+// has been set, which sorts below PriorityLow.
+type Priority int
+
+// The priority levels, from least to most important.
+const (
+	PriorityNone Priority = iota
+	PriorityLow
+	PriorityMedium
+	PriorityHigh
+)
+
+var priorityNames = map[Priority]string{
+	PriorityNone:   "",
+	PriorityLow:    "low",
+	PriorityMedium: "medium",
+	PriorityHigh:   "high",
+}
+
+// String returns the name of the priority, or "" for PriorityNone.
+func (p Priority) String() string {
+	if name, ok := priorityNames[p]; ok {
+		return name
+	}
+	return fmt.Sprintf("Priority(%d)", int(p))
+}
+
+// ParsePriority parses a priority name. As well as low, medium and high it
+// accepts l, m, med, h and the P1–P4 style where P1 is high and P4 is no
+// priority. An empty string gives PriorityNone.
+func ParsePriority(s string) (Priority, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "none", "p4":
+		return PriorityNone, nil
+	case "low", "l", "p3":
+		return PriorityLow, nil
+	case "medium", "med", "m", "p2":
+		return PriorityMedium, nil
+	case "high", "h", "p1":
+		return PriorityHigh, nil
+	}
+	return PriorityNone, fmt.Errorf("%w %q, expected low, medium or high", ErrBadPriority, s)
+}
+
+// MarshalText implements encoding.TextMarshaler so priorities are saved by
+// name.
+func (p Priority) MarshalText() ([]byte, error) {
+	if _, ok := priorityNames[p]; !ok {
+		return nil, fmt.Errorf("%w %d", ErrBadPriority, int(p))
+	}
+	return []byte(p.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (p *Priority) UnmarshalText(text []byte) error {
+	parsed, err := ParsePriority(string(text))
+	if err != nil {
+		return err
+	}
+	*p = parsed
+	return nil
+}
@@ -0,0 +1,1060 @@
+package todo
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrBadRepeat is returned when a repeat rule can't be parsed.
+var ErrBadRepeat = errors.New("badly formed repeat rule")
+
+// Frequency is how often a Recurrence repeats, before its interval is
+// applied.
+type Frequency string
+
+// The supported frequencies, named as in iCalendar RRULEs.
+const (
+	Secondly Frequency = "SECONDLY"
+	Minutely Frequency = "MINUTELY"
+	Hourly   Frequency = "HOURLY"
+	Daily    Frequency = "DAILY"
+	Weekly   Frequency = "WEEKLY"
+	Monthly  Frequency = "MONTHLY"
+	Yearly   Frequency = "YEARLY"
+)
+
+// frequencies are the frequencies from the most often to the least.
+var frequencies = []Frequency{Secondly, Minutely, Hourly, Daily, Weekly, Monthly, Yearly}
+
+// finer reports whether f repeats more often than g.
+func (f Frequency) finer(g Frequency) bool {
+	return slices.Index(frequencies, f) < slices.Index(frequencies, g)
+}
+
+// Recurrence is a rule for when a repeating item is next due. It follows
+// iCalendar RRULEs (RFC 5545), with FREQ, INTERVAL, COUNT, UNTIL, WKST and
+// all of the BY parts, such as BYDAY=-1FR for the last Friday of the month.
+// A part of todo-app's own, X-SKIP=WEEKENDS,HOLIDAYS, moves occurrences
+// falling on a weekend or one of Holidays on to the next day that isn't.
+type Recurrence struct {
+	Freq     Frequency
+	Interval int
+	// Count is how many times the rule falls in all, counting the due date
+	// it repeats from, or 0 for no limit. Each new occurrence carries the
+	// count left, so COUNT=3 gives the item and two more.
+	Count int
+	// Until is the last time the rule can fall on, or zero for no limit.
+	// At midnight UTC it is a date, and covers the whole day.
+	Until time.Time
+	// WeekStart is the day weeks start on, for weekly rules with an
+	// interval and for BYWEEKNO. ParseRepeat makes it Monday unless the
+	// rule says otherwise.
+	WeekStart time.Weekday
+
+	// The BY parts pick out the days and times in each period the rule
+	// falls on, as in RFC 5545. Without any, a weekly rule falls on the
+	// weekday the item was due, a monthly one on the day of the month and a
+	// yearly one on the date. Negative days of the month and year, and weeks
+	// of the year, count back from the end.
+	Weekdays  []Weekday
+	MonthDays []int
+	YearDays  []int
+	WeekNos   []int
+	Months    []time.Month
+	Hours     []int
+	Minutes   []int
+	Seconds   []int
+	// SetPos picks out occurrences by their place among those in a period,
+	// such as -1 for the last.
+	SetPos []int
+
+	// SkipWeekends and SkipHolidays move an occurrence falling on a
+	// Saturday or Sunday, or on one of Holidays, to the next day that isn't.
+	SkipWeekends bool
+	SkipHolidays bool
+}
+
+// Weekday is a day in a BYDAY rule part: every such day, or with N set the
+// Nth of them in the month or year, counting back from the end if N is
+// negative. 2MO is the second Monday and -1FR the last Friday.
+type Weekday struct {
+	N   int
+	Day time.Weekday
+}
+
+var weekdayCodes = []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// workdays are Monday to Friday.
+var workdays = []Weekday{{Day: time.Monday}, {Day: time.Tuesday}, {Day: time.Wednesday}, {Day: time.Thursday}, {Day: time.Friday}}
+
+// ParseRepeat parses a repeat rule. It accepts RRULE syntax such as
+// `FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH` or `FREQ=MONTHLY;BYDAY=-1FR` as well
+// as phrases: daily, weekly, monthly, yearly, hourly, every day, every 3
+// weeks, every monday, every mon and fri, every weekday, every weekend,
+// every business day, every last friday, every 2nd tuesday of the month,
+// every last day of the month, every first weekday, every 4th thursday of
+// november. A phrase can end in "skipping weekends", "skipping holidays" or
+// "skipping weekends and holidays", as in "monthly skipping weekends".
+// Every business day is every weekday skipping weekends and holidays, so
+// that a holiday on a Friday moves it on to Monday.
+//
+// Monthly rules without a day are pinned to the day of due, so an item due
+// on the 31st stays on the 31st rather than drifting to the 28th after
+// February. Rules that skip days are pinned to the day of due in the same
+// way, as skipping moves items off it.
+//
+// RRULEs follow RFC 5545 in skipping the months without the day they fall
+// on, so FREQ=MONTHLY;BYMONTHDAY=31 skips April. Phrases fall back to the
+// end of shorter months instead, and say so in the rule they give: monthly
+// from the 31st is FREQ=MONTHLY;BYMONTHDAY=-1, from the 30th
+// FREQ=MONTHLY;BYMONTHDAY=28,29,30;BYSETPOS=-1, and yearly from 29 February
+// FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=-1.
+func ParseRepeat(s string, due time.Time) (Recurrence, error) {
+	text := strings.TrimSpace(s)
+
+	var r Recurrence
+	phrase := !strings.Contains(strings.ToUpper(text), "FREQ=")
+	if !phrase {
+		var err error
+		if r, err = parseRRule(text); err != nil {
+			return Recurrence{}, fmt.Errorf("%w %q: %v", ErrBadRepeat, s, err)
+		}
+	} else {
+		var ok bool
+		if r, ok = parseRepeatPhrase(strings.ToLower(text)); !ok {
+			return Recurrence{}, fmt.Errorf("%w %q, expected e.g. \"weekly\", \"every 2 days\", \"every last friday\" or FREQ=MONTHLY;BYDAY=-1FR", ErrBadRepeat, s)
+		}
+		r.WeekStart = time.Monday
+	}
+
+	if r.Interval == 0 {
+		r.Interval = 1
+	}
+	if !due.IsZero() && (r.Freq == Monthly || r.SkipWeekends || r.SkipHolidays) {
+		r = r.dayDefaults(local(due))
+	}
+	if phrase && !due.IsZero() {
+		if clamped, ok := r.dayDefaults(local(due)).clampMonthEnd(); ok {
+			r = clamped
+		}
+	}
+	return r, nil
+}
+
+// clampMonthEnd returns r with the one day of the month it falls on, if
+// that is past the end of some of the months it falls in, turned into the
+// last of the days up to it in each month. It reports whether there was
+// such a day.
+func (r Recurrence) clampMonthEnd() (Recurrence, bool) {
+	if len(r.MonthDays) != 1 || len(r.SetPos) > 0 || r.Freq != Monthly && r.Freq != Yearly {
+		return r, false
+	}
+	months := r.Months
+	if len(months) == 0 {
+		months = []time.Month{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}
+	}
+	// 2001 isn't a leap year, and 2000 is.
+	shortest, longest := 31, 0
+	for _, m := range months {
+		shortest = min(shortest, daysIn(2001, m))
+		longest = max(longest, daysIn(2000, m))
+	}
+	day := r.MonthDays[0]
+	switch {
+	case day <= shortest:
+		return r, false
+	case day == longest:
+		r.MonthDays = []int{-1}
+	default:
+		r.MonthDays = nil
+		for d := shortest; d <= day; d++ {
+			r.MonthDays = append(r.MonthDays, d)
+		}
+		r.SetPos = []int{-1}
+	}
+	return r, true
+}
+
+func parseRRule(text string) (Recurrence, error) {
+	r := Recurrence{WeekStart: time.Monday}
+	text = strings.TrimPrefix(strings.ToUpper(text), "RRULE:")
+
+	seen := map[string]bool{}
+	for _, part := range strings.Split(text, ";") {
+		key, value, found := strings.Cut(part, "=")
+		if !found || value == "" {
+			return r, fmt.Errorf("%q isn't of the form KEY=VALUE", part)
+		}
+		if seen[key] {
+			return r, fmt.Errorf("%s is given twice", key)
+		}
+		seen[key] = true
+
+		var err error
+		switch key {
+		case "FREQ":
+			if !slices.Contains(frequencies, Frequency(value)) {
+				return r, fmt.Errorf("unknown FREQ %s", value)
+			}
+			r.Freq = Frequency(value)
+		case "INTERVAL":
+			if r.Interval, err = strconv.Atoi(value); err != nil || r.Interval < 1 {
+				return r, errors.New("INTERVAL has to be a whole number from 1")
+			}
+		case "COUNT":
+			if r.Count, err = strconv.Atoi(value); err != nil || r.Count < 1 {
+				return r, errors.New("COUNT has to be a whole number from 1")
+			}
+		case "UNTIL":
+			r.Until, err = parseUntil(value)
+		case "WKST":
+			var ok bool
+			if r.WeekStart, ok = weekdayFromCode(value); !ok {
+				return r, fmt.Errorf("unknown WKST %s, expected one of %s", value, strings.Join(weekdayCodes, ", "))
+			}
+		case "BYDAY":
+			r.Weekdays, err = parseByDay(value)
+		case "BYMONTHDAY":
+			r.MonthDays, err = parseNumbers(key, value, 1, 31)
+		case "BYYEARDAY":
+			r.YearDays, err = parseNumbers(key, value, 1, 366)
+		case "BYWEEKNO":
+			r.WeekNos, err = parseNumbers(key, value, 1, 53)
+		case "BYSETPOS":
+			r.SetPos, err = parseNumbers(key, value, 1, 366)
+		case "BYMONTH":
+			var months []int
+			months, err = parseNumbers(key, value, 1, 12)
+			for _, m := range months {
+				if m < 0 {
+					return r, errors.New("BYMONTH takes months from 1 to 12")
+				}
+				r.Months = append(r.Months, time.Month(m))
+			}
+		case "BYHOUR":
+			r.Hours, err = parseNumbers(key, value, 0, 23)
+		case "BYMINUTE":
+			r.Minutes, err = parseNumbers(key, value, 0, 59)
+		case "BYSECOND":
+			r.Seconds, err = parseNumbers(key, value, 0, 59)
+		case "X-SKIP":
+			for _, v := range strings.Split(value, ",") {
+				switch v {
+				case "WEEKENDS":
+					r.SkipWeekends = true
+				case "HOLIDAYS":
+					r.SkipHolidays = true
+				default:
+					return r, fmt.Errorf("X-SKIP takes WEEKENDS and HOLIDAYS, not %s", v)
+				}
+			}
+		default:
+			return r, fmt.Errorf("unknown part %s", key)
+		}
+		if err != nil {
+			return r, err
+		}
+	}
+
+	counted := slices.ContainsFunc(r.Weekdays, func(w Weekday) bool { return w.N != 0 })
+	switch {
+	case r.Freq == "":
+		return r, errors.New("FREQ is missing")
+	case r.Count > 0 && !r.Until.IsZero():
+		return r, errors.New("COUNT and UNTIL can't both be given")
+	case counted && r.Freq != Monthly && r.Freq != Yearly:
+		return r, errors.New("BYDAY can only count days, as in -1FR, in monthly and yearly rules")
+	case counted && len(r.WeekNos) > 0:
+		return r, errors.New("BYDAY can't count days, as in -1FR, with BYWEEKNO")
+	case len(r.MonthDays) > 0 && r.Freq == Weekly:
+		return r, errors.New("BYMONTHDAY can't be used in weekly rules")
+	case len(r.YearDays) > 0 && (r.Freq == Daily || r.Freq == Weekly || r.Freq == Monthly):
+		return r, errors.New("BYYEARDAY can't be used in daily, weekly or monthly rules")
+	case len(r.WeekNos) > 0 && r.Freq != Yearly:
+		return r, errors.New("BYWEEKNO can only be used in yearly rules")
+	case len(r.SetPos) > 0 && len(r.Weekdays)+len(r.MonthDays)+len(r.YearDays)+len(r.WeekNos)+len(r.Months)+len(r.Hours)+len(r.Minutes)+len(r.Seconds) == 0:
+		return r, errors.New("BYSETPOS needs another BY part to pick from")
+	}
+	return r, nil
+}
+
+// parseUntil reads an UNTIL value: a date, a time in UTC or a time in
+// Location.
+func parseUntil(value string) (time.Time, error) {
+	if t, err := time.Parse("20060102", value); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse("20060102T150405Z", value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("20060102T150405", value, Location); err == nil {
+		return t.UTC(), nil
+	}
+	return time.Time{}, fmt.Errorf("UNTIL %s isn't a date like 20251231 or time like 20251231T170000Z", value)
+}
+
+// parseByDay reads a BYDAY value such as MO,WE or -1FR.
+func parseByDay(value string) ([]Weekday, error) {
+	var days []Weekday
+	for _, code := range strings.Split(value, ",") {
+		split := max(len(code)-2, 0)
+		d, ok := weekdayFromCode(code[split:])
+		if !ok {
+			return nil, fmt.Errorf("unknown day %q in BYDAY", code)
+		}
+		w := Weekday{Day: d}
+		if split > 0 {
+			n, err := strconv.Atoi(code[:split])
+			if err != nil || n == 0 || n < -53 || n > 53 {
+				return nil, fmt.Errorf("BYDAY counts days from 1 to 53, or -53 to -1 from the end, not %q", code)
+			}
+			w.N = n
+		}
+		days = append(days, w)
+	}
+	return days, nil
+}
+
+// parseNumbers reads a list of numbers from lo to hi for the rule part
+// key. For lists starting from 1 the same numbers counting back from the
+// end, from -hi to -1, are allowed too.
+func parseNumbers(key, value string, lo, hi int) ([]int, error) {
+	var ns []int
+	for _, s := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(s)
+		ok := err == nil && n >= lo && n <= hi || lo == 1 && err == nil && n <= -1 && n >= -hi
+		if !ok {
+			if lo == 1 {
+				return nil, fmt.Errorf("%s takes numbers from 1 to %d, or -%d to -1 from the end, not %q", key, hi, hi, s)
+			}
+			return nil, fmt.Errorf("%s takes numbers from %d to %d, not %q", key, lo, hi, s)
+		}
+		ns = append(ns, n)
+	}
+	return ns, nil
+}
+
+// skipEndings are the endings of a phrase that make the rule skip days.
+var skipEndings = []struct {
+	ending             string
+	weekends, holidays bool
+}{
+	{" skipping weekends and holidays", true, true},
+	{" skipping holidays and weekends", true, true},
+	{" skipping weekends", true, false},
+	{" skipping holidays", false, true},
+}
+
+func parseRepeatPhrase(text string) (Recurrence, bool) {
+	for _, e := range skipEndings {
+		if rest, ok := strings.CutSuffix(text, e.ending); ok {
+			r, ok := parseRepeatPhrase(strings.TrimSuffix(strings.TrimSpace(rest), ","))
+			r.SkipWeekends = r.SkipWeekends || e.weekends
+			r.SkipHolidays = r.SkipHolidays || e.holidays
+			return r, ok
+		}
+	}
+
+	switch text {
+	case "hourly":
+		return Recurrence{Freq: Hourly}, true
+	case "daily":
+		return Recurrence{Freq: Daily}, true
+	case "weekly":
+		return Recurrence{Freq: Weekly}, true
+	case "monthly":
+		return Recurrence{Freq: Monthly}, true
+	case "yearly", "annually":
+		return Recurrence{Freq: Yearly}, true
+	case "every weekday", "weekdays":
+		return Recurrence{Freq: Weekly, Weekdays: workdays}, true
+	case "every business day", "business days", "every working day", "working days", "every workday", "workdays":
+		return Recurrence{Freq: Weekly, Weekdays: workdays, SkipWeekends: true, SkipHolidays: true}, true
+	case "every weekend", "weekends":
+		return Recurrence{Freq: Weekly, Weekdays: []Weekday{{Day: time.Saturday}, {Day: time.Sunday}}}, true
+	}
+
+	rest, ok := strings.CutPrefix(text, "every ")
+	if !ok {
+		return parseOrdinalPhrase(strings.TrimPrefix(text, "the "))
+	}
+	if r, ok := parseOrdinalPhrase(rest); ok {
+		return r, true
+	}
+	words := strings.Fields(rest)
+
+	n := 1
+	if len(words) == 2 {
+		var err error
+		if n, err = strconv.Atoi(words[0]); err != nil || n < 1 {
+			if words[0] != "other" {
+				return Recurrence{}, false
+			}
+			n = 2
+		}
+		words = words[1:]
+	}
+	if len(words) == 1 {
+		unit := strings.TrimSuffix(words[0], "s")
+		freq, found := map[string]Frequency{"minute": Minutely, "hour": Hourly, "day": Daily, "week": Weekly, "month": Monthly, "year": Yearly}[unit]
+		if found {
+			return Recurrence{Freq: freq, Interval: n}, true
+		}
+		if n != 1 {
+			return Recurrence{}, false
+		}
+	}
+
+	// every monday, every mon and thu, every tue, fri
+	var days []Weekday
+	for _, word := range strings.FieldsFunc(rest, func(r rune) bool { return r == ',' || r == ' ' }) {
+		if word == "and" {
+			continue
+		}
+		d, ok := parseWeekday(strings.TrimSuffix(word, "s"))
+		if !ok {
+			return Recurrence{}, false
+		}
+		days = append(days, Weekday{Day: d})
+	}
+	if len(days) == 0 {
+		return Recurrence{}, false
+	}
+	return Recurrence{Freq: Weekly, Weekdays: days}, true
+}
+
+// parseOrdinalPhrase reads a phrase counting days in the month, such as
+// "last friday", "2nd tue of the month", "second last day of the month",
+// "first weekday" or, for one month each year, "4th thursday of november".
+func parseOrdinalPhrase(text string) (Recurrence, bool) {
+	r := Recurrence{Freq: Monthly}
+	text, of, _ := strings.Cut(text, " of ")
+	switch of {
+	case "", "the month", "every month", "each month":
+	case "the year", "every year", "each year":
+		r.Freq = Yearly
+	default:
+		m, ok := parseMonth(of)
+		if !ok {
+			return Recurrence{}, false
+		}
+		r.Freq, r.Months = Yearly, []time.Month{m}
+	}
+
+	words := strings.Fields(text)
+	if len(words) < 2 {
+		return Recurrence{}, false
+	}
+	n, ok := parseOrdinal(words[0])
+	if !ok {
+		return Recurrence{}, false
+	}
+	words = words[1:]
+	// second last, second to last
+	if n > 0 && len(words) > 1 && (words[0] == "last" || words[0] == "to" && words[1] == "last") {
+		n = -n
+		words = words[slices.Index(words, "last")+1:]
+	}
+	if len(words) != 1 {
+		return Recurrence{}, false
+	}
+
+	switch unit := words[0]; {
+	case unit == "day":
+		if r.Freq == Yearly && len(r.Months) == 0 {
+			r.YearDays = []int{n}
+		} else {
+			r.MonthDays = []int{n}
+		}
+	case unit == "weekday":
+		r.Weekdays, r.SetPos = workdays, []int{n}
+	default:
+		d, ok := parseWeekday(unit)
+		if !ok || n > 5 || n < -5 {
+			return Recurrence{}, false
+		}
+		r.Weekdays = []Weekday{{N: n, Day: d}}
+	}
+	return r, true
+}
+
+// parseOrdinal reads first to fifth, last, or a number like 2nd or 31st.
+func parseOrdinal(word string) (int, bool) {
+	if n, ok := map[string]int{"first": 1, "second": 2, "third": 3, "fourth": 4, "fifth": 5, "last": -1}[word]; ok {
+		return n, true
+	}
+	for _, suffix := range []string{"st", "nd", "rd", "th"} {
+		if digits, ok := strings.CutSuffix(word, suffix); ok {
+			n, err := strconv.Atoi(digits)
+			return n, err == nil && n >= 1 && n <= 31
+		}
+	}
+	return 0, false
+}
+
+// parseMonth reads the name of a month, in full or shortened to three
+// letters.
+func parseMonth(word string) (time.Month, bool) {
+	for m := time.January; m <= time.December; m++ {
+		name := strings.ToLower(m.String())
+		if word == name || word == name[:3] {
+			return m, true
+		}
+	}
+	return 0, false
+}
+
+func weekdayFromCode(code string) (time.Weekday, bool) {
+	for i, c := range weekdayCodes {
+		if c == code {
+			return time.Weekday(i), true
+		}
+	}
+	return 0, false
+}
+
+// String returns the rule in RRULE syntax, which is how it is saved.
+func (r Recurrence) String() string {
+	var skip []string
+	if r.SkipWeekends {
+		skip = append(skip, "WEEKENDS")
+	}
+	if r.SkipHolidays {
+		skip = append(skip, "HOLIDAYS")
+	}
+	if len(skip) == 0 {
+		return r.RRule()
+	}
+	return r.RRule() + ";X-SKIP=" + strings.Join(skip, ",")
+}
+
+// RRule returns the rule as an iCalendar RRULE, leaving out the days it
+// skips, which other apps don't know of.
+func (r Recurrence) RRule() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if !r.Until.IsZero() {
+		layout := "20060102T150405Z"
+		if r.untilDate() {
+			layout = "20060102"
+		}
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format(layout))
+	}
+	numbers := func(key string, ns []int) {
+		if len(ns) == 0 {
+			return
+		}
+		s := make([]string, len(ns))
+		for i, n := range ns {
+			s[i] = strconv.Itoa(n)
+		}
+		parts = append(parts, key+"="+strings.Join(s, ","))
+	}
+	var months []int
+	for _, m := range r.Months {
+		months = append(months, int(m))
+	}
+	numbers("BYMONTH", months)
+	numbers("BYWEEKNO", r.WeekNos)
+	numbers("BYYEARDAY", r.YearDays)
+	numbers("BYMONTHDAY", r.MonthDays)
+	if len(r.Weekdays) > 0 {
+		var codes []string
+		for _, w := range r.Weekdays {
+			code := weekdayCodes[w.Day]
+			if w.N != 0 {
+				code = strconv.Itoa(w.N) + code
+			}
+			codes = append(codes, code)
+		}
+		parts = append(parts, "BYDAY="+strings.Join(codes, ","))
+	}
+	numbers("BYHOUR", r.Hours)
+	numbers("BYMINUTE", r.Minutes)
+	numbers("BYSECOND", r.Seconds)
+	numbers("BYSETPOS", r.SetPos)
+	if r.WeekStart != time.Monday {
+		parts = append(parts, "WKST="+weekdayCodes[r.WeekStart])
+	}
+	return strings.Join(parts, ";")
+}
+
+// Describe returns the rule in words, e.g. "every 2 weeks" or "every month
+// on the last Fri".
+func (r Recurrence) Describe() string {
+	unit := map[Frequency]string{Secondly: "second", Minutely: "minute", Hourly: "hour", Daily: "day", Weekly: "week", Monthly: "month", Yearly: "year"}[r.Freq]
+
+	desc := "every " + unit
+	if r.Interval > 1 {
+		desc = fmt.Sprintf("every %d %ss", r.Interval, unit)
+	}
+	list := func(ns []int, word func(int) string) string {
+		words := make([]string, len(ns))
+		for i, n := range ns {
+			words[i] = word(n)
+		}
+		if len(words) == 2 {
+			return words[0] + " and " + words[1]
+		}
+		return strings.Join(words, ", ")
+	}
+	if len(r.Months) > 0 {
+		var names []string
+		for _, m := range r.Months {
+			names = append(names, m.String()[:3])
+		}
+		desc += " in " + strings.Join(names, ", ")
+	}
+	if len(r.WeekNos) > 0 {
+		desc += " in " + list(r.WeekNos, func(n int) string {
+			if n < 0 {
+				return fmt.Sprintf("week %d from the end", -n)
+			}
+			return fmt.Sprintf("week %d", n)
+		})
+	}
+	countBack := func(n int) string {
+		switch {
+		case n == -1:
+			return "the last day"
+		case n < 0:
+			return fmt.Sprintf("day %d from the end", -n)
+		}
+		return fmt.Sprintf("day %d", n)
+	}
+	if len(r.YearDays) > 0 {
+		desc += " on " + list(r.YearDays, countBack) + " of the year"
+	}
+	if len(r.MonthDays) > 0 {
+		desc += " on " + list(r.MonthDays, countBack)
+	}
+	if len(r.Weekdays) > 0 {
+		if slices.Equal(r.Weekdays, workdays) {
+			desc += " on weekdays"
+		} else {
+			var names []string
+			for _, w := range r.Weekdays {
+				name := w.Day.String()[:3]
+				if w.N != 0 {
+					name = "the " + ordinal(w.N) + " " + name
+				}
+				names = append(names, name)
+			}
+			desc += " on " + strings.Join(names, ", ")
+		}
+	}
+	clock := map[string][]int{"hour": r.Hours, "minute": r.Minutes, "second": r.Seconds}
+	for _, part := range []string{"hour", "minute", "second"} {
+		if len(clock[part]) > 0 {
+			desc += " at " + part + " " + list(clock[part], strconv.Itoa)
+		}
+	}
+	if len(r.SetPos) > 0 {
+		desc += ", only the " + list(r.SetPos, ordinal)
+	}
+
+	switch {
+	case r.Count == 1:
+		desc += ", for the last time"
+	case r.Count == 2:
+		desc += ", once more"
+	case r.Count > 2:
+		desc += fmt.Sprintf(", %d more times", r.Count-1)
+	}
+	if r.untilDate() {
+		desc += ", until " + r.Until.Format("2006-01-02")
+	} else if !r.Until.IsZero() {
+		desc += ", until " + r.Until.In(Location).Format("2006-01-02 15:04")
+	}
+	switch {
+	case r.SkipWeekends && r.SkipHolidays:
+		desc += ", skipping weekends and holidays"
+	case r.SkipWeekends:
+		desc += ", skipping weekends"
+	case r.SkipHolidays:
+		desc += ", skipping holidays"
+	}
+	return desc
+}
+
+// ordinal returns 1st, 2nd and so on for n, with last for -1 and 2nd last
+// and so on for the others counting back.
+func ordinal(n int) string {
+	switch {
+	case n == -1:
+		return "last"
+	case n < 0:
+		return ordinal(-n) + " last"
+	case n%100 >= 11 && n%100 <= 13:
+		return strconv.Itoa(n) + "th"
+	}
+	return strconv.Itoa(n) + []string{"th", "st", "nd", "rd", "th", "th", "th", "th", "th", "th"}[n%10]
+}
+
+// Simple reports whether the rule does no more than repeat every so many
+// days, weeks, months or years from due, on the same weekday or day of the
+// month, so that formats with only such repeaters, such as Org mode's +1w,
+// can give it.
+func (r Recurrence) Simple(due time.Time) bool {
+	if r.Freq.finer(Daily) || r.Count > 0 || !r.Until.IsZero() || r.SkipWeekends || r.SkipHolidays ||
+		len(r.YearDays)+len(r.WeekNos)+len(r.Hours)+len(r.Minutes)+len(r.Seconds) > 0 {
+		return false
+	}
+	start := local(due)
+	plain := Recurrence{Freq: r.Freq}.dayDefaults(start)
+	clamped, _ := plain.clampMonthEnd()
+	r = r.dayDefaults(start)
+	return r.sameDays(plain) || r.sameDays(clamped)
+}
+
+// sameDays reports whether r and other fall on the same days.
+func (r Recurrence) sameDays(other Recurrence) bool {
+	return slices.Equal(r.Weekdays, other.Weekdays) && slices.Equal(r.MonthDays, other.MonthDays) &&
+		slices.Equal(r.Months, other.Months) && slices.Equal(r.SetPos, other.SetPos)
+}
+
+// maxPeriods is how many periods Next looks through for the next
+// occurrence before giving up, for rules that never or hardly ever fall.
+// Rules are given up on after a century in any case.
+const maxPeriods = 100000
+
+// Next returns the first occurrence of the rule after prev, or the zero
+// time if there is none before Until. It leaves Count to NextAfter.
+//
+// Times of day are kept as wall clock times in Location, so an item due at
+// 09:00 stays at 09:00 when the clocks change. Items due all day stay due
+// all day. Days of the month past the end of a shorter month fall on its
+// last day, so the 31st becomes the 30th in April rather than the month
+// being left out as in iCalendar, and yearly rules move 29 February to the
+// 28th in other years. Occurrences on days the rule skips are moved to the
+// next day it doesn't.
+func (r Recurrence) Next(prev time.Time) time.Time {
+	start := local(prev)
+	r = r.dayDefaults(start).timeDefaults(start)
+
+	limit := start.AddDate(100, 0, 0)
+	period := r.period(start)
+	for i := 0; i < maxPeriods && period.Before(limit) && !r.past(period); i++ {
+		for _, t := range r.occurrences(period) {
+			if !t.After(start) {
+				continue
+			}
+			if r.past(t) {
+				return time.Time{}
+			}
+			return r.skip(t)
+		}
+		period = r.step(period)
+	}
+	return time.Time{}
+}
+
+// NextAfter returns the first occurrence of the rule after prev that isn't
+// already overdue at now, so that completing an item long after it was due
+// doesn't leave the next one overdue too, and how many occurrences on from
+// prev it is. It returns the zero time if the rule runs out first, having
+// fallen Count times or got to Until.
+func (r Recurrence) NextAfter(prev, now time.Time) (time.Time, int) {
+	next, n := r.Next(prev), 1
+	for !next.IsZero() && (r.Count == 0 || n < r.Count) && (ParsedTodoItem{Due: next}).IsOverdue(now) {
+		next, n = r.Next(next), n+1
+	}
+	if next.IsZero() || r.Count > 0 && n >= r.Count {
+		return time.Time{}, 0
+	}
+	return next, n
+}
+
+// local returns t as a wall clock time in Location. Times at midnight,
+// for items due all day, keep the date as it was given rather than as it
+// is in Location.
+func local(t time.Time) time.Time {
+	if (ParsedTodoItem{Due: t}).DueAllDay() {
+		y, m, d := t.Date()
+		return time.Date(y, m, d, 0, 0, 0, 0, Location)
+	}
+	return t.In(Location)
+}
+
+// dayDefaults fills in the BY parts the rule falls on when it has none of
+// them, from start: its weekday for weekly rules, its day for monthly ones
+// and its date for yearly ones.
+func (r Recurrence) dayDefaults(start time.Time) Recurrence {
+	switch r.Freq {
+	case Weekly:
+		if len(r.Weekdays) == 0 {
+			r.Weekdays = []Weekday{{Day: start.Weekday()}}
+		}
+	case Monthly:
+		if len(r.Weekdays) == 0 && len(r.MonthDays) == 0 {
+			r.MonthDays = []int{start.Day()}
+		}
+	case Yearly:
+		if len(r.Weekdays)+len(r.MonthDays)+len(r.YearDays)+len(r.WeekNos) == 0 {
+			if len(r.Months) == 0 {
+				r.Months = []time.Month{start.Month()}
+			}
+			r.MonthDays = []int{start.Day()}
+		}
+	}
+	return r
+}
+
+// timeDefaults fills in the time of day from start for rules that repeat
+// no more often than it changes.
+func (r Recurrence) timeDefaults(start time.Time) Recurrence {
+	hh, mm, ss := start.Clock()
+	if len(r.Hours) == 0 && !r.Freq.finer(Daily) {
+		r.Hours = []int{hh}
+	}
+	if len(r.Minutes) == 0 && !r.Freq.finer(Hourly) {
+		r.Minutes = []int{mm}
+	}
+	if len(r.Seconds) == 0 && !r.Freq.finer(Minutely) {
+		r.Seconds = []int{ss}
+	}
+	return r
+}
+
+// period returns the start of the year, month, week, day, hour, minute or
+// second, by the rule's frequency, that t is in.
+func (r Recurrence) period(t time.Time) time.Time {
+	y, m, d := t.Date()
+	hh, mm, ss := t.Clock()
+	switch r.Freq {
+	case Yearly:
+		return time.Date(y, 1, 1, 0, 0, 0, 0, Location)
+	case Monthly:
+		return time.Date(y, m, 1, 0, 0, 0, 0, Location)
+	case Weekly:
+		return time.Date(y, m, d-(int(t.Weekday())-int(r.WeekStart)+7)%7, 0, 0, 0, 0, Location)
+	case Daily:
+		return time.Date(y, m, d, 0, 0, 0, 0, Location)
+	case Hourly:
+		return time.Date(y, m, d, hh, 0, 0, 0, Location)
+	case Minutely:
+		return time.Date(y, m, d, hh, mm, 0, 0, Location)
+	}
+	return time.Date(y, m, d, hh, mm, ss, 0, Location)
+}
+
+// step returns the start of the period Interval on from p.
+func (r Recurrence) step(p time.Time) time.Time {
+	n := max(r.Interval, 1)
+	switch r.Freq {
+	case Yearly:
+		return p.AddDate(n, 0, 0)
+	case Monthly:
+		return p.AddDate(0, n, 0)
+	case Weekly:
+		return p.AddDate(0, 0, 7*n)
+	case Daily:
+		return p.AddDate(0, 0, n)
+	case Hourly:
+		return p.Add(time.Duration(n) * time.Hour)
+	case Minutely:
+		return p.Add(time.Duration(n) * time.Minute)
+	}
+	return p.Add(time.Duration(n) * time.Second)
+}
+
+// occurrences returns the times the rule falls on in the period starting
+// at p, in order. The rule's defaults have to have been filled in.
+func (r Recurrence) occurrences(p time.Time) []time.Time {
+	days := []time.Time{p}
+	switch r.Freq {
+	case Yearly, Monthly, Weekly:
+		end := p.AddDate(1, 0, 0)
+		if r.Freq == Monthly {
+			end = p.AddDate(0, 1, 0)
+		} else if r.Freq == Weekly {
+			end = p.AddDate(0, 0, 7)
+		}
+		days = nil
+		for day := p; day.Before(end); day = day.AddDate(0, 0, 1) {
+			days = append(days, day)
+		}
+	}
+
+	hours := clockPart(r.Hours, r.Freq.finer(Daily), p.Hour())
+	minutes := clockPart(r.Minutes, r.Freq.finer(Hourly), p.Minute())
+	seconds := clockPart(r.Seconds, r.Freq.finer(Minutely), p.Second())
+	var times []time.Time
+	for _, day := range days {
+		if !r.onDay(day) {
+			continue
+		}
+		y, m, d := day.Date()
+		for _, hh := range hours {
+			for _, mm := range minutes {
+				for _, ss := range seconds {
+					times = append(times, time.Date(y, m, d, hh, mm, ss, 0, Location))
+				}
+			}
+		}
+	}
+	slices.SortFunc(times, time.Time.Compare)
+	times = slices.CompactFunc(times, time.Time.Equal)
+	if len(r.SetPos) == 0 {
+		return times
+	}
+
+	var picked []time.Time
+	for _, pos := range r.SetPos {
+		i := pos - 1
+		if pos < 0 {
+			i = len(times) + pos
+		}
+		if i >= 0 && i < len(times) {
+			picked = append(picked, times[i])
+		}
+	}
+	slices.SortFunc(picked, time.Time.Compare)
+	return slices.CompactFunc(picked, time.Time.Equal)
+}
+
+// clockPart returns the hours, minutes or seconds of the day in set, or
+// for rules repeating more often than that part changes, the one in the
+// period, at, if set allows it.
+func clockPart(set []int, fixed bool, at int) []int {
+	if !fixed {
+		return set
+	}
+	if len(set) > 0 && !slices.Contains(set, at) {
+		return nil
+	}
+	return []int{at}
+}
+
+// onDay reports whether the rule's BY parts for days allow day.
+func (r Recurrence) onDay(day time.Time) bool {
+	y, m, d := day.Date()
+	if len(r.Months) > 0 && !slices.Contains(r.Months, m) {
+		return false
+	}
+	if len(r.WeekNos) > 0 {
+		n, weeks := weekNumber(day, r.WeekStart)
+		if !slices.ContainsFunc(r.WeekNos, func(w int) bool { return w == n || w == n-weeks-1 }) {
+			return false
+		}
+	}
+	if len(r.YearDays) > 0 {
+		yd, last := day.YearDay(), time.Date(y, 12, 31, 0, 0, 0, 0, time.UTC).YearDay()
+		if !slices.ContainsFunc(r.YearDays, func(n int) bool { return n == yd || n == yd-last-1 }) {
+			return false
+		}
+	}
+	if len(r.MonthDays) > 0 {
+		last := daysIn(y, m)
+		onDay := func(n int) bool { return n == d || n == d-last-1 }
+		if !slices.ContainsFunc(r.MonthDays, onDay) {
+			return false
+		}
+	}
+	if len(r.Weekdays) > 0 {
+		// Counted days are counted in the month for monthly rules and
+		// yearly ones in given months, and otherwise in the year.
+		inYear := r.Freq == Yearly && len(r.Months) == 0
+		onDay := func(w Weekday) bool {
+			return w.Day == day.Weekday() && (w.N == 0 || w.N == nth(day, inYear, w.N < 0))
+		}
+		if !slices.ContainsFunc(r.Weekdays, onDay) {
+			return false
+		}
+	}
+	return true
+}
+
+// nth returns which of the days on its weekday in its month, or its year if
+// inYear is set, day is: 1 for the first, or if fromEnd, -1 for the last.
+func nth(day time.Time, inYear, fromEnd bool) int {
+	at, last := day.Day(), daysIn(day.Year(), day.Month())
+	if inYear {
+		at, last = day.YearDay(), time.Date(day.Year(), 12, 31, 0, 0, 0, 0, time.UTC).YearDay()
+	}
+	if fromEnd {
+		return -((last-at)/7 + 1)
+	}
+	return (at-1)/7 + 1
+}
+
+// weekNumber returns the week of the year day is in and how many weeks
+// that year has. Week 1 is the first week, starting on wkst, with at least
+// four days in the year, so the days around New Year may be in a week of
+// the year before or after.
+func weekNumber(day time.Time, wkst time.Weekday) (n, weeks int) {
+	date := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+	y := date.Year()
+	first := firstWeek(y, wkst)
+	if date.Before(first) {
+		y--
+		first = firstWeek(y, wkst)
+	} else if next := firstWeek(y+1, wkst); !date.Before(next) {
+		y++
+		first = next
+	}
+	const week = 7 * 24 * time.Hour
+	return int(date.Sub(first)/week) + 1, int(firstWeek(y+1, wkst).Sub(first) / week)
+}
+
+// firstWeek returns the start of week 1 of year, at midnight UTC.
+func firstWeek(year int, wkst time.Weekday) time.Time {
+	jan1 := time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
+	offset := (int(wkst) - int(jan1.Weekday()) + 7) % 7
+	if offset >= 4 {
+		offset -= 7
+	}
+	return jan1.AddDate(0, 0, offset)
+}
+
+// past reports whether t is after Until.
+func (r Recurrence) past(t time.Time) bool {
+	if r.Until.IsZero() {
+		return false
+	}
+	if r.untilDate() {
+		y, m, d := t.Date()
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC).After(r.Until)
+	}
+	return t.After(r.Until)
+}
+
+// untilDate reports whether Until is a date rather than a time.
+func (r Recurrence) untilDate() bool {
+	if r.Until.IsZero() {
+		return false
+	}
+	u := r.Until.UTC()
+	return u.Hour() == 0 && u.Minute() == 0 && u.Second() == 0
+}
+
+// skip moves t to the next day the rule doesn't skip, keeping the time of
+// day.
+func (r Recurrence) skip(t time.Time) time.Time {
+	for i := 0; i < 366 && r.skips(t); i++ {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t
+}
+
+// skips reports whether the rule skips the day t is on.
+func (r Recurrence) skips(t time.Time) bool {
+	if r.SkipWeekends && (t.Weekday() == time.Saturday || t.Weekday() == time.Sunday) {
+		return true
+	}
+	return r.SkipHolidays && Holidays.Has(t)
+}
+
+// daysIn returns the number of days in the month.
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
@@ -0,0 +1,245 @@
+package todo
+
+import (
+	"errors"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+// inLocation makes Location name for the rest of the test.
+func inLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := Location
+	Location = loc
+	t.Cleanup(func() { Location = saved })
+	return loc
+}
+
+func TestParseRepeat(t *testing.T) {
+	inLocation(t, "UTC")
+	due := time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		in, want string
+	}{
+		{"weekly", "FREQ=WEEKLY"},
+		{"every 2 weeks", "FREQ=WEEKLY;INTERVAL=2"},
+		{"every last friday", "FREQ=MONTHLY;BYDAY=-1FR"},
+		{"every weekday", "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR"},
+		{"every business day", "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR;X-SKIP=WEEKENDS,HOLIDAYS"},
+		{"every 4th thursday of november", "FREQ=YEARLY;BYMONTH=11;BYDAY=4TH"},
+		{"monthly", "FREQ=MONTHLY;BYMONTHDAY=-1"},
+		{"monthly skipping weekends", "FREQ=MONTHLY;BYMONTHDAY=-1;X-SKIP=WEEKENDS"},
+		{"yearly", "FREQ=YEARLY"},
+		{"FREQ=MONTHLY", "FREQ=MONTHLY;BYMONTHDAY=31"},
+		{"FREQ=MONTHLY;BYDAY=-1FR", "FREQ=MONTHLY;BYDAY=-1FR"},
+		{"rrule:freq=weekly;count=3", "FREQ=WEEKLY;COUNT=3"},
+	}
+	for _, tt := range tests {
+		r, err := ParseRepeat(tt.in, due)
+		if err != nil {
+			t.Errorf("ParseRepeat(%q): %v", tt.in, err)
+			continue
+		}
+		if got := r.String(); got != tt.want {
+			t.Errorf("ParseRepeat(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+
+	for _, in := range []string{"", "every fortnight", "FREQ=SOMETIMES", "FREQ=DAILY;FREQ=DAILY", "FREQ=DAILY;INTERVAL"} {
+		if _, err := ParseRepeat(in, due); !errors.Is(err, ErrBadRepeat) {
+			t.Errorf("ParseRepeat(%q) error = %v, want ErrBadRepeat", in, err)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+	tests := []struct {
+		name, rule string
+		from       time.Time
+		want       []time.Time
+	}{
+		// As in RFC 5545, months without the day are skipped.
+		{
+			"day past the end of the month", "FREQ=MONTHLY;BYMONTHDAY=31", day(2025, 10, 31),
+			[]time.Time{day(2025, 12, 31), day(2026, 1, 31), day(2026, 3, 31), day(2026, 5, 31)},
+		},
+		{
+			"day counted back past the start of the month", "FREQ=MONTHLY;BYMONTHDAY=-30", day(2026, 1, 2),
+			[]time.Time{day(2026, 3, 2), day(2026, 4, 1), day(2026, 5, 2)},
+		},
+		{
+			"29 February", "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=29", day(2028, 2, 29),
+			[]time.Time{day(2032, 2, 29), day(2036, 2, 29)},
+		},
+		// Phrases fall back to the end of shorter months instead.
+		{
+			"monthly from the 31st", "monthly", day(2026, 1, 31),
+			[]time.Time{day(2026, 2, 28), day(2026, 3, 31), day(2026, 4, 30)},
+		},
+		{
+			"monthly from the 30th", "monthly", day(2028, 1, 30),
+			[]time.Time{day(2028, 2, 29), day(2028, 3, 30), day(2028, 4, 30), day(2028, 5, 30)},
+		},
+		{
+			"last friday", "every last friday", day(2025, 1, 31),
+			[]time.Time{day(2025, 2, 28), day(2025, 3, 28), day(2025, 4, 25)},
+		},
+		{
+			"until a date", "FREQ=WEEKLY;UNTIL=20250115", day(2025, 1, 1),
+			[]time.Time{day(2025, 1, 8), day(2025, 1, 15), {}},
+		},
+	}
+	inLocation(t, "UTC")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := ParseRepeat(tt.rule, tt.from)
+			if err != nil {
+				t.Fatal(err)
+			}
+			prev := tt.from
+			for _, want := range tt.want {
+				got := r.Next(prev)
+				if !got.Equal(want) {
+					t.Fatalf("Next(%s) = %s, want %s", prev, got, want)
+				}
+				prev = got
+			}
+		})
+	}
+
+	t.Run("yearly from 29 February", func(t *testing.T) {
+		r, err := ParseRepeat("yearly", day(2028, 2, 29))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := r.String(), "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=-1"; got != want {
+			t.Errorf("ParseRepeat = %s, want %s", got, want)
+		}
+		prev := day(2028, 2, 29)
+		for _, want := range []time.Time{day(2029, 2, 28), day(2030, 2, 28), day(2031, 2, 28), day(2032, 2, 29)} {
+			if got := r.Next(prev); !got.Equal(want) {
+				t.Fatalf("Next(%s) = %s, want %s", prev, got, want)
+			}
+			prev = want
+		}
+	})
+}
+
+func TestNextAcrossDST(t *testing.T) {
+	ny := inLocation(t, "America/New_York")
+	at := func(m time.Month, d, hh, mm int) time.Time { return time.Date(2025, m, d, hh, mm, 0, 0, ny) }
+	tests := []struct {
+		name, rule string
+		from       time.Time
+		want       []time.Time
+	}{
+		// Clocks go forward at 02:00 on 9 March 2025 and back at 02:00 on
+		// 2 November.
+		{"daily into summer time", "daily", at(3, 8, 9, 0), []time.Time{at(3, 9, 9, 0), at(3, 10, 9, 0)}},
+		{"daily out of summer time", "daily", at(11, 1, 9, 0), []time.Time{at(11, 2, 9, 0), at(11, 3, 9, 0)}},
+		{"weekly out of summer time", "weekly", at(10, 27, 18, 30), []time.Time{at(11, 3, 18, 30)}},
+		{"all day into summer time", "daily", at(3, 8, 0, 0), []time.Time{at(3, 9, 0, 0), at(3, 10, 0, 0)}},
+		{"hourly over the missing hour", "hourly", at(3, 9, 1, 30), []time.Time{at(3, 9, 3, 30), at(3, 9, 4, 30)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := ParseRepeat(tt.rule, tt.from)
+			if err != nil {
+				t.Fatal(err)
+			}
+			prev := tt.from
+			for _, want := range tt.want {
+				got := r.Next(prev)
+				if !got.Equal(want) {
+					t.Fatalf("Next(%s) = %s, want %s", prev, got, want)
+				}
+				if got.Location() != ny {
+					t.Errorf("Next(%s) is in %s, want %s", prev, got.Location(), ny)
+				}
+				prev = got
+			}
+		})
+	}
+}
+
+func TestNextAfter(t *testing.T) {
+	inLocation(t, "UTC")
+	jan := func(d int) time.Time { return time.Date(2025, 1, d, 0, 0, 0, 0, time.UTC) }
+	now := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		rule  string
+		prev  time.Time
+		want  time.Time
+		wantN int
+	}{
+		{"daily", jan(1), jan(10), 9},
+		{"daily", jan(11), jan(12), 1},
+		{"weekly", jan(1), jan(15), 2},
+		{"FREQ=DAILY;COUNT=20", jan(1), jan(10), 9},
+		{"FREQ=DAILY;COUNT=5", jan(1), time.Time{}, 0},
+		{"FREQ=DAILY;UNTIL=20250105", jan(1), time.Time{}, 0},
+	}
+	for _, tt := range tests {
+		r, err := ParseRepeat(tt.rule, tt.prev)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, n := r.NextAfter(tt.prev, now)
+		if !got.Equal(tt.want) || n != tt.wantN {
+			t.Errorf("%s NextAfter(%s) = %s, %d, want %s, %d", tt.rule, tt.prev.Format(time.DateOnly), got, n, tt.want, tt.wantN)
+		}
+	}
+}
+
+func TestNextOccurrence(t *testing.T) {
+	inLocation(t, "UTC")
+	now := time.Date(2025, 1, 5, 15, 0, 0, 0, time.UTC)
+	item := ParsedTodoItem{
+		ID:          4,
+		Todo:        "Water the plants",
+		Due:         time.Date(2025, 1, 5, 0, 0, 0, 0, time.UTC),
+		Repeat:      "FREQ=DAILY;COUNT=3",
+		Tags:        []string{"home"},
+		Completed:   true,
+		CompletedAt: now,
+	}
+	next, ok := item.NextOccurrence(now)
+	if !ok {
+		t.Fatal("NextOccurrence ran out")
+	}
+	if want := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC); !next.Due.Equal(want) {
+		t.Errorf("Due = %s, want %s", next.Due, want)
+	}
+	if next.Repeat != "FREQ=DAILY;COUNT=2" {
+		t.Errorf("Repeat = %q, want the count left", next.Repeat)
+	}
+	if next.ID != 0 || next.Completed || !next.CompletedAt.IsZero() || !next.CreatedAt.Equal(now) {
+		t.Errorf("NextOccurrence = %+v, want a new item not done yet", next)
+	}
+	next.Tags[0] = "garden"
+	if item.Tags[0] != "home" {
+		t.Error("the next occurrence shares its tags with the item")
+	}
+
+	item.Repeat = "FREQ=DAILY;COUNT=1"
+	if _, ok := item.NextOccurrence(now); ok {
+		t.Error("NextOccurrence of a rule with no count left didn't run out")
+	}
+
+	item.Due, item.Repeat = time.Time{}, "daily"
+	next, ok = item.NextOccurrence(now)
+	if want := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC); !ok || !next.Due.Equal(want) {
+		t.Errorf("NextOccurrence without a due date = %s, %v, want %s", next.Due, ok, want)
+	}
+
+	item.Repeat = ""
+	if _, ok := item.NextOccurrence(now); ok {
+		t.Error("NextOccurrence of an item that doesn't repeat")
+	}
+}
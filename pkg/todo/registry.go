@@ -0,0 +1,66 @@
+package todo
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// OpenFunc opens a Store from a store URL such as `sqlite:///path/to/db`.
+// It is given the whole URL, scheme included.
+type OpenFunc func(url string) (Store, error)
+
+var (
+	backendsMu sync.RWMutex
+	backends   = map[string]OpenFunc{}
+)
+
+// Register makes a storage backend available to Open under the given URL
+// scheme. It is meant to be called from the init function of the package
+// implementing the backend, in the same way as database/sql drivers, and
+// panics if the scheme is registered twice.
+func Register(scheme string, open OpenFunc) {
+	backendsMu.Lock()
+	defer backendsMu.Unlock()
+
+	if open == nil {
+		panic("todo: Register open func is nil")
+	}
+	if _, dup := backends[scheme]; dup {
+		panic("todo: Register called twice for scheme " + scheme)
+	}
+	backends[scheme] = open
+}
+
+// Backends returns the registered URL schemes in sorted order.
+func Backends() []string {
+	backendsMu.RLock()
+	defer backendsMu.RUnlock()
+
+	var schemes []string
+	for scheme := range backends {
+		schemes = append(schemes, scheme)
+	}
+	sort.Strings(schemes)
+	return schemes
+}
+
+// Open opens the store at url using the backend registered for its scheme.
+// Anything without a `scheme://` prefix is taken to be the path of a JSON
+// file.
+func Open(url string) (Store, error) {
+	scheme, _, ok := strings.Cut(url, "://")
+	if !ok {
+		return NewJSONStore(url), nil
+	}
+
+	backendsMu.RLock()
+	open, found := backends[scheme]
+	backendsMu.RUnlock()
+
+	if !found {
+		return nil, fmt.Errorf("unknown store %q (known stores: %s)", scheme, strings.Join(Backends(), ", "))
+	}
+	return open(url)
+}
@@ -0,0 +1,66 @@
+package todo
+
+import (
+	"fmt"
+	"time"
+)
+
+// RelativeDueDays is how many days either side of today RelativeDue
+// describes. Further out than that, a date is easier to read.
+const RelativeDueDays = 7
+
+// RelativeDue describes when the item is due relative to now, such as
+// "due tomorrow", "due in 3 hours" or "2 days overdue". Items due today at
+// a particular time are described in hours and minutes, and everything
+// else in calendar days in Location, with the time if there is one. It
+// returns false if the item has no due date, has been done, or is due more
+// than RelativeDueDays away.
+func (item ParsedTodoItem) RelativeDue(now time.Time) (string, bool) {
+	if item.Due.IsZero() || item.Completed {
+		return "", false
+	}
+
+	days := int(startOfDay(item.Due).Sub(startOfDay(now)).Round(24*time.Hour) / (24 * time.Hour))
+	if days > RelativeDueDays || days < -RelativeDueDays {
+		return "", false
+	}
+
+	if days == 0 && !item.DueAllDay() {
+		left := item.Due.Sub(now)
+		switch {
+		case left >= time.Hour:
+			return "due in " + plural(int(left/time.Hour), "hour"), true
+		case left >= time.Minute:
+			return "due in " + plural(int(left/time.Minute), "minute"), true
+		case left > -time.Minute:
+			return "due now", true
+		case left > -time.Hour:
+			return plural(int(-left/time.Minute), "minute") + " overdue", true
+		default:
+			return plural(int(-left/time.Hour), "hour") + " overdue", true
+		}
+	}
+
+	at := ""
+	if !item.DueAllDay() {
+		at = item.Due.In(Location).Format(" at 15:04")
+	}
+	switch {
+	case days == 0:
+		return "due today", true
+	case days == 1:
+		return "due tomorrow" + at, true
+	case days > 1:
+		return fmt.Sprintf("due in %d days%s", days, at), true
+	default:
+		return plural(-days, "day") + " overdue", true
+	}
+}
+
+// plural returns n and unit, adding an s to unit unless n is 1.
+func plural(n int, unit string) string {
+	if n == 1 {
+		return "1 " + unit
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
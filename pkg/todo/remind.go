@@ -0,0 +1,161 @@
+package todo
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AllDayReminder is the time of day that items due all day count as due at
+// for reminders, so that an hour's notice isn't given at 11pm the night
+// before.
+const AllDayReminder = 9 * time.Hour
+
+// Reminder is a reminder of an item, Before it is due.
+type Reminder struct {
+	Item   ParsedTodoItem
+	At     time.Time
+	Before time.Duration
+}
+
+// RemindAt returns when to remind of the item before it is due. Items due
+// all day count as due at AllDayReminder on the day.
+func (item ParsedTodoItem) RemindAt(before time.Duration) time.Time {
+	due := item.Due
+	if item.DueAllDay() {
+		due = due.Add(AllDayReminder)
+	}
+	return due.Add(-before)
+}
+
+// RemindOffsets returns how long before the item is due to remind of it:
+// its own reminder times if it has them, or else offsets.
+func (item ParsedTodoItem) RemindOffsets(offsets []time.Duration) []time.Duration {
+	if item.Remind == "" {
+		return offsets
+	}
+	own, err := ParseOffsets(item.Remind)
+	if err != nil {
+		return offsets
+	}
+	return own
+}
+
+// Reminders returns the reminders of the open items with due dates, each
+// of the item's RemindOffsets before they are due, that fall after from
+// and no later than to, soonest first. Of those falling in that time for
+// the same item only the last is returned, as after the computer has been
+// asleep.
+func Reminders(items []ParsedTodoItem, offsets []time.Duration, from, to time.Time) []Reminder {
+	var reminders []Reminder
+	for _, item := range items {
+		if item.Completed || item.Due.IsZero() {
+			continue
+		}
+		var last *Reminder
+		for _, before := range item.RemindOffsets(offsets) {
+			at := item.RemindAt(before)
+			if at.After(from) && !at.After(to) && (last == nil || at.After(last.At)) {
+				last = &Reminder{Item: item, At: at, Before: before}
+			}
+		}
+		if last != nil {
+			reminders = append(reminders, *last)
+		}
+	}
+	slices.SortStableFunc(reminders, func(a, b Reminder) int { return a.At.Compare(b.At) })
+	return reminders
+}
+
+// NextReminder returns when the first of the reminders Reminders would
+// give after from is, if there is one.
+func NextReminder(items []ParsedTodoItem, offsets []time.Duration, from time.Time) (time.Time, bool) {
+	var next time.Time
+	for _, item := range items {
+		if item.Completed || item.Due.IsZero() {
+			continue
+		}
+		for _, before := range item.RemindOffsets(offsets) {
+			if at := item.RemindAt(before); at.After(from) && (next.IsZero() || at.Before(next)) {
+				next = at
+			}
+		}
+	}
+	return next, !next.IsZero()
+}
+
+// ParseDuration parses a length of time given in days or weeks, such as
+// 30d or 2w, or as time.ParseDuration takes it, such as 90m or 1h30m. It
+// can't be negative.
+func ParseDuration(s string) (time.Duration, error) {
+	if n, err := strconv.Atoi(strings.TrimRight(s, "dw")); err == nil && n >= 0 && len(s) > 1 {
+		switch s[len(s)-1] {
+		case 'd':
+			return time.Duration(n) * 24 * time.Hour, nil
+		case 'w':
+			return time.Duration(n) * 7 * 24 * time.Hour, nil
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("bad length of time %q, expected e.g. 30d, 2w or 12h", s)
+	}
+	return d, nil
+}
+
+// ParseOffsets parses a comma separated list of how long before items are
+// due to remind of them, each as ParseDuration takes it, such as "1h,10m"
+// or "1d,2h". 0 reminds of them when they are due.
+func ParseOffsets(s string) ([]time.Duration, error) {
+	var offsets []time.Duration
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		d, err := ParseDuration(part)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.Contains(offsets, d) {
+			offsets = append(offsets, d)
+		}
+	}
+	if len(offsets) == 0 {
+		return nil, fmt.Errorf("no reminder times in %q, expected e.g. 1h,10m", s)
+	}
+	return offsets, nil
+}
+
+// FormatOffsets formats reminder times as ParseOffsets takes them, in days
+// where they are whole days, such as "1d,2h".
+func FormatOffsets(offsets []time.Duration) string {
+	parts := make([]string, len(offsets))
+	for i, d := range offsets {
+		parts[i] = formatDuration(d)
+	}
+	return strings.Join(parts, ",")
+}
+
+// formatDuration formats d as ParseDuration takes it, in days where it is
+// a whole number of them, and without the zero minutes and seconds of
+// time.Duration's String, such as 2d, 2h or 1h30m.
+func formatDuration(d time.Duration) string {
+	const day = 24 * time.Hour
+	if d == 0 {
+		return "0"
+	}
+	if d%day == 0 {
+		return strconv.Itoa(int(d/day)) + "d"
+	}
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
@@ -0,0 +1,151 @@
+package todo
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Searcher is implemented by stores that keep a full-text index, so Search
+// doesn't have to read every item.
+type Searcher interface {
+	// SearchWords returns the items on the list that have, for each of
+	// terms, a word starting with it. terms are as returned by Tokenize.
+	SearchWords(terms []string) ([]ParsedTodoItem, error)
+}
+
+// Tokenize splits text into lower case words for searching. Anything that
+// isn't a letter or digit separates words.
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// SearchText returns the text of the item that searches look at: the task,
+// notes, tags, contexts and project.
+func (item ParsedTodoItem) SearchText() string {
+	parts := []string{item.Todo, item.Notes, item.Project}
+	parts = append(parts, item.Tags...)
+	parts = append(parts, item.Contexts...)
+	return strings.Join(parts, "\n")
+}
+
+// MatchesWords reports whether the item has, for each of terms, a word
+// starting with it.
+func (item ParsedTodoItem) MatchesWords(terms []string) bool {
+	words := Tokenize(item.SearchText())
+	for _, term := range terms {
+		found := false
+		for _, word := range words {
+			if strings.HasPrefix(word, term) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Search returns the items on the list matching query, ignoring case. Each
+// word of the query has to match the start of a word in the item's task,
+// notes, tags, contexts or project, so "inv" finds "Send invoices". Stores
+// with a full-text index are searched through it.
+func Search(store Store, query string) ([]ParsedTodoItem, error) {
+	terms := Tokenize(query)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+
+	var candidates []ParsedTodoItem
+	var err error
+	if searcher, ok := store.(Searcher); ok {
+		candidates, err = searcher.SearchWords(terms)
+	} else {
+		candidates, err = store.List()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Indexes may tokenize a little differently, so check every candidate.
+	var found []ParsedTodoItem
+	for _, item := range candidates {
+		if item.MatchesWords(terms) {
+			found = append(found, item)
+		}
+	}
+	return found, nil
+}
+
+// SearchRegexp returns the items on the list whose task, notes, tags,
+// contexts or project match re. It always reads every item.
+func SearchRegexp(store Store, re *regexp.Regexp) ([]ParsedTodoItem, error) {
+	items, err := store.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var found []ParsedTodoItem
+	for _, item := range items {
+		if re.MatchString(item.SearchText()) {
+			found = append(found, item)
+		}
+	}
+	return found, nil
+}
+
+// Fuzzy returns the items whose task matches query, ignoring case, keeping
+// only those that match it best: a task that is the query, then tasks with
+// the query in them, then ones with each of its words in them, and last
+// ones with its letters in the same order, as "dntst" has those of "Call
+// the dentist". One item is returned when it stands out from the rest.
+func Fuzzy(items []ParsedTodoItem, query string) []ParsedTodoItem {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return nil
+	}
+	words := strings.Fields(query)
+	score := func(task string) int {
+		task = strings.ToLower(task)
+		switch {
+		case task == query:
+			return 4
+		case strings.Contains(task, query):
+			return 3
+		}
+		all := true
+		for _, word := range words {
+			all = all && strings.Contains(task, word)
+		}
+		if all {
+			return 2
+		}
+		rest := task
+		for _, r := range strings.Join(words, "") {
+			i := strings.IndexRune(rest, r)
+			if i < 0 {
+				return 0
+			}
+			rest = rest[i+utf8.RuneLen(r):]
+		}
+		return 1
+	}
+
+	var found []ParsedTodoItem
+	best := 1
+	for _, item := range items {
+		s := score(item.Todo)
+		if s > best {
+			best, found = s, nil
+		}
+		if s == best {
+			found = append(found, item)
+		}
+	}
+	return found
+}
@@ -0,0 +1,82 @@
+package todo
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrBadSnooze is returned when how long to snooze an item for can't be
+// parsed, or wouldn't move it later.
+var ErrBadSnooze = errors.New("bad snooze")
+
+// SnoozeFor returns when the item is due after putting it off by for, a
+// length of time such as 2d, 1w, 3h, "2 days" or "a week". Days, weeks,
+// months and years keep the time of day it is due at, or leave it due all
+// day. An overdue item, or one that isn't due, is put off from now rather
+// than from when it was due.
+func (item ParsedTodoItem) SnoozeFor(by string, now time.Time) (time.Time, error) {
+	words := strings.Fields(strings.ToLower(by))
+	if len(words) == 1 && !strings.HasPrefix(words[0], "+") {
+		// 2d is the same as +2d.
+		words[0] = "+" + words[0]
+	}
+	n, unit, ok := parseOffset(words)
+	if !ok || n <= 0 {
+		return time.Time{}, fmt.Errorf("%w %q, expected e.g. 2d, 1w, 3h or \"2 days\"", ErrBadSnooze, by)
+	}
+
+	now = now.In(Location)
+	due := item.Due
+	switch unit {
+	case "h", "min":
+		if due.IsZero() || item.IsOverdue(now) {
+			due = now.Truncate(time.Minute)
+		}
+		d := time.Duration(n) * time.Hour
+		if unit == "min" {
+			d = time.Duration(n) * time.Minute
+		}
+		return due.Add(d), nil
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, Location)
+	switch {
+	case due.IsZero():
+		due = today
+	case item.IsOverdue(now):
+		// The same time of day as it was due, but today.
+		due = due.In(Location)
+		due = time.Date(now.Year(), now.Month(), now.Day(), due.Hour(), due.Minute(), due.Second(), 0, Location)
+	}
+	switch unit {
+	case "w":
+		return due.AddDate(0, 0, 7*n), nil
+	case "mo":
+		return due.AddDate(0, n, 0), nil
+	case "y":
+		return due.AddDate(n, 0, 0), nil
+	}
+	return due.AddDate(0, 0, n), nil
+}
+
+// Snooze puts the item off until due, counting the times it has been put
+// off in Snoozed so that items that keep being put off stand out. due has
+// to be later than the item was due, and not already past at now.
+func (item *ParsedTodoItem) Snooze(due, now time.Time) error {
+	until := ParsedTodoItem{Due: due}
+	when := due.Format("2006-01-02 15:04")
+	if until.DueAllDay() {
+		when = due.Format("2006-01-02")
+	}
+	if !item.Due.IsZero() && !due.After(item.Due) {
+		return fmt.Errorf("%w: %s is no later than the item is due", ErrBadSnooze, when)
+	}
+	if until.IsOverdue(now) {
+		return fmt.Errorf("%w: %s has already passed", ErrBadSnooze, when)
+	}
+	item.Due = due
+	item.Snoozed++
+	return nil
+}
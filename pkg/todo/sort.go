@@ -0,0 +1,151 @@
+package todo
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// ErrBadSort is returned when a sort order can't be parsed.
+var ErrBadSort = errors.New("bad sort order")
+
+// Comparator compares two items in the style of cmp.Compare, returning a
+// negative number if a sorts before b, a positive one if it sorts after and
+// zero if neither comes first.
+type Comparator func(a, b ParsedTodoItem) int
+
+// sortKeys are the fields that can be sorted on. Empty values, such as no
+// due date or no project, go last whichever way the key is sorted.
+var sortKeys = map[string]struct {
+	compare Comparator
+	empty   func(item ParsedTodoItem) bool
+}{
+	"due": {
+		func(a, b ParsedTodoItem) int { return a.Due.Compare(b.Due) },
+		func(item ParsedTodoItem) bool { return item.Due.IsZero() },
+	},
+	"priority": {
+		func(a, b ParsedTodoItem) int {
+			now := Now()
+			return cmp.Compare(a.EffectivePriority(now), b.EffectivePriority(now))
+		},
+		func(item ParsedTodoItem) bool { return item.EffectivePriority(Now()) == PriorityNone },
+	},
+	"created": {
+		func(a, b ParsedTodoItem) int { return a.CreatedAt.Compare(b.CreatedAt) },
+		func(item ParsedTodoItem) bool { return item.CreatedAt.IsZero() },
+	},
+	"completed": {
+		func(a, b ParsedTodoItem) int { return a.CompletedAt.Compare(b.CompletedAt) },
+		func(item ParsedTodoItem) bool { return item.CompletedAt.IsZero() },
+	},
+	"id": {
+		func(a, b ParsedTodoItem) int { return cmp.Compare(a.ID, b.ID) },
+		func(ParsedTodoItem) bool { return false },
+	},
+	"text": {
+		func(a, b ParsedTodoItem) int { return cmp.Compare(strings.ToLower(a.Todo), strings.ToLower(b.Todo)) },
+		func(ParsedTodoItem) bool { return false },
+	},
+	"project": {
+		func(a, b ParsedTodoItem) int {
+			return cmp.Compare(strings.ToLower(a.Project), strings.ToLower(b.Project))
+		},
+		func(item ParsedTodoItem) bool { return item.Project == "" },
+	},
+	"estimate": {
+		func(a, b ParsedTodoItem) int { return cmp.Compare(a.EstimateLength(), b.EstimateLength()) },
+		func(item ParsedTodoItem) bool { return item.Estimate == "" },
+	},
+	"spent": {
+		func(a, b ParsedTodoItem) int { return cmp.Compare(a.Spent(Now()), b.Spent(Now())) },
+		func(item ParsedTodoItem) bool { return len(item.Worked) == 0 },
+	},
+}
+
+// SortKeys returns the names of the keys ParseSort understands.
+func SortKeys() []string {
+	return slices.Sorted(maps.Keys(sortKeys))
+}
+
+// ParseSort parses a sort order such as "due,-priority,created": the keys to
+// sort on, most important first, each prefixed with - to sort it in
+// descending order. The keys are due, priority, created, completed, id,
+// text, project, estimate and spent. Priority is as Escalate raises it.
+func ParseSort(spec string) (Comparator, error) {
+	var cmps []Comparator
+	for _, field := range strings.Split(spec, ",") {
+		field = strings.ToLower(strings.TrimSpace(field))
+		desc := false
+		if rest, ok := strings.CutPrefix(field, "-"); ok {
+			field, desc = rest, true
+		} else {
+			field = strings.TrimPrefix(field, "+")
+		}
+
+		key, ok := sortKeys[field]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown key %q, expected one of %s", ErrBadSort, field, strings.Join(SortKeys(), ", "))
+		}
+		cmps = append(cmps, sortKey(key.compare, key.empty, desc))
+	}
+	return Chain(cmps...), nil
+}
+
+// sortKey returns a comparator on one key, keeping empty values last.
+func sortKey(compare Comparator, empty func(ParsedTodoItem) bool, desc bool) Comparator {
+	return func(a, b ParsedTodoItem) int {
+		aEmpty, bEmpty := empty(a), empty(b)
+		switch {
+		case aEmpty && bEmpty:
+			return 0
+		case aEmpty:
+			return 1
+		case bEmpty:
+			return -1
+		}
+		if desc {
+			return -compare(a, b)
+		}
+		return compare(a, b)
+	}
+}
+
+// Chain returns a comparator that compares with each of cmps in turn,
+// moving on to the next one when items compare equal.
+func Chain(cmps ...Comparator) Comparator {
+	return func(a, b ParsedTodoItem) int {
+		for _, compare := range cmps {
+			if c := compare(a, b); c != 0 {
+				return c
+			}
+		}
+		return 0
+	}
+}
+
+// SortBy orders items with compare. Items that compare equal are kept in
+// the order they were in.
+func SortBy(items []ParsedTodoItem, compare Comparator) {
+	slices.SortStableFunc(items, compare)
+}
+
+// byDue is the order SortByDue uses, which is also the default for lists.
+var byDue = Chain(
+	sortKey(sortKeys["due"].compare, sortKeys["due"].empty, false),
+	func(a, b ParsedTodoItem) int {
+		now := Now()
+		return cmp.Compare(b.EffectivePriority(now), a.EffectivePriority(now))
+	},
+)
+
+// SortByDue orders items by due date, earliest first. Items due at the same
+// time are ordered by priority, highest first, as Escalate raises it. Items
+// without a due date go at the end, also by priority, and are otherwise
+// kept in the order they were added.
+func SortByDue(items []ParsedTodoItem) {
+	SortBy(items, byDue)
+}
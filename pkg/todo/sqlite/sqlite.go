@@ -0,0 +1,694 @@
+// Package sqlite provides a todo.Store backed by a SQLite database. Importing
+// it registers the `sqlite://` store URL scheme:
+//
+//	import _ "github.com/buck06191/todo-app/pkg/todo/sqlite"
+//
+//	store, err := todo.Open("sqlite:///home/me/.todo/todos.db")
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/buck06191/todo-app/pkg/seal"
+	"github.com/buck06191/todo-app/pkg/todo"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func init() {
+	todo.Register("sqlite", func(url string) (todo.Store, error) {
+		return Open(strings.TrimPrefix(url, "sqlite://"))
+	})
+}
+
+// migrations are run in order to bring a database up to date. The number of
+// migrations already applied is kept in SQLite's user_version, so existing
+// entries must never be changed; add a new one instead.
+//
+// The whole item is kept as JSON in the data column, which is what gets read
+// back. The other columns are copies of the fields that are filtered and
+// sorted on so they can be indexed, and items_fts is a full-text index used
+// by SearchWords. The list column is the namespace an item or setting is
+// in, with an empty name for the default one; IDs are unique across every list.
+//
+// Once the database is encrypted, the data column and the values in meta
+// are sealed, the todo, due and completed columns are left empty and
+// nothing is kept in items_fts. Only which list an item is in and when it
+// was moved to the trash can still be read.
+var migrations = []string{
+	`CREATE TABLE items (
+		id         INTEGER PRIMARY KEY AUTOINCREMENT,
+		todo       TEXT NOT NULL,
+		due        TEXT,
+		completed  INTEGER NOT NULL DEFAULT 0,
+		deleted_at TEXT,
+		data       TEXT NOT NULL
+	);
+	CREATE INDEX items_due ON items (due);
+	CREATE INDEX items_status ON items (deleted_at, completed);`,
+
+	// Full-text index over the searchable text of each item, with the
+	// item's ID as the docid.
+	`CREATE VIRTUAL TABLE items_fts USING fts4 (body);
+	INSERT INTO items_fts (docid, body)
+		SELECT id, concat_ws(char(10),
+			json_extract(data, '$.todo'),
+			json_extract(data, '$.notes'),
+			json_extract(data, '$.project'),
+			(SELECT group_concat(value, char(10)) FROM json_each(data, '$.tags')),
+			(SELECT group_concat(value, char(10)) FROM json_each(data, '$.contexts')))
+		FROM items;`,
+
+	// Settings saved with PutMeta.
+	`CREATE TABLE meta (
+		key   TEXT PRIMARY KEY,
+		value TEXT NOT NULL
+	);`,
+
+	// Namespaces. SQLite can't change a primary key, so meta is copied
+	// into a new table keyed on both columns.
+	`ALTER TABLE items ADD COLUMN list TEXT NOT NULL DEFAULT '';
+	CREATE INDEX items_list ON items (list, deleted_at, completed);
+	CREATE TABLE list_meta (
+		list  TEXT NOT NULL DEFAULT '',
+		key   TEXT NOT NULL,
+		value TEXT NOT NULL,
+		PRIMARY KEY (list, key)
+	);
+	INSERT INTO list_meta (key, value) SELECT key, value FROM meta;
+	DROP TABLE meta;
+	ALTER TABLE list_meta RENAME TO meta;`,
+
+	// Encryption. sample is a value sealed with the key the database is
+	// encrypted with, for checking keys against, and there is no row
+	// until it is encrypted.
+	`CREATE TABLE sealed (sample TEXT NOT NULL);`,
+}
+
+// sealedPrefix starts the data of an item or the value of a setting that
+// is encrypted, going on with the sealed JSON in base64.
+const sealedPrefix = "todo-app:sealed:"
+
+// sample is what the sample in the sealed table decrypts to.
+const sample = "todo-app"
+
+// Store is a todo.Store saving to a SQLite database.
+type Store struct {
+	db *sql.DB
+	// list is the namespace the store reads and writes.
+	list string
+	// view is set for stores returned by Namespace, which share db.
+	view bool
+	// key is the key the database is encrypted with, if it is.
+	key *seal.Key
+}
+
+// Open opens the SQLite database at path, creating it if needed, and runs
+// any migrations that haven't been applied yet.
+func Open(path string) (*Store, error) {
+	if path == "" {
+		return nil, errors.New("sqlite: no database path given")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+
+	db, err := sql.Open("sqlite3", "file:"+path+"?_busy_timeout=5000&_journal_mode=WAL")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := migrate(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("sqlite: migrating %s: %w", path, err)
+	}
+
+	return &Store{db: db}, nil
+}
+
+func migrate(db *sql.DB) error {
+	var version int
+	if err := db.QueryRow(`PRAGMA user_version`).Scan(&version); err != nil {
+		return err
+	}
+	if version > len(migrations) {
+		return fmt.Errorf("%w: database is at version %d but this build only knows %d", todo.ErrNewerSchema, version, len(migrations))
+	}
+
+	for i := version; i < len(migrations); i++ {
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(migrations[i]); err != nil {
+			tx.Rollback()
+			return err
+		}
+		// PRAGMA doesn't take placeholders.
+		if _, err := tx.Exec(fmt.Sprintf(`PRAGMA user_version = %d`, i+1)); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Add implements todo.Store.
+func (s *Store) Add(item todo.ParsedTodoItem) (todo.ParsedTodoItem, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return item, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`INSERT INTO items (list, todo, data) VALUES (?, '', '{}')`, s.list)
+	if err != nil {
+		return item, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return item, err
+	}
+
+	item.ID = int(id)
+	if err := write(tx, s.key, item); err != nil {
+		return item, err
+	}
+	return item, tx.Commit()
+}
+
+// List implements todo.Store.
+func (s *Store) List() ([]todo.ParsedTodoItem, error) {
+	return s.query(`SELECT data FROM items WHERE list = ? AND deleted_at IS NULL ORDER BY id`, s.list)
+}
+
+// Get implements todo.Store.
+func (s *Store) Get(id int) (todo.ParsedTodoItem, error) {
+	return get(s.db, s.key, s.list, id, false)
+}
+
+// Update implements todo.Store.
+func (s *Store) Update(item todo.ParsedTodoItem) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := get(tx, s.key, s.list, item.ID, false); err != nil {
+		return err
+	}
+	if err := write(tx, s.key, item); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Delete implements todo.Store.
+func (s *Store) Delete(id int) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	item, err := get(tx, s.key, s.list, id, false)
+	if err != nil {
+		return err
+	}
+	item.DeletedAt = time.Now()
+	if err := write(tx, s.key, item); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Trash implements todo.Store.
+func (s *Store) Trash() ([]todo.ParsedTodoItem, error) {
+	return s.query(`SELECT data FROM items WHERE list = ? AND deleted_at IS NOT NULL ORDER BY deleted_at`, s.list)
+}
+
+// Restore implements todo.Store.
+func (s *Store) Restore(id int) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	item, err := get(tx, s.key, s.list, id, true)
+	if err != nil {
+		return err
+	}
+	item.DeletedAt = time.Time{}
+	if err := write(tx, s.key, item); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Purge implements todo.Store.
+func (s *Store) Purge() (int, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM items_fts WHERE docid IN (SELECT id FROM items WHERE list = ? AND deleted_at IS NOT NULL)`, s.list); err != nil {
+		return 0, err
+	}
+	res, err := tx.Exec(`DELETE FROM items WHERE list = ? AND deleted_at IS NOT NULL`, s.list)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(n), tx.Commit()
+}
+
+// Remove implements todo.Remover.
+func (s *Store) Remove(ids ...int) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, id := range ids {
+		if _, err := tx.Exec(`DELETE FROM items_fts WHERE docid IN (SELECT id FROM items WHERE list = ? AND id = ?)`, s.list, id); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`DELETE FROM items WHERE list = ? AND id = ?`, s.list, id); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// SearchWords implements todo.Searcher using the FTS4 index. An encrypted
+// database has no index, so every item is a candidate.
+func (s *Store) SearchWords(terms []string) ([]todo.ParsedTodoItem, error) {
+	if s.key != nil {
+		return s.List()
+	}
+	var query []string
+	for _, term := range terms {
+		// Terms from todo.Tokenize are only letters and digits, so are
+		// safe to use as prefix queries.
+		query = append(query, term+"*")
+	}
+	return s.query(`SELECT items.data FROM items_fts JOIN items ON items.id = items_fts.docid
+		WHERE items_fts MATCH ? AND items.list = ? AND items.deleted_at IS NULL ORDER BY items.id`, strings.Join(query, " "), s.list)
+}
+
+// GetMeta implements todo.MetaStore.
+func (s *Store) GetMeta(key string) ([]byte, error) {
+	var value string
+	err := s.db.QueryRow(`SELECT value FROM meta WHERE list = ? AND key = ?`, s.list, key).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return openValue(s.key, value)
+}
+
+// PutMeta implements todo.MetaStore.
+func (s *Store) PutMeta(key string, value []byte) error {
+	var err error
+	if value == nil {
+		_, err = s.db.Exec(`DELETE FROM meta WHERE list = ? AND key = ?`, s.list, key)
+	} else {
+		var sealed string
+		if sealed, err = sealValue(s.key, value); err != nil {
+			return err
+		}
+		_, err = s.db.Exec(`INSERT OR REPLACE INTO meta (list, key, value) VALUES (?, ?, ?)`, s.list, key, sealed)
+	}
+	return err
+}
+
+// MetaKeys implements todo.MetaStore.
+func (s *Store) MetaKeys() ([]string, error) {
+	return s.column(`SELECT key FROM meta WHERE list = ? ORDER BY key`, s.list)
+}
+
+// Import implements todo.Importer. As IDs are shared by every list, it
+// fails with todo.ErrNamespaceUsed if an item's ID is taken in another one.
+func (s *Store) Import(items, trash []todo.ParsedTodoItem) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := s.importItems(tx, items, trash); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Replace implements todo.Replacer, removing and importing in one
+// transaction.
+func (s *Store) Replace(items, trash []todo.ParsedTodoItem) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM items_fts WHERE docid IN (SELECT id FROM items WHERE list = ?)`, s.list); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM items WHERE list = ?`, s.list); err != nil {
+		return err
+	}
+	if err := s.importItems(tx, items, trash); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// importItems saves items onto the list and trash into the trash in tx, as
+// Import does.
+func (s *Store) importItems(tx *sql.Tx, items, trash []todo.ParsedTodoItem) error {
+	for _, item := range append(items, trash...) {
+		var list string
+		err := tx.QueryRow(`SELECT list FROM items WHERE id = ?`, item.ID).Scan(&list)
+		if err == nil && list != s.list {
+			return fmt.Errorf("%w: %d", todo.ErrNamespaceUsed, item.ID)
+		}
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		if _, err := tx.Exec(`INSERT OR REPLACE INTO items (id, list, todo, data) VALUES (?, ?, ?, '{}')`, item.ID, s.list, item.Todo); err != nil {
+			return err
+		}
+		if err := write(tx, s.key, item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Namespace implements todo.Namespacer.
+func (s *Store) Namespace(name string) (todo.Store, error) {
+	return &Store{db: s.db, list: name, view: true, key: s.key}, nil
+}
+
+// Namespaces implements todo.Namespacer.
+func (s *Store) Namespaces() ([]string, error) {
+	return s.column(`SELECT list FROM items WHERE list != '' UNION SELECT list FROM meta WHERE list != '' ORDER BY 1`)
+}
+
+// DropNamespace implements todo.Namespacer.
+func (s *Store) DropNamespace(name string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, query := range []string{
+		`DELETE FROM items_fts WHERE docid IN (SELECT id FROM items WHERE list = ?)`,
+		`DELETE FROM items WHERE list = ?`,
+		`DELETE FROM meta WHERE list = ?`,
+	} {
+		if _, err := tx.Exec(query, name); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Encrypted implements todo.Encrypter.
+func (s *Store) Encrypted() (bool, error) {
+	var n int
+	err := s.db.QueryRow(`SELECT count(*) FROM sealed`).Scan(&n)
+	return n > 0, err
+}
+
+// UseKey implements todo.Encrypter. Encrypting the database rewrites every
+// item and setting in it, in every list, and then vacuums it so that what
+// they were before isn't left behind in free pages.
+func (s *Store) UseKey(key *seal.Key) error {
+	var stored string
+	err := s.db.QueryRow(`SELECT sample FROM sealed`).Scan(&stored)
+	if err == nil {
+		if _, err := openValue(key, stored); err != nil {
+			return err
+		}
+		s.key = key
+		return nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	sealed, err := sealValue(key, []byte(sample))
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`INSERT INTO sealed (sample) VALUES (?)`, sealed); err != nil {
+		return err
+	}
+	items, err := scan(tx, nil, `SELECT data FROM items`)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		if err := write(tx, key, item); err != nil {
+			return err
+		}
+	}
+	if err := reseal(tx, key); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	s.key = key
+
+	if _, err := s.db.Exec(`VACUUM`); err != nil {
+		return err
+	}
+	_, err = s.db.Exec(`PRAGMA wal_checkpoint(TRUNCATE)`)
+	return err
+}
+
+// reseal encrypts the values of every setting in the database.
+func reseal(tx *sql.Tx, key *seal.Key) error {
+	rows, err := tx.Query(`SELECT list, key, value FROM meta`)
+	if err != nil {
+		return err
+	}
+	type setting struct{ list, key, value string }
+	var settings []setting
+	for rows.Next() {
+		var m setting
+		if err := rows.Scan(&m.list, &m.key, &m.value); err != nil {
+			rows.Close()
+			return err
+		}
+		settings = append(settings, m)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for _, m := range settings {
+		sealed, err := sealValue(key, []byte(m.value))
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`UPDATE meta SET value = ? WHERE list = ? AND key = ?`, sealed, m.list, m.key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close implements todo.Store. Closing a namespace does nothing.
+func (s *Store) Close() error {
+	if s.view {
+		return nil
+	}
+	return s.db.Close()
+}
+
+// querier is the part of *sql.DB and *sql.Tx used for reads.
+type querier interface {
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// get reads the item with the given ID in list, either from the list or
+// from the trash.
+func get(q querier, key *seal.Key, list string, id int, trashed bool) (todo.ParsedTodoItem, error) {
+	query := `SELECT data FROM items WHERE id = ? AND list = ? AND deleted_at IS NULL`
+	where := ""
+	if trashed {
+		query = `SELECT data FROM items WHERE id = ? AND list = ? AND deleted_at IS NOT NULL`
+		where = " in the trash"
+	}
+
+	var data string
+	err := q.QueryRow(query, id, list).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return todo.ParsedTodoItem{}, fmt.Errorf("%w with ID %d%s", todo.ErrNotFound, id, where)
+	}
+	if err != nil {
+		return todo.ParsedTodoItem{}, err
+	}
+	return decode(key, data)
+}
+
+// write saves item over the row with the same ID, keeping the indexed
+// columns and the full-text index in step with the JSON. Given a key, the
+// JSON is sealed with it and only deleted_at is kept in step.
+func write(tx *sql.Tx, key *seal.Key, item todo.ParsedTodoItem) error {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+	if key != nil {
+		sealed, err := sealValue(key, data)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(`UPDATE items SET todo = '', due = NULL, completed = 0, deleted_at = ?, data = ? WHERE id = ?`,
+			timeColumn(item.DeletedAt), sealed, item.ID)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(`DELETE FROM items_fts WHERE docid = ?`, item.ID)
+		return err
+	}
+	_, err = tx.Exec(`UPDATE items SET todo = ?, due = ?, completed = ?, deleted_at = ?, data = ? WHERE id = ?`,
+		item.Todo, timeColumn(item.Due), item.Completed, timeColumn(item.DeletedAt), string(data), item.ID)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`INSERT OR REPLACE INTO items_fts (docid, body) VALUES (?, ?)`, item.ID, item.SearchText())
+	return err
+}
+
+func (s *Store) query(query string, args ...any) ([]todo.ParsedTodoItem, error) {
+	return scan(s.db, s.key, query, args...)
+}
+
+// rower is the part of *sql.DB and *sql.Tx used for queries returning
+// several rows.
+type rower interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+}
+
+// scan runs a query returning the data column of items.
+func scan(q rower, key *seal.Key, query string, args ...any) ([]todo.ParsedTodoItem, error) {
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []todo.ParsedTodoItem
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		item, err := decode(key, data)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// column runs a query returning a single text column.
+func (s *Store) column(query string, args ...any) ([]string, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var values []string
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, rows.Err()
+}
+
+func decode(key *seal.Key, data string) (todo.ParsedTodoItem, error) {
+	var item todo.ParsedTodoItem
+	raw, err := openValue(key, data)
+	if err != nil {
+		return item, err
+	}
+	err = json.Unmarshal(raw, &item)
+	return item, err
+}
+
+// sealValue returns value sealed with key, or as it is without one.
+func sealValue(key *seal.Key, value []byte) (string, error) {
+	if key == nil {
+		return string(value), nil
+	}
+	box, err := key.Seal(value)
+	if err != nil {
+		return "", err
+	}
+	return sealedPrefix + base64.StdEncoding.EncodeToString(box), nil
+}
+
+// openValue returns the value sealed in stored, which is returned as it is if
+// it isn't sealed.
+func openValue(key *seal.Key, stored string) ([]byte, error) {
+	sealed, ok := strings.CutPrefix(stored, sealedPrefix)
+	if !ok {
+		return []byte(stored), nil
+	}
+	if key == nil {
+		return nil, todo.ErrEncrypted
+	}
+	box, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil {
+		return nil, err
+	}
+	return key.Open(box)
+}
+
+// timeColumn formats t for one of the indexed columns. Times are stored in
+// UTC so that sorting the text sorts by time, and the zero time is NULL.
+func timeColumn(t time.Time) any {
+	if t.IsZero() {
+		return nil
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
@@ -0,0 +1,209 @@
+package todo
+
+import (
+	"slices"
+	"sort"
+	"time"
+)
+
+// Stats sums up what happened on a list between Since and Until.
+type Stats struct {
+	Since time.Time
+	Until time.Time
+	// Added and Done count the items added and done in that time, and
+	// Periods the same for each day or week of it, oldest first.
+	Added   int
+	Done    int
+	Periods []StatsPeriod
+	// DoneLate counts the items done after they were due.
+	DoneLate int
+	// MeanTimeToDone and MedianTimeToDone are how long the items done were
+	// on the list first, for those that say when they were added.
+	MeanTimeToDone   time.Duration
+	MedianTimeToDone time.Duration
+	// Open and Overdue count the items still to do at Until, and the ones
+	// of those that are overdue.
+	Open    int
+	Overdue int
+	// Tags and Projects are those of the items added or done, busiest
+	// first.
+	Tags     []StatsCount
+	Projects []StatsCount
+	// Snoozed are the open items that have been snoozed, the most often
+	// first.
+	Snoozed []ParsedTodoItem
+	// Streak is how many days in a row, up to Until, at least one item was
+	// done, and LongestStreak the most there have ever been. A streak isn't
+	// broken until a whole day goes by without one.
+	Streak        int
+	LongestStreak int
+}
+
+// StatsPeriod is how many items were added and done in the day or week
+// starting at Start.
+type StatsPeriod struct {
+	Start time.Time
+	Added int
+	Done  int
+}
+
+// StatsCount is how many items with a tag or in a project were added and
+// done.
+type StatsCount struct {
+	Name  string
+	Added int
+	Done  int
+}
+
+// ComputeStats works out the Stats of items from the start of the day
+// since is in up to until, by day or, if byWeek is set, by week starting on
+// Monday. Items in the trash should be left out of items, and done items
+// moved out of the list by archiving put in for them to count.
+func ComputeStats(items []ParsedTodoItem, since, until time.Time, byWeek bool) Stats {
+	start := startOfDay(since)
+	if byWeek {
+		start = WeekStart(start)
+	}
+	stats := Stats{Since: start, Until: until}
+	step := 1
+	if byWeek {
+		step = 7
+	}
+	for day := start; day.Before(until); day = day.AddDate(0, 0, step) {
+		stats.Periods = append(stats.Periods, StatsPeriod{Start: day})
+	}
+	// period returns the period t falls in, if it is in one.
+	period := func(t time.Time) *StatsPeriod {
+		if t.IsZero() || t.Before(start) || t.After(until) {
+			return nil
+		}
+		i := sort.Search(len(stats.Periods), func(i int) bool { return stats.Periods[i].Start.After(t) }) - 1
+		if i < 0 {
+			return nil
+		}
+		return &stats.Periods[i]
+	}
+
+	tags := map[string]*StatsCount{}
+	projects := map[string]*StatsCount{}
+	count := func(item ParsedTodoItem, added, done int) {
+		for _, tag := range item.Tags {
+			if tags[tag] == nil {
+				tags[tag] = &StatsCount{Name: tag}
+			}
+			tags[tag].Added += added
+			tags[tag].Done += done
+		}
+		if item.Project != "" {
+			if projects[item.Project] == nil {
+				projects[item.Project] = &StatsCount{Name: item.Project}
+			}
+			projects[item.Project].Added += added
+			projects[item.Project].Done += done
+		}
+	}
+
+	var times []time.Duration
+	for _, item := range items {
+		if p := period(item.CreatedAt); p != nil {
+			p.Added++
+			stats.Added++
+			count(item, 1, 0)
+		}
+		if !item.Completed {
+			stats.Open++
+			if item.IsOverdue(until) {
+				stats.Overdue++
+			}
+			if item.Snoozed > 0 {
+				stats.Snoozed = append(stats.Snoozed, item)
+			}
+			continue
+		}
+		if p := period(item.CompletedAt); p != nil {
+			p.Done++
+			stats.Done++
+			count(item, 0, 1)
+			if !item.Due.IsZero() && item.CompletedAt.After(item.DueBy()) {
+				stats.DoneLate++
+			}
+			if !item.CreatedAt.IsZero() && item.CompletedAt.After(item.CreatedAt) {
+				times = append(times, item.CompletedAt.Sub(item.CreatedAt))
+			}
+		}
+	}
+
+	if len(times) > 0 {
+		var total time.Duration
+		for _, d := range times {
+			total += d
+		}
+		stats.MeanTimeToDone = total / time.Duration(len(times))
+		slices.Sort(times)
+		stats.MedianTimeToDone = times[len(times)/2]
+		if len(times)%2 == 0 {
+			stats.MedianTimeToDone = (times[len(times)/2-1] + times[len(times)/2]) / 2
+		}
+	}
+	stats.Streak, stats.LongestStreak = Streaks(items, until)
+	slices.SortStableFunc(stats.Snoozed, func(a, b ParsedTodoItem) int { return b.Snoozed - a.Snoozed })
+	stats.Tags = busiest(tags)
+	stats.Projects = busiest(projects)
+	return stats
+}
+
+// busiest returns counts with the most items added and done first.
+func busiest(counts map[string]*StatsCount) []StatsCount {
+	var result []StatsCount
+	for _, c := range counts {
+		result = append(result, *c)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i].Added+result[i].Done, result[j].Added+result[j].Done
+		if a != b {
+			return a > b
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// Streaks returns how many days in a row up to now at least one of items
+// was done on, and the longest run of such days. Today only has to have
+// had one for the streak to go on once it is over, so a streak running up
+// to yesterday still counts.
+func Streaks(items []ParsedTodoItem, now time.Time) (current, longest int) {
+	days := map[time.Time]bool{}
+	for _, item := range items {
+		if item.Completed && !item.CompletedAt.IsZero() && !item.CompletedAt.After(now) {
+			days[startOfDay(item.CompletedAt)] = true
+		}
+	}
+
+	sorted := make([]time.Time, 0, len(days))
+	for day := range days {
+		sorted = append(sorted, day)
+	}
+	slices.SortFunc(sorted, func(a, b time.Time) int { return a.Compare(b) })
+	run := 0
+	for i, day := range sorted {
+		// Days are compared by date rather than by 24 hours apart, which
+		// they aren't across daylight saving changes.
+		if i > 0 && sorted[i-1].AddDate(0, 0, 1).Equal(day) {
+			run++
+		} else {
+			run = 1
+		}
+		longest = max(longest, run)
+	}
+
+	day := startOfDay(now)
+	if !days[day] {
+		day = day.AddDate(0, 0, -1)
+	}
+	for days[day] {
+		current++
+		day = day.AddDate(0, 0, -1)
+	}
+	return current, longest
+}
@@ -0,0 +1,73 @@
+package todo
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrBadStatus is returned when a status can't be parsed.
+var ErrBadStatus = errors.New("unknown status")
+
+// The statuses an item moves through, which are the columns of the board in
+// the TUI. Items that are done are marked with Completed rather than Status,
+// so StatusDone is never saved.
+const (
+	StatusBacklog = "backlog"
+	StatusDoing   = "doing"
+	StatusDone    = "done"
+)
+
+// Statuses lists every status in the order items move through them.
+var Statuses = []string{StatusBacklog, StatusDoing, StatusDone}
+
+// ParseStatus parses a status name. "todo" and "" are the same as backlog,
+// and "wip" and "started" the same as doing.
+func ParseStatus(s string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "backlog", "todo":
+		return StatusBacklog, nil
+	case "doing", "wip", "started":
+		return StatusDoing, nil
+	case "done":
+		return StatusDone, nil
+	}
+	return "", fmt.Errorf("%w %q, expected backlog, doing or done", ErrBadStatus, s)
+}
+
+// CurrentStatus returns which of Statuses the item is in.
+func (item ParsedTodoItem) CurrentStatus() string {
+	switch {
+	case item.Completed:
+		return StatusDone
+	case item.Status == "":
+		return StatusBacklog
+	}
+	return item.Status
+}
+
+// SetStatus moves the item to status, marking it done at now, or no longer
+// done, as needed. Being done stops the clock if it is running. It reports
+// whether the item has just been done, in which case the caller should add
+// its NextOccurrence if it repeats.
+func (item *ParsedTodoItem) SetStatus(status string, now time.Time) (done bool) {
+	if status == StatusDone {
+		item.Status = ""
+		if item.Completed {
+			return false
+		}
+		item.Completed = true
+		item.CompletedAt = now
+		item.StopWork(now)
+		return true
+	}
+
+	item.Completed = false
+	item.CompletedAt = time.Time{}
+	item.Status = status
+	if status == StatusBacklog {
+		item.Status = ""
+	}
+	return false
+}
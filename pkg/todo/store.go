@@ -0,0 +1,249 @@
+package todo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/buck06191/todo-app/pkg/seal"
+)
+
+// ErrNotFound is returned by a Store when there is no item with the
+// requested ID.
+var ErrNotFound = errors.New("no such todo item")
+
+// ErrNewerSchema is returned when opening a store written by a newer
+// version of todo-app than this one, which might lose what it saved.
+var ErrNewerSchema = errors.New("the store was written by a newer version of todo-app")
+
+// Store is somewhere todo items are saved. Backends are made available by
+// name with Register and opened with Open.
+//
+// Deleting an item moves it to the trash rather than removing it, so it can
+// be restored later. Only Purge removes items for good.
+type Store interface {
+	// Add saves a new item, giving it the next free ID, and returns the
+	// saved item.
+	Add(item ParsedTodoItem) (ParsedTodoItem, error)
+	// List returns every item that isn't in the trash in the order they
+	// were added.
+	List() ([]ParsedTodoItem, error)
+	// Get returns the item with the given ID.
+	Get(id int) (ParsedTodoItem, error)
+	// Update replaces the saved item that has the same ID as item.
+	Update(item ParsedTodoItem) error
+	// Delete moves the item with the given ID to the trash.
+	Delete(id int) error
+
+	// Trash returns the items in the trash.
+	Trash() ([]ParsedTodoItem, error)
+	// Restore moves the item with the given ID out of the trash.
+	Restore(id int) error
+	// Purge permanently removes everything in the trash and returns how
+	// many items were removed.
+	Purge() (int, error)
+
+	// Close releases anything held open by the store.
+	Close() error
+}
+
+// Importer is implemented by stores that can take items with their IDs
+// already set, keeping them as they are. It is used to move a list from one
+// backend to another.
+type Importer interface {
+	// Import saves items onto the list and trash into the trash without
+	// giving them new IDs. Items with the same ID as one already saved
+	// replace it.
+	Import(items, trash []ParsedTodoItem) error
+}
+
+// Remover is implemented by stores that can remove single items for good
+// rather than only by emptying the whole trash. It is used to move items
+// into an archive.
+type Remover interface {
+	// Remove permanently removes the items with the given IDs, whether they
+	// are on the list or in the trash. IDs without an item are skipped.
+	Remove(ids ...int) error
+}
+
+// Replacer is implemented by stores that can swap everything on a list for
+// other items in one write, so that failing part way through leaves the
+// list as it was. It is used to restore a list from a backup.
+type Replacer interface {
+	// Replace removes every item on the list and in the trash, and saves
+	// items onto the list and trash into the trash with their IDs.
+	Replace(items, trash []ParsedTodoItem) error
+}
+
+// ErrCantRemove is returned when removing single items from a store that
+// isn't a Remover.
+var ErrCantRemove = errors.New("this store can't remove single items")
+
+// ErrNoMeta is returned when saving settings to a store that isn't a
+// MetaStore.
+var ErrNoMeta = errors.New("this store can't save settings")
+
+// MetaStore is implemented by stores that can keep settings alongside the
+// items, such as saved filters. Values are JSON documents, saved under a key
+// naming what they are for.
+type MetaStore interface {
+	// GetMeta returns the value saved under key, or nil if there isn't one.
+	GetMeta(key string) ([]byte, error)
+	// PutMeta saves value under key. A nil value deletes the key.
+	PutMeta(key string, value []byte) error
+	// MetaKeys returns every key with a value saved, in sorted order.
+	MetaKeys() ([]string, error)
+}
+
+// LoadMeta reads the JSON value saved under key in store into v, leaving v
+// as it is if there isn't one. It fails with ErrNoMeta if store isn't a
+// MetaStore.
+func LoadMeta(store Store, key string, v any) error {
+	meta, ok := store.(MetaStore)
+	if !ok {
+		return ErrNoMeta
+	}
+	raw, err := meta.GetMeta(key)
+	if err != nil || raw == nil {
+		return err
+	}
+	return json.Unmarshal(raw, v)
+}
+
+// SaveMeta saves v as JSON under key in store. It fails with ErrNoMeta if
+// store isn't a MetaStore.
+func SaveMeta(store Store, key string, v any) error {
+	meta, ok := store.(MetaStore)
+	if !ok {
+		return ErrNoMeta
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return meta.PutMeta(key, raw)
+}
+
+// AllItems returns every item in store, including the trash, by ID.
+func AllItems(store Store) (map[int]ParsedTodoItem, error) {
+	list, err := store.List()
+	if err != nil {
+		return nil, err
+	}
+	trash, err := store.Trash()
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int]ParsedTodoItem, len(list)+len(trash))
+	for _, item := range append(list, trash...) {
+		byID[item.ID] = item
+	}
+	return byID, nil
+}
+
+// ErrEncrypted is returned when reading an encrypted store that hasn't
+// been given its key with UseKey.
+var ErrEncrypted = errors.New("the store is encrypted, and needs its passphrase")
+
+// Encrypter is implemented by stores that can keep what they save
+// encrypted on disk.
+type Encrypter interface {
+	// Encrypted reports whether the store has been encrypted.
+	Encrypted() (bool, error)
+	// UseKey makes the store decrypt what it reads with key, and encrypt
+	// what it saves. Anything saved before the store was encrypted is
+	// encrypted there and then, so UseKey on a store that isn't encrypted
+	// encrypts it. It fails with seal.ErrWrongKey if the store was
+	// encrypted with another key.
+	UseKey(key *seal.Key) error
+}
+
+// Copy copies every item in src, including the trash, into dst. If dst is
+// an Importer the items keep their IDs, otherwise they are added as new
+// items and anything from the trash is skipped. Settings are copied too if
+// both stores are MetaStores, and other lists if both are Namespacers.
+func Copy(dst, src Store) error {
+	if err := copyList(dst, src); err != nil {
+		return err
+	}
+	return copyNamespaces(dst, src)
+}
+
+// copyList copies the items and settings of one list for Copy.
+func copyList(dst, src Store) error {
+	if err := copyMeta(dst, src); err != nil {
+		return err
+	}
+
+	items, err := src.List()
+	if err != nil {
+		return err
+	}
+	trash, err := src.Trash()
+	if err != nil {
+		return err
+	}
+
+	if importer, ok := dst.(Importer); ok {
+		return importer.Import(items, trash)
+	}
+
+	for _, item := range items {
+		if _, err := dst.Add(item); err != nil {
+			return fmt.Errorf("copying item %d: %w", item.ID, err)
+		}
+	}
+	return nil
+}
+
+func copyMeta(dst, src Store) error {
+	from, ok := src.(MetaStore)
+	if !ok {
+		return nil
+	}
+	to, ok := dst.(MetaStore)
+	if !ok {
+		return nil
+	}
+
+	keys, err := from.MetaKeys()
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		value, err := from.GetMeta(key)
+		if err != nil {
+			return err
+		}
+		if err := to.PutMeta(key, value); err != nil {
+			return fmt.Errorf("copying %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// withoutIDs returns items without the ones with any of ids.
+func withoutIDs(items []ParsedTodoItem, ids []int) []ParsedTodoItem {
+	return slices.DeleteFunc(items, func(item ParsedTodoItem) bool {
+		return slices.Contains(ids, item.ID)
+	})
+}
+
+// mergeByID returns items with each of extra either replacing the item with
+// the same ID or, if there isn't one, added to the end.
+func mergeByID(items, extra []ParsedTodoItem) []ParsedTodoItem {
+	index := make(map[int]int, len(items))
+	for i, item := range items {
+		index[item.ID] = i
+	}
+	for _, item := range extra {
+		if i, ok := index[item.ID]; ok {
+			items[i] = item
+			continue
+		}
+		index[item.ID] = len(items)
+		items = append(items, item)
+	}
+	return items
+}
@@ -0,0 +1,262 @@
+package todo
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/buck06191/todo-app/pkg/seal"
+)
+
+// stores are the backends every Store test is run against, each opened
+// empty.
+var stores = []struct {
+	name string
+	open func(t *testing.T) Store
+}{
+	{"memory", func(t *testing.T) Store { return NewMemoryStore() }},
+	{"json", func(t *testing.T) Store { return NewJSONStore(filepath.Join(t.TempDir(), "todos.json")) }},
+}
+
+// forEachStore runs test as a subtest against each of stores.
+func forEachStore(t *testing.T, test func(t *testing.T, store Store)) {
+	for _, s := range stores {
+		t.Run(s.name, func(t *testing.T) {
+			store := s.open(t)
+			t.Cleanup(func() { store.Close() })
+			test(t, store)
+		})
+	}
+}
+
+// ids returns the IDs of items in order.
+func ids(items []ParsedTodoItem) []int {
+	var found []int
+	for _, item := range items {
+		found = append(found, item.ID)
+	}
+	return found
+}
+
+func mustAdd(t *testing.T, store Store, task string) ParsedTodoItem {
+	t.Helper()
+	item, err := store.Add(ParsedTodoItem{Todo: task})
+	if err != nil {
+		t.Fatalf("Add(%q): %v", task, err)
+	}
+	return item
+}
+
+func TestStoreAddGetList(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		list, err := store.List()
+		if err != nil || len(list) != 0 {
+			t.Fatalf("List of a new store = %v, %v, want nothing", list, err)
+		}
+
+		due := time.Date(2025, 3, 1, 9, 30, 0, 0, time.UTC)
+		added, err := store.Add(ParsedTodoItem{Todo: "Buy milk", Due: due, Tags: []string{"shopping"}, Priority: PriorityHigh})
+		if err != nil {
+			t.Fatal(err)
+		}
+		second := mustAdd(t, store, "Walk the dog")
+		if added.ID != 1 || second.ID != 2 {
+			t.Errorf("Add gave IDs %d and %d, want 1 and 2", added.ID, second.ID)
+		}
+
+		got, err := store.Get(added.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Todo != "Buy milk" || !got.Due.Equal(due) || !slices.Equal(got.Tags, []string{"shopping"}) || got.Priority != PriorityHigh {
+			t.Errorf("Get(%d) = %+v, want the item as added", added.ID, got)
+		}
+
+		list, err = store.List()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(ids(list), []int{1, 2}) {
+			t.Errorf("List = %v, want [1 2]", ids(list))
+		}
+
+		if _, err := store.Get(3); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Get of a missing item error = %v, want ErrNotFound", err)
+		}
+	})
+}
+
+func TestStoreUpdate(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		item := mustAdd(t, store, "Buy milk")
+		item.Todo = "Buy oat milk"
+		item.Completed = true
+		if err := store.Update(item); err != nil {
+			t.Fatal(err)
+		}
+		got, err := store.Get(item.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Todo != "Buy oat milk" || !got.Completed {
+			t.Errorf("Get after Update = %+v, want the update", got)
+		}
+
+		if err := store.Update(ParsedTodoItem{ID: 9, Todo: "Nothing"}); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Update of a missing item error = %v, want ErrNotFound", err)
+		}
+	})
+}
+
+func TestStoreTrash(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		first := mustAdd(t, store, "Buy milk")
+		second := mustAdd(t, store, "Walk the dog")
+		third := mustAdd(t, store, "Call the bank")
+
+		if err := store.Delete(second.ID); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.Delete(second.ID); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Delete of an item in the trash error = %v, want ErrNotFound", err)
+		}
+		list, _ := store.List()
+		if !slices.Equal(ids(list), []int{first.ID, third.ID}) {
+			t.Errorf("List after Delete = %v, want [%d %d]", ids(list), first.ID, third.ID)
+		}
+		trash, err := store.Trash()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(trash) != 1 || trash[0].ID != second.ID || trash[0].DeletedAt.IsZero() {
+			t.Errorf("Trash = %+v, want item %d with DeletedAt set", trash, second.ID)
+		}
+
+		if err := store.Restore(second.ID); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.Restore(second.ID); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Restore of an item not in the trash error = %v, want ErrNotFound", err)
+		}
+		got, err := store.Get(second.ID)
+		if err != nil || !got.DeletedAt.IsZero() {
+			t.Errorf("Get after Restore = %+v, %v, want the item back", got, err)
+		}
+
+		store.Delete(first.ID)
+		store.Delete(third.ID)
+		n, err := store.Purge()
+		if err != nil || n != 2 {
+			t.Errorf("Purge = %d, %v, want 2", n, err)
+		}
+		if trash, _ := store.Trash(); len(trash) != 0 {
+			t.Errorf("Trash after Purge = %v, want nothing", ids(trash))
+		}
+		if next := mustAdd(t, store, "Water the plants"); next.ID != 4 {
+			t.Errorf("Add after Purge gave ID %d, want 4 as IDs aren't used again", next.ID)
+		}
+	})
+}
+
+func TestStoreMeta(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		var got map[string]int
+		if err := LoadMeta(store, "counts", &got); err != nil || got != nil {
+			t.Fatalf("LoadMeta of a missing key = %v, %v, want nothing", got, err)
+		}
+		if err := SaveMeta(store, "counts", map[string]int{"a": 1}); err != nil {
+			t.Fatal(err)
+		}
+		if err := LoadMeta(store, "counts", &got); err != nil || got["a"] != 1 {
+			t.Errorf("LoadMeta = %v, %v, want what was saved", got, err)
+		}
+	})
+}
+
+func TestJSONStoreKeepsItems(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todos.json")
+	store := NewJSONStore(path)
+	mustAdd(t, store, "Buy milk")
+	mustAdd(t, store, "Walk the dog")
+	store.Delete(1)
+
+	reopened := NewJSONStore(path)
+	list, err := reopened.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(ids(list), []int{2}) {
+		t.Errorf("List after reopening = %v, want [2]", ids(list))
+	}
+	if trash, _ := reopened.Trash(); !slices.Equal(ids(trash), []int{1}) {
+		t.Errorf("Trash after reopening = %v, want [1]", ids(trash))
+	}
+	if next := mustAdd(t, reopened, "Call the bank"); next.ID != 3 {
+		t.Errorf("Add after reopening gave ID %d, want 3", next.ID)
+	}
+}
+
+func TestJSONStoreSealed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todos.json")
+	key := func(secret string) *seal.Key {
+		t.Helper()
+		k, err := seal.NewKey([]byte(secret), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return k
+	}
+	store := NewJSONStore(path)
+	mustAdd(t, store, "Buy milk")
+	work, err := store.Namespace("work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mustAdd(t, work, "Write the invoice")
+	if err := store.UseKey(key("correct horse")); err != nil {
+		t.Fatal(err)
+	}
+	mustAdd(t, store, "Walk the dog")
+
+	if encrypted, err := NewJSONStore(path).Encrypted(); err != nil || !encrypted {
+		t.Errorf("Encrypted after UseKey = %v, %v, want true", encrypted, err)
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(raw, []byte("milk")) || bytes.Contains(raw, []byte("dog")) {
+		t.Errorf("the store file has the items in the clear:\n%s", raw)
+	}
+
+	reopened := NewJSONStore(path)
+	if _, err := reopened.List(); !errors.Is(err, ErrEncrypted) {
+		t.Errorf("List without the key error = %v, want ErrEncrypted", err)
+	}
+	if err := reopened.UseKey(key("wrong horse")); !errors.Is(err, seal.ErrWrongKey) {
+		t.Errorf("UseKey with the wrong key error = %v, want seal.ErrWrongKey", err)
+	}
+
+	reopened = NewJSONStore(path)
+	if err := reopened.UseKey(key("correct horse")); err != nil {
+		t.Fatal(err)
+	}
+	list, err := reopened.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(ids(list), []int{1, 2}) || list[0].Todo != "Buy milk" {
+		t.Errorf("List with the key = %+v, want both items back", list)
+	}
+	work, err = reopened.Namespace("work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list, err := work.List(); err != nil || len(list) != 1 || list[0].Todo != "Write the invoice" {
+		t.Errorf("List of the other namespace = %+v, %v, want its item back", list, err)
+	}
+}
@@ -0,0 +1,82 @@
+package todo
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrParentCycle is returned when making an item a subtask would make it a
+// subtask of itself.
+var ErrParentCycle = errors.New("an item can't be a subtask of itself")
+
+// Progress counts how many of an item's subtasks are done.
+type Progress struct {
+	Done  int
+	Total int
+}
+
+// SubtaskProgress returns the progress of every item in items that has
+// subtasks, keyed by the parent's ID. Only direct subtasks are counted.
+func SubtaskProgress(items []ParsedTodoItem) map[int]Progress {
+	progress := map[int]Progress{}
+	for _, item := range items {
+		if item.Parent == 0 {
+			continue
+		}
+		p := progress[item.Parent]
+		p.Total++
+		if item.Completed {
+			p.Done++
+		}
+		progress[item.Parent] = p
+	}
+	return progress
+}
+
+// Descendants returns the subtasks of the item with the given ID, their
+// subtasks and so on, parents before children.
+func Descendants(items []ParsedTodoItem, id int) []ParsedTodoItem {
+	var found []ParsedTodoItem
+	queue := []int{id}
+	seen := map[int]bool{id: true}
+	for len(queue) > 0 {
+		parent := queue[0]
+		queue = queue[1:]
+		for _, item := range items {
+			if item.Parent == parent && !seen[item.ID] {
+				seen[item.ID] = true
+				found = append(found, item)
+				queue = append(queue, item.ID)
+			}
+		}
+	}
+	return found
+}
+
+// CheckParent checks that the item with the given ID can be made a subtask
+// of parent: the parent must be in items and mustn't already be below the
+// item. Use an ID of 0 for an item that hasn't been added yet.
+func CheckParent(items []ParsedTodoItem, id, parent int) error {
+	if parent == 0 {
+		return nil
+	}
+	if parent == id {
+		return ErrParentCycle
+	}
+
+	byID := map[int]ParsedTodoItem{}
+	for _, item := range items {
+		byID[item.ID] = item
+	}
+	if _, ok := byID[parent]; !ok {
+		return fmt.Errorf("%w with ID %d to be the parent", ErrNotFound, parent)
+	}
+
+	for p, steps := parent, 0; p != 0 && steps <= len(items); steps++ {
+		if p == id {
+			return fmt.Errorf("%w: %d is already below %d", ErrParentCycle, parent, id)
+		}
+		p = byID[p].Parent
+	}
+	return nil
+}
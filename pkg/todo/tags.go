@@ -0,0 +1,149 @@
+package todo
+
+import (
+	"sort"
+	"strings"
+)
+
+// NormalizeTag returns tag in the form it is saved in: lower case without a
+// leading '#'.
+func NormalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+}
+
+// NormalizeContext returns a context in the form it is saved in: lower case
+// without a leading '@'.
+func NormalizeContext(context string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(context), "@"))
+}
+
+// ExtractTags pulls inline `#tag` words out of text, returning the text
+// without them and the tags found.
+func ExtractTags(text string) (string, []string) {
+	return extractPrefixed(text, '#', NormalizeTag)
+}
+
+// ExtractContexts pulls inline `@context` words out of text, returning the
+// text without them and the contexts found.
+func ExtractContexts(text string) (string, []string) {
+	return extractPrefixed(text, '@', NormalizeContext)
+}
+
+// ExtractProject pulls an inline `+project` word out of text, returning the
+// text without it and the project. If there are several the last one wins.
+func ExtractProject(text string) (string, string) {
+	text, projects := extractPrefixed(text, '+', strings.TrimSpace)
+	if len(projects) == 0 {
+		return text, ""
+	}
+	return text, strings.TrimPrefix(projects[len(projects)-1], "+")
+}
+
+func extractPrefixed(text string, prefix byte, normalize func(string) string) (string, []string) {
+	var words, found []string
+	for _, word := range strings.Fields(text) {
+		if len(word) > 1 && word[0] == prefix {
+			found = append(found, normalize(word))
+			continue
+		}
+		words = append(words, word)
+	}
+	return strings.Join(words, " "), found
+}
+
+// AddTags returns tags with each of extra added unless it is already there.
+// Tags are normalized on the way in.
+func AddTags(tags []string, extra ...string) []string {
+	for _, tag := range extra {
+		tag = NormalizeTag(tag)
+		if tag != "" && !containsTag(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// RemoveTags returns tags without any of remove.
+func RemoveTags(tags []string, remove ...string) []string {
+	return removeNormalized(tags, remove, NormalizeTag)
+}
+
+// RemoveContexts returns contexts without any of remove.
+func RemoveContexts(contexts []string, remove ...string) []string {
+	return removeNormalized(contexts, remove, NormalizeContext)
+}
+
+func removeNormalized(words, remove []string, normalize func(string) string) []string {
+	var kept []string
+	for _, word := range words {
+		drop := false
+		for _, r := range remove {
+			if normalize(r) == word {
+				drop = true
+			}
+		}
+		if !drop {
+			kept = append(kept, word)
+		}
+	}
+	return kept
+}
+
+// AddContexts returns contexts with each of extra added unless it is
+// already there. Contexts are normalized on the way in.
+func AddContexts(contexts []string, extra ...string) []string {
+	for _, context := range extra {
+		context = NormalizeContext(context)
+		if context != "" && !containsTag(contexts, context) {
+			contexts = append(contexts, context)
+		}
+	}
+	return contexts
+}
+
+// HasContext reports whether the item has the given `@context`.
+func (item ParsedTodoItem) HasContext(context string) bool {
+	return containsTag(item.Contexts, NormalizeContext(context))
+}
+
+// HasTag reports whether the item is tagged with tag.
+func (item ParsedTodoItem) HasTag(tag string) bool {
+	return containsTag(item.Tags, NormalizeTag(tag))
+}
+
+func containsTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// TagCount is the number of items with a tag.
+type TagCount struct {
+	Tag   string
+	Count int
+}
+
+// CountTags counts how many of items have each tag, most used first.
+func CountTags(items []ParsedTodoItem) []TagCount {
+	counts := map[string]int{}
+	for _, item := range items {
+		for _, tag := range item.Tags {
+			counts[tag]++
+		}
+	}
+
+	var result []TagCount
+	for tag, n := range counts {
+		result = append(result, TagCount{Tag: tag, Count: n})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Tag < result[j].Tag
+	})
+	return result
+}
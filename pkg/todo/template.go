@@ -0,0 +1,153 @@
+package todo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+var (
+	// ErrBadTemplate is returned when a template can't be saved.
+	ErrBadTemplate = errors.New("bad template")
+	// ErrNoTemplate is returned when there is no saved template with a
+	// name.
+	ErrNoTemplate = errors.New("no such template")
+)
+
+// templatesKey is the MetaStore key templates are kept under, as a JSON
+// object of name to template.
+const templatesKey = "templates"
+
+// Template holds fields to fill in for items added with it, as add -template
+// does, so that combinations used often don't have to be given each time.
+// The fields are as in TodoItem, and are read when an item is added, so a
+// Due such as "tomorrow" is the day after the item is added. Subtasks are
+// added under each item as subtasks of it.
+type Template struct {
+	Due      string   `json:"due,omitempty"`
+	Priority string   `json:"priority,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	Project  string   `json:"project,omitempty"`
+	Contexts []string `json:"contexts,omitempty"`
+	Repeat   string   `json:"repeat,omitempty"`
+	Remind   string   `json:"remind,omitempty"`
+	Estimate string   `json:"estimate,omitempty"`
+	Notes    string   `json:"notes,omitempty"`
+	Subtasks []string `json:"subtasks,omitempty"`
+}
+
+// Apply returns item with the fields it leaves empty filled in from t. Tags
+// and contexts are added to those item has.
+func (t Template) Apply(item TodoItem) TodoItem {
+	fill := func(field *string, value string) {
+		if *field == "" {
+			*field = value
+		}
+	}
+	fill(&item.Due, t.Due)
+	fill(&item.Priority, t.Priority)
+	fill(&item.Project, t.Project)
+	fill(&item.Repeat, t.Repeat)
+	fill(&item.Remind, t.Remind)
+	fill(&item.Estimate, t.Estimate)
+	fill(&item.Notes, t.Notes)
+	item.Tags = merge(t.Tags, item.Tags)
+	item.Contexts = merge(t.Contexts, item.Contexts)
+	return item
+}
+
+// merge returns the words of a followed by those of b that aren't in a.
+func merge(a, b []string) []string {
+	words := slices.Clone(a)
+	for _, w := range b {
+		if !slices.Contains(words, w) {
+			words = append(words, w)
+		}
+	}
+	return words
+}
+
+// Templates returns the templates saved in store, by name. Stores that
+// aren't MetaStores have none.
+func Templates(store Store) (map[string]Template, error) {
+	templates := map[string]Template{}
+	meta, ok := store.(MetaStore)
+	if !ok {
+		return templates, nil
+	}
+
+	raw, err := meta.GetMeta(templatesKey)
+	if err != nil || raw == nil {
+		return templates, err
+	}
+	if err := json.Unmarshal(raw, &templates); err != nil {
+		return nil, fmt.Errorf("reading templates: %w", err)
+	}
+	return templates, nil
+}
+
+// SavedTemplate returns the template saved in store as name.
+func SavedTemplate(store Store, name string) (Template, error) {
+	templates, err := Templates(store)
+	if err != nil {
+		return Template{}, err
+	}
+	t, ok := templates[name]
+	if !ok {
+		return Template{}, fmt.Errorf("%w called %q", ErrNoTemplate, name)
+	}
+	return t, nil
+}
+
+// SaveTemplate saves t in store as name, replacing any template already
+// saved with that name. Its fields have to be ones an item can have. Names
+// are made of letters, digits, - and _.
+func SaveTemplate(store Store, name string, t Template) error {
+	if !validFilterName(name) {
+		return fmt.Errorf("%w: %q isn't a valid name, use letters, digits, - and _", ErrBadTemplate, name)
+	}
+	if _, err := ParseItem(t.Apply(TodoItem{Todo: name})); err != nil {
+		return fmt.Errorf("%w: %w", ErrBadTemplate, err)
+	}
+	for _, task := range t.Subtasks {
+		if _, err := ParseItem(TodoItem{Todo: task}); err != nil {
+			return fmt.Errorf("%w: subtask %q: %w", ErrBadTemplate, task, err)
+		}
+	}
+	return updateTemplates(store, func(templates map[string]Template) error {
+		templates[name] = t
+		return nil
+	})
+}
+
+// DeleteTemplate removes the template saved in store as name.
+func DeleteTemplate(store Store, name string) error {
+	return updateTemplates(store, func(templates map[string]Template) error {
+		if _, ok := templates[name]; !ok {
+			return fmt.Errorf("%w called %q", ErrNoTemplate, name)
+		}
+		delete(templates, name)
+		return nil
+	})
+}
+
+func updateTemplates(store Store, fn func(templates map[string]Template) error) error {
+	meta, ok := store.(MetaStore)
+	if !ok {
+		return ErrNoMeta
+	}
+	templates, err := Templates(store)
+	if err != nil {
+		return err
+	}
+	if err := fn(templates); err != nil {
+		return err
+	}
+
+	raw, err := json.Marshal(templates)
+	if err != nil {
+		return err
+	}
+	return meta.PutMeta(templatesKey, raw)
+}
@@ -0,0 +1,191 @@
+package todo
+
+import (
+	"cmp"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrStarted is returned when starting work on an item that is already
+	// being worked on.
+	ErrStarted = errors.New("already started")
+	// ErrNotStarted is returned when stopping work on an item that isn't
+	// being worked on.
+	ErrNotStarted = errors.New("not started")
+)
+
+// Interval is a stretch of time an item was worked on. End is zero while it
+// is still being worked on.
+type Interval struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end,omitzero"`
+}
+
+// Running reports whether the item is being worked on now, having been
+// started and not yet stopped.
+func (item ParsedTodoItem) Running() bool {
+	return len(item.Worked) > 0 && item.Worked[len(item.Worked)-1].End.IsZero()
+}
+
+// StartWork starts the clock on the item at now.
+func (item *ParsedTodoItem) StartWork(now time.Time) error {
+	if item.Running() {
+		return ErrStarted
+	}
+	item.Worked = append(item.Worked, Interval{Start: now})
+	return nil
+}
+
+// StopWork stops the clock on the item at now, returning how long it ran
+// for this time.
+func (item *ParsedTodoItem) StopWork(now time.Time) (time.Duration, error) {
+	if !item.Running() {
+		return 0, ErrNotStarted
+	}
+	last := &item.Worked[len(item.Worked)-1]
+	last.End = now
+	if now.Before(last.Start) {
+		last.End = last.Start
+	}
+	return last.End.Sub(last.Start), nil
+}
+
+// LogWork records that the item was worked on for d up to end, as when the
+// clock wasn't started at the time.
+func (item *ParsedTodoItem) LogWork(d time.Duration, end time.Time) {
+	item.Worked = append(item.Worked, Interval{Start: end.Add(-d), End: end})
+	slices.SortStableFunc(item.Worked, func(a, b Interval) int {
+		// The running interval stays last.
+		switch {
+		case a.End.IsZero() && !b.End.IsZero():
+			return 1
+		case b.End.IsZero() && !a.End.IsZero():
+			return -1
+		}
+		return a.Start.Compare(b.Start)
+	})
+}
+
+// Spent returns how long the item has been worked on, counting the clock
+// running now up to now.
+func (item ParsedTodoItem) Spent(now time.Time) time.Duration {
+	var spent time.Duration
+	for _, i := range item.Worked {
+		end := i.End
+		if end.IsZero() {
+			end = now
+		}
+		if end.After(i.Start) {
+			spent += end.Sub(i.Start)
+		}
+	}
+	return spent
+}
+
+// ParseEstimate checks an estimate of how long an item will take, such as
+// 2h, 45m or 1d, returning it as it is saved, or "" for none.
+func ParseEstimate(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", nil
+	}
+	d, err := ParseDuration(s)
+	if err != nil {
+		return "", err
+	}
+	if d == 0 {
+		return "", nil
+	}
+	return formatDuration(d), nil
+}
+
+// EstimateLength returns how long the item is estimated to take, or 0 if
+// it hasn't been estimated.
+func (item ParsedTodoItem) EstimateLength() time.Duration {
+	d, err := ParseDuration(item.Estimate)
+	if err != nil {
+		return 0
+	}
+	return d
+}
+
+// Remaining returns how much of the item's estimate is left after the time
+// spent on it up to now, which is none once it is done or has taken longer.
+func (item ParsedTodoItem) Remaining(now time.Time) time.Duration {
+	if item.Completed {
+		return 0
+	}
+	return max(item.EstimateLength()-item.Spent(now), 0)
+}
+
+// TimesheetDay is the time spent on each project on one day.
+type TimesheetDay struct {
+	// Date is midnight at the start of the day, in Location.
+	Date     time.Time
+	Projects []ProjectTime
+	Total    time.Duration
+}
+
+// ProjectTime is the time spent on the items in a project, with "" for
+// items without one.
+type ProjectTime struct {
+	Project string
+	Spent   time.Duration
+}
+
+// Timesheet returns the time spent on items each day from the start of the
+// day from is in up to to, by project, busiest first. Time worked across
+// midnight is split between the days, and days without any are left out.
+// Time still being worked counts up to now.
+func Timesheet(items []ParsedTodoItem, from, to, now time.Time) []TimesheetDay {
+	start := startOfDay(from)
+	byDay := map[time.Time]map[string]time.Duration{}
+	for _, item := range items {
+		for _, i := range item.Worked {
+			end := i.End
+			if end.IsZero() {
+				end = now
+			}
+			begin := i.Start
+			if begin.Before(start) {
+				begin = start
+			}
+			if end.After(to) {
+				end = to
+			}
+			for begin.Before(end) {
+				day := startOfDay(begin)
+				next := day.AddDate(0, 0, 1)
+				if next.After(end) {
+					next = end
+				}
+				if byDay[day] == nil {
+					byDay[day] = map[string]time.Duration{}
+				}
+				byDay[day][item.Project] += next.Sub(begin)
+				begin = next
+			}
+		}
+	}
+
+	var days []TimesheetDay
+	for date, projects := range byDay {
+		day := TimesheetDay{Date: date}
+		for project, spent := range projects {
+			day.Projects = append(day.Projects, ProjectTime{Project: project, Spent: spent})
+			day.Total += spent
+		}
+		slices.SortFunc(day.Projects, func(a, b ProjectTime) int {
+			if c := cmp.Compare(b.Spent, a.Spent); c != 0 {
+				return c
+			}
+			return cmp.Compare(a.Project, b.Project)
+		})
+		days = append(days, day)
+	}
+	slices.SortFunc(days, func(a, b TimesheetDay) int { return a.Date.Compare(b.Date) })
+	return days
+}
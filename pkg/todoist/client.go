@@ -0,0 +1,182 @@
+package todoist
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultURL is the address of the Todoist REST API.
+const DefaultURL = "https://api.todoist.com/rest/v2"
+
+// timeout is how long a request to Todoist can take.
+const timeout = 30 * time.Second
+
+// errNotFound is returned for a task or project Todoist doesn't have, or
+// no longer lists, as happens to tasks once they are done.
+var errNotFound = errors.New("not found on Todoist")
+
+// Client talks to the Todoist REST API with one user's API token.
+type Client struct {
+	url, token string
+	http       *http.Client
+}
+
+// NewClient returns a Client for the API at url, normally DefaultURL,
+// using token, the API token from Todoist's integration settings.
+func NewClient(url, token string) *Client {
+	return &Client{url: strings.TrimSuffix(url, "/"), token: token, http: &http.Client{Timeout: timeout}}
+}
+
+// Project is a Todoist project.
+type Project struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	IsInbox bool   `json:"is_inbox_project,omitempty"`
+}
+
+// Task is a Todoist task, with the fields that are kept in step with an
+// item.
+type Task struct {
+	ID          string   `json:"id"`
+	Content     string   `json:"content"`
+	Description string   `json:"description"`
+	ProjectID   string   `json:"project_id"`
+	Labels      []string `json:"labels"`
+	// Priority goes from 1, normal, to 4, urgent.
+	Priority    int       `json:"priority"`
+	Due         *Due      `json:"due"`
+	IsCompleted bool      `json:"is_completed"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Due is when a task is due. Datetime is only set for tasks due at a time.
+type Due struct {
+	Date     string `json:"date"`
+	Datetime string `json:"datetime,omitempty"`
+	String   string `json:"string,omitempty"`
+}
+
+// taskFields are the fields sent to create or update a task. Due dates are
+// cleared with a DueString of "no date".
+type taskFields struct {
+	Content     string   `json:"content"`
+	Description string   `json:"description"`
+	ProjectID   string   `json:"project_id,omitempty"`
+	Labels      []string `json:"labels"`
+	Priority    int      `json:"priority"`
+	DueDate     string   `json:"due_date,omitempty"`
+	DueDatetime string   `json:"due_datetime,omitempty"`
+	DueString   string   `json:"due_string,omitempty"`
+}
+
+// Projects returns every project.
+func (c *Client) Projects() ([]Project, error) {
+	var projects []Project
+	err := c.do(http.MethodGet, "/projects", nil, &projects)
+	return projects, err
+}
+
+// AddProject makes a project called name.
+func (c *Client) AddProject(name string) (Project, error) {
+	var p Project
+	err := c.do(http.MethodPost, "/projects", map[string]string{"name": name}, &p)
+	return p, err
+}
+
+// Tasks returns every task that isn't done.
+func (c *Client) Tasks() ([]Task, error) {
+	var tasks []Task
+	err := c.do(http.MethodGet, "/tasks", nil, &tasks)
+	return tasks, err
+}
+
+// Task returns the task with id, failing with errNotFound if it has been
+// deleted or Todoist otherwise no longer has it to give.
+func (c *Client) Task(id string) (Task, error) {
+	var t Task
+	err := c.do(http.MethodGet, "/tasks/"+id, nil, &t)
+	return t, err
+}
+
+// AddTask creates a task.
+func (c *Client) AddTask(args taskFields) (Task, error) {
+	var t Task
+	err := c.do(http.MethodPost, "/tasks", args, &t)
+	return t, err
+}
+
+// UpdateTask changes the task with id. Todoist doesn't move tasks between
+// projects this way, so args.ProjectID isn't sent.
+func (c *Client) UpdateTask(id string, args taskFields) (Task, error) {
+	args.ProjectID = ""
+	var t Task
+	err := c.do(http.MethodPost, "/tasks/"+id, args, &t)
+	return t, err
+}
+
+// Close marks the task with id done, and Reopen marks it not done.
+func (c *Client) Close(id string) error {
+	return c.do(http.MethodPost, "/tasks/"+id+"/close", nil, nil)
+}
+
+func (c *Client) Reopen(id string) error {
+	return c.do(http.MethodPost, "/tasks/"+id+"/reopen", nil, nil)
+}
+
+// DeleteTask deletes the task with id. One that has already gone is no
+// error.
+func (c *Client) DeleteTask(id string) error {
+	err := c.do(http.MethodDelete, "/tasks/"+id, nil, nil)
+	if errors.Is(err, errNotFound) {
+		return nil
+	}
+	return err
+}
+
+// do makes a request with body sent as JSON, if it isn't nil, and reads a
+// JSON answer into out, if it isn't nil.
+func (c *Client) do(method, path string, body, out any) error {
+	var r io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		r = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, c.url+path, r)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return fmt.Errorf("%s %s: %w", method, path, errNotFound)
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return fmt.Errorf("Todoist: %s, check the API token", resp.Status)
+	case resp.StatusCode/100 != 2:
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("Todoist: %s %s: %s %s", method, path, resp.Status, bytes.TrimSpace(msg))
+	case out == nil:
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("reading the answer to %s %s from Todoist: %w", method, path, err)
+	}
+	return nil
+}
@@ -0,0 +1,485 @@
+// Package todoist keeps a todo list in step with a Todoist account through
+// its REST API, as used by `todo-app sync todoist`.
+//
+// An item and its task are kept in step in the fields both have: the text
+// and notes, the project, the tags as labels, the priority, the due date
+// and whether it is done. The list remembers those fields as they were
+// when they last synced, to tell which side has changed since, and merges
+// changes made on both as todosync.Merge does.
+//
+// Todoist stops listing tasks once they are done, and a task that is done
+// can't then be told apart from one that has been deleted, so both are
+// marked done on the list and no longer synced.
+package todoist
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/buck06191/todo-app/pkg/todo"
+	"github.com/buck06191/todo-app/pkg/todosync"
+)
+
+// stateKey is the meta key the list keeps its State under.
+const stateKey = "todoist"
+
+// State is what a list remembers about syncing with Todoist.
+type State struct {
+	// Token is the API token, if it is remembered.
+	Token  string    `json:"token,omitempty"`
+	Synced time.Time `json:"synced,omitzero"`
+	Tasks  []Synced  `json:"tasks,omitempty"`
+}
+
+// Synced is an item kept in step with a task.
+type Synced struct {
+	ID   int    `json:"id"`
+	Task string `json:"task"`
+	// Base holds the fields kept in step as they were when they last
+	// synced.
+	Base todo.ParsedTodoItem `json:"base"`
+}
+
+// LoadState returns the Todoist state of the list in store, which is empty
+// if it has never been synced.
+func LoadState(store todo.Store) (State, error) {
+	var state State
+	err := todo.LoadMeta(store, stateKey, &state)
+	return state, err
+}
+
+// SaveState saves the Todoist state of the list in store.
+func SaveState(store todo.Store, state State) error {
+	return todo.SaveMeta(store, stateKey, state)
+}
+
+// Action is what a Change does.
+type Action string
+
+const (
+	AddHere     Action = "add here"
+	UpdateHere  Action = "update here"
+	AddThere    Action = "add to Todoist"
+	UpdateThere Action = "update on Todoist"
+	DeleteThere Action = "delete from Todoist"
+)
+
+// Change is one of the changes a sync makes.
+type Change struct {
+	Action Action
+	// ID is the item on the list, or 0 for one still to be added, and Task
+	// the task on Todoist, or empty for one still to be added.
+	ID   int
+	Task string
+	// Item holds the fields kept in step as they are to be afterwards.
+	Item todo.ParsedTodoItem
+	// Conflicts names the fields changed to different values on both
+	// sides, for which the list's value is kept.
+	Conflicts []string
+
+	// gone is set for an item whose task Todoist no longer has, so it is
+	// no longer synced.
+	gone bool
+}
+
+func (c Change) String() string {
+	s := string(c.Action) + ": "
+	if c.ID != 0 {
+		s += fmt.Sprintf("%d ", c.ID)
+	}
+	s += c.Item.Todo
+	if len(c.Conflicts) > 0 {
+		s += " (keeping the values here for " + strings.Join(c.Conflicts, ", ") + ")"
+	}
+	return s
+}
+
+// Sync works out the changes that bring the list in store and the Todoist
+// account c is for into step, and unless dryRun is set makes them, saving
+// state as it goes. It returns the changes either way.
+//
+// A field changed on only one side since the last sync is taken from that
+// side, and one changed on both keeps the list's value. An item deleted
+// here is deleted on Todoist unless its task has been changed since. The
+// first sync keeps the items of both, leaving out the ones already done
+// here.
+func Sync(store todo.Store, c *Client, state *State, dryRun bool) ([]Change, error) {
+	projects, err := c.Projects()
+	if err != nil {
+		return nil, err
+	}
+	names := map[string]string{}
+	for _, p := range projects {
+		if !p.IsInbox {
+			names[p.ID] = p.Name
+		}
+	}
+	listed, err := c.Tasks()
+	if err != nil {
+		return nil, err
+	}
+	tasks := map[string]Task{}
+	for _, t := range listed {
+		tasks[t.ID] = t
+	}
+	current, err := todo.AllItems(store)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []Change
+	synced := map[int]bool{}
+	for _, t := range state.Tasks {
+		synced[t.ID] = true
+		local, here := current[t.ID]
+		alive := here && local.DeletedAt.IsZero()
+		remote, there := tasks[t.Task]
+		if !there {
+			// Tasks that are done aren't listed any more.
+			remote, err = c.Task(t.Task)
+			if err != nil && !errors.Is(err, errNotFound) {
+				return nil, err
+			}
+			there = err == nil
+			tasks[t.Task] = remote
+		}
+		theirs := fromTask(remote, names)
+		changedHere := !alive || !same(local, t.Base)
+		changedThere := !there || !same(theirs, t.Base)
+		change := Change{ID: t.ID, Task: t.Task}
+
+		switch {
+		case !there && !alive:
+			change.gone = true
+			changes = append(changes, change)
+			continue
+		case !changedHere && !changedThere:
+			continue
+		case !there:
+			change.Action, change.Item, change.gone = UpdateHere, kept(local), true
+			change.Item.Completed = true
+			if local.Completed {
+				change.Action = ""
+			}
+		case !alive && !changedThere:
+			change.Action, change.Item = DeleteThere, t.Base
+		case !alive || !changedHere:
+			// Changed on Todoist, and perhaps deleted here, in which case
+			// the change brings it back.
+			change.Action, change.Item = UpdateHere, theirs
+			if !here {
+				change.Action, change.ID = AddHere, 0
+			}
+		case !changedThere:
+			change.Action, change.Item = UpdateThere, kept(local)
+		default:
+			merged, fields := todosync.Merge(t.Base, kept(local), theirs)
+			if !same(merged, local) {
+				changes = append(changes, Change{Action: UpdateHere, ID: t.ID, Task: t.Task, Item: merged})
+			}
+			change.Action, change.Item, change.Conflicts = UpdateThere, merged, fields
+			if same(merged, theirs) {
+				change.Action = ""
+			}
+		}
+		changes = append(changes, change)
+	}
+
+	known := map[string]bool{}
+	for _, t := range state.Tasks {
+		known[t.Task] = true
+	}
+	for _, t := range listed {
+		if !known[t.ID] {
+			changes = append(changes, Change{Action: AddHere, Task: t.ID, Item: fromTask(t, names)})
+		}
+	}
+	for _, item := range sorted(current) {
+		if !synced[item.ID] && item.DeletedAt.IsZero() && !item.Completed {
+			changes = append(changes, Change{Action: AddThere, ID: item.ID, Item: kept(item)})
+		}
+	}
+
+	if dryRun {
+		return shown(changes), nil
+	}
+	s := syncer{store: store, c: c, state: state, tasks: tasks, projects: map[string]string{}}
+	for _, p := range projects {
+		if p.IsInbox {
+			s.inbox = p.ID
+		} else {
+			s.projects[strings.ToLower(p.Name)] = p.ID
+		}
+	}
+	for i := range changes {
+		if err := s.apply(&changes[i]); err != nil {
+			return shown(changes[:i]), err
+		}
+	}
+	state.Synced = time.Now()
+	return shown(changes), SaveState(store, *state)
+}
+
+// shown returns the changes that change something, leaving out the ones
+// that only stop an item being synced.
+func shown(changes []Change) []Change {
+	var out []Change
+	for _, c := range changes {
+		if c.Action != "" {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// syncer makes the changes of a sync.
+type syncer struct {
+	store todo.Store
+	c     *Client
+	state *State
+	// tasks are the tasks on Todoist by ID.
+	tasks map[string]Task
+	// projects are the IDs of the projects by lower case name, and inbox
+	// that of the inbox, where tasks without a project go.
+	projects map[string]string
+	inbox    string
+}
+
+func (s *syncer) apply(change *Change) error {
+	want := change.Item
+	// The task an item is synced with, if it has been synced before.
+	synced := slices.IndexFunc(s.state.Tasks, func(t Synced) bool { return t.Task == change.Task })
+	switch change.Action {
+	case AddHere:
+		item := want
+		item.CreatedAt = s.tasks[change.Task].CreatedAt
+		if item.CreatedAt.IsZero() {
+			item.CreatedAt = time.Now()
+		}
+		if item.Completed {
+			item.CompletedAt = time.Now()
+		}
+		saved, err := s.store.Add(item)
+		if err != nil {
+			return err
+		}
+		change.ID = saved.ID
+		if synced >= 0 {
+			s.state.Tasks[synced].ID = saved.ID
+		} else {
+			s.state.Tasks = append(s.state.Tasks, Synced{ID: saved.ID, Task: change.Task})
+			synced = len(s.state.Tasks) - 1
+		}
+
+	case UpdateHere:
+		current, err := todo.AllItems(s.store)
+		if err != nil {
+			return err
+		}
+		item := current[change.ID]
+		trashed := !item.DeletedAt.IsZero()
+		item.Todo, item.Notes, item.Project, item.Tags = want.Todo, want.Notes, want.Project, want.Tags
+		item.Priority, item.Due = want.Priority, want.Due
+		if want.Completed != item.Completed {
+			status := todo.StatusBacklog
+			if want.Completed {
+				status = todo.StatusDone
+			}
+			item.SetStatus(status, time.Now())
+		}
+		if trashed {
+			if err := s.store.Restore(item.ID); err != nil {
+				return err
+			}
+		}
+		if err := s.store.Update(item); err != nil {
+			return err
+		}
+
+	case AddThere:
+		project, err := s.project(want.Project)
+		if err != nil {
+			return err
+		}
+		args := taskArgs(want)
+		args.ProjectID = project
+		task, err := s.c.AddTask(args)
+		if err != nil {
+			return err
+		}
+		change.Task = task.ID
+		s.state.Tasks = append(s.state.Tasks, Synced{ID: change.ID, Task: task.ID})
+		synced = len(s.state.Tasks) - 1
+
+	case UpdateThere:
+		project, err := s.project(want.Project)
+		if err != nil {
+			return err
+		}
+		old := s.tasks[change.Task]
+		args := taskArgs(want)
+		if old.ProjectID != project {
+			// The REST API can't move tasks to another project, so the task
+			// is made again in the new one.
+			args.ProjectID = project
+			task, err := s.c.AddTask(args)
+			if err != nil {
+				return err
+			}
+			if err := s.c.DeleteTask(old.ID); err != nil {
+				return err
+			}
+			change.Task, s.state.Tasks[synced].Task = task.ID, task.ID
+			old = task
+		} else {
+			if want.Due.IsZero() {
+				args.DueString = "no date"
+			}
+			if _, err := s.c.UpdateTask(old.ID, args); err != nil {
+				return err
+			}
+		}
+		switch {
+		case want.Completed && !old.IsCompleted:
+			err = s.c.Close(change.Task)
+		case !want.Completed && old.IsCompleted:
+			err = s.c.Reopen(change.Task)
+		}
+		if err != nil {
+			return err
+		}
+
+	case DeleteThere:
+		if err := s.c.DeleteTask(change.Task); err != nil {
+			return err
+		}
+		change.gone = true
+	}
+
+	switch {
+	case change.gone:
+		s.state.Tasks = slices.Delete(s.state.Tasks, synced, synced+1)
+	case synced >= 0:
+		s.state.Tasks[synced].Base = want
+	}
+	return SaveState(s.store, *s.state)
+}
+
+// project returns the ID of the project called name, making it on Todoist
+// if there isn't one yet. Items without a project go in the inbox.
+func (s *syncer) project(name string) (string, error) {
+	if name == "" {
+		return s.inbox, nil
+	}
+	if id, ok := s.projects[strings.ToLower(name)]; ok {
+		return id, nil
+	}
+	p, err := s.c.AddProject(name)
+	if err != nil {
+		return "", err
+	}
+	s.projects[strings.ToLower(name)] = p.ID
+	return p.ID, nil
+}
+
+// kept returns the fields of item that are kept in step with Todoist, with
+// the others left unset.
+func kept(item todo.ParsedTodoItem) todo.ParsedTodoItem {
+	var tags []string
+	if len(item.Tags) > 0 {
+		tags = slices.Sorted(slices.Values(item.Tags))
+	}
+	return todo.ParsedTodoItem{
+		Todo:      item.Todo,
+		Notes:     item.Notes,
+		Project:   item.Project,
+		Tags:      tags,
+		Priority:  item.Priority,
+		Due:       item.Due,
+		Completed: item.Completed,
+	}
+}
+
+// same reports whether a and b have the same fields kept in step.
+func same(a, b todo.ParsedTodoItem) bool {
+	return todosync.Hash(kept(a)) == todosync.Hash(kept(b))
+}
+
+// priorities are Todoist's priorities for each priority, 4 being the
+// most urgent.
+var priorities = map[todo.Priority]int{
+	todo.PriorityNone:   1,
+	todo.PriorityLow:    2,
+	todo.PriorityMedium: 3,
+	todo.PriorityHigh:   4,
+}
+
+// fromTask returns the fields of t kept in step, given the names of the
+// projects by ID.
+func fromTask(t Task, projects map[string]string) todo.ParsedTodoItem {
+	item := todo.ParsedTodoItem{
+		Todo:      t.Content,
+		Notes:     t.Description,
+		Project:   projects[t.ProjectID],
+		Tags:      todo.AddTags(nil, t.Labels...),
+		Completed: t.IsCompleted,
+	}
+	for p, n := range priorities {
+		if n == t.Priority {
+			item.Priority = p
+		}
+	}
+	if t.Due != nil {
+		item.Due = parseDue(*t.Due)
+	}
+	return kept(item)
+}
+
+// parseDue returns when d is, in todo.Location. Times without a zone are
+// taken as being in it.
+func parseDue(d Due) time.Time {
+	if d.Datetime != "" {
+		if t, err := time.Parse(time.RFC3339, d.Datetime); err == nil {
+			return t.In(todo.Location)
+		}
+		if t, err := time.ParseInLocation("2006-01-02T15:04:05", d.Datetime, todo.Location); err == nil {
+			return t
+		}
+	}
+	t, _ := time.ParseInLocation("2006-01-02", d.Date, todo.Location)
+	return t
+}
+
+// taskArgs returns the fields to send for item.
+func taskArgs(item todo.ParsedTodoItem) taskFields {
+	args := taskFields{
+		Content:     item.Todo,
+		Description: item.Notes,
+		Labels:      item.Tags,
+		Priority:    priorities[item.Priority],
+	}
+	if args.Labels == nil {
+		args.Labels = []string{}
+	}
+	switch {
+	case item.Due.IsZero():
+	case item.DueAllDay():
+		args.DueDate = item.Due.In(todo.Location).Format("2006-01-02")
+	default:
+		args.DueDatetime = item.Due.UTC().Format(time.RFC3339)
+	}
+	return args
+}
+
+// sorted returns the items by ID.
+func sorted(byID map[int]todo.ParsedTodoItem) []todo.ParsedTodoItem {
+	list := make([]todo.ParsedTodoItem, 0, len(byID))
+	for _, item := range byID {
+		list = append(list, item)
+	}
+	slices.SortFunc(list, func(a, b todo.ParsedTodoItem) int { return a.ID - b.ID })
+	return list
+}
@@ -0,0 +1,117 @@
+package todosync
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/buck06191/todo-app/pkg/seal"
+	"github.com/buck06191/todo-app/pkg/todo"
+)
+
+// ErrEncrypted is returned when pulling items that were encrypted on
+// another device without a key to decrypt them.
+var ErrEncrypted = errors.New("the items on the server are encrypted")
+
+// sealedPrefix starts the text of an encrypted item.
+const sealedPrefix = "todo-app:sealed:"
+
+// sealItem returns item with its contents encrypted into its text. Its ID,
+// parent, blockers and whether it is in the trash are left as they are,
+// since the server needs to know them.
+func sealItem(key *seal.Key, item todo.ParsedTodoItem) (todo.ParsedTodoItem, error) {
+	contents := item
+	contents.ID, contents.Parent, contents.BlockedBy, contents.DeletedAt = 0, 0, nil, time.Time{}
+	data, err := json.Marshal(contents)
+	if err != nil {
+		return item, err
+	}
+	box, err := key.Seal(data)
+	if err != nil {
+		return item, err
+	}
+	return todo.ParsedTodoItem{
+		ID:        item.ID,
+		Todo:      sealedPrefix + base64.RawURLEncoding.EncodeToString(box),
+		Parent:    item.Parent,
+		BlockedBy: item.BlockedBy,
+		DeletedAt: item.DeletedAt,
+	}, nil
+}
+
+// openItem returns the item sealed in item. Items that aren't encrypted
+// are returned as they are.
+func openItem(key *seal.Key, item todo.ParsedTodoItem) (todo.ParsedTodoItem, error) {
+	if !sealed(item) {
+		return item, nil
+	}
+	box, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(item.Todo, sealedPrefix))
+	if err != nil {
+		return item, fmt.Errorf("item %d on the server is encrypted but garbled", item.ID)
+	}
+	data, err := key.Open(box)
+	if err != nil {
+		return item, fmt.Errorf("item %d on the server: %w", item.ID, err)
+	}
+	var contents todo.ParsedTodoItem
+	if err := json.Unmarshal(data, &contents); err != nil {
+		return item, fmt.Errorf("item %d on the server: %w", item.ID, err)
+	}
+	contents.ID, contents.Parent, contents.BlockedBy, contents.DeletedAt = item.ID, item.Parent, item.BlockedBy, item.DeletedAt
+	return contents, nil
+}
+
+// sealed reports whether item is encrypted.
+func sealed(item todo.ParsedTodoItem) bool {
+	return strings.HasPrefix(item.Todo, sealedPrefix)
+}
+
+// encrypted is a Remote whose items are encrypted with key.
+type encrypted struct {
+	Remote
+	key *seal.Key
+}
+
+// Encrypt returns remote with the items pushed to it encrypted with key,
+// and the ones pulled from it decrypted. A Sync with it also pushes the
+// items synced before they were encrypted, the first time.
+func Encrypt(remote Remote, key *seal.Key) Remote {
+	return &encrypted{Remote: remote, key: key}
+}
+
+func (e *encrypted) Pull(since int) (Pull, error) {
+	pull, err := e.Remote.Pull(since)
+	if err != nil {
+		return pull, err
+	}
+	for i, c := range pull.Changes {
+		if c.Item == nil {
+			continue
+		}
+		item, err := openItem(e.key, *c.Item)
+		if err != nil {
+			return Pull{}, err
+		}
+		pull.Changes[i].Item = &item
+	}
+	return pull, nil
+}
+
+func (e *encrypted) Push(p Push) (Pushed, error) {
+	p.Changes = slices.Clone(p.Changes)
+	for i, c := range p.Changes {
+		if c.Item == nil {
+			continue
+		}
+		item, err := sealItem(e.key, *c.Item)
+		if err != nil {
+			return Pushed{}, err
+		}
+		p.Changes[i].Item = &item
+	}
+	return e.Remote.Push(p)
+}
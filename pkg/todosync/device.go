@@ -0,0 +1,399 @@
+package todosync
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/buck06191/todo-app/pkg/todo"
+)
+
+// stateKey is the meta key a device keeps its State under.
+const stateKey = "sync"
+
+// attempts is how many times Sync pulls again when another device pushes
+// in between its pull and push.
+const attempts = 3
+
+// State is what a device remembers about syncing its list.
+type State struct {
+	// Remote is the URL of the server's list, and Token the API token to
+	// use with it.
+	Remote string `json:"remote"`
+	Token  string `json:"token,omitempty"`
+	// Device names this device to the server.
+	Device string `json:"device"`
+	// Rev is the revision of the server's list the device last synced at.
+	Rev    int       `json:"rev"`
+	Synced time.Time `json:"synced,omitzero"`
+	Items  []Synced  `json:"items,omitempty"`
+	// Conflicts are the fields changed on both sides that are yet to be
+	// resolved.
+	Conflicts []Conflict `json:"conflicts,omitempty"`
+	// Keyfile is the file holding the secret the list's items are
+	// encrypted with on the server, and Passphrase says they are encrypted
+	// with a passphrase instead. Salt is what this device derives its Key
+	// from the secret with, and Sealed says all the items synced have been
+	// pushed encrypted.
+	Keyfile    string `json:"keyfile,omitempty"`
+	Passphrase bool   `json:"passphrase,omitempty"`
+	Salt       []byte `json:"salt,omitempty"`
+	Sealed     bool   `json:"sealed,omitempty"`
+	// Queue is the push being made, kept until the server answers it so
+	// that it can be sent again if it couldn't be reached.
+	Queue *Queued `json:"queue,omitempty"`
+}
+
+// Queued is a push waiting for an answer from the server.
+type Queued struct {
+	Push Push `json:"push"`
+	// Sent is the items pushed as they were on the device, by ID, to be
+	// the base of each once the push is answered.
+	Sent map[int]todo.ParsedTodoItem `json:"sent"`
+}
+
+// Synced is an item that has been synced with the server.
+type Synced struct {
+	// ID is the item's ID on the device and Remote its ID on the server.
+	ID     int `json:"id"`
+	Remote int `json:"remote"`
+	// Base is the item as it was on both when they last synced, for
+	// telling what has changed since.
+	Base todo.ParsedTodoItem `json:"base"`
+}
+
+// LoadState returns the sync state of the list in store, which is empty if
+// it has never been synced.
+func LoadState(store todo.Store) (State, error) {
+	var state State
+	err := todo.LoadMeta(store, stateKey, &state)
+	return state, err
+}
+
+// SaveState saves the sync state of the list in store.
+func SaveState(store todo.Store, state State) error {
+	return todo.SaveMeta(store, stateKey, state)
+}
+
+// Result says what a Sync did.
+type Result struct {
+	// Pulled is how many changes were taken from the server and Pushed how
+	// many were sent to it.
+	Pulled, Pushed int
+	// Conflicts is how many fields were changed differently on both sides.
+	Conflicts int
+}
+
+// Sync pulls the changes made on the server since the list in store last
+// synced and then pushes the ones made on this device, saving state as it
+// goes. An item changed on both sides is merged: fields changed on one
+// side are taken from it, tags, contexts and blockers added and removed on
+// either are added and removed, and other fields changed on both keep this
+// device's value and are recorded in state.Conflicts.
+//
+// A push the server can't be reached for is left in state.Queue, and sent
+// again first by the next sync. The server tells that it is the same push
+// by its key, so that if it was made before the answer to it was lost, it
+// isn't made twice.
+//
+// The first sync of a list with a server that already has items keeps the
+// items of both.
+func Sync(store todo.Store, remote Remote, state *State) (Result, error) {
+	var res Result
+	for try := 1; ; try++ {
+		err := syncOnce(store, remote, state, &res)
+		if errors.Is(err, ErrStale) && try < attempts {
+			continue
+		}
+		return res, err
+	}
+}
+
+func syncOnce(store todo.Store, remote Remote, state *State, res *Result) error {
+	if state.Queue != nil {
+		// A push made against a revision that is no longer the latest
+		// wasn't made, and what it would have pushed is worked out again
+		// below.
+		err := state.push(store, remote, res)
+		if err != nil && !errors.Is(err, ErrStale) {
+			return err
+		}
+	}
+
+	pull, err := remote.Pull(state.Rev)
+	if err != nil {
+		return err
+	}
+	conflicts, err := state.apply(store, pull)
+	if err != nil {
+		return err
+	}
+	res.Conflicts += conflicts
+	res.Pulled += len(pull.Changes)
+	state.Rev = pull.Rev
+	if err := SaveState(store, *state); err != nil {
+		return err
+	}
+
+	// Items synced before the list was encrypted are pushed again, so that
+	// the server doesn't keep them as they were.
+	_, encrypting := remote.(*encrypted)
+	reseal := encrypting && !state.Sealed
+	changes, current, err := state.pending(store, reseal)
+	if err != nil {
+		return err
+	}
+	if len(changes) > 0 {
+		key, err := newKey()
+		if err != nil {
+			return err
+		}
+		state.Queue = &Queued{
+			Push: Push{Device: state.Device, Key: key, Rev: state.Rev, Changes: changes},
+			Sent: current,
+		}
+		if err := SaveState(store, *state); err != nil {
+			return err
+		}
+		if err := state.push(store, remote, res); err != nil {
+			return err
+		}
+	}
+	state.Sealed = encrypting
+	state.Synced = time.Now()
+	return SaveState(store, *state)
+}
+
+// push sends state.Queue to remote, keeping it for the next sync if the
+// server can't be reached.
+func (state *State) push(store todo.Store, remote Remote, res *Result) error {
+	q := state.Queue
+	pushed, err := remote.Push(q.Push)
+	if errors.Is(err, ErrUnreachable) {
+		return fmt.Errorf("%w; the %d changes will be pushed by the next sync", err, len(q.Push.Changes))
+	}
+	state.Queue = nil
+	if err == nil {
+		state.record(q.Push.Changes, q.Sent, pushed)
+		res.Pushed += len(q.Push.Changes)
+		state.Rev = pushed.Rev
+	}
+	if err := SaveState(store, *state); err != nil {
+		return err
+	}
+	return err
+}
+
+// newKey returns a random key for a Push.
+func newKey() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// apply makes the changes pulled from the server to the list in store,
+// returning how many conflicts it found.
+func (state *State) apply(store todo.Store, pull Pull) (int, error) {
+	current, err := todo.AllItems(store)
+	if err != nil {
+		return 0, err
+	}
+	byRemote := make(map[int]int, len(state.Items))
+	for i, s := range state.Items {
+		byRemote[s.Remote] = i
+	}
+
+	// Items new to the device are added first, so that every item can
+	// then be saved with its references given as IDs on the device.
+	added := map[int]bool{}
+	for _, c := range pull.Changes {
+		if c.Item != nil && sealed(*c.Item) {
+			return 0, fmt.Errorf("%w: sync needs the key file or passphrase they were encrypted with", ErrEncrypted)
+		}
+	}
+	for _, c := range pull.Changes {
+		if _, ok := byRemote[c.ID]; ok || c.Item == nil {
+			continue
+		}
+		item := *c.Item
+		item.Parent, item.BlockedBy = 0, nil
+		id, err := Add(store, item)
+		if err != nil {
+			return 0, err
+		}
+		item.ID = id
+		current[id] = item
+		byRemote[c.ID] = len(state.Items)
+		state.Items = append(state.Items, Synced{ID: id, Remote: c.ID})
+		added[c.ID] = true
+	}
+	toLocal := state.localIDs()
+
+	purged := map[int]bool{}
+	saved := map[int]bool{}
+	conflicts := 0
+	for _, c := range pull.Changes {
+		i, ok := byRemote[c.ID]
+		if !ok {
+			continue
+		}
+		s := &state.Items[i]
+		local, ok := current[s.ID]
+
+		if c.Item == nil {
+			if ok && local.DeletedAt.IsZero() {
+				if err := store.Delete(s.ID); err != nil {
+					return 0, err
+				}
+			}
+			purged[s.ID] = true
+			continue
+		}
+
+		want := mapRefs(*c.Item, toLocal)
+		want.ID = s.ID
+		switch {
+		case !ok:
+			// Purged here, which is pushed next.
+		case added[c.ID] || Hash(local) == Hash(s.Base):
+			if Hash(want) != Hash(local) {
+				if err := Put(store, want, !local.DeletedAt.IsZero()); err != nil {
+					return 0, err
+				}
+			}
+			saved[s.ID] = true
+		default:
+			// Changed on both sides: the merge is pushed next.
+			merged, fields := Merge(s.Base, local, want)
+			if Hash(merged) != Hash(local) {
+				if err := Put(store, merged, !local.DeletedAt.IsZero()); err != nil {
+					return 0, err
+				}
+			}
+			for _, f := range fields {
+				state.addConflict(Conflict{ID: s.ID, Field: f, Theirs: want, Device: c.Device, Found: time.Now()})
+			}
+			conflicts += len(fields)
+		}
+		s.Base = want
+	}
+	state.Items = slices.DeleteFunc(state.Items, func(s Synced) bool { return purged[s.ID] })
+
+	// The items as the store saved them are the base, since it may not
+	// keep times as precisely as they were sent.
+	current, err = todo.AllItems(store)
+	if err != nil {
+		return 0, err
+	}
+	for i, s := range state.Items {
+		if saved[s.ID] {
+			state.Items[i].Base = current[s.ID]
+		}
+	}
+	state.dropConflicts(current)
+	return conflicts, nil
+}
+
+// localIDs maps the server's IDs of synced items to the device's.
+func (state *State) localIDs() map[int]int {
+	ids := make(map[int]int, len(state.Items))
+	for _, s := range state.Items {
+		ids[s.Remote] = s.ID
+	}
+	return ids
+}
+
+// mapRefs returns item with its parent and blockers given by their IDs in
+// ids, dropping the ones that aren't in it.
+func mapRefs(item todo.ParsedTodoItem, ids map[int]int) todo.ParsedTodoItem {
+	item.Parent = ids[item.Parent]
+	var blockedBy []int
+	for _, id := range item.BlockedBy {
+		if id = ids[id]; id != 0 {
+			blockedBy = append(blockedBy, id)
+		}
+	}
+	item.BlockedBy = blockedBy
+	return item
+}
+
+// Pending returns the changes made to the list in store since it last
+// synced, as they would be pushed.
+func (state *State) Pending(store todo.Store) ([]Change, error) {
+	changes, _, err := state.pending(store, false)
+	return changes, err
+}
+
+// pending returns the changes to push along with the items they were
+// made from, by ID. all pushes every item, changed or not.
+func (state *State) pending(store todo.Store, all bool) ([]Change, map[int]todo.ParsedTodoItem, error) {
+	current, err := todo.AllItems(store)
+	if err != nil {
+		return nil, nil, err
+	}
+	toRemote := make(map[int]int, len(current))
+	synced := make(map[int]bool, len(state.Items))
+	for _, s := range state.Items {
+		toRemote[s.ID] = s.Remote
+		synced[s.ID] = true
+	}
+	// New items are referred to by -Ref, their ID here.
+	for id := range current {
+		if !synced[id] {
+			toRemote[id] = -id
+		}
+	}
+
+	var changes []Change
+	for _, s := range state.Items {
+		local, ok := current[s.ID]
+		switch {
+		case !ok:
+			changes = append(changes, Change{ID: s.Remote})
+		case all || Hash(local) != Hash(s.Base):
+			item := mapRefs(local, toRemote)
+			item.ID = s.Remote
+			changes = append(changes, Change{ID: s.Remote, Item: &item})
+		}
+	}
+	for _, id := range slices.Sorted(maps.Keys(current)) {
+		if synced[id] {
+			continue
+		}
+		item := mapRefs(current[id], toRemote)
+		item.ID = 0
+		changes = append(changes, Change{Ref: id, Item: &item})
+	}
+	return changes, current, nil
+}
+
+// record notes the changes, made from the items sent, as pushed to the
+// server, which answered with pushed.
+func (state *State) record(changes []Change, sent map[int]todo.ParsedTodoItem, pushed Pushed) {
+	byRemote := make(map[int]int, len(state.Items))
+	for i, s := range state.Items {
+		byRemote[s.Remote] = i
+	}
+
+	purged := map[int]bool{}
+	for _, c := range changes {
+		switch {
+		case c.ID == 0:
+			if remote, ok := pushed.IDs[c.Ref]; ok {
+				state.Items = append(state.Items, Synced{ID: c.Ref, Remote: remote, Base: sent[c.Ref]})
+			}
+		case c.Item == nil:
+			purged[c.ID] = true
+		default:
+			s := &state.Items[byRemote[c.ID]]
+			s.Base = sent[s.ID]
+		}
+	}
+	state.Items = slices.DeleteFunc(state.Items, func(s Synced) bool { return purged[s.Remote] })
+}
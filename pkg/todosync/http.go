@@ -0,0 +1,77 @@
+package todosync
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/buck06191/todo-app/client"
+)
+
+// timeout is how long a request to the server can take.
+const timeout = 30 * time.Second
+
+// httpRemote is a Remote reached over the JSON API of `todo-app serve`.
+type httpRemote struct {
+	url string
+	c   *client.ClientWithResponses
+}
+
+// NewHTTPRemote returns the Remote at url, the address of a server, such as
+// https://todo.example.com, or of a shared list on one, such as
+// https://todo.example.com/lists/household. token is the API token to use,
+// or empty for none.
+func NewHTTPRemote(url, token string) (Remote, error) {
+	opts := []client.ClientOption{client.WithHTTPClient(&http.Client{Timeout: timeout})}
+	if token != "" {
+		opts = append(opts, client.WithToken(token))
+	}
+	c, err := client.NewClientWithResponses(url, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &httpRemote{url: url, c: c}, nil
+}
+
+func (r *httpRemote) Pull(since int) (Pull, error) {
+	resp, err := r.c.PullSyncWithResponse(context.Background(), &client.PullSyncParams{Since: &since})
+	if err != nil {
+		return Pull{}, fmt.Errorf("%w: pulling from %s: %w", ErrUnreachable, r.url, err)
+	}
+	var pull Pull
+	err = r.decode(resp.HTTPResponse, resp.Body, &pull)
+	return pull, err
+}
+
+func (r *httpRemote) Push(p Push) (Pushed, error) {
+	body, err := json.Marshal(p)
+	if err != nil {
+		return Pushed{}, err
+	}
+	resp, err := r.c.PushSyncWithBodyWithResponse(context.Background(), "application/json", bytes.NewReader(body))
+	if err != nil {
+		return Pushed{}, fmt.Errorf("%w: pushing to %s: %w", ErrUnreachable, r.url, err)
+	}
+	var pushed Pushed
+	err = r.decode(resp.HTTPResponse, resp.Body, &pushed)
+	return pushed, err
+}
+
+// decode reads the body of a successful response into v, or returns the
+// error the server answered with.
+func (r *httpRemote) decode(resp *http.Response, body []byte, v any) error {
+	if resp.StatusCode == http.StatusOK {
+		return json.Unmarshal(body, v)
+	}
+	var e client.Error
+	if json.Unmarshal(body, &e) != nil || e.Error == "" {
+		e.Error = resp.Status
+	}
+	if resp.StatusCode == http.StatusConflict {
+		return fmt.Errorf("%w: %s", ErrStale, e.Error)
+	}
+	return fmt.Errorf("%s: %s", r.url, e.Error)
+}
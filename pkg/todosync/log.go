@@ -0,0 +1,255 @@
+package todosync
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/buck06191/todo-app/pkg/todo"
+)
+
+// ErrBadChange is returned by Apply for changes that make no sense, such as
+// a new item without a Ref.
+var ErrBadChange = errors.New("bad change")
+
+// logKey is the meta key the server keeps its changeLog under.
+const logKey = "sync-log"
+
+// changeLog is the revision each item on the server's list was last changed
+// at. It is brought up to date by comparing hashes of the items with the
+// ones saved, so changes made to the list without going through the server
+// are picked up too.
+type changeLog struct {
+	Rev   int              `json:"rev"`
+	Items map[int]logEntry `json:"items"`
+	// Pushes is the last push made by each device that gave a key, by
+	// device.
+	Pushes map[string]keyedPush `json:"pushes,omitempty"`
+}
+
+type keyedPush struct {
+	Key    string `json:"key"`
+	Pushed Pushed `json:"pushed"`
+}
+
+type logEntry struct {
+	Rev int `json:"rev"`
+	// Hash is the Hash of the item, or empty once it has been purged.
+	Hash   string `json:"hash,omitempty"`
+	Device string `json:"device,omitempty"`
+}
+
+// loadLog returns the change log of store, brought up to date with the
+// items it has now, and those items.
+func loadLog(store todo.Store) (*changeLog, map[int]todo.ParsedTodoItem, error) {
+	l := &changeLog{Items: map[int]logEntry{}}
+	if err := todo.LoadMeta(store, logKey, l); err != nil {
+		return nil, nil, err
+	}
+	current, err := todo.AllItems(store)
+	if err != nil {
+		return nil, nil, err
+	}
+	if l.scan(current) {
+		if err := todo.SaveMeta(store, logKey, l); err != nil {
+			return nil, nil, err
+		}
+	}
+	return l, current, nil
+}
+
+// scan gives a new revision to every item in current that has changed
+// since the log last saw it, and to every item that has gone, reporting
+// whether there were any.
+func (l *changeLog) scan(current map[int]todo.ParsedTodoItem) bool {
+	var ids []int
+	for id := range current {
+		ids = append(ids, id)
+	}
+	for id := range l.Items {
+		if _, ok := current[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+
+	changed := false
+	for _, id := range ids {
+		hash := ""
+		if item, ok := current[id]; ok {
+			hash = Hash(item)
+		}
+		if e, ok := l.Items[id]; (ok || hash != "") && e.Hash != hash {
+			l.Rev++
+			l.Items[id] = logEntry{Rev: l.Rev, Hash: hash}
+			changed = true
+		}
+	}
+	return changed
+}
+
+// Changes returns the changes made to the list in store since the revision
+// since, in the order they were made, for a device to pull.
+func Changes(store todo.Store, since int) (Pull, error) {
+	l, current, err := loadLog(store)
+	if err != nil {
+		return Pull{}, err
+	}
+	if since > l.Rev {
+		return Pull{}, fmt.Errorf("%w: it is at revision %d, not %d", ErrStale, l.Rev, since)
+	}
+
+	pull := Pull{Rev: l.Rev, Changes: []Change{}}
+	for id, e := range l.Items {
+		if e.Rev <= since {
+			continue
+		}
+		c := Change{ID: id, Device: e.Device}
+		if item, ok := current[id]; ok {
+			c.Item = &item
+		}
+		pull.Changes = append(pull.Changes, c)
+	}
+	slices.SortFunc(pull.Changes, func(a, b Change) int {
+		return l.Items[a.ID].Rev - l.Items[b.ID].Rev
+	})
+	return pull, nil
+}
+
+// Apply makes the changes pushed by a device to the list in store. It
+// returns the changes as made, for telling others about them: new items
+// have their Ref set along with the ID they were given.
+//
+// A push with the same Key as the last one from its device isn't made
+// again: it is answered as it was then, even if the list has changed
+// since.
+//
+// Items a device has purged are moved to the trash rather than purged,
+// since a store can only empty its whole trash, and changes to items that
+// have since been purged on the server are dropped.
+func Apply(store todo.Store, p Push) (Pushed, []Change, error) {
+	for _, c := range p.Changes {
+		if err := check(c); err != nil {
+			return Pushed{}, nil, err
+		}
+	}
+	l, current, err := loadLog(store)
+	if err != nil {
+		return Pushed{}, nil, err
+	}
+	if k, ok := l.Pushes[p.Device]; ok && p.Key != "" && k.Key == p.Key {
+		return k.Pushed, nil, nil
+	}
+	if p.Rev != l.Rev {
+		return Pushed{}, nil, fmt.Errorf("%w: it is at revision %d, not %d", ErrStale, l.Rev, p.Rev)
+	}
+
+	// New items are added first, without the references to each other
+	// they can't have yet, so that every item can then be saved with its
+	// references given as IDs on the server.
+	pushed := Pushed{IDs: map[int]int{}}
+	for _, c := range p.Changes {
+		if c.ID != 0 {
+			continue
+		}
+		item := resolveRefs(*c.Item, nil)
+		id, err := Add(store, item)
+		if err != nil {
+			return Pushed{}, nil, err
+		}
+		pushed.IDs[c.Ref] = id
+		item.ID = id
+		current[id] = item
+	}
+
+	var applied []Change
+	for _, c := range p.Changes {
+		id := c.ID
+		if id == 0 {
+			id = pushed.IDs[c.Ref]
+		}
+		cur, ok := current[id]
+		if !ok {
+			continue
+		}
+		trashed := !cur.DeletedAt.IsZero()
+
+		if c.Item == nil {
+			if !trashed {
+				if err := store.Delete(id); err != nil {
+					return Pushed{}, nil, err
+				}
+				cur.DeletedAt = time.Now()
+				applied = append(applied, Change{ID: id, Item: &cur, Device: p.Device})
+			}
+			continue
+		}
+
+		want := resolveRefs(*c.Item, pushed.IDs)
+		want.ID = id
+		if Hash(want) != Hash(cur) {
+			if err := Put(store, want, trashed); err != nil {
+				return Pushed{}, nil, err
+			}
+		}
+		applied = append(applied, Change{ID: id, Ref: c.Ref, Item: &want, Device: p.Device})
+	}
+
+	l, _, err = loadLog(store)
+	if err != nil {
+		return Pushed{}, nil, err
+	}
+	for id, e := range l.Items {
+		if e.Rev > p.Rev {
+			e.Device = p.Device
+			l.Items[id] = e
+		}
+	}
+	pushed.Rev = l.Rev
+	if p.Key != "" {
+		if l.Pushes == nil {
+			l.Pushes = map[string]keyedPush{}
+		}
+		l.Pushes[p.Device] = keyedPush{Key: p.Key, Pushed: pushed}
+	}
+	if err := todo.SaveMeta(store, logKey, l); err != nil {
+		return Pushed{}, nil, err
+	}
+	return pushed, applied, nil
+}
+
+func check(c Change) error {
+	switch {
+	case c.ID < 0:
+		return fmt.Errorf("%w: item ID %d", ErrBadChange, c.ID)
+	case c.ID == 0 && c.Ref <= 0:
+		return fmt.Errorf("%w: a new item needs a ref above 0", ErrBadChange)
+	case c.ID == 0 && c.Item == nil:
+		return fmt.Errorf("%w: new item %d has no contents", ErrBadChange, c.Ref)
+	case c.Item != nil && c.Item.Todo == "":
+		return fmt.Errorf("%w: item %d has no text", ErrBadChange, max(c.ID, c.Ref))
+	}
+	return nil
+}
+
+// resolveRefs returns item with the references to new items, given as
+// -Ref, replaced by their IDs in ids. References to items that aren't in
+// ids are dropped.
+func resolveRefs(item todo.ParsedTodoItem, ids map[int]int) todo.ParsedTodoItem {
+	resolve := func(id int) int {
+		if id >= 0 {
+			return id
+		}
+		return ids[-id]
+	}
+	item.Parent = resolve(item.Parent)
+	var blockedBy []int
+	for _, id := range item.BlockedBy {
+		if id = resolve(id); id != 0 {
+			blockedBy = append(blockedBy, id)
+		}
+	}
+	item.BlockedBy = blockedBy
+	return item
+}
@@ -0,0 +1,225 @@
+package todosync
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/buck06191/todo-app/pkg/todo"
+)
+
+// Conflict is a field of an item that was changed to different values on
+// the device and on the server in between syncs. The device's value is
+// kept, and pushed, until the conflict is resolved.
+type Conflict struct {
+	// ID is the item's ID on the device.
+	ID int `json:"id"`
+	// Field is the JSON name of the field, such as "due".
+	Field string `json:"field"`
+	// Theirs is the item as it was on the server.
+	Theirs todo.ParsedTodoItem `json:"theirs"`
+	// Device is the device the server's value came from, if any.
+	Device string    `json:"device,omitempty"`
+	Found  time.Time `json:"found"`
+}
+
+// field is a field of todo.ParsedTodoItem that is merged on its own.
+type field struct {
+	name  string
+	index int
+	// set fields are lists merged by adding and removing what was added
+	// and removed on either side, so they never conflict.
+	set bool
+}
+
+// fields are the fields that are merged. The ID is the device's own,
+// completed_at goes along with completed and only whether deleted_at is
+// set matters.
+var fields = func() []field {
+	var fields []field
+	t := reflect.TypeOf(todo.ParsedTodoItem{})
+	for i := range t.NumField() {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		switch name {
+		case "id", "completed_at":
+			continue
+		}
+		fields = append(fields, field{name: name, index: i, set: t.Field(i).Type.Kind() == reflect.Slice})
+	}
+	return fields
+}()
+
+// findField returns the field with the given JSON name.
+func findField(name string) (field, bool) {
+	for _, f := range fields {
+		if f.name == name {
+			return f, true
+		}
+	}
+	return field{}, false
+}
+
+// Merge returns ours with the changes made in theirs since base. Fields
+// changed on only one side are taken from that side, and tags, contexts and
+// blockers added or removed on either side are added or removed. Fields
+// changed on both to different values are left as they are in ours and
+// returned by their JSON names.
+func Merge(base, ours, theirs todo.ParsedTodoItem) (todo.ParsedTodoItem, []string) {
+	merged := ours
+	vb, vo, vt := reflect.ValueOf(base), reflect.ValueOf(ours), reflect.ValueOf(theirs)
+	vm := reflect.ValueOf(&merged).Elem()
+
+	var conflicts []string
+	for _, f := range fields {
+		b, o, t := f.compared(vb), f.compared(vo), f.compared(vt)
+		switch {
+		case same(o, t) || same(b, t):
+		case same(b, o):
+			vm.Field(f.index).Set(vt.Field(f.index))
+			if f.name == "completed" {
+				merged.CompletedAt = theirs.CompletedAt
+			}
+		case f.set:
+			vm.Field(f.index).Set(mergeSets(b, o, t))
+		default:
+			conflicts = append(conflicts, f.name)
+		}
+	}
+	return merged, conflicts
+}
+
+// compared returns the value of the field of item that merge compares,
+// which for deleted_at is only whether it is set.
+func (f field) compared(item reflect.Value) reflect.Value {
+	v := item.Field(f.index)
+	if f.name == "deleted_at" {
+		return reflect.ValueOf(!v.Interface().(time.Time).IsZero())
+	}
+	return v
+}
+
+// same reports whether two values of a field are the same: times at the
+// same instant and lists with the same elements in the same order.
+func same(a, b reflect.Value) bool {
+	switch a := a.Interface().(type) {
+	case time.Time:
+		return a.Equal(b.Interface().(time.Time))
+	}
+	if a.Kind() == reflect.Slice && a.Len() == 0 && b.Len() == 0 {
+		return true
+	}
+	return reflect.DeepEqual(a.Interface(), b.Interface())
+}
+
+// mergeSets returns ours with what theirs added since base added, and what
+// it removed removed.
+func mergeSets(base, ours, theirs reflect.Value) reflect.Value {
+	in := func(list reflect.Value, v any) bool {
+		for i := range list.Len() {
+			if list.Index(i).Interface() == v {
+				return true
+			}
+		}
+		return false
+	}
+	merged := reflect.MakeSlice(ours.Type(), 0, ours.Len()+theirs.Len())
+	for i := range ours.Len() {
+		v := ours.Index(i)
+		if in(base, v.Interface()) && !in(theirs, v.Interface()) {
+			continue
+		}
+		merged = reflect.Append(merged, v)
+	}
+	for i := range theirs.Len() {
+		v := theirs.Index(i)
+		if !in(base, v.Interface()) && !in(merged, v.Interface()) {
+			merged = reflect.Append(merged, v)
+		}
+	}
+	return merged
+}
+
+// Value returns the value of the named field of item, formatted for
+// showing.
+func Value(item todo.ParsedTodoItem, name string) string {
+	f, ok := findField(name)
+	if !ok {
+		return ""
+	}
+	switch v := reflect.ValueOf(item).Field(f.index).Interface().(type) {
+	case time.Time:
+		if v.IsZero() {
+			return "none"
+		}
+		return v.In(todo.Location).Format("2006-01-02 15:04")
+	case string:
+		if v == "" {
+			return "none"
+		}
+		return fmt.Sprintf("%q", v)
+	case fmt.Stringer:
+		if s := v.String(); s != "" {
+			return s
+		}
+		return "none"
+	case []string:
+		if len(v) == 0 {
+			return "none"
+		}
+		return strings.Join(v, " ")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// dropConflicts forgets the conflicts for items that aren't in current,
+// having since been purged.
+func (state *State) dropConflicts(current map[int]todo.ParsedTodoItem) {
+	state.Conflicts = slices.DeleteFunc(state.Conflicts, func(c Conflict) bool {
+		_, ok := current[c.ID]
+		return !ok
+	})
+}
+
+// addConflict records a conflict, replacing any earlier one for the same
+// field of the same item.
+func (state *State) addConflict(c Conflict) {
+	for i, old := range state.Conflicts {
+		if old.ID == c.ID && old.Field == c.Field {
+			state.Conflicts[i] = c
+			return
+		}
+	}
+	state.Conflicts = append(state.Conflicts, c)
+}
+
+// Resolve resolves the i'th of state.Conflicts, either by keeping the
+// device's value or by taking the server's, which is pushed on the next
+// sync.
+func (state *State) Resolve(store todo.Store, i int, theirs bool) error {
+	c := state.Conflicts[i]
+	if theirs {
+		current, err := todo.AllItems(store)
+		if err != nil {
+			return err
+		}
+		item, ok := current[c.ID]
+		f, known := findField(c.Field)
+		if ok && known {
+			trashed := !item.DeletedAt.IsZero()
+			want := item
+			reflect.ValueOf(&want).Elem().Field(f.index).Set(reflect.ValueOf(c.Theirs).Field(f.index))
+			if c.Field == "completed" {
+				want.CompletedAt = c.Theirs.CompletedAt
+			}
+			want.DeletedAt = item.DeletedAt
+			if err := Put(store, want, trashed); err != nil {
+				return err
+			}
+		}
+	}
+	state.Conflicts = slices.Delete(state.Conflicts, i, i+1)
+	return SaveState(store, *state)
+}
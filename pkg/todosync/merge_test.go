@@ -0,0 +1,104 @@
+package todosync
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/buck06191/todo-app/pkg/todo"
+)
+
+func TestMerge(t *testing.T) {
+	monday := time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC)
+	friday := time.Date(2025, 3, 7, 9, 0, 0, 0, time.UTC)
+	base := todo.ParsedTodoItem{ID: 1, Todo: "Buy milk", Due: monday, Tags: []string{"shopping"}, Priority: todo.PriorityLow}
+
+	t.Run("changed on one side", func(t *testing.T) {
+		ours, theirs := base, base
+		ours.Todo = "Buy oat milk"
+		theirs.Due = friday
+		merged, conflicts := Merge(base, ours, theirs)
+		if len(conflicts) != 0 {
+			t.Errorf("conflicts = %v, want none", conflicts)
+		}
+		if merged.Todo != "Buy oat milk" || !merged.Due.Equal(friday) {
+			t.Errorf("Merge = %q due %s, want both changes", merged.Todo, merged.Due)
+		}
+	})
+
+	t.Run("changed to the same on both", func(t *testing.T) {
+		ours, theirs := base, base
+		ours.Priority, theirs.Priority = todo.PriorityHigh, todo.PriorityHigh
+		// The same instant in another zone is the same due date.
+		ours.Due, theirs.Due = friday, friday.In(time.FixedZone("EST", -5*3600))
+		merged, conflicts := Merge(base, ours, theirs)
+		if len(conflicts) != 0 || merged.Priority != todo.PriorityHigh {
+			t.Errorf("Merge = %v, %v, want high priority without conflicts", merged.Priority, conflicts)
+		}
+	})
+
+	t.Run("changed differently on both", func(t *testing.T) {
+		ours, theirs := base, base
+		ours.Todo, theirs.Todo = "Buy oat milk", "Buy soy milk"
+		ours.Priority, theirs.Priority = todo.PriorityHigh, todo.PriorityMedium
+		merged, conflicts := Merge(base, ours, theirs)
+		if !slices.Equal(conflicts, []string{"todo", "priority"}) {
+			t.Errorf("conflicts = %v, want [todo priority]", conflicts)
+		}
+		if merged.Todo != "Buy oat milk" || merged.Priority != todo.PriorityHigh {
+			t.Errorf("Merge = %q %v, want ours kept", merged.Todo, merged.Priority)
+		}
+	})
+
+	t.Run("done on the server", func(t *testing.T) {
+		theirs := base
+		theirs.Completed, theirs.CompletedAt = true, friday
+		merged, conflicts := Merge(base, base, theirs)
+		if len(conflicts) != 0 || !merged.Completed || !merged.CompletedAt.Equal(friday) {
+			t.Errorf("Merge = %v at %s, %v, want done at %s", merged.Completed, merged.CompletedAt, conflicts, friday)
+		}
+	})
+
+	t.Run("deleted on both at different times", func(t *testing.T) {
+		ours, theirs := base, base
+		ours.DeletedAt, theirs.DeletedAt = monday, friday
+		merged, conflicts := Merge(base, ours, theirs)
+		if len(conflicts) != 0 || !merged.DeletedAt.Equal(monday) {
+			t.Errorf("Merge = deleted at %s, %v, want ours without conflicts", merged.DeletedAt, conflicts)
+		}
+	})
+
+	t.Run("tags changed on both", func(t *testing.T) {
+		ours, theirs := base, base
+		ours.Tags = []string{"shopping", "home"}
+		theirs.Tags = []string{"errand"}
+		merged, conflicts := Merge(base, ours, theirs)
+		if len(conflicts) != 0 {
+			t.Errorf("conflicts = %v, want none as tags are merged", conflicts)
+		}
+		if !slices.Equal(merged.Tags, []string{"home", "errand"}) {
+			t.Errorf("Tags = %v, want [home errand]", merged.Tags)
+		}
+	})
+}
+
+func TestMergeSets(t *testing.T) {
+	tests := []struct {
+		base, ours, theirs, want []int
+	}{
+		{nil, nil, nil, nil},
+		{nil, []int{1}, []int{2}, []int{1, 2}},
+		{[]int{1, 2}, []int{1, 2, 3}, []int{2}, []int{2, 3}},
+		{[]int{1}, []int{1}, []int{1, 2}, []int{1, 2}},
+		{[]int{1}, nil, []int{1, 2}, []int{2}},
+		{[]int{1}, []int{1, 2}, []int{2, 1}, []int{1, 2}},
+		{[]int{1, 2}, nil, nil, nil},
+	}
+	for _, tt := range tests {
+		got := mergeSets(reflect.ValueOf(tt.base), reflect.ValueOf(tt.ours), reflect.ValueOf(tt.theirs)).Interface().([]int)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("mergeSets(%v, %v, %v) = %v, want %v", tt.base, tt.ours, tt.theirs, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+// Package todosync keeps the todo lists of several devices in step through
+// a `todo-app serve` server, as used by `todo-app sync`.
+//
+// The server numbers every change to its list with a revision. A device
+// remembers the revision it last synced at along with how each item was
+// then, so it can pull just the changes made since and push just the items
+// it has changed. Items keep their own IDs on each device; the server's IDs
+// are only used between the two.
+//
+// A Remote wrapped with Encrypt encrypts the contents of the items a device
+// pushes, so that the server only keeps ciphertext.
+package todosync
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/buck06191/todo-app/pkg/todo"
+)
+
+// ErrStale is returned by a push made against a revision of the server's
+// list that is no longer the latest, and by a pull from a server that has
+// never reached the revision asked for.
+var ErrStale = errors.New("the list on the server has changed since it was last pulled")
+
+// ErrUnreachable is returned by a Remote that couldn't get an answer from
+// the server, which may or may not have received what was sent.
+var ErrUnreachable = errors.New("can't reach the server")
+
+// Change is a change to one item, as sent between a device and the
+// server.
+type Change struct {
+	// ID is the item's ID on the server, or 0 for an item a device has
+	// added since it last synced.
+	ID int `json:"id"`
+	// Ref is the device's ID for a new item. Other items pushed with it
+	// refer to it as a parent or blocker by -Ref, since it doesn't have
+	// an ID on the server yet.
+	Ref int `json:"ref,omitempty"`
+	// Item is the item as it is now, with DeletedAt set if it is in the
+	// trash. It is nil if the item has been purged.
+	Item *todo.ParsedTodoItem `json:"item"`
+	// Device is the device that made the change, or empty if it was made
+	// on the server itself.
+	Device string `json:"device,omitempty"`
+}
+
+// Pull is what a device is sent when asking for the changes made since
+// the revision it last synced at.
+type Pull struct {
+	// Rev is the latest revision of the list.
+	Rev     int      `json:"rev"`
+	Changes []Change `json:"changes"`
+}
+
+// Push is the changes a device has made since it last synced.
+type Push struct {
+	Device string `json:"device"`
+	// Key tells apart the pushes a device makes. A push sent again with
+	// the same key, as it is when the answer to it was lost, is answered
+	// with what it was the first time rather than being made twice.
+	Key string `json:"key,omitempty"`
+	// Rev is the revision the device last pulled. The push is refused
+	// with ErrStale if the list has changed since.
+	Rev     int      `json:"rev"`
+	Changes []Change `json:"changes"`
+}
+
+// Pushed is the answer to a Push.
+type Pushed struct {
+	// Rev is the revision of the list with the push made.
+	Rev int `json:"rev"`
+	// IDs are the IDs given on the server to new items, by their Ref.
+	IDs map[int]int `json:"ids"`
+}
+
+// Remote is a server to sync with.
+type Remote interface {
+	// Pull returns the changes made since the revision given.
+	Pull(since int) (Pull, error)
+	// Push sends the changes made on a device.
+	Push(p Push) (Pushed, error)
+}
+
+// Hash returns a hash of the contents of item, for telling whether it has
+// changed. Its ID and when it was moved to the trash are left out, but not
+// whether it has been, and times are compared in UTC.
+func Hash(item todo.ParsedTodoItem) string {
+	item.ID = 0
+	item.Due = item.Due.UTC()
+	item.CreatedAt = item.CreatedAt.UTC()
+	item.CompletedAt = item.CompletedAt.UTC()
+	if !item.DeletedAt.IsZero() {
+		item.DeletedAt = time.Unix(0, 0).UTC()
+	}
+	// An item always marshals.
+	data, _ := json.Marshal(item)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:16])
+}
+
+// Put makes the item with the ID of want look like it, moving it into or
+// out of the trash as need be. trashed says whether it is in the trash
+// now.
+func Put(store todo.Store, want todo.ParsedTodoItem, trashed bool) error {
+	if trashed {
+		if err := store.Restore(want.ID); err != nil {
+			return err
+		}
+	}
+	deleted := !want.DeletedAt.IsZero()
+	want.DeletedAt = time.Time{}
+	if err := store.Update(want); err != nil {
+		return err
+	}
+	if deleted {
+		return store.Delete(want.ID)
+	}
+	return nil
+}
+
+// Add adds want to store as a new item, returning its ID.
+func Add(store todo.Store, want todo.ParsedTodoItem) (int, error) {
+	deleted := !want.DeletedAt.IsZero()
+	want.DeletedAt = time.Time{}
+	saved, err := store.Add(want)
+	if err != nil {
+		return 0, err
+	}
+	if deleted {
+		return saved.ID, store.Delete(saved.ID)
+	}
+	return saved.ID, nil
+}
@@ -0,0 +1,169 @@
+// Package todotxt reads and writes todo items in the todo.txt format
+// (https://github.com/todotxt/todo.txt), for `todo-app import -format
+// todotxt` and `todo-app export -format todotxt`.
+//
+// Each line is an item:
+//
+//	x 2026-03-02 2026-03-01 (A) Call the bank +money @phone due:2026-03-05
+//
+// "x" and the completion date mark it done, (A), (B) and (C) are high,
+// medium and low priority and the date before the text is when it was
+// added. +project and @context words are the item's project and contexts,
+// #tag words its tags, as in `todo-app add`, and due: is the day it is
+// due. Done items keep their priority as pri:, the way todo.txt clients
+// do. Other key:value words are kept in the text.
+package todotxt
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/buck06191/todo-app/pkg/todo"
+)
+
+const date = "2006-01-02"
+
+// priorities are the letters for each priority. Letters after C are read
+// as low.
+var priorities = map[todo.Priority]string{
+	todo.PriorityHigh:   "A",
+	todo.PriorityMedium: "B",
+	todo.PriorityLow:    "C",
+}
+
+var priorityMark = regexp.MustCompile(`^\(([A-Z])\)$`)
+
+// Read reads the items of a todo.txt file, skipping blank lines.
+func Read(r io.Reader) ([]todo.ParsedTodoItem, error) {
+	var items []todo.ParsedTodoItem
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		item, err := Parse(scanner.Text())
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		items = append(items, item)
+	}
+	return items, scanner.Err()
+}
+
+// Parse parses one line of a todo.txt file.
+func Parse(line string) (todo.ParsedTodoItem, error) {
+	var item todo.ParsedTodoItem
+	words := strings.Fields(line)
+	if len(words) > 0 && words[0] == "x" {
+		item.Completed = true
+		words = words[1:]
+		if t, ok := day(words); ok {
+			item.CompletedAt, words = t, words[1:]
+		}
+	}
+	if len(words) > 0 {
+		if m := priorityMark.FindStringSubmatch(words[0]); m != nil {
+			item.Priority, words = priority(m[1]), words[1:]
+		}
+	}
+	if t, ok := day(words); ok {
+		item.CreatedAt, words = t, words[1:]
+	}
+
+	var text []string
+	for _, word := range words {
+		key, value, _ := strings.Cut(word, ":")
+		switch {
+		case key == "due" && value != "":
+			t, err := time.ParseInLocation(date, value, todo.Location)
+			if err != nil {
+				return item, fmt.Errorf("bad due date %q", value)
+			}
+			item.Due = t
+		case key == "pri" && len(value) == 1 && item.Priority == todo.PriorityNone:
+			item.Priority = priority(strings.ToUpper(value))
+		default:
+			text = append(text, word)
+		}
+	}
+	rest := strings.Join(text, " ")
+	rest, item.Tags = todo.ExtractTags(rest)
+	rest, item.Contexts = todo.ExtractContexts(rest)
+	item.Todo, item.Project = todo.ExtractProject(rest)
+	if item.Todo == "" {
+		return item, fmt.Errorf("no text in %q", line)
+	}
+	if item.Completed && item.CompletedAt.IsZero() {
+		item.CompletedAt = item.CreatedAt
+	}
+	return item, nil
+}
+
+// day returns the date words start with, if they do.
+func day(words []string) (time.Time, bool) {
+	if len(words) == 0 {
+		return time.Time{}, false
+	}
+	t, err := time.ParseInLocation(date, words[0], todo.Location)
+	return t, err == nil
+}
+
+func priority(letter string) todo.Priority {
+	for p, l := range priorities {
+		if l == letter {
+			return p
+		}
+	}
+	return todo.PriorityLow
+}
+
+// Write writes items as the lines of a todo.txt file.
+func Write(w io.Writer, items []todo.ParsedTodoItem) error {
+	for _, item := range items {
+		if _, err := fmt.Fprintln(w, Format(item)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Format returns item as a line of a todo.txt file. The time of day an
+// item is due is left out, as todo.txt has no place for it, and spaces in
+// its project are written as dashes.
+func Format(item todo.ParsedTodoItem) string {
+	var words []string
+	if item.Completed {
+		words = append(words, "x")
+		if !item.CompletedAt.IsZero() {
+			words = append(words, item.CompletedAt.In(todo.Location).Format(date))
+		}
+	} else if l, ok := priorities[item.Priority]; ok {
+		words = append(words, "("+l+")")
+	}
+	// A done item's creation date can only be given after its completion
+	// date.
+	if !item.CreatedAt.IsZero() && (!item.Completed || !item.CompletedAt.IsZero()) {
+		words = append(words, item.CreatedAt.In(todo.Location).Format(date))
+	}
+	words = append(words, strings.Fields(item.Todo)...)
+	if item.Project != "" {
+		words = append(words, "+"+strings.Join(strings.Fields(item.Project), "-"))
+	}
+	for _, c := range item.Contexts {
+		words = append(words, "@"+c)
+	}
+	for _, t := range item.Tags {
+		words = append(words, "#"+t)
+	}
+	if !item.Due.IsZero() {
+		words = append(words, "due:"+item.Due.In(todo.Location).Format(date))
+	}
+	if l, ok := priorities[item.Priority]; ok && item.Completed {
+		words = append(words, "pri:"+l)
+	}
+	return strings.Join(words, " ")
+}
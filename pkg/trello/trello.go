@@ -0,0 +1,168 @@
+// Package trello reads the JSON Trello exports a board as, from the
+// board's menu under Print, export and share, for `todo-app import
+// trello`.
+//
+// Each open card is an item, with its description as the notes, its
+// labels as tags and its due date, done if the due date is marked
+// complete. The items of its checklists are subtasks. Cards and lists that
+// have been archived are left out.
+package trello
+
+import (
+	"cmp"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/buck06191/todo-app/pkg/todo"
+)
+
+// ErrNotBoard is returned by Read for JSON that isn't a Trello board.
+var ErrNotBoard = errors.New("not a Trello board export")
+
+// Lists says what the list a card is in becomes.
+type Lists int
+
+const (
+	// ListsAsProjects makes each list the project of its cards.
+	ListsAsProjects Lists = iota
+	// ListsAsTags makes the board the project of every card, and each list
+	// a tag of its cards, for boards whose lists are stages like To Do,
+	// Doing and Done.
+	ListsAsTags
+)
+
+type board struct {
+	Name  string `json:"name"`
+	Lists []struct {
+		ID     string `json:"id"`
+		Name   string `json:"name"`
+		Closed bool   `json:"closed"`
+	} `json:"lists"`
+	Cards []struct {
+		ID          string    `json:"id"`
+		Name        string    `json:"name"`
+		Desc        string    `json:"desc"`
+		Closed      bool      `json:"closed"`
+		IDList      string    `json:"idList"`
+		Due         time.Time `json:"due"`
+		DueComplete bool      `json:"dueComplete"`
+		Labels      []struct {
+			Name  string `json:"name"`
+			Color string `json:"color"`
+		} `json:"labels"`
+	} `json:"cards"`
+	Checklists []struct {
+		IDCard     string      `json:"idCard"`
+		CheckItems []checkItem `json:"checkItems"`
+	} `json:"checklists"`
+}
+
+type checkItem struct {
+	Name string `json:"name"`
+	// State is complete or incomplete.
+	State string  `json:"state"`
+	Pos   float64 `json:"pos"`
+}
+
+// Read reads a board, returning its cards and checklist items as items.
+// Each card's item has an ID of its own, which its subtasks' Parent is
+// set to, for the caller to number anew as it adds them.
+func Read(r io.Reader, lists Lists) ([]todo.ParsedTodoItem, error) {
+	var b board
+	if err := json.NewDecoder(r).Decode(&b); err != nil {
+		return nil, err
+	}
+	if b.Lists == nil && b.Cards == nil {
+		return nil, ErrNotBoard
+	}
+	names := map[string]string{}
+	for _, l := range b.Lists {
+		if !l.Closed {
+			names[l.ID] = l.Name
+		}
+	}
+	checklists := map[string][]int{}
+	for i, c := range b.Checklists {
+		checklists[c.IDCard] = append(checklists[c.IDCard], i)
+	}
+
+	var items []todo.ParsedTodoItem
+	for _, c := range b.Cards {
+		list, ok := names[c.IDList]
+		if c.Closed || !ok {
+			continue
+		}
+		item := todo.ParsedTodoItem{
+			ID:        len(items) + 1,
+			Todo:      strings.TrimSpace(c.Name),
+			Notes:     strings.TrimSpace(c.Desc),
+			Due:       c.Due,
+			CreatedAt: created(c.ID),
+		}
+		if item.Todo == "" {
+			continue
+		}
+		switch lists {
+		case ListsAsProjects:
+			item.Project = list
+		case ListsAsTags:
+			item.Project = b.Name
+			item.Tags = todo.AddTags(item.Tags, tag(list))
+		}
+		for _, l := range c.Labels {
+			if l.Name == "" {
+				// Labels can be just a colour.
+				l.Name = l.Color
+			}
+			item.Tags = todo.AddTags(item.Tags, tag(l.Name))
+		}
+		if !item.Due.IsZero() {
+			item.Due = item.Due.In(todo.Location)
+			item.Completed = c.DueComplete
+		}
+		items = append(items, item)
+
+		parent := item
+		for _, i := range checklists[c.ID] {
+			checkItems := b.Checklists[i].CheckItems
+			// The order on the card is by position, not the order in the
+			// export.
+			slices.SortStableFunc(checkItems, func(a, b checkItem) int { return cmp.Compare(a.Pos, b.Pos) })
+			for _, ci := range checkItems {
+				if strings.TrimSpace(ci.Name) == "" {
+					continue
+				}
+				items = append(items, todo.ParsedTodoItem{
+					ID:        len(items) + 1,
+					Todo:      strings.TrimSpace(ci.Name),
+					Project:   parent.Project,
+					Parent:    parent.ID,
+					CreatedAt: parent.CreatedAt,
+					Completed: ci.State == "complete",
+				})
+			}
+		}
+	}
+	return items, nil
+}
+
+// tag returns name as a tag, with its spaces as dashes.
+func tag(name string) string {
+	return strings.Join(strings.Fields(name), "-")
+}
+
+// created returns when the card with id was made, which is the first four
+// bytes of its ID as a Unix time, as in a MongoDB object ID.
+func created(id string) time.Time {
+	b, err := hex.DecodeString(id)
+	if err != nil || len(b) < 4 {
+		return time.Time{}
+	}
+	return time.Unix(int64(binary.BigEndian.Uint32(b)), 0)
+}
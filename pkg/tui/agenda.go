@@ -0,0 +1,93 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/buck06191/todo-app/pkg/calendar"
+	"github.com/buck06191/todo-app/pkg/todo"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const agendaHelpText = "←→ previous/next  . today  m week/month  a add  / filter  tab list  q quit"
+
+// updateAgenda handles the keys that only apply to the agenda. It reports
+// false for keys it leaves to updateNormal.
+func (m model) updateAgenda(msg tea.KeyMsg) (tea.Model, tea.Cmd, bool) {
+	switch msg.String() {
+	case "left", "h", "[":
+		m.agendaFrom = m.agendaStep(-1)
+	case "right", "l", "]":
+		m.agendaFrom = m.agendaStep(1)
+	case ".", "t":
+		m.agendaFrom = time.Now()
+	case "m":
+		m.agendaMonth = !m.agendaMonth
+	default:
+		return m, nil, false
+	}
+	return m, nil, true
+}
+
+// agendaStep returns a day in the week or month n periods away from the
+// one shown.
+func (m model) agendaStep(n int) time.Time {
+	if m.agendaMonth {
+		return todo.MonthStart(m.agendaFrom).AddDate(0, n, 0)
+	}
+	return m.agendaFrom.AddDate(0, 0, 7*n)
+}
+
+func (m model) agendaView() string {
+	now := time.Now()
+	var items []todo.ParsedTodoItem
+	for _, item := range m.items {
+		if m.filter == nil || m.filter.Match(item, now) {
+			items = append(items, item)
+		}
+	}
+
+	opts := calendar.Options{
+		Width: max(m.width, 40),
+		Today: now,
+		Style: func(item todo.ParsedTodoItem, text string) string {
+			if dueToday(item, now) {
+				return todayStyle.Render(text)
+			}
+			return text
+		},
+	}
+	start, days := todo.WeekStart(m.agendaFrom), 7
+	title := "Week of " + start.Format("Mon 2 January 2006")
+	if m.agendaMonth {
+		start, days = todo.MonthGrid(m.agendaFrom)
+		opts.Month = todo.MonthStart(m.agendaFrom).Month()
+		opts.MaxItems = 2
+		title = todo.MonthStart(m.agendaFrom).Format("January 2006")
+	}
+	overdue, agenda := todo.Agenda(items, start, days, now)
+
+	var lines []string
+	if len(overdue) > 0 {
+		// Keep at least half the screen for the calendar.
+		shown := min(len(overdue), max(m.pageSize()/2-2, 1))
+		lines = append(lines, headerStyle.Render(fmt.Sprintf("Overdue (%d)", len(overdue))))
+		for _, item := range overdue[:shown] {
+			lines = append(lines, overdueStyle.Render(cut(fmt.Sprintf("%4d  %-16s  %s", item.ID, formatDue(item), item.Todo), opts.Width)))
+		}
+		if shown < len(overdue) {
+			lines = append(lines, helpStyle.Render(fmt.Sprintf("      +%d more", len(overdue)-shown)))
+		}
+		lines = append(lines, "")
+	}
+	lines = append(lines, headerStyle.Render(title))
+	lines = append(lines, strings.Split(strings.TrimSuffix(calendar.Grid(agenda, opts), "\n"), "\n")...)
+
+	if page := m.pageSize(); len(lines) > page {
+		lines = lines[:page]
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
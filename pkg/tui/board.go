@@ -0,0 +1,237 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/buck06191/todo-app/pkg/todo"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const boardHelpText = "←→ column  H L move card  t by tag/status  x done  tab agenda  q quit"
+
+// column is one column of the board. When the board is by tag, tag is the
+// tag the column is for, or "" for items without one of the tags.
+type column struct {
+	name  string
+	tag   string
+	items []todo.ParsedTodoItem
+}
+
+// columns arranges the items matching the filter into the columns of the
+// board. By status, done items go in the last column; by tag, they are left
+// out and an item with several tags is in the first of them.
+func (m model) columns() []column {
+	now := time.Now()
+	var items []todo.ParsedTodoItem
+	for _, item := range m.items {
+		if m.filter == nil || m.filter.Match(item, now) {
+			items = append(items, item)
+		}
+	}
+	todo.SortByDue(items)
+
+	if !m.boardByTag {
+		cols := make([]column, len(todo.Statuses))
+		index := map[string]int{}
+		for i, status := range todo.Statuses {
+			cols[i].name = strings.ToUpper(status[:1]) + status[1:]
+			index[status] = i
+		}
+		for _, item := range items {
+			i := index[item.CurrentStatus()]
+			cols[i].items = append(cols[i].items, item)
+		}
+		return cols
+	}
+
+	var open []todo.ParsedTodoItem
+	for _, item := range items {
+		if !item.Completed {
+			open = append(open, item)
+		}
+	}
+	var cols []column
+	index := map[string]int{}
+	for _, c := range todo.CountTags(open) {
+		index[c.Tag] = len(cols)
+		cols = append(cols, column{name: "#" + c.Tag, tag: c.Tag})
+	}
+	untagged := column{name: "(no tag)"}
+	for _, item := range open {
+		placed := false
+		for _, tag := range item.Tags {
+			if i, ok := index[tag]; ok {
+				cols[i].items = append(cols[i].items, item)
+				placed = true
+				break
+			}
+		}
+		if !placed {
+			untagged.items = append(untagged.items, item)
+		}
+	}
+	return append(cols, untagged)
+}
+
+// updateBoard handles the keys that only apply to the board. It reports
+// false for keys it leaves to updateNormal.
+func (m model) updateBoard(msg tea.KeyMsg) (tea.Model, tea.Cmd, bool) {
+	cols := m.columns()
+	switch msg.String() {
+	case "left", "h":
+		m.col = max(m.col-1, 0)
+	case "right", "l":
+		m.col = min(m.col+1, len(cols)-1)
+	case "up", "k":
+		m.row = max(m.row-1, 0)
+	case "down", "j":
+		m.row++
+	case "H", "shift+left", "<":
+		return m, m.moveCard(cols, -1), true
+	case "L", "shift+right", ">":
+		return m, m.moveCard(cols, 1), true
+	case "t":
+		m.boardByTag = !m.boardByTag
+		m.col, m.row = 0, 0
+		m.syncBoard()
+		return m, nil, true
+	default:
+		return m, nil, false
+	}
+	m.clampBoard(cols)
+	return m, nil, true
+}
+
+// moveCard moves the selected card dir columns to the left or right,
+// changing its status or tag to match.
+func (m *model) moveCard(cols []column, dir int) tea.Cmd {
+	item, ok := m.current()
+	if !ok {
+		return nil
+	}
+	to := m.col + dir
+	if to < 0 || to >= len(cols) {
+		return nil
+	}
+	from := cols[m.col]
+	target := cols[to]
+	m.col = to
+
+	if m.boardByTag {
+		item.Tags = todo.RemoveTags(item.Tags, from.tag)
+		if target.tag != "" {
+			item.Tags = todo.AddTags(item.Tags, target.tag)
+		}
+		return m.change(fmt.Sprintf("Moved %d to %s", item.ID, target.name), func(store todo.Store) error {
+			return store.Update(item)
+		})
+	}
+
+	status := todo.Statuses[to]
+	return m.change(fmt.Sprintf("Moved %d to %s", item.ID, target.name), func(store todo.Store) error {
+		now := time.Now()
+		done := item.SetStatus(status, now)
+		if err := store.Update(item); err != nil {
+			return err
+		}
+		if !done {
+			return nil
+		}
+		if next, ok := item.NextOccurrence(now); ok {
+			_, err := store.Add(next)
+			return err
+		}
+		return nil
+	})
+}
+
+// syncBoard puts the board cursor on the selected item, wherever it has
+// moved to.
+func (m *model) syncBoard() {
+	cols := m.columns()
+	for c, col := range cols {
+		for r, item := range col.items {
+			if item.ID == m.selected {
+				m.col, m.row = c, r
+				return
+			}
+		}
+	}
+	m.clampBoard(cols)
+}
+
+// clampBoard keeps the board cursor on a card, if there are any, and
+// remembers which one it is on.
+func (m *model) clampBoard(cols []column) {
+	m.col = max(min(m.col, len(cols)-1), 0)
+	if len(cols) == 0 {
+		return
+	}
+	m.row = max(min(m.row, len(cols[m.col].items)-1), 0)
+	if m.row < len(cols[m.col].items) {
+		m.selected = cols[m.col].items[m.row].ID
+	}
+}
+
+// boardCurrent returns the card under the board cursor.
+func (m model) boardCurrent() (todo.ParsedTodoItem, bool) {
+	cols := m.columns()
+	if m.col >= len(cols) || m.row >= len(cols[m.col].items) {
+		return todo.ParsedTodoItem{}, false
+	}
+	return cols[m.col].items[m.row], true
+}
+
+func (m model) boardView() string {
+	cols := m.columns()
+	width := max(m.width, 40)
+	colWidth := max((width-len(cols)+1)/len(cols), 12)
+	page := m.pageSize() - 1
+	now := time.Now()
+
+	var rendered []string
+	for c, col := range cols {
+		if c > 0 {
+			rendered = append(rendered, " ")
+		}
+		lines := []string{headerStyle.Render(cut(fmt.Sprintf("%s (%d)", col.name, len(col.items)), colWidth))}
+
+		offset := 0
+		if c == m.col && m.row >= page {
+			offset = m.row - page + 1
+		}
+		for r := offset; r < len(col.items) && r < offset+page; r++ {
+			item := col.items[r]
+			style := lipgloss.NewStyle()
+			switch {
+			case item.Completed:
+				style = doneStyle
+			case item.IsOverdue(now):
+				style = overdueStyle
+			case dueToday(item, now):
+				style = todayStyle
+			case item.EffectivePriority(now) > item.Priority:
+				style = raisedStyle
+			}
+			if c == m.col && r == m.row {
+				style = style.Inherit(selectedStyle)
+			}
+			card := fmt.Sprintf("%d %s%s", item.ID, strings.Repeat("!", int(item.EffectivePriority(now))), item.Todo)
+			lines = append(lines, style.Render(cut(card, colWidth)))
+		}
+		rendered = append(rendered, lipgloss.NewStyle().Width(colWidth).Render(strings.Join(lines, "\n")))
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, rendered...)
+}
+
+// cut shortens s to n runes, ending it with … if anything was removed.
+func cut(s string, n int) string {
+	if runes := []rune(s); len(runes) > n {
+		return string(runes[:n-1]) + "…"
+	}
+	return s
+}
@@ -0,0 +1,441 @@
+// Package tui is a full screen, keyboard driven interface to a todo.Store,
+// built with Bubble Tea. It is what `todo-app tui` runs.
+//
+// There are three views, switched between with tab: the list, a kanban
+// board with a column per status (see todo.Statuses) or per tag, and an
+// agenda showing what is due each day of a week or month. Moving a card to
+// another column of the board changes its status or tag in the store.
+//
+// The list is reloaded from the store every few seconds, so changes made by
+// other commands, or by other people sharing a database, show up without
+// having to restart it.
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/buck06191/todo-app/pkg/todo"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// DefaultRefresh is how often the list is reloaded if Options.Refresh isn't
+// set.
+const DefaultRefresh = 2 * time.Second
+
+// Options change how Run behaves. The zero value is the defaults.
+type Options struct {
+	// NoColor turns off colors.
+	NoColor bool
+	// Refresh is how often the list is reloaded from the store.
+	Refresh time.Duration
+	// Filter, if set, is the filter expression to start with.
+	Filter string
+}
+
+// Run shows the interface for store until the user quits.
+func Run(store todo.Store, opts Options) error {
+	if opts.NoColor {
+		lipgloss.SetColorProfile(termenv.Ascii)
+	}
+	if opts.Refresh <= 0 {
+		opts.Refresh = DefaultRefresh
+	}
+
+	m := newModel(store, opts.Refresh)
+	if opts.Filter != "" {
+		filter, err := todo.ParseFilter(opts.Filter)
+		if err != nil {
+			return err
+		}
+		m.filter = filter
+	}
+
+	final, err := tea.NewProgram(m, tea.WithAltScreen()).Run()
+	if err != nil {
+		return err
+	}
+	return final.(model).err
+}
+
+// view is which of the views is shown.
+type view int
+
+const (
+	viewList view = iota
+	viewBoard
+	viewAgenda
+)
+
+// mode is what key presses currently do.
+type mode int
+
+const (
+	modeNormal mode = iota
+	modeAdd
+	modeEdit
+	modeFilter
+)
+
+// Messages sent back to Update by the commands it starts.
+type (
+	// loadedMsg carries the list read from the store.
+	loadedMsg struct {
+		items []todo.ParsedTodoItem
+		err   error
+	}
+	// changedMsg reports the result of changing the store.
+	changedMsg struct {
+		status string
+		err    error
+	}
+	tickMsg time.Time
+)
+
+type model struct {
+	store   todo.Store
+	refresh time.Duration
+
+	// items is everything on the list and shown is the part of it that
+	// is visible, in the order it is shown.
+	items []todo.ParsedTodoItem
+	shown []todo.ParsedTodoItem
+
+	// cursor is the index in shown of the selected item and offset the
+	// index of the first one on screen.
+	cursor, offset int
+	// selected is the ID of the selected item, so the cursor stays on it
+	// when the list is reloaded.
+	selected int
+
+	view view
+	// col and row are the board cursor, and boardByTag is set when the
+	// board has a column per tag rather than per status.
+	col, row   int
+	boardByTag bool
+	// agendaFrom is a day in the week or month the agenda shows, and
+	// agendaMonth is set when it shows a month.
+	agendaFrom  time.Time
+	agendaMonth bool
+
+	mode     mode
+	input    textinput.Model
+	filter   *todo.Filter
+	showDone bool
+
+	status        string
+	width, height int
+	// err is a fatal error that ends the program.
+	err error
+}
+
+func newModel(store todo.Store, refresh time.Duration) model {
+	input := textinput.New()
+	input.CharLimit = 500
+	return model{store: store, refresh: refresh, input: input, agendaFrom: time.Now()}
+}
+
+func (m model) Init() tea.Cmd {
+	return tea.Batch(m.load, m.tick())
+}
+
+func (m model) load() tea.Msg {
+	items, err := m.store.List()
+	return loadedMsg{items: items, err: err}
+}
+
+func (m model) tick() tea.Cmd {
+	return tea.Tick(m.refresh, func(t time.Time) tea.Msg { return tickMsg(t) })
+}
+
+// change runs fn against the store in the background, reporting status if
+// it works.
+func (m model) change(status string, fn func(store todo.Store) error) tea.Cmd {
+	return func() tea.Msg {
+		return changedMsg{status: status, err: fn(m.store)}
+	}
+}
+
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.scroll()
+		return m, nil
+
+	case loadedMsg:
+		if msg.err != nil {
+			m.err = msg.err
+			return m, tea.Quit
+		}
+		m.items = msg.items
+		m.rebuild()
+		return m, nil
+
+	case changedMsg:
+		if msg.err != nil {
+			m.status = "Error: " + msg.err.Error()
+		} else {
+			m.status = msg.status
+		}
+		return m, m.load
+
+	case tickMsg:
+		return m, tea.Batch(m.load, m.tick())
+
+	case tea.KeyMsg:
+		if m.mode != modeNormal {
+			return m.updateInput(msg)
+		}
+		return m.updateNormal(msg)
+	}
+	return m, nil
+}
+
+func (m model) updateNormal(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.status = ""
+	switch m.view {
+	case viewBoard:
+		if next, cmd, ok := m.updateBoard(msg); ok {
+			return next, cmd
+		}
+	case viewAgenda:
+		if next, cmd, ok := m.updateAgenda(msg); ok {
+			return next, cmd
+		}
+	}
+
+	switch msg.String() {
+	case "tab":
+		m.view = (m.view + 1) % (viewAgenda + 1)
+		m.rebuild()
+	case "q", "ctrl+c":
+		return m, tea.Quit
+	case "up", "k":
+		m.move(-1)
+	case "down", "j":
+		m.move(1)
+	case "pgup":
+		m.move(-m.pageSize())
+	case "pgdown":
+		m.move(m.pageSize())
+	case "home", "g":
+		m.move(-len(m.shown))
+	case "end", "G":
+		m.move(len(m.shown))
+	case "a":
+		return m.startInput(modeAdd, "Add: ", "")
+	case "e", "enter":
+		if item, ok := m.current(); ok {
+			return m.startInput(modeEdit, "Edit: ", item.Todo)
+		}
+	case "/":
+		current := ""
+		if m.filter != nil {
+			current = m.filter.String()
+		}
+		return m.startInput(modeFilter, "Filter: ", current)
+	case "esc":
+		m.filter = nil
+		m.rebuild()
+	case "h":
+		m.showDone = !m.showDone
+		m.rebuild()
+	case "x", " ":
+		if item, ok := m.current(); ok {
+			return m, m.toggleDone(item)
+		}
+	case "d", "delete":
+		if item, ok := m.current(); ok {
+			return m, m.change(fmt.Sprintf("Moved %d to the trash", item.ID), func(store todo.Store) error {
+				return store.Delete(item.ID)
+			})
+		}
+	case "r":
+		return m, m.load
+	}
+	return m, nil
+}
+
+// toggleDone marks item as done, adding the next one if it repeats, or
+// marks it as not done if it already was.
+func (m model) toggleDone(item todo.ParsedTodoItem) tea.Cmd {
+	if item.Completed {
+		return m.change(fmt.Sprintf("Marked %d as not done", item.ID), func(store todo.Store) error {
+			item.Completed = false
+			item.CompletedAt = time.Time{}
+			return store.Update(item)
+		})
+	}
+	return m.change(fmt.Sprintf("Done: %d %s", item.ID, item.Todo), func(store todo.Store) error {
+		now := time.Now()
+		item.Completed = true
+		item.CompletedAt = now
+		item.StopWork(now)
+		if err := store.Update(item); err != nil {
+			return err
+		}
+		if next, ok := item.NextOccurrence(now); ok {
+			_, err := store.Add(next)
+			return err
+		}
+		return nil
+	})
+}
+
+func (m model) startInput(mode mode, prompt, value string) (tea.Model, tea.Cmd) {
+	m.mode = mode
+	m.input.Prompt = prompt
+	m.input.SetValue(value)
+	m.input.CursorEnd()
+	return m, m.input.Focus()
+}
+
+func (m model) updateInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.mode = modeNormal
+		m.input.Blur()
+		return m, nil
+	case "enter":
+		mode, value := m.mode, strings.TrimSpace(m.input.Value())
+		m.mode = modeNormal
+		m.input.Blur()
+		return m.submit(mode, value)
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+// submit acts on the text entered in one of the input modes.
+func (m model) submit(mode mode, value string) (tea.Model, tea.Cmd) {
+	switch mode {
+	case modeAdd:
+		if value == "" {
+			return m, nil
+		}
+		item, err := todo.ParseItem(todo.TodoItem{Todo: value})
+		if err != nil {
+			m.status = "Error: " + err.Error()
+			return m, nil
+		}
+		return m, m.change("Added "+item.Todo, func(store todo.Store) error {
+			_, err := store.Add(item)
+			return err
+		})
+
+	case modeEdit:
+		item, ok := m.current()
+		if !ok || value == "" || value == item.Todo {
+			return m, nil
+		}
+		// Markers typed into the text are handled as they are by add.
+		parsed, err := todo.ParseItem(todo.TodoItem{Todo: value})
+		if err != nil {
+			m.status = "Error: " + err.Error()
+			return m, nil
+		}
+		item.Todo = parsed.Todo
+		item.Tags = todo.AddTags(item.Tags, parsed.Tags...)
+		item.Contexts = todo.AddContexts(item.Contexts, parsed.Contexts...)
+		if parsed.Project != "" {
+			item.Project = parsed.Project
+		}
+		return m, m.change(fmt.Sprintf("Updated %d", item.ID), func(store todo.Store) error {
+			return store.Update(item)
+		})
+
+	case modeFilter:
+		m.filter = nil
+		if value != "" {
+			filter, err := todo.ParseFilter(value)
+			if err != nil {
+				m.status = "Error: " + err.Error()
+				return m, nil
+			}
+			m.filter = filter
+		}
+		m.rebuild()
+	}
+	return m, nil
+}
+
+// rebuild works out which items are shown after the list or the filter
+// changes, keeping the same item selected if it is still there.
+func (m *model) rebuild() {
+	now := time.Now()
+	m.shown = nil
+	for _, item := range m.items {
+		if m.filter != nil {
+			if !m.filter.Match(item, now) {
+				continue
+			}
+		} else if item.Completed && !m.showDone {
+			continue
+		}
+		m.shown = append(m.shown, item)
+	}
+	todo.SortByDue(m.shown)
+
+	if m.view == viewBoard {
+		m.syncBoard()
+		return
+	}
+
+	m.cursor = min(m.cursor, max(len(m.shown)-1, 0))
+	for i, item := range m.shown {
+		if item.ID == m.selected {
+			m.cursor = i
+			break
+		}
+	}
+	m.move(0)
+}
+
+// move moves the cursor by n items, staying inside the list.
+func (m *model) move(n int) {
+	m.cursor = max(min(m.cursor+n, len(m.shown)-1), 0)
+	if item, ok := m.current(); ok {
+		m.selected = item.ID
+	}
+	m.scroll()
+}
+
+// scroll moves the visible part of the list so the cursor is on screen.
+func (m *model) scroll() {
+	page := m.pageSize()
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+page {
+		m.offset = m.cursor - page + 1
+	}
+	m.offset = max(m.offset, 0)
+}
+
+// pageSize is how many items fit on screen between the header and footer.
+func (m model) pageSize() int {
+	return max(m.height-4, 1)
+}
+
+// current returns the selected item in whichever view is shown. Nothing
+// is selected in the agenda.
+func (m model) current() (todo.ParsedTodoItem, bool) {
+	switch m.view {
+	case viewBoard:
+		return m.boardCurrent()
+	case viewAgenda:
+		return todo.ParsedTodoItem{}, false
+	}
+	if m.cursor < 0 || m.cursor >= len(m.shown) {
+		return todo.ParsedTodoItem{}, false
+	}
+	return m.shown[m.cursor], true
+}
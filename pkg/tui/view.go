@@ -0,0 +1,134 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/buck06191/todo-app/pkg/todo"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	headerStyle   = lipgloss.NewStyle().Bold(true)
+	selectedStyle = lipgloss.NewStyle().Reverse(true)
+	overdueStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("1"))
+	todayStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("3"))
+	raisedStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("5"))
+	doneStyle     = lipgloss.NewStyle().Faint(true)
+	helpStyle     = lipgloss.NewStyle().Faint(true)
+)
+
+const helpText = "a add  e edit  x done  d delete  / filter  esc clear  h show done  tab next view  q quit"
+
+func (m model) View() string {
+	var b strings.Builder
+
+	header := fmt.Sprintf("todo-app  %d shown of %d", len(m.shown), len(m.items))
+	if m.filter != nil {
+		header += "  filter: " + m.filter.String()
+	} else if m.showDone {
+		header += "  (showing done)"
+	}
+	b.WriteString(headerStyle.Render(header) + "\n\n")
+
+	help := helpText
+	switch m.view {
+	case viewBoard, viewAgenda:
+		var view string
+		if m.view == viewBoard {
+			view, help = m.boardView(), boardHelpText
+		} else {
+			view, help = m.agendaView(), agendaHelpText
+		}
+		b.WriteString(view + "\n")
+		for i := strings.Count(view, "\n") + 1; i < m.pageSize(); i++ {
+			b.WriteString("\n")
+		}
+	default:
+		now := time.Now()
+		end := min(m.offset+m.pageSize(), len(m.shown))
+		if len(m.shown) == 0 {
+			b.WriteString("Nothing to do!\n")
+		}
+		for i := m.offset; i < end; i++ {
+			b.WriteString(m.line(m.shown[i], i == m.cursor, now) + "\n")
+		}
+		for i := max(end-m.offset, 1); i < m.pageSize(); i++ {
+			b.WriteString("\n")
+		}
+	}
+
+	switch {
+	case m.mode != modeNormal:
+		b.WriteString(m.input.View())
+	case m.status != "":
+		b.WriteString(m.status)
+	default:
+		b.WriteString(helpStyle.Render(help))
+	}
+	return b.String()
+}
+
+// line formats one item of the list, cut to the width of the screen.
+func (m model) line(item todo.ParsedTodoItem, selected bool, now time.Time) string {
+	text := item.Todo
+	if item.Project != "" {
+		text += " +" + item.Project
+	}
+	for _, context := range item.Contexts {
+		text += " @" + context
+	}
+	for _, tag := range item.Tags {
+		text += " #" + tag
+	}
+	if item.Notes != "" {
+		text += " …"
+	}
+
+	check := "[ ]"
+	if item.Completed {
+		check = "[x]"
+	}
+	line := fmt.Sprintf("%s %4d  %-16s  %-3s %s", check, item.ID, formatDue(item), strings.Repeat("!", int(item.EffectivePriority(now))), text)
+	if m.width > 0 {
+		line = cut(line, m.width)
+	}
+
+	style := lipgloss.NewStyle()
+	switch {
+	case item.Completed:
+		style = doneStyle
+	case item.IsOverdue(now):
+		style = overdueStyle
+	case dueToday(item, now):
+		style = todayStyle
+	case item.EffectivePriority(now) > item.Priority:
+		style = raisedStyle
+	}
+	if selected {
+		style = style.Inherit(selectedStyle)
+	}
+	return style.Render(line)
+}
+
+func formatDue(item todo.ParsedTodoItem) string {
+	switch {
+	case item.Due.IsZero():
+		return ""
+	case item.DueAllDay():
+		return item.Due.Format("2006-01-02")
+	default:
+		return item.Due.In(todo.Location).Format("2006-01-02 15:04")
+	}
+}
+
+func dueToday(item todo.ParsedTodoItem, now time.Time) bool {
+	if item.Due.IsZero() {
+		return false
+	}
+	y, m, d := item.Due.In(todo.Location).Date()
+	ny, nm, nd := now.In(todo.Location).Date()
+	return y == ny && m == nm && d == nd
+}
@@ -0,0 +1,137 @@
+// Package webhook sends what happens to todo items to other programs, such
+// as Slack, Home Assistant or scripts of one's own, by POSTing a JSON
+// payload to a URL for each event. Payloads are signed with the hook's
+// secret, if it has one, so that the receiver can check they came from
+// todo-app.
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/buck06191/todo-app/pkg/todo"
+)
+
+// The events a hook can be sent.
+const (
+	// EventAdd is sent when an item is added.
+	EventAdd = "add"
+	// EventDone is sent when an item is marked as done.
+	EventDone = "done"
+	// EventOverdue is sent when an item comes to be overdue.
+	EventOverdue = "overdue"
+)
+
+// Events are all the events, in the order they are listed.
+var Events = []string{EventAdd, EventDone, EventOverdue}
+
+// SignatureHeader holds a payload's signature: "sha256=" followed by the
+// HMAC-SHA256 of the body with the hook's secret as the key, in hex.
+const SignatureHeader = "X-Todo-Signature"
+
+// EventHeader holds the event a payload is for, so it can be told without
+// reading the body.
+const EventHeader = "X-Todo-Event"
+
+// timeout is how long a hook has to answer.
+const timeout = 10 * time.Second
+
+// Hook is a URL events are sent to.
+type Hook struct {
+	Name   string
+	URL    string
+	Secret string
+	// Events are those the hook is sent, or all of them if it is empty.
+	Events []string
+}
+
+// Wants reports whether the hook is sent event.
+func (h Hook) Wants(event string) bool {
+	return len(h.Events) == 0 || slices.Contains(h.Events, event)
+}
+
+// ParseEvents reads a list of events separated by commas, such as
+// "add,done".
+func ParseEvents(s string) ([]string, error) {
+	var events []string
+	for _, e := range strings.Split(s, ",") {
+		e = strings.ToLower(strings.TrimSpace(e))
+		if e == "" {
+			continue
+		}
+		if !slices.Contains(Events, e) {
+			return nil, fmt.Errorf("unknown event %q, expected %s", e, strings.Join(Events, ", "))
+		}
+		events = append(events, e)
+	}
+	return events, nil
+}
+
+// Payload is the JSON body sent for an event. Text sums it up in a line,
+// which is what Slack's incoming webhooks show.
+type Payload struct {
+	Event string              `json:"event"`
+	At    time.Time           `json:"at"`
+	Text  string              `json:"text"`
+	Item  todo.ParsedTodoItem `json:"item"`
+}
+
+// NewPayload returns the payload for event happening to item at at.
+func NewPayload(event string, item todo.ParsedTodoItem, at time.Time) Payload {
+	what := map[string]string{EventAdd: "Added", EventDone: "Done", EventOverdue: "Overdue"}[event]
+	return Payload{Event: event, At: at, Text: fmt.Sprintf("%s: %s", what, item.Todo), Item: item}
+}
+
+// Sign returns the signature of body with secret, as it goes in
+// SignatureHeader.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is that of body with secret, for
+// receivers checking a payload came from a hook with their secret.
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
+
+// Send POSTs p to the hook, failing unless it answers with a 2xx status.
+func (h Hook) Send(p Payload) error {
+	body, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, h.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "todo-app")
+	req.Header.Set(EventHeader, p.Event)
+	if h.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(h.Secret, body))
+	}
+	resp, err := (&http.Client{Timeout: timeout}).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		if msg = bytes.TrimSpace(msg); len(msg) > 0 {
+			return fmt.Errorf("%s answered %s: %s", h.URL, resp.Status, msg)
+		}
+		return fmt.Errorf("%s answered %s", h.URL, resp.Status)
+	}
+	return nil
+}